@@ -2,6 +2,7 @@ package datafeed
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -19,139 +20,183 @@ func GetAlpacaBars(symbol string, timeframe string, limit int, startDate string)
 	return GetAlpacaBarsWithType(symbol, timeframe, limit, startDate, "stock")
 }
 
+// length of one bar of an Alpaca timeframe, a day for unknown ones
+func TimeframeDuration(tf string) time.Duration {
+	switch tf {
+	case "1Min":
+		return time.Minute
+	case "3Min":
+		return 3 * time.Minute
+	case "5Min":
+		return 5 * time.Minute
+	case "10Min":
+		return 10 * time.Minute
+	case "30Min":
+		return 30 * time.Minute
+	case "1Hour":
+		return time.Hour
+	case "2Hour":
+		return 2 * time.Hour
+	case "4Hour":
+		return 4 * time.Hour
+	case "1Day":
+		return 24 * time.Hour
+	case "1Week":
+		return 7 * 24 * time.Hour
+	case "1Month":
+		return 30 * 24 * time.Hour
+	default:
+		return 24 * time.Hour
+	}
+}
+
 func GetAlpacaBarsWithType(symbol string, timeframe string, limit int, startDate string, assetType string) ([]Bar, error) {
-	apiKey := os.Getenv("ALPACA_API_KEY")
-	secretKey := os.Getenv("ALPACA_API_SECRET")
+	apiURL := alpacaBarsURL(symbol, timeframe, limit, startDate, assetType)
+	fmt.Printf("🔗 API Request: %s\n", apiURL)
 
+	var bars []Bar
+	retryConfig := utils.DefaultRetryConfig()
+
+	err := utils.RetryWithBackoff(func() error {
+		var err error
+		bars, err = fetchAlpacaBars(apiURL, symbol, timeframe, assetType)
+		return err
+	}, retryConfig)
+
+	if err != nil {
+		return nil, err
+	}
+	return latestFirstBars(symbol, bars)
+}
+
+// the data API bars request, starting far enough back for limit bars when startDate is empty
+func alpacaBarsURL(symbol string, timeframe string, limit int, startDate string, assetType string) string {
 	if startDate == "" {
 		now := time.Now().UTC()
 
-		timeframeToDur := func(tf string) time.Duration {
-			switch tf {
-			case "1Min":
-				return time.Minute
-			case "3Min":
-				return 3 * time.Minute
-			case "5Min":
-				return 5 * time.Minute
-			case "10Min":
-				return 10 * time.Minute
-			case "30Min":
-				return 30 * time.Minute
-			case "1Hour":
-				return time.Hour
-			case "2Hour":
-				return 2 * time.Hour
-			case "4Hour":
-				return 4 * time.Hour
-			case "1Day":
-				return 24 * time.Hour
-			case "1Week":
-				return 7 * 24 * time.Hour
-			case "1Month":
-				return 30 * 24 * time.Hour
-			default:
-				return 24 * time.Hour
-			}
-		}
-
-		barDur := timeframeToDur(timeframe)
+		barDur := TimeframeDuration(timeframe)
 		totalDur := barDur * time.Duration(limit+2)
 		start := now.Add(-totalDur)
 		startDate = start.Format(time.RFC3339)
 	}
 
-	var apiURL string
 	if assetType == "crypto" {
-		apiURL = fmt.Sprintf(
+		// the crypto data API only accepts the BASE/QUOTE pair form
+		return fmt.Sprintf(
 			"https://data.alpaca.markets/v1beta3/crypto/us/bars?symbols=%s&timeframe=%s&limit=%d&start=%s",
-			url.QueryEscape(symbol), timeframe, limit, startDate,
-		)
-	} else {
-		apiURL = fmt.Sprintf(
-			"https://data.alpaca.markets/v2/stocks/%s/bars?timeframe=%s&limit=%d&start=%s",
-			symbol, timeframe, limit, startDate,
+			url.QueryEscape(utils.DisplaySymbol(symbol, assetType)), timeframe, limit, startDate,
 		)
 	}
+	return fmt.Sprintf(
+		"https://data.alpaca.markets/v2/stocks/%s/bars?timeframe=%s&limit=%d&start=%s",
+		symbol, timeframe, limit, startDate,
+	)
+}
 
-	fmt.Printf("🔗 API Request: %s\n", apiURL)
+// client behind bar requests, swapped for a fake transport in tests
+var dataHTTPClient = &http.Client{}
 
-	var bars []Bar
-	retryConfig := utils.DefaultRetryConfig()
+// one bars request, oldest first as the API returns them
+func fetchAlpacaBars(apiURL, symbol, timeframe, assetType string) ([]Bar, error) {
+	apiKey := os.Getenv("ALPACA_API_KEY")
+	secretKey := os.Getenv("ALPACA_API_SECRET")
 
-	err := utils.RetryWithBackoff(func() error {
-		req, _ := http.NewRequest("GET", apiURL, nil)
-		req.Header.Set("APCA-API-KEY-ID", apiKey)
-		req.Header.Set("APCA-API-SECRET-KEY", secretKey)
+	req, _ := http.NewRequest("GET", apiURL, nil)
+	req.Header.Set("APCA-API-KEY-ID", apiKey)
+	req.Header.Set("APCA-API-SECRET-KEY", secretKey)
 
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			return err
-		}
-		defer resp.Body.Close()
+	resp, err := dataHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
-		fmt.Printf("📡 API Response Status: %s\n", resp.Status)
+	fmt.Printf("📡 API Response Status: %s\n", resp.Status)
 
-		if resp.StatusCode == 403 {
-			fmt.Printf("⚠️  403 Forbidden - Your account may not have access to %s data\n", timeframe)
-			bars = []Bar{}
-			return nil
-		}
+	if resp.StatusCode == 403 {
+		fmt.Printf("⚠️  403 Forbidden - Your account may not have access to %s data\n", timeframe)
+		return []Bar{}, nil
+	}
 
-		if resp.StatusCode != 200 {
-			return fmt.Errorf("API returned status %d", resp.StatusCode)
-		}
+	if resp.StatusCode != 200 {
+		return nil, providerStatusError(resp, symbol, fmt.Sprintf("API returned status %d", resp.StatusCode))
+	}
 
-		// Handle different response structures for stock vs crypto
-		if assetType == "crypto" {
-			type CryptoResponse struct {
-				Bars map[string][]types.CryptoBar `json:"bars"`
-			}
-			var r CryptoResponse
-			if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
-				return err
-			}
-			// Extract bars for the requested symbol and convert to standard Bar format
-			for _, barSlice := range r.Bars {
-				for _, cb := range barSlice {
-					bars = append(bars, Bar{
-						Timestamp: cb.Timestamp,
-						Open:      cb.Open,
-						High:      cb.High,
-						Low:       cb.Low,
-						Close:     cb.Close,
-						Volume:    int64(cb.Volume), // Convert float to int64
-					})
-				}
-				break
-			}
-		} else {
-			// v2 stock endpoint returns flat structure with int volumes
-			type StockResponse struct {
-				Bars []Bar `json:"bars"`
-			}
-			var r StockResponse
-			if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
-				return err
+	var bars []Bar
+	// Handle different response structures for stock vs crypto
+	if assetType == "crypto" {
+		type CryptoResponse struct {
+			Bars map[string][]types.CryptoBar `json:"bars"`
+		}
+		var r CryptoResponse
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			return nil, err
+		}
+		// Extract bars for the requested symbol and convert to standard Bar format
+		for _, barSlice := range r.Bars {
+			for _, cb := range barSlice {
+				bars = append(bars, Bar{
+					Timestamp: cb.Timestamp,
+					Open:      cb.Open,
+					High:      cb.High,
+					Low:       cb.Low,
+					Close:     cb.Close,
+					Volume:    int64(cb.Volume), // Convert float to int64
+				})
 			}
-			bars = r.Bars
+			break
 		}
-		return nil
-	}, retryConfig)
-
-	if err != nil {
-		return nil, err
+	} else {
+		// v2 stock endpoint returns flat structure with int volumes
+		type StockResponse struct {
+			Bars []Bar `json:"bars"`
+		}
+		var r StockResponse
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			return nil, err
+		}
+		bars = r.Bars
 	}
+	return bars, nil
+}
 
+// reverses fetched bars to latest-first (most recent data first) and cleans them
+func latestFirstBars(symbol string, bars []Bar) ([]Bar, error) {
 	fmt.Printf("📊 Received %d bars\n", len(bars))
 
-	// Reverse bars to latest-first (most recent data first)
 	for i, j := 0, len(bars)-1; i < j; i, j = i+1, j-1 {
 		bars[i], bars[j] = bars[j], bars[i]
 	}
 
-	return bars, nil
+	return cleanBars(symbol, bars)
+}
+
+// a non-200 data API response; it unwraps to the typed error providerStatusError picked for the status
+type ProviderStatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // from the Retry-After header, 0 when absent or unparseable
+	err        error
+}
+
+func (e *ProviderStatusError) Error() string { return e.err.Error() }
+
+func (e *ProviderStatusError) Unwrap() error { return e.err }
+
+// wraps a non-200 data API response in the matching typed error so callers can map it
+func providerStatusError(resp *http.Response, symbol, message string) error {
+	statusErr := &ProviderStatusError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		statusErr.err = fmt.Errorf("%w: %s", utils.ErrRateLimited, message)
+	case http.StatusNotFound, http.StatusUnprocessableEntity:
+		statusErr.err = fmt.Errorf("%w: %s (%s)", utils.ErrSymbolNotFound, symbol, message)
+	default:
+		statusErr.err = errors.New(message)
+	}
+	return statusErr
 }
 
 type LastQuote struct {
@@ -180,7 +225,7 @@ func GetLastQuote(symbol string) (*LastQuote, error) {
 		defer resp.Body.Close()
 
 		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("failed to get last quote: %s", resp.Status)
+			return providerStatusError(resp, symbol, "failed to get last quote: "+resp.Status)
 		}
 
 		type Response struct {
@@ -221,7 +266,7 @@ func GetLastTrade(symbol string) (*Bar, error) {
 		defer resp.Body.Close()
 
 		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("failed to get last trade: %s", resp.Status)
+			return providerStatusError(resp, symbol, "failed to get last trade: "+resp.Status)
 		}
 
 		var r Bar
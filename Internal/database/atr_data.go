@@ -60,10 +60,12 @@ func FetchATRPrices(symbol string, limit int, timeframe string) ([]ATRBar, error
 	return atrBars, nil
 }
 
-func SaveATR(symbol string, timestamp time.Time, atrValue float64) error {
+// stored in UTC, like SaveRSI
+func SaveATR(symbol, timeframe string, timestamp time.Time, atrValue float64) error {
 	params := database.SaveATRParams{
 		Symbol:               symbol,
-		CalculationTimestamp: timestamp,
+		Timeframe:            timeframe,
+		CalculationTimestamp: timestamp.UTC(),
 		AtrValue:             float32(atrValue),
 	}
 	ctx := context.Background()
@@ -75,10 +77,12 @@ func SaveATR(symbol string, timestamp time.Time, atrValue float64) error {
 	return nil
 }
 
-func FetchATRForDisplay(symbol string, limit int) (map[string]float64, error) {
+// latest stored values keyed by IndicatorKey; an empty timeframe matches every timeframe
+func FetchATRForDisplay(symbol, timeframe string, limit int) (map[string]float64, error) {
 	params := database.GetATRForDateRangeParams{
-		Symbol: symbol,
-		Limit:  int32(limit),
+		Symbol:    symbol,
+		Timeframe: timeframe,
+		Limit:     int32(limit),
 	}
 	ctx := context.Background()
 	rows, err := Queries.GetATRForDateRange(ctx, params)
@@ -88,17 +92,18 @@ func FetchATRForDisplay(symbol string, limit int) (map[string]float64, error) {
 
 	atrMap := make(map[string]float64)
 	for _, row := range rows {
-		dateStr := row.CalculationTimestamp.Format("2006-01-02 15:04:05")
-		atrMap[dateStr] = float64(row.AtrValue)
+		atrMap[IndicatorKey(row.CalculationTimestamp)] = float64(row.AtrValue)
 	}
 	return atrMap, nil
 }
 
-func FetchATRByTimestampRange(symbol string, startTime, endTime time.Time) (map[string]float64, error) {
+// stored values for one timeframe within [startTime, endTime], keyed by IndicatorKey
+func FetchATRByTimestampRange(symbol, timeframe string, startTime, endTime time.Time) (map[string]float64, error) {
 	params := database.GetATRByTimestampRangeParams{
 		Symbol:                 symbol,
-		CalculationTimestamp:   startTime,
-		CalculationTimestamp_2: endTime,
+		Timeframe:              timeframe,
+		CalculationTimestamp:   startTime.UTC(),
+		CalculationTimestamp_2: endTime.UTC(),
 	}
 	ctx := context.Background()
 	rows, err := Queries.GetATRByTimestampRange(ctx, params)
@@ -108,12 +113,11 @@ func FetchATRByTimestampRange(symbol string, startTime, endTime time.Time) (map[
 
 	atrMap := make(map[string]float64)
 	for _, row := range rows {
-		dateStr := row.CalculationTimestamp.Format("2006-01-02 15:04:05")
-		atrMap[dateStr] = float64(row.AtrValue)
+		atrMap[IndicatorKey(row.CalculationTimestamp)] = float64(row.AtrValue)
 	}
 	return atrMap, nil
 }
-func CalculateAndStoreATR(symbol string, bars []types.Bar) error {
+func CalculateAndStoreATR(symbol, timeframe string, bars []types.Bar) error {
 	if len(bars) == 0 {
 		return nil
 	}
@@ -126,7 +130,7 @@ func CalculateAndStoreATR(symbol string, bars []types.Bar) error {
 		return err
 	}
 
-	err = SaveATR(symbol, latestTime, atrValue)
+	err = SaveATR(symbol, timeframe, latestTime, atrValue)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,95 @@
+package datafeed
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// retries GetAlpacaBarsWithRetry allows after a 429 or 5xx, and its wait before the first (doubled after each);
+// set from bar_retry
+var barRetry = struct {
+	maxRetries int
+	baseDelay  time.Duration
+}{maxRetries: 5, baseDelay: 500 * time.Millisecond}
+
+// longest Retry-After honored, so one throttled symbol can't stall a whole scan
+const maxBarRetryAfter = time.Minute
+
+// sleeps between attempts, swapped in tests
+var barRetrySleep = time.Sleep
+
+// updates the GetAlpacaBarsWithRetry defaults; non-positive values keep the current ones
+func SetBarRetry(maxRetries int, baseDelay time.Duration) {
+	if maxRetries > 0 {
+		barRetry.maxRetries = maxRetries
+	}
+	if baseDelay > 0 {
+		barRetry.baseDelay = baseDelay
+	}
+}
+
+func GetAlpacaBarsWithRetry(symbol, timeframe string, limit int, startDate string, maxRetries int) ([]Bar, error) {
+	return GetAlpacaBarsWithTypeRetry(symbol, timeframe, limit, startDate, "stock", maxRetries)
+}
+
+// GetAlpacaBarsWithType that retries rate limits (429) and provider errors (5xx) up to maxRetries times (0 uses
+// the bar_retry default), backing off exponentially or for the response's Retry-After when that is longer. Other
+// failures are returned straight away
+func GetAlpacaBarsWithTypeRetry(symbol, timeframe string, limit int, startDate, assetType string, maxRetries int) ([]Bar, error) {
+	if maxRetries <= 0 {
+		maxRetries = barRetry.maxRetries
+	}
+	apiURL := alpacaBarsURL(symbol, timeframe, limit, startDate, assetType)
+	fmt.Printf("🔗 API Request: %s\n", apiURL)
+
+	delay := barRetry.baseDelay
+	for attempt := 0; ; attempt++ {
+		bars, err := fetchAlpacaBars(apiURL, symbol, timeframe, assetType)
+		if err == nil {
+			return latestFirstBars(symbol, bars)
+		}
+
+		var statusErr *ProviderStatusError
+		if !errors.As(err, &statusErr) || !retryableStatus(statusErr.StatusCode) {
+			return nil, err
+		}
+		if attempt >= maxRetries {
+			return nil, fmt.Errorf("%s bars still failing after %d attempts: %w", symbol, attempt+1, err)
+		}
+
+		wait := delay
+		if retryAfter := min(statusErr.RetryAfter, maxBarRetryAfter); retryAfter > wait {
+			wait = retryAfter
+		}
+		log.Printf("Bars for %s returned %d, retrying in %s (%d/%d)", symbol, statusErr.StatusCode, wait, attempt+1, maxRetries)
+		barRetrySleep(wait)
+		delay *= 2
+	}
+}
+
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// a Retry-After header as a wait from now; it holds either seconds or an HTTP date
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
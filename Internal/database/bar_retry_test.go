@@ -0,0 +1,122 @@
+package datafeed
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+// answers each request with the next scripted response, repeating the last one when they run out
+type scriptedTransport struct {
+	responses []*http.Response
+	calls     int
+}
+
+func (t *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp := t.responses[min(t.calls, len(t.responses)-1)]
+	t.calls++
+	resp.Request = req
+	return resp, nil
+}
+
+func scriptedResponse(status int, retryAfter, body string) *http.Response {
+	header := http.Header{}
+	if retryAfter != "" {
+		header.Set("Retry-After", retryAfter)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// routes bar requests through transport and records sleeps instead of waiting
+func fakeBarTransport(t *testing.T, transport http.RoundTripper) *[]time.Duration {
+	t.Helper()
+	var slept []time.Duration
+	client, sleep := dataHTTPClient, barRetrySleep
+	dataHTTPClient = &http.Client{Transport: transport}
+	barRetrySleep = func(d time.Duration) { slept = append(slept, d) }
+	t.Cleanup(func() { dataHTTPClient, barRetrySleep = client, sleep })
+	return &slept
+}
+
+const twoBarsJSON = `{"bars":[{"t":"2024-01-02T00:00:00Z","o":10,"h":11,"l":9,"c":10.5,"v":100},` +
+	`{"t":"2024-01-03T00:00:00Z","o":10.5,"h":12,"l":10,"c":11.5,"v":200}]}`
+
+func TestGetAlpacaBarsWithRetry_RateLimitedTwiceThenOK(t *testing.T) {
+	transport := &scriptedTransport{responses: []*http.Response{
+		scriptedResponse(http.StatusTooManyRequests, "3", ""),
+		scriptedResponse(http.StatusTooManyRequests, "", ""),
+		scriptedResponse(http.StatusOK, "", twoBarsJSON),
+	}}
+	slept := fakeBarTransport(t, transport)
+	defer SetBarRetry(barRetry.maxRetries, barRetry.baseDelay)
+	SetBarRetry(5, 100*time.Millisecond)
+
+	bars, err := GetAlpacaBarsWithRetry("AAPL", "1Day", 2, "2024-01-01", 3)
+	if err != nil {
+		t.Fatalf("GetAlpacaBarsWithRetry() error = %v", err)
+	}
+	if transport.calls != 3 {
+		t.Errorf("requests = %d, want 3", transport.calls)
+	}
+	if len(bars) != 2 || bars[0].Close != 11.5 {
+		t.Errorf("bars = %+v, want both bars latest first", bars)
+	}
+	// the first wait honors Retry-After, the second backs off from the base delay
+	want := []time.Duration{3 * time.Second, 200 * time.Millisecond}
+	if len(*slept) != 2 || (*slept)[0] != want[0] || (*slept)[1] != want[1] {
+		t.Errorf("waits = %v, want %v", *slept, want)
+	}
+}
+
+func TestGetAlpacaBarsWithRetry_GivesUp(t *testing.T) {
+	transport := &scriptedTransport{responses: []*http.Response{scriptedResponse(http.StatusServiceUnavailable, "", "")}}
+	slept := fakeBarTransport(t, transport)
+
+	_, err := GetAlpacaBarsWithRetry("AAPL", "1Day", 2, "2024-01-01", 2)
+	if err == nil {
+		t.Fatal("Expected an error once retries run out")
+	}
+	if transport.calls != 3 || len(*slept) != 2 {
+		t.Errorf("requests/waits = %d/%d, want 3/2", transport.calls, len(*slept))
+	}
+}
+
+func TestGetAlpacaBarsWithRetry_NoRetryOnNotFound(t *testing.T) {
+	transport := &scriptedTransport{responses: []*http.Response{scriptedResponse(http.StatusNotFound, "", "")}}
+	slept := fakeBarTransport(t, transport)
+
+	_, err := GetAlpacaBarsWithRetry("NOPE", "1Day", 2, "2024-01-01", 3)
+	if !errors.Is(err, utils.ErrSymbolNotFound) {
+		t.Errorf("error = %v, want ErrSymbolNotFound", err)
+	}
+	if transport.calls != 1 || len(*slept) != 0 {
+		t.Errorf("requests/waits = %d/%d, want a single attempt", transport.calls, len(*slept))
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              0,
+		"7":                             7 * time.Second,
+		"-1":                            0,
+		"soon":                          0,
+		"Mon, 01 Jan 2024 12:00:30 GMT": 30 * time.Second,
+		"Mon, 01 Jan 2024 11:00:00 GMT": 0,
+	}
+	for header, want := range cases {
+		if got := parseRetryAfter(header, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
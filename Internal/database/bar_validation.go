@@ -0,0 +1,91 @@
+package datafeed
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+// returned when too many fetched bars were malformed to analyze what's left; it is also ErrInsufficientData
+var ErrMalformedBars = fmt.Errorf("%w: too many malformed bars", utils.ErrInsufficientData)
+
+// bar cleaning applied to every fetch; set from bar_validation
+var barValidation = struct {
+	enabled       bool
+	maxDroppedPct float64
+}{enabled: true, maxDroppedPct: 20}
+
+// updates the cleaning GetAlpacaBars applies; a non-positive percentage keeps the current limit
+func SetBarValidation(enabled bool, maxDroppedPct float64) {
+	barValidation.enabled = enabled
+	if maxDroppedPct > 0 {
+		barValidation.maxDroppedPct = maxDroppedPct
+	}
+}
+
+// how many bars ValidateBars dropped, by reason
+type BarValidation struct {
+	Kept         int
+	NonPositive  int // an open, high, low or close at or below zero
+	Inverted     int // high below low
+	BadTimestamp int // unparseable, or not older than the bar before it
+}
+
+func (v BarValidation) Dropped() int {
+	return v.NonPositive + v.Inverted + v.BadTimestamp
+}
+
+// share of the input that was dropped, 0-100
+func (v BarValidation) DroppedPct() float64 {
+	total := v.Kept + v.Dropped()
+	if total == 0 {
+		return 0
+	}
+	return float64(v.Dropped()) / float64(total) * 100
+}
+
+// removes bars with a non-positive OHLC, a high below the low, or a timestamp that is unparseable or out of order.
+// Bars are latest-first, so each kept bar must be strictly older than the one kept before it
+func ValidateBars(bars []Bar) ([]Bar, BarValidation) {
+	var v BarValidation
+	cleaned := make([]Bar, 0, len(bars))
+	var previous time.Time
+	for _, bar := range bars {
+		if bar.Open <= 0 || bar.High <= 0 || bar.Low <= 0 || bar.Close <= 0 {
+			v.NonPositive++
+			continue
+		}
+		if bar.High < bar.Low {
+			v.Inverted++
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, bar.Timestamp)
+		if err != nil || (!previous.IsZero() && !ts.Before(previous)) {
+			v.BadTimestamp++
+			continue
+		}
+		previous = ts
+		cleaned = append(cleaned, bar)
+	}
+	v.Kept = len(cleaned)
+	return cleaned, v
+}
+
+// ValidateBars with the configured limit: logs what was cleaned and fails with ErrMalformedBars past max_dropped_pct
+func cleanBars(symbol string, bars []Bar) ([]Bar, error) {
+	if !barValidation.enabled || len(bars) == 0 {
+		return bars, nil
+	}
+	cleaned, v := ValidateBars(bars)
+	if v.Dropped() == 0 {
+		return bars, nil
+	}
+	log.Printf("Cleaned %d of %d bars for %s (%d non-positive, %d high<low, %d bad timestamps)",
+		v.Dropped(), len(bars), symbol, v.NonPositive, v.Inverted, v.BadTimestamp)
+	if v.DroppedPct() > barValidation.maxDroppedPct {
+		return nil, fmt.Errorf("%w: %d of %d bars for %s (limit %.0f%%)", ErrMalformedBars, v.Dropped(), len(bars), symbol, barValidation.maxDroppedPct)
+	}
+	return cleaned, nil
+}
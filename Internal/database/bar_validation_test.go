@@ -0,0 +1,65 @@
+package datafeed
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+func validBar(ts string, close float64) Bar {
+	return Bar{Timestamp: ts, Open: close, High: close + 1, Low: close - 1, Close: close, Volume: 1000}
+}
+
+func TestValidateBars_DropsMalformedBars(t *testing.T) {
+	zeroClose := validBar("2024-03-07T05:00:00Z", 10)
+	zeroClose.Close = 0
+	inverted := validBar("2024-03-06T05:00:00Z", 10)
+	inverted.High, inverted.Low = 9, 11
+
+	bars := []Bar{
+		validBar("2024-03-08T05:00:00Z", 10),
+		zeroClose,
+		inverted,
+		validBar("2024-03-05T05:00:00Z", 10),
+		validBar("2024-03-05T05:00:00Z", 10), // duplicate
+		validBar("2024-03-06T05:00:00Z", 10), // newer than the bar before it
+		validBar("not a time", 10),
+		validBar("2024-03-04T05:00:00Z", 10),
+	}
+
+	cleaned, v := ValidateBars(bars)
+	if len(cleaned) != 3 || cleaned[0].Timestamp != "2024-03-08T05:00:00Z" || cleaned[2].Timestamp != "2024-03-04T05:00:00Z" {
+		t.Errorf("cleaned = %+v, want the 8th, 5th and 4th", cleaned)
+	}
+	if v.Kept != 3 || v.NonPositive != 1 || v.Inverted != 1 || v.BadTimestamp != 3 || v.Dropped() != 5 {
+		t.Errorf("validation = %+v, want 3 kept, 1 non-positive, 1 inverted, 3 bad timestamps", v)
+	}
+}
+
+func TestCleanBars_FailsPastDroppedLimit(t *testing.T) {
+	defer SetBarValidation(barValidation.enabled, barValidation.maxDroppedPct)
+	SetBarValidation(true, 20)
+
+	bars := make([]Bar, 0, 10)
+	for _, day := range []string{"10", "09", "08", "07", "06", "05", "04", "03", "02", "01"} {
+		bars = append(bars, validBar("2024-03-"+day+"T05:00:00Z", 10))
+	}
+
+	bars[4].Low = -1
+	cleaned, err := cleanBars("THIN", bars)
+	if err != nil || len(cleaned) != 9 {
+		t.Errorf("one bad bar in ten: %d bars, err %v, want 9 and no error", len(cleaned), err)
+	}
+
+	bars[5].Open, bars[6].High = 0, 1
+	_, err = cleanBars("THIN", bars)
+	if !errors.Is(err, ErrMalformedBars) || !errors.Is(err, utils.ErrInsufficientData) {
+		t.Errorf("three bad bars in ten: err = %v, want ErrMalformedBars", err)
+	}
+
+	SetBarValidation(false, 0)
+	if kept, err := cleanBars("THIN", bars); err != nil || len(kept) != 10 {
+		t.Errorf("validation off: %d bars, err %v, want all 10 untouched", len(kept), err)
+	}
+}
@@ -91,7 +91,108 @@ func initializeSchema() error {
 
 	CREATE INDEX IF NOT EXISTS idx_watchlist_symbol ON watchlist(symbol);
 	CREATE INDEX IF NOT EXISTS idx_watchlist_status ON watchlist(status);
-	
+
+	ALTER TABLE watchlist ADD COLUMN IF NOT EXISTS profile TEXT;
+	CREATE INDEX IF NOT EXISTS idx_watchlist_profile ON watchlist(profile);
+
+	ALTER TABLE watchlist ADD COLUMN IF NOT EXISTS technical_score REAL;
+	ALTER TABLE watchlist ADD COLUMN IF NOT EXISTS news_score REAL;
+	ALTER TABLE watchlist ADD COLUMN IF NOT EXISTS pattern_score REAL;
+	ALTER TABLE watchlist ADD COLUMN IF NOT EXISTS sr_score REAL;
+
+	CREATE TABLE IF NOT EXISTS alert_rules (
+		id SERIAL PRIMARY KEY,
+		name TEXT NOT NULL,
+		symbol TEXT,
+		conditions TEXT NOT NULL,
+		enabled BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_triggered_at TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS signal_history (
+		id SERIAL PRIMARY KEY,
+		symbol TEXT NOT NULL,
+		source TEXT NOT NULL DEFAULT 'scan',
+		timeframe TEXT NOT NULL DEFAULT '1Day',
+		recommendation TEXT NOT NULL,
+		confidence DOUBLE PRECISION NOT NULL,
+		ensemble_score DOUBLE PRECISION NOT NULL,
+		price DOUBLE PRECISION NOT NULL,
+		components TEXT NOT NULL,
+		computed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_signal_history_symbol_computed ON signal_history(symbol, computed_at DESC);
+
+	CREATE TABLE IF NOT EXISTS scan_runs (
+		id SERIAL PRIMARY KEY,
+		profile_name TEXT NOT NULL,
+		symbols_scanned INT NOT NULL DEFAULT 0,
+		candidates_found INT NOT NULL DEFAULT 0,
+		avg_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+		ran_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_scan_runs_ran_at ON scan_runs(ran_at);
+
+	CREATE TABLE IF NOT EXISTS portfolio_heat_snapshots (
+		id SERIAL PRIMARY KEY,
+		heat_percent DOUBLE PRECISION NOT NULL,
+		band TEXT NOT NULL,
+		risk_amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+		equity DOUBLE PRECISION NOT NULL DEFAULT 0,
+		open_positions INT NOT NULL DEFAULT 0,
+		recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_portfolio_heat_recorded_at ON portfolio_heat_snapshots(recorded_at);
+
+	CREATE TABLE IF NOT EXISTS problem_symbols (
+		symbol TEXT PRIMARY KEY,
+		consecutive_failures INT NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		flagged BOOLEAN NOT NULL DEFAULT FALSE,
+		flagged_at TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_problem_symbols_flagged ON problem_symbols(flagged);
+
+	CREATE TABLE IF NOT EXISTS backtests (
+		id TEXT PRIMARY KEY,
+		symbol TEXT NOT NULL,
+		start_date DATE NOT NULL,
+		end_date DATE NOT NULL,
+		initial_capital DOUBLE PRECISION NOT NULL,
+		final_balance DOUBLE PRECISION NOT NULL,
+		metrics JSONB NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_backtests_created_at ON backtests(created_at DESC);
+
+	CREATE TABLE IF NOT EXISTS sectors (
+		symbol TEXT PRIMARY KEY,
+		sector TEXT NOT NULL,
+		source TEXT NOT NULL DEFAULT '',
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS risk_events (
+		id SERIAL PRIMARY KEY,
+		timestamp TIMESTAMP NOT NULL,
+		event_type VARCHAR(50) NOT NULL,
+		severity VARCHAR(20) NOT NULL,
+		symbol VARCHAR(10),
+		details TEXT,
+		account_value DECIMAL(12, 4),
+		daily_loss DECIMAL(12, 4),
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_risk_events_timestamp ON risk_events(timestamp);
+
 	CREATE TABLE IF NOT EXISTS settings (
 		id SERIAL PRIMARY KEY,
 		setting_key VARCHAR(255) UNIQUE NOT NULL,
@@ -103,6 +204,19 @@ func initializeSchema() error {
 	);
 	
 	CREATE INDEX IF NOT EXISTS idx_settings_key ON settings(setting_key);
+
+	ALTER TABLE IF EXISTS trades ADD COLUMN IF NOT EXISTS imported BOOLEAN NOT NULL DEFAULT FALSE;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_trades_imported_order ON trades(alpaca_order_id) WHERE imported;
+	ALTER TABLE IF EXISTS trades ADD COLUMN IF NOT EXISTS expected_price DECIMAL(10, 4);
+	ALTER TABLE IF EXISTS trades ADD COLUMN IF NOT EXISTS slippage_bps DOUBLE PRECISION;
+	ALTER TABLE IF EXISTS trades ADD COLUMN IF NOT EXISTS order_type VARCHAR(20);
+
+	ALTER TABLE IF EXISTS rsi_calculation ADD COLUMN IF NOT EXISTS timeframe TEXT NOT NULL DEFAULT '1Day';
+	ALTER TABLE IF EXISTS rsi_calculation DROP CONSTRAINT IF EXISTS rsi_calculation_symbol_calculation_timestamp_key;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_rsi_symbol_timeframe_timestamp ON rsi_calculation(symbol, timeframe, calculation_timestamp);
+	ALTER TABLE IF EXISTS atr_calculation ADD COLUMN IF NOT EXISTS timeframe TEXT NOT NULL DEFAULT '1Day';
+	ALTER TABLE IF EXISTS atr_calculation DROP CONSTRAINT IF EXISTS atr_calculation_symbol_calculation_timestamp_key;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_atr_symbol_timeframe_timestamp ON atr_calculation(symbol, timeframe, calculation_timestamp);
 	
 	INSERT INTO settings (setting_key, setting_value, setting_type, is_encrypted) 
 	VALUES 
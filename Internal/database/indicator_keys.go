@@ -0,0 +1,17 @@
+package datafeed
+
+import "time"
+
+// stored indicators are keyed by the bar's instant in UTC, so a lookup never depends on the display timezone
+func IndicatorKey(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// the indicator key for a bar's RFC3339 timestamp; an unparseable timestamp is its own key
+func BarIndicatorKey(timestamp string) string {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return IndicatorKey(t)
+}
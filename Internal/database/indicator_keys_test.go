@@ -0,0 +1,57 @@
+package datafeed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIndicatorKey_MatchesBarsAcrossTimezones(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone data not available:", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("timezone data not available:", err)
+	}
+
+	// bar opens on both sides of the March DST switch, with their RSI as it was calculated
+	opens := []time.Time{
+		time.Date(2024, 3, 8, 9, 30, 0, 0, newYork),
+		time.Date(2024, 3, 11, 9, 30, 0, 0, newYork),
+		time.Date(2024, 3, 11, 15, 59, 0, 0, newYork),
+	}
+	rsiValues := []float64{41.5, 58.25, 63}
+
+	// SaveRSI writes the UTC instant and the zoneless column comes back with a zero offset
+	stored := make(map[string]float64)
+	for i, open := range opens {
+		saved := open.UTC()
+		readBack := time.Date(saved.Year(), saved.Month(), saved.Day(), saved.Hour(), saved.Minute(), saved.Second(), 0, time.FixedZone("", 0))
+		stored[IndicatorKey(readBack)] = rsiValues[i]
+	}
+
+	// the same bars as the feed might format them: exchange-local, converted for display, and UTC
+	for _, loc := range []*time.Location{newYork, tokyo, time.UTC} {
+		for i, open := range opens {
+			timestamp := open.In(loc).Format(time.RFC3339)
+			got, ok := stored[BarIndicatorKey(timestamp)]
+			if !ok {
+				t.Errorf("no RSI for bar %s (%s)", timestamp, loc)
+				continue
+			}
+			if got != rsiValues[i] {
+				t.Errorf("RSI for bar %s = %.2f, want %.2f", timestamp, got, rsiValues[i])
+			}
+		}
+	}
+}
+
+func TestBarIndicatorKey_UnparseableTimestamp(t *testing.T) {
+	if got := BarIndicatorKey("2024-03-08 09:30"); got != "2024-03-08 09:30" {
+		t.Errorf("key for an unparseable timestamp = %q, want it unchanged", got)
+	}
+	if got := BarIndicatorKey("2024-03-08T09:30:00-05:00"); got != "2024-03-08T14:30:00Z" {
+		t.Errorf("key = %q, want the UTC instant", got)
+	}
+}
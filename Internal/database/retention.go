@@ -0,0 +1,98 @@
+package datafeed
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+const defaultRetentionInterval = 24 * time.Hour
+
+// delete queries for tables with a retention policy (*database.Queries satisfies it)
+type RetentionStore interface {
+	PruneSignalHistory(ctx context.Context, computedAt time.Time) (int64, error)
+	PruneNewsArticles(ctx context.Context, publishedAt time.Time) (int64, error)
+	PruneWhaleEvents(ctx context.Context, timestamp time.Time) (int64, error)
+	PruneRiskEvents(ctx context.Context, timestamp time.Time) (int64, error)
+	PruneBacktests(ctx context.Context, createdAt time.Time) (int64, error)
+}
+
+var retentionPruners = map[string]func(RetentionStore, context.Context, time.Time) (int64, error){
+	"signal_history": RetentionStore.PruneSignalHistory,
+	"news_articles":  RetentionStore.PruneNewsArticles,
+	"whale_events":   RetentionStore.PruneWhaleEvents,
+	"risk_events":    RetentionStore.PruneRiskEvents,
+	"backtests":      RetentionStore.PruneBacktests,
+}
+
+// deletes rows older than each table's configured age and returns how many were pruned per table
+func PruneExpiredRows(ctx context.Context, store RetentionStore, cfg config.RetentionConfig, now time.Time) (map[string]int64, error) {
+	tables := make([]string, 0, len(cfg.Tables))
+	for table := range cfg.Tables {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	pruned := make(map[string]int64)
+	for _, table := range tables {
+		days := cfg.Tables[table]
+		if days <= 0 {
+			continue
+		}
+		prune, ok := retentionPruners[table]
+		if !ok {
+			return pruned, fmt.Errorf("no retention policy available for table %s", table)
+		}
+
+		cutoff := now.AddDate(0, 0, -days)
+		count, err := prune(store, ctx, cutoff)
+		if err != nil {
+			return pruned, fmt.Errorf("failed to prune %s: %w", table, err)
+		}
+		pruned[table] = count
+	}
+	return pruned, nil
+}
+
+// prunes expired rows on startup (if configured) and then every interval until ctx is cancelled
+func StartRetentionJob(ctx context.Context, store RetentionStore, cfg config.RetentionConfig) {
+	if !cfg.Enabled || store == nil {
+		return
+	}
+
+	interval := defaultRetentionInterval
+	if cfg.IntervalHours > 0 {
+		interval = time.Duration(cfg.IntervalHours) * time.Hour
+	}
+
+	if cfg.RunOnStartup {
+		runRetention(ctx, store, cfg)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Retention job stopped")
+			return
+		case <-ticker.C:
+			runRetention(ctx, store, cfg)
+		}
+	}
+}
+
+func runRetention(ctx context.Context, store RetentionStore, cfg config.RetentionConfig) {
+	pruned, err := PruneExpiredRows(ctx, store, cfg, time.Now())
+	for table, count := range pruned {
+		log.Printf("Retention: pruned %d rows from %s", count, table)
+	}
+	if err != nil {
+		log.Printf("Retention job error: %v", err)
+	}
+}
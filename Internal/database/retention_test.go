@@ -0,0 +1,117 @@
+package datafeed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+type fakeRetentionStore struct {
+	tables map[string][]time.Time
+}
+
+func (f *fakeRetentionStore) prune(table string, cutoff time.Time) int64 {
+	var kept []time.Time
+	var pruned int64
+	for _, ts := range f.tables[table] {
+		if ts.Before(cutoff) {
+			pruned++
+			continue
+		}
+		kept = append(kept, ts)
+	}
+	f.tables[table] = kept
+	return pruned
+}
+
+func (f *fakeRetentionStore) PruneSignalHistory(ctx context.Context, computedAt time.Time) (int64, error) {
+	return f.prune("signal_history", computedAt), nil
+}
+
+func (f *fakeRetentionStore) PruneNewsArticles(ctx context.Context, publishedAt time.Time) (int64, error) {
+	return f.prune("news_articles", publishedAt), nil
+}
+
+func (f *fakeRetentionStore) PruneWhaleEvents(ctx context.Context, timestamp time.Time) (int64, error) {
+	return f.prune("whale_events", timestamp), nil
+}
+
+func (f *fakeRetentionStore) PruneRiskEvents(ctx context.Context, timestamp time.Time) (int64, error) {
+	return f.prune("risk_events", timestamp), nil
+}
+
+func (f *fakeRetentionStore) PruneBacktests(ctx context.Context, createdAt time.Time) (int64, error) {
+	return f.prune("backtests", createdAt), nil
+}
+
+func TestPruneExpiredRows_KeepsRecentRows(t *testing.T) {
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+
+	store := &fakeRetentionStore{tables: map[string][]time.Time{
+		"signal_history": {daysAgo(120), daysAgo(91), daysAgo(89), daysAgo(1)},
+		"news_articles":  {daysAgo(45), daysAgo(10)},
+		"whale_events":   {daysAgo(400)},
+	}}
+	cfg := config.RetentionConfig{
+		Enabled: true,
+		Tables: map[string]int{
+			"signal_history": 90,
+			"news_articles":  30,
+			"whale_events":   0, // keep forever
+		},
+	}
+
+	pruned, err := PruneExpiredRows(context.Background(), store, cfg, now)
+	if err != nil {
+		t.Fatalf("PruneExpiredRows() error = %v", err)
+	}
+
+	if pruned["signal_history"] != 2 || pruned["news_articles"] != 1 {
+		t.Errorf("pruned = %v, want signal_history=2 news_articles=1", pruned)
+	}
+	if _, ok := pruned["whale_events"]; ok {
+		t.Errorf("whale_events has no retention and should not be pruned")
+	}
+	if len(store.tables["signal_history"]) != 2 || len(store.tables["news_articles"]) != 1 || len(store.tables["whale_events"]) != 1 {
+		t.Errorf("Unexpected remaining rows: %v", store.tables)
+	}
+	for _, ts := range store.tables["signal_history"] {
+		if ts.Before(daysAgo(90)) {
+			t.Errorf("Row from %v should have been pruned", ts)
+		}
+	}
+}
+
+func TestPruneExpiredRows_RiskEventsAndBacktests(t *testing.T) {
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+
+	store := &fakeRetentionStore{tables: map[string][]time.Time{
+		"risk_events": {daysAgo(200), daysAgo(100), daysAgo(5)},
+		"backtests":   {daysAgo(400), daysAgo(30)},
+	}}
+	cfg := config.RetentionConfig{Tables: map[string]int{"risk_events": 180, "backtests": 365}}
+
+	pruned, err := PruneExpiredRows(context.Background(), store, cfg, now)
+	if err != nil {
+		t.Fatalf("PruneExpiredRows() error = %v", err)
+	}
+	if pruned["risk_events"] != 1 || pruned["backtests"] != 1 {
+		t.Errorf("pruned = %v, want risk_events=1 backtests=1", pruned)
+	}
+	if len(store.tables["risk_events"]) != 2 || len(store.tables["backtests"]) != 1 {
+		t.Errorf("Unexpected remaining rows: %v", store.tables)
+	}
+}
+
+func TestPruneExpiredRows_UnknownTable(t *testing.T) {
+	store := &fakeRetentionStore{tables: map[string][]time.Time{}}
+	cfg := config.RetentionConfig{Tables: map[string]int{"trades": 30}}
+
+	if _, err := PruneExpiredRows(context.Background(), store, cfg, time.Now()); err == nil {
+		t.Errorf("Expected error for table without a retention policy")
+	}
+}
@@ -40,10 +40,13 @@ func FetchClosingPrices(symbol string, days int, timeframe string) ([]float64, e
 
 	return closingPrices, nil
 }
-func SaveRSI(symbol string, timestamp time.Time, rsiValue float64) error {
+
+// timestamps are stored in UTC; the column has no zone, so a local time would be saved as its wall clock
+func SaveRSI(symbol, timeframe string, timestamp time.Time, rsiValue float64) error {
 	params := database.SaveRSIParams{
 		Symbol:               symbol,
-		CalculationTimestamp: timestamp,
+		Timeframe:            timeframe,
+		CalculationTimestamp: timestamp.UTC(),
 		RsiValue:             float32(rsiValue),
 	}
 	ctx := context.Background()
@@ -82,10 +85,12 @@ func FetchPricePoints(symbol string, days int, timeframe string) ([]PricePoint,
 	return pricePoints, nil
 }
 
-func FetchRSIForDisplay(symbol string, limit int) (map[string]float64, error) {
+// latest stored values keyed by IndicatorKey; an empty timeframe matches every timeframe
+func FetchRSIForDisplay(symbol, timeframe string, limit int) (map[string]float64, error) {
 	params := database.GetRSIForDateRangeParams{
-		Symbol: symbol,
-		Limit:  int32(limit),
+		Symbol:    symbol,
+		Timeframe: timeframe,
+		Limit:     int32(limit),
 	}
 	ctx := context.Background()
 	rows, err := Queries.GetRSIForDateRange(ctx, params)
@@ -95,17 +100,18 @@ func FetchRSIForDisplay(symbol string, limit int) (map[string]float64, error) {
 
 	rsiMap := make(map[string]float64)
 	for _, row := range rows {
-		dateStr := row.CalculationTimestamp.Format("2006-01-02 15:04:05")
-		rsiMap[dateStr] = float64(row.RsiValue)
+		rsiMap[IndicatorKey(row.CalculationTimestamp)] = float64(row.RsiValue)
 	}
 	return rsiMap, nil
 }
 
-func FetchRSIByTimestampRange(symbol string, startTime, endTime time.Time) (map[string]float64, error) {
+// stored values for one timeframe within [startTime, endTime], keyed by IndicatorKey
+func FetchRSIByTimestampRange(symbol, timeframe string, startTime, endTime time.Time) (map[string]float64, error) {
 	params := database.GetRSIByTimestampRangeParams{
 		Symbol:                 symbol,
-		CalculationTimestamp:   startTime,
-		CalculationTimestamp_2: endTime,
+		Timeframe:              timeframe,
+		CalculationTimestamp:   startTime.UTC(),
+		CalculationTimestamp_2: endTime.UTC(),
 	}
 	ctx := context.Background()
 	rows, err := Queries.GetRSIByTimestampRange(ctx, params)
@@ -115,8 +121,7 @@ func FetchRSIByTimestampRange(symbol string, startTime, endTime time.Time) (map[
 
 	rsiMap := make(map[string]float64)
 	for _, row := range rows {
-		dateStr := row.CalculationTimestamp.Format("2006-01-02 15:04:05")
-		rsiMap[dateStr] = float64(row.RsiValue)
+		rsiMap[IndicatorKey(row.CalculationTimestamp)] = float64(row.RsiValue)
 	}
 	return rsiMap, nil
 }
@@ -160,7 +165,7 @@ func calculateRSI(closes []float64, period int) ([]float64, error) {
 	return rsi, nil
 }
 
-func CalculateAndStoreRSI(symbol string, bars []types.Bar) error {
+func CalculateAndStoreRSI(symbol, timeframe string, bars []types.Bar) error {
 	if len(bars) == 0 {
 		return nil
 	}
@@ -178,7 +183,7 @@ func CalculateAndStoreRSI(symbol string, bars []types.Bar) error {
 		if err != nil {
 			return err
 		}
-		err = SaveRSI(symbol, timestamp, rsiValues[i])
+		err = SaveRSI(symbol, timeframe, timestamp, rsiValues[i])
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,80 @@
+package datafeed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+// a filled trade with the price it was decided at, for execution-quality tracking
+type ExecutedTrade struct {
+	Symbol        string
+	Side          string // BUY/SELL or the LONG/SHORT direction the CLI logs
+	Quantity      int64
+	FillPrice     decimal.Decimal // Alpaca's FilledAvgPrice
+	ExpectedPrice decimal.Decimal // bar close / signal price when the trade was decided
+	OrderType     string          // market, limit, stop, ...
+	OrderID       string
+	Status        string
+}
+
+// slippage of a fill against the expected price in basis points. Positive is a cost (bought above or sold below
+// what was expected), negative is price improvement; 0 without an expected price
+func SlippageBps(side string, expected, filled float64) float64 {
+	if expected <= 0 || filled <= 0 {
+		return 0
+	}
+	bps := (filled - expected) / expected * 10000
+	switch strings.ToUpper(side) {
+	case "SELL", "SHORT":
+		return -bps
+	}
+	return bps
+}
+
+// logs the trade like LogTradeExecution, also storing the expected price, slippage and order type
+func LogTradeWithSlippage(ctx context.Context, trade ExecutedTrade) error {
+	if Queries == nil {
+		return fmt.Errorf("database queries not initialized")
+	}
+
+	slippage := SlippageBps(trade.Side, trade.ExpectedPrice.InexactFloat64(), trade.FillPrice.InexactFloat64())
+	err := Queries.LogTradeWithSlippage(ctx, database.LogTradeWithSlippageParams{
+		Symbol:        trade.Symbol,
+		Side:          trade.Side,
+		Quantity:      decimal.NewFromInt(trade.Quantity).String(),
+		Price:         trade.FillPrice.String(),
+		TotalValue:    decimal.NewFromInt(trade.Quantity).Mul(trade.FillPrice).String(),
+		AlpacaOrderID: sql.NullString{String: trade.OrderID, Valid: trade.OrderID != ""},
+		Status:        sql.NullString{String: trade.Status, Valid: true},
+		ExpectedPrice: sql.NullString{String: trade.ExpectedPrice.String(), Valid: trade.ExpectedPrice.IsPositive()},
+		SlippageBps:   sql.NullFloat64{Float64: slippage, Valid: trade.ExpectedPrice.IsPositive() && trade.FillPrice.IsPositive()},
+		OrderType:     sql.NullString{String: trade.OrderType, Valid: trade.OrderType != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to log trade: %w", err)
+	}
+
+	log.Printf("Trade logged: %s %s x%d filled %s vs expected %s (%.1f bps slippage, order %s)",
+		trade.Side, trade.Symbol, trade.Quantity, trade.FillPrice.String(), trade.ExpectedPrice.String(), slippage, trade.OrderID)
+	return nil
+}
+
+// per-symbol/order-type slippage rows rolled up into a fill-weighted average
+func AverageSlippage(rows []database.GetSlippageSummaryRow) (avgBps float64, fills int64) {
+	total := 0.0
+	for _, row := range rows {
+		total += row.AvgSlippageBps * float64(row.Fills)
+		fills += row.Fills
+	}
+	if fills == 0 {
+		return 0, 0
+	}
+	return total / float64(fills), fills
+}
@@ -0,0 +1,40 @@
+package datafeed
+
+import (
+	"math"
+	"testing"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+func TestSlippageBps(t *testing.T) {
+	cases := []struct {
+		name             string
+		side             string
+		expected, filled float64
+		want             float64
+	}{
+		{"buy filled above", "BUY", 100, 100.10, 10},
+		{"buy filled below", "BUY", 100, 99.95, -5},
+		{"sell filled below", "SELL", 50, 49.90, 20},
+		{"sell filled above", "SELL", 50, 50.05, -10},
+		{"long direction counts as a buy", "LONG", 200, 200.40, 20},
+		{"short direction counts as a sell", "SHORT", 200, 199.60, 20},
+		{"no expected price", "BUY", 0, 101, 0},
+	}
+	for _, tc := range cases {
+		if got := SlippageBps(tc.side, tc.expected, tc.filled); math.Abs(got-tc.want) > 1e-6 {
+			t.Errorf("%s: SlippageBps = %.4f, want %.4f", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestAverageSlippage_WeightsByFills(t *testing.T) {
+	avg, fills := AverageSlippage([]database.GetSlippageSummaryRow{
+		{Symbol: "AAPL", OrderType: "market", Fills: 3, AvgSlippageBps: 12},
+		{Symbol: "AAPL", OrderType: "limit", Fills: 1, AvgSlippageBps: -4},
+	})
+	if fills != 4 || avg != 8 {
+		t.Errorf("average = %.2f over %d fills, want 8 over 4", avg, fills)
+	}
+}
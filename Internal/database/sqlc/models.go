@@ -6,13 +6,36 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+type AlertRule struct {
+	ID              int32          `json:"id"`
+	Name            string         `json:"name"`
+	Symbol          sql.NullString `json:"symbol"`
+	Conditions      string         `json:"conditions"`
+	Enabled         bool           `json:"enabled"`
+	CreatedAt       sql.NullTime   `json:"created_at"`
+	LastTriggeredAt sql.NullTime   `json:"last_triggered_at"`
+}
+
 type AtrCalculation struct {
 	Symbol               string    `json:"symbol"`
 	CalculationTimestamp time.Time `json:"calculation_timestamp"`
 	AtrValue             float32   `json:"atr_value"`
+	Timeframe            string    `json:"timeframe"`
+}
+
+type Backtest struct {
+	ID             string          `json:"id"`
+	Symbol         string          `json:"symbol"`
+	StartDate      time.Time       `json:"start_date"`
+	EndDate        time.Time       `json:"end_date"`
+	InitialCapital float64         `json:"initial_capital"`
+	FinalBalance   float64         `json:"final_balance"`
+	Metrics        json.RawMessage `json:"metrics"`
+	CreatedAt      time.Time       `json:"created_at"`
 }
 
 type CandleDailyBollinger struct {
@@ -88,6 +111,15 @@ type PortfolioHistory struct {
 	CreatedAt      sql.NullTime   `json:"created_at"`
 }
 
+type ProblemSymbol struct {
+	Symbol              string       `json:"symbol"`
+	ConsecutiveFailures int32        `json:"consecutive_failures"`
+	LastError           string       `json:"last_error"`
+	Flagged             bool         `json:"flagged"`
+	FlaggedAt           sql.NullTime `json:"flagged_at"`
+	UpdatedAt           time.Time    `json:"updated_at"`
+}
+
 type Position struct {
 	ID            int32          `json:"id"`
 	Symbol        string         `json:"symbol"`
@@ -99,10 +131,23 @@ type Position struct {
 	UpdatedAt     sql.NullTime   `json:"updated_at"`
 }
 
+type RiskEvent struct {
+	ID           int32          `json:"id"`
+	Timestamp    time.Time      `json:"timestamp"`
+	EventType    string         `json:"event_type"`
+	Severity     string         `json:"severity"`
+	Symbol       sql.NullString `json:"symbol"`
+	Details      sql.NullString `json:"details"`
+	AccountValue sql.NullString `json:"account_value"`
+	DailyLoss    sql.NullString `json:"daily_loss"`
+	CreatedAt    sql.NullTime   `json:"created_at"`
+}
+
 type RsiCalculation struct {
 	Symbol               string    `json:"symbol"`
 	CalculationTimestamp time.Time `json:"calculation_timestamp"`
 	RsiValue             float32   `json:"rsi_value"`
+	Timeframe            string    `json:"timeframe"`
 }
 
 type ScanLog struct {
@@ -115,6 +160,25 @@ type ScanLog struct {
 	UpdatedAt         sql.NullTime  `json:"updated_at"`
 }
 
+type PortfolioHeatSnapshot struct {
+	ID            int32     `json:"id"`
+	HeatPercent   float64   `json:"heat_percent"`
+	Band          string    `json:"band"`
+	RiskAmount    float64   `json:"risk_amount"`
+	Equity        float64   `json:"equity"`
+	OpenPositions int32     `json:"open_positions"`
+	RecordedAt    time.Time `json:"recorded_at"`
+}
+
+type ScanRun struct {
+	ID              int32     `json:"id"`
+	ProfileName     string    `json:"profile_name"`
+	SymbolsScanned  int32     `json:"symbols_scanned"`
+	CandidatesFound int32     `json:"candidates_found"`
+	AvgScore        float64   `json:"avg_score"`
+	RanAt           time.Time `json:"ran_at"`
+}
+
 type ScoutList struct {
 	ID           int32          `json:"id"`
 	Symbol       string         `json:"symbol"`
@@ -136,6 +200,13 @@ type ScoutSkipList struct {
 	RecheckAfter time.Time      `json:"recheck_after"`
 }
 
+type Sector struct {
+	Symbol    string    `json:"symbol"`
+	Sector    string    `json:"sector"`
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type Setting struct {
 	ID           int32          `json:"id"`
 	SettingKey   string         `json:"setting_key"`
@@ -157,6 +228,19 @@ type Signal struct {
 	Executed     sql.NullBool   `json:"executed"`
 }
 
+type SignalHistory struct {
+	ID             int32     `json:"id"`
+	Symbol         string    `json:"symbol"`
+	Source         string    `json:"source"`
+	Timeframe      string    `json:"timeframe"`
+	Recommendation string    `json:"recommendation"`
+	Confidence     float64   `json:"confidence"`
+	EnsembleScore  float64   `json:"ensemble_score"`
+	Price          float64   `json:"price"`
+	Components     string    `json:"components"`
+	ComputedAt     time.Time `json:"computed_at"`
+}
+
 type SkipBacklog struct {
 	ID           int32          `json:"id"`
 	Symbol       string         `json:"symbol"`
@@ -176,29 +260,38 @@ type SupportLevel struct {
 }
 
 type Trade struct {
-	ID            int32          `json:"id"`
-	SignalID      sql.NullInt32  `json:"signal_id"`
-	Symbol        string         `json:"symbol"`
-	Side          string         `json:"side"`
-	Quantity      string         `json:"quantity"`
-	Price         string         `json:"price"`
-	TotalValue    string         `json:"total_value"`
-	Commission    sql.NullString `json:"commission"`
-	AlpacaOrderID sql.NullString `json:"alpaca_order_id"`
-	Status        sql.NullString `json:"status"`
-	CreatedAt     sql.NullTime   `json:"created_at"`
-	FilledAt      sql.NullTime   `json:"filled_at"`
+	ID            int32           `json:"id"`
+	SignalID      sql.NullInt32   `json:"signal_id"`
+	Symbol        string          `json:"symbol"`
+	Side          string          `json:"side"`
+	Quantity      string          `json:"quantity"`
+	Price         string          `json:"price"`
+	TotalValue    string          `json:"total_value"`
+	Commission    sql.NullString  `json:"commission"`
+	AlpacaOrderID sql.NullString  `json:"alpaca_order_id"`
+	Status        sql.NullString  `json:"status"`
+	CreatedAt     sql.NullTime    `json:"created_at"`
+	FilledAt      sql.NullTime    `json:"filled_at"`
+	Imported      bool            `json:"imported"`
+	ExpectedPrice sql.NullString  `json:"expected_price"`
+	SlippageBps   sql.NullFloat64 `json:"slippage_bps"`
+	OrderType     sql.NullString  `json:"order_type"`
 }
 
 type Watchlist struct {
-	ID          int32          `json:"id"`
-	Symbol      string         `json:"symbol"`
-	AssetType   string         `json:"asset_type"`
-	Score       float32        `json:"score"`
-	Reason      sql.NullString `json:"reason"`
-	AddedDate   sql.NullTime   `json:"added_date"`
-	LastUpdated sql.NullTime   `json:"last_updated"`
-	Status      sql.NullString `json:"status"`
+	ID             int32           `json:"id"`
+	Symbol         string          `json:"symbol"`
+	AssetType      string          `json:"asset_type"`
+	Score          float32         `json:"score"`
+	Reason         sql.NullString  `json:"reason"`
+	AddedDate      sql.NullTime    `json:"added_date"`
+	LastUpdated    sql.NullTime    `json:"last_updated"`
+	Status         sql.NullString  `json:"status"`
+	Profile        sql.NullString  `json:"profile"`
+	TechnicalScore sql.NullFloat64 `json:"technical_score"`
+	NewsScore      sql.NullFloat64 `json:"news_score"`
+	PatternScore   sql.NullFloat64 `json:"pattern_score"`
+	SrScore        sql.NullFloat64 `json:"sr_score"`
 }
 
 type WatchlistHistory struct {
@@ -8,11 +8,41 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/lib/pq"
 )
 
+const addScanCandidateToWatchlist = `-- name: AddScanCandidateToWatchlist :one
+INSERT INTO watchlist (symbol, asset_type, score, reason, added_date, last_updated, status, profile)
+VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'active', $5)
+ON CONFLICT (symbol) DO NOTHING
+RETURNING id
+`
+
+type AddScanCandidateToWatchlistParams struct {
+	Symbol    string         `json:"symbol"`
+	AssetType string         `json:"asset_type"`
+	Score     float32        `json:"score"`
+	Reason    sql.NullString `json:"reason"`
+	Profile   sql.NullString `json:"profile"`
+}
+
+// Add a scan candidate tagged with its profile, skipping symbols already tracked
+func (q *Queries) AddScanCandidateToWatchlist(ctx context.Context, arg AddScanCandidateToWatchlistParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, addScanCandidateToWatchlist,
+		arg.Symbol,
+		arg.AssetType,
+		arg.Score,
+		arg.Reason,
+		arg.Profile,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
 const addToScoutSkipList = `-- name: AddToScoutSkipList :exec
 INSERT INTO scout_skip_list (symbol, profile_name, asset_type, reason, recheck_after)
 VALUES ($1, $2, $3, $4, NOW() + INTERVAL '2 days')
@@ -100,6 +130,52 @@ func (q *Queries) ArchiveOldWatchlist(ctx context.Context) error {
 	return err
 }
 
+const clearProblemSymbol = `-- name: ClearProblemSymbol :execrows
+DELETE FROM problem_symbols WHERE symbol = $1
+`
+
+func (q *Queries) ClearProblemSymbol(ctx context.Context, symbol string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, clearProblemSymbol, symbol)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createAlertRule = `-- name: CreateAlertRule :one
+INSERT INTO alert_rules (name, symbol, conditions, enabled)
+VALUES ($1, $2, $3, $4)
+RETURNING id, name, symbol, conditions, enabled, created_at, last_triggered_at
+`
+
+type CreateAlertRuleParams struct {
+	Name       string         `json:"name"`
+	Symbol     sql.NullString `json:"symbol"`
+	Conditions string         `json:"conditions"`
+	Enabled    bool           `json:"enabled"`
+}
+
+// Create a user-defined alert rule
+func (q *Queries) CreateAlertRule(ctx context.Context, arg CreateAlertRuleParams) (AlertRule, error) {
+	row := q.db.QueryRowContext(ctx, createAlertRule,
+		arg.Name,
+		arg.Symbol,
+		arg.Conditions,
+		arg.Enabled,
+	)
+	var i AlertRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Symbol,
+		&i.Conditions,
+		&i.Enabled,
+		&i.CreatedAt,
+		&i.LastTriggeredAt,
+	)
+	return i, err
+}
+
 const createWhaleEvent = `-- name: CreateWhaleEvent :exec
 INSERT INTO whale_events (
     symbol, timestamp, direction, volume, z_score, close_price, price_change, conviction
@@ -131,6 +207,76 @@ func (q *Queries) CreateWhaleEvent(ctx context.Context, arg CreateWhaleEventPara
 	return err
 }
 
+const deleteAlertRule = `-- name: DeleteAlertRule :exec
+DELETE FROM alert_rules WHERE id = $1
+`
+
+// Delete an alert rule
+func (q *Queries) DeleteAlertRule(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteAlertRule, id)
+	return err
+}
+
+const getAlertRule = `-- name: GetAlertRule :one
+SELECT id, name, symbol, conditions, enabled, created_at, last_triggered_at
+FROM alert_rules
+WHERE id = $1
+`
+
+// Get a single alert rule by id
+func (q *Queries) GetAlertRule(ctx context.Context, id int32) (AlertRule, error) {
+	row := q.db.QueryRowContext(ctx, getAlertRule, id)
+	var i AlertRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Symbol,
+		&i.Conditions,
+		&i.Enabled,
+		&i.CreatedAt,
+		&i.LastTriggeredAt,
+	)
+	return i, err
+}
+
+const getAlertRules = `-- name: GetAlertRules :many
+SELECT id, name, symbol, conditions, enabled, created_at, last_triggered_at
+FROM alert_rules
+ORDER BY id
+`
+
+// List all alert rules
+func (q *Queries) GetAlertRules(ctx context.Context) ([]AlertRule, error) {
+	rows, err := q.db.QueryContext(ctx, getAlertRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AlertRule
+	for rows.Next() {
+		var i AlertRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Symbol,
+			&i.Conditions,
+			&i.Enabled,
+			&i.CreatedAt,
+			&i.LastTriggeredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getATR = `-- name: GetATR :one
 SELECT atr_value, calculation_timestamp
 FROM atr_calculation
@@ -155,13 +301,15 @@ const getATRByTimestampRange = `-- name: GetATRByTimestampRange :many
 SELECT calculation_timestamp, atr_value
 FROM atr_calculation
 WHERE symbol = $1
-  AND calculation_timestamp >= $2
-  AND calculation_timestamp <= $3
+  AND timeframe = $2
+  AND calculation_timestamp >= $3
+  AND calculation_timestamp <= $4
 ORDER BY calculation_timestamp ASC
 `
 
 type GetATRByTimestampRangeParams struct {
 	Symbol                 string    `json:"symbol"`
+	Timeframe              string    `json:"timeframe"`
 	CalculationTimestamp   time.Time `json:"calculation_timestamp"`
 	CalculationTimestamp_2 time.Time `json:"calculation_timestamp_2"`
 }
@@ -172,7 +320,7 @@ type GetATRByTimestampRangeRow struct {
 }
 
 func (q *Queries) GetATRByTimestampRange(ctx context.Context, arg GetATRByTimestampRangeParams) ([]GetATRByTimestampRangeRow, error) {
-	rows, err := q.db.QueryContext(ctx, getATRByTimestampRange, arg.Symbol, arg.CalculationTimestamp, arg.CalculationTimestamp_2)
+	rows, err := q.db.QueryContext(ctx, getATRByTimestampRange, arg.Symbol, arg.Timeframe, arg.CalculationTimestamp, arg.CalculationTimestamp_2)
 	if err != nil {
 		return nil, err
 	}
@@ -198,13 +346,15 @@ const getATRForDateRange = `-- name: GetATRForDateRange :many
 SELECT calculation_timestamp, atr_value
 FROM atr_calculation
 WHERE symbol = $1
+  AND ($2::text = '' OR timeframe = $2)
 ORDER BY calculation_timestamp DESC
-LIMIT $2
+LIMIT $3
 `
 
 type GetATRForDateRangeParams struct {
-	Symbol string `json:"symbol"`
-	Limit  int32  `json:"limit"`
+	Symbol    string `json:"symbol"`
+	Timeframe string `json:"timeframe"`
+	Limit     int32  `json:"limit"`
 }
 
 type GetATRForDateRangeRow struct {
@@ -213,7 +363,7 @@ type GetATRForDateRangeRow struct {
 }
 
 func (q *Queries) GetATRForDateRange(ctx context.Context, arg GetATRForDateRangeParams) ([]GetATRForDateRangeRow, error) {
-	rows, err := q.db.QueryContext(ctx, getATRForDateRange, arg.Symbol, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getATRForDateRange, arg.Symbol, arg.Timeframe, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -426,6 +576,28 @@ func (q *Queries) GetAllTrades(ctx context.Context) ([]GetAllTradesRow, error) {
 	return items, nil
 }
 
+const getBacktestByID = `-- name: GetBacktestByID :one
+SELECT id, symbol, start_date, end_date, initial_capital, final_balance, metrics, created_at
+FROM backtests
+WHERE id = $1
+`
+
+func (q *Queries) GetBacktestByID(ctx context.Context, id string) (Backtest, error) {
+	row := q.db.QueryRowContext(ctx, getBacktestByID, id)
+	var i Backtest
+	err := row.Scan(
+		&i.ID,
+		&i.Symbol,
+		&i.StartDate,
+		&i.EndDate,
+		&i.InitialCapital,
+		&i.FinalBalance,
+		&i.Metrics,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getClosingPrices = `-- name: GetClosingPrices :many
 SELECT close_price, timestamp
 FROM historical_bars
@@ -469,6 +641,126 @@ func (q *Queries) GetClosingPrices(ctx context.Context, arg GetClosingPricesPara
 	return items, nil
 }
 
+const getEnabledAlertRules = `-- name: GetEnabledAlertRules :many
+SELECT id, name, symbol, conditions, enabled, created_at, last_triggered_at
+FROM alert_rules
+WHERE enabled = TRUE
+ORDER BY id
+`
+
+// List alert rules evaluated on each scanner tick
+func (q *Queries) GetEnabledAlertRules(ctx context.Context) ([]AlertRule, error) {
+	rows, err := q.db.QueryContext(ctx, getEnabledAlertRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AlertRule
+	for rows.Next() {
+		var i AlertRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Symbol,
+			&i.Conditions,
+			&i.Enabled,
+			&i.CreatedAt,
+			&i.LastTriggeredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFlaggedSymbols = `-- name: GetFlaggedSymbols :many
+SELECT symbol, consecutive_failures, last_error, flagged, flagged_at, updated_at
+FROM problem_symbols
+WHERE flagged
+ORDER BY flagged_at DESC, symbol
+`
+
+func (q *Queries) GetFlaggedSymbols(ctx context.Context) ([]ProblemSymbol, error) {
+	rows, err := q.db.QueryContext(ctx, getFlaggedSymbols)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProblemSymbol
+	for rows.Next() {
+		var i ProblemSymbol
+		if err := rows.Scan(
+			&i.Symbol,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.Flagged,
+			&i.FlaggedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getHeatSnapshots = `-- name: GetHeatSnapshots :many
+SELECT id, heat_percent, band, risk_amount, equity, open_positions, recorded_at
+FROM portfolio_heat_snapshots
+WHERE recorded_at >= $1 AND recorded_at < $2
+ORDER BY recorded_at
+`
+
+type GetHeatSnapshotsParams struct {
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+}
+
+// Heat snapshots in [from, to), oldest first
+func (q *Queries) GetHeatSnapshots(ctx context.Context, arg GetHeatSnapshotsParams) ([]PortfolioHeatSnapshot, error) {
+	rows, err := q.db.QueryContext(ctx, getHeatSnapshots, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PortfolioHeatSnapshot
+	for rows.Next() {
+		var i PortfolioHeatSnapshot
+		if err := rows.Scan(
+			&i.ID,
+			&i.HeatPercent,
+			&i.Band,
+			&i.RiskAmount,
+			&i.Equity,
+			&i.OpenPositions,
+			&i.RecordedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getHighConvictionWhales = `-- name: GetHighConvictionWhales :many
 SELECT id, symbol, timestamp, direction, volume, z_score, close_price, price_change, conviction, created_at FROM whale_events
 WHERE symbol = $1 AND conviction = 'HIGH'
@@ -654,17 +946,61 @@ func (q *Queries) GetNewsForScreener(ctx context.Context, dollar_1 []string) ([]
 	return items, nil
 }
 
+const getRecentSignals = `-- name: GetRecentSignals :many
+SELECT id, symbol, source, timeframe, recommendation, confidence, ensemble_score, price, components, computed_at
+FROM signal_history
+ORDER BY computed_at DESC
+LIMIT $1
+`
+
+// Get the most recently persisted signals across all symbols
+func (q *Queries) GetRecentSignals(ctx context.Context, limit int32) ([]SignalHistory, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentSignals, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SignalHistory
+	for rows.Next() {
+		var i SignalHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.Symbol,
+			&i.Source,
+			&i.Timeframe,
+			&i.Recommendation,
+			&i.Confidence,
+			&i.EnsembleScore,
+			&i.Price,
+			&i.Components,
+			&i.ComputedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRSIByTimestampRange = `-- name: GetRSIByTimestampRange :many
 SELECT calculation_timestamp, rsi_value
 FROM rsi_calculation
 WHERE symbol = $1
-  AND calculation_timestamp >= $2
-  AND calculation_timestamp <= $3
+  AND timeframe = $2
+  AND calculation_timestamp >= $3
+  AND calculation_timestamp <= $4
 ORDER BY calculation_timestamp ASC
 `
 
 type GetRSIByTimestampRangeParams struct {
 	Symbol                 string    `json:"symbol"`
+	Timeframe              string    `json:"timeframe"`
 	CalculationTimestamp   time.Time `json:"calculation_timestamp"`
 	CalculationTimestamp_2 time.Time `json:"calculation_timestamp_2"`
 }
@@ -675,7 +1011,7 @@ type GetRSIByTimestampRangeRow struct {
 }
 
 func (q *Queries) GetRSIByTimestampRange(ctx context.Context, arg GetRSIByTimestampRangeParams) ([]GetRSIByTimestampRangeRow, error) {
-	rows, err := q.db.QueryContext(ctx, getRSIByTimestampRange, arg.Symbol, arg.CalculationTimestamp, arg.CalculationTimestamp_2)
+	rows, err := q.db.QueryContext(ctx, getRSIByTimestampRange, arg.Symbol, arg.Timeframe, arg.CalculationTimestamp, arg.CalculationTimestamp_2)
 	if err != nil {
 		return nil, err
 	}
@@ -701,13 +1037,15 @@ const getRSIForDateRange = `-- name: GetRSIForDateRange :many
 SELECT calculation_timestamp, rsi_value
 FROM rsi_calculation
 WHERE symbol = $1
+  AND ($2::text = '' OR timeframe = $2)
 ORDER BY calculation_timestamp DESC
-LIMIT $2
+LIMIT $3
 `
 
 type GetRSIForDateRangeParams struct {
-	Symbol string `json:"symbol"`
-	Limit  int32  `json:"limit"`
+	Symbol    string `json:"symbol"`
+	Timeframe string `json:"timeframe"`
+	Limit     int32  `json:"limit"`
 }
 
 type GetRSIForDateRangeRow struct {
@@ -716,7 +1054,7 @@ type GetRSIForDateRangeRow struct {
 }
 
 func (q *Queries) GetRSIForDateRange(ctx context.Context, arg GetRSIForDateRangeParams) ([]GetRSIForDateRangeRow, error) {
-	rows, err := q.db.QueryContext(ctx, getRSIForDateRange, arg.Symbol, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getRSIForDateRange, arg.Symbol, arg.Timeframe, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -797,52 +1135,37 @@ func (q *Queries) GetScanLog(ctx context.Context, profileName string) (ScanLog,
 	return i, err
 }
 
-const getTradeHistory = `-- name: GetTradeHistory :many
-SELECT id, symbol, side, quantity, price, total_value, alpaca_order_id, status, created_at, filled_at
-FROM trades
-WHERE symbol = $1
-ORDER BY created_at DESC
-LIMIT $2
+const getScanRuns = `-- name: GetScanRuns :many
+SELECT id, profile_name, symbols_scanned, candidates_found, avg_score, ran_at
+FROM scan_runs
+WHERE ran_at >= $1 AND ran_at < $2
+  AND ($3::text = '' OR profile_name = $3::text)
+ORDER BY ran_at
 `
 
-type GetTradeHistoryParams struct {
-	Symbol string `json:"symbol"`
-	Limit  int32  `json:"limit"`
-}
-
-type GetTradeHistoryRow struct {
-	ID            int32          `json:"id"`
-	Symbol        string         `json:"symbol"`
-	Side          string         `json:"side"`
-	Quantity      string         `json:"quantity"`
-	Price         string         `json:"price"`
-	TotalValue    string         `json:"total_value"`
-	AlpacaOrderID sql.NullString `json:"alpaca_order_id"`
-	Status        sql.NullString `json:"status"`
-	CreatedAt     sql.NullTime   `json:"created_at"`
-	FilledAt      sql.NullTime   `json:"filled_at"`
+type GetScanRunsParams struct {
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+	Profile  string    `json:"profile"`
 }
 
-func (q *Queries) GetTradeHistory(ctx context.Context, arg GetTradeHistoryParams) ([]GetTradeHistoryRow, error) {
-	rows, err := q.db.QueryContext(ctx, getTradeHistory, arg.Symbol, arg.Limit)
+// Scan runs in [from, to), oldest first; an empty profile matches every profile
+func (q *Queries) GetScanRuns(ctx context.Context, arg GetScanRunsParams) ([]ScanRun, error) {
+	rows, err := q.db.QueryContext(ctx, getScanRuns, arg.FromTime, arg.ToTime, arg.Profile)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetTradeHistoryRow
+	var items []ScanRun
 	for rows.Next() {
-		var i GetTradeHistoryRow
+		var i ScanRun
 		if err := rows.Scan(
 			&i.ID,
-			&i.Symbol,
-			&i.Side,
-			&i.Quantity,
-			&i.Price,
-			&i.TotalValue,
-			&i.AlpacaOrderID,
-			&i.Status,
-			&i.CreatedAt,
-			&i.FilledAt,
+			&i.ProfileName,
+			&i.SymbolsScanned,
+			&i.CandidatesFound,
+			&i.AvgScore,
+			&i.RanAt,
 		); err != nil {
 			return nil, err
 		}
@@ -857,20 +1180,199 @@ func (q *Queries) GetTradeHistory(ctx context.Context, arg GetTradeHistoryParams
 	return items, nil
 }
 
-const getWatchlist = `-- name: GetWatchlist :many
-SELECT id, symbol, asset_type, score, reason, added_date, last_updated
-FROM watchlist
-ORDER BY score DESC
+const getSlippageSummary = `-- name: GetSlippageSummary :many
+SELECT symbol,
+       COALESCE(order_type, 'market')::TEXT AS order_type,
+       COUNT(*) AS fills,
+       AVG(slippage_bps)::DOUBLE PRECISION AS avg_slippage_bps,
+       MAX(slippage_bps)::DOUBLE PRECISION AS worst_slippage_bps
+FROM trades
+WHERE slippage_bps IS NOT NULL AND created_at >= $1 AND created_at < $2
+GROUP BY symbol, COALESCE(order_type, 'market')
+ORDER BY avg_slippage_bps DESC
 `
 
-type GetWatchlistRow struct {
-	ID          int32          `json:"id"`
-	Symbol      string         `json:"symbol"`
-	AssetType   string         `json:"asset_type"`
-	Score       float32        `json:"score"`
-	Reason      sql.NullString `json:"reason"`
-	AddedDate   sql.NullTime   `json:"added_date"`
-	LastUpdated sql.NullTime   `json:"last_updated"`
+type GetSlippageSummaryParams struct {
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+}
+
+type GetSlippageSummaryRow struct {
+	Symbol           string  `json:"symbol"`
+	OrderType        string  `json:"order_type"`
+	Fills            int64   `json:"fills"`
+	AvgSlippageBps   float64 `json:"avg_slippage_bps"`
+	WorstSlippageBps float64 `json:"worst_slippage_bps"`
+}
+
+// Average and worst slippage per symbol and order type for trades logged in [from, to), costliest first
+func (q *Queries) GetSlippageSummary(ctx context.Context, arg GetSlippageSummaryParams) ([]GetSlippageSummaryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSlippageSummary, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSlippageSummaryRow
+	for rows.Next() {
+		var i GetSlippageSummaryRow
+		if err := rows.Scan(
+			&i.Symbol,
+			&i.OrderType,
+			&i.Fills,
+			&i.AvgSlippageBps,
+			&i.WorstSlippageBps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSignalHistory = `-- name: GetSignalHistory :many
+SELECT id, symbol, source, timeframe, recommendation, confidence, ensemble_score, price, components, computed_at
+FROM signal_history
+WHERE symbol = $1
+ORDER BY computed_at DESC
+LIMIT $2
+`
+
+type GetSignalHistoryParams struct {
+	Symbol string `json:"symbol"`
+	Limit  int32  `json:"limit"`
+}
+
+// Get persisted signals for a symbol, newest first
+func (q *Queries) GetSignalHistory(ctx context.Context, arg GetSignalHistoryParams) ([]SignalHistory, error) {
+	rows, err := q.db.QueryContext(ctx, getSignalHistory, arg.Symbol, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SignalHistory
+	for rows.Next() {
+		var i SignalHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.Symbol,
+			&i.Source,
+			&i.Timeframe,
+			&i.Recommendation,
+			&i.Confidence,
+			&i.EnsembleScore,
+			&i.Price,
+			&i.Components,
+			&i.ComputedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSymbolSector = `-- name: GetSymbolSector :one
+SELECT sector FROM sectors WHERE symbol = $1
+`
+
+func (q *Queries) GetSymbolSector(ctx context.Context, symbol string) (string, error) {
+	row := q.db.QueryRowContext(ctx, getSymbolSector, symbol)
+	var sector string
+	err := row.Scan(&sector)
+	return sector, err
+}
+
+const getTradeHistory = `-- name: GetTradeHistory :many
+SELECT id, symbol, side, quantity, price, total_value, alpaca_order_id, status, created_at, filled_at
+FROM trades
+WHERE symbol = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type GetTradeHistoryParams struct {
+	Symbol string `json:"symbol"`
+	Limit  int32  `json:"limit"`
+}
+
+type GetTradeHistoryRow struct {
+	ID            int32          `json:"id"`
+	Symbol        string         `json:"symbol"`
+	Side          string         `json:"side"`
+	Quantity      string         `json:"quantity"`
+	Price         string         `json:"price"`
+	TotalValue    string         `json:"total_value"`
+	AlpacaOrderID sql.NullString `json:"alpaca_order_id"`
+	Status        sql.NullString `json:"status"`
+	CreatedAt     sql.NullTime   `json:"created_at"`
+	FilledAt      sql.NullTime   `json:"filled_at"`
+}
+
+func (q *Queries) GetTradeHistory(ctx context.Context, arg GetTradeHistoryParams) ([]GetTradeHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTradeHistory, arg.Symbol, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTradeHistoryRow
+	for rows.Next() {
+		var i GetTradeHistoryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Symbol,
+			&i.Side,
+			&i.Quantity,
+			&i.Price,
+			&i.TotalValue,
+			&i.AlpacaOrderID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.FilledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWatchlist = `-- name: GetWatchlist :many
+SELECT id, symbol, asset_type, score, reason, added_date, last_updated,
+       technical_score, news_score, pattern_score, sr_score
+FROM watchlist
+ORDER BY score DESC
+`
+
+type GetWatchlistRow struct {
+	ID             int32           `json:"id"`
+	Symbol         string          `json:"symbol"`
+	AssetType      string          `json:"asset_type"`
+	Score          float32         `json:"score"`
+	Reason         sql.NullString  `json:"reason"`
+	AddedDate      sql.NullTime    `json:"added_date"`
+	LastUpdated    sql.NullTime    `json:"last_updated"`
+	TechnicalScore sql.NullFloat64 `json:"technical_score"`
+	NewsScore      sql.NullFloat64 `json:"news_score"`
+	PatternScore   sql.NullFloat64 `json:"pattern_score"`
+	SrScore        sql.NullFloat64 `json:"sr_score"`
 }
 
 // Get all watchlist items, ordered by score
@@ -883,6 +1385,59 @@ func (q *Queries) GetWatchlist(ctx context.Context) ([]GetWatchlistRow, error) {
 	var items []GetWatchlistRow
 	for rows.Next() {
 		var i GetWatchlistRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Symbol,
+			&i.AssetType,
+			&i.Score,
+			&i.Reason,
+			&i.AddedDate,
+			&i.LastUpdated,
+			&i.TechnicalScore,
+			&i.NewsScore,
+			&i.PatternScore,
+			&i.SrScore,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWatchlistByProfile = `-- name: GetWatchlistByProfile :many
+SELECT id, symbol, asset_type, score, reason, added_date, last_updated
+FROM watchlist
+WHERE profile = $1 AND status = 'active'
+ORDER BY score DESC
+`
+
+type GetWatchlistByProfileRow struct {
+	ID          int32          `json:"id"`
+	Symbol      string         `json:"symbol"`
+	AssetType   string         `json:"asset_type"`
+	Score       float32        `json:"score"`
+	Reason      sql.NullString `json:"reason"`
+	AddedDate   sql.NullTime   `json:"added_date"`
+	LastUpdated sql.NullTime   `json:"last_updated"`
+}
+
+// Get active watchlist items added by a scan profile
+func (q *Queries) GetWatchlistByProfile(ctx context.Context, profile sql.NullString) ([]GetWatchlistByProfileRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWatchlistByProfile, profile)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWatchlistByProfileRow
+	for rows.Next() {
+		var i GetWatchlistByProfileRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Symbol,
@@ -983,6 +1538,145 @@ func (q *Queries) GetWhaleEventsBySymbol(ctx context.Context, arg GetWhaleEvents
 	return items, nil
 }
 
+const importTrade = `-- name: ImportTrade :execrows
+INSERT INTO trades (symbol, side, quantity, price, total_value, alpaca_order_id, status, created_at, filled_at, imported)
+SELECT $1::varchar, $2::varchar, $3::decimal, $4::decimal, $5::decimal, $6::varchar, 'FILLED', $7::timestamp, $7::timestamp, TRUE
+WHERE NOT EXISTS (SELECT 1 FROM trades WHERE alpaca_order_id = $6::varchar)
+ON CONFLICT DO NOTHING
+`
+
+type ImportTradeParams struct {
+	Symbol        string         `json:"symbol"`
+	Side          string         `json:"side"`
+	Quantity      string         `json:"quantity"`
+	Price         string         `json:"price"`
+	TotalValue    string         `json:"total_value"`
+	AlpacaOrderID sql.NullString `json:"alpaca_order_id"`
+	CreatedAt     sql.NullTime   `json:"created_at"`
+}
+
+// Inserts a historical fill from Alpaca, skipping order IDs already in trades whether imported or app-logged
+func (q *Queries) ImportTrade(ctx context.Context, arg ImportTradeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, importTrade,
+		arg.Symbol,
+		arg.Side,
+		arg.Quantity,
+		arg.Price,
+		arg.TotalValue,
+		arg.AlpacaOrderID,
+		arg.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const insertHeatSnapshot = `-- name: InsertHeatSnapshot :one
+INSERT INTO portfolio_heat_snapshots (heat_percent, band, risk_amount, equity, open_positions, recorded_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id
+`
+
+type InsertHeatSnapshotParams struct {
+	HeatPercent   float64   `json:"heat_percent"`
+	Band          string    `json:"band"`
+	RiskAmount    float64   `json:"risk_amount"`
+	Equity        float64   `json:"equity"`
+	OpenPositions int32     `json:"open_positions"`
+	RecordedAt    time.Time `json:"recorded_at"`
+}
+
+// Record the current portfolio heat for charting against equity
+func (q *Queries) InsertHeatSnapshot(ctx context.Context, arg InsertHeatSnapshotParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, insertHeatSnapshot,
+		arg.HeatPercent,
+		arg.Band,
+		arg.RiskAmount,
+		arg.Equity,
+		arg.OpenPositions,
+		arg.RecordedAt,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertScanRun = `-- name: InsertScanRun :one
+INSERT INTO scan_runs (profile_name, symbols_scanned, candidates_found, avg_score, ran_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id
+`
+
+type InsertScanRunParams struct {
+	ProfileName     string    `json:"profile_name"`
+	SymbolsScanned  int32     `json:"symbols_scanned"`
+	CandidatesFound int32     `json:"candidates_found"`
+	AvgScore        float64   `json:"avg_score"`
+	RanAt           time.Time `json:"ran_at"`
+}
+
+// Record one profile scan for market-breadth trends
+func (q *Queries) InsertScanRun(ctx context.Context, arg InsertScanRunParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, insertScanRun,
+		arg.ProfileName,
+		arg.SymbolsScanned,
+		arg.CandidatesFound,
+		arg.AvgScore,
+		arg.RanAt,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertSignal = `-- name: InsertSignal :one
+INSERT INTO signal_history (symbol, source, timeframe, recommendation, confidence, ensemble_score, price, components)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id
+`
+
+type InsertSignalParams struct {
+	Symbol         string  `json:"symbol"`
+	Source         string  `json:"source"`
+	Timeframe      string  `json:"timeframe"`
+	Recommendation string  `json:"recommendation"`
+	Confidence     float64 `json:"confidence"`
+	EnsembleScore  float64 `json:"ensemble_score"`
+	Price          float64 `json:"price"`
+	Components     string  `json:"components"`
+}
+
+// Persist a computed signal for audit
+func (q *Queries) InsertSignal(ctx context.Context, arg InsertSignalParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, insertSignal,
+		arg.Symbol,
+		arg.Source,
+		arg.Timeframe,
+		arg.Recommendation,
+		arg.Confidence,
+		arg.EnsembleScore,
+		arg.Price,
+		arg.Components,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const isSymbolFlagged = `-- name: IsSymbolFlagged :one
+SELECT COUNT(*) > 0 AS is_flagged
+FROM problem_symbols
+WHERE symbol = $1 AND flagged
+`
+
+func (q *Queries) IsSymbolFlagged(ctx context.Context, symbol string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isSymbolFlagged, symbol)
+	var is_flagged bool
+	err := row.Scan(&is_flagged)
+	return is_flagged, err
+}
+
 const isSymbolSkipped = `-- name: IsSymbolSkipped :one
 SELECT COUNT(*) > 0 as is_skipped
 FROM scout_skip_list
@@ -1003,6 +1697,55 @@ func (q *Queries) IsSymbolSkipped(ctx context.Context, arg IsSymbolSkippedParams
 	return is_skipped, err
 }
 
+const listBacktests = `-- name: ListBacktests :many
+SELECT id, symbol, start_date, end_date, initial_capital, final_balance, created_at
+FROM backtests
+ORDER BY created_at DESC, id
+LIMIT $1
+`
+
+type ListBacktestsRow struct {
+	ID             string    `json:"id"`
+	Symbol         string    `json:"symbol"`
+	StartDate      time.Time `json:"start_date"`
+	EndDate        time.Time `json:"end_date"`
+	InitialCapital float64   `json:"initial_capital"`
+	FinalBalance   float64   `json:"final_balance"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Most recent backtests first, without their metrics payload
+func (q *Queries) ListBacktests(ctx context.Context, limit int32) ([]ListBacktestsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBacktests, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBacktestsRow
+	for rows.Next() {
+		var i ListBacktestsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Symbol,
+			&i.StartDate,
+			&i.EndDate,
+			&i.InitialCapital,
+			&i.FinalBalance,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const logTrade = `-- name: LogTrade :exec
 INSERT INTO trades (symbol, side, quantity, price, total_value, alpaca_order_id, status, created_at)
 VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
@@ -1031,6 +1774,146 @@ func (q *Queries) LogTrade(ctx context.Context, arg LogTradeParams) error {
 	return err
 }
 
+const logTradeWithSlippage = `-- name: LogTradeWithSlippage :exec
+INSERT INTO trades (symbol, side, quantity, price, total_value, alpaca_order_id, status, expected_price, slippage_bps, order_type, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+`
+
+type LogTradeWithSlippageParams struct {
+	Symbol        string          `json:"symbol"`
+	Side          string          `json:"side"`
+	Quantity      string          `json:"quantity"`
+	Price         string          `json:"price"`
+	TotalValue    string          `json:"total_value"`
+	AlpacaOrderID sql.NullString  `json:"alpaca_order_id"`
+	Status        sql.NullString  `json:"status"`
+	ExpectedPrice sql.NullString  `json:"expected_price"`
+	SlippageBps   sql.NullFloat64 `json:"slippage_bps"`
+	OrderType     sql.NullString  `json:"order_type"`
+}
+
+// Like LogTrade, also storing the price the trade was decided at and the fill's slippage against it
+func (q *Queries) LogTradeWithSlippage(ctx context.Context, arg LogTradeWithSlippageParams) error {
+	_, err := q.db.ExecContext(ctx, logTradeWithSlippage,
+		arg.Symbol,
+		arg.Side,
+		arg.Quantity,
+		arg.Price,
+		arg.TotalValue,
+		arg.AlpacaOrderID,
+		arg.Status,
+		arg.ExpectedPrice,
+		arg.SlippageBps,
+		arg.OrderType,
+	)
+	return err
+}
+
+const markAlertRuleTriggered = `-- name: MarkAlertRuleTriggered :exec
+UPDATE alert_rules SET last_triggered_at = CURRENT_TIMESTAMP WHERE id = $1
+`
+
+// Record when an alert rule last fired
+func (q *Queries) MarkAlertRuleTriggered(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, markAlertRuleTriggered, id)
+	return err
+}
+
+const pruneBacktests = `-- name: PruneBacktests :execrows
+DELETE FROM backtests WHERE created_at < $1
+`
+
+func (q *Queries) PruneBacktests(ctx context.Context, createdAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pruneBacktests, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const pruneNewsArticles = `-- name: PruneNewsArticles :execrows
+DELETE FROM news_articles WHERE published_at < $1
+`
+
+func (q *Queries) PruneNewsArticles(ctx context.Context, publishedAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pruneNewsArticles, publishedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const pruneRiskEvents = `-- name: PruneRiskEvents :execrows
+DELETE FROM risk_events WHERE timestamp < $1
+`
+
+func (q *Queries) PruneRiskEvents(ctx context.Context, timestamp time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pruneRiskEvents, timestamp)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const pruneSignalHistory = `-- name: PruneSignalHistory :execrows
+DELETE FROM signal_history WHERE computed_at < $1
+`
+
+func (q *Queries) PruneSignalHistory(ctx context.Context, computedAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pruneSignalHistory, computedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const pruneWhaleEvents = `-- name: PruneWhaleEvents :execrows
+DELETE FROM whale_events WHERE timestamp < $1
+`
+
+func (q *Queries) PruneWhaleEvents(ctx context.Context, timestamp time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pruneWhaleEvents, timestamp)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const recordSymbolFetchFailure = `-- name: RecordSymbolFetchFailure :one
+INSERT INTO problem_symbols (symbol, consecutive_failures, last_error, flagged, flagged_at, updated_at)
+VALUES ($1, 1, $2, 1 >= $3::int,
+        CASE WHEN 1 >= $3::int THEN NOW() END, NOW())
+ON CONFLICT (symbol) DO UPDATE SET
+    consecutive_failures = problem_symbols.consecutive_failures + 1,
+    last_error = EXCLUDED.last_error,
+    flagged = problem_symbols.flagged OR problem_symbols.consecutive_failures + 1 >= $3::int,
+    flagged_at = COALESCE(problem_symbols.flagged_at,
+        CASE WHEN problem_symbols.consecutive_failures + 1 >= $3::int THEN NOW() END),
+    updated_at = NOW()
+RETURNING symbol, consecutive_failures, last_error, flagged, flagged_at, updated_at
+`
+
+type RecordSymbolFetchFailureParams struct {
+	Symbol    string `json:"symbol"`
+	LastError string `json:"last_error"`
+	Threshold int32  `json:"threshold"`
+}
+
+// Count one more consecutive fetch failure, flagging the symbol once the streak reaches the threshold
+func (q *Queries) RecordSymbolFetchFailure(ctx context.Context, arg RecordSymbolFetchFailureParams) (ProblemSymbol, error) {
+	row := q.db.QueryRowContext(ctx, recordSymbolFetchFailure, arg.Symbol, arg.LastError, arg.Threshold)
+	var i ProblemSymbol
+	err := row.Scan(
+		&i.Symbol,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.Flagged,
+		&i.FlaggedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const removeFromSkipBacklog = `-- name: RemoveFromSkipBacklog :exec
 DELETE FROM skip_backlog WHERE symbol = $1
 `
@@ -1052,21 +1935,68 @@ func (q *Queries) RemoveFromWatchlist(ctx context.Context, symbol string) error
 	return err
 }
 
+const resetSymbolFetchFailures = `-- name: ResetSymbolFetchFailures :exec
+DELETE FROM problem_symbols WHERE symbol = $1 AND NOT flagged
+`
+
+// A successful fetch ends the failure streak of a symbol that hasn't been flagged yet
+func (q *Queries) ResetSymbolFetchFailures(ctx context.Context, symbol string) error {
+	_, err := q.db.ExecContext(ctx, resetSymbolFetchFailures, symbol)
+	return err
+}
+
 const saveATR = `-- name: SaveATR :exec
-INSERT INTO atr_calculation (symbol, calculation_timestamp, atr_value)
-VALUES ($1, $2, $3)
-ON CONFLICT (symbol, calculation_timestamp)
+INSERT INTO atr_calculation (symbol, timeframe, calculation_timestamp, atr_value)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (symbol, timeframe, calculation_timestamp)
 DO UPDATE SET atr_value = EXCLUDED.atr_value
 `
 
 type SaveATRParams struct {
 	Symbol               string    `json:"symbol"`
+	Timeframe            string    `json:"timeframe"`
 	CalculationTimestamp time.Time `json:"calculation_timestamp"`
 	AtrValue             float32   `json:"atr_value"`
 }
 
 func (q *Queries) SaveATR(ctx context.Context, arg SaveATRParams) error {
-	_, err := q.db.ExecContext(ctx, saveATR, arg.Symbol, arg.CalculationTimestamp, arg.AtrValue)
+	_, err := q.db.ExecContext(ctx, saveATR, arg.Symbol, arg.Timeframe, arg.CalculationTimestamp, arg.AtrValue)
+	return err
+}
+
+const saveBacktest = `-- name: SaveBacktest :exec
+INSERT INTO backtests (id, symbol, start_date, end_date, initial_capital, final_balance, metrics)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (id) DO UPDATE SET
+    symbol = EXCLUDED.symbol,
+    start_date = EXCLUDED.start_date,
+    end_date = EXCLUDED.end_date,
+    initial_capital = EXCLUDED.initial_capital,
+    final_balance = EXCLUDED.final_balance,
+    metrics = EXCLUDED.metrics
+`
+
+type SaveBacktestParams struct {
+	ID             string          `json:"id"`
+	Symbol         string          `json:"symbol"`
+	StartDate      time.Time       `json:"start_date"`
+	EndDate        time.Time       `json:"end_date"`
+	InitialCapital float64         `json:"initial_capital"`
+	FinalBalance   float64         `json:"final_balance"`
+	Metrics        json.RawMessage `json:"metrics"`
+}
+
+// Store a finished backtest, replacing an earlier run saved under the same id
+func (q *Queries) SaveBacktest(ctx context.Context, arg SaveBacktestParams) error {
+	_, err := q.db.ExecContext(ctx, saveBacktest,
+		arg.ID,
+		arg.Symbol,
+		arg.StartDate,
+		arg.EndDate,
+		arg.InitialCapital,
+		arg.FinalBalance,
+		arg.Metrics,
+	)
 	return err
 }
 
@@ -1098,20 +2028,21 @@ func (q *Queries) SaveNewsArticle(ctx context.Context, arg SaveNewsArticleParams
 }
 
 const saveRSI = `-- name: SaveRSI :exec
-INSERT INTO rsi_calculation (symbol, calculation_timestamp, rsi_value)
-VALUES ($1, $2, $3)
-ON CONFLICT (symbol, calculation_timestamp)
+INSERT INTO rsi_calculation (symbol, timeframe, calculation_timestamp, rsi_value)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (symbol, timeframe, calculation_timestamp)
 DO UPDATE SET rsi_value = EXCLUDED.rsi_value
 `
 
 type SaveRSIParams struct {
 	Symbol               string    `json:"symbol"`
+	Timeframe            string    `json:"timeframe"`
 	CalculationTimestamp time.Time `json:"calculation_timestamp"`
 	RsiValue             float32   `json:"rsi_value"`
 }
 
 func (q *Queries) SaveRSI(ctx context.Context, arg SaveRSIParams) error {
-	_, err := q.db.ExecContext(ctx, saveRSI, arg.Symbol, arg.CalculationTimestamp, arg.RsiValue)
+	_, err := q.db.ExecContext(ctx, saveRSI, arg.Symbol, arg.Timeframe, arg.CalculationTimestamp, arg.RsiValue)
 	return err
 }
 
@@ -1132,6 +2063,43 @@ func (q *Queries) SkipSymbol(ctx context.Context, arg SkipSymbolParams) error {
 	return err
 }
 
+const updateAlertRule = `-- name: UpdateAlertRule :one
+UPDATE alert_rules
+SET name = $2, symbol = $3, conditions = $4, enabled = $5
+WHERE id = $1
+RETURNING id, name, symbol, conditions, enabled, created_at, last_triggered_at
+`
+
+type UpdateAlertRuleParams struct {
+	ID         int32          `json:"id"`
+	Name       string         `json:"name"`
+	Symbol     sql.NullString `json:"symbol"`
+	Conditions string         `json:"conditions"`
+	Enabled    bool           `json:"enabled"`
+}
+
+// Replace an alert rule definition
+func (q *Queries) UpdateAlertRule(ctx context.Context, arg UpdateAlertRuleParams) (AlertRule, error) {
+	row := q.db.QueryRowContext(ctx, updateAlertRule,
+		arg.ID,
+		arg.Name,
+		arg.Symbol,
+		arg.Conditions,
+		arg.Enabled,
+	)
+	var i AlertRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Symbol,
+		&i.Conditions,
+		&i.Enabled,
+		&i.CreatedAt,
+		&i.LastTriggeredAt,
+	)
+	return i, err
+}
+
 const updateTradeStatus = `-- name: UpdateTradeStatus :exec
 UPDATE trades
 SET status = $1, filled_at = NOW()
@@ -1165,6 +2133,32 @@ func (q *Queries) UpdateWatchlistScore(ctx context.Context, arg UpdateWatchlistS
 	return err
 }
 
+const updateWatchlistScoreBreakdown = `-- name: UpdateWatchlistScoreBreakdown :exec
+UPDATE watchlist
+SET technical_score = $1, news_score = $2, pattern_score = $3, sr_score = $4
+WHERE symbol = $5
+`
+
+type UpdateWatchlistScoreBreakdownParams struct {
+	TechnicalScore sql.NullFloat64 `json:"technical_score"`
+	NewsScore      sql.NullFloat64 `json:"news_score"`
+	PatternScore   sql.NullFloat64 `json:"pattern_score"`
+	SrScore        sql.NullFloat64 `json:"sr_score"`
+	Symbol         string          `json:"symbol"`
+}
+
+// Store the sub-scores behind the latest watchlist score
+func (q *Queries) UpdateWatchlistScoreBreakdown(ctx context.Context, arg UpdateWatchlistScoreBreakdownParams) error {
+	_, err := q.db.ExecContext(ctx, updateWatchlistScoreBreakdown,
+		arg.TechnicalScore,
+		arg.NewsScore,
+		arg.PatternScore,
+		arg.SrScore,
+		arg.Symbol,
+	)
+	return err
+}
+
 const upsertScanLog = `-- name: UpsertScanLog :exec
 INSERT INTO scan_log (profile_name, last_scan_timestamp, next_scan_due, symbols_scanned)
 VALUES ($1, $2, $3, $4)
@@ -1192,3 +2186,24 @@ func (q *Queries) UpsertScanLog(ctx context.Context, arg UpsertScanLogParams) er
 	)
 	return err
 }
+
+const upsertSymbolSector = `-- name: UpsertSymbolSector :exec
+INSERT INTO sectors (symbol, sector, source, updated_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (symbol) DO UPDATE SET
+    sector = EXCLUDED.sector,
+    source = EXCLUDED.source,
+    updated_at = NOW()
+`
+
+type UpsertSymbolSectorParams struct {
+	Symbol string `json:"symbol"`
+	Sector string `json:"sector"`
+	Source string `json:"source"`
+}
+
+// Cache the sector resolved for a symbol, replacing an earlier lookup
+func (q *Queries) UpsertSymbolSector(ctx context.Context, arg UpsertSymbolSectorParams) error {
+	_, err := q.db.ExecContext(ctx, upsertSymbolSector, arg.Symbol, arg.Sector, arg.Source)
+	return err
+}
@@ -0,0 +1,182 @@
+package datafeed
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	TradeStatusClosed = "closed"
+	TradeStatusOpen   = "open"
+	TradeStatusAll    = "all"
+)
+
+// one round trip as the trade-history export writes it; exit fields are empty while the trade is open
+type TradeExportRow struct {
+	Symbol          string    `json:"symbol"`
+	Direction       string    `json:"direction"` // LONG or SHORT
+	Status          string    `json:"status"`    // closed or open
+	Quantity        float64   `json:"quantity"`  // still held for an open trade
+	EntryTime       time.Time `json:"entry_time"`
+	EntryPrice      float64   `json:"entry_price"`
+	ExitTime        time.Time `json:"exit_time,omitempty"`
+	ExitPrice       float64   `json:"exit_price,omitempty"` // quantity-weighted across the exit fills
+	PnL             float64   `json:"pnl"`                  // realized; partial exits only for an open trade
+	ReturnPct       float64   `json:"return_pct"`
+	DurationSeconds int64     `json:"duration_seconds"`
+	ExitReason      string    `json:"exit_reason,omitempty"`
+	Tags            []string  `json:"tags"`
+	EntryOrderID    string    `json:"entry_order_id"`
+	ExitOrderIDs    []string  `json:"exit_order_ids"`
+}
+
+// column order of the CSV export
+var TradeExportColumns = []string{
+	"symbol", "direction", "status", "quantity", "entry_time", "entry_price", "exit_time", "exit_price",
+	"pnl", "return_pct", "duration_seconds", "exit_reason", "tags", "entry_order_id", "exit_order_ids",
+}
+
+// the row as CSV fields in TradeExportColumns order; lists are joined with ';'
+func (r TradeExportRow) CSVRecord() []string {
+	exitTime := ""
+	if !r.ExitTime.IsZero() {
+		exitTime = r.ExitTime.Format(time.RFC3339)
+	}
+	exitPrice := ""
+	if r.Status == TradeStatusClosed {
+		exitPrice = formatExportFloat(r.ExitPrice)
+	}
+	return []string{
+		r.Symbol,
+		r.Direction,
+		r.Status,
+		formatExportFloat(r.Quantity),
+		r.EntryTime.Format(time.RFC3339),
+		formatExportFloat(r.EntryPrice),
+		exitTime,
+		exitPrice,
+		strconv.FormatFloat(r.PnL, 'f', 2, 64),
+		strconv.FormatFloat(r.ReturnPct, 'f', 2, 64),
+		strconv.FormatInt(r.DurationSeconds, 10),
+		r.ExitReason,
+		strings.Join(r.Tags, ";"),
+		r.EntryOrderID,
+		strings.Join(r.ExitOrderIDs, ";"),
+	}
+}
+
+func formatExportFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// pairs the orders FIFO and returns the trades with the given status (closed, open or all), oldest entry first
+func TradeExportRows(orders []alpaca.Order, status string) ([]TradeExportRow, error) {
+	if status == "" {
+		status = TradeStatusAll
+	}
+	if status != TradeStatusClosed && status != TradeStatusOpen && status != TradeStatusAll {
+		return nil, fmt.Errorf("unknown trade status %q, want closed, open or all", status)
+	}
+
+	closed, open := pairFIFO(FillsFromOrders(orders), nil)
+
+	var rows []TradeExportRow
+	if status != TradeStatusOpen {
+		for _, trip := range closed {
+			rows = append(rows, exportRow(trip, TradeStatusClosed))
+		}
+	}
+	if status != TradeStatusClosed {
+		for _, trip := range open {
+			rows = append(rows, exportRow(trip, TradeStatusOpen))
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !rows[i].EntryTime.Equal(rows[j].EntryTime) {
+			return rows[i].EntryTime.Before(rows[j].EntryTime)
+		}
+		return rows[i].Symbol < rows[j].Symbol
+	})
+	return rows, nil
+}
+
+func exportRow(trip RoundTrip, status string) TradeExportRow {
+	entry := trip.Entry
+	row := TradeExportRow{
+		Symbol:       entry.Symbol,
+		Direction:    "LONG",
+		Status:       status,
+		Quantity:     entry.Quantity.InexactFloat64(),
+		EntryTime:    entry.FilledAt,
+		EntryPrice:   entry.Price.InexactFloat64(),
+		Tags:         exportTags(trip, status),
+		EntryOrderID: entry.OrderID,
+		ExitOrderIDs: []string{},
+	}
+	if entry.Side == "SELL" {
+		row.Direction = "SHORT"
+	}
+
+	exitQty, exitValue, pnl := decimal.Zero, decimal.Zero, decimal.Zero
+	for i, exit := range trip.Exits {
+		qty := trip.ExitQtys[i]
+		exitQty = exitQty.Add(qty)
+		exitValue = exitValue.Add(qty.Mul(exit.Price))
+		move := exit.Price.Sub(entry.Price)
+		if row.Direction == "SHORT" {
+			move = move.Neg()
+		}
+		pnl = pnl.Add(move.Mul(qty))
+		row.ExitOrderIDs = append(row.ExitOrderIDs, exit.OrderID)
+	}
+	row.PnL = pnl.InexactFloat64()
+
+	if status == TradeStatusClosed && len(trip.Exits) > 0 {
+		last := trip.Exits[len(trip.Exits)-1]
+		row.ExitTime = last.FilledAt
+		row.ExitPrice = exitValue.Div(exitQty).InexactFloat64()
+		row.DurationSeconds = int64(last.FilledAt.Sub(entry.FilledAt).Seconds())
+		row.ExitReason = exitReason(last.OrderType)
+	}
+	if cost := entry.Price.Mul(exitQty); cost.IsPositive() {
+		row.ReturnPct = pnl.Div(cost).InexactFloat64() * 100
+	}
+	return row
+}
+
+// what closed the trade, read off the order type of its final exit
+func exitReason(orderType string) string {
+	switch orderType {
+	case string(alpaca.Stop), string(alpaca.StopLimit):
+		return "STOP_LOSS"
+	case string(alpaca.TrailingStop):
+		return "TRAILING_STOP"
+	case string(alpaca.Limit):
+		return "TAKE_PROFIT"
+	case string(alpaca.Market):
+		return "MARKET"
+	}
+	return ""
+}
+
+// the entry's order class when it wasn't a plain order, plus how the trade was exited
+func exportTags(trip RoundTrip, status string) []string {
+	tags := []string{}
+	if class := trip.Entry.OrderClass; class != "" && class != string(alpaca.Simple) {
+		tags = append(tags, class)
+	}
+	switch {
+	case status == TradeStatusClosed && len(trip.Exits) > 1:
+		tags = append(tags, "scaled_out")
+	case status == TradeStatusOpen && len(trip.Exits) > 0:
+		tags = append(tags, "partial")
+	}
+	return tags
+}
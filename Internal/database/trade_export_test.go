@@ -0,0 +1,44 @@
+package datafeed
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+)
+
+func TestTradeExportRows_ClosedOnly(t *testing.T) {
+	orders := mockedOrderHistory()
+	orders[4].Type = alpaca.Stop // msft-sell-2 stopped out the rest
+
+	rows, err := TradeExportRows(orders, TradeStatusClosed)
+	if err != nil {
+		t.Fatalf("TradeExportRows() error = %v", err)
+	}
+	if len(rows) != 2 || rows[0].Symbol != "AAPL" || rows[1].Symbol != "MSFT" {
+		t.Fatalf("rows = %+v, want closed AAPL then MSFT", rows)
+	}
+
+	aapl := rows[0]
+	if aapl.PnL != 100 || aapl.DurationSeconds != 2*24*3600 || aapl.ExitPrice != 190 || aapl.Direction != "LONG" {
+		t.Errorf("AAPL = %+v, want +100 over two days exiting at 190", aapl)
+	}
+	msft := rows[1]
+	if msft.PnL != 20 || msft.ExitPrice != 402.5 || msft.ExitReason != "STOP_LOSS" || len(msft.ExitOrderIDs) != 2 {
+		t.Errorf("MSFT = %+v, want +20 at an average 402.50, stopped out over two exits", msft)
+	}
+	if len(msft.Tags) != 1 || msft.Tags[0] != "scaled_out" {
+		t.Errorf("MSFT tags = %v, want [scaled_out]", msft.Tags)
+	}
+	if math.Abs(msft.ReturnPct-0.625) > 1e-9 {
+		t.Errorf("MSFT return = %.4f%%, want 0.625%%", msft.ReturnPct)
+	}
+
+	open, err := TradeExportRows(orders, TradeStatusOpen)
+	if err != nil || len(open) != 1 || open[0].Symbol != "TSLA" || !open[0].ExitTime.IsZero() {
+		t.Errorf("open rows = %+v, err %v, want TSLA alone with no exit", open, err)
+	}
+	if _, err := TradeExportRows(orders, "pending"); err == nil {
+		t.Error("unknown status: want an error")
+	}
+}
@@ -0,0 +1,240 @@
+package datafeed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+// a filled Alpaca order normalized into the shape the trades table stores
+type ImportedFill struct {
+	OrderID  string
+	Symbol   string
+	Side     string // BUY or SELL, same as LogTrade
+	Quantity decimal.Decimal
+	Price    decimal.Decimal
+	FilledAt time.Time
+
+	OrderType  string // market, limit, stop, stop_limit or trailing_stop
+	OrderClass string // simple, bracket, oco or oto; "" when Alpaca left it out
+}
+
+// an entry fill and the opposite-side fills that closed it
+type RoundTrip struct {
+	Entry    ImportedFill
+	Exits    []ImportedFill
+	ExitQtys []decimal.Decimal // how much of each exit fill went to this entry
+}
+
+type TradeImportResult struct {
+	Orders     int `json:"orders"`      // filled orders found in the range
+	RoundTrips int `json:"round_trips"` // completed entry/exit pairs
+	Imported   int `json:"imported"`    // trade rows inserted
+	Duplicates int `json:"duplicates"`  // rows skipped because the order was imported before
+	Unpaired   int `json:"unpaired"`    // entries still open at the end of the range, left out
+}
+
+// the one query the importer needs, so tests can stand in for the database
+type TradeImporter interface {
+	ImportTrade(ctx context.Context, arg database.ImportTradeParams) (int64, error)
+}
+
+// keeps orders (and bracket/OCO legs) that actually filled, oldest first and once per order ID
+func FillsFromOrders(orders []alpaca.Order) []ImportedFill {
+	var fills []ImportedFill
+	seen := make(map[string]bool)
+
+	var collect func(orders []alpaca.Order)
+	collect = func(orders []alpaca.Order) {
+		for _, o := range orders {
+			collect(o.Legs)
+			if seen[o.ID] || !o.FilledQty.IsPositive() || o.FilledAvgPrice == nil {
+				continue
+			}
+			seen[o.ID] = true
+
+			filledAt := o.SubmittedAt
+			if o.FilledAt != nil {
+				filledAt = *o.FilledAt
+			}
+			fills = append(fills, ImportedFill{
+				OrderID:  o.ID,
+				Symbol:   utils.NormalizeSymbol(o.Symbol, string(o.AssetClass)),
+				Side:     strings.ToUpper(string(o.Side)),
+				Quantity: o.FilledQty,
+				Price:    *o.FilledAvgPrice,
+				FilledAt: filledAt,
+
+				OrderType:  string(o.Type),
+				OrderClass: string(o.OrderClass),
+			})
+		}
+	}
+	collect(orders)
+
+	sort.SliceStable(fills, func(i, j int) bool {
+		return fills[i].FilledAt.Before(fills[j].FilledAt)
+	})
+	return fills
+}
+
+// pairs fills per symbol FIFO: an opposite-side fill closes the oldest open entry, and any quantity left
+// over opens a new entry the other way; returns completed round trips and the entries still open. opening
+// holds the signed shares per symbol held before the first fill (from OpeningPositions, nil for none); fills
+// that close those shares belong to trips opened before the range and are dropped
+func PairRoundTrips(fills []ImportedFill, opening map[string]decimal.Decimal) ([]RoundTrip, int) {
+	trips, open := pairFIFO(fills, opening)
+	return trips, len(open)
+}
+
+// PairRoundTrips keeping the entries still open; an open entry's quantity is what's left of it and its exits
+// are the fills that partly closed it
+func pairFIFO(fills []ImportedFill, opening map[string]decimal.Decimal) ([]RoundTrip, []RoundTrip) {
+	type openEntry struct {
+		fill      ImportedFill
+		remaining decimal.Decimal
+		exits     []ImportedFill
+		exitQtys  []decimal.Decimal
+		preRange  bool // shares held before the first fill; closing them completes no trip
+	}
+
+	var trips []RoundTrip
+	open := make(map[string][]*openEntry)
+	for symbol, qty := range opening {
+		side := "BUY"
+		if qty.IsNegative() {
+			side = "SELL"
+		}
+		if !qty.IsZero() {
+			open[symbol] = []*openEntry{{fill: ImportedFill{Symbol: symbol, Side: side}, remaining: qty.Abs(), preRange: true}}
+		}
+	}
+	seen := make(map[string]bool)
+	var symbols []string
+
+	for _, fill := range fills {
+		queue := open[fill.Symbol]
+		remaining := fill.Quantity
+
+		if !seen[fill.Symbol] {
+			seen[fill.Symbol] = true
+			symbols = append(symbols, fill.Symbol)
+		}
+
+		for len(queue) > 0 && queue[0].fill.Side != fill.Side && remaining.IsPositive() {
+			head := queue[0]
+			used := decimal.Min(head.remaining, remaining)
+			head.remaining = head.remaining.Sub(used)
+			head.exits = append(head.exits, fill)
+			head.exitQtys = append(head.exitQtys, used)
+			remaining = remaining.Sub(used)
+
+			if head.remaining.IsZero() {
+				if !head.preRange {
+					trips = append(trips, RoundTrip{Entry: head.fill, Exits: head.exits, ExitQtys: head.exitQtys})
+				}
+				queue = queue[1:]
+			}
+		}
+		if remaining.IsPositive() {
+			queue = append(queue, &openEntry{fill: fill, remaining: remaining})
+		}
+		open[fill.Symbol] = queue
+	}
+
+	var stillOpen []RoundTrip
+	for _, symbol := range symbols {
+		for _, entry := range open[symbol] {
+			if entry.preRange {
+				continue
+			}
+			held := entry.fill
+			held.Quantity = entry.remaining
+			stillOpen = append(stillOpen, RoundTrip{Entry: held, Exits: entry.exits, ExitQtys: entry.exitQtys})
+		}
+	}
+	return trips, stillOpen
+}
+
+// stores both legs of each round trip as imported trades; order IDs already in trades, whether imported or
+// logged by the app when it placed them, are counted as duplicates
+func ImportRoundTrips(ctx context.Context, q TradeImporter, trips []RoundTrip) (TradeImportResult, error) {
+	result := TradeImportResult{RoundTrips: len(trips)}
+	stored := make(map[string]bool)
+
+	for _, trip := range trips {
+		for _, fill := range append([]ImportedFill{trip.Entry}, trip.Exits...) {
+			// an exit that closed two entries belongs to both trips but is one row
+			if stored[fill.OrderID] {
+				continue
+			}
+			stored[fill.OrderID] = true
+
+			inserted, err := q.ImportTrade(ctx, database.ImportTradeParams{
+				Symbol:        fill.Symbol,
+				Side:          fill.Side,
+				Quantity:      fill.Quantity.String(),
+				Price:         fill.Price.String(),
+				TotalValue:    fill.Quantity.Mul(fill.Price).String(),
+				AlpacaOrderID: sql.NullString{String: fill.OrderID, Valid: true},
+				CreatedAt:     sql.NullTime{Time: fill.FilledAt, Valid: true},
+			})
+			if err != nil {
+				return result, fmt.Errorf("failed to import order %s: %w", fill.OrderID, err)
+			}
+			if inserted == 0 {
+				result.Duplicates++
+			} else {
+				result.Imported++
+			}
+		}
+	}
+	return result, nil
+}
+
+// the signed shares per symbol held before since's first fill: what the broker holds now, less what since
+// bought and plus what it sold. since must run up to now for that to hold
+func OpeningPositions(held []alpaca.Position, since []ImportedFill) map[string]decimal.Decimal {
+	opening := make(map[string]decimal.Decimal)
+	for _, pos := range held {
+		qty := pos.Qty
+		if pos.Side == "short" && qty.IsPositive() {
+			qty = qty.Neg()
+		}
+		symbol := utils.NormalizeSymbol(pos.Symbol, string(pos.AssetClass))
+		opening[symbol] = opening[symbol].Add(qty)
+	}
+	for _, fill := range since {
+		if fill.Side == "BUY" {
+			opening[fill.Symbol] = opening[fill.Symbol].Sub(fill.Quantity)
+		} else {
+			opening[fill.Symbol] = opening[fill.Symbol].Add(fill.Quantity)
+		}
+	}
+	for symbol, qty := range opening {
+		if qty.IsZero() {
+			delete(opening, symbol)
+		}
+	}
+	return opening
+}
+
+// pairs a batch of Alpaca orders into round trips and imports them; opening is as for PairRoundTrips
+func ImportAlpacaOrders(ctx context.Context, q TradeImporter, orders []alpaca.Order, opening map[string]decimal.Decimal) (TradeImportResult, error) {
+	fills := FillsFromOrders(orders)
+	trips, unpaired := PairRoundTrips(fills, opening)
+
+	result, err := ImportRoundTrips(ctx, q, trips)
+	result.Orders = len(fills)
+	result.Unpaired = unpaired
+	return result, err
+}
@@ -0,0 +1,178 @@
+package datafeed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+// mimics ImportTrade skipping any order ID already in trades; app-logged rows can be seeded into rows
+type fakeTradeImporter struct {
+	rows map[string]database.ImportTradeParams
+}
+
+func (f *fakeTradeImporter) ImportTrade(ctx context.Context, arg database.ImportTradeParams) (int64, error) {
+	if _, exists := f.rows[arg.AlpacaOrderID.String]; exists {
+		return 0, nil
+	}
+	f.rows[arg.AlpacaOrderID.String] = arg
+	return 1, nil
+}
+
+func filledOrder(id, symbol string, side alpaca.Side, qty, price float64, filledAt time.Time) alpaca.Order {
+	avg := decimal.NewFromFloat(price)
+	return alpaca.Order{
+		ID:             id,
+		Symbol:         symbol,
+		Side:           side,
+		Status:         "filled",
+		FilledQty:      decimal.NewFromFloat(qty),
+		FilledAvgPrice: &avg,
+		SubmittedAt:    filledAt,
+		FilledAt:       &filledAt,
+	}
+}
+
+func mockedOrderHistory() []alpaca.Order {
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 15, 0, 0, 0, time.UTC) }
+	return []alpaca.Order{
+		filledOrder("aapl-buy", "AAPL", alpaca.Buy, 10, 180, day(1)),
+		filledOrder("aapl-sell", "AAPL", alpaca.Sell, 10, 190, day(3)),
+		// scale out of one entry over two sells
+		filledOrder("msft-buy", "MSFT", alpaca.Buy, 8, 400, day(2)),
+		filledOrder("msft-sell-1", "MSFT", alpaca.Sell, 4, 410, day(4)),
+		filledOrder("msft-sell-2", "MSFT", alpaca.Sell, 4, 395, day(6)),
+		// still open, so not imported yet
+		filledOrder("tsla-buy", "TSLA", alpaca.Buy, 5, 170, day(5)),
+		// cancelled without a fill
+		{ID: "nvda-cancelled", Symbol: "NVDA", Side: alpaca.Buy, Status: "canceled", SubmittedAt: day(5)},
+	}
+}
+
+func TestImportAlpacaOrders_PairsRoundTrips(t *testing.T) {
+	store := &fakeTradeImporter{rows: make(map[string]database.ImportTradeParams)}
+
+	result, err := ImportAlpacaOrders(context.Background(), store, mockedOrderHistory(), nil)
+	if err != nil {
+		t.Fatalf("ImportAlpacaOrders() error = %v", err)
+	}
+
+	want := TradeImportResult{Orders: 6, RoundTrips: 2, Imported: 5, Duplicates: 0, Unpaired: 1}
+	if result != want {
+		t.Errorf("result = %+v, want %+v", result, want)
+	}
+	if _, ok := store.rows["tsla-buy"]; ok {
+		t.Errorf("Open TSLA entry should not be imported")
+	}
+
+	sell := store.rows["aapl-sell"]
+	if sell.Side != "SELL" || sell.Quantity != "10" || sell.Price != "190" || sell.TotalValue != "1900" {
+		t.Errorf("aapl-sell row = %+v, want SELL 10 @ 190 = 1900", sell)
+	}
+	if !sell.CreatedAt.Valid || !sell.CreatedAt.Time.Equal(time.Date(2024, 5, 3, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf("aapl-sell created_at = %v, want the fill time", sell.CreatedAt)
+	}
+}
+
+func TestImportAlpacaOrders_ReimportIsIdempotent(t *testing.T) {
+	store := &fakeTradeImporter{rows: make(map[string]database.ImportTradeParams)}
+	orders := mockedOrderHistory()
+
+	if _, err := ImportAlpacaOrders(context.Background(), store, orders, nil); err != nil {
+		t.Fatalf("first import error = %v", err)
+	}
+	result, err := ImportAlpacaOrders(context.Background(), store, orders, nil)
+	if err != nil {
+		t.Fatalf("re-import error = %v", err)
+	}
+
+	if result.Imported != 0 || result.Duplicates != 5 {
+		t.Errorf("re-import = %d imported, %d duplicates, want 0 and 5", result.Imported, result.Duplicates)
+	}
+	if len(store.rows) != 5 {
+		t.Errorf("stored rows = %d, want 5", len(store.rows))
+	}
+}
+
+func TestPairRoundTrips_ExitLargerThanEntryOpensReverse(t *testing.T) {
+	at := time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC)
+	fills := []ImportedFill{
+		{OrderID: "b1", Symbol: "AMD", Side: "BUY", Quantity: decimal.NewFromInt(5), FilledAt: at},
+		{OrderID: "s1", Symbol: "AMD", Side: "SELL", Quantity: decimal.NewFromInt(8), FilledAt: at.Add(time.Hour)},
+	}
+
+	trips, unpaired := PairRoundTrips(fills, nil)
+	if len(trips) != 1 || trips[0].Entry.OrderID != "b1" || trips[0].Exits[0].OrderID != "s1" {
+		t.Fatalf("trips = %+v, want b1 closed by s1", trips)
+	}
+	if unpaired != 1 {
+		t.Errorf("unpaired = %d, want the 3 extra shares left open as a short", unpaired)
+	}
+}
+
+func TestImportAlpacaOrders_SkipsAppLoggedOrders(t *testing.T) {
+	// the app logged the AAPL entry when it placed it, so only its exit is new
+	store := &fakeTradeImporter{rows: map[string]database.ImportTradeParams{
+		"aapl-buy": {Symbol: "AAPL", Side: "BUY"},
+	}}
+
+	result, err := ImportAlpacaOrders(context.Background(), store, mockedOrderHistory(), nil)
+	if err != nil {
+		t.Fatalf("ImportAlpacaOrders() error = %v", err)
+	}
+	if result.Imported != 4 || result.Duplicates != 1 {
+		t.Errorf("import = %d imported, %d duplicates, want 4 and 1", result.Imported, result.Duplicates)
+	}
+	if len(store.rows) != 5 {
+		t.Errorf("stored rows = %d, want 5 with the app-logged AAPL buy kept once", len(store.rows))
+	}
+}
+
+func TestPairRoundTrips_DropsExitsOfEntriesBeforeRange(t *testing.T) {
+	at := time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC)
+	fills := []ImportedFill{
+		// closes 5 shares bought before the range
+		{OrderID: "s0", Symbol: "AMD", Side: "SELL", Quantity: decimal.NewFromInt(5), FilledAt: at},
+		{OrderID: "b1", Symbol: "AMD", Side: "BUY", Quantity: decimal.NewFromInt(3), FilledAt: at.Add(time.Hour)},
+		{OrderID: "s1", Symbol: "AMD", Side: "SELL", Quantity: decimal.NewFromInt(3), FilledAt: at.Add(2 * time.Hour)},
+	}
+
+	// nothing AMD is held now, so 5 were held before s0
+	opening := OpeningPositions(nil, fills)
+	if !opening["AMD"].Equal(decimal.NewFromInt(5)) {
+		t.Fatalf("opening AMD = %s, want 5", opening["AMD"])
+	}
+
+	trips, unpaired := PairRoundTrips(fills, opening)
+	if len(trips) != 1 || trips[0].Entry.OrderID != "b1" || trips[0].Exits[0].OrderID != "s1" {
+		t.Fatalf("trips = %+v, want only b1 closed by s1", trips)
+	}
+	if unpaired != 0 {
+		t.Errorf("unpaired = %d, want the pre-range sell dropped rather than left open as a short", unpaired)
+	}
+}
+
+func TestPairRoundTrips_KeepsShortRoundTrips(t *testing.T) {
+	at := time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC)
+	fills := []ImportedFill{
+		// sell to open, buy to cover, with a long already held from before the range left alone
+		{OrderID: "s1", Symbol: "TSLA", Side: "SELL", Quantity: decimal.NewFromInt(4), FilledAt: at},
+		{OrderID: "b1", Symbol: "TSLA", Side: "BUY", Quantity: decimal.NewFromInt(4), FilledAt: at.Add(time.Hour)},
+	}
+	held := []alpaca.Position{{Symbol: "AAPL", Side: "long", Qty: decimal.NewFromInt(10)}}
+
+	opening := OpeningPositions(held, fills)
+	if len(opening) != 1 || !opening["AAPL"].Equal(decimal.NewFromInt(10)) {
+		t.Fatalf("opening = %v, want only the 10 AAPL", opening)
+	}
+
+	trips, unpaired := PairRoundTrips(fills, opening)
+	if len(trips) != 1 || trips[0].Entry.OrderID != "s1" || trips[0].Exits[0].OrderID != "b1" || unpaired != 0 {
+		t.Errorf("trips = %+v, unpaired = %d; want the s1 short covered by b1", trips, unpaired)
+	}
+}
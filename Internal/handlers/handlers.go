@@ -6,7 +6,9 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -21,6 +23,7 @@ import (
 	"github.com/fazecat/mogulmaker/Internal/strategy/metrics"
 	positionPkg "github.com/fazecat/mogulmaker/Internal/strategy/position"
 	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
 	"github.com/fazecat/mogulmaker/Internal/utils/config"
 	"github.com/fazecat/mogulmaker/Internal/utils/scanner"
 	"github.com/fazecat/mogulmaker/Internal/utils/scoring"
@@ -46,6 +49,21 @@ func GetGlobalPositionManager() *positionPkg.PositionManager {
 	return globalPosManager
 }
 
+// entry throttle shared by every trade executed this session, since each execution builds its own manager
+var (
+	entryThrottle     *positionPkg.EntryThrottle
+	entryThrottleOnce sync.Once
+)
+
+func sessionEntryThrottle(cfg *config.Config) *positionPkg.EntryThrottle {
+	entryThrottleOnce.Do(func() {
+		if cfg != nil {
+			entryThrottle = positionPkg.NewEntryThrottle(cfg.TradeThrottle.MaxEntriesPerHour, cfg.TradeThrottle.MaxEntriesPerDay, nil)
+		}
+	})
+	return entryThrottle
+}
+
 // clears any remaining input from stdin
 func ClearInputBuffer() {
 	reader := bufio.NewReader(os.Stdin)
@@ -89,13 +107,31 @@ func HandleScan(ctx context.Context, cfg *config.Config, q *database.Queries) {
 	selectedProfile := profiles[choice-1]
 
 	fmt.Printf("Scanning profile: %s\n", selectedProfile)
-	scannedCount, err := scanner.PerformScan(ctx, selectedProfile, cfg, q)
+	scannedCount, skips, err := scanner.PerformScanWithSummary(ctx, selectedProfile, cfg, q)
 	if err != nil {
 		fmt.Printf("Scan failed: %v\n", err)
 		return
 	}
 
 	fmt.Printf("Scan complete! Updated %d symbols\n", scannedCount)
+	printSkipSummary(skips)
+}
+
+// explains why only some of the scanned symbols produced results
+func printSkipSummary(skips *scanner.SkipSummary) {
+	if skips == nil || skips.Skipped == 0 {
+		return
+	}
+	reasons := make([]string, 0, len(skips.Reasons))
+	for reason := range skips.Reasons {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	fmt.Printf("Skipped %d of %d symbols:\n", skips.Skipped, skips.Scanned)
+	for _, reason := range reasons {
+		fmt.Printf("   %-18s %d\n", reason, skips.Reasons[reason])
+	}
 }
 
 func HandleAnalyzeSingle(ctx context.Context, assetType string, q *database.Queries, newsStorage *newsscraping.NewsStorage, finnhubClient *newsscraping.FinnhubClient) {
@@ -118,6 +154,8 @@ func HandleAnalyzeSingle(ctx context.Context, assetType string, q *database.Quer
 		fmt.Println("Invalid symbol")
 		return
 	}
+	symbol = utils.NormalizeSymbol(symbol, assetType)
+	displaySymbol := utils.DisplaySymbol(symbol, assetType)
 
 	// Fetch and store news for stocks (not crypto for now)
 	if assetType == "stock" && finnhubClient != nil && newsStorage != nil {
@@ -152,13 +190,13 @@ func HandleAnalyzeSingle(ctx context.Context, assetType string, q *database.Quer
 		return
 	}
 
-	err = datafeed.CalculateAndStoreRSI(symbol, bars)
+	err = datafeed.CalculateAndStoreRSI(symbol, timeframe, bars)
 	if err != nil {
 		fmt.Printf("Warning: Failed to calculate and store RSI: %v\n", err)
 		// Don't return - continue with analysis
 	}
 
-	err = datafeed.CalculateAndStoreATR(symbol, bars)
+	err = datafeed.CalculateAndStoreATR(symbol, timeframe, bars)
 	if err != nil {
 		fmt.Printf("Warning: Failed to calculate and store ATR: %v\n", err)
 		// Don't return - continue with analysis
@@ -169,19 +207,21 @@ func HandleAnalyzeSingle(ctx context.Context, assetType string, q *database.Quer
 
 	switch displayChoice {
 	case "basic":
-		interactive.DisplayBasicData(bars, symbol, timeframe)
+		interactive.DisplayBasicData(bars, displaySymbol, timeframe)
 	case "full":
-		interactive.DisplayAdvancedData(bars, symbol, timeframe)
+		interactive.DisplayAdvancedData(bars, displaySymbol, timeframe)
 	case "analytics":
 		tz, _ := interactive.ShowTimezoneMenu()
 		ClearInputBuffer()
-		interactive.DisplayAnalyticsData(bars, symbol, timeframe, tz, q, newsStorage)
+		interactive.DisplayAnalyticsData(bars, symbol, timeframe, tz, q, newsStorage, nil)
 		fmt.Println("\n--- Press Enter to continue ---")
 		bufio.NewReader(os.Stdin).ReadBytes('\n')
 	case "vwap":
-		interactive.DisplayVWAPAnalysis(bars, symbol, timeframe)
+		interactive.DisplayVWAPAnalysis(bars, displaySymbol, timeframe)
+	case "bollinger":
+		interactive.DisplayBollingerAnalysis(bars, displaySymbol, timeframe)
 	default:
-		interactive.DisplayBasicData(bars, symbol, timeframe)
+		interactive.DisplayBasicData(bars, displaySymbol, timeframe)
 	}
 }
 
@@ -249,6 +289,7 @@ func HandleScout(ctx context.Context, cfg *config.Config, q *database.Queries, n
 	}
 
 	fmt.Printf("\nUsing %s profile with threshold: %.1f\n", selectedProfile, minScore)
+	signalWeights := scanner.ScreenerCriteriaForProfile(cfg, selectedProfile).SignalWeights
 
 	assetType := "stock"
 	if cfg.Features.CryptoSupport {
@@ -276,7 +317,7 @@ func HandleScout(ctx context.Context, cfg *config.Config, q *database.Queries, n
 
 	for {
 		fmt.Printf("\nScanning batch %d (evaluating %d symbols)...\n", batchNum, batchSize)
-		candidates, totalSymbols, err := scanner.PerformProfileScan(ctx, selectedProfile, minScore, offset, batchSize, cfg)
+		candidates, totalSymbols, skips, err := scanner.PerformProfileScanForAsset(ctx, selectedProfile, minScore, offset, batchSize, cfg, assetType)
 		if err != nil {
 			fmt.Printf("Scout scan failed: %v\n", err)
 			return
@@ -287,6 +328,8 @@ func HandleScout(ctx context.Context, cfg *config.Config, q *database.Queries, n
 			break
 		}
 
+		printSkipSummary(skips)
+
 		if len(candidates) == 0 {
 			fmt.Printf("No candidates found in this batch (evaluated %d-%d of %d symbols)\n", offset+1, offset+batchSize, totalSymbols)
 		} else {
@@ -295,6 +338,9 @@ func HandleScout(ctx context.Context, cfg *config.Config, q *database.Queries, n
 			for _, candidate := range candidates {
 				fmt.Printf("\n   %s\n", candidate.Symbol)
 				fmt.Printf("      Score: %.2f | Pattern: %s\n", candidate.Score, candidate.Analysis)
+				if candidate.Direction != "" {
+					fmt.Printf("      Setup: %s\n", candidate.Direction)
+				}
 
 				for {
 					fmt.Print("      (e)xpand / (y)es / (n)o / (i)gnore: ")
@@ -306,7 +352,7 @@ func HandleScout(ctx context.Context, cfg *config.Config, q *database.Queries, n
 						tz, _ := interactive.ShowTimezoneMenu()
 						ClearInputBuffer()
 						newsStorage := newsscraping.NewNewsStorage(q)
-						interactive.DisplayAnalyticsData(candidate.Bars, candidate.Symbol, "1Day", tz, q, newsStorage)
+						interactive.DisplayAnalyticsData(candidate.Bars, candidate.Symbol, "1Day", tz, q, newsStorage, signalWeights)
 						continue
 					}
 
@@ -371,7 +417,8 @@ func HandleScout(ctx context.Context, cfg *config.Config, q *database.Queries, n
 	bufio.NewReader(os.Stdin).ReadBytes('\n')
 }
 
-func HandleExecuteTrades(ctx context.Context, cfg *config.Config, q *database.Queries, client *alpaca.Client) {
+// riskMgr may be nil, which skips the same-sector position limit
+func HandleExecuteTrades(ctx context.Context, cfg *config.Config, q *database.Queries, client *alpaca.Client, riskMgr *risk.Manager) {
 	ClearInputBuffer()
 
 	separator := "============================================================"
@@ -397,8 +444,20 @@ func HandleExecuteTrades(ctx context.Context, cfg *config.Config, q *database.Qu
 		MaxDailyLossPercent:   -2.0, // -2%
 		PartialExitPercentage: 0.5,  //50%
 	}
+	riskMgr.CapOrderSizing(orderConfig)
+	if cfg != nil {
+		orderConfig.SetAssetClassRisk(cfg.AssetClassRisk)
+		orderConfig.SetPositionSizing(cfg.PositionSizing)
+	}
 
 	posManager := positionPkg.NewPositionManager(client, orderConfig)
+	if cfg != nil {
+		posManager.SetAutoExit(cfg.AutoExit)
+		posManager.SetAggregateLots(cfg.Features.AggregateLots)
+		posManager.SetRecalculateLevels(cfg.Features.RecalculateLevelsOnConfigChange)
+		posManager.SetTrackBracketLegs(cfg.Features.TrackBracketLegs)
+	}
+	posManager.SetEntryThrottle(sessionEntryThrottle(cfg))
 
 	// Store globally so menu can access alerts
 	SetGlobalPositionManager(posManager)
@@ -410,6 +469,8 @@ func HandleExecuteTrades(ctx context.Context, cfg *config.Config, q *database.Qu
 		fmt.Println("Invalid symbol")
 		return
 	}
+	assetType := utils.DetectAssetType(symbol, "")
+	symbol = utils.NormalizeSymbol(symbol, assetType)
 
 	fmt.Print("Enter direction (LONG/SHORT): ")
 	var direction string
@@ -433,7 +494,7 @@ func HandleExecuteTrades(ctx context.Context, cfg *config.Config, q *database.Qu
 	}
 
 	fmt.Println("\nFetching market data...")
-	bars, err := interactive.FetchMarketDataWithType(symbol, "1Day", 100, "", "stock")
+	bars, err := interactive.FetchMarketDataWithType(symbol, "1Day", 100, "", assetType)
 	if err != nil {
 		fmt.Printf("Failed to fetch data: %v\n", err)
 		return
@@ -444,23 +505,83 @@ func HandleExecuteTrades(ctx context.Context, cfg *config.Config, q *database.Qu
 		return
 	}
 
-	bar := bars[len(bars)-1]
+	bar := strategy.LatestBar(bars)
+	if err := strategy.CheckBarFreshness(bar, "1Day", assetType, time.Now(), cfg); err != nil {
+		if !cfg.DataFreshness.WarnOnly {
+			fmt.Println("ORDER REJECTED:")
+			fmt.Printf("   • %v\n", err)
+			return
+		}
+		fmt.Printf("WARNING: %v\n", err)
+	}
 	entryPrice := bar.Close
+	decisionPrice := entryPrice
+
+	if cfg != nil && cfg.Earnings.Enabled {
+		note, err := strategy.CheckEarningsWindow(symbol, time.Now(), newsscraping.NewFinnhubClient(), cfg.Earnings)
+		if err != nil {
+			fmt.Println("ORDER REJECTED:")
+			fmt.Printf("   • %v\n", err)
+			return
+		}
+		if note != "" {
+			fmt.Printf("NOTE: %s\n", note)
+		}
+	}
+
+	// crypto and equities carry separate stop/size limits
+	assetConfig := orderConfig.ForAsset(symbol, assetType)
+	stopLoss, takeProfit := strategy.CalculatePriceTargets(entryPrice, direction, assetConfig)
 
-	stopLoss, takeProfit := strategy.CalculatePriceTargets(entryPrice, direction, orderConfig)
+	// a percent stop inside about an ATR of entry tends to get whipsawed out
+	var stopWarning string
+	if cfg != nil && cfg.StopNoise.Enabled {
+		if atr, err := strategy.StopNoiseATR(bars, cfg.StopNoise); err != nil {
+			fmt.Printf("Skipping stop distance check: %v\n", err)
+		} else {
+			check := strategy.CheckStopNoise(entryPrice, stopLoss, atr, direction, cfg.StopNoise)
+			stopLoss, stopWarning = check.Stop, check.Warning()
+		}
+	}
 	safeBail := 0.0
 	if direction == "LONG" {
-		safeBail = entryPrice * (1 + (orderConfig.SafeBailPercent / 100))
+		safeBail = entryPrice * (1 + (assetConfig.SafeBailPercent / 100))
 	} else {
-		safeBail = entryPrice * (1 - (orderConfig.SafeBailPercent / 100))
+		safeBail = entryPrice * (1 - (assetConfig.SafeBailPercent / 100))
 	}
 
 	// Auto-calculate quantity if needed
 	if quantity == 0 {
-		quantity = strategy.CalculatePositionSize(accountValue, entryPrice, stopLoss, orderConfig.MaxPortfolioPercent, orderConfig)
+		openRiskPercent := 0.0
+		if accountValue > 0 {
+			openRiskPercent = posManager.OpenRiskAmount() / accountValue * 100
+		}
+		riskBudget, beta, err := assetConfig.BetaAdjustedRisk(symbol, assetConfig.MaxPortfolioPercent)
+		if err != nil {
+			fmt.Printf("Sizing without beta adjustment: %v\n", err)
+		} else if beta != 0 {
+			fmt.Printf("Beta %.2f vs benchmark: risk budget %.2f%% -> %.2f%%\n", beta, assetConfig.MaxPortfolioPercent, riskBudget)
+		}
+		quantity = strategy.CalculateScaledPositionSize(accountValue, entryPrice, stopLoss, riskBudget,
+			posManager.CountOpenPositions(), openRiskPercent, assetConfig)
 		fmt.Printf("Auto-calculated quantity: %d shares\n", quantity)
 	}
 
+	// size against what the account can actually pay for, so Alpaca doesn't reject it for funds
+	if cfg != nil && cfg.BuyingPower.Enabled {
+		buyingPower := strategy.AccountBuyingPower(account, symbol, assetType)
+		fitted, err := strategy.CheckBuyingPower(float64(quantity), entryPrice, buyingPower, cfg.BuyingPower)
+		if err != nil {
+			fmt.Println("ORDER REJECTED:")
+			fmt.Printf("   • %v\n", err)
+			return
+		}
+		if int64(fitted) != quantity {
+			fmt.Printf("Resized from %d to %d shares to fit buying power ($%.2f)\n", quantity, int64(fitted), buyingPower)
+			quantity = int64(fitted)
+		}
+	}
+
 	// Create order request
 	orderReq := &strategy.OrderRequest{
 		Symbol:           symbol,
@@ -473,6 +594,31 @@ func HandleExecuteTrades(ctx context.Context, cfg *config.Config, q *database.Qu
 		EntryPrice:       entryPrice,
 		UseStopOrder:     true,
 		UseLimitOrder:    false,
+		AssetType:        assetType,
+		UseBracketOrder:  cfg != nil && cfg.Features.BracketOrders && assetType != utils.AssetTypeCrypto,
+	}
+
+	if ok, reason := posManager.CanOpenPosition(); !ok {
+		fmt.Println("ORDER REJECTED:")
+		fmt.Printf("   • %s\n", reason)
+		return
+	}
+
+	if riskMgr != nil {
+		held, err := client.GetPositions()
+		if err != nil {
+			fmt.Printf("Failed to fetch positions for the sector limit: %v\n", err)
+			return
+		}
+		heldSymbols := make([]string, len(held))
+		for i, pos := range held {
+			heldSymbols[i] = pos.Symbol
+		}
+		if ok, reason := riskMgr.CanAddPosition(symbol, riskMgr.SymbolSector(symbol), heldSymbols); !ok {
+			fmt.Println("ORDER REJECTED:")
+			fmt.Printf("   • %s\n", reason)
+			return
+		}
 	}
 
 	// Validate order
@@ -496,8 +642,11 @@ func HandleExecuteTrades(ctx context.Context, cfg *config.Config, q *database.Qu
 	fmt.Printf("Direction:           %s\n", orderReq.Direction)
 	fmt.Printf("Quantity:            %d shares\n", orderReq.Quantity)
 	fmt.Printf("Entry Price:         $%.2f\n", orderReq.EntryPrice)
-	fmt.Printf("Stop Loss:           $%.2f (%.2f%% below entry)\n", stopLoss, orderConfig.StopLossPercent)
-	fmt.Printf("Take Profit:         $%.2f (%.2f%% above entry)\n", takeProfit, orderConfig.TakeProfitPercent)
+	fmt.Printf("Stop Loss:           $%.2f (%.2f%% from entry)\n", stopLoss, math.Abs(entryPrice-stopLoss)/entryPrice*100)
+	if stopWarning != "" {
+		fmt.Printf("WARNING: %s\n", stopWarning)
+	}
+	fmt.Printf("Take Profit:         $%.2f (%.2f%% above entry)\n", takeProfit, assetConfig.TakeProfitPercent)
 	fmt.Printf("Safe Bail:           $%.2f\n", safeBail)
 	fmt.Printf("Max Risk:            $%.2f (%.2f%% of portfolio)\n", validation.RiskAmount, validation.PortfolioRisk)
 	fmt.Printf("Potential Gain:      $%.2f\n", validation.PotentialGain)
@@ -519,12 +668,41 @@ func HandleExecuteTrades(ctx context.Context, cfg *config.Config, q *database.Qu
 	}
 
 	fmt.Println("\nExecuting trade...")
-	order, err := client.PlaceOrder(*alpacaOrder)
+	retry := strategy.SubmitRetryFromConfig(config.OrderSubmitConfig{})
+	if cfg != nil {
+		retry = strategy.SubmitRetryFromConfig(cfg.OrderSubmit)
+	}
+	order, err := strategy.SubmitOrder(client, *alpacaOrder, retry)
 	if err != nil {
 		fmt.Printf("Trade execution failed: %v\n", err)
 		return
 	}
 
+	// seed the position with what actually filled rather than the just-submitted order
+	if cfg != nil && cfg.OrderFill.Enabled {
+		timeout, poll := strategy.FillWaitFromConfig(cfg.OrderFill)
+		poll.OnStatus = func(o *alpaca.Order) {
+			fmt.Printf("Order %s: %s (filled %s)\n", o.ID, o.Status, o.FilledQty.String())
+		}
+		fill, err := strategy.WaitForFill(ctx, client, order.ID, timeout, poll)
+		switch {
+		case fill.Filled():
+			order = fill.Order
+			orderReq.Quantity = int64(fill.FilledQty)
+			if fill.FillPrice > 0 {
+				entryPrice = fill.FillPrice
+			}
+			if err != nil {
+				fmt.Printf("WARNING: %v; tracking the %.0f shares filled so far\n", err, fill.FilledQty)
+			}
+		case err != nil:
+			fmt.Printf("WARNING: %v; tracking the order as submitted\n", err)
+		default:
+			fmt.Printf("Order %s ended %s without a fill, no position opened\n", order.ID, fill.Status)
+			return
+		}
+	}
+
 	// Add to position manager
 	signal := &types.TradeSignal{
 		Direction:  direction,
@@ -536,8 +714,22 @@ func HandleExecuteTrades(ctx context.Context, cfg *config.Config, q *database.Qu
 
 	strategy.LogOrderExecution(orderReq, validation, order.ID)
 
-	err = datafeed.LogTradeExecution(ctx, order.Symbol, direction, orderReq.Quantity,
-		decimal.NewFromFloat(entryPrice), order.ID, order.Status)
+	// slippage needs the broker's fill price; an order that hasn't reported one is logged at the decision price
+	if cfg != nil && cfg.Features.TrackSlippage && order.FilledAvgPrice != nil {
+		err = datafeed.LogTradeWithSlippage(ctx, datafeed.ExecutedTrade{
+			Symbol:        order.Symbol,
+			Side:          direction,
+			Quantity:      orderReq.Quantity,
+			FillPrice:     *order.FilledAvgPrice,
+			ExpectedPrice: decimal.NewFromFloat(decisionPrice),
+			OrderType:     string(order.Type),
+			OrderID:       order.ID,
+			Status:        order.Status,
+		})
+	} else {
+		err = datafeed.LogTradeExecution(ctx, order.Symbol, direction, orderReq.Quantity,
+			decimal.NewFromFloat(entryPrice), order.ID, order.Status)
+	}
 	if err != nil {
 		log.Printf(" Warning: Could not log trade to database: %v\n", err)
 	}
@@ -728,14 +920,15 @@ func HandleWatchlistMenu(ctx context.Context, cfg *config.Config, q *database.Qu
 }
 
 func HandleAddToWatchlistInteractive(ctx context.Context, q *database.Queries) {
-	fmt.Print("\nEnter symbol to add (e.g., AAPL): ")
+	fmt.Print("\nEnter symbol to add (e.g., AAPL or BTC/USD): ")
 	var symbol string
 	_, err := fmt.Scanln(&symbol)
 	if err != nil || symbol == "" {
 		fmt.Println("Invalid symbol")
 		return
 	}
-	symbol = strings.ToUpper(symbol)
+	assetType := utils.DetectAssetType(symbol, "")
+	symbol = utils.NormalizeSymbol(symbol, assetType)
 
 	fmt.Print("Enter reason (optional): ")
 	scanner := bufio.NewScanner(os.Stdin)
@@ -749,7 +942,7 @@ func HandleAddToWatchlistInteractive(ctx context.Context, q *database.Queries) {
 
 	params := database.AddToWatchlistParams{
 		Symbol:    symbol,
-		AssetType: "stock",
+		AssetType: assetType,
 		Score:     defaultScore,
 		Reason: sql.NullString{
 			String: reason,
@@ -763,7 +956,7 @@ func HandleAddToWatchlistInteractive(ctx context.Context, q *database.Queries) {
 		return
 	}
 
-	fmt.Printf("Successfully added %s to watchlist (ID: %d)\n", symbol, watchlistID)
+	fmt.Printf("Successfully added %s to watchlist (ID: %d)\n", utils.DisplaySymbol(symbol, assetType), watchlistID)
 }
 
 func HandleRemoveFromWatchlistInteractive(ctx context.Context, q *database.Queries) {
@@ -774,7 +967,7 @@ func HandleRemoveFromWatchlistInteractive(ctx context.Context, q *database.Queri
 		fmt.Println("Invalid symbol")
 		return
 	}
-	symbol = strings.ToUpper(symbol)
+	symbol = utils.NormalizeSymbol(symbol, "")
 
 	watchlistItem, err := q.GetWatchlistBySymbol(ctx, symbol)
 	if err != nil {
@@ -919,7 +1112,14 @@ func HandleDisplayBackTester() {
 		return
 	}
 
-	trades, err := metrics.RunBacktest(symbol, bars, 10000.0)
+	// size entries with the same rules as live trades
+	orderConfig := &strategy.OrderConfig{
+		MaxOpenPositions:    5,
+		MaxPortfolioPercent: 20.0,
+		StopLossPercent:     2.0,
+		TakeProfitPercent:   5.0,
+	}
+	trades, err := metrics.RunBacktest(symbol, bars, 10000.0, orderConfig)
 	if err != nil {
 		fmt.Printf("Backtest failed: %v\n", err)
 		return
@@ -0,0 +1,99 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/handlers/risk"
+)
+
+// subset of queries used to persist and chart portfolio heat (*database.Queries satisfies it)
+type HeatSnapshotStore interface {
+	InsertHeatSnapshot(ctx context.Context, arg database.InsertHeatSnapshotParams) (int32, error)
+	GetHeatSnapshots(ctx context.Context, arg database.GetHeatSnapshotsParams) ([]database.PortfolioHeatSnapshot, error)
+}
+
+// position calls heat needs (*position.PositionManager satisfies it)
+type RiskAtStop interface {
+	OpenRiskAmount() float64
+	CountOpenPositions() int
+}
+
+// current heat of the tracked positions against equity, banded by risk.PortfolioHeatBands
+func CurrentHeat(positions RiskAtStop, equity float64) risk.PortfolioHeat {
+	return risk.CalculatePortfolioHeat(positions.OpenRiskAmount(), equity, positions.CountOpenPositions(), risk.PortfolioHeatBands)
+}
+
+// account equity from Alpaca, for the heat recorder
+func AccountEquity(client interface {
+	GetAccount() (*alpaca.Account, error)
+}) func() (float64, error) {
+	return func() (float64, error) {
+		account, err := client.GetAccount()
+		if err != nil {
+			return 0, err
+		}
+		return account.Equity.InexactFloat64(), nil
+	}
+}
+
+// snapshots portfolio heat on an interval so it can be charted against the equity curve
+type HeatRecorder struct {
+	positions RiskAtStop
+	equity    func() (float64, error)
+	store     HeatSnapshotStore
+	now       func() time.Time
+}
+
+// now may be nil (time.Now)
+func NewHeatRecorder(positions RiskAtStop, equity func() (float64, error), store HeatSnapshotStore, now func() time.Time) *HeatRecorder {
+	if now == nil {
+		now = time.Now
+	}
+	return &HeatRecorder{positions: positions, equity: equity, store: store, now: now}
+}
+
+// computes and stores one snapshot
+func (h *HeatRecorder) Record(ctx context.Context) (risk.PortfolioHeat, error) {
+	equity, err := h.equity()
+	if err != nil {
+		return risk.PortfolioHeat{}, fmt.Errorf("failed to fetch equity for heat snapshot: %w", err)
+	}
+	heat := CurrentHeat(h.positions, equity)
+	_, err = h.store.InsertHeatSnapshot(ctx, database.InsertHeatSnapshotParams{
+		HeatPercent:   heat.HeatPercent,
+		Band:          heat.Band,
+		RiskAmount:    heat.RiskAmount,
+		Equity:        heat.Equity,
+		OpenPositions: int32(heat.OpenPositions),
+		RecordedAt:    h.now(),
+	})
+	if err != nil {
+		return heat, fmt.Errorf("failed to persist heat snapshot: %w", err)
+	}
+	return heat, nil
+}
+
+// records a snapshot every interval until ctx is done
+func (h *HeatRecorder) Run(ctx context.Context, interval time.Duration) {
+	log.Printf("Portfolio heat snapshots every %s\n", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Portfolio heat recorder stopped")
+			return
+		case <-ticker.C:
+			if _, err := h.Record(ctx); err != nil {
+				log.Printf("Warning: %v\n", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/handlers/risk"
+)
+
+type fixedRiskAtStop struct {
+	amount float64
+	count  int
+}
+
+func (f fixedRiskAtStop) OpenRiskAmount() float64 { return f.amount }
+func (f fixedRiskAtStop) CountOpenPositions() int { return f.count }
+
+type recordedHeat struct {
+	rows []database.InsertHeatSnapshotParams
+}
+
+func (r *recordedHeat) InsertHeatSnapshot(ctx context.Context, arg database.InsertHeatSnapshotParams) (int32, error) {
+	r.rows = append(r.rows, arg)
+	return int32(len(r.rows)), nil
+}
+
+func (r *recordedHeat) GetHeatSnapshots(ctx context.Context, arg database.GetHeatSnapshotsParams) ([]database.PortfolioHeatSnapshot, error) {
+	return nil, nil
+}
+
+func TestHeatRecorder_RecordPersistsBandedSnapshot(t *testing.T) {
+	defer func(saved risk.HeatBands) { risk.PortfolioHeatBands = saved }(risk.PortfolioHeatBands)
+	risk.PortfolioHeatBands = risk.HeatBands{Yellow: 5, Red: 8}
+
+	at := time.Date(2024, 6, 3, 15, 30, 0, 0, time.UTC)
+	store := &recordedHeat{}
+	recorder := NewHeatRecorder(fixedRiskAtStop{amount: 4500, count: 3}, func() (float64, error) { return 50000, nil }, store, func() time.Time { return at })
+
+	heat, err := recorder.Record(context.Background())
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if heat.HeatPercent != 9 || heat.Band != risk.HeatRed {
+		t.Errorf("heat = %+v, want 9%% RED", heat)
+	}
+	if len(store.rows) != 1 || store.rows[0].Band != risk.HeatRed || store.rows[0].Equity != 50000 ||
+		store.rows[0].OpenPositions != 3 || !store.rows[0].RecordedAt.Equal(at) {
+		t.Errorf("stored = %+v, want one RED snapshot at %v", store.rows, at)
+	}
+
+	failing := NewHeatRecorder(fixedRiskAtStop{}, func() (float64, error) { return 0, errors.New("account unavailable") }, store, nil)
+	if _, err := failing.Record(context.Background()); err == nil || len(store.rows) != 1 {
+		t.Errorf("equity failure: err %v, %d rows, want an error and nothing stored", err, len(store.rows))
+	}
+}
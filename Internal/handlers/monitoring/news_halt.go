@@ -0,0 +1,215 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/handlers/risk"
+	newsscraping "github.com/fazecat/mogulmaker/Internal/news_scraping"
+	"github.com/fazecat/mogulmaker/Internal/strategy/position"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+const newsHaltArticlesPerSymbol = 10
+
+// position calls the news halt needs (*position.PositionManager satisfies it)
+type HeldPositions interface {
+	SyncFromAlpaca(ctx context.Context) error
+	GetOpenPositions() []*position.OpenPosition
+	FlattenSymbol(ctx context.Context, symbol, reason string) position.FlattenSummary
+}
+
+// a strong negative catalyst found for a held symbol
+type NewsHalt struct {
+	Symbol      string                    `json:"symbol"`
+	Headline    string                    `json:"headline"`
+	URL         string                    `json:"url"`
+	PublishedAt time.Time                 `json:"published_at"`
+	Catalyst    newsscraping.CatalystType `json:"catalyst"`
+	Impact      float64                   `json:"impact"`
+	Sentiment   float64                   `json:"sentiment"`
+	Exited      bool                      `json:"exited"`
+	Exit        *position.FlattenSummary  `json:"exit,omitempty"`
+}
+
+func (h NewsHalt) String() string {
+	msg := fmt.Sprintf("%s: %s catalyst (impact %.2f, sentiment %.2f): %s", h.Symbol, h.Catalyst, h.Impact, h.Sentiment, h.Headline)
+	if h.Exit != nil {
+		msg += " | " + h.Exit.String()
+	}
+	return msg
+}
+
+// checks fresh news for every held symbol and alerts (optionally exiting) on a strong negative catalyst
+type NewsHaltMonitor struct {
+	positions HeldPositions
+	news      newsscraping.NewsScraper
+	cfg       config.NewsHaltConfig
+	detector  *newsscraping.CatalystDetector
+	sentiment *newsscraping.SentimentAnalyzer
+	now       func() time.Time
+	alert     func(NewsHalt)
+
+	handled map[string]bool // symbol + headline, so one article halts once
+	mu      sync.Mutex
+}
+
+// now and alert may be nil (time.Now, and a log line)
+func NewNewsHaltMonitor(positions HeldPositions, news newsscraping.NewsScraper, cfg config.NewsHaltConfig, now func() time.Time, alert func(NewsHalt)) *NewsHaltMonitor {
+	if now == nil {
+		now = time.Now
+	}
+	if alert == nil {
+		alert = func(halt NewsHalt) {
+			log.Printf("NEWS HALT: %s\n", halt)
+		}
+	}
+	return &NewsHaltMonitor{
+		positions: positions,
+		news:      news,
+		cfg:       cfg,
+		detector:  newsscraping.NewCatalystDetector(),
+		sentiment: newsscraping.NewSentimentAnalyzer(),
+		now:       now,
+		alert:     alert,
+		handled:   make(map[string]bool),
+	}
+}
+
+// scans news for the symbols currently held; returns the halts raised on this pass
+func (m *NewsHaltMonitor) Check(ctx context.Context) []NewsHalt {
+	if err := m.positions.SyncFromAlpaca(ctx); err != nil {
+		log.Printf("Warning: Could not sync positions before news check: %v\n", err)
+	}
+
+	symbols := make(map[string]bool)
+	for _, pos := range m.positions.GetOpenPositions() {
+		symbols[pos.Symbol] = true
+	}
+
+	var halts []NewsHalt
+	for symbol := range symbols {
+		articles, err := m.news.FetchNews(symbol, newsHaltArticlesPerSymbol)
+		if err != nil {
+			log.Printf("Warning: news check failed for %s: %v\n", symbol, err)
+			continue
+		}
+		for _, article := range articles {
+			halt, ok := m.evaluate(symbol, article)
+			if !ok || !m.claim(symbol, article.Headline) {
+				continue
+			}
+			if m.cfg.AutoExit {
+				summary := m.positions.FlattenSymbol(ctx, symbol, "NEWS_HALT")
+				halt.Exit = &summary
+				halt.Exited = len(summary.Closed) > 0
+			}
+			m.alert(halt)
+			halts = append(halts, halt)
+			// one halt per symbol per pass; the rest of the articles are moot
+			break
+		}
+	}
+	return halts
+}
+
+// reports whether the article is a fresh, high-impact, clearly negative catalyst
+func (m *NewsHaltMonitor) evaluate(symbol string, article newsscraping.NewsArticle) (NewsHalt, bool) {
+	if m.cfg.LookbackHours > 0 && !article.PublishedAt.IsZero() &&
+		m.now().Sub(article.PublishedAt) > time.Duration(m.cfg.LookbackHours)*time.Hour {
+		return NewsHalt{}, false
+	}
+
+	catalyst := article.CatalystType
+	if catalyst == "" || catalyst == newsscraping.NoCatalyst {
+		catalyst = m.detector.Detect(article.Headline)
+	}
+	impact := article.Impact
+	if impact <= 0 {
+		impact = m.detector.GetImpact(catalyst)
+	}
+	_, sentiment := m.sentiment.Analyze(article.Headline)
+
+	if catalyst == newsscraping.NoCatalyst || impact < m.cfg.MinImpact || sentiment > -m.cfg.MinNegativeSentiment {
+		return NewsHalt{}, false
+	}
+	return NewsHalt{
+		Symbol:      symbol,
+		Headline:    article.Headline,
+		URL:         article.URL,
+		PublishedAt: article.PublishedAt,
+		Catalyst:    catalyst,
+		Impact:      impact,
+		Sentiment:   sentiment,
+	}, true
+}
+
+func (m *NewsHaltMonitor) claim(symbol, headline string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := symbol + "|" + headline
+	if m.handled[key] {
+		return false
+	}
+	m.handled[key] = true
+	return true
+}
+
+// checks every interval until ctx is done
+func (m *NewsHaltMonitor) Run(ctx context.Context, interval time.Duration) {
+	if !m.cfg.Enabled {
+		return
+	}
+	log.Printf("News halt enabled: checking held symbols every %s (auto-exit %v)\n", interval, m.cfg.AutoExit)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("News halt monitor stopped")
+			return
+		case <-ticker.C:
+			m.Check(ctx)
+		}
+	}
+}
+
+// alerts a halt as CRITICAL through the risk manager and records it as a risk event; on a nil manager it only logs
+func NewsHaltAlert(rm *risk.Manager) func(NewsHalt) {
+	return func(halt NewsHalt) {
+		if rm == nil {
+			log.Printf("NEWS HALT: %s\n", halt)
+			return
+		}
+		title := "Negative news catalyst"
+		if halt.Exited {
+			title = "Negative news catalyst - position closed"
+		}
+		rm.RecordCriticalPosition(&risk.Event{
+			Timestamp: time.Now(),
+			EventType: "NEWS_HALT",
+			Severity:  "CRITICAL",
+			Symbol:    halt.Symbol,
+			Details:   halt.String(),
+		})
+		rm.SendAlert(&risk.Alert{
+			Level:   "CRITICAL",
+			Title:   title,
+			Message: halt.String(),
+			Symbol:  halt.Symbol,
+			Data: map[string]interface{}{
+				"headline":  halt.Headline,
+				"url":       halt.URL,
+				"catalyst":  halt.Catalyst,
+				"impact":    halt.Impact,
+				"sentiment": halt.Sentiment,
+				"exited":    halt.Exited,
+			},
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	newsscraping "github.com/fazecat/mogulmaker/Internal/news_scraping"
+	"github.com/fazecat/mogulmaker/Internal/strategy/position"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+type fakeHeldPositions struct {
+	open   []*position.OpenPosition
+	exited []string
+}
+
+func (f *fakeHeldPositions) SyncFromAlpaca(ctx context.Context) error { return nil }
+
+func (f *fakeHeldPositions) GetOpenPositions() []*position.OpenPosition { return f.open }
+
+func (f *fakeHeldPositions) FlattenSymbol(ctx context.Context, symbol, reason string) position.FlattenSummary {
+	f.exited = append(f.exited, symbol)
+	return position.FlattenSummary{Closed: []position.FlattenResult{{Symbol: symbol, Quantity: 10, Price: 80}}}
+}
+
+type fakeNewsScraper map[string][]newsscraping.NewsArticle
+
+func (f fakeNewsScraper) FetchNews(symbol string, limit int) ([]newsscraping.NewsArticle, error) {
+	return f[symbol], nil
+}
+
+func (f fakeNewsScraper) Name() string { return "fake" }
+
+func newsHaltTestConfig(autoExit bool) config.NewsHaltConfig {
+	return config.NewsHaltConfig{Enabled: true, MinImpact: 0.2, MinNegativeSentiment: 0.5, LookbackHours: 24, AutoExit: autoExit}
+}
+
+func TestNewsHaltMonitor_NegativeCatalystAlertsAndExits(t *testing.T) {
+	now := time.Date(2024, 3, 6, 15, 0, 0, 0, time.UTC)
+	held := &fakeHeldPositions{open: []*position.OpenPosition{{Symbol: "ACME"}, {Symbol: "MSFT"}}}
+	news := fakeNewsScraper{
+		"ACME": {{Symbol: "ACME", Headline: "FDA rejects Acme lead drug, shares plunge", PublishedAt: now.Add(-time.Hour)}},
+		"MSFT": {{Symbol: "MSFT", Headline: "Microsoft shares rally on strong cloud growth", PublishedAt: now.Add(-time.Hour)}},
+	}
+	var alerts []NewsHalt
+	monitor := NewNewsHaltMonitor(held, news, newsHaltTestConfig(true), func() time.Time { return now },
+		func(halt NewsHalt) { alerts = append(alerts, halt) })
+
+	halts := monitor.Check(context.Background())
+
+	if len(halts) != 1 || halts[0].Symbol != "ACME" {
+		t.Fatalf("halts = %+v, want one for ACME", halts)
+	}
+	if halts[0].Catalyst != newsscraping.Regulatory || halts[0].Sentiment > -0.5 {
+		t.Errorf("halt = %+v, want a strongly negative regulatory catalyst", halts[0])
+	}
+	if len(held.exited) != 1 || held.exited[0] != "ACME" || !halts[0].Exited {
+		t.Errorf("exited = %v (Exited %v), want ACME closed", held.exited, halts[0].Exited)
+	}
+	if len(alerts) != 1 {
+		t.Errorf("alerts = %d, want 1", len(alerts))
+	}
+
+	// the same article doesn't halt twice
+	if again := monitor.Check(context.Background()); len(again) != 0 {
+		t.Errorf("second check raised %+v, want nothing", again)
+	}
+}
+
+func TestNewsHaltMonitor_AlertOnlyAndThresholds(t *testing.T) {
+	now := time.Date(2024, 3, 6, 15, 0, 0, 0, time.UTC)
+	held := &fakeHeldPositions{open: []*position.OpenPosition{{Symbol: "ACME"}, {Symbol: "OLD"}, {Symbol: "LOW"}}}
+	news := fakeNewsScraper{
+		"ACME": {{Symbol: "ACME", Headline: "Acme under fraud investigation, stock collapse feared", PublishedAt: now.Add(-2 * time.Hour)}},
+		"OLD":  {{Symbol: "OLD", Headline: "FDA rejects Old Co drug, shares plunge", PublishedAt: now.Add(-72 * time.Hour)}},
+		"LOW":  {{Symbol: "LOW", Headline: "Low Corp announces dividend amid weak quarter concerns", PublishedAt: now.Add(-time.Hour)}},
+	}
+	monitor := NewNewsHaltMonitor(held, news, newsHaltTestConfig(false), func() time.Time { return now }, func(NewsHalt) {})
+
+	halts := monitor.Check(context.Background())
+
+	if len(halts) != 1 || halts[0].Symbol != "ACME" {
+		t.Fatalf("halts = %+v, want only ACME (OLD is stale, LOW is low impact)", halts)
+	}
+	if len(held.exited) != 0 || halts[0].Exited {
+		t.Errorf("exited = %v with auto-exit off", held.exited)
+	}
+}
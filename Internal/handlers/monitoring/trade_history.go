@@ -150,6 +150,7 @@ func FormatTradeRecordsAsJSON(records []TradeHistoryRecord) []map[string]interfa
 			"qty":           filledQty,
 			"side":          order.Side,
 			"status":        status,
+			"pair_id":       rec.TradePairID,
 			"realized_pl":   rec.PnL,
 			"realized_plpc": rec.ReturnPct / 100,
 			"duration_ms":   nil,
@@ -4,14 +4,15 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strconv"
 	"strings"
 	"time"
 
 	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
 	"github.com/fazecat/mogulmaker/Internal/handlers/risk"
 	"github.com/fazecat/mogulmaker/Internal/strategy/position"
+	"github.com/fazecat/mogulmaker/Internal/utils"
 	"github.com/fazecat/mogulmaker/Internal/utils/formatting"
+	"github.com/shopspring/decimal"
 )
 
 // P&L tracking, and analytics
@@ -194,9 +195,13 @@ func (tm *Monitor) calculateStatsFromTrades(trades []database.GetAllTradesRow) *
 		}
 	}
 
-	// Calculate P&L for completed trades
-	var completedTrades []float64
+	// Calculate P&L for completed trades; totals stay decimal until the end
+	var completedTrades []decimal.Decimal
 	var tradeDurations []time.Duration
+	totalProfit := decimal.Zero
+	totalLoss := decimal.Zero
+	largestWin := decimal.Zero
+	largestLoss := decimal.Zero
 	consecutiveWins := 0
 	consecutiveLosses := 0
 
@@ -206,30 +211,30 @@ func (tm *Monitor) calculateStatsFromTrades(trades []database.GetAllTradesRow) *
 			buy := pos.buys[i]
 			sell := pos.sells[i]
 
-			buyPrice, _ := strconv.ParseFloat(buy.Price, 64)
-			sellPrice, _ := strconv.ParseFloat(sell.Price, 64)
-			qty, _ := strconv.ParseFloat(buy.Quantity, 64)
+			buyPrice := utils.ParseMoney(buy.Price)
+			sellPrice := utils.ParseMoney(sell.Price)
+			qty := utils.ParseMoney(buy.Quantity)
 
-			pnl := (sellPrice - buyPrice) * qty
+			pnl := utils.RealizedPnL(buyPrice, sellPrice, qty)
 			completedTrades = append(completedTrades, pnl)
 
 			stats.TotalTrades++
-			if pnl > 0 {
+			if pnl.IsPositive() {
 				stats.WinningTrades++
-				stats.TotalProfit += pnl
-				if pnl > stats.LargestWin {
-					stats.LargestWin = pnl
+				totalProfit = totalProfit.Add(pnl)
+				if pnl.GreaterThan(largestWin) {
+					largestWin = pnl
 				}
 				consecutiveWins++
 				if consecutiveWins > stats.MaxConsecutiveWins {
 					stats.MaxConsecutiveWins = consecutiveWins
 				}
 				consecutiveLosses = 0
-			} else if pnl < 0 {
+			} else if pnl.IsNegative() {
 				stats.LosingTrades++
-				stats.TotalLoss += pnl
-				if pnl < stats.LargestLoss {
-					stats.LargestLoss = pnl
+				totalLoss = totalLoss.Add(pnl)
+				if pnl.LessThan(largestLoss) {
+					largestLoss = pnl
 				}
 				consecutiveLosses++
 				if consecutiveLosses > stats.MaxConsecutiveLosses {
@@ -258,21 +263,26 @@ func (tm *Monitor) calculateStatsFromTrades(trades []database.GetAllTradesRow) *
 	}
 
 	// Calculate derived statistics
+	stats.TotalProfit = utils.MoneyToFloat(totalProfit)
+	stats.TotalLoss = utils.MoneyToFloat(totalLoss)
+	stats.LargestWin = utils.MoneyToFloat(largestWin)
+	stats.LargestLoss = utils.MoneyToFloat(largestLoss)
+
 	if stats.TotalTrades > 0 {
 		stats.WinRate = (float64(stats.WinningTrades) / float64(stats.TotalTrades)) * 100
-		stats.NetProfit = stats.TotalProfit + stats.TotalLoss // TotalLoss is negative
+		stats.NetProfit = utils.MoneyToFloat(totalProfit.Add(totalLoss)) // totalLoss is negative
 	}
 
 	if stats.WinningTrades > 0 {
-		stats.AverageProfitPerTrade = stats.TotalProfit / float64(stats.WinningTrades)
+		stats.AverageProfitPerTrade = utils.MoneyToFloat(totalProfit.Div(decimal.NewFromInt(int64(stats.WinningTrades))))
 	}
 
 	if stats.LosingTrades > 0 {
-		stats.AverageLossPerTrade = stats.TotalLoss / float64(stats.LosingTrades)
+		stats.AverageLossPerTrade = utils.MoneyToFloat(totalLoss.Div(decimal.NewFromInt(int64(stats.LosingTrades))))
 	}
 
-	if stats.TotalLoss != 0 {
-		stats.ProfitFactor = stats.TotalProfit / -stats.TotalLoss
+	if !totalLoss.IsZero() {
+		stats.ProfitFactor = totalProfit.Div(totalLoss.Neg()).InexactFloat64()
 	}
 
 	// Calculate average trade duration
@@ -286,28 +296,36 @@ func (tm *Monitor) calculateStatsFromTrades(trades []database.GetAllTradesRow) *
 
 	// Calculate max drawdown
 	if len(completedTrades) > 0 {
-		var peak, trough float64
-		runningTotal := 0.0
+		peak := decimal.Zero
+		maxDrawdown := decimal.Zero
+		runningTotal := decimal.Zero
 		for _, pnl := range completedTrades {
-			runningTotal += pnl
-			if runningTotal > peak {
+			runningTotal = runningTotal.Add(pnl)
+			if runningTotal.GreaterThan(peak) {
 				peak = runningTotal
 			}
-			drawdown := peak - runningTotal
-			if drawdown > stats.MaxDrawdown {
-				stats.MaxDrawdown = drawdown
-				trough = runningTotal
+			drawdown := peak.Sub(runningTotal)
+			if drawdown.GreaterThan(maxDrawdown) {
+				maxDrawdown = drawdown
 			}
 		}
-		if peak > 0 {
-			stats.MaxDrawdownPercent = (stats.MaxDrawdown / peak) * 100
+		stats.MaxDrawdown = utils.MoneyToFloat(maxDrawdown)
+		if peak.IsPositive() {
+			stats.MaxDrawdownPercent = maxDrawdown.Div(peak).Mul(decimal.NewFromInt(100)).InexactFloat64()
 		}
-		_ = trough
 	}
 
 	return stats
 }
 
+// books bracket stop/take-profit legs that filled at the broker as position closes
+func (tm *Monitor) ReconcileBracketLegs(ctx context.Context) []position.BracketLegFill {
+	if tm.positionManager == nil {
+		return nil
+	}
+	return tm.positionManager.ReconcileBracketLegs(ctx)
+}
+
 // UpdatePositionAlerts records CRITICAL positions as risk events (called from API endpoints)
 func (tm *Monitor) UpdatePositionAlerts() {
 	// Sync with Alpaca first
@@ -316,6 +334,7 @@ func (tm *Monitor) UpdatePositionAlerts() {
 		if err := tm.positionManager.SyncFromAlpaca(ctx); err != nil {
 			log.Printf("Warning: Could not sync positions from Alpaca: %v\n", err)
 		}
+		tm.ReconcileBracketLegs(ctx)
 	}
 
 	monitors := tm.GetPositionMonitors()
@@ -345,6 +364,7 @@ func (tm *Monitor) PrintOpenPositions() {
 		if err := tm.positionManager.SyncFromAlpaca(ctx); err != nil {
 			log.Printf("Warning: Could not sync positions from Alpaca: %v\n", err)
 		}
+		tm.ReconcileBracketLegs(ctx)
 	}
 
 	monitors := tm.GetPositionMonitors()
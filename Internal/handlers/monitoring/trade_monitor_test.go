@@ -0,0 +1,52 @@
+package monitoring
+
+import (
+	"testing"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+func TestCalculateStatsFromTrades_DecimalPnL(t *testing.T) {
+	var trades []database.GetAllTradesRow
+	for i := 0; i < 10; i++ {
+		trades = append(trades,
+			database.GetAllTradesRow{Symbol: "AAPL", Side: "buy", Price: "10.00", Quantity: "3"},
+			database.GetAllTradesRow{Symbol: "AAPL", Side: "sell", Price: "10.045", Quantity: "3"},
+		)
+	}
+
+	stats := (&Monitor{}).calculateStatsFromTrades(trades)
+
+	// each pair earns exactly 0.135, which float math lands just below and rounds to 0.13
+	if stats.TotalTrades != 10 || stats.WinningTrades != 10 {
+		t.Fatalf("TotalTrades = %d, WinningTrades = %d, want 10/10", stats.TotalTrades, stats.WinningTrades)
+	}
+	if stats.NetProfit != 1.35 {
+		t.Errorf("NetProfit = %v, want 1.35", stats.NetProfit)
+	}
+	if stats.LargestWin != 0.14 {
+		t.Errorf("LargestWin = %v, want 0.14", stats.LargestWin)
+	}
+	if stats.AverageProfitPerTrade != 0.14 {
+		t.Errorf("AverageProfitPerTrade = %v, want 0.14", stats.AverageProfitPerTrade)
+	}
+}
+
+func TestCalculateStatsFromTrades_ProfitFactor(t *testing.T) {
+	trades := []database.GetAllTradesRow{
+		{Symbol: "MSFT", Side: "buy", Price: "100.10", Quantity: "2"},
+		{Symbol: "MSFT", Side: "sell", Price: "100.30", Quantity: "2"},
+		{Symbol: "TSLA", Side: "buy", Price: "50.20", Quantity: "1"},
+		{Symbol: "TSLA", Side: "sell", Price: "50.10", Quantity: "1"},
+	}
+
+	stats := (&Monitor{}).calculateStatsFromTrades(trades)
+
+	if stats.TotalProfit != 0.4 || stats.TotalLoss != -0.1 {
+		t.Errorf("TotalProfit/TotalLoss = %v/%v, want 0.4/-0.1", stats.TotalProfit, stats.TotalLoss)
+	}
+	// float P&L gives 3.99999... here
+	if stats.ProfitFactor != 4 {
+		t.Errorf("ProfitFactor = %v, want exactly 4", stats.ProfitFactor)
+	}
+}
@@ -0,0 +1,63 @@
+package risk
+
+import "fmt"
+
+const (
+	HeatGreen  = "GREEN"
+	HeatYellow = "YELLOW"
+	HeatRed    = "RED"
+)
+
+// heat at or above Yellow is yellow and at or above Red is red, both as percent of equity
+type HeatBands struct {
+	Yellow float64
+	Red    float64
+}
+
+// set from the portfolio_heat config block
+var PortfolioHeatBands = HeatBands{Yellow: 5, Red: 8}
+
+// replaces PortfolioHeatBands; non-positive or inverted bands are reported and the current ones kept.
+// Both zero (an absent config block) keeps the current bands
+func SetHeatBands(yellow, red float64) error {
+	if yellow == 0 && red == 0 {
+		return nil
+	}
+	if yellow <= 0 || red <= 0 {
+		return fmt.Errorf("heat bands must be positive, got yellow %.2f / red %.2f", yellow, red)
+	}
+	if red < yellow {
+		return fmt.Errorf("red heat band %.2f%% is below yellow %.2f%%", red, yellow)
+	}
+	PortfolioHeatBands = HeatBands{Yellow: yellow, Red: red}
+	return nil
+}
+
+func (b HeatBands) Classify(heatPercent float64) string {
+	switch {
+	case heatPercent >= b.Red:
+		return HeatRed
+	case heatPercent >= b.Yellow:
+		return HeatYellow
+	}
+	return HeatGreen
+}
+
+// total risk-at-stop across open positions as a percent of equity: how exposed the account is right now
+type PortfolioHeat struct {
+	HeatPercent   float64 `json:"heat_percent"`
+	Band          string  `json:"band"`
+	RiskAmount    float64 `json:"risk_amount"`
+	Equity        float64 `json:"equity"`
+	OpenPositions int     `json:"open_positions"`
+}
+
+// heat for riskAmount dollars at stop against equity; no equity reads as zero heat rather than infinite
+func CalculatePortfolioHeat(riskAmount, equity float64, openPositions int, bands HeatBands) PortfolioHeat {
+	heat := PortfolioHeat{RiskAmount: riskAmount, Equity: equity, OpenPositions: openPositions}
+	if equity > 0 {
+		heat.HeatPercent = riskAmount / equity * 100
+	}
+	heat.Band = bands.Classify(heat.HeatPercent)
+	return heat
+}
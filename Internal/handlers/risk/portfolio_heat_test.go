@@ -0,0 +1,55 @@
+package risk
+
+import "testing"
+
+func TestHeatBands_Classify(t *testing.T) {
+	bands := HeatBands{Yellow: 5, Red: 8}
+	tests := []struct {
+		heat float64
+		want string
+	}{
+		{0, HeatGreen},
+		{4.99, HeatGreen},
+		{5, HeatYellow},
+		{7.5, HeatYellow},
+		{8, HeatRed},
+		{25, HeatRed},
+	}
+	for _, tt := range tests {
+		if got := bands.Classify(tt.heat); got != tt.want {
+			t.Errorf("Classify(%.2f) = %s, want %s", tt.heat, got, tt.want)
+		}
+	}
+}
+
+func TestCalculatePortfolioHeat(t *testing.T) {
+	bands := HeatBands{Yellow: 5, Red: 8}
+
+	heat := CalculatePortfolioHeat(3000, 50000, 4, bands)
+	if heat.HeatPercent != 6 || heat.Band != HeatYellow || heat.OpenPositions != 4 {
+		t.Errorf("$3000 at stop on $50k = %+v, want 6%% YELLOW", heat)
+	}
+	if heat := CalculatePortfolioHeat(500, 50000, 1, bands); heat.Band != HeatGreen {
+		t.Errorf("1%% heat band = %s, want GREEN", heat.Band)
+	}
+	if heat := CalculatePortfolioHeat(4500, 50000, 3, bands); heat.Band != HeatRed {
+		t.Errorf("9%% heat band = %s, want RED", heat.Band)
+	}
+	if heat := CalculatePortfolioHeat(1000, 0, 1, bands); heat.HeatPercent != 0 || heat.Band != HeatGreen {
+		t.Errorf("no equity = %+v, want zero heat", heat)
+	}
+}
+
+func TestSetHeatBands(t *testing.T) {
+	defer func(saved HeatBands) { PortfolioHeatBands = saved }(PortfolioHeatBands)
+
+	if err := SetHeatBands(4, 10); err != nil || PortfolioHeatBands != (HeatBands{Yellow: 4, Red: 10}) {
+		t.Errorf("SetHeatBands(4, 10) = %v, bands %+v", err, PortfolioHeatBands)
+	}
+	if err := SetHeatBands(10, 4); err == nil || PortfolioHeatBands.Red != 10 {
+		t.Errorf("inverted bands should be rejected and the current ones kept, got %v %+v", err, PortfolioHeatBands)
+	}
+	if err := SetHeatBands(0, 8); err == nil {
+		t.Errorf("a zero band should be rejected")
+	}
+}
@@ -1,13 +1,19 @@
 package risk
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"math"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/fazecat/mogulmaker/Internal/strategy"
 	"github.com/fazecat/mogulmaker/Internal/strategy/position"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
 	"github.com/fazecat/mogulmaker/Internal/utils/formatting"
 )
 
@@ -25,9 +31,10 @@ type Manager struct {
 	MaxPortfolioRiskPercent float64 // Overall portfolio risk cap
 
 	// Sector diversification
-	MaxSameSectorPositions int            // 3 trades max in same sector
-	PositionsBySymbol      map[string]int // Track positions per symbol
-	PositionsBySector      map[string]int // Track positions per sector
+	MaxSameSectorPositions int             // 3 trades max in same sector
+	PositionsBySymbol      map[string]int  // Track positions per symbol
+	PositionsBySector      map[string]int  // Track positions per sector
+	sectors                *SectorResolver // resolves held symbols for the sector limit
 	positionsMutex         sync.RWMutex
 
 	// Account tracking
@@ -40,14 +47,17 @@ type Manager struct {
 	riskEvents      []*Event
 	riskEventsMutex sync.RWMutex
 
-	// Track recently recorded position alerts to prevent duplicates
-	recentPositionAlerts  map[string]time.Time // key: symbol, value: last alert time
-	recentAlertsMutex     sync.RWMutex
-	alertDeduplicationTTL time.Duration // TTL before allowing duplicate alerts
+	// Latest event per type+symbol, guarded by riskEventsMutex; a repeat inside the cooldown updates it
+	recentEvents          map[string]*Event
+	alertDeduplicationTTL time.Duration // cooldown between identical events/alerts, 0 records every one
 
 	// Alerts
 	alertCallbacks      []AlertCallback
 	alertCallbacksMutex sync.RWMutex
+	recentAlerts        map[string]*Alert // last alert sent per title+symbol
+	recentAlertsMutex   sync.Mutex
+
+	now func() time.Time
 }
 
 // represents a significant risk event
@@ -59,6 +69,8 @@ type Event struct {
 	Details             string
 	CurrentAccountValue float64
 	CurrentDailyLoss    float64
+	Occurrences         int       // times the same type+symbol fired inside the cooldown
+	LastSeen            time.Time // latest of those
 }
 
 // callback function for risk alerts
@@ -90,10 +102,62 @@ func NewManager(client *alpaca.Client, accountBalance float64) *Manager {
 		client:                  client,
 		lastAccountUpdateTime:   time.Now(),
 		riskEvents:              make([]*Event, 0),
-		recentPositionAlerts:    make(map[string]time.Time),
-		alertDeduplicationTTL:   3 * time.Minute, // Allow duplicate alerts every 3 minutes
+		recentEvents:            make(map[string]*Event),
+		alertDeduplicationTTL:   defaultAlertCooldown,
 		alertCallbacks:          make([]AlertCallback, 0),
+		recentAlerts:            make(map[string]*Alert),
+		now:                     time.Now,
+	}
+}
+
+// makes the portfolio risk cap the order sizing ceiling too; a nil manager leaves cfg uncapped
+func (rm *Manager) CapOrderSizing(cfg *strategy.OrderConfig) {
+	if rm == nil || cfg == nil {
+		return
+	}
+	cfg.MaxPortfolioRiskPercent = rm.MaxPortfolioRiskPercent
+}
+
+// ALERT COOLDOWN
+
+const defaultAlertCooldown = 3 * time.Minute
+
+// sets the window inside which an identical (type+symbol) event or alert updates the previous one instead of
+// being recorded or sent again; 0 turns deduplication off. A nil manager is left alone
+func (rm *Manager) SetAlertCooldown(window time.Duration) {
+	if rm == nil {
+		return
+	}
+	rm.riskEventsMutex.Lock()
+	rm.alertDeduplicationTTL = max(window, 0)
+	rm.riskEventsMutex.Unlock()
+}
+
+// the cooldown the alert_cooldown block asks for; disabled means none
+func AlertCooldownFromConfig(cfg config.AlertCooldownConfig) time.Duration {
+	if !cfg.Enabled {
+		return 0
+	}
+	if cfg.WindowSeconds <= 0 {
+		return defaultAlertCooldown
+	}
+	return time.Duration(cfg.WindowSeconds) * time.Second
+}
+
+func (rm *Manager) alertCooldown() time.Duration {
+	rm.riskEventsMutex.RLock()
+	defer rm.riskEventsMutex.RUnlock()
+	return rm.alertDeduplicationTTL
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case "CRITICAL":
+		return 2
+	case "WARNING":
+		return 1
 	}
+	return 0
 }
 
 // ACCOUNT BALANCE MANAGEMENT
@@ -199,7 +263,23 @@ func (rm *Manager) ClosePositionBySymbol(symbol string) error {
 
 // PORTFOLIO RISK ASSESSMENT
 
+// total risk as the plain sum of what each position loses at its stop
 func (rm *Manager) CalculatePortfolioRisk(positions []*position.OpenPosition) PortfolioRisk {
+	return rm.CalculatePortfolioRiskWithCorrelation(positions, nil)
+}
+
+// CalculatePortfolioRisk plus CorrelationAdjustedRisk, the total inflated for holdings that move together, from
+// recent returns per symbol (oldest first). With r_i a position's risk at its stop and rho_ij the Pearson
+// correlation of two symbols' returns,
+//
+//	rhoBar   = sum_{i<j} r_i*r_j*max(rho_ij, 0) / sum_{i<j} r_i*r_j
+//	adjusted = sum_i r_i * (1 + rhoBar)
+//
+// so perfectly correlated holdings count double and uncorrelated ones leave the sum alone; negative correlation
+// never shrinks it, since each stop can still be hit. Pairs without enough returns count as uncorrelated.
+// TotalRiskAmount stays the dollars lost if every stop hits and alone decides IsOverRisk; a nil returns map
+// gives exactly CalculatePortfolioRisk
+func (rm *Manager) CalculatePortfolioRiskWithCorrelation(positions []*position.OpenPosition, returns map[string][]float64) PortfolioRisk {
 	risk := PortfolioRisk{
 		TotalRiskAmount:  0,
 		TotalRiskPercent: 0,
@@ -221,6 +301,15 @@ func (rm *Manager) CalculatePortfolioRisk(positions []*position.OpenPosition) Po
 		risk.TotalRiskAmount += positionRisk
 	}
 
+	if returns != nil {
+		risk.CorrelationFactor = 1 + averageCorrelation(risk.PositionRisks, returns)
+		risk.CorrelationAdjustedRisk = risk.TotalRiskAmount
+		for _, pr := range risk.PositionRisks {
+			// positions already past break-even carry no risk to inflate
+			risk.CorrelationAdjustedRisk += max(pr.RiskAmount, 0) * (risk.CorrelationFactor - 1)
+		}
+	}
+
 	risk.TotalRiskPercent = (risk.TotalRiskAmount / rm.GetAccountBalance()) * 100
 	risk.IsOverRisk = risk.TotalRiskAmount > risk.MaxAllowedRisk
 
@@ -237,12 +326,103 @@ func (rm *Manager) CalculatePortfolioRisk(positions []*position.OpenPosition) Po
 	return risk
 }
 
+// rhoBar above: the positive pairwise correlations weighted by the product of the two positions' risks
+func averageCorrelation(positionRisks []PositionRisk, returns map[string][]float64) float64 {
+	symbols := make([]string, len(positionRisks))
+	for i, pr := range positionRisks {
+		symbols[i] = pr.Symbol
+	}
+	matrix := correlationMatrix(symbols, returns)
+
+	var weighted, totalWeight float64
+	for i := range positionRisks {
+		for j := i + 1; j < len(positionRisks); j++ {
+			weight := max(positionRisks[i].RiskAmount, 0) * max(positionRisks[j].RiskAmount, 0)
+			weighted += weight * max(matrix[i][j], 0)
+			totalWeight += weight
+		}
+	}
+	if totalWeight == 0 {
+		return 0
+	}
+	return weighted / totalWeight
+}
+
+// pairwise Pearson correlations of the symbols' returns, 1 on the diagonal
+func correlationMatrix(symbols []string, returns map[string][]float64) [][]float64 {
+	matrix := make([][]float64, len(symbols))
+	for i := range matrix {
+		matrix[i] = make([]float64, len(symbols))
+		matrix[i][i] = 1
+	}
+	for i := range symbols {
+		for j := i + 1; j < len(symbols); j++ {
+			rho := pearsonCorrelation(returns[symbols[i]], returns[symbols[j]])
+			matrix[i][j], matrix[j][i] = rho, rho
+		}
+	}
+	return matrix
+}
+
+// correlation of the most recent returns both series share; 0 with fewer than two or when either is flat
+func pearsonCorrelation(a, b []float64) float64 {
+	n := min(len(a), len(b))
+	if n < 2 {
+		return 0
+	}
+	a, b = a[len(a)-n:], b[len(b)-n:]
+
+	var meanA, meanB float64
+	for i := 0; i < n; i++ {
+		meanA += a[i]
+		meanB += b[i]
+	}
+	meanA /= float64(n)
+	meanB /= float64(n)
+
+	var cov, varA, varB float64
+	for i := 0; i < n; i++ {
+		da, db := a[i]-meanA, b[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
+
 // RISK EVENTS & ALERTS
 
+// records the event, or folds it into the last one of the same type and symbol while that is inside the
+// cooldown: the count and details are refreshed and the severity only ever escalates
 func (rm *Manager) recordRiskEvent(event *Event) {
 	rm.riskEventsMutex.Lock()
 	defer rm.riskEventsMutex.Unlock()
+
+	now := rm.now()
+	if event.Timestamp.IsZero() {
+		event.Timestamp = now
+	}
+	key := event.EventType + "|" + event.Symbol
+	if last, ok := rm.recentEvents[key]; ok && rm.alertDeduplicationTTL > 0 && now.Sub(last.Timestamp) < rm.alertDeduplicationTTL {
+		last.Occurrences++
+		last.LastSeen = now
+		last.Details = event.Details
+		last.CurrentAccountValue = event.CurrentAccountValue
+		last.CurrentDailyLoss = event.CurrentDailyLoss
+		if severityRank(event.Severity) > severityRank(last.Severity) {
+			last.Severity = event.Severity
+			log.Printf("Risk Event escalated: [%s] %s - %s\n", last.Severity, last.EventType, last.Details)
+		}
+		return
+	}
+
+	event.Occurrences = 1
+	event.LastSeen = now
 	rm.riskEvents = append(rm.riskEvents, event)
+	rm.recentEvents[key] = event
 	log.Printf("Risk Event: [%s] %s - %s\n", event.Severity, event.EventType, event.Details)
 }
 
@@ -251,25 +431,7 @@ func (rm *Manager) RecordCriticalPosition(event *Event) {
 	if event == nil || event.Symbol == "" {
 		return
 	}
-
-	// Check if we've recently recorded an alert for this symbol
-	rm.recentAlertsMutex.RLock()
-	lastAlertTime, exists := rm.recentPositionAlerts[event.Symbol]
-	rm.recentAlertsMutex.RUnlock()
-
-	now := time.Now()
-
-	// If we've recently recorded this symbol, skip it
-	if exists && now.Sub(lastAlertTime) < rm.alertDeduplicationTTL {
-		return
-	}
-
 	rm.recordRiskEvent(event)
-
-	// Update the last alert time for this symbol
-	rm.recentAlertsMutex.Lock()
-	rm.recentPositionAlerts[event.Symbol] = now
-	rm.recentAlertsMutex.Unlock()
 }
 
 func (rm *Manager) GetRiskEvents(limit int) []*Event {
@@ -313,8 +475,20 @@ func (rm *Manager) PrintRiskEvents(limit int) {
 	fmt.Println(formatting.Separator(width) + "\n")
 }
 
+// adds a callback invoked for every alert sent through the manager
+func (rm *Manager) RegisterAlertCallback(callback AlertCallback) {
+	rm.alertCallbacksMutex.Lock()
+	defer rm.alertCallbacksMutex.Unlock()
+	rm.alertCallbacks = append(rm.alertCallbacks, callback)
+}
+
+// sends the alert to every callback unless the same title and symbol went out inside the cooldown at the same
+// or a higher level
 func (rm *Manager) SendAlert(alert *Alert) {
-	alert.Timestamp = time.Now()
+	alert.Timestamp = rm.now()
+	if rm.suppressAlert(alert) {
+		return
+	}
 
 	rm.alertCallbacksMutex.RLock()
 	callbacks := rm.alertCallbacks
@@ -325,6 +499,45 @@ func (rm *Manager) SendAlert(alert *Alert) {
 	}
 }
 
+func (rm *Manager) suppressAlert(alert *Alert) bool {
+	cooldown := rm.alertCooldown()
+	if cooldown <= 0 {
+		return false
+	}
+	key := alert.Title + "|" + alert.Symbol
+
+	rm.recentAlertsMutex.Lock()
+	defer rm.recentAlertsMutex.Unlock()
+	if last, ok := rm.recentAlerts[key]; ok && alert.Timestamp.Sub(last.Timestamp) < cooldown &&
+		severityRank(alert.Level) <= severityRank(last.Level) {
+		return true
+	}
+	rm.recentAlerts[key] = alert
+	return false
+}
+
+// alerts what an end-of-day flatten closed; on a nil manager it only logs
+func (rm *Manager) SendFlattenAlert(summary position.FlattenSummary) {
+	if rm == nil {
+		log.Printf("END OF DAY CLOSE: %s\n", summary)
+		return
+	}
+	level := "INFO"
+	if len(summary.Failed) > 0 {
+		level = "WARNING"
+	}
+	rm.SendAlert(&Alert{
+		Level:   level,
+		Title:   "End-of-day close",
+		Message: summary.String(),
+		Data: map[string]interface{}{
+			"closed":  summary.Closed,
+			"failed":  summary.Failed,
+			"skipped": summary.Skipped,
+		},
+	})
+}
+
 // RISK REPORT & MONITORING
 
 func (rm *Manager) GenerateRiskReport(positions []*position.OpenPosition) Report {
@@ -379,61 +592,75 @@ type ValidationResult struct {
 }
 
 type PortfolioRisk struct {
-	TotalRiskAmount  float64
-	TotalRiskPercent float64
-	PositionRisks    []PositionRisk
-	MaxAllowedRisk   float64
-	IsOverRisk       bool
+	TotalRiskAmount  float64        `json:"total_risk_amount"`
+	TotalRiskPercent float64        `json:"total_risk_percent"`
+	PositionRisks    []PositionRisk `json:"position_risks"`
+	MaxAllowedRisk   float64        `json:"max_allowed_risk"`
+	IsOverRisk       bool           `json:"is_over_risk"`
+
+	// 1 + rhoBar and the total scaled by it from CalculatePortfolioRiskWithCorrelation, 0 when no returns were given
+	CorrelationFactor       float64 `json:"correlation_factor,omitempty"`
+	CorrelationAdjustedRisk float64 `json:"correlation_adjusted_risk,omitempty"`
 }
 
 type PositionRisk struct {
-	Symbol      string
-	RiskAmount  float64
-	RiskPercent float64
+	Symbol      string  `json:"symbol"`
+	RiskAmount  float64 `json:"risk_amount"`
+	RiskPercent float64 `json:"risk_percent"`
 }
 
 type Report struct {
-	Timestamp           time.Time
-	AccountBalance      float64
-	OpenPositions       int
-	DailyLoss           float64
-	DailyLossPercent    float64
-	MaxDailyLossPercent float64
-	DailyLossRemaining  float64
-	PortfolioRisk       PortfolioRisk
-	HealthStatus        string
-	Alerts              []string
-	RecentEvents        []*Event
+	Timestamp           time.Time     `json:"timestamp"`
+	AccountBalance      float64       `json:"account_balance"`
+	OpenPositions       int           `json:"open_positions"`
+	DailyLoss           float64       `json:"daily_loss"`
+	DailyLossPercent    float64       `json:"daily_loss_percent"`
+	MaxDailyLossPercent float64       `json:"max_daily_loss_percent"`
+	DailyLossRemaining  float64       `json:"daily_loss_remaining"`
+	PortfolioRisk       PortfolioRisk `json:"portfolio_risk"`
+	HealthStatus        string        `json:"health_status"`
+	Alerts              []string      `json:"alerts"`
+	RecentEvents        []*Event      `json:"recent_events"`
+}
+
+// the report as indented JSON, for the dashboard and archiving
+func (r *Report) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
 }
 
 func (r *Report) Print() {
+	r.WriteText(os.Stdout)
+}
+
+// the formatted text report Print shows
+func (r *Report) WriteText(w io.Writer) {
 	width := 70
-	fmt.Println("\n" + formatting.Separator(width))
-	fmt.Println("PORTFOLIO RISK REPORT")
-	fmt.Println(formatting.Separator(width))
-	fmt.Printf("Account Balance:       $%.2f\n", r.AccountBalance)
-	fmt.Printf("Open Positions:        %d/%d\n", r.OpenPositions, 5)
-	fmt.Printf("Daily Loss:            $%.2f (%.2f%% of %.2f%% limit)\n",
+	fmt.Fprintln(w, "\n"+formatting.Separator(width))
+	fmt.Fprintln(w, "PORTFOLIO RISK REPORT")
+	fmt.Fprintln(w, formatting.Separator(width))
+	fmt.Fprintf(w, "Account Balance:       $%.2f\n", r.AccountBalance)
+	fmt.Fprintf(w, "Open Positions:        %d/%d\n", r.OpenPositions, 5)
+	fmt.Fprintf(w, "Daily Loss:            $%.2f (%.2f%% of %.2f%% limit)\n",
 		r.DailyLoss, r.DailyLossPercent, r.MaxDailyLossPercent)
-	fmt.Printf("Portfolio Risk:        $%.2f (%.2f%% of max 10%%)\n",
+	fmt.Fprintf(w, "Portfolio Risk:        $%.2f (%.2f%% of max 10%%)\n",
 		r.PortfolioRisk.TotalRiskAmount, r.PortfolioRisk.TotalRiskPercent)
-	fmt.Printf("Status:                %s\n", r.HealthStatus)
+	fmt.Fprintf(w, "Status:                %s\n", r.HealthStatus)
 
 	if len(r.Alerts) > 0 {
-		fmt.Println("\nAlerts:")
+		fmt.Fprintln(w, "\nAlerts:")
 		for _, alert := range r.Alerts {
-			fmt.Printf("  %s\n", alert)
+			fmt.Fprintf(w, "  %s\n", alert)
 		}
 	}
 
 	if len(r.RecentEvents) > 0 {
-		fmt.Println("\nRecent Risk Events (Last 5):")
+		fmt.Fprintln(w, "\nRecent Risk Events (Last 5):")
 		for _, event := range r.RecentEvents {
-			fmt.Printf("  [%s] %s - %s (%s)\n",
+			fmt.Fprintf(w, "  [%s] %s - %s (%s)\n",
 				event.Severity, event.EventType, event.Details, event.Timestamp.Format("15:04:05"))
 		}
 	}
-	fmt.Println(formatting.Separator(width) + "\n")
+	fmt.Fprintln(w, formatting.Separator(width)+"\n")
 }
 
 func (rm *Manager) GetRecentEvents() []string {
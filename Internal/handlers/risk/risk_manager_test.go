@@ -0,0 +1,243 @@
+package risk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/strategy/position"
+)
+
+func TestReportToJSON_IncludesPositionRiskAndHealth(t *testing.T) {
+	rm := NewManager(nil, 100000)
+	rm.LogTradeLoss("TSLA", 1600) // 1.6% of a 2% limit
+
+	report := rm.GenerateRiskReport([]*position.OpenPosition{
+		{Symbol: "AAPL", Quantity: 100, EntryPrice: 150, StopLossPrice: 145},
+		{Symbol: "MSFT", Quantity: 20, EntryPrice: 400, StopLossPrice: 380},
+	})
+
+	data, err := report.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	var decoded struct {
+		AccountBalance float64 `json:"account_balance"`
+		HealthStatus   string  `json:"health_status"`
+		Alerts         []string
+		PortfolioRisk  struct {
+			TotalRiskAmount float64 `json:"total_risk_amount"`
+			PositionRisks   []struct {
+				Symbol      string  `json:"symbol"`
+				RiskAmount  float64 `json:"risk_amount"`
+				RiskPercent float64 `json:"risk_percent"`
+			} `json:"position_risks"`
+		} `json:"portfolio_risk"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("decode report: %v\n%s", err, data)
+	}
+
+	if decoded.HealthStatus != "WARNING" {
+		t.Errorf("health_status = %q, want WARNING at 80%% of the daily loss limit", decoded.HealthStatus)
+	}
+	if decoded.AccountBalance != 100000 {
+		t.Errorf("account_balance = %.2f, want 100000", decoded.AccountBalance)
+	}
+	risks := decoded.PortfolioRisk.PositionRisks
+	if len(risks) != 2 {
+		t.Fatalf("position_risks = %+v, want AAPL and MSFT", risks)
+	}
+	if risks[0].Symbol != "AAPL" || risks[0].RiskAmount != 500 || risks[0].RiskPercent != 0.5 {
+		t.Errorf("AAPL risk = %+v, want $500 / 0.5%%", risks[0])
+	}
+	if risks[1].Symbol != "MSFT" || risks[1].RiskAmount != 400 {
+		t.Errorf("MSFT risk = %+v, want $400", risks[1])
+	}
+	if decoded.PortfolioRisk.TotalRiskAmount != 900 {
+		t.Errorf("total_risk_amount = %.2f, want 900", decoded.PortfolioRisk.TotalRiskAmount)
+	}
+	if !strings.Contains(string(data), `"recent_events"`) {
+		t.Errorf("report JSON is missing recent_events:\n%s", data)
+	}
+}
+
+// a manager whose clock the test moves by hand
+func managerAt(clock *time.Time) *Manager {
+	rm := NewManager(nil, 100000)
+	rm.now = func() time.Time { return *clock }
+	return rm
+}
+
+func criticalEvent(symbol string, lossPct float64) *Event {
+	return &Event{
+		EventType: "POSITION_CRITICAL",
+		Severity:  "CRITICAL",
+		Symbol:    symbol,
+		Details:   fmt.Sprintf("%s down %.1f%%", symbol, lossPct),
+	}
+}
+
+func TestCapOrderSizing_UsesPortfolioCap(t *testing.T) {
+	rm := NewManager(nil, 100000)
+	rm.MaxPortfolioRiskPercent = 6
+	cfg := &strategy.OrderConfig{}
+
+	rm.CapOrderSizing(cfg)
+	if cfg.MaxPortfolioRiskPercent != 6 {
+		t.Errorf("MaxPortfolioRiskPercent = %.1f, want the manager's 6", cfg.MaxPortfolioRiskPercent)
+	}
+
+	uncapped := &strategy.OrderConfig{}
+	var none *Manager
+	none.CapOrderSizing(uncapped)
+	if uncapped.MaxPortfolioRiskPercent != 0 {
+		t.Errorf("MaxPortfolioRiskPercent = %.1f with no risk manager, want 0", uncapped.MaxPortfolioRiskPercent)
+	}
+}
+
+func TestRecordCriticalPosition_CoolsDownRepeats(t *testing.T) {
+	clock := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	rm := managerAt(&clock)
+	rm.SetAlertCooldown(5 * time.Minute)
+
+	// a monitor pass every 30s while AAPL stays underwater
+	for i := 0; i < 6; i++ {
+		rm.RecordCriticalPosition(criticalEvent("AAPL", 8+float64(i)))
+		clock = clock.Add(30 * time.Second)
+	}
+	rm.RecordCriticalPosition(criticalEvent("MSFT", 9))
+
+	events := rm.GetRiskEvents(50)
+	if len(events) != 2 {
+		t.Fatalf("events = %d, want one for AAPL and one for MSFT", len(events))
+	}
+	aapl := events[0]
+	if aapl.Symbol != "AAPL" || aapl.Occurrences != 6 {
+		t.Errorf("AAPL event = %+v, want 6 occurrences folded into one", aapl)
+	}
+	if aapl.Details != "AAPL down 13.0%" || !aapl.LastSeen.After(aapl.Timestamp) {
+		t.Errorf("AAPL event = %+v, want the latest details and last seen", aapl)
+	}
+
+	// past the cooldown a fresh event is recorded
+	clock = clock.Add(5 * time.Minute)
+	rm.RecordCriticalPosition(criticalEvent("AAPL", 15))
+	if events := rm.GetRiskEvents(50); len(events) != 3 {
+		t.Errorf("events = %d after the cooldown, want a new AAPL event", len(events))
+	}
+}
+
+func TestRecordRiskEvent_EscalatesSeverity(t *testing.T) {
+	clock := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	rm := managerAt(&clock)
+
+	rm.recordRiskEvent(&Event{EventType: "PORTFOLIO_RISK_EXCEEDED", Severity: "WARNING", Details: "11%"})
+	clock = clock.Add(time.Minute)
+	rm.recordRiskEvent(&Event{EventType: "PORTFOLIO_RISK_EXCEEDED", Severity: "CRITICAL", Details: "15%"})
+	clock = clock.Add(time.Minute)
+	rm.recordRiskEvent(&Event{EventType: "PORTFOLIO_RISK_EXCEEDED", Severity: "WARNING", Details: "12%"})
+
+	events := rm.GetRiskEvents(50)
+	if len(events) != 1 {
+		t.Fatalf("events = %d, want 1", len(events))
+	}
+	if events[0].Severity != "CRITICAL" || events[0].Occurrences != 3 {
+		t.Errorf("event = %+v, want CRITICAL kept after escalating, 3 occurrences", events[0])
+	}
+}
+
+func TestSendAlert_CoolsDownIdenticalAlerts(t *testing.T) {
+	clock := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	rm := managerAt(&clock)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var sent []string
+	rm.RegisterAlertCallback(func(alert *Alert) {
+		defer wg.Done()
+		mu.Lock()
+		sent = append(sent, alert.Level+" "+alert.Symbol)
+		mu.Unlock()
+	})
+	send := func(level, symbol string, delivered bool) {
+		if delivered {
+			wg.Add(1)
+		}
+		rm.SendAlert(&Alert{Level: level, Title: "Position critical", Symbol: symbol})
+		clock = clock.Add(time.Minute)
+	}
+
+	send("WARNING", "AAPL", true)
+	send("WARNING", "AAPL", false)
+	send("CRITICAL", "AAPL", true) // escalation goes through
+	send("CRITICAL", "AAPL", false)
+	send("CRITICAL", "TSLA", true)
+	clock = clock.Add(3 * time.Minute)
+	send("CRITICAL", "AAPL", true) // cooldown over
+	wg.Wait()
+
+	if len(sent) != 4 {
+		t.Errorf("sent = %v, want 4 alerts", sent)
+	}
+
+	rm.SetAlertCooldown(0)
+	send("INFO", "NVDA", true)
+	send("INFO", "NVDA", true)
+	wg.Wait()
+	if len(sent) != 6 {
+		t.Errorf("sent = %v, want every alert with the cooldown off", sent)
+	}
+}
+
+func TestCalculatePortfolioRiskWithCorrelation(t *testing.T) {
+	rm := NewManager(nil, 100000)
+	positions := []*position.OpenPosition{
+		{Symbol: "AAPL", Quantity: 100, EntryPrice: 150, StopLossPrice: 145}, // $500 at risk
+		{Symbol: "MSFT", Quantity: 50, EntryPrice: 400, StopLossPrice: 390},  // $500 at risk
+	}
+
+	simple := rm.CalculatePortfolioRisk(positions)
+	if simple.TotalRiskAmount != 1000 || simple.CorrelationFactor != 0 || simple.CorrelationAdjustedRisk != 0 {
+		t.Fatalf("simple risk = %.2f (factor %.2f, adjusted %.2f), want the plain 1000", simple.TotalRiskAmount,
+			simple.CorrelationFactor, simple.CorrelationAdjustedRisk)
+	}
+
+	// MSFT moves exactly with AAPL at twice the size: correlation 1 counts the risk twice
+	correlated := rm.CalculatePortfolioRiskWithCorrelation(positions, map[string][]float64{
+		"AAPL": {0.01, -0.02, 0.015, 0.005, -0.01},
+		"MSFT": {0.02, -0.04, 0.03, 0.01, -0.02},
+	})
+	if correlated.CorrelationFactor != 2 || correlated.CorrelationAdjustedRisk != 2000 {
+		t.Errorf("correlated risk = %.2f (factor %.2f), want 2000 (factor 2)",
+			correlated.CorrelationAdjustedRisk, correlated.CorrelationFactor)
+	}
+	// the stop risk itself, and the over-risk check on it, are untouched
+	if correlated.TotalRiskAmount != 1000 || correlated.TotalRiskPercent != 1 || correlated.IsOverRisk {
+		t.Errorf("correlated stop risk = %.2f (%.2f%%, over %v), want 1000 (1%%, not over)",
+			correlated.TotalRiskAmount, correlated.TotalRiskPercent, correlated.IsOverRisk)
+	}
+
+	// returns with zero correlation leave the sum alone
+	uncorrelated := rm.CalculatePortfolioRiskWithCorrelation(positions, map[string][]float64{
+		"AAPL": {0.01, -0.01, 0.01, -0.01},
+		"MSFT": {0.01, 0.01, -0.01, -0.01},
+	})
+	if uncorrelated.CorrelationFactor != 1 || uncorrelated.CorrelationAdjustedRisk != 1000 {
+		t.Errorf("uncorrelated risk = %.2f (factor %.2f), want 1000 (factor 1)", uncorrelated.CorrelationAdjustedRisk, uncorrelated.CorrelationFactor)
+	}
+
+	// a hedge never shrinks it, and a symbol without returns counts as uncorrelated
+	hedged := rm.CalculatePortfolioRiskWithCorrelation(positions, map[string][]float64{
+		"AAPL": {0.01, -0.02, 0.015},
+		"MSFT": {-0.01, 0.02, -0.015},
+	})
+	missing := rm.CalculatePortfolioRiskWithCorrelation(positions, map[string][]float64{"AAPL": {0.01, -0.02}})
+	if hedged.CorrelationAdjustedRisk != 1000 || missing.CorrelationAdjustedRisk != 1000 {
+		t.Errorf("hedged = %.2f, missing returns = %.2f; want both 1000", hedged.CorrelationAdjustedRisk, missing.CorrelationAdjustedRisk)
+	}
+}
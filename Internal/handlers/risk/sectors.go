@@ -0,0 +1,176 @@
+package risk
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+// sector for symbols nothing could resolve; it is tracked but never held to MaxSameSectorPositions
+const UnknownSector = "UNKNOWN"
+
+// how long a sector lookup against the store may take
+const sectorLookupTimeout = 3 * time.Second
+
+// subset of queries used to cache resolved sectors (*database.Queries satisfies it)
+type SectorStore interface {
+	GetSymbolSector(ctx context.Context, symbol string) (string, error)
+	UpsertSymbolSector(ctx context.Context, arg database.UpsertSymbolSectorParams) error
+}
+
+// sectors of commonly traded names, the fallback when the store has no row for a symbol
+var knownSectors = map[string]string{
+	"AAPL": "TECHNOLOGY", "MSFT": "TECHNOLOGY", "NVDA": "TECHNOLOGY", "AMD": "TECHNOLOGY", "INTC": "TECHNOLOGY",
+	"AVGO": "TECHNOLOGY", "ORCL": "TECHNOLOGY", "CRM": "TECHNOLOGY", "ADBE": "TECHNOLOGY", "CSCO": "TECHNOLOGY",
+	"QCOM": "TECHNOLOGY", "IBM": "TECHNOLOGY", "MU": "TECHNOLOGY", "TXN": "TECHNOLOGY", "PLTR": "TECHNOLOGY",
+	"GOOGL": "COMMUNICATION", "GOOG": "COMMUNICATION", "META": "COMMUNICATION", "NFLX": "COMMUNICATION",
+	"DIS": "COMMUNICATION", "T": "COMMUNICATION", "VZ": "COMMUNICATION", "CMCSA": "COMMUNICATION",
+	"AMZN": "CONSUMER_DISCRETIONARY", "TSLA": "CONSUMER_DISCRETIONARY", "HD": "CONSUMER_DISCRETIONARY",
+	"MCD": "CONSUMER_DISCRETIONARY", "NKE": "CONSUMER_DISCRETIONARY", "SBUX": "CONSUMER_DISCRETIONARY",
+	"WMT": "CONSUMER_STAPLES", "COST": "CONSUMER_STAPLES", "PG": "CONSUMER_STAPLES", "KO": "CONSUMER_STAPLES",
+	"PEP": "CONSUMER_STAPLES",
+	"JPM": "FINANCIALS", "BAC": "FINANCIALS", "WFC": "FINANCIALS", "GS": "FINANCIALS", "MS": "FINANCIALS",
+	"V": "FINANCIALS", "MA": "FINANCIALS", "PYPL": "FINANCIALS", "COIN": "FINANCIALS",
+	"JNJ": "HEALTHCARE", "UNH": "HEALTHCARE", "PFE": "HEALTHCARE", "MRK": "HEALTHCARE", "ABBV": "HEALTHCARE",
+	"LLY": "HEALTHCARE",
+	"XOM": "ENERGY", "CVX": "ENERGY", "COP": "ENERGY", "OXY": "ENERGY",
+	"BA": "INDUSTRIALS", "CAT": "INDUSTRIALS", "GE": "INDUSTRIALS", "UPS": "INDUSTRIALS",
+}
+
+// resolves a symbol's sector from an in-memory cache, then the sectors table, then the built-in list (crypto
+// pairs are all CRYPTO); built-in answers are written back to the table
+type SectorResolver struct {
+	store SectorStore
+	mu    sync.RWMutex
+	cache map[string]string
+}
+
+// store may be nil, leaving only the built-in list
+func NewSectorResolver(store SectorStore) *SectorResolver {
+	return &SectorResolver{store: store, cache: make(map[string]string)}
+}
+
+func (r *SectorResolver) GetSymbolSector(symbol string) (string, error) {
+	symbol = utils.NormalizeSymbol(symbol, "")
+	if symbol == "" {
+		return "", errors.New("symbol is required")
+	}
+
+	r.mu.RLock()
+	sector, ok := r.cache[symbol]
+	r.mu.RUnlock()
+	if ok {
+		return sector, nil
+	}
+
+	if r.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), sectorLookupTimeout)
+		sector, err := r.store.GetSymbolSector(ctx, symbol)
+		cancel()
+		if err == nil && sector != "" {
+			r.remember(symbol, sector)
+			return sector, nil
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Warning: sector lookup for %s failed: %v", symbol, err)
+		}
+	}
+
+	sector, ok = knownSectors[symbol]
+	if !ok && utils.DetectAssetType(symbol, "") == utils.AssetTypeCrypto {
+		sector, ok = "CRYPTO", true
+	}
+	if !ok {
+		return "", fmt.Errorf("no sector known for %s", symbol)
+	}
+
+	r.remember(symbol, sector)
+	if r.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), sectorLookupTimeout)
+		defer cancel()
+		if err := r.store.UpsertSymbolSector(ctx, database.UpsertSymbolSectorParams{Symbol: symbol, Sector: sector, Source: "builtin"}); err != nil {
+			log.Printf("Warning: could not cache sector for %s: %v", symbol, err)
+		}
+	}
+	return sector, nil
+}
+
+// GetSymbolSector with UnknownSector for anything it can't resolve; a nil resolver resolves nothing
+func (r *SectorResolver) SectorOrUnknown(symbol string) string {
+	if r == nil {
+		return UnknownSector
+	}
+	sector, err := r.GetSymbolSector(symbol)
+	if err != nil {
+		return UnknownSector
+	}
+	return sector
+}
+
+func (r *SectorResolver) remember(symbol, sector string) {
+	r.mu.Lock()
+	r.cache[symbol] = sector
+	r.mu.Unlock()
+}
+
+// SECTOR LIMITS
+
+// points the sector limit at a resolver for the symbols already held; without one they all count as UNKNOWN
+func (rm *Manager) SetSectorResolver(resolver *SectorResolver) {
+	rm.positionsMutex.Lock()
+	defer rm.positionsMutex.Unlock()
+	rm.sectors = resolver
+}
+
+// the symbol's sector from the resolver set with SetSectorResolver, UnknownSector without one
+func (rm *Manager) SymbolSector(symbol string) string {
+	rm.positionsMutex.RLock()
+	resolver := rm.sectors
+	rm.positionsMutex.RUnlock()
+	return resolver.SectorOrUnknown(symbol)
+}
+
+// whether opening symbol in sector keeps it within MaxSameSectorPositions, counted from the symbols held right
+// now rather than a running tally, so positions closed by any route free their slot. Adding to a held symbol,
+// or one in UnknownSector, always fits; a refusal is recorded as a risk event. PositionsBySymbol and
+// PositionsBySector are refreshed from held on every check
+func (rm *Manager) CanAddPosition(symbol, sector string, held []string) (bool, string) {
+	rm.positionsMutex.RLock()
+	resolver := rm.sectors
+	rm.positionsMutex.RUnlock()
+
+	bySymbol := make(map[string]int, len(held))
+	bySector := make(map[string]int)
+	for _, h := range held {
+		if bySymbol[h]++; bySymbol[h] == 1 {
+			bySector[resolver.SectorOrUnknown(h)]++
+		}
+	}
+	rm.positionsMutex.Lock()
+	rm.PositionsBySymbol, rm.PositionsBySector = bySymbol, bySector
+	rm.positionsMutex.Unlock()
+
+	if bySymbol[symbol] > 0 || sector == "" || sector == UnknownSector || rm.MaxSameSectorPositions <= 0 {
+		return true, ""
+	}
+	count := bySector[sector]
+	if count < rm.MaxSameSectorPositions {
+		return true, ""
+	}
+
+	reason := fmt.Sprintf("Sector limit reached: %d of %d %s positions already open", count, rm.MaxSameSectorPositions, sector)
+	rm.recordRiskEvent(&Event{
+		EventType: "MAX_SECTOR_POSITIONS_HIT",
+		Severity:  "WARNING",
+		Symbol:    symbol,
+		Details:   reason,
+	})
+	return false, reason
+}
@@ -0,0 +1,123 @@
+package risk
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+// an in-memory sectors table
+type memorySectorStore struct {
+	rows    map[string]string
+	lookups int
+	err     error
+}
+
+func (s *memorySectorStore) GetSymbolSector(ctx context.Context, symbol string) (string, error) {
+	s.lookups++
+	if s.err != nil {
+		return "", s.err
+	}
+	sector, ok := s.rows[symbol]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return sector, nil
+}
+
+func (s *memorySectorStore) UpsertSymbolSector(ctx context.Context, arg database.UpsertSymbolSectorParams) error {
+	s.rows[arg.Symbol] = arg.Sector
+	return nil
+}
+
+func TestSectorResolver_GetSymbolSector(t *testing.T) {
+	store := &memorySectorStore{rows: map[string]string{"SHOP": "TECHNOLOGY", "AAPL": "HARDWARE"}}
+	resolver := NewSectorResolver(store)
+
+	// a stored row wins over the built-in list
+	if sector, err := resolver.GetSymbolSector("aapl"); err != nil || sector != "HARDWARE" {
+		t.Errorf("AAPL = %q, %v; want the stored HARDWARE", sector, err)
+	}
+	if sector, _ := resolver.GetSymbolSector("SHOP"); sector != "TECHNOLOGY" {
+		t.Errorf("SHOP = %q, want TECHNOLOGY from the store", sector)
+	}
+
+	// built-in answers are written back, and repeats come from memory
+	if sector, err := resolver.GetSymbolSector("XOM"); err != nil || sector != "ENERGY" {
+		t.Errorf("XOM = %q, %v; want ENERGY", sector, err)
+	}
+	if store.rows["XOM"] != "ENERGY" {
+		t.Errorf("XOM was not cached in the store: %v", store.rows)
+	}
+	lookups := store.lookups
+	resolver.GetSymbolSector("XOM")
+	if store.lookups != lookups {
+		t.Errorf("repeat lookup hit the store again")
+	}
+
+	if sector, _ := resolver.GetSymbolSector("BTC/USD"); sector != "CRYPTO" {
+		t.Errorf("BTC/USD = %q, want CRYPTO", sector)
+	}
+	if _, err := resolver.GetSymbolSector("ZZZZ"); err == nil {
+		t.Error("want an error for a symbol nothing knows")
+	}
+	if got := resolver.SectorOrUnknown("ZZZZ"); got != UnknownSector {
+		t.Errorf("SectorOrUnknown(ZZZZ) = %q, want %q", got, UnknownSector)
+	}
+
+	// a broken store still leaves the built-in list
+	broken := NewSectorResolver(&memorySectorStore{rows: map[string]string{}, err: errors.New("connection refused")})
+	if sector, err := broken.GetSymbolSector("NVDA"); err != nil || sector != "TECHNOLOGY" {
+		t.Errorf("NVDA with a failing store = %q, %v; want TECHNOLOGY", sector, err)
+	}
+	if got := (*SectorResolver)(nil).SectorOrUnknown("NVDA"); got != UnknownSector {
+		t.Errorf("nil resolver = %q, want %q", got, UnknownSector)
+	}
+}
+
+func TestCanAddPosition_BlocksFourthTechPosition(t *testing.T) {
+	rm := NewManager(nil, 100000)
+	rm.SetSectorResolver(NewSectorResolver(nil))
+	held := []string{"AAPL", "MSFT", "NVDA", "XOM"}
+
+	if ok, reason := rm.CanAddPosition("AMD", rm.SymbolSector("AMD"), held); ok || reason == "" {
+		t.Fatalf("CanAddPosition(AMD) = %v, %q; want a 4th tech position refused", ok, reason)
+	}
+	if rm.PositionsBySector["TECHNOLOGY"] != 3 || rm.PositionsBySector["ENERGY"] != 1 {
+		t.Errorf("positions by sector = %v, want 3 tech and 1 energy", rm.PositionsBySector)
+	}
+	if events := rm.GetRiskEvents(10); len(events) != 1 || events[0].EventType != "MAX_SECTOR_POSITIONS_HIT" {
+		t.Errorf("events = %+v, want one MAX_SECTOR_POSITIONS_HIT", events)
+	}
+
+	// other sectors, unknown sectors and adds to a held symbol still fit
+	for _, symbol := range []string{"CVX", "ZZZA", "AAPL"} {
+		if ok, reason := rm.CanAddPosition(symbol, rm.SymbolSector(symbol), held); !ok {
+			t.Errorf("CanAddPosition(%s) refused: %s", symbol, reason)
+		}
+	}
+	unknowns := []string{"ZZZA", "ZZZB", "ZZZC"}
+	if ok, _ := rm.CanAddPosition("ZZZD", UnknownSector, unknowns); !ok {
+		t.Error("a 4th UNKNOWN position was refused")
+	}
+}
+
+func TestCanAddPosition_ClosedPositionFreesSlot(t *testing.T) {
+	rm := NewManager(nil, 100000)
+	rm.SetSectorResolver(NewSectorResolver(nil))
+
+	if ok, _ := rm.CanAddPosition("AMD", "TECHNOLOGY", []string{"AAPL", "MSFT", "NVDA"}); ok {
+		t.Fatal("4th tech position allowed while three are held")
+	}
+	// MSFT closed by whatever route (stop, OCO leg, EOD flatten): the next check sees only what is still held
+	if ok, reason := rm.CanAddPosition("AMD", "TECHNOLOGY", []string{"AAPL", "NVDA"}); !ok {
+		t.Errorf("AMD refused after MSFT closed: %s", reason)
+	}
+	// and reopening MSFT once AMD is in is refused again
+	if ok, _ := rm.CanAddPosition("MSFT", "TECHNOLOGY", []string{"AAPL", "NVDA", "AMD"}); ok {
+		t.Error("reopening MSFT allowed with three tech positions held")
+	}
+}
@@ -0,0 +1,75 @@
+package risk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// how long one webhook delivery may take before it is dropped
+const webhookAlertTimeout = 5 * time.Second
+
+var webhookHTTPClient = &http.Client{Timeout: webhookAlertTimeout}
+
+// the JSON body posted for an alert: content is what Discord shows, text what Slack shows, and the remaining
+// fields carry the alert for any other receiver
+type webhookAlertPayload struct {
+	Content   string                 `json:"content"`
+	Text      string                 `json:"text"`
+	Level     string                 `json:"level"`
+	Title     string                 `json:"title"`
+	Message   string                 `json:"message"`
+	Symbol    string                 `json:"symbol,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// an AlertCallback that POSTs each alert as JSON to url, e.g. a Discord or Slack incoming webhook. SendAlert
+// already runs callbacks on their own goroutines; failed or timed-out deliveries are logged and dropped
+func NewWebhookAlertCallback(url string) AlertCallback {
+	return func(alert *Alert) {
+		if err := postWebhookAlert(url, alert); err != nil {
+			log.Printf("Failed to deliver %s alert %q to webhook: %v\n", alert.Level, alert.Title, err)
+		}
+	}
+}
+
+func postWebhookAlert(url string, alert *Alert) error {
+	summary := fmt.Sprintf("**[%s] %s**", alert.Level, alert.Title)
+	if alert.Symbol != "" {
+		summary += " (" + alert.Symbol + ")"
+	}
+	if alert.Message != "" {
+		summary += "\n" + alert.Message
+	}
+
+	body, err := json.Marshal(webhookAlertPayload{
+		Content:   summary,
+		Text:      summary,
+		Level:     alert.Level,
+		Title:     alert.Title,
+		Message:   alert.Message,
+		Symbol:    alert.Symbol,
+		Timestamp: alert.Timestamp,
+		Data:      alert.Data,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding alert: %w", err)
+	}
+
+	resp, err := webhookHTTPClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package risk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookAlertCallback_PostsDailyLossAlert(t *testing.T) {
+	received := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("webhook got %s with content type %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decoding webhook body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+		received <- payload
+	}))
+	defer server.Close()
+
+	rm := NewManager(nil, 100000)
+	rm.RegisterAlertCallback(NewWebhookAlertCallback(server.URL))
+	rm.LogTradeLoss("TSLA", 2500) // 2.5% against the 2% limit
+
+	var payload map[string]interface{}
+	select {
+	case payload = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook never received the daily loss alert")
+	}
+
+	if payload["level"] != "CRITICAL" || payload["title"] != "DAILY LOSS LIMIT HIT" || payload["symbol"] != "TSLA" {
+		t.Errorf("payload = %v, want the CRITICAL daily loss alert for TSLA", payload)
+	}
+	content, _ := payload["content"].(string)
+	if !strings.HasPrefix(content, "**[CRITICAL] DAILY LOSS LIMIT HIT** (TSLA)\n") || payload["text"] != content {
+		t.Errorf("content = %q, text = %v; want the same Discord/Slack summary in both", content, payload["text"])
+	}
+	if !strings.Contains(payload["message"].(string), "2.50%") {
+		t.Errorf("message = %v, want the daily loss percent", payload["message"])
+	}
+	data, _ := payload["data"].(map[string]interface{})
+	if data["dailyLoss"] != 2500.0 || data["limit"] != 2000.0 {
+		t.Errorf("data = %v, want dailyLoss 2500 and limit 2000", payload["data"])
+	}
+	if _, err := time.Parse(time.RFC3339, payload["timestamp"].(string)); err != nil {
+		t.Errorf("timestamp %v: %v", payload["timestamp"], err)
+	}
+}
+
+func TestWebhookAlertCallback_ReportsFailedDelivery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	if err := postWebhookAlert(server.URL, &Alert{Level: "INFO", Title: "test"}); err == nil {
+		t.Error("want an error for a 400 from the webhook")
+	}
+	server.Close()
+	if err := postWebhookAlert(server.URL, &Alert{Level: "INFO", Title: "test"}); err == nil {
+		t.Error("want an error when the webhook is unreachable")
+	}
+}
@@ -13,6 +13,10 @@ type TradeSettings struct {
 	TradingHoursOnly bool    `json:"tradingHoursOnly"`
 	AutoStopLoss     bool    `json:"autoStopLoss"`
 	AutoProfitTaking bool    `json:"autoProfitTaking"`
+
+	// order config percents applied to the running server; 0 leaves the current value
+	StopLossPercent   float64 `json:"stopLossPercent,omitempty"`
+	TakeProfitPercent float64 `json:"takeProfitPercent,omitempty"`
 }
 
 type NotificationSettings struct {
@@ -25,6 +25,7 @@ func NewCatalystDetector() *CatalystDetector {
 		regulatoryPattern: []*regexp.Regexp{
 			regexp.MustCompile(`(?i)(FDA|approval|clearance|ban|sanction|investigation)`),
 			regexp.MustCompile(`(?i)(lawsuit|settlement|fine|regulation)`),
+			regexp.MustCompile(`(?i)(fraud|recall|subpoena|rejection|rejects)`),
 		},
 		marketPattern: []*regexp.Regexp{
 			regexp.MustCompile(`(?i)(stock\s*split|dividend|buyback|delisting)`),
@@ -0,0 +1,57 @@
+package newsscraping
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type finnhubEarningsCalendar struct {
+	EarningsCalendar []struct {
+		Date   string `json:"date"`
+		Symbol string `json:"symbol"`
+		Hour   string `json:"hour"` // bmo, amc or dmh
+	} `json:"earningsCalendar"`
+}
+
+// the symbol's first scheduled earnings report between from and to, by calendar date
+func (c *FinnhubClient) NextEarnings(symbol string, from, to time.Time) (time.Time, bool, error) {
+	if c.apiKey == "" {
+		return time.Time{}, false, fmt.Errorf("FINNHUB_API_KEY not set in environment")
+	}
+
+	url := fmt.Sprintf(
+		"https://finnhub.io/api/v1/calendar/earnings?symbol=%s&from=%s&to=%s&token=%s",
+		symbol, from.Format("2006-01-02"), to.Format("2006-01-02"), c.apiKey,
+	)
+
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to fetch earnings calendar: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return time.Time{}, false, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var calendar finnhubEarningsCalendar
+	if err := json.NewDecoder(resp.Body).Decode(&calendar); err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to parse earnings calendar: %v", err)
+	}
+
+	var next time.Time
+	for _, entry := range calendar.EarningsCalendar {
+		date, err := time.Parse("2006-01-02", entry.Date)
+		if err != nil || (entry.Symbol != "" && entry.Symbol != symbol) {
+			continue
+		}
+		if next.IsZero() || date.Before(next) {
+			next = date
+		}
+	}
+	return next, !next.IsZero(), nil
+}
@@ -38,6 +38,7 @@ func NewSentimentAnalyzer() *SentimentAnalyzer {
 			"catastrophic": 1.0, "disaster": 1.0, "crisis": 0.95, "bankruptcy": 0.95,
 			"savaged": 0.95, "plummet": 0.95, "tumble": 0.95, "rout": 0.95,
 			"hammered": 0.9, "slaughter": 0.9, "massacre": 0.9, "panic": 0.9,
+			"fraud": 0.95, "rejection": 0.9, "rejects": 0.9, "rejected": 0.9,
 
 			// Moderate negative (0.7-0.89)
 			"bearish": 0.85, "downgrade": 0.85, "warning": 0.85, "alert": 0.85,
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Tag watchlist entries with the scan profile that added them
+ALTER TABLE watchlist ADD COLUMN IF NOT EXISTS profile TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_profile ON watchlist(profile);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_watchlist_profile;
+ALTER TABLE watchlist DROP COLUMN IF EXISTS profile;
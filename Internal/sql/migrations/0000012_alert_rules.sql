@@ -0,0 +1,14 @@
+-- +goose Up
+-- User-defined alert rules evaluated on the scanner tick
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    symbol TEXT, -- NULL applies the rule to every watchlist symbol
+    conditions TEXT NOT NULL, -- JSON array of {field, operator, value}
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_triggered_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS alert_rules;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Audit log of computed signals for later signal-quality analysis
+-- (separate from the legacy signals table, which trades.signal_id references)
+CREATE TABLE IF NOT EXISTS signal_history (
+    id SERIAL PRIMARY KEY,
+    symbol TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT 'scan', -- scan, analysis
+    timeframe TEXT NOT NULL DEFAULT '1Day',
+    recommendation TEXT NOT NULL,
+    confidence DOUBLE PRECISION NOT NULL,
+    ensemble_score DOUBLE PRECISION NOT NULL,
+    price DOUBLE PRECISION NOT NULL,
+    components TEXT NOT NULL, -- JSON array of {name, score, weight}
+    computed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_signal_history_symbol_computed ON signal_history(symbol, computed_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_signal_history_symbol_computed;
+DROP TABLE IF EXISTS signal_history;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Trades pulled from Alpaca order history are tagged so re-imports can skip them by order ID
+ALTER TABLE trades ADD COLUMN IF NOT EXISTS imported BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trades_imported_order ON trades(alpaca_order_id) WHERE imported;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_trades_imported_order;
+ALTER TABLE trades DROP COLUMN IF EXISTS imported;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- One row per profile scan, so the number and quality of opportunities can be charted over time
+CREATE TABLE IF NOT EXISTS scan_runs (
+    id SERIAL PRIMARY KEY,
+    profile_name TEXT NOT NULL,
+    symbols_scanned INT NOT NULL DEFAULT 0,
+    candidates_found INT NOT NULL DEFAULT 0,
+    avg_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ran_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_scan_runs_ran_at ON scan_runs(ran_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_scan_runs_ran_at;
+DROP TABLE IF EXISTS scan_runs;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Sub-scores behind each watchlist score as of its last update
+ALTER TABLE watchlist ADD COLUMN IF NOT EXISTS technical_score REAL;
+ALTER TABLE watchlist ADD COLUMN IF NOT EXISTS news_score REAL;
+ALTER TABLE watchlist ADD COLUMN IF NOT EXISTS pattern_score REAL;
+ALTER TABLE watchlist ADD COLUMN IF NOT EXISTS sr_score REAL;
+
+-- +goose Down
+ALTER TABLE watchlist DROP COLUMN IF EXISTS sr_score;
+ALTER TABLE watchlist DROP COLUMN IF EXISTS pattern_score;
+ALTER TABLE watchlist DROP COLUMN IF EXISTS news_score;
+ALTER TABLE watchlist DROP COLUMN IF EXISTS technical_score;
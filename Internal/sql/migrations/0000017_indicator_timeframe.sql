@@ -0,0 +1,18 @@
+-- +goose Up
+-- Indicators are keyed by (symbol, timeframe, UTC timestamp) so daily and intraday values don't overwrite each other
+ALTER TABLE rsi_calculation ADD COLUMN IF NOT EXISTS timeframe TEXT NOT NULL DEFAULT '1Day';
+ALTER TABLE rsi_calculation DROP CONSTRAINT IF EXISTS rsi_calculation_symbol_calculation_timestamp_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rsi_symbol_timeframe_timestamp ON rsi_calculation(symbol, timeframe, calculation_timestamp);
+
+ALTER TABLE atr_calculation ADD COLUMN IF NOT EXISTS timeframe TEXT NOT NULL DEFAULT '1Day';
+ALTER TABLE atr_calculation DROP CONSTRAINT IF EXISTS atr_calculation_symbol_calculation_timestamp_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_atr_symbol_timeframe_timestamp ON atr_calculation(symbol, timeframe, calculation_timestamp);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_atr_symbol_timeframe_timestamp;
+ALTER TABLE atr_calculation DROP COLUMN IF EXISTS timeframe;
+ALTER TABLE atr_calculation ADD CONSTRAINT atr_calculation_symbol_calculation_timestamp_key UNIQUE (symbol, calculation_timestamp);
+
+DROP INDEX IF EXISTS idx_rsi_symbol_timeframe_timestamp;
+ALTER TABLE rsi_calculation DROP COLUMN IF EXISTS timeframe;
+ALTER TABLE rsi_calculation ADD CONSTRAINT rsi_calculation_symbol_calculation_timestamp_key UNIQUE (symbol, calculation_timestamp);
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Periodic portfolio heat (risk-at-stop as a percent of equity), charted against the equity curve
+CREATE TABLE IF NOT EXISTS portfolio_heat_snapshots (
+    id SERIAL PRIMARY KEY,
+    heat_percent DOUBLE PRECISION NOT NULL,
+    band TEXT NOT NULL,
+    risk_amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+    equity DOUBLE PRECISION NOT NULL DEFAULT 0,
+    open_positions INT NOT NULL DEFAULT 0,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_heat_recorded_at ON portfolio_heat_snapshots(recorded_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_portfolio_heat_recorded_at;
+DROP TABLE IF EXISTS portfolio_heat_snapshots;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Price the trade was decided at (bar close / signal price) and the fill's slippage against it, in basis points
+ALTER TABLE trades ADD COLUMN IF NOT EXISTS expected_price DECIMAL(10, 4);
+ALTER TABLE trades ADD COLUMN IF NOT EXISTS slippage_bps DOUBLE PRECISION;
+ALTER TABLE trades ADD COLUMN IF NOT EXISTS order_type VARCHAR(20);
+
+-- +goose Down
+ALTER TABLE trades DROP COLUMN IF EXISTS order_type;
+ALTER TABLE trades DROP COLUMN IF EXISTS slippage_bps;
+ALTER TABLE trades DROP COLUMN IF EXISTS expected_price;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Symbols whose bar fetches keep failing (delisted or renamed); flagged ones are skipped by scans until cleared
+CREATE TABLE IF NOT EXISTS problem_symbols (
+    symbol TEXT PRIMARY KEY,
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    flagged_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_problem_symbols_flagged ON problem_symbols(flagged);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_problem_symbols_flagged;
+DROP TABLE IF EXISTS problem_symbols;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Finished backtest runs; metrics holds the full results payload served by /api/backtest/results
+CREATE TABLE IF NOT EXISTS backtests (
+    id TEXT PRIMARY KEY,
+    symbol TEXT NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    initial_capital DOUBLE PRECISION NOT NULL,
+    final_balance DOUBLE PRECISION NOT NULL,
+    metrics JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_backtests_created_at ON backtests(created_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_backtests_created_at;
+DROP TABLE IF EXISTS backtests;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Cached sector per symbol for the risk manager's same-sector position limit; rows can be edited to override
+CREATE TABLE IF NOT EXISTS sectors (
+    symbol TEXT PRIMARY KEY,
+    sector TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS sectors;
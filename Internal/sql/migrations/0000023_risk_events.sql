@@ -0,0 +1,19 @@
+-- +goose Up
+-- Risk limit breaches logged by the risk manager; pruned by the retention job
+CREATE TABLE IF NOT EXISTS risk_events (
+    id SERIAL PRIMARY KEY,
+    timestamp TIMESTAMP NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    symbol VARCHAR(10),
+    details TEXT,
+    account_value DECIMAL(12, 4),
+    daily_loss DECIMAL(12, 4),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_risk_events_timestamp ON risk_events(timestamp);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_risk_events_timestamp;
+DROP TABLE IF EXISTS risk_events;
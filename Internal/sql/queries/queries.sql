@@ -7,9 +7,9 @@ ORDER BY timestamp ASC
 LIMIT $3;
 
 -- name: SaveRSI :exec
-INSERT INTO rsi_calculation (symbol, calculation_timestamp, rsi_value)
-VALUES ($1, $2, $3)
-ON CONFLICT (symbol, calculation_timestamp)
+INSERT INTO rsi_calculation (symbol, timeframe, calculation_timestamp, rsi_value)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (symbol, timeframe, calculation_timestamp)
 DO UPDATE SET rsi_value = EXCLUDED.rsi_value;
 
 -- name: GetLatestRSI :one
@@ -35,39 +35,43 @@ ORDER BY calculation_timestamp DESC
 LIMIT 1;
 
 -- name: SaveATR :exec
-INSERT INTO atr_calculation (symbol, calculation_timestamp, atr_value)
-VALUES ($1, $2, $3)
-ON CONFLICT (symbol, calculation_timestamp)
+INSERT INTO atr_calculation (symbol, timeframe, calculation_timestamp, atr_value)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (symbol, timeframe, calculation_timestamp)
 DO UPDATE SET atr_value = EXCLUDED.atr_value;
 
 -- name: GetRSIForDateRange :many
 SELECT calculation_timestamp, rsi_value
 FROM rsi_calculation
 WHERE symbol = $1
+  AND ($2::text = '' OR timeframe = $2)
 ORDER BY calculation_timestamp DESC
-LIMIT $2;
+LIMIT $3;
 
 -- name: GetATRForDateRange :many
 SELECT calculation_timestamp, atr_value
 FROM atr_calculation
 WHERE symbol = $1
+  AND ($2::text = '' OR timeframe = $2)
 ORDER BY calculation_timestamp DESC
-LIMIT $2;
+LIMIT $3;
 
 -- name: GetRSIByTimestampRange :many
 SELECT calculation_timestamp, rsi_value
 FROM rsi_calculation
 WHERE symbol = $1
-  AND calculation_timestamp >= $2
-  AND calculation_timestamp <= $3
+  AND timeframe = $2
+  AND calculation_timestamp >= $3
+  AND calculation_timestamp <= $4
 ORDER BY calculation_timestamp ASC;
 
 -- name: GetATRByTimestampRange :many
 SELECT calculation_timestamp, atr_value
 FROM atr_calculation
 WHERE symbol = $1
-  AND calculation_timestamp >= $2
-  AND calculation_timestamp <= $3
+  AND timeframe = $2
+  AND calculation_timestamp >= $3
+  AND calculation_timestamp <= $4
 ORDER BY calculation_timestamp ASC;
 
 -- name: SaveNewsArticle :exec
@@ -120,7 +124,8 @@ RETURNING id;
 
 -- name: GetWatchlist :many
 -- Get all watchlist items, ordered by score
-SELECT id, symbol, asset_type, score, reason, added_date, last_updated
+SELECT id, symbol, asset_type, score, reason, added_date, last_updated,
+       technical_score, news_score, pattern_score, sr_score
 FROM watchlist
 ORDER BY score DESC;
 
@@ -136,6 +141,12 @@ UPDATE watchlist
 SET score = $1, last_updated = CURRENT_TIMESTAMP
 WHERE symbol = $2;
 
+-- name: UpdateWatchlistScoreBreakdown :exec
+-- Store the sub-scores behind the latest watchlist score
+UPDATE watchlist
+SET technical_score = $1, news_score = $2, pattern_score = $3, sr_score = $4
+WHERE symbol = $5;
+
 -- name: RemoveFromWatchlist :exec
 -- Remove symbol from watchlist by actually deleting the record
 DELETE FROM watchlist
@@ -156,6 +167,20 @@ WHERE id IN (
   AND datetime(w.last_updated) < datetime('now', '-30 days')
 );
 
+-- name: AddScanCandidateToWatchlist :one
+-- Add a scan candidate tagged with its profile, skipping symbols already tracked
+INSERT INTO watchlist (symbol, asset_type, score, reason, added_date, last_updated, status, profile)
+VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'active', $5)
+ON CONFLICT (symbol) DO NOTHING
+RETURNING id;
+
+-- name: GetWatchlistByProfile :many
+-- Get active watchlist items added by a scan profile
+SELECT id, symbol, asset_type, score, reason, added_date, last_updated
+FROM watchlist
+WHERE profile = $1 AND status = 'active'
+ORDER BY score DESC;
+
 -- name: SkipSymbol :exec
 -- Add to skip backlog (recheck in 30 days)
 INSERT INTO skip_backlog (symbol, asset_type, reason, timestamp, recheck_after)
@@ -209,6 +234,23 @@ WHERE symbol = $1
 INSERT INTO trades (symbol, side, quantity, price, total_value, alpaca_order_id, status, created_at)
 VALUES ($1, $2, $3, $4, $5, $6, $7, NOW());
 
+-- name: LogTradeWithSlippage :exec
+-- Like LogTrade, also storing the price the trade was decided at and the fill's slippage against it
+INSERT INTO trades (symbol, side, quantity, price, total_value, alpaca_order_id, status, expected_price, slippage_bps, order_type, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW());
+
+-- name: GetSlippageSummary :many
+-- Average and worst slippage per symbol and order type for trades logged in [from, to), costliest first
+SELECT symbol,
+       COALESCE(order_type, 'market')::TEXT AS order_type,
+       COUNT(*) AS fills,
+       AVG(slippage_bps)::DOUBLE PRECISION AS avg_slippage_bps,
+       MAX(slippage_bps)::DOUBLE PRECISION AS worst_slippage_bps
+FROM trades
+WHERE slippage_bps IS NOT NULL AND created_at >= sqlc.arg(from_time) AND created_at < sqlc.arg(to_time)
+GROUP BY symbol, COALESCE(order_type, 'market')
+ORDER BY avg_slippage_bps DESC;
+
 -- name: GetTradeHistory :many
 SELECT id, symbol, side, quantity, price, total_value, alpaca_order_id, status, created_at, filled_at
 FROM trades
@@ -230,4 +272,179 @@ ORDER BY created_at DESC;
 -- name: UpdateTradeStatus :exec
 UPDATE trades
 SET status = $1, filled_at = NOW()
-WHERE alpaca_order_id = $2;
\ No newline at end of file
+WHERE alpaca_order_id = $2;
+
+-- name: ImportTrade :execrows
+-- Inserts a historical fill from Alpaca, skipping order IDs already in trades whether imported or app-logged
+INSERT INTO trades (symbol, side, quantity, price, total_value, alpaca_order_id, status, created_at, filled_at, imported)
+SELECT $1::varchar, $2::varchar, $3::decimal, $4::decimal, $5::decimal, $6::varchar, 'FILLED', $7::timestamp, $7::timestamp, TRUE
+WHERE NOT EXISTS (SELECT 1 FROM trades WHERE alpaca_order_id = $6::varchar)
+ON CONFLICT DO NOTHING;
+-- Alert Rule Queries
+
+-- name: CreateAlertRule :one
+INSERT INTO alert_rules (name, symbol, conditions, enabled)
+VALUES ($1, $2, $3, $4)
+RETURNING id, name, symbol, conditions, enabled, created_at, last_triggered_at;
+
+-- name: GetAlertRules :many
+SELECT id, name, symbol, conditions, enabled, created_at, last_triggered_at
+FROM alert_rules
+ORDER BY id;
+
+-- name: GetAlertRule :one
+SELECT id, name, symbol, conditions, enabled, created_at, last_triggered_at
+FROM alert_rules
+WHERE id = $1;
+
+-- name: GetEnabledAlertRules :many
+SELECT id, name, symbol, conditions, enabled, created_at, last_triggered_at
+FROM alert_rules
+WHERE enabled = TRUE
+ORDER BY id;
+
+-- name: UpdateAlertRule :one
+UPDATE alert_rules
+SET name = $2, symbol = $3, conditions = $4, enabled = $5
+WHERE id = $1
+RETURNING id, name, symbol, conditions, enabled, created_at, last_triggered_at;
+
+-- name: DeleteAlertRule :exec
+DELETE FROM alert_rules WHERE id = $1;
+
+-- name: MarkAlertRuleTriggered :exec
+UPDATE alert_rules SET last_triggered_at = CURRENT_TIMESTAMP WHERE id = $1;
+
+-- Signal Audit Queries
+
+-- name: InsertSignal :one
+INSERT INTO signal_history (symbol, source, timeframe, recommendation, confidence, ensemble_score, price, components)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id;
+
+-- name: GetSignalHistory :many
+SELECT id, symbol, source, timeframe, recommendation, confidence, ensemble_score, price, components, computed_at
+FROM signal_history
+WHERE symbol = $1
+ORDER BY computed_at DESC
+LIMIT $2;
+
+-- name: GetRecentSignals :many
+SELECT id, symbol, source, timeframe, recommendation, confidence, ensemble_score, price, components, computed_at
+FROM signal_history
+ORDER BY computed_at DESC
+LIMIT $1;
+
+-- name: InsertScanRun :one
+-- Record one profile scan for market-breadth trends
+INSERT INTO scan_runs (profile_name, symbols_scanned, candidates_found, avg_score, ran_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id;
+
+-- name: GetScanRuns :many
+-- Scan runs in [from, to), oldest first; an empty profile matches every profile
+SELECT id, profile_name, symbols_scanned, candidates_found, avg_score, ran_at
+FROM scan_runs
+WHERE ran_at >= sqlc.arg(from_time) AND ran_at < sqlc.arg(to_time)
+  AND (sqlc.arg(profile)::text = '' OR profile_name = sqlc.arg(profile)::text)
+ORDER BY ran_at;
+
+-- name: InsertHeatSnapshot :one
+-- Record the current portfolio heat for charting against equity
+INSERT INTO portfolio_heat_snapshots (heat_percent, band, risk_amount, equity, open_positions, recorded_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id;
+
+-- name: GetHeatSnapshots :many
+-- Heat snapshots in [from, to), oldest first
+SELECT id, heat_percent, band, risk_amount, equity, open_positions, recorded_at
+FROM portfolio_heat_snapshots
+WHERE recorded_at >= sqlc.arg(from_time) AND recorded_at < sqlc.arg(to_time)
+ORDER BY recorded_at;
+
+-- Retention Queries
+
+-- name: PruneSignalHistory :execrows
+DELETE FROM signal_history WHERE computed_at < $1;
+
+-- name: PruneNewsArticles :execrows
+DELETE FROM news_articles WHERE published_at < $1;
+
+-- name: PruneWhaleEvents :execrows
+DELETE FROM whale_events WHERE timestamp < $1;
+
+-- name: PruneRiskEvents :execrows
+DELETE FROM risk_events WHERE timestamp < $1;
+
+-- name: PruneBacktests :execrows
+DELETE FROM backtests WHERE created_at < $1;
+
+-- Problem Symbol Queries
+
+-- name: RecordSymbolFetchFailure :one
+-- Count one more consecutive fetch failure, flagging the symbol once the streak reaches the threshold
+INSERT INTO problem_symbols (symbol, consecutive_failures, last_error, flagged, flagged_at, updated_at)
+VALUES (sqlc.arg(symbol), 1, sqlc.arg(last_error), 1 >= sqlc.arg(threshold)::int,
+        CASE WHEN 1 >= sqlc.arg(threshold)::int THEN NOW() END, NOW())
+ON CONFLICT (symbol) DO UPDATE SET
+    consecutive_failures = problem_symbols.consecutive_failures + 1,
+    last_error = EXCLUDED.last_error,
+    flagged = problem_symbols.flagged OR problem_symbols.consecutive_failures + 1 >= sqlc.arg(threshold)::int,
+    flagged_at = COALESCE(problem_symbols.flagged_at,
+        CASE WHEN problem_symbols.consecutive_failures + 1 >= sqlc.arg(threshold)::int THEN NOW() END),
+    updated_at = NOW()
+RETURNING symbol, consecutive_failures, last_error, flagged, flagged_at, updated_at;
+
+-- name: ResetSymbolFetchFailures :exec
+-- A successful fetch ends the failure streak of a symbol that hasn't been flagged yet
+DELETE FROM problem_symbols WHERE symbol = $1 AND NOT flagged;
+
+-- name: IsSymbolFlagged :one
+SELECT COUNT(*) > 0 AS is_flagged
+FROM problem_symbols
+WHERE symbol = $1 AND flagged;
+
+-- name: GetFlaggedSymbols :many
+SELECT symbol, consecutive_failures, last_error, flagged, flagged_at, updated_at
+FROM problem_symbols
+WHERE flagged
+ORDER BY flagged_at DESC, symbol;
+
+-- name: ClearProblemSymbol :execrows
+DELETE FROM problem_symbols WHERE symbol = $1;
+
+-- name: SaveBacktest :exec
+-- Store a finished backtest, replacing an earlier run saved under the same id
+INSERT INTO backtests (id, symbol, start_date, end_date, initial_capital, final_balance, metrics)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (id) DO UPDATE SET
+    symbol = EXCLUDED.symbol,
+    start_date = EXCLUDED.start_date,
+    end_date = EXCLUDED.end_date,
+    initial_capital = EXCLUDED.initial_capital,
+    final_balance = EXCLUDED.final_balance,
+    metrics = EXCLUDED.metrics;
+
+-- name: GetBacktestByID :one
+SELECT id, symbol, start_date, end_date, initial_capital, final_balance, metrics, created_at
+FROM backtests
+WHERE id = $1;
+
+-- name: ListBacktests :many
+-- Most recent backtests first, without their metrics payload
+SELECT id, symbol, start_date, end_date, initial_capital, final_balance, created_at
+FROM backtests
+ORDER BY created_at DESC, id
+LIMIT $1;
+
+-- name: GetSymbolSector :one
+SELECT sector FROM sectors WHERE symbol = $1;
+
+-- name: UpsertSymbolSector :exec
+-- Cache the sector resolved for a symbol, replacing an earlier lookup
+INSERT INTO sectors (symbol, sector, source, updated_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (symbol) DO UPDATE SET
+    sector = EXCLUDED.sector,
+    source = EXCLUDED.source,
+    updated_at = NOW();
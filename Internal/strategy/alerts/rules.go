@@ -0,0 +1,198 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// fields a rule condition can reference
+const (
+	FieldRSI         = "rsi"
+	FieldATR         = "atr"
+	FieldPrice       = "price"
+	FieldVolumeRatio = "volume_ratio"
+	FieldScore       = "score"
+)
+
+var SupportedFields = []string{FieldRSI, FieldATR, FieldPrice, FieldVolumeRatio, FieldScore}
+
+var supportedOperators = map[string]bool{"<": true, "<=": true, ">": true, ">=": true, "==": true}
+
+// a single comparison such as rsi < 30
+type Condition struct {
+	Field    string  `json:"field"`
+	Operator string  `json:"operator"`
+	Value    float64 `json:"value"`
+}
+
+// a named set of conditions that must all hold for the alert to fire
+type Rule struct {
+	ID         int32       `json:"id"`
+	Name       string      `json:"name"`
+	Symbol     string      `json:"symbol,omitempty"` // empty applies to every watchlist symbol
+	Conditions []Condition `json:"conditions"`
+	Enabled    bool        `json:"enabled"`
+}
+
+// a rule that matched a symbol along with the values it was evaluated against
+type Trigger struct {
+	Rule   Rule
+	Symbol string
+	Values map[string]float64
+}
+
+func isSupportedField(field string) bool {
+	for _, f := range SupportedFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Condition) Validate() error {
+	if !isSupportedField(c.Field) {
+		return fmt.Errorf("unsupported field %q (supported: %s)", c.Field, strings.Join(SupportedFields, ", "))
+	}
+	if !supportedOperators[c.Operator] {
+		return fmt.Errorf("unsupported operator %q for field %s", c.Operator, c.Field)
+	}
+	return nil
+}
+
+func (c Condition) Matches(actual float64) bool {
+	switch c.Operator {
+	case "<":
+		return actual < c.Value
+	case "<=":
+		return actual <= c.Value
+	case ">":
+		return actual > c.Value
+	case ">=":
+		return actual >= c.Value
+	case "==":
+		return actual == c.Value
+	}
+	return false
+}
+
+func (c Condition) String() string {
+	return fmt.Sprintf("%s %s %.2f", c.Field, c.Operator, c.Value)
+}
+
+func (r Rule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("rule name is required")
+	}
+	if len(r.Conditions) == 0 {
+		return fmt.Errorf("rule %s has no conditions", r.Name)
+	}
+	for _, c := range r.Conditions {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// true when every condition holds; a condition on a missing value never matches
+func (r Rule) Evaluate(values map[string]float64) bool {
+	if len(r.Conditions) == 0 {
+		return false
+	}
+	for _, c := range r.Conditions {
+		actual, ok := values[c.Field]
+		if !ok || !c.Matches(actual) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Rule) Describe() string {
+	parts := make([]string, len(r.Conditions))
+	for i, c := range r.Conditions {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// decodes the conditions column stored as a JSON array
+func ParseConditions(raw string) ([]Condition, error) {
+	var conditions []Condition
+	if err := json.Unmarshal([]byte(raw), &conditions); err != nil {
+		return nil, fmt.Errorf("invalid conditions JSON: %w", err)
+	}
+	return conditions, nil
+}
+
+// converts a stored alert_rules row into an evaluable rule
+func RuleFromRecord(record database.AlertRule) (Rule, error) {
+	conditions, err := ParseConditions(record.Conditions)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %d: %w", record.ID, err)
+	}
+	return Rule{
+		ID:         record.ID,
+		Name:       record.Name,
+		Symbol:     record.Symbol.String,
+		Conditions: conditions,
+		Enabled:    record.Enabled,
+	}, nil
+}
+
+func EncodeConditions(conditions []Condition) (string, error) {
+	data, err := json.Marshal(conditions)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// computes rsi, atr, price and volume_ratio from bars ordered oldest first
+func ComputeIndicatorValues(bars []types.Bar) (map[string]float64, error) {
+	if len(bars) == 0 {
+		return nil, fmt.Errorf("no bars to evaluate")
+	}
+
+	latest := bars[len(bars)-1]
+	values := map[string]float64{
+		FieldPrice: latest.Close,
+	}
+
+	closes := make([]float64, len(bars))
+	atrBars := make([]indicators.ATRBar, len(bars))
+	for i, bar := range bars {
+		closes[i] = bar.Close
+		atrBars[i] = indicators.ATRBar{High: bar.High, Low: bar.Low, Close: bar.Close}
+	}
+
+	if rsiValues, err := indicators.CalculateRSI(closes, 14); err == nil {
+		values[FieldRSI] = rsiValues[len(rsiValues)-1]
+	}
+	if atrValues, err := indicators.CalculateATR(atrBars, 14); err == nil {
+		values[FieldATR] = atrValues[len(atrValues)-1]
+	}
+
+	// latest volume against the average of the 20 bars before it
+	lookback := 20
+	if len(bars)-1 < lookback {
+		lookback = len(bars) - 1
+	}
+	if lookback > 0 {
+		total := 0.0
+		for _, bar := range bars[len(bars)-1-lookback : len(bars)-1] {
+			total += float64(bar.Volume)
+		}
+		if avg := total / float64(lookback); avg > 0 {
+			values[FieldVolumeRatio] = float64(latest.Volume) / avg
+		}
+	}
+
+	return values, nil
+}
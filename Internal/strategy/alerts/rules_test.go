@@ -0,0 +1,124 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// steadily falling closes with a volume spike on the final bar
+func buildOversoldSpikeBars() []types.Bar {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bars := make([]types.Bar, 30)
+	price := 100.0
+	for i := range bars {
+		volume := int64(1000)
+		if i == len(bars)-1 {
+			volume = 3000
+		}
+		bars[i] = types.Bar{
+			Timestamp: base.AddDate(0, 0, i).Format(time.RFC3339),
+			Open:      price + 0.5,
+			High:      price + 1,
+			Low:       price - 1,
+			Close:     price,
+			Volume:    volume,
+		}
+		price -= 1
+	}
+	return bars
+}
+
+func TestComputeIndicatorValues(t *testing.T) {
+	values, err := ComputeIndicatorValues(buildOversoldSpikeBars())
+	if err != nil {
+		t.Fatalf("ComputeIndicatorValues() error = %v", err)
+	}
+
+	if values[FieldPrice] != 71 {
+		t.Errorf("price = %.2f, want 71", values[FieldPrice])
+	}
+	if values[FieldRSI] != 0 {
+		t.Errorf("rsi = %.2f, want 0 for a straight decline", values[FieldRSI])
+	}
+	if values[FieldATR] != 2 {
+		t.Errorf("atr = %.2f, want 2", values[FieldATR])
+	}
+	if values[FieldVolumeRatio] != 3 {
+		t.Errorf("volume_ratio = %.2f, want 3", values[FieldVolumeRatio])
+	}
+}
+
+func TestRuleEvaluate_AgainstComputedIndicators(t *testing.T) {
+	values, err := ComputeIndicatorValues(buildOversoldSpikeBars())
+	if err != nil {
+		t.Fatalf("ComputeIndicatorValues() error = %v", err)
+	}
+
+	rule := Rule{
+		Name: "oversold on volume",
+		Conditions: []Condition{
+			{Field: FieldRSI, Operator: "<", Value: 30},
+			{Field: FieldVolumeRatio, Operator: ">", Value: 2},
+		},
+	}
+	if !rule.Evaluate(values) {
+		t.Errorf("Expected %q to fire for values %v", rule.Describe(), values)
+	}
+
+	rule.Conditions[1].Value = 5
+	if rule.Evaluate(values) {
+		t.Errorf("Expected %q not to fire when volume ratio is below threshold", rule.Describe())
+	}
+}
+
+func TestRuleEvaluate_MissingValueDoesNotMatch(t *testing.T) {
+	rule := Rule{
+		Name:       "high score",
+		Conditions: []Condition{{Field: FieldScore, Operator: ">=", Value: 5}},
+	}
+	if rule.Evaluate(map[string]float64{FieldRSI: 20}) {
+		t.Errorf("Rule on score should not fire when score is unavailable")
+	}
+	if !rule.Evaluate(map[string]float64{FieldScore: 5}) {
+		t.Errorf("Rule should fire when score equals the threshold")
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{"valid", Rule{Name: "r", Conditions: []Condition{{Field: FieldATR, Operator: ">", Value: 1}}}, false},
+		{"missing name", Rule{Conditions: []Condition{{Field: FieldATR, Operator: ">", Value: 1}}}, true},
+		{"no conditions", Rule{Name: "r"}, true},
+		{"unknown field", Rule{Name: "r", Conditions: []Condition{{Field: "macd", Operator: ">", Value: 1}}}, true},
+		{"unknown operator", Rule{Name: "r", Conditions: []Condition{{Field: FieldRSI, Operator: "!=", Value: 1}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConditionsRoundTrip(t *testing.T) {
+	conditions := []Condition{{Field: FieldPrice, Operator: "<=", Value: 12.5}}
+	raw, err := EncodeConditions(conditions)
+	if err != nil {
+		t.Fatalf("EncodeConditions() error = %v", err)
+	}
+	parsed, err := ParseConditions(raw)
+	if err != nil {
+		t.Fatalf("ParseConditions() error = %v", err)
+	}
+	if len(parsed) != 1 || parsed[0] != conditions[0] {
+		t.Errorf("Round trip = %v, want %v", parsed, conditions)
+	}
+}
@@ -0,0 +1,173 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	datafeed "github.com/fazecat/mogulmaker/Internal/database"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+// fallbacks when position_sizing leaves the beta fields unset
+const (
+	defaultBetaBenchmark = "SPY"
+	defaultBetaLookback  = 60 // daily returns
+	defaultBetaCacheTTL  = 24 * time.Hour
+	defaultMinBeta       = 0.5
+)
+
+// fewest overlapping daily returns a beta is computed from
+const minBetaReturns = 20
+
+// daily bars for a symbol, at least limit of them when that much history exists
+type BetaBarFetcher func(symbol string, limit int) ([]types.Bar, error)
+
+// daily bars from Alpaca with enough calendar days behind them to cover limit sessions
+func fetchBetaBars(symbol string, limit int) ([]types.Bar, error) {
+	start := time.Now().UTC().AddDate(0, 0, -(limit*7/5 + 7)).Format(time.RFC3339)
+	return datafeed.GetAlpacaBarsWithType(symbol, "1Day", limit+10, start, utils.DetectAssetType(symbol, ""))
+}
+
+// close of each bar keyed by its UTC date
+func closesByDay(bars []types.Bar) map[string]float64 {
+	closes := make(map[string]float64, len(bars))
+	for _, bar := range bars {
+		t, err := time.Parse(time.RFC3339, bar.Timestamp)
+		if err != nil || bar.Close <= 0 {
+			continue
+		}
+		closes[t.UTC().Format("2006-01-02")] = bar.Close
+	}
+	return closes
+}
+
+// cov(symbol, benchmark) / var(benchmark) over close-to-close returns on the days both have a bar, using the
+// most recent lookback of them (0 uses all)
+func CalculateBeta(symbolBars, benchmarkBars []types.Bar, lookback int) (float64, error) {
+	symbolCloses, benchCloses := closesByDay(symbolBars), closesByDay(benchmarkBars)
+	var days []string
+	for day := range symbolCloses {
+		if _, ok := benchCloses[day]; ok {
+			days = append(days, day)
+		}
+	}
+	sort.Strings(days)
+	if lookback > 0 && len(days) > lookback+1 {
+		days = days[len(days)-lookback-1:]
+	}
+	if len(days)-1 < minBetaReturns {
+		return 0, fmt.Errorf("need %d overlapping daily returns for beta, got %d", minBetaReturns, max(len(days)-1, 0))
+	}
+
+	symbolReturns := make([]float64, len(days)-1)
+	benchReturns := make([]float64, len(days)-1)
+	for i := 1; i < len(days); i++ {
+		symbolReturns[i-1] = symbolCloses[days[i]]/symbolCloses[days[i-1]] - 1
+		benchReturns[i-1] = benchCloses[days[i]]/benchCloses[days[i-1]] - 1
+	}
+
+	symbolMean, benchMean := utils.Average(symbolReturns), utils.Average(benchReturns)
+	var covariance, variance float64
+	for i := range benchReturns {
+		covariance += (symbolReturns[i] - symbolMean) * (benchReturns[i] - benchMean)
+		variance += (benchReturns[i] - benchMean) * (benchReturns[i] - benchMean)
+	}
+	if variance == 0 {
+		return 0, fmt.Errorf("benchmark returns have no variance")
+	}
+	return covariance / variance, nil
+}
+
+// the risk budget divided by the symbol's beta so every position carries about the same market risk; the beta
+// is floored at minBeta (default 0.5) so a near-zero or negative beta can't blow the budget up
+func BetaAdjustedRiskPercent(riskPercent, beta, minBeta float64) float64 {
+	if minBeta <= 0 {
+		minBeta = defaultMinBeta
+	}
+	return riskPercent / math.Max(beta, minBeta)
+}
+
+type cachedBeta struct {
+	beta     float64
+	computed time.Time
+}
+
+// betas against one benchmark, each kept for the TTL since they move slowly; the benchmark's bars are cached
+// alongside them
+type BetaCache struct {
+	mu        sync.Mutex
+	fetch     BetaBarFetcher
+	now       func() time.Time
+	benchmark string
+	lookback  int
+	ttl       time.Duration
+
+	betas        map[string]cachedBeta
+	benchBars    []types.Bar
+	benchFetched time.Time
+}
+
+func NewBetaCache(fetch BetaBarFetcher, benchmark string, lookback int, ttl time.Duration) *BetaCache {
+	if fetch == nil {
+		fetch = fetchBetaBars
+	}
+	if benchmark == "" {
+		benchmark = defaultBetaBenchmark
+	}
+	if lookback <= 0 {
+		lookback = defaultBetaLookback
+	}
+	if ttl <= 0 {
+		ttl = defaultBetaCacheTTL
+	}
+	return &BetaCache{fetch: fetch, now: time.Now, benchmark: benchmark, lookback: lookback, ttl: ttl, betas: map[string]cachedBeta{}}
+}
+
+// the symbol's cached beta while fresh, otherwise a new one from the latest bars; the benchmark itself is 1
+func (c *BetaCache) Beta(symbol string) (float64, error) {
+	if symbol == c.benchmark {
+		return 1, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if cached, ok := c.betas[symbol]; ok && now.Sub(cached.computed) < c.ttl {
+		return cached.beta, nil
+	}
+
+	if c.benchBars == nil || now.Sub(c.benchFetched) >= c.ttl {
+		bars, err := c.fetch(c.benchmark, c.lookback+1)
+		if err != nil {
+			return 0, fmt.Errorf("fetching %s bars for beta: %w", c.benchmark, err)
+		}
+		c.benchBars, c.benchFetched = bars, now
+	}
+	bars, err := c.fetch(symbol, c.lookback+1)
+	if err != nil {
+		return 0, fmt.Errorf("fetching %s bars for beta: %w", symbol, err)
+	}
+	beta, err := CalculateBeta(bars, c.benchBars, c.lookback)
+	if err != nil {
+		return 0, fmt.Errorf("%s beta vs %s: %w", symbol, c.benchmark, err)
+	}
+	c.betas[symbol] = cachedBeta{beta: beta, computed: now}
+	return beta, nil
+}
+
+// the risk budget for symbol after beta adjustment, and the beta used; with beta sizing off the budget comes
+// back as is with a beta of 0
+func (cfg *OrderConfig) BetaAdjustedRisk(symbol string, riskPercent float64) (float64, float64, error) {
+	if cfg == nil || !cfg.BetaAdjust || cfg.Betas == nil {
+		return riskPercent, 0, nil
+	}
+	beta, err := cfg.Betas.Beta(symbol)
+	if err != nil {
+		return riskPercent, 0, err
+	}
+	return BetaAdjustedRiskPercent(riskPercent, beta, cfg.MinBeta), beta, nil
+}
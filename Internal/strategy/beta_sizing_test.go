@@ -0,0 +1,104 @@
+package strategy
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// daily bars whose returns are beta times the benchmark's zig-zag returns
+func betaBars(beta float64, days int) []types.Bar {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bars := make([]types.Bar, days)
+	price := 100.0
+	for i := range bars {
+		if i > 0 {
+			move := 0.01
+			if i%3 == 0 {
+				move = -0.015
+			}
+			price *= 1 + beta*move
+		}
+		bars[i] = types.Bar{Timestamp: base.AddDate(0, 0, i).Format(time.RFC3339), Close: price}
+	}
+	return bars
+}
+
+func TestCalculateBeta(t *testing.T) {
+	bench := betaBars(1, 80)
+	for _, want := range []float64{1.5, 0.7} {
+		beta, err := CalculateBeta(betaBars(want, 80), bench, 60)
+		if err != nil {
+			t.Fatalf("CalculateBeta() error = %v", err)
+		}
+		if math.Abs(beta-want) > 1e-9 {
+			t.Errorf("beta = %v, want %v", beta, want)
+		}
+	}
+	if _, err := CalculateBeta(betaBars(1.5, 10), bench, 60); err == nil {
+		t.Error("Expected an error with too little overlapping history")
+	}
+}
+
+func TestBetaAdjustedSizing_HighBetaGetsSmaller(t *testing.T) {
+	fetches := 0
+	cache := NewBetaCache(func(symbol string, limit int) ([]types.Bar, error) {
+		fetches++
+		switch symbol {
+		case "HIGH":
+			return betaBars(1.5, 80), nil
+		case "LOW":
+			return betaBars(0.7, 80), nil
+		}
+		return betaBars(1, 80), nil
+	}, "SPY", 60, time.Hour)
+	clock := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return clock }
+	cfg := &OrderConfig{MaxPortfolioPercent: 2, BetaAdjust: true, MinBeta: 0.5, Betas: cache}
+
+	// same account, entry and stop, so any difference comes from beta alone
+	size := func(symbol string) (int64, float64) {
+		riskPercent, beta, err := cfg.BetaAdjustedRisk(symbol, 1)
+		if err != nil {
+			t.Fatalf("BetaAdjustedRisk(%s) error = %v", symbol, err)
+		}
+		return CalculatePositionSize(100000, 50, 49, riskPercent, cfg), beta
+	}
+	highQty, highBeta := size("HIGH")
+	lowQty, lowBeta := size("LOW")
+
+	if highQty != 666 || lowQty != 1428 {
+		t.Errorf("quantities = %d (beta %.2f) and %d (beta %.2f), want 666 and 1428", highQty, highBeta, lowQty, lowBeta)
+	}
+	// beta-weighted dollars at risk match
+	if highRisk, lowRisk := float64(highQty)*highBeta, float64(lowQty)*lowBeta; math.Abs(highRisk-lowRisk) > 2 {
+		t.Errorf("beta-weighted risk %.1f vs %.1f, want about equal", highRisk, lowRisk)
+	}
+
+	// SPY once plus one fetch per symbol; repeats come from the cache until the TTL runs out
+	size("HIGH")
+	if fetches != 3 {
+		t.Errorf("fetches = %d, want 3 with cached betas", fetches)
+	}
+	clock = clock.Add(2 * time.Hour)
+	size("HIGH")
+	if fetches != 5 {
+		t.Errorf("fetches = %d after the TTL, want the benchmark and HIGH refetched", fetches)
+	}
+
+	cfg.BetaAdjust = false
+	if qty, beta := size("HIGH"); qty != 1000 || beta != 0 {
+		t.Errorf("unadjusted quantity = %d (beta %v), want 1000", qty, beta)
+	}
+}
+
+func TestBetaAdjustedRiskPercent_FloorsBeta(t *testing.T) {
+	if got := BetaAdjustedRiskPercent(1, 0.1, 0.5); got != 2 {
+		t.Errorf("risk = %v, want 2 with beta floored at 0.5", got)
+	}
+	if got := BetaAdjustedRiskPercent(1, -0.8, 0); got != 2 {
+		t.Errorf("risk = %v, want 2 for a negative beta at the default floor", got)
+	}
+}
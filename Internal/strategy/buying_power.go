@@ -0,0 +1,57 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+var ErrInsufficientBuyingPower = errors.New("insufficient buying power")
+
+// the buying power an order for the asset class draws on; crypto can't be bought on margin
+func AccountBuyingPower(account *alpaca.Account, symbol, assetType string) float64 {
+	if account == nil {
+		return 0
+	}
+	if utils.DetectAssetType(symbol, assetType) == utils.AssetTypeCrypto {
+		return account.NonMarginBuyingPower.InexactFloat64()
+	}
+	return account.BuyingPower.InexactFloat64()
+}
+
+// checks quantity x price against buyingPower less the configured reserve. An order that fits comes back as is;
+// an oversized one is cut to what fits when resize_to_fit is on (whole units unless the quantity was fractional),
+// otherwise, or when nothing fits, the error wraps ErrInsufficientBuyingPower
+func CheckBuyingPower(quantity, price, buyingPower float64, cfg config.BuyingPowerConfig) (float64, error) {
+	if !cfg.Enabled || quantity <= 0 || price <= 0 {
+		return quantity, nil
+	}
+
+	available := buyingPower * (1 - math.Max(cfg.ReservePercent, 0)/100)
+	required := quantity * price
+	if required <= available {
+		return quantity, nil
+	}
+
+	shortfall := fmt.Errorf("%w: order needs $%.2f, $%.2f available (buying power $%.2f less %.1f%% reserve)",
+		ErrInsufficientBuyingPower, required, math.Max(available, 0), buyingPower, cfg.ReservePercent)
+	if !cfg.ResizeToFit || available <= 0 {
+		return 0, shortfall
+	}
+
+	fits := available / price
+	if quantity == math.Trunc(quantity) {
+		fits = math.Floor(fits)
+	} else {
+		// Alpaca takes fractional quantities to 9 places; 6 keeps the float noise out
+		fits = math.Floor(fits*1e6) / 1e6
+	}
+	if fits <= 0 {
+		return 0, shortfall
+	}
+	return fits, nil
+}
@@ -4,9 +4,20 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
 	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
 )
 
+// breakout volume confirmation for new detectors; set from patterns.breakout_volume_multiplier / breakout_volume_period
+var (
+	DefaultBreakoutVolumeMultiplier = 1.3
+	DefaultBreakoutVolumePeriod     = 20
+)
+
+// confidence taken off a breakout whose OBV ran the other way through the consolidation
+const obvDivergencePenalty = 25.0
+
 type PatternType string
 
 const (
@@ -41,13 +52,28 @@ type PatternDetector struct {
 	MinFormationBars int
 	TolerancePercent float64
 	VerboseLogging   bool
+
+	BreakoutVolumeMultiplier float64 // breakout bar volume must exceed this multiple of the average volume
+	BreakoutVolumePeriod     int     // bars before the breakout bar averaged for that comparison
+}
+
+// updates the breakout volume defaults used by new detectors; non-positive values keep the current ones
+func SetBreakoutVolume(multiplier float64, period int) {
+	if multiplier > 0 {
+		DefaultBreakoutVolumeMultiplier = multiplier
+	}
+	if period > 0 {
+		DefaultBreakoutVolumePeriod = period
+	}
 }
 
 func NewPatternDetector() *PatternDetector {
 	return &PatternDetector{
-		MinFormationBars: 3,
-		TolerancePercent: 1.5,
-		VerboseLogging:   false,
+		MinFormationBars:         3,
+		TolerancePercent:         1.5,
+		VerboseLogging:           false,
+		BreakoutVolumeMultiplier: DefaultBreakoutVolumeMultiplier,
+		BreakoutVolumePeriod:     DefaultBreakoutVolumePeriod,
 	}
 }
 
@@ -350,9 +376,9 @@ func (pd *PatternDetector) DetectConsolidationBreakout(bars []types.Bar) Pattern
 		return signal
 	}
 
-	// Use helper to find consolidation zone
+	// Use helper to find consolidation zone; the breakout bar itself is left out, or it could never close outside it
 	consolidationBars := 6
-	maxPrice, minPrice, rangePercent := pd.calculateConsolidationZone(bars, consolidationBars)
+	maxPrice, minPrice, rangePercent := pd.calculateConsolidationZone(bars[:len(bars)-1], consolidationBars)
 
 	// Consolidation should be tight
 	if rangePercent > 1.5 {
@@ -362,9 +388,12 @@ func (pd *PatternDetector) DetectConsolidationBreakout(bars []types.Bar) Pattern
 	// Check if current bar breaks out
 	currentBar := bars[len(bars)-1]
 	prevBar := bars[len(bars)-2]
+	volumeConfirmed := pd.breakoutVolumeConfirmed(bars)
+	// OBV through the consolidation, before the breakout bar's own volume swamps it
+	obvTrend := indicators.OBVTrend(indicators.CalculateOBV(bars[:len(bars)-1]), consolidationBars)
 
 	// Breakout up
-	if currentBar.Close > maxPrice && prevBar.Close < maxPrice && currentBar.Volume > int64(float64(prevBar.Volume)*1.3) {
+	if currentBar.Close > maxPrice && prevBar.Close < maxPrice && volumeConfirmed {
 		signal.Detected = true
 		signal.Pattern = PatternConsolidationBreak
 		signal.Direction = "LONG"
@@ -374,6 +403,10 @@ func (pd *PatternDetector) DetectConsolidationBreakout(bars []types.Bar) Pattern
 		signal.Reasoning = "Upside breakout from consolidation"
 		signal.PriceTargetUp = maxPrice + (maxPrice - minPrice)
 		signal.StopLossLevel = minPrice * 0.98
+		if obvTrend == "falling" {
+			signal.Confidence -= obvDivergencePenalty
+			signal.Reasoning += " (OBV falling - volume not confirming)"
+		}
 
 		if pd.VerboseLogging {
 			fmt.Printf("Consolidation breakout (UP) detected\n")
@@ -383,7 +416,7 @@ func (pd *PatternDetector) DetectConsolidationBreakout(bars []types.Bar) Pattern
 	}
 
 	// Breakout down
-	if currentBar.Close < minPrice && prevBar.Close > minPrice && currentBar.Volume > int64(float64(prevBar.Volume)*1.3) {
+	if currentBar.Close < minPrice && prevBar.Close > minPrice && volumeConfirmed {
 		signal.Detected = true
 		signal.Pattern = PatternConsolidationBreak
 		signal.Direction = "SHORT"
@@ -393,6 +426,10 @@ func (pd *PatternDetector) DetectConsolidationBreakout(bars []types.Bar) Pattern
 		signal.Reasoning = "Downside breakout from consolidation"
 		signal.PriceTargetDown = minPrice - (maxPrice - minPrice)
 		signal.StopLossLevel = maxPrice * 1.02
+		if obvTrend == "rising" {
+			signal.Confidence -= obvDivergencePenalty
+			signal.Reasoning += " (OBV rising - volume not confirming)"
+		}
 
 		if pd.VerboseLogging {
 			fmt.Printf("Consolidation breakout (DOWN) detected\n")
@@ -404,6 +441,26 @@ func (pd *PatternDetector) DetectConsolidationBreakout(bars []types.Bar) Pattern
 	return signal
 }
 
+// the latest bar's volume against the average of the bars before it, so one quiet prior bar can't confirm noise
+func (pd *PatternDetector) breakoutVolumeConfirmed(bars []types.Bar) bool {
+	multiplier := pd.BreakoutVolumeMultiplier
+	if multiplier <= 0 {
+		multiplier = DefaultBreakoutVolumeMultiplier
+	}
+	period := pd.BreakoutVolumePeriod
+	if period <= 0 {
+		period = DefaultBreakoutVolumePeriod
+	}
+
+	prior := bars[:len(bars)-1]
+	volumes := make([]int64, len(prior))
+	for i, bar := range prior {
+		volumes[i] = bar.Volume
+	}
+	avgVolume := utils.CalculateAvgVolume(volumes, period)
+	return float64(bars[len(bars)-1].Volume) > avgVolume*multiplier
+}
+
 func (pd *PatternDetector) DetectTriangle(bars []types.Bar) PatternSignal {
 	signal := PatternSignal{
 		Pattern:   PatternTriangle,
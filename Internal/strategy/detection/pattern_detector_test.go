@@ -152,3 +152,75 @@ func TestPatternDetector_RiskRewardCalculation(t *testing.T) {
 		t.Errorf("Pattern should be initialized")
 	}
 }
+
+// ten quiet bars, a tight six-bar range just under 100.4, then a breakout bar closing above it
+func breakoutBars(priorVolumes []int64, breakoutVolume int64) []types.Bar {
+	var bars []types.Bar
+	for _, v := range priorVolumes {
+		bars = append(bars, types.Bar{High: 100.3, Low: 99.2, Close: 99.6, Volume: v})
+	}
+	return append(bars, types.Bar{High: 102, Low: 100, Close: 101.5, Volume: breakoutVolume})
+}
+
+func TestDetectConsolidationBreakout_ConfirmedByAverageVolumeSpike(t *testing.T) {
+	detector := NewPatternDetector()
+	if detector.BreakoutVolumeMultiplier != 1.3 {
+		t.Fatalf("default multiplier = %.2f, want 1.3", detector.BreakoutVolumeMultiplier)
+	}
+
+	// the bar right before the breakout is quiet, but the average is 1000: 1200 beat the old prior-bar test only
+	volumes := []int64{1000, 1100, 900, 1000, 1000, 1200, 800, 1000, 1100, 500}
+	weak := detector.DetectConsolidationBreakout(breakoutBars(volumes, 1200))
+	if weak.Detected {
+		t.Errorf("breakout on 1200 vs a ~960 average was confirmed at 1.3x")
+	}
+
+	strong := detector.DetectConsolidationBreakout(breakoutBars(volumes, 1500))
+	if !strong.Detected || strong.Direction != "LONG" {
+		t.Errorf("breakout on 1500 = %+v, want a confirmed LONG breakout", strong)
+	}
+}
+
+func TestDetectConsolidationBreakout_ConfigurableMultiplier(t *testing.T) {
+	volumes := []int64{1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000}
+
+	detector := NewPatternDetector()
+	detector.BreakoutVolumeMultiplier = 2.0
+	if detector.DetectConsolidationBreakout(breakoutBars(volumes, 1500)).Detected {
+		t.Errorf("1.5x volume confirmed a breakout with a 2x requirement")
+	}
+
+	detector.BreakoutVolumeMultiplier = 1.1
+	if !detector.DetectConsolidationBreakout(breakoutBars(volumes, 1500)).Detected {
+		t.Errorf("1.5x volume rejected with a 1.1x requirement")
+	}
+}
+
+func TestDetectConsolidationBreakout_OBVDivergenceCutsConfidence(t *testing.T) {
+	// closes chop inside the range; heavy volume on the down closes is distribution, on the up closes accumulation
+	rangeBars := func(upVolume, downVolume int64) []types.Bar {
+		var bars []types.Bar
+		for i := 0; i < 10; i++ {
+			bar := types.Bar{High: 100.3, Low: 99.2, Close: 99.8, Volume: upVolume}
+			if i%2 == 1 {
+				bar.Close, bar.Volume = 99.4, downVolume
+			}
+			bars = append(bars, bar)
+		}
+		return append(bars, types.Bar{High: 102, Low: 100, Close: 101.5, Volume: 1500})
+	}
+
+	detector := NewPatternDetector()
+	distribution := detector.DetectConsolidationBreakout(rangeBars(700, 1300))
+	if !distribution.Detected || distribution.Direction != "LONG" {
+		t.Fatalf("breakout after distribution = %+v, want a LONG breakout", distribution)
+	}
+	if distribution.Confidence >= 80 {
+		t.Errorf("breakout on falling OBV confidence = %.0f, want it below 80", distribution.Confidence)
+	}
+
+	accumulation := detector.DetectConsolidationBreakout(rangeBars(1300, 700))
+	if !accumulation.Detected || accumulation.Confidence != 80 {
+		t.Errorf("breakout on rising OBV = %+v, want full 80 confidence", accumulation)
+	}
+}
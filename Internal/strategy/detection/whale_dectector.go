@@ -5,6 +5,30 @@ import (
 	"github.com/fazecat/mogulmaker/Internal/utils"
 )
 
+// modified z-score cutoff from Iglewicz & Hoaglin, the robust counterpart of the 2.0 mean cutoff
+const (
+	whaleZScoreThreshold       = 2.0
+	whaleRobustZScoreThreshold = 3.5
+)
+
+// whale volume baseline; set from whales.baseline_window / include_current_bar / robust_zscore
+var DefaultWhaleBaseline = WhaleBaseline{Window: 20}
+
+type WhaleBaseline struct {
+	Window            int  // bars in the rolling volume baseline
+	IncludeCurrentBar bool // the bar being tested is part of its own baseline
+	Robust            bool // median/MAD modified z-score instead of mean/stddev
+}
+
+// updates the baseline DetectWhales uses; a non-positive window keeps the current one
+func SetWhaleBaseline(window int, includeCurrentBar, robust bool) {
+	if window > 0 {
+		DefaultWhaleBaseline.Window = window
+	}
+	DefaultWhaleBaseline.IncludeCurrentBar = includeCurrentBar
+	DefaultWhaleBaseline.Robust = robust
+}
+
 type VolumeStats struct {
 	Timestamp   string
 	TotalVolume int64
@@ -47,23 +71,65 @@ func CalculateZScore(currentVolume int64, meanVolume float64, stdDev float64) fl
 	return zScore
 }
 
+// modified z-score: 0.6745 * (x - median) / MAD, which one outlier in the baseline can't drag around.
+// A zero MAD (mostly identical volumes) falls back to the mean absolute deviation around the median
+func CalculateRobustZScore(currentVolume int64, volumes []int64) float64 {
+	floatVolumes := make([]float64, len(volumes))
+	for i, v := range volumes {
+		floatVolumes[i] = float64(v)
+	}
+	median := utils.Median(floatVolumes)
+
+	deviations := make([]float64, len(floatVolumes))
+	for i, v := range floatVolumes {
+		deviations[i] = utils.Abs(v - median)
+	}
+	deviation := float64(currentVolume) - median
+	if mad := utils.Median(deviations); mad > 0 {
+		return 0.6745 * deviation / mad
+	}
+	if meanAD := utils.Average(deviations); meanAD > 0 {
+		return deviation / (1.2533 * meanAD)
+	}
+	return 0
+}
+
 func DetectWhales(symbol string, bars []types.Bar) []WhaleEvent {
+	return DetectWhalesWithBaseline(symbol, bars, DefaultWhaleBaseline)
+}
+
+func DetectWhalesWithBaseline(symbol string, bars []types.Bar, baseline WhaleBaseline) []WhaleEvent {
 	whales := make([]WhaleEvent, 0)
 
-	if len(bars) < 20 {
+	window := baseline.Window
+	if window <= 0 {
+		window = 20
+	}
+	threshold := whaleZScoreThreshold
+	if baseline.Robust {
+		threshold = whaleRobustZScoreThreshold
+	}
+
+	if len(bars) < window {
 		return whales
 	}
-	for i := 20; i < len(bars); i++ {
+	for i := window; i < len(bars); i++ {
 		currentBar := bars[i]
 
-		historicalBars := bars[i-20 : i]
+		historicalBars := bars[i-window : i]
+		if baseline.IncludeCurrentBar {
+			historicalBars = bars[i-window+1 : i+1]
+		}
 		volumes := extractVolumes(historicalBars)
 
 		meanVolume, stdDev := CalculateVolumeStats(volumes)
 
 		zScore := CalculateZScore(currentBar.Volume, meanVolume, stdDev)
+		if baseline.Robust {
+			zScore = CalculateRobustZScore(currentBar.Volume, volumes)
+		}
 
-		if zScore > 2.0 {
+		if zScore > threshold {
 			whale := createWhaleEvent(symbol, currentBar, zScore, meanVolume)
 			whales = append(whales, whale)
 		}
@@ -0,0 +1,84 @@
+package detection
+
+import (
+	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// ~1M shares a day with +/-100k of noise, modest 1.2M bumps at 25 and 45 and one 20M spike at 35
+func spikeVolumeBars() []types.Bar {
+	bars := make([]types.Bar, 60)
+	for i := range bars {
+		volume := int64(1_000_000 + ((i*7919)%11-5)*20_000)
+		switch i {
+		case 25, 45:
+			volume = 1_200_000
+		case 35:
+			volume = 20_000_000
+		}
+		bars[i] = types.Bar{Timestamp: string(rune('A' + i%26)), Open: 100, Close: 101, Volume: volume}
+	}
+	return bars
+}
+
+func flaggedVolumes(whales []WhaleEvent) map[int64]bool {
+	flagged := make(map[int64]bool)
+	for _, w := range whales {
+		flagged[w.Volume] = true
+	}
+	return flagged
+}
+
+func TestDetectWhalesWithBaseline_RobustFlagsFewerFalsePositives(t *testing.T) {
+	bars := spikeVolumeBars()
+
+	mean := DetectWhalesWithBaseline("TEST", bars, WhaleBaseline{Window: 20})
+	robust := DetectWhalesWithBaseline("TEST", bars, WhaleBaseline{Window: 20, Robust: true})
+
+	if !flaggedVolumes(mean)[20_000_000] || !flaggedVolumes(robust)[20_000_000] {
+		t.Fatalf("the 20M spike must be a whale for both methods: mean %+v, robust %+v", mean, robust)
+	}
+	if len(robust) >= len(mean) {
+		t.Errorf("robust flagged %d whales, mean %d; want fewer false positives from the robust score", len(robust), len(mean))
+	}
+	if len(robust) != 1 {
+		t.Errorf("robust whales = %+v, want only the spike", robust)
+	}
+}
+
+func TestDetectWhalesWithBaseline_WindowAndCurrentBar(t *testing.T) {
+	bars := spikeVolumeBars()
+
+	if got := DetectWhalesWithBaseline("TEST", bars[:30], WhaleBaseline{Window: 40}); len(got) != 0 {
+		t.Errorf("whales with fewer bars than the window = %+v, want none", got)
+	}
+
+	// with the spike inside its own baseline, a 20-bar window can lift it at most to sqrt(n-1) ~ 4.36
+	excluded := DetectWhalesWithBaseline("TEST", bars, WhaleBaseline{Window: 20})
+	included := DetectWhalesWithBaseline("TEST", bars, WhaleBaseline{Window: 20, IncludeCurrentBar: true})
+	var excludedZ, includedZ float64
+	for _, w := range excluded {
+		if w.Volume == 20_000_000 {
+			excludedZ = w.ZScore
+		}
+	}
+	for _, w := range included {
+		if w.Volume == 20_000_000 {
+			includedZ = w.ZScore
+		}
+	}
+	if includedZ <= 0 || includedZ >= excludedZ || includedZ > 4.37 {
+		t.Errorf("spike z-score including the bar = %.2f, excluding = %.2f; want a damped but flagged score", includedZ, excludedZ)
+	}
+}
+
+func TestCalculateRobustZScore_ZeroMADFallsBack(t *testing.T) {
+	volumes := []int64{100, 100, 100, 100, 100, 100, 400}
+	if z := CalculateRobustZScore(400, volumes); z <= whaleRobustZScoreThreshold {
+		t.Errorf("robust z-score with zero MAD = %.2f, want a fallback above %.1f", z, whaleRobustZScoreThreshold)
+	}
+	if z := CalculateRobustZScore(100, []int64{100, 100, 100}); z != 0 {
+		t.Errorf("robust z-score on constant volume = %.2f, want 0", z)
+	}
+}
@@ -0,0 +1,95 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/utils"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+var ErrEarningsWindow = errors.New("earnings inside the avoidance window")
+
+// source of scheduled earnings dates (*newsscraping.FinnhubClient satisfies it)
+type EarningsCalendar interface {
+	NextEarnings(symbol string, from, to time.Time) (date time.Time, found bool, err error)
+}
+
+// an upcoming earnings report for a symbol
+type EarningsFlag struct {
+	Symbol       string    `json:"symbol"`
+	EarningsDate time.Time `json:"earnings_date"`
+	DaysAway     int       `json:"days_away"` // calendar days from today, 0 when it's today
+}
+
+// calendar days from now's date to date, both read as plain dates
+func earningsDaysAway(now, date time.Time) int {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return int(day.Sub(today).Hours() / 24)
+}
+
+// the symbol's earnings within the next withinDays calendar days, if any
+func UpcomingEarnings(symbol string, now time.Time, calendar EarningsCalendar, withinDays int) (*EarningsFlag, error) {
+	date, found, err := calendar.NextEarnings(symbol, now, now.AddDate(0, 0, withinDays))
+	if err != nil || !found {
+		return nil, err
+	}
+	days := earningsDaysAway(now, date)
+	if days < 0 || days > withinDays {
+		return nil, nil
+	}
+	return &EarningsFlag{Symbol: symbol, EarningsDate: date, DaysAway: days}, nil
+}
+
+// rejects a new entry in symbol when it reports earnings within cfg.AvoidDays. An unavailable calendar never
+// blocks: the entry goes ahead with a note saying the check was skipped
+func CheckEarningsWindow(symbol string, now time.Time, calendar EarningsCalendar, cfg config.EarningsConfig) (note string, err error) {
+	// crypto has no earnings
+	if !cfg.Enabled || utils.DetectAssetType(symbol, "") == utils.AssetTypeCrypto {
+		return "", nil
+	}
+	if calendar == nil {
+		return fmt.Sprintf("earnings check skipped for %s: no earnings calendar configured", symbol), nil
+	}
+	avoidDays := cfg.AvoidDays
+	if avoidDays <= 0 {
+		avoidDays = 3
+	}
+
+	flag, err := UpcomingEarnings(symbol, now, calendar, avoidDays)
+	if err != nil {
+		return fmt.Sprintf("earnings check skipped for %s: %v", symbol, err), nil
+	}
+	if flag != nil {
+		return "", fmt.Errorf("%w: %s reports on %s, %d day(s) away (avoid_days %d)",
+			ErrEarningsWindow, symbol, flag.EarningsDate.Format("2006-01-02"), flag.DaysAway, avoidDays)
+	}
+	return "", nil
+}
+
+// open positions reporting within cfg.WarnDays, plus the symbols whose dates couldn't be looked up
+func FlagPositionsNearEarnings(symbols []string, now time.Time, calendar EarningsCalendar, cfg config.EarningsConfig) (flags []EarningsFlag, unavailable []string) {
+	warnDays := cfg.WarnDays
+	if warnDays <= 0 {
+		warnDays = 5
+	}
+	seen := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		if seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+
+		flag, err := UpcomingEarnings(symbol, now, calendar, warnDays)
+		if err != nil {
+			unavailable = append(unavailable, symbol)
+			continue
+		}
+		if flag != nil {
+			flags = append(flags, *flag)
+		}
+	}
+	return flags, unavailable
+}
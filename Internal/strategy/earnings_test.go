@@ -0,0 +1,76 @@
+package strategy
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+// earnings dates by symbol; a symbol mapped to the zero time has none scheduled
+type fakeEarningsCalendar struct {
+	dates map[string]time.Time
+	err   error
+	calls int
+}
+
+func (f *fakeEarningsCalendar) NextEarnings(symbol string, from, to time.Time) (time.Time, bool, error) {
+	f.calls++
+	if f.err != nil {
+		return time.Time{}, false, f.err
+	}
+	date, ok := f.dates[symbol]
+	if !ok || date.Before(from.Truncate(24*time.Hour)) || date.After(to) {
+		return time.Time{}, false, nil
+	}
+	return date, true, nil
+}
+
+func TestCheckEarningsWindow(t *testing.T) {
+	now := time.Date(2024, 4, 24, 15, 0, 0, 0, time.UTC)
+	calendar := &fakeEarningsCalendar{dates: map[string]time.Time{
+		"MSFT": time.Date(2024, 4, 25, 0, 0, 0, 0, time.UTC), // tomorrow
+		"AAPL": time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),  // 8 days out
+	}}
+	enabled := config.EarningsConfig{Enabled: true, AvoidDays: 3}
+
+	if _, err := CheckEarningsWindow("MSFT", now, calendar, enabled); !errors.Is(err, ErrEarningsWindow) {
+		t.Errorf("MSFT with earnings tomorrow: err = %v, want ErrEarningsWindow", err)
+	}
+	if note, err := CheckEarningsWindow("AAPL", now, calendar, enabled); err != nil || note != "" {
+		t.Errorf("AAPL 8 days out: note %q, err %v, want allowed", note, err)
+	}
+	if _, err := CheckEarningsWindow("MSFT", now, calendar, config.EarningsConfig{AvoidDays: 3}); err != nil {
+		t.Errorf("toggle off: err = %v, want allowed", err)
+	}
+
+	calls := calendar.calls
+	if _, err := CheckEarningsWindow("BTC/USD", now, calendar, enabled); err != nil || calendar.calls != calls {
+		t.Errorf("crypto: err %v, calendar called %d times, want no lookup", err, calendar.calls-calls)
+	}
+
+	down := &fakeEarningsCalendar{err: errors.New("FINNHUB_API_KEY not set in environment")}
+	note, err := CheckEarningsWindow("MSFT", now, down, enabled)
+	if err != nil || note == "" {
+		t.Errorf("calendar unavailable: note %q, err %v, want allowed with a note", note, err)
+	}
+}
+
+func TestFlagPositionsNearEarnings(t *testing.T) {
+	now := time.Date(2024, 4, 24, 15, 0, 0, 0, time.UTC)
+	calendar := &fakeEarningsCalendar{dates: map[string]time.Time{
+		"MSFT": time.Date(2024, 4, 25, 0, 0, 0, 0, time.UTC),
+		"AAPL": time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+	}}
+
+	flags, unavailable := FlagPositionsNearEarnings([]string{"MSFT", "AAPL", "MSFT"}, now, calendar, config.EarningsConfig{WarnDays: 5})
+	if len(flags) != 1 || flags[0].Symbol != "MSFT" || flags[0].DaysAway != 1 || len(unavailable) != 0 {
+		t.Errorf("flags = %+v, unavailable %v; want MSFT one day away", flags, unavailable)
+	}
+
+	calendar.err = errors.New("rate limited")
+	if flags, unavailable := FlagPositionsNearEarnings([]string{"MSFT"}, now, calendar, config.EarningsConfig{}); len(flags) != 0 || len(unavailable) != 1 {
+		t.Errorf("calendar down: flags %+v, unavailable %v, want MSFT unavailable", flags, unavailable)
+	}
+}
@@ -0,0 +1,59 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	datafeed "github.com/fazecat/mogulmaker/Internal/database"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+// allowed bar age beyond one bar period when data_freshness.max_bar_age_minutes is unset
+const defaultMaxBarAgeMinutes = 30
+
+// returned (wrapped) when the latest bar is too old to price an order from
+var ErrStaleMarketData = errors.New("stale market data")
+
+// the bar with the newest timestamp, whichever order the fetch returned them in; bars must not be empty
+func LatestBar(bars []types.Bar) types.Bar {
+	latest := bars[0]
+	latestTime, _ := time.Parse(time.RFC3339, latest.Timestamp)
+	for _, bar := range bars[1:] {
+		if t, err := time.Parse(time.RFC3339, bar.Timestamp); err == nil && t.After(latestTime) {
+			latest, latestTime = bar, t
+		}
+	}
+	return latest
+}
+
+// checks the latest bar of a timeframe is no older than one bar period plus data_freshness.max_bar_age_minutes.
+// Equities are only checked during the regular session since no new bars print while the market is closed;
+// crypto trades around the clock and is always checked. A nil config or a disabled check passes everything
+func CheckBarFreshness(latest types.Bar, timeframe, assetType string, now time.Time, cfg *config.Config) error {
+	if cfg == nil || !cfg.DataFreshness.Enabled {
+		return nil
+	}
+	if assetType != "crypto" {
+		if status, _ := utils.CheckMarketStatus(now, cfg); status != "REGULAR" {
+			return nil
+		}
+	}
+
+	barTime, err := time.Parse(time.RFC3339, latest.Timestamp)
+	if err != nil {
+		return fmt.Errorf("%w: unreadable bar timestamp %q", ErrStaleMarketData, latest.Timestamp)
+	}
+	slack := cfg.DataFreshness.MaxBarAgeMinutes
+	if slack <= 0 {
+		slack = defaultMaxBarAgeMinutes
+	}
+	maxAge := datafeed.TimeframeDuration(timeframe) + time.Duration(slack)*time.Minute
+	if age := now.Sub(barTime); age > maxAge {
+		return fmt.Errorf("%w: latest %s bar is from %s, %s old (limit %s)",
+			ErrStaleMarketData, timeframe, barTime.UTC().Format(time.RFC3339), age.Round(time.Minute), maxAge)
+	}
+	return nil
+}
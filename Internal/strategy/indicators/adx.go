@@ -0,0 +1,71 @@
+package indicators
+
+import (
+	"fmt"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+// Wilder's 14-bar ADX period
+const DefaultADXPeriod = 14
+
+// Wilder's average directional index over oldest-first bars: how strongly price is trending (0-100), whichever
+// way. Aligned with bars, with the first value at 2*period-1 and 0 before it; needs at least 2*period bars
+func CalculateADX(bars []types.Bar, period int) ([]float64, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("adx period must be positive, got %d", period)
+	}
+	if len(bars) < 2*period {
+		return nil, fmt.Errorf("not enough data for %d-period adx - need %d bars, got %d", period, 2*period, len(bars))
+	}
+
+	trueRanges := make([]float64, len(bars))
+	plusDM := make([]float64, len(bars))
+	minusDM := make([]float64, len(bars))
+	for i := 1; i < len(bars); i++ {
+		trueRanges[i] = CalculateTrueRange(bars[i].High, bars[i].Low, bars[i-1].Close)
+		up := bars[i].High - bars[i-1].High
+		down := bars[i-1].Low - bars[i].Low
+		if up > down && up > 0 {
+			plusDM[i] = up
+		}
+		if down > up && down > 0 {
+			minusDM[i] = down
+		}
+	}
+
+	// Wilder smoothing: seeded with the sum of the first period values, then each step sheds 1/period
+	var smoothedTR, smoothedPlus, smoothedMinus float64
+	dx := make([]float64, len(bars))
+	for i := 1; i < len(bars); i++ {
+		if i <= period {
+			smoothedTR += trueRanges[i]
+			smoothedPlus += plusDM[i]
+			smoothedMinus += minusDM[i]
+			if i < period {
+				continue
+			}
+		} else {
+			smoothedTR += trueRanges[i] - smoothedTR/float64(period)
+			smoothedPlus += plusDM[i] - smoothedPlus/float64(period)
+			smoothedMinus += minusDM[i] - smoothedMinus/float64(period)
+		}
+		if smoothedTR == 0 {
+			continue
+		}
+		plusDI := 100 * smoothedPlus / smoothedTR
+		minusDI := 100 * smoothedMinus / smoothedTR
+		if plusDI+minusDI > 0 {
+			dx[i] = 100 * utils.Abs(plusDI-minusDI) / (plusDI + minusDI)
+		}
+	}
+
+	adx := make([]float64, len(bars))
+	first := 2*period - 1
+	adx[first] = utils.Average(dx[period : first+1])
+	for i := first + 1; i < len(bars); i++ {
+		adx[i] = (adx[i-1]*float64(period-1) + dx[i]) / float64(period)
+	}
+	return adx, nil
+}
@@ -0,0 +1,72 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// closes climbing step per bar with a fixed 1-point range around each
+func trendingADXBars(n int, step float64) []types.Bar {
+	bars := make([]types.Bar, n)
+	for i := range bars {
+		c := 100 + float64(i)*step
+		bars[i] = types.Bar{Open: c - step/2, High: c + 0.5, Low: c - 0.5, Close: c}
+	}
+	return bars
+}
+
+// closes swinging around 100 every few bars without going anywhere
+func rangingADXBars(n int) []types.Bar {
+	bars := make([]types.Bar, n)
+	for i := range bars {
+		c := 100 + 2*math.Sin(float64(i)*math.Pi/3)
+		bars[i] = types.Bar{Open: c, High: c + 0.5, Low: c - 0.5, Close: c}
+	}
+	return bars
+}
+
+func TestCalculateADX(t *testing.T) {
+	trending, err := CalculateADX(trendingADXBars(60, 1), DefaultADXPeriod)
+	if err != nil {
+		t.Fatalf("CalculateADX(trending): %v", err)
+	}
+	if got := trending[len(trending)-1]; got < 40 {
+		t.Errorf("trending ADX = %.1f, want a strong trend above 40", got)
+	}
+	if trending[2*DefaultADXPeriod-2] != 0 || trending[2*DefaultADXPeriod-1] == 0 {
+		t.Errorf("ADX should start at index %d", 2*DefaultADXPeriod-1)
+	}
+
+	ranging, err := CalculateADX(rangingADXBars(60), DefaultADXPeriod)
+	if err != nil {
+		t.Fatalf("CalculateADX(ranging): %v", err)
+	}
+	if got := ranging[len(ranging)-1]; got >= 20 {
+		t.Errorf("ranging ADX = %.1f, want it below 20", got)
+	}
+
+	// a falling trend is just as strong
+	falling, _ := CalculateADX(trendingADXBars(60, -1), DefaultADXPeriod)
+	if got := falling[len(falling)-1]; got < 40 {
+		t.Errorf("falling ADX = %.1f, want a strong trend above 40", got)
+	}
+}
+
+func TestCalculateADXErrors(t *testing.T) {
+	if _, err := CalculateADX(trendingADXBars(27, 1), DefaultADXPeriod); err == nil {
+		t.Error("want an error with fewer than 2*period bars")
+	}
+	if _, err := CalculateADX(trendingADXBars(30, 1), 0); err == nil {
+		t.Error("want an error for a zero period")
+	}
+	flat := make([]types.Bar, 30)
+	for i := range flat {
+		flat[i] = types.Bar{Open: 50, High: 50, Low: 50, Close: 50}
+	}
+	adx, err := CalculateADX(flat, DefaultADXPeriod)
+	if err != nil || adx[len(adx)-1] != 0 {
+		t.Errorf("flat bars ADX = %v (err %v), want 0", adx[len(adx)-1], err)
+	}
+}
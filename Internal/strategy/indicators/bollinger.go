@@ -0,0 +1,92 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// band width at or under this share of its recent average counts as a squeeze
+const SqueezeWidthRatio = 0.75
+
+// standard deviations the bands sit from the middle when IsBollingerSqueeze builds them
+const DefaultBollingerStdDev = 2.0
+
+// most band widths (before the latest) the squeeze compares against
+const squeezeHistoryBars = 100
+
+// period-SMA middle band with upper and lower bands stdDevMult population standard deviations away, aligned with
+// closes; entries before the first full period are 0
+func CalculateBollingerBands(closes []float64, period int, stdDevMult float64) (upper, middle, lower []float64, err error) {
+	if period <= 0 {
+		return nil, nil, nil, fmt.Errorf("bollinger period must be positive, got %d", period)
+	}
+	if len(closes) < period {
+		return nil, nil, nil, fmt.Errorf("not enough data for %d-period bollinger bands (got %d closes)", period, len(closes))
+	}
+
+	upper = make([]float64, len(closes))
+	middle = make([]float64, len(closes))
+	lower = make([]float64, len(closes))
+	for i := period - 1; i < len(closes); i++ {
+		window := closes[i-period+1 : i+1]
+		mean := 0.0
+		for _, c := range window {
+			mean += c
+		}
+		mean /= float64(period)
+
+		variance := 0.0
+		for _, c := range window {
+			variance += (c - mean) * (c - mean)
+		}
+		band := stdDevMult * math.Sqrt(variance/float64(period))
+
+		middle[i] = mean
+		upper[i] = mean + band
+		lower[i] = mean - band
+	}
+	return upper, middle, lower, nil
+}
+
+// (upper - lower) / middle at each index the bands cover, 0 elsewhere
+func BollingerBandWidth(upper, middle, lower []float64) []float64 {
+	widths := make([]float64, len(middle))
+	for i := range middle {
+		if middle[i] != 0 {
+			widths[i] = (upper[i] - lower[i]) / middle[i]
+		}
+	}
+	return widths
+}
+
+// whether the bands over oldest-first bars have contracted to SqueezeWidthRatio of their average width across up
+// to the last 100 bars, plus the current width. Needs a full period of widths before the latest to compare with,
+// otherwise it reports no squeeze
+func IsBollingerSqueeze(bars []types.Bar, period int) (bool, float64) {
+	closes := make([]float64, len(bars))
+	for i, bar := range bars {
+		closes[i] = bar.Close
+	}
+	upper, middle, lower, err := CalculateBollingerBands(closes, period, DefaultBollingerStdDev)
+	if err != nil {
+		return false, 0
+	}
+	widths := BollingerBandWidth(upper, middle, lower)
+	current := widths[len(widths)-1]
+
+	history := widths[period-1 : len(widths)-1]
+	if len(history) > squeezeHistoryBars {
+		history = history[len(history)-squeezeHistoryBars:]
+	}
+	if len(history) < period {
+		return false, current
+	}
+	average := 0.0
+	for _, w := range history {
+		average += w
+	}
+	average /= float64(len(history))
+	return average > 0 && current <= average*SqueezeWidthRatio, current
+}
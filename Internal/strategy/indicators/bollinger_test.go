@@ -0,0 +1,59 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+func TestCalculateBollingerBands(t *testing.T) {
+	closes := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+
+	upper, middle, lower, err := CalculateBollingerBands(closes, 8, 2)
+	if err != nil {
+		t.Fatalf("CalculateBollingerBands: %v", err)
+	}
+	// mean 5, population standard deviation 2
+	if middle[7] != 5 || upper[7] != 9 || lower[7] != 1 {
+		t.Errorf("bands = %.2f/%.2f/%.2f, want 9/5/1", upper[7], middle[7], lower[7])
+	}
+	if middle[6] != 0 {
+		t.Errorf("entries before the first full period should be 0, got %.2f", middle[6])
+	}
+
+	if _, _, _, err := CalculateBollingerBands(closes[:5], 8, 2); err == nil {
+		t.Error("want an error with fewer closes than the period")
+	}
+	if _, _, _, err := CalculateBollingerBands(closes, 0, 2); err == nil {
+		t.Error("want an error for a zero period")
+	}
+}
+
+func squeezeBars(wide, tight int) []types.Bar {
+	bars := make([]types.Bar, 0, wide+tight)
+	for i := 0; i < wide; i++ {
+		bars = append(bars, types.Bar{Close: 100 + 5*math.Pow(-1, float64(i))})
+	}
+	for i := 0; i < tight; i++ {
+		bars = append(bars, types.Bar{Close: 100 + 0.5*math.Pow(-1, float64(i))})
+	}
+	return bars
+}
+
+func TestIsBollingerSqueeze(t *testing.T) {
+	squeeze, width := IsBollingerSqueeze(squeezeBars(60, 20), 20)
+	if !squeeze {
+		t.Errorf("bands contracting to a tenth of their width not flagged (width %.4f)", width)
+	}
+	if math.Abs(width-0.02) > 1e-9 {
+		t.Errorf("width = %.4f, want 0.02", width)
+	}
+
+	if squeeze, width := IsBollingerSqueeze(squeezeBars(80, 0), 20); squeeze {
+		t.Errorf("steady volatility flagged as a squeeze (width %.4f)", width)
+	}
+	if squeeze, width := IsBollingerSqueeze(squeezeBars(10, 0), 20); squeeze || width != 0 {
+		t.Errorf("too few bars: squeeze %v width %.4f, want neither", squeeze, width)
+	}
+}
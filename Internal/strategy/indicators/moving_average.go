@@ -0,0 +1,59 @@
+package indicators
+
+import (
+	"fmt"
+
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+// simple moving average aligned with closes; entries before the first full period are 0
+func CalculateSMA(closes []float64, period int) ([]float64, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("sma period must be positive, got %d", period)
+	}
+	if len(closes) < period {
+		return nil, fmt.Errorf("not enough data for %d-period sma (got %d closes)", period, len(closes))
+	}
+
+	sma := make([]float64, len(closes))
+	for i := period - 1; i < len(closes); i++ {
+		sma[i] = utils.Average(closes[i-period+1 : i+1])
+	}
+	return sma, nil
+}
+
+// exponential moving average aligned with closes, seeded with the SMA of the first period closes and then
+// weighted 2/(period+1) toward each new close; entries before the seed are 0
+func CalculateEMA(closes []float64, period int) ([]float64, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("ema period must be positive, got %d", period)
+	}
+	if len(closes) < period {
+		return nil, fmt.Errorf("not enough data for %d-period ema (got %d closes)", period, len(closes))
+	}
+
+	ema := make([]float64, len(closes))
+	ema[period-1] = utils.Average(closes[:period])
+	multiplier := 2 / float64(period+1)
+	for i := period; i < len(closes); i++ {
+		ema[i] = (closes[i]-ema[i-1])*multiplier + ema[i-1]
+	}
+	return ema, nil
+}
+
+// whether the fast average crossed above (golden cross) or below (death cross) the slow one on the latest bar.
+// The series are compared by their last two entries, so they must end on the same bar; a 0 (warm-up) entry
+// in either pair means no cross
+func DetectMACrossover(fast, slow []float64) (crossedUp, crossedDown bool) {
+	if len(fast) < 2 || len(slow) < 2 {
+		return false, false
+	}
+	prevFast, lastFast := fast[len(fast)-2], fast[len(fast)-1]
+	prevSlow, lastSlow := slow[len(slow)-2], slow[len(slow)-1]
+	if prevFast == 0 || lastFast == 0 || prevSlow == 0 || lastSlow == 0 {
+		return false, false
+	}
+	crossedUp = prevFast <= prevSlow && lastFast > lastSlow
+	crossedDown = prevFast >= prevSlow && lastFast < lastSlow
+	return crossedUp, crossedDown
+}
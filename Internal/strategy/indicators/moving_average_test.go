@@ -0,0 +1,67 @@
+package indicators
+
+import "testing"
+
+func TestCalculateSMA(t *testing.T) {
+	sma, err := CalculateSMA([]float64{1, 2, 3, 4, 5}, 3)
+	if err != nil {
+		t.Fatalf("CalculateSMA: %v", err)
+	}
+	want := []float64{0, 0, 2, 3, 4}
+	for i := range want {
+		if sma[i] != want[i] {
+			t.Errorf("sma[%d] = %.2f, want %.2f", i, sma[i], want[i])
+		}
+	}
+
+	if _, err := CalculateSMA([]float64{1, 2}, 3); err == nil {
+		t.Error("want an error with fewer closes than the period")
+	}
+	if _, err := CalculateSMA([]float64{1, 2}, 0); err == nil {
+		t.Error("want an error for a zero period")
+	}
+}
+
+func TestCalculateEMA(t *testing.T) {
+	ema, err := CalculateEMA([]float64{2, 4, 6, 8, 10, 9}, 3)
+	if err != nil {
+		t.Fatalf("CalculateEMA: %v", err)
+	}
+	// seeded with the SMA of 2, 4, 6, then each close is weighted 2/(3+1) = 0.5
+	want := []float64{0, 0, 4, 6, 8, 8.5}
+	for i := range want {
+		if ema[i] != want[i] {
+			t.Errorf("ema[%d] = %.2f, want %.2f", i, ema[i], want[i])
+		}
+	}
+
+	if _, err := CalculateEMA([]float64{2, 4}, 3); err == nil {
+		t.Error("want an error with fewer closes than the period")
+	}
+}
+
+func TestDetectMACrossover_CrossingBar(t *testing.T) {
+	closes := []float64{10, 9, 8, 7, 6, 5, 6, 8, 10, 12}
+	mirrored := make([]float64, len(closes))
+	for i, c := range closes {
+		mirrored[i] = 20 - c
+	}
+
+	for name, series := range map[string][]float64{"golden": closes, "death": mirrored} {
+		fast, _ := CalculateSMA(series, 2)
+		slow, _ := CalculateSMA(series, 4)
+		for i := 1; i < len(series); i++ {
+			up, down := DetectMACrossover(fast[:i+1], slow[:i+1])
+			// the 2-bar average climbs through the 4-bar one on bar 7 (7 vs 6.25 after 5.5 vs 6.25)
+			wantUp := name == "golden" && i == 7
+			wantDown := name == "death" && i == 7
+			if up != wantUp || down != wantDown {
+				t.Errorf("%s bar %d: crossed up/down = %v/%v, want %v/%v", name, i, up, down, wantUp, wantDown)
+			}
+		}
+	}
+
+	if up, down := DetectMACrossover([]float64{1}, []float64{2}); up || down {
+		t.Error("want no cross with a single value")
+	}
+}
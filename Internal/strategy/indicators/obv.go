@@ -0,0 +1,46 @@
+package indicators
+
+import (
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+// net OBV moves under this share of the volume traded across the window read as flat
+const obvFlatShare = 0.1
+
+// on-balance volume over oldest-first bars: each bar's volume is added on an up close, subtracted on a down
+// close and skipped when the close is unchanged. Aligned with bars and starting at 0, so only its slope matters
+func CalculateOBV(bars []types.Bar) []float64 {
+	obv := make([]float64, len(bars))
+	for i := 1; i < len(bars); i++ {
+		obv[i] = obv[i-1]
+		switch {
+		case bars[i].Close > bars[i-1].Close:
+			obv[i] += float64(bars[i].Volume)
+		case bars[i].Close < bars[i-1].Close:
+			obv[i] -= float64(bars[i].Volume)
+		}
+	}
+	return obv
+}
+
+// "rising" (accumulation) or "falling" (distribution) when OBV's net move over the last lookback bars is at least
+// a tenth of the volume that moved it either way; "flat" otherwise, or when there aren't lookback+1 values
+func OBVTrend(obv []float64, lookback int) string {
+	if lookback <= 0 || len(obv) <= lookback {
+		return "flat"
+	}
+	window := obv[len(obv)-lookback-1:]
+	gross := 0.0
+	for i := 1; i < len(window); i++ {
+		gross += utils.Abs(window[i] - window[i-1])
+	}
+	net := window[len(window)-1] - window[0]
+	if gross == 0 || utils.Abs(net) < gross*obvFlatShare {
+		return "flat"
+	}
+	if net > 0 {
+		return "rising"
+	}
+	return "falling"
+}
@@ -0,0 +1,56 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+func obvBars(closes []float64, volumes []int64) []types.Bar {
+	bars := make([]types.Bar, len(closes))
+	for i := range closes {
+		bars[i] = types.Bar{Close: closes[i], Volume: volumes[i]}
+	}
+	return bars
+}
+
+func TestCalculateOBV(t *testing.T) {
+	obv := CalculateOBV(obvBars(
+		[]float64{10, 11, 11, 10.5, 12},
+		[]int64{500, 1000, 700, 400, 900},
+	))
+	// +1000 on the up close, nothing on the unchanged one, -400 on the down close, +900 on the last
+	want := []float64{0, 1000, 1000, 600, 1500}
+	for i := range want {
+		if obv[i] != want[i] {
+			t.Errorf("obv[%d] = %.0f, want %.0f", i, obv[i], want[i])
+		}
+	}
+	if got := CalculateOBV(nil); len(got) != 0 {
+		t.Errorf("CalculateOBV(nil) = %v, want empty", got)
+	}
+}
+
+func TestOBVTrend(t *testing.T) {
+	// price chops sideways while the heavy volume lands on up closes: accumulation
+	closes := []float64{100, 101, 100, 101, 100, 101, 100, 101}
+	accumulation := CalculateOBV(obvBars(closes, []int64{0, 1500, 500, 1500, 500, 1500, 500, 1500}))
+	if got := OBVTrend(accumulation, 6); got != "rising" {
+		t.Errorf("accumulation trend = %q, want rising", got)
+	}
+
+	// the same prices with the heavy volume on down closes: distribution
+	distribution := CalculateOBV(obvBars(closes, []int64{0, 500, 1500, 500, 1500, 500, 1500, 500}))
+	if got := OBVTrend(distribution, 6); got != "falling" {
+		t.Errorf("distribution trend = %q, want falling", got)
+	}
+
+	balanced := CalculateOBV(obvBars(closes, []int64{0, 1000, 1000, 1000, 1000, 1000, 1000, 1000}))
+	if got := OBVTrend(balanced, 6); got != "flat" {
+		t.Errorf("balanced trend = %q, want flat", got)
+	}
+
+	if got := OBVTrend(accumulation, len(accumulation)); got != "flat" {
+		t.Errorf("trend without lookback+1 values = %q, want flat", got)
+	}
+}
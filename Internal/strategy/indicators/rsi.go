@@ -51,3 +51,48 @@ func DetermineRSISignal(rsiValue float64) string {
 	}
 	return "neutral"
 }
+
+// most recent cross of a fast RSI over a slow one
+type RSICrossover struct {
+	Direction string  `json:"direction"` // BULLISH (fast crossed above slow), BEARISH, or "" when there was none
+	BarsAgo   int     `json:"bars_ago"`  // 0 when the cross happened on the latest bar
+	Fast      float64 `json:"fast"`      // latest fast RSI
+	Slow      float64 `json:"slow"`      // latest slow RSI
+}
+
+// fast and slow RSI over the same closes; fails when there aren't enough closes for the slow period
+func CalculateRSIPair(closes []float64, fastPeriod, slowPeriod int) (fast, slow []float64, err error) {
+	slow, err = CalculateRSI(closes, slowPeriod)
+	if err != nil {
+		return nil, nil, fmt.Errorf("slow RSI(%d) needs %d closes, got %d: %w", slowPeriod, slowPeriod+1, len(closes), err)
+	}
+	fast, err = CalculateRSI(closes, fastPeriod)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fast, slow, nil
+}
+
+// looks back lookback bars for the latest cross of fast over slow. Both series come from CalculateRSI on the
+// same closes, so values before the longer period are zero and are skipped
+func DetectRSICrossover(fast, slow []float64, fastPeriod, slowPeriod, lookback int) RSICrossover {
+	n := min(len(fast), len(slow))
+	if n == 0 {
+		return RSICrossover{}
+	}
+	result := RSICrossover{Fast: fast[n-1], Slow: slow[n-1]}
+
+	first := max(fastPeriod, slowPeriod) + 1 // the first bar with a valid previous value for both series
+	for i := n - 1; i >= first && n-1-i < max(lookback, 1); i-- {
+		before, now := fast[i-1]-slow[i-1], fast[i]-slow[i]
+		switch {
+		case before <= 0 && now > 0:
+			result.Direction, result.BarsAgo = "BULLISH", n-1-i
+			return result
+		case before >= 0 && now < 0:
+			result.Direction, result.BarsAgo = "BEARISH", n-1-i
+			return result
+		}
+	}
+	return result
+}
@@ -92,7 +92,7 @@ func TestCalculateAndStoreRSI(t *testing.T) {
 		return
 	}
 
-	err = datafeed.CalculateAndStoreRSI(symbol, bars)
+	err = datafeed.CalculateAndStoreRSI(symbol, timeframe, bars)
 	if err != nil {
 		t.Errorf("CalculateAndStoreRSI() error = %v", err)
 	}
@@ -137,3 +137,45 @@ func TestRSIMultipleValues(t *testing.T) {
 	// Log all RSI values to see the progression
 	t.Logf("RSI values: %v", rsi[period:])
 }
+
+// a steady decline followed by a sharp rally over the last three closes
+func pullbackThenRally() []float64 {
+	closes := make([]float64, 0, 33)
+	price := 130.0
+	for i := 0; i < 30; i++ {
+		price -= 1
+		closes = append(closes, price)
+	}
+	return append(closes, price+3, price+6, price+9)
+}
+
+func TestDetectRSICrossover_FastCrossesAboveSlow(t *testing.T) {
+	closes := pullbackThenRally()
+	fast, slow, err := CalculateRSIPair(closes, 5, 14)
+	if err != nil {
+		t.Fatalf("CalculateRSIPair() error = %v", err)
+	}
+
+	crossover := DetectRSICrossover(fast, slow, 5, 14, 3)
+	if crossover.Direction != "BULLISH" || crossover.BarsAgo != 2 {
+		t.Fatalf("crossover = %+v, want BULLISH on the first rally bar, 2 bars ago", crossover)
+	}
+	if crossover.Fast <= crossover.Slow {
+		t.Errorf("latest fast RSI %.2f not above slow %.2f", crossover.Fast, crossover.Slow)
+	}
+
+	// outside the lookback the cross no longer counts
+	if stale := DetectRSICrossover(fast, slow, 5, 14, 2); stale.Direction != "" {
+		t.Errorf("crossover with a 2-bar lookback = %+v, want none", stale)
+	}
+}
+
+func TestCalculateRSIPair_InsufficientDataForSlowPeriod(t *testing.T) {
+	closes := []float64{100, 101, 102, 101, 100, 99, 100, 101, 102, 103}
+	if _, _, err := CalculateRSIPair(closes, 5, 14); err == nil {
+		t.Error("CalculateRSIPair() with 10 closes and a 14-period slow RSI: want an error")
+	}
+	if got := DetectRSICrossover(nil, nil, 5, 14, 3); got.Direction != "" {
+		t.Errorf("DetectRSICrossover() on empty series = %+v, want no cross", got)
+	}
+}
@@ -0,0 +1,81 @@
+package indicators
+
+import (
+	"fmt"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// %K and %D levels DetermineStochasticSignal treats as stretched
+const (
+	StochasticOverbought = 80.0
+	StochasticOversold   = 20.0
+)
+
+// the usual 14-bar %K smoothed by a 3-bar %D
+const (
+	DefaultStochasticKPeriod = 14
+	DefaultStochasticDPeriod = 3
+)
+
+// stochastic oscillator over oldest-first bars: %K places each close in the high-low range of the last kPeriod
+// bars (0 at the low, 100 at the high, 50 when the range is flat) and %D is the dPeriod average of %K. Both
+// are aligned with bars; since 0 is a real %K, the warm-up is only known by index: %K starts at kPeriod-1 and
+// %D at kPeriod+dPeriod-2. Needs enough bars for at least one %D
+func CalculateStochastic(bars []types.Bar, kPeriod, dPeriod int) (k, d []float64, err error) {
+	if kPeriod <= 0 || dPeriod <= 0 {
+		return nil, nil, fmt.Errorf("stochastic periods must be positive, got %%K %d and %%D %d", kPeriod, dPeriod)
+	}
+	if need := kPeriod + dPeriod - 1; len(bars) < need {
+		return nil, nil, fmt.Errorf("not enough data for a %d/%d stochastic - need %d bars, got %d", kPeriod, dPeriod, need, len(bars))
+	}
+
+	k = make([]float64, len(bars))
+	for i := kPeriod - 1; i < len(bars); i++ {
+		highest, lowest := bars[i].High, bars[i].Low
+		for _, bar := range bars[i-kPeriod+1 : i] {
+			highest = max(highest, bar.High)
+			lowest = min(lowest, bar.Low)
+		}
+		if highest == lowest {
+			k[i] = 50
+			continue
+		}
+		k[i] = (bars[i].Close - lowest) / (highest - lowest) * 100
+	}
+
+	d = make([]float64, len(bars))
+	for i := kPeriod + dPeriod - 2; i < len(bars); i++ {
+		sum := 0.0
+		for _, value := range k[i-dPeriod+1 : i+1] {
+			sum += value
+		}
+		d[i] = sum / float64(dPeriod)
+	}
+	return k, d, nil
+}
+
+// overbought when %K and %D are both above StochasticOverbought, oversold when both are below
+// StochasticOversold; a lone %K spike stays neutral until %D follows
+func DetermineStochasticSignal(k, d float64) string {
+	if k > StochasticOverbought && d > StochasticOverbought {
+		return "overbought"
+	} else if k < StochasticOversold && d < StochasticOversold {
+		return "oversold"
+	}
+	return "neutral"
+}
+
+// whether %K crossed above (bullish) or below (bearish) %D on the latest bar. The series come from
+// CalculateStochastic with the same periods, so the cross needs two bars past the %D warm-up
+func DetectStochasticCrossover(k, d []float64, kPeriod, dPeriod int) (crossedUp, crossedDown bool) {
+	n := min(len(k), len(d))
+	if n < 2 || n-2 < kPeriod+dPeriod-2 {
+		return false, false
+	}
+	prevK, lastK := k[n-2], k[n-1]
+	prevD, lastD := d[n-2], d[n-1]
+	crossedUp = prevK <= prevD && lastK > lastD
+	crossedDown = prevK >= prevD && lastK < lastD
+	return crossedUp, crossedDown
+}
@@ -0,0 +1,80 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+func stochasticBars() []types.Bar {
+	return []types.Bar{
+		{High: 10, Low: 8, Close: 9},
+		{High: 11, Low: 9, Close: 10},
+		{High: 12, Low: 10, Close: 11},
+		{High: 11, Low: 9, Close: 9.5},
+		{High: 13, Low: 10, Close: 12.5},
+	}
+}
+
+func TestCalculateStochastic(t *testing.T) {
+	k, d, err := CalculateStochastic(stochasticBars(), 3, 2)
+	if err != nil {
+		t.Fatalf("CalculateStochastic: %v", err)
+	}
+
+	// %K: (11-8)/(12-8), (9.5-9)/(12-9), (12.5-9)/(13-9); %D averages each pair of %K
+	wantK := []float64{0, 0, 75, 100.0 / 6, 87.5}
+	wantD := []float64{0, 0, 0, (75 + 100.0/6) / 2, (100.0/6 + 87.5) / 2}
+	for i := range wantK {
+		if utils.Abs(k[i]-wantK[i]) > 1e-9 {
+			t.Errorf("k[%d] = %.4f, want %.4f", i, k[i], wantK[i])
+		}
+		if utils.Abs(d[i]-wantD[i]) > 1e-9 {
+			t.Errorf("d[%d] = %.4f, want %.4f", i, d[i], wantD[i])
+		}
+	}
+
+	if up, down := DetectStochasticCrossover(k, d, 3, 2); !up || down {
+		t.Errorf("crossover = up %v, down %v; want %%K crossing above %%D on the last bar", up, down)
+	}
+	if up, down := DetectStochasticCrossover(k[:4], d[:4], 3, 2); up || down {
+		t.Errorf("crossover before two %%D values = up %v, down %v; want none", up, down)
+	}
+}
+
+func TestCalculateStochasticFlatRangeAndErrors(t *testing.T) {
+	flat := []types.Bar{{High: 5, Low: 5, Close: 5}, {High: 5, Low: 5, Close: 5}}
+	k, _, err := CalculateStochastic(flat, 2, 1)
+	if err != nil {
+		t.Fatalf("CalculateStochastic: %v", err)
+	}
+	if k[1] != 50 {
+		t.Errorf("flat-range %%K = %.2f, want 50", k[1])
+	}
+
+	if _, _, err := CalculateStochastic(stochasticBars(), 3, 4); err == nil {
+		t.Error("want an error with too few bars for a %D value")
+	}
+	if _, _, err := CalculateStochastic(stochasticBars(), 0, 3); err == nil {
+		t.Error("want an error for a zero period")
+	}
+}
+
+func TestDetermineStochasticSignal(t *testing.T) {
+	tests := []struct {
+		k, d float64
+		want string
+	}{
+		{90, 85, "overbought"},
+		{90, 70, "neutral"},
+		{10, 15, "oversold"},
+		{10, 30, "neutral"},
+		{50, 50, "neutral"},
+	}
+	for _, tt := range tests {
+		if got := DetermineStochasticSignal(tt.k, tt.d); got != tt.want {
+			t.Errorf("DetermineStochasticSignal(%.0f, %.0f) = %q, want %q", tt.k, tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package indicators
+
+// recent volume at or under this share of its baseline counts as dried up
+const DryUpThreshold = 0.6
+
+// bars before the recent window that make up the baseline average
+const dryUpBaselineBars = 20
+
+// average volume of the last lookback bars over the average of up to 20 bars before them, oldest-first input.
+// 0 when there isn't a full window plus at least as many baseline bars, or the baseline traded nothing
+func VolumeDryUpRatio(volumes []int64, lookback int) float64 {
+	if lookback <= 0 || len(volumes) < 2*lookback {
+		return 0
+	}
+	recent := volumes[len(volumes)-lookback:]
+	baselineStart := len(volumes) - lookback - dryUpBaselineBars
+	if baselineStart < 0 {
+		baselineStart = 0
+	}
+	baseline := volumes[baselineStart : len(volumes)-lookback]
+
+	baselineAvg := averageVolume(baseline)
+	if baselineAvg <= 0 {
+		return 0
+	}
+	return averageVolume(recent) / baselineAvg
+}
+
+// volume contraction over the last lookback bars, the coiling that often comes before a breakout
+func DetectVolumeDryUp(volumes []int64, lookback int) bool {
+	ratio := VolumeDryUpRatio(volumes, lookback)
+	return ratio > 0 && ratio <= DryUpThreshold
+}
+
+func averageVolume(volumes []int64) float64 {
+	sum := 0.0
+	for _, v := range volumes {
+		sum += float64(v)
+	}
+	return sum / float64(len(volumes))
+}
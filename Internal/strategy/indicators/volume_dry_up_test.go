@@ -0,0 +1,32 @@
+package indicators
+
+import "testing"
+
+func TestDetectVolumeDryUp(t *testing.T) {
+	normal := make([]int64, 25)
+	for i := range normal {
+		normal[i] = 1_000_000 + int64(i%3)*50_000
+	}
+	if DetectVolumeDryUp(normal, 5) {
+		t.Errorf("steady volume flagged as a dry-up (ratio %.2f)", VolumeDryUpRatio(normal, 5))
+	}
+
+	dryUp := append([]int64{}, normal[:20]...)
+	dryUp = append(dryUp, 600_000, 450_000, 400_000, 350_000, 300_000)
+	if !DetectVolumeDryUp(dryUp, 5) {
+		t.Errorf("contracting volume not flagged (ratio %.2f)", VolumeDryUpRatio(dryUp, 5))
+	}
+	if ratio := VolumeDryUpRatio(dryUp, 5); ratio < 0.39 || ratio > 0.41 {
+		t.Errorf("ratio = %.3f, want about 0.4", ratio)
+	}
+
+	// a spike on the latest bar pulls the window back above the threshold
+	spiked := append(append([]int64{}, dryUp[:24]...), 3_000_000)
+	if DetectVolumeDryUp(spiked, 5) {
+		t.Errorf("window ending in a volume spike flagged as a dry-up (ratio %.2f)", VolumeDryUpRatio(spiked, 5))
+	}
+
+	if DetectVolumeDryUp(dryUp[16:], 5) || DetectVolumeDryUp(dryUp, 0) {
+		t.Error("want no dry-up without a baseline as long as the window")
+	}
+}
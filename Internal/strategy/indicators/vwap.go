@@ -2,22 +2,109 @@ package indicators
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/fazecat/mogulmaker/Internal/types"
 )
 
+// whether NewVWAPCalculator anchors intraday bars to their trading day, and the zone days are cut in;
+// set from features.session_vwap and global.market_hours.timezone
+var sessionVWAP = struct {
+	enabled  bool
+	location *time.Location
+}{}
+
+// turns session anchoring on or off; an empty timezone means America/New_York, an unknown one is reported
+// and the current zone kept
+func SetSessionVWAP(enabled bool, timezone string) error {
+	sessionVWAP.enabled = enabled
+	if timezone == "" {
+		timezone = "America/New_York"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return fmt.Errorf("session VWAP timezone %q: %w", timezone, err)
+	}
+	sessionVWAP.location = loc
+	return nil
+}
+
 // computes Volume Weighted Average Price
 type VWAPCalculator struct {
-	bars []types.Bar
+	bars          []types.Bar
+	sessionStarts []int // index of the first bar of each bar's session, nil when the whole slice is one session
 }
 
-// creates a new VWAP calculator
+// creates a new VWAP calculator; with session VWAP enabled, intraday bars are anchored to their trading day
 func NewVWAPCalculator(bars []types.Bar) *VWAPCalculator {
+	if sessionVWAP.enabled && isIntraday(bars) {
+		return NewSessionVWAPCalculator(bars, sessionVWAP.location)
+	}
 	return &VWAPCalculator{
 		bars: bars,
 	}
 }
 
+// creates a VWAP calculator that restarts its accumulation at the first bar of each trading day in loc
+// (New York when nil), so intraday VWAP reflects only the current session. Bars are oldest-first
+func NewSessionVWAPCalculator(bars []types.Bar, loc *time.Location) *VWAPCalculator {
+	if loc == nil {
+		loc, _ = time.LoadLocation("America/New_York")
+	}
+	return &VWAPCalculator{
+		bars:          bars,
+		sessionStarts: sessionStarts(bars, loc),
+	}
+}
+
+// for each bar, the index of the first bar on the same trading day; a bar whose timestamp can't be
+// parsed stays in the session before it
+func sessionStarts(bars []types.Bar, loc *time.Location) []int {
+	starts := make([]int, len(bars))
+	var sessionDay string
+	for i, bar := range bars {
+		if i > 0 {
+			starts[i] = starts[i-1]
+		}
+		ts, err := time.Parse(time.RFC3339, bar.Timestamp)
+		if err != nil {
+			continue
+		}
+		if day := ts.In(loc).Format(time.DateOnly); day != sessionDay {
+			if sessionDay != "" {
+				starts[i] = i
+			}
+			sessionDay = day
+		}
+	}
+	return starts
+}
+
+// whether the bars are spaced less than a day apart, judged on the last two with parseable timestamps
+func isIntraday(bars []types.Bar) bool {
+	if len(bars) < 2 {
+		return false
+	}
+	last, errLast := time.Parse(time.RFC3339, bars[len(bars)-1].Timestamp)
+	prev, errPrev := time.Parse(time.RFC3339, bars[len(bars)-2].Timestamp)
+	if errLast != nil || errPrev != nil {
+		return false
+	}
+	gap := last.Sub(prev)
+	if gap < 0 {
+		gap = -gap
+	}
+	return gap > 0 && gap < 24*time.Hour
+}
+
+// index of the first bar in the session containing index
+func (v *VWAPCalculator) sessionStart(index int) int {
+	if v.sessionStarts == nil {
+		return 0
+	}
+	return v.sessionStarts[index]
+}
+
 // returns the VWAP value for the full dataset
 func (v *VWAPCalculator) Calculate() float64 {
 	if len(v.bars) == 0 {
@@ -26,7 +113,7 @@ func (v *VWAPCalculator) Calculate() float64 {
 	return v.CalculateAt(len(v.bars) - 1)
 }
 
-// returns the VWAP value at a specific bar index
+// returns the VWAP value at a specific bar index, accumulated from the start of its session when session-anchored
 func (v *VWAPCalculator) CalculateAt(index int) float64 {
 	if index < 0 || index >= len(v.bars) {
 		return 0
@@ -35,7 +122,7 @@ func (v *VWAPCalculator) CalculateAt(index int) float64 {
 	typicalPrice := 0.0
 	volume := 0.0
 
-	for i := 0; i <= index; i++ {
+	for i := v.sessionStart(index); i <= index; i++ {
 		tp := v.typicalPrice(v.bars[i])
 		typicalPrice += tp * float64(v.bars[i].Volume)
 		volume += float64(v.bars[i].Volume)
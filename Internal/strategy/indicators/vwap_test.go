@@ -1,7 +1,9 @@
 package indicators
 
 import (
+	"math"
 	"testing"
+	"time"
 
 	"github.com/fazecat/mogulmaker/Internal/types"
 )
@@ -235,3 +237,61 @@ func TestVWAPAllValues(t *testing.T) {
 		}
 	}
 }
+
+func TestSessionVWAP_ResetsAtSessionBoundary(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	// two afternoon bars on March 4, then the March 5 open; 15:00/15:30 ET on the 4th are 20:00/20:30 UTC
+	bars := []types.Bar{
+		{Timestamp: "2024-03-04T20:00:00Z", High: 101, Low: 99, Close: 100, Volume: 1000},
+		{Timestamp: "2024-03-04T20:30:00Z", High: 103, Low: 101, Close: 102, Volume: 1000},
+		{Timestamp: "2024-03-05T14:30:00Z", High: 111, Low: 109, Close: 110, Volume: 500},
+		{Timestamp: "2024-03-05T15:00:00Z", High: 113, Low: 111, Close: 112, Volume: 1500},
+	}
+
+	session := NewSessionVWAPCalculator(bars, ny)
+	if got := session.CalculateAt(1); math.Abs(got-101) > 1e-9 {
+		t.Errorf("day one VWAP = %.4f, want 101", got)
+	}
+	if got := session.CalculateAt(2); math.Abs(got-110) > 1e-9 {
+		t.Errorf("first bar of day two = %.4f, want 110 (reset at the boundary)", got)
+	}
+	// (110*500 + 112*1500) / 2000
+	if got := session.Calculate(); math.Abs(got-111.5) > 1e-9 {
+		t.Errorf("day two VWAP = %.4f, want 111.5", got)
+	}
+
+	// the whole-slice VWAP still blends both days
+	if got := NewVWAPCalculator(bars).Calculate(); math.Abs(got-(100*1000+102*1000+110*500+112*1500)/4000.0) > 1e-9 {
+		t.Errorf("unanchored VWAP = %.4f, want both sessions blended", got)
+	}
+}
+
+func TestSetSessionVWAP_AnchorsOnlyIntradayBars(t *testing.T) {
+	defer func(saved bool, loc *time.Location) { sessionVWAP.enabled, sessionVWAP.location = saved, loc }(sessionVWAP.enabled, sessionVWAP.location)
+	if err := SetSessionVWAP(true, "America/New_York"); err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+
+	intraday := []types.Bar{
+		{Timestamp: "2024-03-04T20:30:00Z", High: 101, Low: 99, Close: 100, Volume: 1000},
+		{Timestamp: "2024-03-05T14:30:00Z", High: 121, Low: 119, Close: 120, Volume: 1000},
+	}
+	if got := NewVWAPCalculator(intraday).Calculate(); got != 120 {
+		t.Errorf("session-anchored intraday VWAP = %.2f, want 120 from today's bar alone", got)
+	}
+
+	daily := []types.Bar{
+		{Timestamp: "2024-03-04T05:00:00Z", High: 101, Low: 99, Close: 100, Volume: 1000},
+		{Timestamp: "2024-03-05T05:00:00Z", High: 121, Low: 119, Close: 120, Volume: 1000},
+	}
+	if got := NewVWAPCalculator(daily).Calculate(); got != 110 {
+		t.Errorf("daily VWAP = %.2f, want 110 across both bars", got)
+	}
+
+	if err := SetSessionVWAP(true, "Not/AZone"); err == nil {
+		t.Errorf("unknown timezone should be reported")
+	}
+}
@@ -1,8 +1,11 @@
 package metrics
 
 import (
+	"fmt"
+	"math"
 	"time"
 
+	"github.com/fazecat/mogulmaker/Internal/strategy"
 	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
 	"github.com/fazecat/mogulmaker/Internal/types"
 )
@@ -10,23 +13,88 @@ import (
 type Position struct {
 	Symbol     string
 	InTrade    bool
+	Direction  string // LONG or SHORT
 	EntryPrice float64
 	Quantity   float64
 	EntryTime  time.Time
 	EntryDate  string // Store the bar date as string (YYYY-MM-DD)
 }
 
-func RunBacktest(symbol string, bars []types.Bar, startingCapital float64) ([]TradeResult, error) {
+// stop distance in ATRs used for sizing when the order config has no stop loss percent
+const backtestATRStopMultiplier = 2.0
+
+// first bar the backtester evaluates, so every strategy sees at least this much history
+const backtestWarmupBars = 14
+
+// runs the default RSI strategy on one symbol; with an order config entries are sized like live trades
+// (risk-based quantity capped by cash), otherwise every entry uses the full starting capital
+func RunBacktest(symbol string, bars []types.Bar, startingCapital float64, cfg *strategy.OrderConfig) ([]TradeResult, error) {
+	return RunBacktestWithProgress(symbol, bars, startingCapital, cfg, nil)
+}
+
+// RunBacktest that reports how many bars have been processed out of the total after each bar
+func RunBacktestWithProgress(symbol string, bars []types.Bar, startingCapital float64, cfg *strategy.OrderConfig, progress func(processed, total int)) ([]TradeResult, error) {
+	return RunBacktestWithStrategy(symbol, bars, startingCapital, cfg, NewRSIMeanReversion(), progress)
+}
+
+// RunBacktestWithProgress driven by the given strategy's entry and exit rules (nil runs the default); only
+// LONG entries are simulated, SHORT signals are ignored
+func RunBacktestWithStrategy(symbol string, bars []types.Bar, startingCapital float64, cfg *strategy.OrderConfig, strat Strategy, progress func(processed, total int)) ([]TradeResult, error) {
+	return RunBacktestWithConfig(symbol, bars, BacktestConfig{
+		InitialCapital: startingCapital,
+		Order:          cfg,
+		Strategy:       strat,
+		Progress:       progress,
+	})
+}
+
+// inputs for RunBacktestWithConfig; zero costs reproduce RunBacktestWithStrategy
+type BacktestConfig struct {
+	InitialCapital     float64
+	CommissionPerTrade float64                    // flat fee charged on every entry and exit fill
+	SlippageBps        float64                    // basis points each fill moves against the trade
+	AllowShorts        bool                       // take the strategy's SHORT entries instead of skipping them
+	Order              *strategy.OrderConfig      // sizes entries like live trades when set
+	Strategy           Strategy                   // nil runs the default RSI strategy
+	Progress           func(processed, total int) // called after each bar
+}
+
+// RunBacktestWithStrategy with trading costs and optional shorts: every fill is SlippageBps worse than the close
+// (long entries and short covers above it) and each leg pays CommissionPerTrade, so trade PnL and ReturnPercent
+// are net of Fees
+func RunBacktestWithConfig(symbol string, bars []types.Bar, bc BacktestConfig) ([]TradeResult, error) {
+	if bc.CommissionPerTrade < 0 || bc.SlippageBps < 0 {
+		return nil, fmt.Errorf("backtest commission and slippage must not be negative (got $%.2f, %.1f bps)", bc.CommissionPerTrade, bc.SlippageBps)
+	}
+	strat, progress := bc.Strategy, bc.Progress
+	if strat == nil {
+		strat = NewRSIMeanReversion()
+	}
 	if len(bars) == 0 {
 		return nil, nil
 	}
+	if progress != nil {
+		defer progress(len(bars), len(bars))
+	}
+	cfg := bc.Order.ForAsset(symbol, "")
+	slippage := bc.SlippageBps / 10000
+	// the close moved against a fill that buys (buying) or sells
+	fillAt := func(price float64, buying bool) float64 {
+		if buying {
+			return price * (1 + slippage)
+		}
+		return price * (1 - slippage)
+	}
 
 	var trades []TradeResult
 	currentPosition := Position{InTrade: false}
-	capital := startingCapital
+	capital := bc.InitialCapital
 
-	for i := 14; i < len(bars); i++ {
+	for i := backtestWarmupBars; i < len(bars); i++ {
 		currentBar := bars[i]
+		if progress != nil {
+			progress(i, len(bars))
+		}
 
 		// Parse the bar date for trade record
 		barDate := "1970-01-01"
@@ -34,34 +102,41 @@ func RunBacktest(symbol string, bars []types.Bar, startingCapital float64) ([]Tr
 			barDate = t.Format("2006-01-02")
 		}
 
-		closingPrices := make([]float64, i+1)
-		for j := 0; j <= i; j++ {
-			closingPrices[j] = bars[j].Close
-		}
-		rsiValues, err := indicators.CalculateRSI(closingPrices, 14)
-		if err != nil {
-			continue
-		}
-		rsi := rsiValues[len(rsiValues)-1]
-
-		if !currentPosition.InTrade && rsi < 30 {
-			// Enter long position
-			quantity := capital / currentBar.Close
+		if !currentPosition.InTrade {
+			enter, direction := strat.ShouldEnter(bars, i)
+			if !enter || (direction != "LONG" && (direction != "SHORT" || !bc.AllowShorts)) {
+				continue
+			}
+			// Enter the position, leaving cash for the entry commission
+			fillPrice := fillAt(currentBar.Close, direction == "LONG")
+			spendable := capital - bc.CommissionPerTrade
+			quantity := spendable / fillPrice
+			if cfg != nil {
+				quantity = math.Min(backtestPositionSize(capital, bars[:i+1], direction, cfg), math.Floor(spendable/fillPrice))
+			}
+			if quantity <= 0 {
+				continue
+			}
 			entryTime, _ := time.Parse("2006-01-02", barDate)
 			if entryTime.IsZero() {
 				entryTime = time.Now()
 			}
 			currentPosition = Position{
 				InTrade:    true,
-				EntryPrice: currentBar.Close,
+				Direction:  direction,
+				EntryPrice: fillPrice,
 				Quantity:   quantity,
 				EntryTime:  entryTime,
 				EntryDate:  barDate,
 			}
-		} else if currentPosition.InTrade && rsi > 70 {
-			trade := createTradeResult(symbol, currentPosition, currentBar.Close, barDate)
+		} else if strat.ShouldExit(currentPosition, bars, i) {
+			exitPrice := fillAt(currentBar.Close, currentPosition.Direction == "SHORT")
+			trade := createTradeResult(symbol, currentPosition, exitPrice, barDate, 2*bc.CommissionPerTrade)
 			trades = append(trades, trade)
 			currentPosition = Position{InTrade: false}
+			if cfg != nil {
+				capital += trade.PnL
+			}
 		}
 	}
 	if currentPosition.InTrade {
@@ -70,16 +145,62 @@ func RunBacktest(symbol string, bars []types.Bar, startingCapital float64) ([]Tr
 		if t, err := time.Parse(time.RFC3339, bars[len(bars)-1].Timestamp); err == nil {
 			barDate = t.Format("2006-01-02")
 		}
-		trade := createTradeResult(symbol, currentPosition, bars[len(bars)-1].Close, barDate)
+		exitPrice := fillAt(bars[len(bars)-1].Close, currentPosition.Direction == "SHORT")
+		trade := createTradeResult(symbol, currentPosition, exitPrice, barDate, 2*bc.CommissionPerTrade)
 		trades = append(trades, trade)
 	}
 
 	return trades, nil
 }
 
-func createTradeResult(symbol string, pos Position, exitPrice float64, exitDate string) TradeResult {
-	pnl := (exitPrice - pos.EntryPrice) * pos.Quantity
-	returnPercent := ((exitPrice - pos.EntryPrice) / pos.EntryPrice) * 100
+// sizes an entry at the last bar's close with the live CalculatePositionSize rules, capped by available cash; the
+// stop sits below a LONG entry and above a SHORT one
+func backtestPositionSize(capital float64, bars []types.Bar, direction string, cfg *strategy.OrderConfig) float64 {
+	entryPrice := bars[len(bars)-1].Close
+	if capital <= 0 || entryPrice <= 0 {
+		return 0
+	}
+
+	stopLoss, _ := strategy.CalculatePriceTargets(entryPrice, direction, cfg)
+	if cfg.StopLossPercent <= 0 {
+		atrBars := make([]indicators.ATRBar, len(bars))
+		for i, bar := range bars {
+			atrBars[i] = indicators.ATRBar{High: bar.High, Low: bar.Low, Close: bar.Close}
+		}
+		atrValues, err := indicators.CalculateATR(atrBars, 14)
+		if err != nil {
+			return 0
+		}
+		stopDistance := backtestATRStopMultiplier * atrValues[len(atrValues)-1]
+		stopLoss = entryPrice - stopDistance
+		if direction == "SHORT" {
+			stopLoss = entryPrice + stopDistance
+		}
+	}
+	if stopLoss <= 0 || stopLoss == entryPrice || (stopLoss > entryPrice) != (direction == "SHORT") {
+		return 0
+	}
+
+	quantity := float64(strategy.CalculatePositionSize(capital, entryPrice, stopLoss, cfg.MaxPortfolioPercent, cfg))
+	affordable := math.Floor(capital / entryPrice)
+	if quantity > affordable {
+		quantity = affordable
+	}
+	return quantity
+}
+
+// closes pos at exitPrice, taking fees out of the PnL and the return on the position's cost; a SHORT gains as the
+// price falls
+func createTradeResult(symbol string, pos Position, exitPrice float64, exitDate string, fees float64) TradeResult {
+	move := exitPrice - pos.EntryPrice
+	if pos.Direction == "SHORT" {
+		move = -move
+	}
+	pnl := move*pos.Quantity - fees
+	returnPercent := (move / pos.EntryPrice) * 100
+	if cost := pos.EntryPrice * pos.Quantity; fees > 0 && cost > 0 {
+		returnPercent = pnl / cost * 100
+	}
 
 	// Parse exit date to create proper exit time for duration calculation
 	exitTime, _ := time.Parse("2006-01-02", exitDate)
@@ -89,17 +210,28 @@ func createTradeResult(symbol string, pos Position, exitPrice float64, exitDate
 
 	return TradeResult{
 		Symbol:        symbol,
+		Direction:     pos.Direction,
 		EntryPrice:    pos.EntryPrice,
 		ExitPrice:     exitPrice,
 		Quantity:      pos.Quantity,
 		PnL:           pnl,
 		ReturnPercent: returnPercent,
+		Fees:          fees,
 		Duration:      exitTime.Sub(pos.EntryTime),
 		EntryTime:     pos.EntryTime,
 		ExitTime:      exitTime,
 	}
 }
 
+// commissions paid across trades
+func TotalFees(trades []TradeResult) float64 {
+	total := 0.0
+	for _, trade := range trades {
+		total += trade.Fees
+	}
+	return total
+}
+
 func CalculateWinRate(trades []TradeResult) float64 {
 	if len(trades) == 0 {
 		return 0.0
@@ -0,0 +1,278 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+func backtestEquityPath(startingCapital float64, trades []TradeResult) []float64 {
+	equity := []float64{startingCapital}
+	for _, trade := range trades {
+		equity = append(equity, equity[len(equity)-1]+trade.PnL)
+	}
+	return equity
+}
+
+func TestRunBacktest_OrderConfigCapsChangeEquityPath(t *testing.T) {
+	bars := buildOversoldThenRallyBars(100)
+
+	tight := &strategy.OrderConfig{MaxOpenPositions: 5, MaxPortfolioPercent: 0.5, StopLossPercent: 2}
+	loose := &strategy.OrderConfig{MaxOpenPositions: 5, MaxPortfolioPercent: 1.0, StopLossPercent: 2}
+
+	tightTrades, err := RunBacktest("AAA", bars, 10000, tight)
+	if err != nil {
+		t.Fatalf("RunBacktest() error = %v", err)
+	}
+	looseTrades, err := RunBacktest("AAA", bars, 10000, loose)
+	if err != nil {
+		t.Fatalf("RunBacktest() error = %v", err)
+	}
+	fullTrades, err := RunBacktest("AAA", bars, 10000, nil)
+	if err != nil {
+		t.Fatalf("RunBacktest() error = %v", err)
+	}
+
+	if len(tightTrades) == 0 || len(tightTrades) != len(looseTrades) || len(looseTrades) != len(fullTrades) {
+		t.Fatalf("Expected the same entries under every config, got %d/%d/%d trades", len(tightTrades), len(looseTrades), len(fullTrades))
+	}
+
+	tightQty, looseQty, fullQty := tightTrades[0].Quantity, looseTrades[0].Quantity, fullTrades[0].Quantity
+	if tightQty != math.Trunc(tightQty) || looseQty != math.Trunc(looseQty) {
+		t.Errorf("Sized quantities should be whole shares, got %v and %v", tightQty, looseQty)
+	}
+	if !(tightQty < looseQty && looseQty < fullQty) {
+		t.Errorf("Expected quantity to grow with the cap: tight=%v loose=%v full=%v", tightQty, looseQty, fullQty)
+	}
+
+	// risk of 1% of 10000 at a 2% stop sizes the position at roughly half the account
+	entry := looseTrades[0].EntryPrice
+	wantLoose := math.Floor(10000 * 0.01 / (entry * 0.02))
+	if looseQty != wantLoose {
+		t.Errorf("loose quantity = %v, want %v", looseQty, wantLoose)
+	}
+
+	tightEquity := backtestEquityPath(10000, tightTrades)
+	looseEquity := backtestEquityPath(10000, looseTrades)
+	if tightEquity[len(tightEquity)-1] >= looseEquity[len(looseEquity)-1] {
+		t.Errorf("Expected the tighter cap to end with less equity on a winning trade: tight=%v loose=%v", tightEquity, looseEquity)
+	}
+}
+
+func TestRunBacktest_ATRStopWhenNoStopPercent(t *testing.T) {
+	bars := buildOversoldThenRallyBars(100)
+	cfg := &strategy.OrderConfig{MaxOpenPositions: 5, MaxPortfolioPercent: 1.0}
+
+	trades, err := RunBacktest("AAA", bars, 10000, cfg)
+	if err != nil {
+		t.Fatalf("RunBacktest() error = %v", err)
+	}
+	if len(trades) == 0 {
+		t.Fatal("Expected at least one trade")
+	}
+	if trades[0].Quantity <= 0 || trades[0].Quantity != math.Trunc(trades[0].Quantity) {
+		t.Errorf("Expected a positive whole-share ATR-sized quantity, got %v", trades[0].Quantity)
+	}
+}
+
+func TestRunBacktestWithProgress_ReportsEveryBar(t *testing.T) {
+	bars := buildOversoldThenRallyBars(100)
+	var calls, lastProcessed int
+
+	_, err := RunBacktestWithProgress("AAA", bars, 10000, nil, func(processed, total int) {
+		if total != len(bars) {
+			t.Fatalf("total = %d, want %d", total, len(bars))
+		}
+		if processed < lastProcessed {
+			t.Fatalf("progress went backwards: %d after %d", processed, lastProcessed)
+		}
+		calls++
+		lastProcessed = processed
+	})
+	if err != nil {
+		t.Fatalf("RunBacktestWithProgress() error = %v", err)
+	}
+	if lastProcessed != len(bars) {
+		t.Errorf("final progress = %d, want %d", lastProcessed, len(bars))
+	}
+	if calls != len(bars)-14+1 {
+		t.Errorf("calls = %d, want one per evaluated bar plus completion", calls)
+	}
+}
+
+func TestRunBacktestWithStrategy_DifferentStrategiesDifferentTrades(t *testing.T) {
+	bars := buildOversoldThenRallyBars(100)
+
+	rsiTrades, err := RunBacktestWithStrategy("AAA", bars, 10000, nil, NewRSIMeanReversion(), nil)
+	if err != nil {
+		t.Fatalf("RSI backtest error = %v", err)
+	}
+	breakoutTrades, err := RunBacktestWithStrategy("AAA", bars, 10000, nil, NewBreakoutStrategy(), nil)
+	if err != nil {
+		t.Fatalf("breakout backtest error = %v", err)
+	}
+	if len(rsiTrades) == 0 || len(breakoutTrades) == 0 {
+		t.Fatalf("Expected trades from both strategies, got %d RSI and %d breakout", len(rsiTrades), len(breakoutTrades))
+	}
+
+	// RSI buys the selloff, the breakout waits for the rally to clear the old highs
+	if rsiTrades[0].EntryPrice >= breakoutTrades[0].EntryPrice {
+		t.Errorf("RSI entry %v should be below the breakout entry %v", rsiTrades[0].EntryPrice, breakoutTrades[0].EntryPrice)
+	}
+	if !breakoutTrades[0].EntryTime.After(rsiTrades[0].EntryTime) {
+		t.Errorf("breakout entered %v, want after the RSI entry %v", breakoutTrades[0].EntryTime, rsiTrades[0].EntryTime)
+	}
+	// the default path is unchanged
+	defaultTrades, err := RunBacktest("AAA", bars, 10000, nil)
+	if err != nil {
+		t.Fatalf("RunBacktest() error = %v", err)
+	}
+	if len(defaultTrades) != len(rsiTrades) || defaultTrades[0] != rsiTrades[0] {
+		t.Errorf("RunBacktest() = %+v, want the RSI strategy's trades %+v", defaultTrades, rsiTrades)
+	}
+}
+
+func TestStrategyByName(t *testing.T) {
+	for name, want := range map[string]string{"": StrategyRSIMeanReversion, "Breakout": StrategyBreakout, " pattern ": StrategyPattern} {
+		strat, err := StrategyByName(name)
+		if err != nil {
+			t.Fatalf("StrategyByName(%q) error = %v", name, err)
+		}
+		if strat.Name() != want {
+			t.Errorf("StrategyByName(%q) = %s, want %s", name, strat.Name(), want)
+		}
+	}
+	if _, err := StrategyByName("martingale"); err == nil {
+		t.Error("Expected an error for an unknown strategy")
+	}
+}
+
+func TestRunBacktestWithConfig_CostsReduceReturns(t *testing.T) {
+	bars := buildOversoldThenRallyBars(100)
+
+	gross, err := RunBacktestWithConfig("AAA", bars, BacktestConfig{InitialCapital: 10000})
+	if err != nil {
+		t.Fatalf("RunBacktestWithConfig() error = %v", err)
+	}
+	legacy, err := RunBacktest("AAA", bars, 10000, nil)
+	if err != nil {
+		t.Fatalf("RunBacktest() error = %v", err)
+	}
+	if len(gross) == 0 || len(gross) != len(legacy) || gross[0].PnL != legacy[0].PnL {
+		t.Fatalf("Zero-cost config should match RunBacktest, got %+v vs %+v", gross, legacy)
+	}
+	if TotalFees(gross) != 0 {
+		t.Errorf("TotalFees() = %v without costs, want 0", TotalFees(gross))
+	}
+
+	net, err := RunBacktestWithConfig("AAA", bars, BacktestConfig{InitialCapital: 10000, CommissionPerTrade: 5, SlippageBps: 10})
+	if err != nil {
+		t.Fatalf("RunBacktestWithConfig() error = %v", err)
+	}
+	if len(net) != len(gross) {
+		t.Fatalf("Costs shouldn't change the entries, got %d vs %d trades", len(net), len(gross))
+	}
+
+	g, n := gross[0], net[0]
+	if math.Abs(n.EntryPrice-g.EntryPrice*1.001) > 1e-9 || math.Abs(n.ExitPrice-g.ExitPrice*0.999) > 1e-9 {
+		t.Errorf("Fills = %v/%v, want 10bps worse than %v/%v", n.EntryPrice, n.ExitPrice, g.EntryPrice, g.ExitPrice)
+	}
+	if n.Fees != 10 || TotalFees(net) != 10*float64(len(net)) {
+		t.Errorf("Fees = %v (total %v), want $5 per leg", n.Fees, TotalFees(net))
+	}
+	wantPnL := (n.ExitPrice-n.EntryPrice)*n.Quantity - 10
+	if math.Abs(n.PnL-wantPnL) > 1e-9 {
+		t.Errorf("net PnL = %v, want %v", n.PnL, wantPnL)
+	}
+	if n.PnL >= g.PnL || n.ReturnPercent >= g.ReturnPercent {
+		t.Errorf("Expected net below gross: pnl %v vs %v, return %v vs %v", n.PnL, g.PnL, n.ReturnPercent, g.ReturnPercent)
+	}
+
+	if _, err := RunBacktestWithConfig("AAA", bars, BacktestConfig{InitialCapital: 10000, SlippageBps: -1}); err == nil {
+		t.Errorf("Expected an error for negative slippage")
+	}
+}
+
+// flat for 25 bars, then a steady decline
+func buildDecliningBars(start float64) []types.Bar {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var bars []types.Bar
+	price := start
+	for i := 0; i < 60; i++ {
+		if i >= 25 {
+			price -= 1
+		}
+		bars = append(bars, types.Bar{
+			Timestamp: base.AddDate(0, 0, i).Format(time.RFC3339),
+			Open:      price,
+			High:      price + 0.5,
+			Low:       price - 0.5,
+			Close:     price,
+			Volume:    1000,
+		})
+	}
+	return bars
+}
+
+func TestRunBacktestWithConfig_ShortsProfitOnDecline(t *testing.T) {
+	bars := buildDecliningBars(100)
+
+	longOnly, err := RunBacktestWithConfig("AAA", bars, BacktestConfig{InitialCapital: 10000, Strategy: NewBreakoutStrategy()})
+	if err != nil {
+		t.Fatalf("RunBacktestWithConfig() error = %v", err)
+	}
+	if len(longOnly) != 0 {
+		t.Errorf("Expected no trades without AllowShorts, got %+v", longOnly)
+	}
+
+	cfg := &strategy.OrderConfig{MaxOpenPositions: 5, MaxPortfolioPercent: 1.0, StopLossPercent: 2}
+	for name, order := range map[string]*strategy.OrderConfig{"full capital": nil, "sized": cfg, "atr sized": {MaxOpenPositions: 5, MaxPortfolioPercent: 1.0}} {
+		trades, err := RunBacktestWithConfig("AAA", bars, BacktestConfig{InitialCapital: 10000, Strategy: NewBreakoutStrategy(), AllowShorts: true, Order: order})
+		if err != nil {
+			t.Fatalf("%s: RunBacktestWithConfig() error = %v", name, err)
+		}
+		if len(trades) != 1 {
+			t.Fatalf("%s: expected one short, got %+v", name, trades)
+		}
+		trade := trades[0]
+		if trade.Direction != "SHORT" || trade.ExitPrice >= trade.EntryPrice {
+			t.Errorf("%s: expected a short covered lower, got %+v", name, trade)
+		}
+		want := (trade.EntryPrice - trade.ExitPrice) * trade.Quantity
+		if trade.Quantity <= 0 || trade.PnL <= 0 || math.Abs(trade.PnL-want) > 1e-9 || trade.ReturnPercent <= 0 {
+			t.Errorf("%s: PnL = %v (return %v%%), want (entry - exit) * qty = %v", name, trade.PnL, trade.ReturnPercent, want)
+		}
+	}
+}
+
+func TestRunBacktestWithConfig_ShortSlippageRaisesCover(t *testing.T) {
+	bars := buildDecliningBars(100)
+	run := func(bps float64) TradeResult {
+		trades, err := RunBacktestWithConfig("AAA", bars, BacktestConfig{InitialCapital: 10000, Strategy: NewBreakoutStrategy(), AllowShorts: true, SlippageBps: bps})
+		if err != nil || len(trades) != 1 {
+			t.Fatalf("RunBacktestWithConfig() = %+v, %v", trades, err)
+		}
+		return trades[0]
+	}
+	gross, net := run(0), run(10)
+	if net.EntryPrice >= gross.EntryPrice || net.ExitPrice <= gross.ExitPrice || net.PnL >= gross.PnL {
+		t.Errorf("Expected a short to sell lower and cover higher with slippage: gross %+v, net %+v", gross, net)
+	}
+}
+
+func TestBacktestPositionSize_ShortStopAboveEntry(t *testing.T) {
+	bars := buildDecliningBars(100)[:30]
+	cfg := &strategy.OrderConfig{MaxOpenPositions: 5, MaxPortfolioPercent: 1.0, StopLossPercent: 2}
+
+	entry := bars[len(bars)-1].Close
+	want := math.Floor(10000 * 0.01 / (entry * 0.02))
+	if got := backtestPositionSize(10000, bars, "SHORT", cfg); got != want {
+		t.Errorf("short size = %v, want %v", got, want)
+	}
+	if got := backtestPositionSize(10000, bars, "SHORT", &strategy.OrderConfig{MaxOpenPositions: 5, MaxPortfolioPercent: 1.0}); got <= 0 {
+		t.Errorf("ATR-stopped short size = %v, want positive", got)
+	}
+}
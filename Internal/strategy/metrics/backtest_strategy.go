@@ -0,0 +1,198 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/detection"
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// entry and exit rules the backtester runs; bars are oldest-first and i is the bar being evaluated, so only
+// bars[:i+1] may be looked at
+type Strategy interface {
+	Name() string
+	ShouldEnter(bars []types.Bar, i int) (bool, string) // direction is LONG or SHORT
+	ShouldExit(position Position, bars []types.Bar, i int) bool
+}
+
+const (
+	StrategyRSIMeanReversion = "rsi"
+	StrategyBreakout         = "breakout"
+	StrategyPattern          = "pattern"
+
+	DefaultStrategyName = StrategyRSIMeanReversion
+)
+
+var strategyFactories = map[string]func() Strategy{
+	StrategyRSIMeanReversion: func() Strategy { return NewRSIMeanReversion() },
+	StrategyBreakout:         func() Strategy { return NewBreakoutStrategy() },
+	StrategyPattern:          func() Strategy { return NewPatternStrategy() },
+}
+
+// a fresh instance of the named strategy; an empty name gives the default RSI mean reversion
+func StrategyByName(name string) (Strategy, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DefaultStrategyName
+	}
+	factory, ok := strategyFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown backtest strategy %q, want one of %s", name, strings.Join(StrategyNames(), ", "))
+	}
+	return factory(), nil
+}
+
+// registered strategy names, sorted
+func StrategyNames() []string {
+	names := make([]string, 0, len(strategyFactories))
+	for name := range strategyFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func closesThrough(bars []types.Bar, i int) []float64 {
+	closes := make([]float64, i+1)
+	for j := 0; j <= i; j++ {
+		closes[j] = bars[j].Close
+	}
+	return closes
+}
+
+// buys when RSI drops below Oversold and sells when it climbs above Overbought, the backtester's original rules;
+// shorts the mirror image, entering above Overbought and covering below Oversold
+type RSIMeanReversion struct {
+	Period     int
+	Oversold   float64
+	Overbought float64
+}
+
+func NewRSIMeanReversion() *RSIMeanReversion {
+	return &RSIMeanReversion{Period: 14, Oversold: 30, Overbought: 70}
+}
+
+func (s *RSIMeanReversion) Name() string { return StrategyRSIMeanReversion }
+
+func (s *RSIMeanReversion) rsiAt(bars []types.Bar, i int) (float64, bool) {
+	rsiValues, err := indicators.CalculateRSI(closesThrough(bars, i), s.Period)
+	if err != nil {
+		return 0, false
+	}
+	return rsiValues[len(rsiValues)-1], true
+}
+
+func (s *RSIMeanReversion) ShouldEnter(bars []types.Bar, i int) (bool, string) {
+	rsi, ok := s.rsiAt(bars, i)
+	if ok && rsi > s.Overbought {
+		return true, "SHORT"
+	}
+	return ok && rsi < s.Oversold, "LONG"
+}
+
+func (s *RSIMeanReversion) ShouldExit(position Position, bars []types.Bar, i int) bool {
+	rsi, ok := s.rsiAt(bars, i)
+	if position.Direction == "SHORT" {
+		return ok && rsi < s.Oversold
+	}
+	return ok && rsi > s.Overbought
+}
+
+// buys a close above the highest high of the prior EntryLookback bars and sells a close below the lowest low of
+// the prior ExitLookback bars; shorts a close below the EntryLookback low and covers above the ExitLookback high
+type BreakoutStrategy struct {
+	EntryLookback int
+	ExitLookback  int
+}
+
+func NewBreakoutStrategy() *BreakoutStrategy {
+	return &BreakoutStrategy{EntryLookback: 20, ExitLookback: 10}
+}
+
+func (s *BreakoutStrategy) Name() string { return StrategyBreakout }
+
+func (s *BreakoutStrategy) ShouldEnter(bars []types.Bar, i int) (bool, string) {
+	if i < s.EntryLookback {
+		return false, "LONG"
+	}
+	high, low := priorRange(bars, i, s.EntryLookback)
+	if bars[i].Close < low {
+		return true, "SHORT"
+	}
+	return bars[i].Close > high, "LONG"
+}
+
+func (s *BreakoutStrategy) ShouldExit(position Position, bars []types.Bar, i int) bool {
+	if i < s.ExitLookback {
+		return false
+	}
+	high, low := priorRange(bars, i, s.ExitLookback)
+	if position.Direction == "SHORT" {
+		return bars[i].Close > high
+	}
+	return bars[i].Close < low
+}
+
+// highest high and lowest low of the lookback bars before bar i
+func priorRange(bars []types.Bar, i, lookback int) (high, low float64) {
+	high, low = bars[i-lookback].High, bars[i-lookback].Low
+	for _, bar := range bars[i-lookback : i] {
+		high = max(high, bar.High)
+		low = min(low, bar.Low)
+	}
+	return high, low
+}
+
+// enters on a chart pattern pointing the trade's way with at least MinConfidence, and exits on an opposing
+// pattern or once the close is StopPercent against the entry
+type PatternStrategy struct {
+	Window        int // trailing bars handed to the pattern detector
+	MinConfidence float64
+	StopPercent   float64
+	detector      *detection.PatternDetector
+}
+
+func NewPatternStrategy() *PatternStrategy {
+	return &PatternStrategy{Window: 60, MinConfidence: 60, StopPercent: 5, detector: detection.NewPatternDetector()}
+}
+
+func (s *PatternStrategy) Name() string { return StrategyPattern }
+
+// the strongest detected pattern leaning LONG or SHORT over the window ending at bar i
+func (s *PatternStrategy) strongest(bars []types.Bar, i int) (detection.PatternSignal, bool) {
+	window := bars[max(0, i+1-s.Window) : i+1]
+	var best detection.PatternSignal
+	found := false
+	for _, signal := range s.detector.DetectAllPatterns(window) {
+		if signal.Direction != "LONG" && signal.Direction != "SHORT" {
+			continue
+		}
+		if signal.Confidence >= s.MinConfidence && (!found || signal.Confidence > best.Confidence) {
+			best, found = signal, true
+		}
+	}
+	return best, found
+}
+
+func (s *PatternStrategy) ShouldEnter(bars []types.Bar, i int) (bool, string) {
+	signal, ok := s.strongest(bars, i)
+	if !ok {
+		return false, ""
+	}
+	return true, signal.Direction
+}
+
+func (s *PatternStrategy) ShouldExit(position Position, bars []types.Bar, i int) bool {
+	move := (bars[i].Close - position.EntryPrice) / position.EntryPrice * 100
+	if position.Direction == "SHORT" {
+		move = -move
+	}
+	if s.StopPercent > 0 && move <= -s.StopPercent {
+		return true
+	}
+	signal, ok := s.strongest(bars, i)
+	return ok && signal.Direction != position.Direction
+}
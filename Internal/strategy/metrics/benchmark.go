@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+// benchmark alpha/beta are measured against when none is configured
+const DefaultBenchmark = "SPY"
+
+// beta is cov(trade, benchmark) / var(benchmark), alpha the mean excess return left after beta;
+// both series must already be aligned (see AlignBenchmarkReturns), otherwise the trailing overlap is used
+func CalculateAlphaBeta(tradeReturns, benchmarkReturns []float64) (alpha, beta float64) {
+	n := len(tradeReturns)
+	if len(benchmarkReturns) < n {
+		n = len(benchmarkReturns)
+	}
+	if n < 2 {
+		return 0, 0
+	}
+	trade := tradeReturns[len(tradeReturns)-n:]
+	bench := benchmarkReturns[len(benchmarkReturns)-n:]
+
+	tradeMean := utils.Average(trade)
+	benchMean := utils.Average(bench)
+
+	var covariance, variance float64
+	for i := 0; i < n; i++ {
+		covariance += (trade[i] - tradeMean) * (bench[i] - benchMean)
+		variance += (bench[i] - benchMean) * (bench[i] - benchMean)
+	}
+	if variance == 0 {
+		return tradeMean - benchMean, 0
+	}
+
+	beta = covariance / variance
+	alpha = tradeMean - beta*benchMean
+	return alpha, beta
+}
+
+// pairs each trade's return with the benchmark's return over the same holding period (by date),
+// dropping trades that fall outside the benchmark bars; both series are in percent
+func AlignBenchmarkReturns(trades []TradeResult, benchmarkBars []types.Bar) (tradeReturns, benchmarkReturns []float64) {
+	type dailyClose struct {
+		date  time.Time
+		close float64
+	}
+
+	closes := make([]dailyClose, 0, len(benchmarkBars))
+	for _, bar := range benchmarkBars {
+		t, err := time.Parse(time.RFC3339, bar.Timestamp)
+		if err != nil || bar.Close <= 0 {
+			continue
+		}
+		closes = append(closes, dailyClose{date: truncateDay(t), close: bar.Close})
+	}
+	sort.Slice(closes, func(i, j int) bool { return closes[i].date.Before(closes[j].date) })
+
+	// last benchmark close on or before the given day
+	closeOn := func(t time.Time) (float64, bool) {
+		day := truncateDay(t)
+		i := sort.Search(len(closes), func(i int) bool { return closes[i].date.After(day) })
+		if i == 0 {
+			return 0, false
+		}
+		return closes[i-1].close, true
+	}
+
+	for _, trade := range trades {
+		if trade.EntryTime.IsZero() || trade.ExitTime.IsZero() {
+			continue
+		}
+		entry, ok := closeOn(trade.EntryTime)
+		if !ok {
+			continue
+		}
+		exit, ok := closeOn(trade.ExitTime)
+		if !ok {
+			continue
+		}
+		tradeReturns = append(tradeReturns, trade.ReturnPercent)
+		benchmarkReturns = append(benchmarkReturns, (exit-entry)/entry*100)
+	}
+	return tradeReturns, benchmarkReturns
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+func TestCalculateAlphaBeta_PerfectlyCorrelated(t *testing.T) {
+	benchmark := []float64{1.0, -0.5, 2.0, 0.3, -1.2, 0.8}
+	trades := append([]float64(nil), benchmark...)
+
+	alpha, beta := CalculateAlphaBeta(trades, benchmark)
+	if math.Abs(beta-1) > 1e-9 {
+		t.Errorf("beta = %.6f, want 1", beta)
+	}
+	if math.Abs(alpha) > 1e-9 {
+		t.Errorf("alpha = %.6f, want 0", alpha)
+	}
+}
+
+func TestCalculateAlphaBeta_LeveragedWithExcessReturn(t *testing.T) {
+	benchmark := []float64{1.0, -0.5, 2.0, 0.3, -1.2}
+	trades := make([]float64, len(benchmark))
+	for i, r := range benchmark {
+		trades[i] = 2*r + 0.5
+	}
+
+	alpha, beta := CalculateAlphaBeta(trades, benchmark)
+	if math.Abs(beta-2) > 1e-9 {
+		t.Errorf("beta = %.6f, want 2", beta)
+	}
+	if math.Abs(alpha-0.5) > 1e-9 {
+		t.Errorf("alpha = %.6f, want 0.5", alpha)
+	}
+}
+
+func TestCalculateAlphaBeta_TooFewPoints(t *testing.T) {
+	if alpha, beta := CalculateAlphaBeta([]float64{1}, []float64{1, 2, 3}); alpha != 0 || beta != 0 {
+		t.Errorf("Expected zero alpha/beta for a single overlapping point, got %.2f/%.2f", alpha, beta)
+	}
+}
+
+func TestAlignBenchmarkReturns_MatchesHoldingPeriodsByDate(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	bar := func(d int, close float64) types.Bar {
+		return types.Bar{Timestamp: day(d).Format(time.RFC3339), Close: close}
+	}
+
+	// latest-first like GetAlpacaBars, with a gap over the weekend of the 9th/10th
+	bars := []types.Bar{bar(12, 110), bar(11, 105), bar(8, 102), bar(7, 100), bar(6, 98)}
+
+	trades := []TradeResult{
+		{Symbol: "A", ReturnPercent: 4, EntryTime: day(7).Add(15 * time.Hour), ExitTime: day(12).Add(15 * time.Hour)},
+		{Symbol: "B", ReturnPercent: 1, EntryTime: day(8).Add(10 * time.Hour), ExitTime: day(10).Add(10 * time.Hour)},
+		{Symbol: "C", ReturnPercent: 9, EntryTime: day(1), ExitTime: day(7)}, // before the first bar
+		{Symbol: "D", ReturnPercent: 3}, // no timestamps
+	}
+
+	tradeReturns, benchmarkReturns := AlignBenchmarkReturns(trades, bars)
+	if len(tradeReturns) != 2 || len(benchmarkReturns) != 2 {
+		t.Fatalf("Expected 2 aligned trades, got %d/%d", len(tradeReturns), len(benchmarkReturns))
+	}
+	if tradeReturns[0] != 4 || math.Abs(benchmarkReturns[0]-10) > 1e-9 {
+		t.Errorf("Trade A aligned to %.2f vs %.2f, want 4 vs 10", tradeReturns[0], benchmarkReturns[0])
+	}
+	// exiting on a weekend uses the last close before it
+	if tradeReturns[1] != 1 || benchmarkReturns[1] != 0 {
+		t.Errorf("Trade B aligned to %.2f vs %.2f, want 1 vs 0", tradeReturns[1], benchmarkReturns[1])
+	}
+}
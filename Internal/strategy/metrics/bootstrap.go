@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	// fewest trades worth resampling; below this the intervals say more about the sample than the edge
+	MinBootstrapTrades  = 10
+	MaxBootstrapSamples = 10000
+)
+
+// a metric's value on the actual trades next to the mean and 5th/95th percentiles of its resampled values
+type MetricInterval struct {
+	Estimate float64 `json:"estimate"`
+	Mean     float64 `json:"mean"`
+	P5       float64 `json:"p5"`
+	P95      float64 `json:"p95"`
+}
+
+// confidence intervals from resampling the trades with replacement; Skipped is set with a Note when there were
+// too few trades to resample
+type BootstrapResult struct {
+	Samples     int            `json:"samples"`
+	Trades      int            `json:"trades"`
+	Skipped     bool           `json:"skipped"`
+	Note        string         `json:"note,omitempty"`
+	TotalReturn MetricInterval `json:"total_return_pct"`
+	Sharpe      MetricInterval `json:"sharpe_ratio"`
+	WinRate     MetricInterval `json:"win_rate"`
+}
+
+// total return on capital (percent), per-trade Sharpe and win rate for one set of trades
+func bootstrapStats(trades []TradeResult, capital float64) (totalReturn, sharpe, winRate float64) {
+	pnl := 0.0
+	for _, trade := range trades {
+		pnl += trade.PnL
+	}
+	if capital > 0 {
+		totalReturn = pnl / capital * 100
+	}
+	return totalReturn, CalculateSharpeRatio(trades, 0), CalculateWinRate(trades)
+}
+
+// resamples the trades samples times to put intervals on total return, Sharpe and win rate; a nil rng uses a
+// time-seeded source
+func BootstrapMetrics(trades []TradeResult, capital float64, samples int, rng *rand.Rand) (BootstrapResult, error) {
+	if samples <= 0 || samples > MaxBootstrapSamples {
+		return BootstrapResult{}, fmt.Errorf("bootstrap samples must be between 1 and %d, got %d", MaxBootstrapSamples, samples)
+	}
+	result := BootstrapResult{Samples: samples, Trades: len(trades)}
+	result.TotalReturn.Estimate, result.Sharpe.Estimate, result.WinRate.Estimate = bootstrapStats(trades, capital)
+	if len(trades) < MinBootstrapTrades {
+		result.Skipped = true
+		result.Note = fmt.Sprintf("bootstrap needs at least %d trades, got %d", MinBootstrapTrades, len(trades))
+		return result, nil
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+
+	returns := make([]float64, samples)
+	sharpes := make([]float64, samples)
+	winRates := make([]float64, samples)
+	resample := make([]TradeResult, len(trades))
+	for s := 0; s < samples; s++ {
+		for i := range resample {
+			resample[i] = trades[rng.Intn(len(trades))]
+		}
+		returns[s], sharpes[s], winRates[s] = bootstrapStats(resample, capital)
+	}
+
+	result.TotalReturn = summarizeBootstrap(result.TotalReturn.Estimate, returns)
+	result.Sharpe = summarizeBootstrap(result.Sharpe.Estimate, sharpes)
+	result.WinRate = summarizeBootstrap(result.WinRate.Estimate, winRates)
+	return result, nil
+}
+
+func summarizeBootstrap(estimate float64, values []float64) MetricInterval {
+	sort.Float64s(values)
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	return MetricInterval{Estimate: estimate, Mean: mean, P5: percentile(values, 5), P95: percentile(values, 95)}
+}
+
+// linearly interpolated percentile p (0-100) of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func bootstrapTrades(pnls ...float64) []TradeResult {
+	trades := make([]TradeResult, len(pnls))
+	for i, pnl := range pnls {
+		trades[i] = TradeResult{Symbol: "AAPL", EntryPrice: 100, Quantity: 10, PnL: pnl, ReturnPercent: pnl / 10}
+	}
+	return trades
+}
+
+func TestBootstrapMetrics_IntervalsBracketEstimate(t *testing.T) {
+	trades := bootstrapTrades(120, -40, 75, 30, -90, 210, 15, -25, 60, 95, -10, 45)
+
+	result, err := BootstrapMetrics(trades, 10000, 1000, rand.New(rand.NewSource(7)))
+	if err != nil {
+		t.Fatalf("BootstrapMetrics: %v", err)
+	}
+	if result.Skipped || result.Samples != 1000 || result.Trades != len(trades) {
+		t.Fatalf("result = %+v, want 1000 samples over %d trades", result, len(trades))
+	}
+
+	for name, interval := range map[string]MetricInterval{
+		"total return": result.TotalReturn,
+		"sharpe":       result.Sharpe,
+		"win rate":     result.WinRate,
+	} {
+		if interval.P5 > interval.Estimate || interval.P95 < interval.Estimate {
+			t.Errorf("%s: [%.3f, %.3f] does not bracket %.3f", name, interval.P5, interval.P95, interval.Estimate)
+		}
+		if interval.P5 >= interval.P95 {
+			t.Errorf("%s: empty interval [%.3f, %.3f]", name, interval.P5, interval.P95)
+		}
+		if interval.Mean < interval.P5 || interval.Mean > interval.P95 {
+			t.Errorf("%s: mean %.3f outside [%.3f, %.3f]", name, interval.Mean, interval.P5, interval.P95)
+		}
+	}
+	if want := 485.0 / 10000 * 100; math.Abs(result.TotalReturn.Estimate-want) > 1e-9 {
+		t.Errorf("total return estimate = %.3f, want %.3f", result.TotalReturn.Estimate, want)
+	}
+}
+
+func TestBootstrapMetrics_TooFewTradesSkips(t *testing.T) {
+	result, err := BootstrapMetrics(bootstrapTrades(50, -20, 30), 10000, 1000, nil)
+	if err != nil {
+		t.Fatalf("BootstrapMetrics: %v", err)
+	}
+	if !result.Skipped || result.Note == "" {
+		t.Errorf("Expected a skipped bootstrap with a note, got %+v", result)
+	}
+	if result.WinRate.Estimate == 0 || result.WinRate.P5 != 0 {
+		t.Errorf("Skipped bootstrap should keep the estimate and leave the interval empty, got %+v", result.WinRate)
+	}
+}
+
+func TestBootstrapMetrics_RejectsSampleCount(t *testing.T) {
+	for _, samples := range []int{0, MaxBootstrapSamples + 1} {
+		if _, err := BootstrapMetrics(bootstrapTrades(1, 2), 1000, samples, nil); err == nil {
+			t.Errorf("Expected an error for %d samples", samples)
+		}
+	}
+}
+
+func TestPercentile_Interpolates(t *testing.T) {
+	sorted := []float64{0, 10, 20, 30, 40}
+	if got := percentile(sorted, 50); got != 20 {
+		t.Errorf("p50 = %.2f, want 20", got)
+	}
+	if got := percentile(sorted, 5); got != 2 {
+		t.Errorf("p5 = %.2f, want 2", got)
+	}
+}
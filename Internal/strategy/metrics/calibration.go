@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/signals"
+)
+
+const (
+	DefaultCalibrationBucketSize  = 10.0
+	DefaultCalibrationMatchWindow = 48 * time.Hour
+)
+
+// predicted confidence of a signal paired with whether the trade it led to was profitable
+type SignalOutcome struct {
+	SignalID   int32
+	Symbol     string
+	Confidence float64
+	Win        bool
+}
+
+// one bin of the reliability diagram
+type CalibrationBucket struct {
+	Range          string  `json:"range"`
+	MinConfidence  float64 `json:"min_confidence"`
+	MaxConfidence  float64 `json:"max_confidence"`
+	Signals        int     `json:"signals"`
+	Wins           int     `json:"wins"`
+	AvgConfidence  float64 `json:"avg_confidence"`
+	WinRate        float64 `json:"win_rate"`
+	CalibrationGap float64 `json:"calibration_gap"` // win rate minus avg confidence, negative means overconfident
+}
+
+type CalibrationReport struct {
+	BucketSize               float64             `json:"bucket_size"`
+	MatchedSignals           int                 `json:"matched_signals"`
+	Buckets                  []CalibrationBucket `json:"buckets"`
+	ExpectedCalibrationError float64             `json:"expected_calibration_error"`
+	BrierScore               float64             `json:"brier_score"`
+}
+
+// pairs each closed long trade with the latest unused bullish signal for the symbol computed within window before entry
+func MatchSignalOutcomes(records []signals.SignalRecord, trades []TradeResult, window time.Duration) []SignalOutcome {
+	if window <= 0 {
+		window = DefaultCalibrationMatchWindow
+	}
+
+	bySymbol := make(map[string][]signals.SignalRecord)
+	for _, record := range records {
+		if record.Recommendation != signals.RecommendationBuy && record.Recommendation != signals.RecommendationAccumulate {
+			continue
+		}
+		bySymbol[record.Symbol] = append(bySymbol[record.Symbol], record)
+	}
+	for symbol := range bySymbol {
+		list := bySymbol[symbol]
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].ComputedAt.Before(list[j].ComputedAt)
+		})
+	}
+
+	sortedTrades := make([]TradeResult, len(trades))
+	copy(sortedTrades, trades)
+	sort.SliceStable(sortedTrades, func(i, j int) bool {
+		return sortedTrades[i].EntryTime.Before(sortedTrades[j].EntryTime)
+	})
+
+	used := make(map[int32]bool)
+	var outcomes []SignalOutcome
+	for _, trade := range sortedTrades {
+		candidates := bySymbol[trade.Symbol]
+		for i := len(candidates) - 1; i >= 0; i-- {
+			record := candidates[i]
+			if record.ComputedAt.After(trade.EntryTime) {
+				continue
+			}
+			if trade.EntryTime.Sub(record.ComputedAt) > window {
+				break
+			}
+			if used[record.ID] {
+				continue
+			}
+			used[record.ID] = true
+			outcomes = append(outcomes, SignalOutcome{
+				SignalID:   record.ID,
+				Symbol:     record.Symbol,
+				Confidence: record.Confidence,
+				Win:        trade.PnL > 0,
+			})
+			break
+		}
+	}
+
+	return outcomes
+}
+
+// buckets outcomes by predicted confidence (0-100) and compares each bucket to its realized win rate
+func BuildCalibrationReport(outcomes []SignalOutcome, bucketSize float64) CalibrationReport {
+	if bucketSize <= 0 || bucketSize > 100 {
+		bucketSize = DefaultCalibrationBucketSize
+	}
+
+	report := CalibrationReport{
+		BucketSize:     bucketSize,
+		MatchedSignals: len(outcomes),
+		Buckets:        []CalibrationBucket{},
+	}
+	if len(outcomes) == 0 {
+		return report
+	}
+
+	bucketCount := int(math.Ceil(100 / bucketSize))
+	confidenceSums := make([]float64, bucketCount)
+	buckets := make([]CalibrationBucket, bucketCount)
+	brier := 0.0
+
+	for _, outcome := range outcomes {
+		confidence := math.Max(0, math.Min(100, outcome.Confidence))
+		idx := int(confidence / bucketSize)
+		if idx >= bucketCount {
+			idx = bucketCount - 1
+		}
+
+		buckets[idx].Signals++
+		confidenceSums[idx] += confidence
+		actual := 0.0
+		if outcome.Win {
+			buckets[idx].Wins++
+			actual = 1.0
+		}
+		brier += math.Pow(confidence/100-actual, 2)
+	}
+
+	for i := range buckets {
+		if buckets[i].Signals == 0 {
+			continue
+		}
+		bucket := buckets[i]
+		bucket.MinConfidence = float64(i) * bucketSize
+		bucket.MaxConfidence = math.Min(100, float64(i+1)*bucketSize)
+		bucket.Range = fmt.Sprintf("%.0f-%.0f%%", bucket.MinConfidence, bucket.MaxConfidence)
+		bucket.AvgConfidence = confidenceSums[i] / float64(bucket.Signals)
+		bucket.WinRate = float64(bucket.Wins) / float64(bucket.Signals) * 100
+		bucket.CalibrationGap = bucket.WinRate - bucket.AvgConfidence
+
+		report.ExpectedCalibrationError += float64(bucket.Signals) / float64(len(outcomes)) * math.Abs(bucket.CalibrationGap)
+		report.Buckets = append(report.Buckets, bucket)
+	}
+	report.BrierScore = brier / float64(len(outcomes))
+
+	return report
+}
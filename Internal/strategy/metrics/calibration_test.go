@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/signals"
+)
+
+func TestBuildCalibrationReport_ExpectedTable(t *testing.T) {
+	var outcomes []SignalOutcome
+	// 85% bucket: 10 signals, 8 wins
+	for i := 0; i < 10; i++ {
+		outcomes = append(outcomes, SignalOutcome{Confidence: 85, Win: i < 8})
+	}
+	// 55% bucket: 4 signals, 1 win
+	for i := 0; i < 4; i++ {
+		outcomes = append(outcomes, SignalOutcome{Confidence: 55, Win: i == 0})
+	}
+	// a 100% signal must land in the top bucket rather than overflow
+	outcomes = append(outcomes, SignalOutcome{Confidence: 100, Win: true})
+
+	report := BuildCalibrationReport(outcomes, 10)
+
+	if report.MatchedSignals != 15 {
+		t.Fatalf("MatchedSignals = %d, want 15", report.MatchedSignals)
+	}
+
+	expected := []CalibrationBucket{
+		{Range: "50-60%", MinConfidence: 50, MaxConfidence: 60, Signals: 4, Wins: 1, AvgConfidence: 55, WinRate: 25, CalibrationGap: -30},
+		{Range: "80-90%", MinConfidence: 80, MaxConfidence: 90, Signals: 10, Wins: 8, AvgConfidence: 85, WinRate: 80, CalibrationGap: -5},
+		{Range: "90-100%", MinConfidence: 90, MaxConfidence: 100, Signals: 1, Wins: 1, AvgConfidence: 100, WinRate: 100, CalibrationGap: 0},
+	}
+	if len(report.Buckets) != len(expected) {
+		t.Fatalf("Expected %d buckets, got %d: %+v", len(expected), len(report.Buckets), report.Buckets)
+	}
+	for i, want := range expected {
+		if report.Buckets[i] != want {
+			t.Errorf("bucket %d = %+v, want %+v", i, report.Buckets[i], want)
+		}
+	}
+
+	wantECE := (4.0*30 + 10.0*5) / 15
+	if math.Abs(report.ExpectedCalibrationError-wantECE) > 1e-9 {
+		t.Errorf("ExpectedCalibrationError = %f, want %f", report.ExpectedCalibrationError, wantECE)
+	}
+}
+
+func TestBuildCalibrationReport_Empty(t *testing.T) {
+	report := BuildCalibrationReport(nil, 0)
+	if report.BucketSize != DefaultCalibrationBucketSize {
+		t.Errorf("BucketSize = %f, want default %f", report.BucketSize, DefaultCalibrationBucketSize)
+	}
+	if len(report.Buckets) != 0 || report.BrierScore != 0 {
+		t.Errorf("Expected empty report, got %+v", report)
+	}
+}
+
+func TestMatchSignalOutcomes_LatestSignalWithinWindow(t *testing.T) {
+	base := time.Date(2024, 3, 1, 14, 0, 0, 0, time.UTC)
+	records := []signals.SignalRecord{
+		{ID: 1, Symbol: "AAPL", Recommendation: signals.RecommendationBuy, Confidence: 60, ComputedAt: base.Add(-10 * time.Hour)},
+		{ID: 2, Symbol: "AAPL", Recommendation: signals.RecommendationBuy, Confidence: 80, ComputedAt: base.Add(-1 * time.Hour)},
+		{ID: 3, Symbol: "AAPL", Recommendation: signals.RecommendationSell, Confidence: 90, ComputedAt: base.Add(-30 * time.Minute)},
+		{ID: 4, Symbol: "MSFT", Recommendation: signals.RecommendationBuy, Confidence: 70, ComputedAt: base.Add(-72 * time.Hour)},
+		{ID: 5, Symbol: "TSLA", Recommendation: signals.RecommendationAccumulate, Confidence: 65, ComputedAt: base.Add(time.Hour)},
+	}
+	trades := []TradeResult{
+		{Symbol: "AAPL", EntryTime: base, PnL: 12.5},
+		{Symbol: "AAPL", EntryTime: base.Add(2 * time.Hour), PnL: -3},
+		{Symbol: "MSFT", EntryTime: base, PnL: 5}, // signal is outside the window
+		{Symbol: "TSLA", EntryTime: base, PnL: 5}, // signal came after the entry
+	}
+
+	outcomes := MatchSignalOutcomes(records, trades, 24*time.Hour)
+
+	want := []SignalOutcome{
+		{SignalID: 2, Symbol: "AAPL", Confidence: 80, Win: true},
+		{SignalID: 1, Symbol: "AAPL", Confidence: 60, Win: false},
+	}
+	if len(outcomes) != len(want) {
+		t.Fatalf("Expected %d outcomes, got %d: %+v", len(want), len(outcomes), outcomes)
+	}
+	for i := range want {
+		if outcomes[i] != want[i] {
+			t.Errorf("outcome %d = %+v, want %+v", i, outcomes[i], want[i])
+		}
+	}
+}
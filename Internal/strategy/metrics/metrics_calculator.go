@@ -2,9 +2,11 @@ package metrics
 
 import (
 	"math"
+	"sort"
 	"time"
 
 	"github.com/fazecat/mogulmaker/Internal/utils"
+	"github.com/shopspring/decimal"
 )
 
 type SymbolStats struct {
@@ -12,6 +14,11 @@ type SymbolStats struct {
 	TotalTrades  int
 	Wins         int
 	Losses       int
+	WinRate      float64
+	NetPnL       float64
+	LargestWin   float64
+	LargestLoss  float64
+	AvgHold      time.Duration
 	SharpeRatio  float64
 	SortinoRatio float64
 	CalmarRatio  float64
@@ -19,11 +26,13 @@ type SymbolStats struct {
 
 type TradeResult struct {
 	Symbol        string
+	Direction     string // LONG or SHORT
 	EntryPrice    float64
 	ExitPrice     float64
 	Quantity      float64
 	PnL           float64
 	ReturnPercent float64
+	Fees          float64 // commissions taken out of PnL
 	Duration      time.Duration
 	EntryTime     time.Time
 	ExitTime      time.Time
@@ -67,18 +76,83 @@ func CalculateSortinoRatio(trades []TradeResult, riskFreeRate float64) float64 {
 	return (avgReturn - riskFreeRate) / downsideDev
 }
 
-func CalculateCalmarRatio(trades []TradeResult, annualReturn float64, maxDrawdown float64) float64 {
-	if maxDrawdown == 0 {
+// annualized return over max drawdown of the equity curve from compounding each trade's ReturnPercent in exit
+// order, annualized over first entry to last exit. 0 when nothing was drawn down (like Sharpe/Sortino with no
+// deviation, and +Inf wouldn't encode to JSON) or the trades span no time
+func CalculateCalmarRatio(trades []TradeResult) float64 {
+	curve := tradeEquityCurve(trades)
+	if len(curve) < 2 {
 		return 0.0
 	}
-	var returns []float64
-	for _, trade := range trades {
-		returns = append(returns, trade.ReturnPercent)
+	maxDrawdown := 0.0
+	peak := curve[0].equity
+	for _, point := range curve {
+		peak = math.Max(peak, point.equity)
+		maxDrawdown = math.Max(maxDrawdown, (peak-point.equity)/peak)
 	}
-
+	years := curve[len(curve)-1].at.Sub(curve[0].at).Hours() / (24 * 365.25)
+	final := curve[len(curve)-1].equity
+	if maxDrawdown == 0 || years <= 0 || final <= 0 {
+		return 0.0
+	}
+	annualReturn := math.Pow(final, 1/years) - 1
 	return annualReturn / maxDrawdown
 }
 
+// longest stretch the trade equity curve spent below a prior peak, from the exit that set the peak to the exit
+// that recovered it (or the last exit while still under water); 0 when it never dipped
+func CalculateMaxDrawdownDuration(trades []TradeResult) time.Duration {
+	curve := tradeEquityCurve(trades)
+	if len(curve) < 2 {
+		return 0
+	}
+	var longest time.Duration
+	peak := curve[0]
+	underwater := false
+	for _, point := range curve[1:] {
+		if point.equity < peak.equity {
+			underwater = true
+			longest = max(longest, point.at.Sub(peak.at))
+			continue
+		}
+		if underwater {
+			longest = max(longest, point.at.Sub(peak.at))
+			underwater = false
+		}
+		peak = point
+	}
+	return longest
+}
+
+type equityAt struct {
+	at     time.Time
+	equity float64
+}
+
+// growth of 1 unit compounded through the trades by exit time, starting at the first entry
+func tradeEquityCurve(trades []TradeResult) []equityAt {
+	if len(trades) == 0 {
+		return nil
+	}
+	ordered := make([]TradeResult, len(trades))
+	copy(ordered, trades)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].ExitTime.Before(ordered[j].ExitTime) })
+
+	start := ordered[0].EntryTime
+	for _, trade := range ordered {
+		if !trade.EntryTime.IsZero() && trade.EntryTime.Before(start) {
+			start = trade.EntryTime
+		}
+	}
+	curve := []equityAt{{at: start, equity: 1}}
+	equity := 1.0
+	for _, trade := range ordered {
+		equity *= 1 + trade.ReturnPercent/100
+		curve = append(curve, equityAt{at: trade.ExitTime, equity: equity})
+	}
+	return curve
+}
+
 func CalculateSymbolStats(trades []TradeResult) map[string]*SymbolStats {
 
 	Trademap := make(map[string][]TradeResult)
@@ -91,17 +165,25 @@ func CalculateSymbolStats(trades []TradeResult) map[string]*SymbolStats {
 	for symbol, tradesForSymbol := range Trademap {
 		wins := 0
 		losses := 0
+		netPnL := decimal.Zero
+		largestWin := 0.0
+		largestLoss := 0.0
+		var totalHold time.Duration
 		for _, trade := range tradesForSymbol {
 			if trade.PnL > 0 {
 				wins++
 			} else if trade.PnL < 0 {
 				losses++
 			}
+			netPnL = netPnL.Add(decimal.NewFromFloat(trade.PnL))
+			largestWin = math.Max(largestWin, trade.PnL)
+			largestLoss = math.Min(largestLoss, trade.PnL)
+			totalHold += trade.Duration
 		}
 		// 2% risk-free rate assumed
 		Sharpe := CalculateSharpeRatio(tradesForSymbol, 0.02)
 		Sortino := CalculateSortinoRatio(tradesForSymbol, 0.02)
-		Calmar := CalculateCalmarRatio(tradesForSymbol, Sharpe*0.02, 0.1)
+		Calmar := CalculateCalmarRatio(tradesForSymbol)
 		symbolStats := &SymbolStats{
 			Symbol:       symbol,
 			TotalTrades:  len(tradesForSymbol),
@@ -110,6 +192,11 @@ func CalculateSymbolStats(trades []TradeResult) map[string]*SymbolStats {
 			CalmarRatio:  Calmar,
 			Wins:         wins,
 			Losses:       losses,
+			WinRate:      float64(wins) / float64(len(tradesForSymbol)) * 100,
+			NetPnL:       utils.MoneyToFloat(netPnL),
+			LargestWin:   largestWin,
+			LargestLoss:  largestLoss,
+			AvgHold:      totalHold / time.Duration(len(tradesForSymbol)),
 		}
 		Results[symbol] = symbolStats
 
@@ -117,6 +204,24 @@ func CalculateSymbolStats(trades []TradeResult) map[string]*SymbolStats {
 	return Results
 }
 
+// orders per-symbol stats by net P&L (best first), dropping symbols with fewer than minTrades closed trades
+func RankSymbolStats(stats map[string]*SymbolStats, minTrades int) []*SymbolStats {
+	ranked := make([]*SymbolStats, 0, len(stats))
+	for _, stat := range stats {
+		if stat.TotalTrades < minTrades {
+			continue
+		}
+		ranked = append(ranked, stat)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].NetPnL != ranked[j].NetPnL {
+			return ranked[i].NetPnL > ranked[j].NetPnL
+		}
+		return ranked[i].Symbol < ranked[j].Symbol
+	})
+	return ranked
+}
+
 func calculateStandardDeviation(values []float64) float64 {
 	if len(values) == 0 {
 		return 0.0
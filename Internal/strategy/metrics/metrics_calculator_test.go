@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func dayTrade(entry, exit time.Time, returnPercent float64) TradeResult {
+	return TradeResult{Symbol: "AAA", EntryTime: entry, ExitTime: exit, ReturnPercent: returnPercent}
+}
+
+func TestCalmarAndDrawdownDuration_KnownCurve(t *testing.T) {
+	day := func(month time.Month, d int, year int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	// equity 1 -> 2 -> 1 -> 2 -> 3, listed out of exit order
+	trades := []TradeResult{
+		dayTrade(day(time.June, 1, 2024), day(time.September, 1, 2024), 100),
+		dayTrade(day(time.January, 1, 2024), day(time.April, 1, 2024), 100),
+		dayTrade(day(time.September, 1, 2024), day(time.January, 1, 2025), 50),
+		dayTrade(day(time.April, 1, 2024), day(time.June, 1, 2024), -50),
+	}
+
+	years := day(time.January, 1, 2025).Sub(day(time.January, 1, 2024)).Hours() / (24 * 365.25)
+	want := (math.Pow(3, 1/years) - 1) / 0.5
+	if got := CalculateCalmarRatio(trades); math.Abs(got-want) > 1e-9 {
+		t.Errorf("CalculateCalmarRatio() = %v, want %v", got, want)
+	}
+
+	// under water from the April peak until September's recovery
+	if got, want := CalculateMaxDrawdownDuration(trades), day(time.September, 1, 2024).Sub(day(time.April, 1, 2024)); got != want {
+		t.Errorf("CalculateMaxDrawdownDuration() = %v, want %v", got, want)
+	}
+}
+
+func TestCalmarAndDrawdownDuration_NoDrawdown(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	trades := []TradeResult{
+		dayTrade(start, start.AddDate(0, 1, 0), 5),
+		dayTrade(start.AddDate(0, 1, 0), start.AddDate(0, 6, 0), 10),
+	}
+	if got := CalculateCalmarRatio(trades); got != 0 {
+		t.Errorf("CalculateCalmarRatio() = %v without a drawdown, want 0", got)
+	}
+	if got := CalculateMaxDrawdownDuration(trades); got != 0 {
+		t.Errorf("CalculateMaxDrawdownDuration() = %v without a drawdown, want 0", got)
+	}
+	if CalculateCalmarRatio(nil) != 0 || CalculateMaxDrawdownDuration(nil) != 0 {
+		t.Errorf("Expected 0 for no trades")
+	}
+}
+
+func TestCalculateMaxDrawdownDuration_StillUnderWater(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	trades := []TradeResult{
+		dayTrade(start, start.AddDate(0, 0, 10), 20),
+		dayTrade(start.AddDate(0, 0, 10), start.AddDate(0, 0, 20), -10),
+		dayTrade(start.AddDate(0, 0, 20), start.AddDate(0, 0, 45), 5),
+	}
+	// 1.2 -> 1.08 -> 1.134 never gets back to the day-10 peak
+	if got, want := CalculateMaxDrawdownDuration(trades), 35*24*time.Hour; got != want {
+		t.Errorf("CalculateMaxDrawdownDuration() = %v, want %v", got, want)
+	}
+	if got := CalculateCalmarRatio(trades); got <= 0 {
+		t.Errorf("CalculateCalmarRatio() = %v, want positive for a net gain", got)
+	}
+}
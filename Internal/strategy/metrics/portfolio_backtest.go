@@ -0,0 +1,244 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+type EquityPoint struct {
+	Date          string
+	Equity        float64
+	Cash          float64
+	OpenPositions int
+}
+
+type PortfolioBacktestResult struct {
+	Symbols                []string
+	StartingCapital        float64
+	FinalEquity            float64
+	TotalReturnPercent     float64
+	MaxDrawdownPercent     float64
+	SharpeRatio            float64
+	WinRate                float64
+	MaxConcurrentPositions int
+	SkippedEntries         int // entries rejected by position limits or lack of cash
+	Trades                 []TradeResult
+	EquityCurve            []EquityPoint
+}
+
+// one symbol's bars keyed by date with precomputed RSI
+type portfolioSeries struct {
+	closes map[string]float64
+	rsi    map[string]float64
+}
+
+// runs the RSI strategy across a basket of symbols sharing one capital pool
+func RunPortfolioBacktest(symbols []string, barsBySymbol map[string][]types.Bar, startingCapital float64, cfg *strategy.OrderConfig) (*PortfolioBacktestResult, error) {
+	if startingCapital <= 0 {
+		return nil, fmt.Errorf("starting capital must be positive")
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("order config is required")
+	}
+
+	result := &PortfolioBacktestResult{
+		Symbols:         symbols,
+		StartingCapital: startingCapital,
+		FinalEquity:     startingCapital,
+	}
+
+	series := make(map[string]*portfolioSeries)
+	dateSet := make(map[string]bool)
+	for _, symbol := range symbols {
+		bars := barsBySymbol[symbol]
+		if len(bars) < 15 {
+			continue
+		}
+
+		closes := make([]float64, len(bars))
+		for i, bar := range bars {
+			closes[i] = bar.Close
+		}
+		rsiValues, err := indicators.CalculateRSI(closes, 14)
+		if err != nil {
+			continue
+		}
+
+		s := &portfolioSeries{
+			closes: make(map[string]float64),
+			rsi:    make(map[string]float64),
+		}
+		for i, bar := range bars {
+			date := barDateString(bar.Timestamp)
+			s.closes[date] = bar.Close
+			if i >= 14 {
+				s.rsi[date] = rsiValues[i]
+			}
+			dateSet[date] = true
+		}
+		series[symbol] = s
+	}
+
+	if len(series) == 0 {
+		return result, nil
+	}
+
+	dates := make([]string, 0, len(dateSet))
+	for date := range dateSet {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	cash := startingCapital
+	positions := make(map[string]*Position)
+	lastClose := make(map[string]float64)
+
+	for _, date := range dates {
+		for symbol, s := range series {
+			if price, ok := s.closes[date]; ok {
+				lastClose[symbol] = price
+			}
+		}
+
+		// exits first so freed capital is available for new entries on the same bar
+		for _, symbol := range symbols {
+			pos, open := positions[symbol]
+			if !open {
+				continue
+			}
+			rsi, ok := series[symbol].rsi[date]
+			if !ok || rsi <= 70 {
+				continue
+			}
+			exitPrice := series[symbol].closes[date]
+			cash += exitPrice * pos.Quantity
+			result.Trades = append(result.Trades, createTradeResult(symbol, *pos, exitPrice, date, 0))
+			delete(positions, symbol)
+		}
+
+		for _, symbol := range symbols {
+			s, ok := series[symbol]
+			if !ok {
+				continue
+			}
+			if _, open := positions[symbol]; open {
+				continue
+			}
+			rsi, ok := s.rsi[date]
+			if !ok || rsi >= 30 {
+				continue
+			}
+
+			if len(positions) >= cfg.MaxOpenPositions {
+				result.SkippedEntries++
+				continue
+			}
+
+			equity := markToMarket(cash, positions, lastClose)
+			allocation := equity * cfg.ForAsset(symbol, "").MaxPortfolioPercent / 100
+			if allocation > cash {
+				allocation = cash
+			}
+			entryPrice := s.closes[date]
+			if allocation <= 0 || entryPrice <= 0 {
+				result.SkippedEntries++
+				continue
+			}
+
+			entryTime, _ := time.Parse("2006-01-02", date)
+			quantity := allocation / entryPrice
+			cash -= allocation
+			positions[symbol] = &Position{
+				Symbol:     symbol,
+				InTrade:    true,
+				Direction:  "LONG",
+				EntryPrice: entryPrice,
+				Quantity:   quantity,
+				EntryTime:  entryTime,
+				EntryDate:  date,
+			}
+		}
+
+		if len(positions) > result.MaxConcurrentPositions {
+			result.MaxConcurrentPositions = len(positions)
+		}
+
+		result.EquityCurve = append(result.EquityCurve, EquityPoint{
+			Date:          date,
+			Equity:        markToMarket(cash, positions, lastClose),
+			Cash:          cash,
+			OpenPositions: len(positions),
+		})
+	}
+
+	// close anything still open at the last known price
+	lastDate := dates[len(dates)-1]
+	for _, symbol := range symbols {
+		pos, open := positions[symbol]
+		if !open {
+			continue
+		}
+		exitPrice := lastClose[symbol]
+		cash += exitPrice * pos.Quantity
+		result.Trades = append(result.Trades, createTradeResult(symbol, *pos, exitPrice, lastDate, 0))
+		delete(positions, symbol)
+	}
+
+	result.FinalEquity = cash
+	result.TotalReturnPercent = ((cash - startingCapital) / startingCapital) * 100
+	result.WinRate = CalculateWinRate(result.Trades)
+	result.MaxDrawdownPercent = calculateEquityDrawdown(result.EquityCurve)
+	result.SharpeRatio = CalculateSharpeFromReturns(equityReturns(result.EquityCurve))
+
+	return result, nil
+}
+
+func markToMarket(cash float64, positions map[string]*Position, lastClose map[string]float64) float64 {
+	equity := cash
+	for symbol, pos := range positions {
+		equity += lastClose[symbol] * pos.Quantity
+	}
+	return equity
+}
+
+// returns the largest peak-to-trough decline in percent
+func calculateEquityDrawdown(curve []EquityPoint) float64 {
+	peak := 0.0
+	maxDrawdown := 0.0
+	for _, point := range curve {
+		if point.Equity > peak {
+			peak = point.Equity
+		}
+		if peak > 0 {
+			drawdown := (peak - point.Equity) / peak * 100
+			if drawdown > maxDrawdown {
+				maxDrawdown = drawdown
+			}
+		}
+	}
+	return maxDrawdown
+}
+
+func equityReturns(curve []EquityPoint) []float64 {
+	returns := []float64{}
+	for i := 1; i < len(curve); i++ {
+		prev := curve[i-1].Equity
+		if prev == 0 {
+			continue
+		}
+		returns = append(returns, (curve[i].Equity-prev)/prev)
+	}
+	return returns
+}
+
+func barDateString(timestamp string) string {
+	if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
+		return t.Format("2006-01-02")
+	}
+	return timestamp
+}
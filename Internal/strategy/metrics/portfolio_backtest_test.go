@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// builds bars that fall long enough to go oversold and then rally to overbought
+func buildOversoldThenRallyBars(start float64) []types.Bar {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	closes := []float64{}
+	price := start
+	for i := 0; i < 16; i++ {
+		closes = append(closes, price)
+		price -= 1
+	}
+	for i := 0; i < 20; i++ {
+		price += 2
+		closes = append(closes, price)
+	}
+
+	bars := make([]types.Bar, len(closes))
+	for i, c := range closes {
+		bars[i] = types.Bar{
+			Timestamp: base.AddDate(0, 0, i).Format(time.RFC3339),
+			Open:      c,
+			High:      c + 0.5,
+			Low:       c - 0.5,
+			Close:     c,
+			Volume:    1000,
+		}
+	}
+	return bars
+}
+
+func TestRunPortfolioBacktest_SharesCapital(t *testing.T) {
+	symbols := []string{"AAA", "BBB", "CCC"}
+	barsBySymbol := map[string][]types.Bar{
+		"AAA": buildOversoldThenRallyBars(100),
+		"BBB": buildOversoldThenRallyBars(100),
+		"CCC": buildOversoldThenRallyBars(100),
+	}
+	cfg := &strategy.OrderConfig{
+		MaxOpenPositions:    5,
+		MaxPortfolioPercent: 50.0,
+	}
+
+	result, err := RunPortfolioBacktest(symbols, barsBySymbol, 10000, cfg)
+	if err != nil {
+		t.Fatalf("RunPortfolioBacktest() error = %v", err)
+	}
+
+	// two 50% allocations exhaust the pool so the third symbol cannot enter
+	if len(result.Trades) != 2 {
+		t.Fatalf("Expected 2 trades with shared capital, got %d", len(result.Trades))
+	}
+	if result.SkippedEntries == 0 {
+		t.Errorf("Expected third entry to be skipped for lack of cash")
+	}
+
+	invested := 0.0
+	for _, trade := range result.Trades {
+		invested += trade.EntryPrice * trade.Quantity
+	}
+	if invested > 10000+0.01 {
+		t.Errorf("Invested %.2f exceeds starting capital 10000", invested)
+	}
+
+	for _, point := range result.EquityCurve {
+		if point.Cash < -0.01 {
+			t.Errorf("Cash went negative on %s: %.2f", point.Date, point.Cash)
+		}
+	}
+}
+
+func TestRunPortfolioBacktest_RespectsMaxOpenPositions(t *testing.T) {
+	symbols := []string{"AAA", "BBB", "CCC"}
+	barsBySymbol := map[string][]types.Bar{
+		"AAA": buildOversoldThenRallyBars(100),
+		"BBB": buildOversoldThenRallyBars(100),
+		"CCC": buildOversoldThenRallyBars(100),
+	}
+	cfg := &strategy.OrderConfig{
+		MaxOpenPositions:    1,
+		MaxPortfolioPercent: 20.0,
+	}
+
+	result, err := RunPortfolioBacktest(symbols, barsBySymbol, 10000, cfg)
+	if err != nil {
+		t.Fatalf("RunPortfolioBacktest() error = %v", err)
+	}
+
+	if result.MaxConcurrentPositions > 1 {
+		t.Errorf("MaxConcurrentPositions = %d, want <= 1", result.MaxConcurrentPositions)
+	}
+	for _, point := range result.EquityCurve {
+		if point.OpenPositions > 1 {
+			t.Errorf("Open positions on %s = %d, want <= 1", point.Date, point.OpenPositions)
+		}
+	}
+	if len(result.Trades) == 0 {
+		t.Errorf("Expected at least one trade")
+	}
+
+	// position sizing caps each entry at MaxPortfolioPercent of equity
+	for _, trade := range result.Trades {
+		notional := trade.EntryPrice * trade.Quantity
+		if notional > 10000*0.20+0.01 {
+			t.Errorf("Entry notional %.2f exceeds 20%% of capital", notional)
+		}
+	}
+}
+
+func TestRunPortfolioBacktest_InvalidInput(t *testing.T) {
+	if _, err := RunPortfolioBacktest([]string{"AAA"}, nil, 0, &strategy.OrderConfig{}); err == nil {
+		t.Errorf("Expected error for zero capital")
+	}
+	if _, err := RunPortfolioBacktest([]string{"AAA"}, nil, 1000, nil); err == nil {
+		t.Errorf("Expected error for nil config")
+	}
+}
@@ -3,11 +3,24 @@ package strategy
 import (
 	"fmt"
 	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
 	"github.com/shopspring/decimal"
 )
 
+// default auction submission windows (Eastern), used when config leaves them empty
+const (
+	defaultCloseCutoff    = "15:50"
+	defaultOpenCutoff     = "09:28"
+	defaultOpenAcceptFrom = "19:00"
+)
+
 type OrderConfig struct {
 	MaxPortfolioPercent   float64 //(default 20%)
 	MaxOpenPositions      int     //(default 5)
@@ -16,6 +29,103 @@ type OrderConfig struct {
 	SafeBailPercent       float64 //(default 3%)
 	MaxDailyLossPercent   float64 //(default -2%)
 	PartialExitPercentage float64 //(default 0.5 = 50%)
+
+	ScaleRiskByOpenPositions bool    // shrink the per-trade risk budget as positions stack up
+	MaxPortfolioRiskPercent  float64 // caps the combined risk of open positions plus the new one, from risk.Manager (0 = none)
+
+	BetaAdjust bool       // divide each trade's risk budget by the symbol's beta to the benchmark
+	MinBeta    float64    // floor on the beta used for that; a low-beta name's budget grows at most 1/MinBeta times
+	Betas      *BetaCache // shared beta lookups, set with BetaAdjust
+
+	AssetClass map[string]*OrderConfig // overrides keyed by utils.AssetTypeStock/AssetTypeCrypto, zero fields inherit
+}
+
+// guards the OrderConfigs shared by pointer between the API, the position manager and the order builder while
+// settings swap one with Replace
+var orderConfigMu sync.RWMutex
+
+// a copy of a shared config that a concurrent Replace can't tear; nil stays nil
+func (cfg *OrderConfig) Snapshot() *OrderConfig {
+	if cfg == nil {
+		return nil
+	}
+	orderConfigMu.RLock()
+	defer orderConfigMu.RUnlock()
+	copied := *cfg
+	return &copied
+}
+
+// swaps next in place so every holder of the pointer sees it, returning the config it replaced
+func (cfg *OrderConfig) Replace(next OrderConfig) OrderConfig {
+	orderConfigMu.Lock()
+	defer orderConfigMu.Unlock()
+	previous := *cfg
+	*cfg = next
+	return previous
+}
+
+// resolves the limits for a symbol's asset class, layering its override onto the global values
+func (cfg *OrderConfig) ForAsset(symbol, assetType string) *OrderConfig {
+	if cfg == nil {
+		return nil
+	}
+	override := cfg.AssetClass[utils.DetectAssetType(symbol, assetType)]
+	if override == nil {
+		return cfg
+	}
+
+	resolved := *cfg
+	resolved.AssetClass = nil
+	if override.MaxPortfolioPercent > 0 {
+		resolved.MaxPortfolioPercent = override.MaxPortfolioPercent
+	}
+	if override.MaxOpenPositions > 0 {
+		resolved.MaxOpenPositions = override.MaxOpenPositions
+	}
+	if override.StopLossPercent > 0 {
+		resolved.StopLossPercent = override.StopLossPercent
+	}
+	if override.TakeProfitPercent > 0 {
+		resolved.TakeProfitPercent = override.TakeProfitPercent
+	}
+	if override.SafeBailPercent > 0 {
+		resolved.SafeBailPercent = override.SafeBailPercent
+	}
+	if override.MaxDailyLossPercent < 0 {
+		resolved.MaxDailyLossPercent = override.MaxDailyLossPercent
+	}
+	if override.PartialExitPercentage > 0 {
+		resolved.PartialExitPercentage = override.PartialExitPercentage
+	}
+	return &resolved
+}
+
+// installs the equity/crypto overrides from config
+func (cfg *OrderConfig) SetAssetClassRisk(risk config.AssetClassRiskConfig) {
+	cfg.AssetClass = map[string]*OrderConfig{
+		utils.AssetTypeStock:  assetRiskOverride(risk.Equity),
+		utils.AssetTypeCrypto: assetRiskOverride(risk.Crypto),
+	}
+}
+
+// installs the open-position risk scaling and beta adjustment from config
+func (cfg *OrderConfig) SetPositionSizing(sizing config.PositionSizingConfig) {
+	cfg.ScaleRiskByOpenPositions = sizing.ScaleByOpenPositions
+	cfg.BetaAdjust = sizing.BetaAdjust
+	cfg.MinBeta = sizing.MinBeta
+	if sizing.BetaAdjust {
+		cfg.Betas = NewBetaCache(nil, sizing.BetaBenchmark, sizing.BetaLookbackDays, time.Duration(sizing.BetaCacheHours)*time.Hour)
+	}
+}
+
+func assetRiskOverride(risk config.AssetRiskConfig) *OrderConfig {
+	return &OrderConfig{
+		MaxPortfolioPercent: risk.MaxPortfolioPercent,
+		MaxOpenPositions:    risk.MaxOpenPositions,
+		StopLossPercent:     risk.StopLossPercent,
+		TakeProfitPercent:   risk.TakeProfitPercent,
+		SafeBailPercent:     risk.SafeBailPercent,
+	}
 }
 
 type OrderRequest struct {
@@ -30,6 +140,9 @@ type OrderRequest struct {
 	UseStopOrder     bool
 	UseLimitOrder    bool
 	LimitPrice       float64
+	TimeInForce      alpaca.TimeInForce // empty means day; cls/opg make market-on-close/open (limit-on-* with UseLimitOrder)
+	AssetType        string             // empty detects it from the symbol; picks the OrderConfig asset-class limits
+	UseBracketOrder  bool               // attach TakeProfitPrice/StopLossPrice as broker-held exit legs (stocks only, day or gtc)
 }
 
 type OrderValidation struct {
@@ -41,8 +154,9 @@ type OrderValidation struct {
 	Issues        []string
 }
 
-// ValidateOrder checks if order meets safety requirements
+// ValidateOrder checks if order meets safety requirements, using the limits for the symbol's asset class
 func ValidateOrder(req *OrderRequest, cfg *OrderConfig, accountValue float64, openPositions int, dailyLoss float64) *OrderValidation {
+	cfg = cfg.ForAsset(req.Symbol, req.AssetType)
 	validation := &OrderValidation{
 		IsValid: true,
 		Issues:  []string{},
@@ -147,12 +261,20 @@ func BuildPlaceOrderRequest(req *OrderRequest) (*alpaca.PlaceOrderRequest, error
 		orderType = alpaca.Limit
 	}
 
+	timeInForce, err := ParseTimeInForce(string(req.TimeInForce))
+	if err != nil {
+		return nil, err
+	}
+	if IsAuctionTimeInForce(timeInForce) && req.UseLimitOrder && req.LimitPrice <= 0 {
+		return nil, fmt.Errorf("limit price is required for limit-on-%s orders", auctionName(timeInForce))
+	}
+
 	placeOrderReq := &alpaca.PlaceOrderRequest{
 		Symbol:      req.Symbol,
 		Qty:         &decimal.Decimal{},
 		Side:        side,
 		Type:        orderType,
-		TimeInForce: alpaca.Day,
+		TimeInForce: timeInForce,
 	}
 
 	*placeOrderReq.Qty = decimal.NewFromInt(req.Quantity)
@@ -162,9 +284,142 @@ func BuildPlaceOrderRequest(req *OrderRequest) (*alpaca.PlaceOrderRequest, error
 		placeOrderReq.LimitPrice = &limitPrice
 	}
 
+	if req.UseBracketOrder {
+		if err := attachBracketLegs(placeOrderReq, req); err != nil {
+			return nil, err
+		}
+	}
+
 	return placeOrderReq, nil
 }
 
+// turns the entry into a bracket whose take-profit and stop-loss legs Alpaca holds, so exits survive a restart
+func attachBracketLegs(order *alpaca.PlaceOrderRequest, req *OrderRequest) error {
+	if utils.DetectAssetType(req.Symbol, req.AssetType) == utils.AssetTypeCrypto {
+		return fmt.Errorf("bracket orders are not supported for crypto (%s)", req.Symbol)
+	}
+	if order.TimeInForce != alpaca.Day && order.TimeInForce != alpaca.GTC {
+		return fmt.Errorf("bracket orders must be day or gtc, got %s", order.TimeInForce)
+	}
+	if req.TakeProfitPrice <= 0 || req.StopLossPrice <= 0 {
+		return fmt.Errorf("bracket orders need a positive take-profit and stop-loss (got $%.2f/$%.2f)", req.TakeProfitPrice, req.StopLossPrice)
+	}
+
+	entry := req.EntryPrice
+	if req.UseLimitOrder {
+		entry = req.LimitPrice
+	}
+	if req.Direction == "LONG" && req.StopLossPrice >= req.TakeProfitPrice {
+		return fmt.Errorf("long bracket stop $%.2f must be below take-profit $%.2f", req.StopLossPrice, req.TakeProfitPrice)
+	}
+	if req.Direction == "SHORT" && req.StopLossPrice <= req.TakeProfitPrice {
+		return fmt.Errorf("short bracket stop $%.2f must be above take-profit $%.2f", req.StopLossPrice, req.TakeProfitPrice)
+	}
+	if entry > 0 {
+		below, above := req.StopLossPrice, req.TakeProfitPrice
+		if req.Direction == "SHORT" {
+			below, above = above, below
+		}
+		if below >= entry || above <= entry {
+			return fmt.Errorf("bracket legs $%.2f/$%.2f must straddle the $%.2f entry", below, above, entry)
+		}
+	}
+
+	takeProfit := decimal.NewFromFloat(req.TakeProfitPrice)
+	stopLoss := decimal.NewFromFloat(req.StopLossPrice)
+	order.OrderClass = alpaca.Bracket
+	order.TakeProfit = &alpaca.TakeProfit{LimitPrice: &takeProfit}
+	order.StopLoss = &alpaca.StopLoss{StopPrice: &stopLoss}
+	return nil
+}
+
+// parses a time-in-force string, defaulting to day when empty
+func ParseTimeInForce(value string) (alpaca.TimeInForce, error) {
+	tif := alpaca.TimeInForce(strings.ToLower(strings.TrimSpace(value)))
+	switch tif {
+	case "":
+		return alpaca.Day, nil
+	case alpaca.Day, alpaca.GTC, alpaca.OPG, alpaca.CLS, alpaca.IOC, alpaca.FOK:
+		return tif, nil
+	}
+	return "", fmt.Errorf("invalid time_in_force: %s (must be day, gtc, opg, cls, ioc or fok)", value)
+}
+
+// opg and cls orders execute in the opening/closing auction
+func IsAuctionTimeInForce(tif alpaca.TimeInForce) bool {
+	return tif == alpaca.OPG || tif == alpaca.CLS
+}
+
+func auctionName(tif alpaca.TimeInForce) string {
+	if tif == alpaca.OPG {
+		return "open"
+	}
+	return "close"
+}
+
+// rejects opg/cls orders submitted outside Alpaca's auction submission window
+func CheckAuctionOrderWindow(tif alpaca.TimeInForce, now time.Time, cfg *config.Config) error {
+	if !IsAuctionTimeInForce(tif) {
+		return nil
+	}
+	if cfg == nil {
+		return fmt.Errorf("config is required to validate on-%s orders", auctionName(tif))
+	}
+	if !cfg.AuctionOrders.Enabled {
+		return fmt.Errorf("on-open/on-close orders are disabled in config")
+	}
+
+	status, _ := utils.CheckMarketStatus(now, cfg)
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return fmt.Errorf("failed to load market timezone: %w", err)
+	}
+	hour, minute, _ := now.In(location).Clock()
+	minutes := hour*60 + minute
+
+	if tif == alpaca.CLS {
+		cutoff := orDefault(cfg.AuctionOrders.CloseCutoff, defaultCloseCutoff)
+		cutoffMinutes, err := clockMinutes(cutoff)
+		if err != nil {
+			return err
+		}
+		if (status != "PREMARKET" && status != "REGULAR") || minutes >= cutoffMinutes {
+			return fmt.Errorf("market-on-close/limit-on-close orders must be submitted before %s ET on a trading day (market is %s)", cutoff, status)
+		}
+		return nil
+	}
+
+	cutoff := orDefault(cfg.AuctionOrders.OpenCutoff, defaultOpenCutoff)
+	acceptFrom := orDefault(cfg.AuctionOrders.OpenAcceptFrom, defaultOpenAcceptFrom)
+	cutoffMinutes, err := clockMinutes(cutoff)
+	if err != nil {
+		return err
+	}
+	acceptMinutes, err := clockMinutes(acceptFrom)
+	if err != nil {
+		return err
+	}
+	if status != "CLOSED" && minutes >= cutoffMinutes && minutes < acceptMinutes {
+		return fmt.Errorf("market-on-open/limit-on-open orders are only accepted between %s ET and %s ET (market is %s)", acceptFrom, cutoff, status)
+	}
+	return nil
+}
+
+func clockMinutes(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid auction window time %q: %w", value, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
 // checks safe quantity based on account size and risk
 func CalculatePositionSize(accountValue float64, entryPrice float64, stopLossPrice float64,
 	maxRiskPercent float64, cfg *OrderConfig) int64 {
@@ -195,6 +450,33 @@ func CalculatePositionSize(accountValue float64, entryPrice float64, stopLossPri
 	return positionSize
 }
 
+// the risk budget for the next trade with openPositions already held. With scaling on it's
+// maxRiskPercent/sqrt(n+1), so the 4th concurrent position risks half of the 1st; either way it's never more
+// than what MaxPortfolioRiskPercent leaves after the openRiskPercent already at stake
+func ScaledRiskPercent(maxRiskPercent float64, openPositions int, openRiskPercent float64, cfg *OrderConfig) float64 {
+	if cfg == nil {
+		return maxRiskPercent
+	}
+	riskPercent := maxRiskPercent
+	if cfg.ScaleRiskByOpenPositions {
+		riskPercent /= math.Sqrt(float64(max(openPositions, 0) + 1))
+	}
+	if cfg.MaxPortfolioRiskPercent > 0 {
+		riskPercent = math.Min(riskPercent, math.Max(cfg.MaxPortfolioRiskPercent-openRiskPercent, 0))
+	}
+	return riskPercent
+}
+
+// CalculatePositionSize with the risk budget from ScaledRiskPercent; 0 when the portfolio ceiling is used up
+func CalculateScaledPositionSize(accountValue float64, entryPrice float64, stopLossPrice float64,
+	maxRiskPercent float64, openPositions int, openRiskPercent float64, cfg *OrderConfig) int64 {
+	riskPercent := ScaledRiskPercent(maxRiskPercent, openPositions, openRiskPercent, cfg)
+	if riskPercent <= 0 {
+		return 0
+	}
+	return CalculatePositionSize(accountValue, entryPrice, stopLossPrice, riskPercent, cfg)
+}
+
 // computes stop loss and take profit levels
 func CalculatePriceTargets(entryPrice float64, direction string, cfg *OrderConfig) (stopLoss float64, takeProfit float64) {
 	if direction == "LONG" {
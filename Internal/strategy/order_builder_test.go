@@ -0,0 +1,574 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+func auctionTestConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Global.MarketHours.PremarketOpen = "04:00"
+	cfg.Global.MarketHours.RegularOpen = "09:30"
+	cfg.Global.MarketHours.RegularClose = "16:00"
+	cfg.Global.MarketHours.AfterhourClose = "20:00"
+	cfg.AuctionOrders.Enabled = true
+	return cfg
+}
+
+func easternTime(t *testing.T, year int, month time.Month, day, hour, minute int) time.Time {
+	t.Helper()
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	return time.Date(year, month, day, hour, minute, 0, 0, location)
+}
+
+func TestBuildPlaceOrderRequest_MarketOnClose(t *testing.T) {
+	order, err := BuildPlaceOrderRequest(&OrderRequest{
+		Symbol:      "AAPL",
+		Quantity:    10,
+		Direction:   "LONG",
+		TimeInForce: alpaca.CLS,
+	})
+	if err != nil {
+		t.Fatalf("BuildPlaceOrderRequest() error = %v", err)
+	}
+	if order.Type != alpaca.Market || order.TimeInForce != alpaca.CLS {
+		t.Errorf("Type/TimeInForce = %s/%s, want market/cls", order.Type, order.TimeInForce)
+	}
+	if order.LimitPrice != nil {
+		t.Errorf("Expected no limit price on a market-on-close order")
+	}
+}
+
+func TestBuildPlaceOrderRequest_LimitOnClose(t *testing.T) {
+	order, err := BuildPlaceOrderRequest(&OrderRequest{
+		Symbol:        "AAPL",
+		Quantity:      10,
+		Direction:     "SHORT",
+		UseLimitOrder: true,
+		LimitPrice:    187.5,
+		TimeInForce:   "CLS",
+	})
+	if err != nil {
+		t.Fatalf("BuildPlaceOrderRequest() error = %v", err)
+	}
+	if order.Type != alpaca.Limit || order.TimeInForce != alpaca.CLS || order.Side != alpaca.Sell {
+		t.Errorf("Unexpected order: type=%s tif=%s side=%s", order.Type, order.TimeInForce, order.Side)
+	}
+	if order.LimitPrice == nil || order.LimitPrice.String() != "187.5" {
+		t.Errorf("LimitPrice = %v, want 187.5", order.LimitPrice)
+	}
+
+	_, err = BuildPlaceOrderRequest(&OrderRequest{Symbol: "AAPL", Quantity: 10, Direction: "LONG", UseLimitOrder: true, TimeInForce: alpaca.CLS})
+	if err == nil {
+		t.Errorf("Expected error for limit-on-close without a limit price")
+	}
+}
+
+func TestBuildPlaceOrderRequest_DefaultsToDay(t *testing.T) {
+	order, err := BuildPlaceOrderRequest(&OrderRequest{Symbol: "AAPL", Quantity: 1, Direction: "LONG"})
+	if err != nil {
+		t.Fatalf("BuildPlaceOrderRequest() error = %v", err)
+	}
+	if order.TimeInForce != alpaca.Day {
+		t.Errorf("TimeInForce = %s, want day", order.TimeInForce)
+	}
+
+	if _, err := BuildPlaceOrderRequest(&OrderRequest{Symbol: "AAPL", Quantity: 1, Direction: "LONG", TimeInForce: "moc"}); err == nil {
+		t.Errorf("Expected error for unsupported time in force")
+	}
+}
+
+func TestBuildPlaceOrderRequest_SimpleOrderHasNoLegs(t *testing.T) {
+	order, err := BuildPlaceOrderRequest(&OrderRequest{
+		Symbol:          "AAPL",
+		Quantity:        5,
+		Direction:       "LONG",
+		EntryPrice:      100,
+		StopLossPrice:   95,
+		TakeProfitPrice: 110,
+	})
+	if err != nil {
+		t.Fatalf("BuildPlaceOrderRequest() error = %v", err)
+	}
+	if order.OrderClass != "" || order.TakeProfit != nil || order.StopLoss != nil {
+		t.Errorf("Expected a simple order, got class=%q take_profit=%v stop_loss=%v", order.OrderClass, order.TakeProfit, order.StopLoss)
+	}
+	if order.Type != alpaca.Market || order.Side != alpaca.Buy || order.Qty.String() != "5" {
+		t.Errorf("Unexpected order: type=%s side=%s qty=%s", order.Type, order.Side, order.Qty)
+	}
+}
+
+func TestBuildPlaceOrderRequest_Bracket(t *testing.T) {
+	order, err := BuildPlaceOrderRequest(&OrderRequest{
+		Symbol:          "AAPL",
+		Quantity:        5,
+		Direction:       "LONG",
+		EntryPrice:      100,
+		StopLossPrice:   95.5,
+		TakeProfitPrice: 110.25,
+		UseBracketOrder: true,
+		TimeInForce:     alpaca.GTC,
+	})
+	if err != nil {
+		t.Fatalf("BuildPlaceOrderRequest() error = %v", err)
+	}
+	if order.OrderClass != alpaca.Bracket || order.Type != alpaca.Market || order.TimeInForce != alpaca.GTC {
+		t.Errorf("Unexpected order: class=%s type=%s tif=%s", order.OrderClass, order.Type, order.TimeInForce)
+	}
+	if order.TakeProfit == nil || order.TakeProfit.LimitPrice == nil || order.TakeProfit.LimitPrice.String() != "110.25" {
+		t.Errorf("TakeProfit = %+v, want limit 110.25", order.TakeProfit)
+	}
+	if order.StopLoss == nil || order.StopLoss.StopPrice == nil || order.StopLoss.StopPrice.String() != "95.5" {
+		t.Errorf("StopLoss = %+v, want stop 95.5", order.StopLoss)
+	}
+	if order.StopLoss != nil && order.StopLoss.LimitPrice != nil {
+		t.Errorf("Expected a stop (not stop-limit) loss leg, got limit %s", order.StopLoss.LimitPrice)
+	}
+
+	short, err := BuildPlaceOrderRequest(&OrderRequest{
+		Symbol:          "AAPL",
+		Quantity:        5,
+		Direction:       "SHORT",
+		UseLimitOrder:   true,
+		LimitPrice:      100,
+		StopLossPrice:   104,
+		TakeProfitPrice: 90,
+		UseBracketOrder: true,
+	})
+	if err != nil {
+		t.Fatalf("BuildPlaceOrderRequest() short error = %v", err)
+	}
+	if short.OrderClass != alpaca.Bracket || short.Side != alpaca.Sell || short.Type != alpaca.Limit {
+		t.Errorf("Unexpected short order: class=%s side=%s type=%s", short.OrderClass, short.Side, short.Type)
+	}
+	if short.StopLoss.StopPrice.String() != "104" || short.TakeProfit.LimitPrice.String() != "90" {
+		t.Errorf("Short legs = stop %s / target %s, want 104 / 90", short.StopLoss.StopPrice, short.TakeProfit.LimitPrice)
+	}
+}
+
+func TestBuildPlaceOrderRequest_BracketRejectsInvalidLegs(t *testing.T) {
+	base := OrderRequest{
+		Symbol:          "AAPL",
+		Quantity:        5,
+		Direction:       "LONG",
+		EntryPrice:      100,
+		StopLossPrice:   95,
+		TakeProfitPrice: 110,
+		UseBracketOrder: true,
+	}
+	tests := []struct {
+		name   string
+		mutate func(req *OrderRequest)
+	}{
+		{"missing take profit", func(req *OrderRequest) { req.TakeProfitPrice = 0 }},
+		{"missing stop loss", func(req *OrderRequest) { req.StopLossPrice = 0 }},
+		{"long stop above target", func(req *OrderRequest) { req.StopLossPrice, req.TakeProfitPrice = 110, 95 }},
+		{"stop above entry", func(req *OrderRequest) { req.StopLossPrice = 101 }},
+		{"short with long legs", func(req *OrderRequest) { req.Direction = "SHORT" }},
+		{"auction time in force", func(req *OrderRequest) { req.TimeInForce = alpaca.CLS }},
+		{"immediate or cancel", func(req *OrderRequest) { req.TimeInForce = alpaca.IOC }},
+		{"crypto", func(req *OrderRequest) { req.Symbol = "BTC/USD" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := base
+			tt.mutate(&req)
+			if _, err := BuildPlaceOrderRequest(&req); err == nil {
+				t.Errorf("Expected an error for %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestCheckAuctionOrderWindow(t *testing.T) {
+	cfg := auctionTestConfig()
+	tests := []struct {
+		name    string
+		tif     alpaca.TimeInForce
+		at      time.Time
+		wantErr bool
+	}{
+		{"moc during session", alpaca.CLS, easternTime(t, 2024, time.March, 6, 15, 30), false},
+		{"moc after cutoff", alpaca.CLS, easternTime(t, 2024, time.March, 6, 15, 55), true},
+		{"moc after close", alpaca.CLS, easternTime(t, 2024, time.March, 6, 17, 0), true},
+		{"moc on weekend", alpaca.CLS, easternTime(t, 2024, time.March, 9, 12, 0), true},
+		{"moo premarket", alpaca.OPG, easternTime(t, 2024, time.March, 6, 8, 0), false},
+		{"moo during session", alpaca.OPG, easternTime(t, 2024, time.March, 6, 12, 0), true},
+		{"moo evening before", alpaca.OPG, easternTime(t, 2024, time.March, 6, 19, 30), false},
+		{"day order ignored", alpaca.Day, easternTime(t, 2024, time.March, 9, 12, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckAuctionOrderWindow(tt.tif, tt.at, cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckAuctionOrderWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckAuctionOrderWindow_RejectionMessage(t *testing.T) {
+	err := CheckAuctionOrderWindow(alpaca.CLS, easternTime(t, 2024, time.March, 6, 15, 55), auctionTestConfig())
+	if err == nil {
+		t.Fatal("Expected out-of-window rejection")
+	}
+	if !strings.Contains(err.Error(), "before 15:50 ET") {
+		t.Errorf("Unexpected message: %v", err)
+	}
+}
+
+func TestCheckAuctionOrderWindow_Disabled(t *testing.T) {
+	cfg := auctionTestConfig()
+	cfg.AuctionOrders.Enabled = false
+	if err := CheckAuctionOrderWindow(alpaca.CLS, easternTime(t, 2024, time.March, 6, 15, 0), cfg); err == nil {
+		t.Errorf("Expected error when auction orders are disabled")
+	}
+}
+
+func assetClassTestConfig() *OrderConfig {
+	cfg := &OrderConfig{
+		MaxPortfolioPercent: 20,
+		MaxOpenPositions:    5,
+		StopLossPercent:     2,
+		TakeProfitPercent:   5,
+		SafeBailPercent:     3,
+		MaxDailyLossPercent: -2,
+	}
+	cfg.SetAssetClassRisk(config.AssetClassRiskConfig{
+		Crypto: config.AssetRiskConfig{MaxPortfolioPercent: 5, MaxOpenPositions: 2, StopLossPercent: 8, TakeProfitPercent: 15},
+	})
+	return cfg
+}
+
+func TestOrderConfigForAsset_PicksAssetClassLimits(t *testing.T) {
+	cfg := assetClassTestConfig()
+
+	crypto := cfg.ForAsset("BTC/USD", "")
+	if crypto.StopLossPercent != 8 || crypto.TakeProfitPercent != 15 || crypto.MaxPortfolioPercent != 5 || crypto.MaxOpenPositions != 2 {
+		t.Errorf("crypto config = %+v, want the crypto overrides", crypto)
+	}
+	if crypto.SafeBailPercent != 3 {
+		t.Errorf("crypto SafeBailPercent = %.1f, want the unset override to inherit 3", crypto.SafeBailPercent)
+	}
+
+	equity := cfg.ForAsset("AAPL", "")
+	if equity.StopLossPercent != 2 || equity.TakeProfitPercent != 5 || equity.MaxPortfolioPercent != 20 || equity.MaxOpenPositions != 5 {
+		t.Errorf("equity config = %+v, want the global limits", equity)
+	}
+
+	stop, target := CalculatePriceTargets(100, "LONG", crypto)
+	if math.Abs(stop-92) > 1e-9 || math.Abs(target-115) > 1e-9 {
+		t.Errorf("crypto targets = %.2f/%.2f, want 92/115", stop, target)
+	}
+	stop, target = CalculatePriceTargets(100, "LONG", equity)
+	if math.Abs(stop-98) > 1e-9 || math.Abs(target-105) > 1e-9 {
+		t.Errorf("equity targets = %.2f/%.2f, want 98/105", stop, target)
+	}
+
+	if size := CalculatePositionSize(10000, 100, 92, crypto.MaxPortfolioPercent, crypto); size != 62 {
+		t.Errorf("crypto size = %d, want 62 from the 5%% cap", size)
+	}
+	if size := CalculatePositionSize(10000, 100, 98, equity.MaxPortfolioPercent, equity); size != 1000 {
+		t.Errorf("equity size = %d, want 1000 from the 20%% cap", size)
+	}
+}
+
+func TestValidateOrder_UsesAssetClassLimits(t *testing.T) {
+	cfg := assetClassTestConfig()
+
+	// $800 at risk on a $10k account: 8% clears the equity 20% cap but not the crypto 5% cap
+	crypto := &OrderRequest{Symbol: "ETH/USD", Quantity: 100, Direction: "LONG", EntryPrice: 100, StopLossPrice: 92, TakeProfitPrice: 115}
+	if v := ValidateOrder(crypto, cfg, 10000, 0, 0); v.IsValid {
+		t.Errorf("crypto order passed with %.1f%% risk over the 5%% crypto cap", v.PortfolioRisk)
+	}
+	equity := &OrderRequest{Symbol: "AAPL", Quantity: 100, Direction: "LONG", EntryPrice: 100, StopLossPrice: 92, TakeProfitPrice: 115}
+	if v := ValidateOrder(equity, cfg, 10000, 0, 0); !v.IsValid {
+		t.Errorf("equity order rejected: %v", v.Issues)
+	}
+
+	// two open positions hit the crypto cap but not the equity one
+	small := *crypto
+	small.Quantity = 1
+	if v := ValidateOrder(&small, cfg, 10000, 2, 0); v.IsValid {
+		t.Errorf("crypto order passed with 2 open positions against a cap of 2")
+	}
+	small.AssetType = "stock" // explicit asset type beats symbol detection
+	if v := ValidateOrder(&small, cfg, 10000, 2, 0); !v.IsValid {
+		t.Errorf("explicit stock order rejected: %v", v.Issues)
+	}
+}
+
+func TestCalculateScaledPositionSize_ShrinksWithOpenPositions(t *testing.T) {
+	cfg := &OrderConfig{MaxPortfolioPercent: 20, ScaleRiskByOpenPositions: true, MaxPortfolioRiskPercent: 10}
+
+	// 2% risk budget, $2 risk per share on a $100k account
+	first := CalculateScaledPositionSize(100000, 100, 98, 2, 0, 0, cfg)
+	fourth := CalculateScaledPositionSize(100000, 100, 98, 2, 3, 4, cfg)
+	if first != 1000 {
+		t.Errorf("1st position = %d shares, want 1000", first)
+	}
+	if fourth != 500 {
+		t.Errorf("4th position = %d shares, want 500 (risk / sqrt(4))", fourth)
+	}
+
+	cfg.ScaleRiskByOpenPositions = false
+	if got := CalculateScaledPositionSize(100000, 100, 98, 2, 3, 4, cfg); got != first {
+		t.Errorf("4th position with scaling off = %d shares, want %d", got, first)
+	}
+}
+
+func TestCalculateScaledPositionSize_PortfolioRiskCeiling(t *testing.T) {
+	cfg := &OrderConfig{MaxPortfolioPercent: 20, ScaleRiskByOpenPositions: true, MaxPortfolioRiskPercent: 10}
+
+	// 9.5% already at risk leaves 0.5% of the 10% ceiling, below the scaled 1%
+	if got := CalculateScaledPositionSize(100000, 100, 98, 2, 3, 9.5, cfg); got != 250 {
+		t.Errorf("size near the ceiling = %d shares, want 250", got)
+	}
+	if got := CalculateScaledPositionSize(100000, 100, 98, 2, 5, 10, cfg); got != 0 {
+		t.Errorf("size with the ceiling used up = %d shares, want 0", got)
+	}
+	if got := ScaledRiskPercent(2, 3, 0, cfg); math.Abs(got-1) > 1e-9 {
+		t.Errorf("ScaledRiskPercent(2, 3 open) = %.4f, want 1", got)
+	}
+
+	// the ceiling holds with scaling off too: 9.5% at risk leaves 0.5% of the unscaled 2%
+	cfg.ScaleRiskByOpenPositions = false
+	if got := CalculateScaledPositionSize(100000, 100, 98, 2, 3, 9.5, cfg); got != 250 {
+		t.Errorf("size near the ceiling with scaling off = %d shares, want 250", got)
+	}
+	if got := CalculateScaledPositionSize(100000, 100, 98, 2, 5, 10, cfg); got != 0 {
+		t.Errorf("size with the ceiling used up and scaling off = %d shares, want 0", got)
+	}
+}
+
+func TestCheckBarFreshness(t *testing.T) {
+	cfg := auctionTestConfig()
+	cfg.DataFreshness.Enabled = true
+	cfg.DataFreshness.MaxBarAgeMinutes = 30
+	wednesday := easternTime(t, 2024, time.March, 6, 11, 0)
+	todayBar := types.Bar{Timestamp: "2024-03-06T05:00:00Z", Close: 100}
+	yesterdayBar := types.Bar{Timestamp: "2024-03-05T05:00:00Z", Close: 100}
+
+	if err := CheckBarFreshness(todayBar, "1Day", "stock", wednesday, cfg); err != nil {
+		t.Errorf("today's bar during market hours: error = %v, want nil", err)
+	}
+	err := CheckBarFreshness(yesterdayBar, "1Day", "stock", wednesday, cfg)
+	if !errors.Is(err, ErrStaleMarketData) {
+		t.Errorf("day-old bar during market hours: error = %v, want ErrStaleMarketData", err)
+	}
+
+	// no new equity bars print over a weekend, but crypto keeps trading
+	saturday := easternTime(t, 2024, time.March, 9, 11, 0)
+	fridayBar := types.Bar{Timestamp: "2024-03-08T05:00:00Z", Close: 100}
+	if err := CheckBarFreshness(fridayBar, "1Day", "stock", saturday, cfg); err != nil {
+		t.Errorf("Friday's bar on Saturday: error = %v, want nil while the market is closed", err)
+	}
+	if err := CheckBarFreshness(fridayBar, "1Day", "crypto", saturday, cfg); !errors.Is(err, ErrStaleMarketData) {
+		t.Errorf("day-old crypto bar on Saturday: error = %v, want ErrStaleMarketData", err)
+	}
+
+	cfg.DataFreshness.Enabled = false
+	if err := CheckBarFreshness(yesterdayBar, "1Day", "stock", wednesday, cfg); err != nil {
+		t.Errorf("disabled check: error = %v, want nil", err)
+	}
+}
+
+// broker double: accepted orders are keyed by client order ID and a submit may fail after or before acceptance
+type flakySubmitter struct {
+	accepted    map[string]*alpaca.Order
+	submits     int
+	failAfter   int // submits that reach the broker, then report a network error
+	failBefore  int // submits that fail before reaching the broker
+	failWithErr error
+}
+
+func (f *flakySubmitter) PlaceOrder(req alpaca.PlaceOrderRequest) (*alpaca.Order, error) {
+	f.submits++
+	if f.failBefore > 0 {
+		f.failBefore--
+		return nil, f.failWithErr
+	}
+	if _, dup := f.accepted[req.ClientOrderID]; dup {
+		return nil, errors.New("client_order_id must be unique")
+	}
+	order := &alpaca.Order{ID: fmt.Sprintf("order-%d", len(f.accepted)+1), ClientOrderID: req.ClientOrderID, Symbol: req.Symbol}
+	f.accepted[req.ClientOrderID] = order
+	if f.failAfter > 0 {
+		f.failAfter--
+		return nil, f.failWithErr
+	}
+	return order, nil
+}
+
+func (f *flakySubmitter) GetOrderByClientOrderID(clientOrderID string) (*alpaca.Order, error) {
+	if order, ok := f.accepted[clientOrderID]; ok {
+		return order, nil
+	}
+	return nil, errors.New("order not found")
+}
+
+func testSubmitRetry() SubmitRetry {
+	return SubmitRetry{MaxAttempts: 3, Backoff: time.Millisecond, sleep: func(time.Duration) {}}
+}
+
+func TestSubmitOrder_AcceptedBeforeErrorIsNotDuplicated(t *testing.T) {
+	broker := &flakySubmitter{accepted: map[string]*alpaca.Order{}, failAfter: 1, failWithErr: io.ErrUnexpectedEOF}
+
+	order, err := SubmitOrder(broker, alpaca.PlaceOrderRequest{Symbol: "AAPL"}, testSubmitRetry())
+	if err != nil {
+		t.Fatalf("SubmitOrder() error = %v", err)
+	}
+	if len(broker.accepted) != 1 || broker.submits != 1 {
+		t.Errorf("broker accepted %d orders over %d submits, want 1 and no resubmit", len(broker.accepted), broker.submits)
+	}
+	if order.ID != "order-1" || !strings.HasPrefix(order.ClientOrderID, "mm-AAPL-") {
+		t.Errorf("order = %+v, want the accepted order with a generated client order ID", order)
+	}
+}
+
+func TestSubmitOrder_RetriesTransientFailures(t *testing.T) {
+	broker := &flakySubmitter{accepted: map[string]*alpaca.Order{}, failBefore: 2, failWithErr: context.DeadlineExceeded}
+
+	order, err := SubmitOrder(broker, alpaca.PlaceOrderRequest{Symbol: "AAPL", ClientOrderID: "caller-key"}, testSubmitRetry())
+	if err != nil {
+		t.Fatalf("SubmitOrder() error = %v", err)
+	}
+	if broker.submits != 3 || len(broker.accepted) != 1 || order.ClientOrderID != "caller-key" {
+		t.Errorf("submits = %d, accepted = %d, order = %+v; want one order on the third try under the caller's key", broker.submits, len(broker.accepted), order)
+	}
+
+	exhausted := &flakySubmitter{accepted: map[string]*alpaca.Order{}, failBefore: 5, failWithErr: context.DeadlineExceeded}
+	if _, err := SubmitOrder(exhausted, alpaca.PlaceOrderRequest{Symbol: "AAPL"}, testSubmitRetry()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("exhausted retries error = %v, want the wrapped transient error", err)
+	}
+	if exhausted.submits != 3 {
+		t.Errorf("submits = %d, want MaxAttempts (3)", exhausted.submits)
+	}
+}
+
+func TestSubmitOrder_RejectionIsNotRetried(t *testing.T) {
+	rejected := errors.New("insufficient buying power")
+	broker := &flakySubmitter{accepted: map[string]*alpaca.Order{}, failBefore: 1, failWithErr: rejected}
+
+	if _, err := SubmitOrder(broker, alpaca.PlaceOrderRequest{Symbol: "AAPL"}, testSubmitRetry()); !errors.Is(err, rejected) {
+		t.Errorf("error = %v, want the rejection", err)
+	}
+	if broker.submits != 1 {
+		t.Errorf("submits = %d, want 1", broker.submits)
+	}
+}
+
+func TestCheckBuyingPower(t *testing.T) {
+	reject := config.BuyingPowerConfig{Enabled: true, ReservePercent: 1}
+	resize := config.BuyingPowerConfig{Enabled: true, ResizeToFit: true, ReservePercent: 1}
+
+	tests := []struct {
+		name        string
+		quantity    float64
+		price       float64
+		buyingPower float64
+		cfg         config.BuyingPowerConfig
+		want        float64
+		wantErr     bool
+	}{
+		{"fits", 40, 100, 5000, reject, 40, false},
+		{"reserve pushes it over", 50, 100, 5000, reject, 0, true},
+		{"exceeds and rejected", 80, 100, 5000, reject, 0, true},
+		{"exceeds and resized", 80, 100, 5000, resize, 49, false},
+		{"fractional resized", 0.5, 60000, 10000, resize, 0.165, false},
+		{"nothing fits", 5, 100, 50, resize, 0, true},
+		{"disabled", 80, 100, 5000, config.BuyingPowerConfig{}, 80, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckBuyingPower(tt.quantity, tt.price, tt.buyingPower, tt.cfg)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInsufficientBuyingPower) {
+					t.Errorf("error = %v, want ErrInsufficientBuyingPower", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("quantity = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckStopNoise_TightStopOnHighATRSymbol(t *testing.T) {
+	cfg := &OrderConfig{StopLossPercent: 0.5, TakeProfitPercent: 5}
+	stop, _ := CalculatePriceTargets(200, "LONG", cfg)
+	noise := config.StopNoiseConfig{Enabled: true, MinATRMultiple: 1}
+
+	// a $1 stop against a $6 ATR
+	check := CheckStopNoise(200, stop, 6, "LONG", noise)
+	if !check.TooTight || check.Widened || check.Stop != stop || check.SuggestedStop != 194 {
+		t.Errorf("warn only: %+v, want too tight with the $194 suggestion and the stop kept", check)
+	}
+	if check.Warning() == "" {
+		t.Error("want a warning for the tight stop")
+	}
+
+	noise.AutoWiden = true
+	if check := CheckStopNoise(200, stop, 6, "LONG", noise); !check.Widened || check.Stop != 194 {
+		t.Errorf("auto widen long: %+v, want the stop moved to $194", check)
+	}
+	shortStop, _ := CalculatePriceTargets(200, "SHORT", cfg)
+	if check := CheckStopNoise(200, shortStop, 6, "SHORT", noise); !check.Widened || check.Stop != 206 {
+		t.Errorf("auto widen short: %+v, want the stop moved to $206", check)
+	}
+
+	if check := CheckStopNoise(200, stop, 0.5, "LONG", noise); check.TooTight || check.Warning() != "" {
+		t.Errorf("low ATR: %+v, want the $1 stop accepted", check)
+	}
+	if check := CheckStopNoise(200, stop, 6, "LONG", config.StopNoiseConfig{}); check.TooTight || check.Stop != stop {
+		t.Errorf("disabled: %+v, want the stop untouched", check)
+	}
+}
+
+func TestOrderConfig_ReplaceSwapsSharedConfig(t *testing.T) {
+	shared := &OrderConfig{StopLossPercent: 2, TakeProfitPercent: 5}
+	before := shared.Snapshot()
+
+	// readers snapshot while settings swap the config; run with -race
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if cfg := shared.Snapshot(); cfg.TakeProfitPercent != 2.5*cfg.StopLossPercent {
+					t.Errorf("torn snapshot %.1f/%.1f", cfg.StopLossPercent, cfg.TakeProfitPercent)
+					return
+				}
+			}
+		}()
+	}
+	previous := shared.Replace(OrderConfig{StopLossPercent: 4, TakeProfitPercent: 10})
+	wg.Wait()
+
+	if previous.StopLossPercent != 2 || shared.StopLossPercent != 4 {
+		t.Errorf("previous stop %.1f, shared stop %.1f; want 2 then 4", previous.StopLossPercent, shared.StopLossPercent)
+	}
+	if before.StopLossPercent != 2 {
+		t.Errorf("snapshot stop = %.1f, want it unaffected by the swap", before.StopLossPercent)
+	}
+}
@@ -0,0 +1,117 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+// fallbacks when order_fill leaves a field unset
+const (
+	defaultFillTimeout      = 30 * time.Second
+	defaultFillPollInterval = 500 * time.Millisecond
+)
+
+var ErrFillTimeout = errors.New("order not filled before the timeout")
+
+// broker call WaitForFill needs (*alpaca.Client satisfies it)
+type OrderGetter interface {
+	GetOrder(orderID string) (*alpaca.Order, error)
+}
+
+// how often WaitForFill polls and who hears about status changes
+type FillPolling struct {
+	Interval time.Duration
+	OnStatus func(order *alpaca.Order) // called whenever the polled status changes, nil to stay quiet
+}
+
+// where a submitted order ended up
+type FillResult struct {
+	Order     *alpaca.Order // last order state seen, nil if no poll succeeded
+	Status    string
+	FilledQty float64
+	FillPrice float64 // average fill price, 0 when nothing filled
+	TimedOut  bool
+}
+
+// whether anything was bought or sold
+func (r *FillResult) Filled() bool {
+	return r != nil && r.FilledQty > 0
+}
+
+// timeout and poll interval from the order_fill config block, filling unset fields with the defaults
+func FillWaitFromConfig(cfg config.OrderFillConfig) (time.Duration, FillPolling) {
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultFillTimeout
+	}
+	interval := time.Duration(cfg.PollIntervalMillis) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultFillPollInterval
+	}
+	return timeout, FillPolling{Interval: interval}
+}
+
+// statuses after which an order's fill can't change
+func fillSettled(status string) bool {
+	switch status {
+	case "filled", "partially_filled", "canceled", "expired", "rejected", "done_for_day":
+		return true
+	}
+	return false
+}
+
+// polls orderID until it is filled, partially filled, canceled, expired or rejected, and returns the actual
+// quantity and average price. Past the timeout the result is marked TimedOut with whatever had filled by then and
+// the error wraps ErrFillTimeout; failed polls are retried until then
+func WaitForFill(ctx context.Context, client OrderGetter, orderID string, timeout time.Duration, poll FillPolling) (*FillResult, error) {
+	if timeout <= 0 {
+		timeout = defaultFillTimeout
+	}
+	interval := poll.Interval
+	if interval <= 0 {
+		interval = defaultFillPollInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	result := &FillResult{}
+	var lastErr error
+	for {
+		order, err := client.GetOrder(orderID)
+		if err != nil {
+			lastErr = err
+		} else if order != nil {
+			if order.Status != result.Status && poll.OnStatus != nil {
+				poll.OnStatus(order)
+			}
+			result.Order = order
+			result.Status = order.Status
+			result.FilledQty = order.FilledQty.InexactFloat64()
+			result.FillPrice = 0
+			if order.FilledAvgPrice != nil {
+				result.FillPrice = order.FilledAvgPrice.InexactFloat64()
+			}
+			if fillSettled(order.Status) {
+				return result, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			result.TimedOut = true
+			if lastErr != nil && result.Order == nil {
+				return result, fmt.Errorf("%w: order %s after %s (last poll error: %v)", ErrFillTimeout, orderID, timeout, lastErr)
+			}
+			return result, fmt.Errorf("%w: order %s still %q after %s", ErrFillTimeout, orderID, result.Status, timeout)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+// walks through the given order states one poll at a time, repeating the last
+type scriptedOrderGetter struct {
+	states []alpaca.Order
+	polls  int
+}
+
+func (s *scriptedOrderGetter) GetOrder(orderID string) (*alpaca.Order, error) {
+	state := s.states[min(s.polls, len(s.states)-1)]
+	s.polls++
+	state.ID = orderID
+	return &state, nil
+}
+
+func TestWaitForFill_FillsAfterPolls(t *testing.T) {
+	fillPrice := decimal.NewFromFloat(101.37)
+	client := &scriptedOrderGetter{states: []alpaca.Order{
+		{Status: "new"},
+		{Status: "accepted"},
+		{Status: "filled", FilledQty: decimal.NewFromInt(10), FilledAvgPrice: &fillPrice},
+	}}
+	var seen []string
+	poll := FillPolling{Interval: time.Millisecond, OnStatus: func(o *alpaca.Order) { seen = append(seen, o.Status) }}
+
+	fill, err := WaitForFill(context.Background(), client, "order-1", time.Second, poll)
+	if err != nil {
+		t.Fatalf("WaitForFill() error = %v", err)
+	}
+	if !fill.Filled() || fill.FilledQty != 10 || fill.FillPrice != 101.37 || fill.Status != "filled" || fill.TimedOut {
+		t.Errorf("fill = %+v, want 10 filled at 101.37", fill)
+	}
+	if client.polls != 3 {
+		t.Errorf("polled %d times, want 3", client.polls)
+	}
+	if len(seen) != 3 || seen[2] != "filled" {
+		t.Errorf("status callbacks = %v, want new, accepted, filled", seen)
+	}
+}
+
+func TestWaitForFill_TimesOut(t *testing.T) {
+	client := &scriptedOrderGetter{states: []alpaca.Order{{Status: "new"}}}
+	changes := 0
+	poll := FillPolling{Interval: time.Millisecond, OnStatus: func(o *alpaca.Order) { changes++ }}
+
+	fill, err := WaitForFill(context.Background(), client, "order-2", 20*time.Millisecond, poll)
+	if !errors.Is(err, ErrFillTimeout) {
+		t.Fatalf("error = %v, want ErrFillTimeout", err)
+	}
+	if !fill.TimedOut || fill.Filled() || fill.Status != "new" {
+		t.Errorf("fill = %+v, want a timed-out, unfilled order still new", fill)
+	}
+	if client.polls < 2 || changes != 1 {
+		t.Errorf("polled %d times with %d status changes, want repeated polls and one change", client.polls, changes)
+	}
+}
+
+func TestWaitForFill_StopsOnPartialAndCancel(t *testing.T) {
+	partialPrice := decimal.NewFromFloat(50)
+	partial := &scriptedOrderGetter{states: []alpaca.Order{
+		{Status: "partially_filled", FilledQty: decimal.NewFromInt(4), FilledAvgPrice: &partialPrice},
+	}}
+	fill, err := WaitForFill(context.Background(), partial, "order-3", time.Second, FillPolling{Interval: time.Millisecond})
+	if err != nil || fill.FilledQty != 4 || fill.FillPrice != 50 {
+		t.Errorf("partial fill = %+v, err %v, want 4 at 50", fill, err)
+	}
+
+	canceled := &scriptedOrderGetter{states: []alpaca.Order{{Status: "canceled"}}}
+	fill, err = WaitForFill(context.Background(), canceled, "order-4", time.Second, FillPolling{Interval: time.Millisecond})
+	if err != nil || fill.Filled() || fill.Status != "canceled" {
+		t.Errorf("canceled order = %+v, err %v, want settled with nothing filled", fill, err)
+	}
+}
@@ -0,0 +1,123 @@
+package strategy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+// fallbacks when order_submit leaves a field unset
+const (
+	defaultSubmitAttempts = 3
+	defaultSubmitBackoff  = 500 * time.Millisecond
+)
+
+// broker calls SubmitOrder needs (*alpaca.Client satisfies it)
+type OrderSubmitter interface {
+	PlaceOrder(req alpaca.PlaceOrderRequest) (*alpaca.Order, error)
+	GetOrderByClientOrderID(clientOrderID string) (*alpaca.Order, error)
+}
+
+// bounded retry of an order submission on transient errors
+type SubmitRetry struct {
+	MaxAttempts int
+	Backoff     time.Duration    // wait before the first retry, doubled after each one
+	IsTransient func(error) bool // nil uses IsTransientError
+
+	sleep func(time.Duration) // time.Sleep unless a test overrides it
+}
+
+// retry policy from the order_submit config block, filling unset fields with the defaults
+func SubmitRetryFromConfig(cfg config.OrderSubmitConfig) SubmitRetry {
+	retry := SubmitRetry{
+		MaxAttempts: cfg.MaxAttempts,
+		Backoff:     time.Duration(cfg.RetryBackoffMillis) * time.Millisecond,
+	}
+	if retry.MaxAttempts <= 0 {
+		retry.MaxAttempts = defaultSubmitAttempts
+	}
+	if retry.Backoff <= 0 {
+		retry.Backoff = defaultSubmitBackoff
+	}
+	return retry
+}
+
+// network-level failures where the order may or may not have reached the broker
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// unique client order ID tagged with the symbol; Alpaca rejects a second order carrying the same one
+func NewClientOrderID(symbol string) string {
+	tag := strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, symbol)
+	var suffix [6]byte
+	if _, err := rand.Read(suffix[:]); err != nil {
+		return fmt.Sprintf("mm-%s-%d", tag, time.Now().UnixNano())
+	}
+	return fmt.Sprintf("mm-%s-%d-%s", tag, time.Now().UnixMilli(), hex.EncodeToString(suffix[:]))
+}
+
+// places req under a client order ID (generated when req has none) and retries transient failures. Before each
+// retry, and after the last failed attempt, it looks the ID up: an order the broker accepted before the
+// connection dropped is returned instead of being submitted twice
+func SubmitOrder(client OrderSubmitter, req alpaca.PlaceOrderRequest, retry SubmitRetry) (*alpaca.Order, error) {
+	if req.ClientOrderID == "" {
+		req.ClientOrderID = NewClientOrderID(req.Symbol)
+	}
+	attempts := max(retry.MaxAttempts, 1)
+	isTransient := retry.IsTransient
+	if isTransient == nil {
+		isTransient = IsTransientError
+	}
+	sleep := retry.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	backoff := retry.Backoff
+
+	for attempt := 1; ; attempt++ {
+		order, err := client.PlaceOrder(req)
+		if err == nil {
+			return order, nil
+		}
+		if !isTransient(err) {
+			return nil, err
+		}
+
+		if existing, lookupErr := client.GetOrderByClientOrderID(req.ClientOrderID); lookupErr == nil && existing != nil && existing.ID != "" {
+			log.Printf("Order %s for %s was accepted despite a submit error (%v), not resubmitting\n", req.ClientOrderID, req.Symbol, err)
+			return existing, nil
+		}
+		if attempt >= attempts {
+			return nil, fmt.Errorf("order %s for %s failed after %d attempts: %w", req.ClientOrderID, req.Symbol, attempts, err)
+		}
+
+		log.Printf("Order %s for %s failed (attempt %d/%d): %v, retrying in %s\n", req.ClientOrderID, req.Symbol, attempt, attempts, err, backoff)
+		sleep(backoff)
+		backoff *= 2
+	}
+}
@@ -0,0 +1,77 @@
+package position
+
+import (
+	"sort"
+	"strings"
+)
+
+// nets lots of the same symbol and direction into one position: summed quantity and unrealized P&L,
+// quantity-weighted entry, stop and target (so the dollar risk to the stop is unchanged), earliest entry time.
+// Single lots are returned as-is; netted positions are copies with the originals in Lots. Output is sorted by symbol.
+func AggregateLots(lots []*OpenPosition) []*OpenPosition {
+	groups := make(map[string][]*OpenPosition)
+	var keys []string
+	for _, lot := range lots {
+		key := lot.Symbol + "|" + lot.Direction
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], lot)
+	}
+	sort.Strings(keys)
+
+	net := make([]*OpenPosition, 0, len(keys))
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) == 1 {
+			net = append(net, group[0])
+			continue
+		}
+		net = append(net, netPosition(group))
+	}
+	return net
+}
+
+func netPosition(lots []*OpenPosition) *OpenPosition {
+	sort.SliceStable(lots, func(i, j int) bool { return lots[i].EntryTime.Before(lots[j].EntryTime) })
+
+	first := lots[0]
+	pos := &OpenPosition{
+		Symbol:    first.Symbol,
+		Direction: first.Direction,
+		EntryTime: first.EntryTime,
+		Status:    first.Status,
+		Lots:      lots,
+	}
+
+	var ids []string
+	var entryValue, stopValue, targetValue, bailValue float64
+	for _, lot := range lots {
+		qty := float64(lot.Quantity)
+		pos.Quantity += lot.Quantity
+		pos.UnrealizedPnL += lot.UnrealizedPnL
+		entryValue += lot.EntryPrice * qty
+		stopValue += lot.StopLossPrice * qty
+		targetValue += lot.TakeProfitPrice * qty
+		bailValue += lot.SafeBailPrice * qty
+		if lot.CurrentPrice > 0 {
+			pos.CurrentPrice = lot.CurrentPrice
+		}
+		pos.Intraday = pos.Intraday || lot.Intraday
+		ids = append(ids, lot.OrderID)
+	}
+	pos.OrderID = strings.Join(ids, ",")
+
+	if pos.Quantity == 0 {
+		return pos
+	}
+	qty := float64(pos.Quantity)
+	pos.EntryPrice = entryValue / qty
+	pos.StopLossPrice = stopValue / qty
+	pos.TakeProfitPrice = targetValue / qty
+	pos.SafeBailPrice = bailValue / qty
+	if cost := pos.EntryPrice * qty; cost > 0 {
+		pos.UnrealizedPnLPercent = pos.UnrealizedPnL / cost * 100
+	}
+	return pos
+}
@@ -0,0 +1,62 @@
+package position
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+func TestGetOpenPositions_AggregatesLotsOfOneSymbol(t *testing.T) {
+	pm, _, _ := newAutoExitManager(t, config.AutoExitConfig{})
+	addLongPosition(pm, "o1", "AAPL", 100, 10, 95, 110)
+	addLongPosition(pm, "o2", "AAPL", 110, 30, 104, 120)
+	addLongPosition(pm, "o3", "MSFT", 300, 2, 290, 320)
+	pm.positions["o2"].EntryTime = pm.positions["o1"].EntryTime.Add(time.Hour)
+	for _, id := range []string{"o1", "o2"} {
+		if err := pm.UpdatePosition(id, 112); err != nil {
+			t.Fatalf("UpdatePosition(%s) error = %v", id, err)
+		}
+	}
+
+	if got := len(pm.GetOpenPositions()); got != 3 {
+		t.Fatalf("GetOpenPositions() without aggregation = %d rows, want 3 lots", got)
+	}
+
+	pm.SetAggregateLots(true)
+	positions := pm.GetOpenPositions()
+	if len(positions) != 2 {
+		t.Fatalf("GetOpenPositions() = %d rows, want AAPL and MSFT", len(positions))
+	}
+
+	aapl := positions[0]
+	if aapl.Symbol != "AAPL" || aapl.Quantity != 40 {
+		t.Fatalf("net position = %s x%d, want AAPL x40", aapl.Symbol, aapl.Quantity)
+	}
+	// (100*10 + 110*30) / 40
+	if math.Abs(aapl.EntryPrice-107.5) > 1e-9 {
+		t.Errorf("EntryPrice = %.4f, want 107.5", aapl.EntryPrice)
+	}
+	// 12*10 + 2*30
+	if math.Abs(aapl.UnrealizedPnL-180) > 1e-9 {
+		t.Errorf("UnrealizedPnL = %.2f, want 180", aapl.UnrealizedPnL)
+	}
+	// risk to the stop matches the lots: 5*10 + 6*30
+	if risk := (aapl.EntryPrice - aapl.StopLossPrice) * float64(aapl.Quantity); math.Abs(risk-230) > 1e-9 {
+		t.Errorf("net risk = %.2f, want 230", risk)
+	}
+	if len(aapl.Lots) != 2 || aapl.Lots[0].OrderID != "o1" || aapl.OrderID != "o1,o2" {
+		t.Errorf("lots = %d, order ID %q, want o1 then o2 kept as detail", len(aapl.Lots), aapl.OrderID)
+	}
+
+	if positions[1] != pm.positions["o3"] {
+		t.Errorf("single MSFT lot should be returned untouched")
+	}
+	if got := pm.CountOpenPositions(); got != 3 {
+		t.Errorf("CountOpenPositions() = %d, want 3 lots toward the position cap", got)
+	}
+	if got := len(pm.GetOpenLots()); got != 3 {
+		t.Errorf("GetOpenLots() = %d, want per-lot detail", got)
+	}
+}
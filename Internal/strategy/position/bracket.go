@@ -0,0 +1,134 @@
+package position
+
+import (
+	"context"
+	"log"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+// a bracket leg that filled at the broker and closed its position
+type BracketLegFill struct {
+	Symbol     string  `json:"symbol"`
+	OrderID    string  `json:"order_id"`     // the position's entry order
+	LegOrderID string  `json:"leg_order_id"` // the leg that filled
+	Reason     string  `json:"reason"`       // STOP_LOSS or TAKE_PROFIT
+	Quantity   int64   `json:"quantity"`
+	Price      float64 `json:"price"`
+}
+
+// records the take-profit/stop legs of bracket entries so a broker-side fill closes the position (off by default)
+func (pm *PositionManager) SetTrackBracketLegs(enabled bool) {
+	pm.trackBracketLegs = enabled
+}
+
+func (p *OpenPosition) hasBracketLegs() bool {
+	return p.BracketTargetOrderID != "" || p.BracketStopOrderID != ""
+}
+
+// a bracket's legs come back on the entry order: the limit leg is the take-profit, the stop (or stop-limit) leg the stop-loss
+func bracketLegIDs(order *alpaca.Order) (targetID, stopID string) {
+	for _, leg := range order.Legs {
+		switch leg.Type {
+		case alpaca.Limit:
+			targetID = leg.ID
+		case alpaca.Stop, alpaca.StopLimit, alpaca.TrailingStop:
+			stopID = leg.ID
+		}
+	}
+	return targetID, stopID
+}
+
+type bracketLeg struct {
+	pos    *OpenPosition
+	id     string
+	reason string
+}
+
+// polls the legs of bracket entries and books any that filled as the position's close, logging the exit trade
+// with a STOP_LOSS or TAKE_PROFIT reason; our own price polling never has to see the move
+func (pm *PositionManager) ReconcileBracketLegs(ctx context.Context) []BracketLegFill {
+	if pm.exitClient == nil {
+		return nil
+	}
+
+	pm.positionsMutex.RLock()
+	var legs []bracketLeg
+	for _, p := range pm.positions {
+		if p.Status == "CLOSED" {
+			continue
+		}
+		if p.BracketTargetOrderID != "" {
+			legs = append(legs, bracketLeg{pos: p, id: p.BracketTargetOrderID, reason: "TAKE_PROFIT"})
+		}
+		if p.BracketStopOrderID != "" {
+			legs = append(legs, bracketLeg{pos: p, id: p.BracketStopOrderID, reason: "STOP_LOSS"})
+		}
+	}
+	pm.positionsMutex.RUnlock()
+
+	var fills []BracketLegFill
+	for _, leg := range legs {
+		order, err := pm.exitClient.GetOrder(leg.id)
+		if err != nil || order == nil || order.Status != "filled" {
+			continue
+		}
+		fill, ok := pm.bookBracketFill(ctx, leg, order)
+		if ok {
+			fills = append(fills, fill)
+		}
+	}
+	return fills
+}
+
+func (pm *PositionManager) bookBracketFill(ctx context.Context, leg bracketLeg, order *alpaca.Order) (BracketLegFill, bool) {
+	pm.positionsMutex.Lock()
+	pos := leg.pos
+	// the sibling leg (or an earlier pass) may have booked this position already
+	if pos.Status == "CLOSED" || (pos.BracketTargetOrderID != leg.id && pos.BracketStopOrderID != leg.id) {
+		pm.positionsMutex.Unlock()
+		return BracketLegFill{}, false
+	}
+	pos.BracketTargetOrderID, pos.BracketStopOrderID = "", ""
+	symbol, direction, quantity := pos.Symbol, pos.Direction, pos.Quantity
+	pm.positionsMutex.Unlock()
+
+	price := 0.0
+	switch {
+	case order.FilledAvgPrice != nil:
+		price = order.FilledAvgPrice.InexactFloat64()
+	case order.LimitPrice != nil:
+		price = order.LimitPrice.InexactFloat64()
+	case order.StopPrice != nil:
+		price = order.StopPrice.InexactFloat64()
+	}
+	if filled := order.FilledQty.IntPart(); filled > 0 {
+		quantity = filled
+	}
+
+	if err := pm.ClosePosition(pos.OrderID, price, leg.reason); err != nil {
+		log.Printf("Failed to book bracket %s fill %s: %v\n", leg.reason, leg.id, err)
+		return BracketLegFill{}, false
+	}
+
+	exitSide := "SELL"
+	if direction == "SHORT" {
+		exitSide = "BUY"
+	}
+	if pm.logExit != nil {
+		if err := pm.logExit(ctx, symbol, exitSide, quantity, decimal.NewFromFloat(price), order.ID, order.Status); err != nil {
+			log.Printf("Warning: Could not log bracket %s exit for %s: %v\n", leg.reason, symbol, err)
+		}
+	}
+
+	log.Printf("BRACKET %s filled: %s x%d @ $%.2f (Leg ID: %s)\n", leg.reason, symbol, quantity, price, leg.id)
+	return BracketLegFill{
+		Symbol:     symbol,
+		OrderID:    pos.OrderID,
+		LegOrderID: leg.id,
+		Reason:     leg.reason,
+		Quantity:   quantity,
+		Price:      price,
+	}, true
+}
@@ -0,0 +1,104 @@
+package position
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+	"github.com/shopspring/decimal"
+)
+
+func bracketEntryOrder() *alpaca.Order {
+	return &alpaca.Order{
+		ID:         "entry-1",
+		Symbol:     "AAPL",
+		OrderClass: alpaca.Bracket,
+		FilledQty:  decimal.NewFromInt(10),
+		CreatedAt:  time.Date(2024, 3, 6, 14, 30, 0, 0, time.UTC),
+		Legs: []alpaca.Order{
+			{ID: "leg-target", Type: alpaca.Limit},
+			{ID: "leg-stop", Type: alpaca.Stop},
+		},
+	}
+}
+
+func TestReconcileBracketLegs_TakeProfitFillClosesPosition(t *testing.T) {
+	pm, client, logged := newAutoExitManager(t, config.AutoExitConfig{Enabled: true})
+	pm.SetTrackBracketLegs(true)
+	pos := pm.AddPosition(bracketEntryOrder(), &types.TradeSignal{Direction: "LONG"}, 100, 95, 110, 105)
+	if pos.BracketTargetOrderID != "leg-target" || pos.BracketStopOrderID != "leg-stop" {
+		t.Fatalf("Bracket legs = %s/%s, want leg-target/leg-stop", pos.BracketTargetOrderID, pos.BracketStopOrderID)
+	}
+
+	// the broker owns the exit, so our own polling must not close it a second time
+	if err := pm.UpdatePosition("entry-1", 111); err != nil {
+		t.Fatalf("UpdatePosition() error = %v", err)
+	}
+	pm.checkExitHits(context.Background())
+	if len(client.closed) != 0 || len(client.placed) != 0 {
+		t.Fatalf("Auto-exit submitted an exit for a bracket position: closed %v, placed %d", client.closed, len(client.placed))
+	}
+
+	fillPrice := decimal.NewFromFloat(110.25)
+	client.orders = map[string]*alpaca.Order{
+		"leg-target": {ID: "leg-target", Status: "filled", FilledQty: decimal.NewFromInt(10), FilledAvgPrice: &fillPrice},
+		"leg-stop":   {ID: "leg-stop", Status: "canceled"},
+	}
+	fills := pm.ReconcileBracketLegs(context.Background())
+
+	if len(fills) != 1 || fills[0].Reason != "TAKE_PROFIT" || fills[0].LegOrderID != "leg-target" || fills[0].Price != 110.25 {
+		t.Fatalf("Fills = %+v, want one TAKE_PROFIT at 110.25", fills)
+	}
+	if pos.Status != "CLOSED" || pos.CurrentPrice != 110.25 {
+		t.Errorf("Position after fill = %s @ %.2f, want CLOSED @ 110.25", pos.Status, pos.CurrentPrice)
+	}
+	if pos.hasBracketLegs() {
+		t.Errorf("Expected bracket legs cleared after the fill")
+	}
+	if len(*logged) != 1 || (*logged)[0] != (loggedExit{symbol: "AAPL", side: "SELL", qty: 10}) {
+		t.Errorf("Logged exits = %+v, want one SELL x10", *logged)
+	}
+	if got := pm.GetDailyLoss(); got != 0 {
+		t.Errorf("Daily loss = %.2f after a winning exit, want 0", got)
+	}
+
+	if again := pm.ReconcileBracketLegs(context.Background()); len(again) != 0 || len(*logged) != 1 {
+		t.Errorf("Second reconcile booked %+v again", again)
+	}
+}
+
+func TestReconcileBracketLegs_StopFillAndTrackingOff(t *testing.T) {
+	pm, client, logged := newAutoExitManager(t, config.AutoExitConfig{})
+	untracked := pm.AddPosition(bracketEntryOrder(), &types.TradeSignal{Direction: "LONG"}, 100, 95, 110, 105)
+	if untracked.hasBracketLegs() {
+		t.Fatalf("Legs recorded with bracket tracking off: %s/%s", untracked.BracketTargetOrderID, untracked.BracketStopOrderID)
+	}
+
+	pm.SetTrackBracketLegs(true)
+	order := bracketEntryOrder()
+	order.ID = "entry-2"
+	pos := pm.AddPosition(order, &types.TradeSignal{Direction: "LONG"}, 100, 95, 110, 105)
+
+	stopPrice := decimal.NewFromFloat(95)
+	client.orders = map[string]*alpaca.Order{
+		"leg-target": {ID: "leg-target", Status: "new"},
+		"leg-stop":   {ID: "leg-stop", Status: "filled", StopPrice: &stopPrice},
+	}
+	fills := pm.ReconcileBracketLegs(context.Background())
+
+	if len(fills) != 1 || fills[0].Reason != "STOP_LOSS" || fills[0].Quantity != 10 {
+		t.Fatalf("Fills = %+v, want one STOP_LOSS x10", fills)
+	}
+	if pos.Status != "CLOSED" || untracked.Status != "OPEN" {
+		t.Errorf("Statuses = %s (tracked) / %s (untracked), want CLOSED / OPEN", pos.Status, untracked.Status)
+	}
+	if len(*logged) != 1 {
+		t.Errorf("Logged exits = %+v, want one", *logged)
+	}
+	if got := pm.GetDailyLoss(); got != -50 {
+		t.Errorf("Daily loss = %.2f, want -50", got)
+	}
+}
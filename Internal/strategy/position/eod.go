@@ -0,0 +1,309 @@
+package position
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+
+	"github.com/fazecat/mogulmaker/Internal/utils"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+const defaultEODMinutesBeforeClose = 15
+
+// one position handled by a flatten pass
+type FlattenResult struct {
+	Symbol   string  `json:"symbol"`
+	Quantity int64   `json:"quantity"`
+	Price    float64 `json:"price"`
+	Error    string  `json:"error,omitempty"`
+}
+
+type FlattenSummary struct {
+	Closed  []FlattenResult `json:"closed"`
+	Failed  []FlattenResult `json:"failed"`
+	Skipped int             `json:"skipped"` // not tagged intraday, or already exiting
+}
+
+// closes every open position (only intraday-tagged ones when intradayOnly), cancelling attached OCO and bracket
+// legs first
+func (pm *PositionManager) FlattenPositions(ctx context.Context, intradayOnly bool, reason string) FlattenSummary {
+	return pm.flatten(ctx, "", intradayOnly, reason)
+}
+
+// closes every open lot of one symbol the same way
+func (pm *PositionManager) FlattenSymbol(ctx context.Context, symbol, reason string) FlattenSummary {
+	return pm.flatten(ctx, symbol, false, reason)
+}
+
+// symbol "" flattens all symbols
+func (pm *PositionManager) flatten(ctx context.Context, onlySymbol string, intradayOnly bool, reason string) FlattenSummary {
+	summary := FlattenSummary{}
+
+	// partially exited positions still hold shares, so they're flattened too
+	pm.positionsMutex.RLock()
+	var held []*OpenPosition
+	heldLots := make(map[string]int) // open lots per symbol, whether this pass flattens them or not
+	for _, pos := range pm.positions {
+		if pos.Status != "OPEN" && pos.Status != "PARTIAL_EXIT" {
+			continue
+		}
+		heldLots[pos.Symbol]++
+		if onlySymbol == "" || pos.Symbol == onlySymbol {
+			held = append(held, pos)
+		}
+	}
+	pm.positionsMutex.RUnlock()
+
+	// lots of one symbol share a broker position, so they're grouped and each symbol is exited once
+	var symbols []string
+	lots := make(map[string][]*OpenPosition)
+	for _, pos := range held {
+		pm.positionsMutex.RLock()
+		symbol, intraday := pos.Symbol, pos.Intraday
+		legIDs := []string{pos.OCOTargetOrderID, pos.OCOStopOrderID, pos.BracketTargetOrderID, pos.BracketStopOrderID}
+		pm.positionsMutex.RUnlock()
+
+		if intradayOnly && !intraday {
+			summary.Skipped++
+			continue
+		}
+
+		// claimExit leaves positions with broker-side exits alone, so their legs go first
+		if pm.exitClient != nil && strings.Join(legIDs, "") != "" {
+			for _, id := range legIDs {
+				if id == "" {
+					continue
+				}
+				if err := pm.exitClient.CancelOrder(id); err != nil {
+					log.Printf("Warning: could not cancel exit leg %s for %s: %v\n", id, symbol, err)
+				}
+			}
+			pm.positionsMutex.Lock()
+			pos.OCOTargetOrderID, pos.OCOStopOrderID = "", ""
+			pos.BracketTargetOrderID, pos.BracketStopOrderID = "", ""
+			pm.positionsMutex.Unlock()
+		}
+
+		// already being exited elsewhere
+		if !pm.claimExit(pos.OrderID) {
+			summary.Skipped++
+			continue
+		}
+		if _, seen := lots[symbol]; !seen {
+			symbols = append(symbols, symbol)
+		}
+		lots[symbol] = append(lots[symbol], pos)
+	}
+
+	for _, symbol := range symbols {
+		group := lots[symbol]
+		err := pm.closeLots(ctx, symbol, group, len(group) == heldLots[symbol], reason)
+		for _, pos := range group {
+			pm.releaseExit(pos.OrderID)
+			pm.positionsMutex.RLock()
+			quantity, price := pos.Quantity, pos.CurrentPrice
+			pm.positionsMutex.RUnlock()
+			if err != nil {
+				summary.Failed = append(summary.Failed, FlattenResult{Symbol: symbol, Quantity: quantity, Error: err.Error()})
+				continue
+			}
+			summary.Closed = append(summary.Closed, FlattenResult{Symbol: symbol, Quantity: quantity, Price: price})
+		}
+	}
+
+	return summary
+}
+
+// exits claimed lots of one symbol with a single broker order, closing the whole position when they are all of
+// it and selling (or covering) their combined quantity otherwise; every lot is then marked closed and the exit
+// logged once
+func (pm *PositionManager) closeLots(ctx context.Context, symbol string, lots []*OpenPosition, whole bool, reason string) error {
+	if pm.exitClient == nil {
+		return fmt.Errorf("alpaca client not initialized")
+	}
+
+	pm.positionsMutex.RLock()
+	direction := lots[0].Direction
+	var quantity int64
+	var value float64
+	for _, pos := range lots {
+		quantity += pos.Quantity
+		value += pos.CurrentPrice * float64(pos.Quantity)
+	}
+	pm.positionsMutex.RUnlock()
+
+	var order *alpaca.Order
+	var err error
+	if whole {
+		order, err = pm.exitClient.ClosePosition(symbol, alpaca.ClosePositionRequest{})
+	} else {
+		side := alpaca.Sell
+		if direction == "SHORT" {
+			side = alpaca.Buy
+		}
+		qty := decimal.NewFromInt(quantity)
+		order, err = pm.exitClient.PlaceOrder(alpaca.PlaceOrderRequest{
+			Symbol:      symbol,
+			Qty:         &qty,
+			Side:        side,
+			Type:        alpaca.Market,
+			TimeInForce: alpaca.Day,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to submit %s exit: %w", reason, err)
+	}
+
+	for _, pos := range lots {
+		pm.positionsMutex.RLock()
+		price := pos.CurrentPrice
+		pm.positionsMutex.RUnlock()
+		if err := pm.ClosePosition(pos.OrderID, price, reason); err != nil {
+			return err
+		}
+	}
+
+	price := 0.0
+	if quantity > 0 {
+		price = value / float64(quantity)
+	}
+	exitSide := "SELL"
+	if direction == "SHORT" {
+		exitSide = "BUY"
+	}
+	if pm.logExit != nil {
+		if err := pm.logExit(ctx, symbol, exitSide, quantity, decimal.NewFromFloat(price), order.ID, order.Status); err != nil {
+			log.Printf("Warning: Could not log %s exit for %s: %v\n", reason, symbol, err)
+		}
+	}
+
+	log.Printf("AUTO-EXIT %s: %s x%d over %d lot(s) @ $%.2f (Order ID: %s)\n", reason, symbol, quantity, len(lots), price, order.ID)
+	return nil
+}
+
+// one-line description of a flatten pass for alerts
+func (s FlattenSummary) String() string {
+	closed := make([]string, 0, len(s.Closed))
+	for _, r := range s.Closed {
+		closed = append(closed, fmt.Sprintf("%s x%d @ $%.2f", r.Symbol, r.Quantity, r.Price))
+	}
+	msg := fmt.Sprintf("Closed %d position(s)", len(s.Closed))
+	if len(closed) > 0 {
+		msg += ": " + strings.Join(closed, ", ")
+	}
+	if len(s.Failed) > 0 {
+		failed := make([]string, 0, len(s.Failed))
+		for _, r := range s.Failed {
+			failed = append(failed, fmt.Sprintf("%s (%s)", r.Symbol, r.Error))
+		}
+		msg += fmt.Sprintf("; %d failed: %s", len(s.Failed), strings.Join(failed, ", "))
+	}
+	if s.Skipped > 0 {
+		msg += fmt.Sprintf("; %d skipped", s.Skipped)
+	}
+	return msg
+}
+
+// flattens positions once per trading day, a configured number of minutes before the regular close
+type EODCloser struct {
+	pm        *PositionManager
+	cfg       config.EODCloseConfig
+	marketCfg *config.Config
+	now       func() time.Time
+	alert     func(FlattenSummary)
+
+	lastRun string // New York date of the last flatten, so it fires once a day
+	mu      sync.Mutex
+}
+
+// now and alert may be nil (time.Now, and a log line)
+func NewEODCloser(pm *PositionManager, cfg *config.Config, now func() time.Time, alert func(FlattenSummary)) *EODCloser {
+	if now == nil {
+		now = time.Now
+	}
+	if alert == nil {
+		alert = func(summary FlattenSummary) {
+			log.Printf("END OF DAY CLOSE: %s\n", summary)
+		}
+	}
+	return &EODCloser{pm: pm, cfg: cfg.EODClose, marketCfg: cfg, now: now, alert: alert}
+}
+
+func (c *EODCloser) minutesBeforeClose() int {
+	if c.cfg.MinutesBeforeClose > 0 {
+		return c.cfg.MinutesBeforeClose
+	}
+	return defaultEODMinutesBeforeClose
+}
+
+// reports whether t falls in today's flatten window and today hasn't been flattened yet;
+// weekends and hours outside the regular session never qualify
+func (c *EODCloser) Due(t time.Time) bool {
+	if !c.cfg.Enabled {
+		return false
+	}
+	minutesLeft, regular := utils.MinutesUntilRegularClose(t, c.marketCfg)
+	if !regular || minutesLeft > c.minutesBeforeClose() {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastRun != tradingDate(t)
+}
+
+// flattens and alerts when due; returns the summary and whether it ran
+func (c *EODCloser) Check(ctx context.Context) (FlattenSummary, bool) {
+	now := c.now()
+	if !c.Due(now) {
+		return FlattenSummary{}, false
+	}
+	c.mu.Lock()
+	c.lastRun = tradingDate(now)
+	c.mu.Unlock()
+
+	if c.pm.client != nil {
+		// pick up positions opened outside this process before flattening
+		if err := c.pm.SyncFromAlpaca(ctx); err != nil {
+			log.Printf("Warning: Could not sync positions before end-of-day close: %v\n", err)
+		}
+	}
+
+	summary := c.pm.FlattenPositions(ctx, c.cfg.IntradayOnly, "EOD_CLOSE")
+	c.alert(summary)
+	return summary, true
+}
+
+// checks every interval until ctx is done
+func (c *EODCloser) Run(ctx context.Context, interval time.Duration) {
+	if !c.cfg.Enabled {
+		return
+	}
+	log.Printf("End-of-day close enabled: flattening %d minutes before the close\n", c.minutesBeforeClose())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("End-of-day close scheduler stopped")
+			return
+		case <-ticker.C:
+			c.Check(ctx)
+		}
+	}
+}
+
+func tradingDate(t time.Time) string {
+	if loc, err := time.LoadLocation("America/New_York"); err == nil {
+		t = t.In(loc)
+	}
+	return t.Format("2006-01-02")
+}
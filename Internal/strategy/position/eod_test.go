@@ -0,0 +1,200 @@
+package position
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+func eodTestConfig(eod config.EODCloseConfig) *config.Config {
+	cfg := &config.Config{EODClose: eod}
+	cfg.Global.MarketHours.PremarketOpen = "04:00"
+	cfg.Global.MarketHours.RegularOpen = "09:30"
+	cfg.Global.MarketHours.RegularClose = "16:00"
+	cfg.Global.MarketHours.AfterhourClose = "20:00"
+	return cfg
+}
+
+func newYorkTime(t *testing.T, year int, month time.Month, day, hour, min int) time.Time {
+	t.Helper()
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	return time.Date(year, month, day, hour, min, 0, 0, loc)
+}
+
+func TestEODCloser_TriggersOnceInWindow(t *testing.T) {
+	pm, client, _ := newAutoExitManager(t, config.AutoExitConfig{})
+	addLongPosition(pm, "o1", "AAPL", 100, 10, 95, 110)
+
+	// Wednesday 2024-03-06, 15:30 New York time
+	clock := &fakeClock{now: newYorkTime(t, 2024, time.March, 6, 15, 30)}
+	var alerts []FlattenSummary
+	closer := NewEODCloser(pm, eodTestConfig(config.EODCloseConfig{Enabled: true, MinutesBeforeClose: 10}), clock.Now,
+		func(summary FlattenSummary) { alerts = append(alerts, summary) })
+
+	if _, ran := closer.Check(context.Background()); ran {
+		t.Fatalf("Closed 30 minutes before the close with a 10 minute window")
+	}
+
+	clock.Advance(21 * time.Minute) // 15:51
+	summary, ran := closer.Check(context.Background())
+	if !ran {
+		t.Fatalf("Expected the close to run 9 minutes before the bell")
+	}
+	if len(summary.Closed) != 1 || summary.Closed[0].Symbol != "AAPL" {
+		t.Errorf("Closed = %+v, want AAPL", summary.Closed)
+	}
+	if len(client.closed) != 1 {
+		t.Errorf("Broker closes = %v, want one", client.closed)
+	}
+
+	clock.Advance(5 * time.Minute)
+	if _, ran := closer.Check(context.Background()); ran {
+		t.Errorf("Close ran twice on the same day")
+	}
+	if len(alerts) != 1 || !strings.Contains(alerts[0].String(), "AAPL x10") {
+		t.Errorf("Alerts = %+v, want one summary naming AAPL x10", alerts)
+	}
+
+	// the next trading day gets its own run
+	clock.Advance(24 * time.Hour)
+	addLongPosition(pm, "o2", "MSFT", 300, 2, 290, 320)
+	if _, ran := closer.Check(context.Background()); !ran {
+		t.Errorf("Expected the close to run again the next trading day")
+	}
+}
+
+func TestEODCloser_SkipsNonTradingDaysAndDisabled(t *testing.T) {
+	pm, _, _ := newAutoExitManager(t, config.AutoExitConfig{})
+	saturday := newYorkTime(t, 2024, time.March, 9, 15, 55)
+	weekday := newYorkTime(t, 2024, time.March, 6, 15, 55)
+
+	enabled := NewEODCloser(pm, eodTestConfig(config.EODCloseConfig{Enabled: true}), nil, nil)
+	if enabled.Due(saturday) {
+		t.Errorf("Close due on a Saturday")
+	}
+	if !enabled.Due(weekday) {
+		t.Errorf("Close not due 5 minutes before a weekday close")
+	}
+	if enabled.Due(newYorkTime(t, 2024, time.March, 6, 16, 30)) {
+		t.Errorf("Close due after the regular session ended")
+	}
+
+	disabled := NewEODCloser(pm, eodTestConfig(config.EODCloseConfig{}), nil, nil)
+	if disabled.Due(weekday) {
+		t.Errorf("Close due while disabled")
+	}
+}
+
+func TestFlattenPositions_FansOutAndHonorsIntradayTag(t *testing.T) {
+	pm, client, logged := newAutoExitManager(t, config.AutoExitConfig{})
+	addLongPosition(pm, "o1", "AAPL", 100, 10, 95, 110)
+	addLongPosition(pm, "o2", "MSFT", 300, 4, 290, 320)
+	addLongPosition(pm, "o3", "TSLA", 200, 3, 190, 220)
+	pm.positions["o3"].OCOTargetOrderID = "tsla-target"
+	pm.positions["o3"].OCOStopOrderID = "tsla-stop"
+	if err := pm.SetIntraday("o1", true); err != nil {
+		t.Fatalf("SetIntraday() error = %v", err)
+	}
+	if err := pm.SetIntraday("o3", true); err != nil {
+		t.Fatalf("SetIntraday() error = %v", err)
+	}
+
+	summary := pm.FlattenPositions(context.Background(), true, "EOD_CLOSE")
+	if len(summary.Closed) != 2 || summary.Skipped != 1 || len(summary.Failed) != 0 {
+		t.Fatalf("Summary = %+v, want 2 closed and MSFT skipped", summary)
+	}
+	if pm.positions["o2"].Status != "OPEN" {
+		t.Errorf("Untagged MSFT was closed by an intraday-only flatten")
+	}
+	if len(client.cancelled) != 2 {
+		t.Errorf("Cancelled = %v, want both TSLA OCO legs", client.cancelled)
+	}
+	if len(*logged) != 2 {
+		t.Errorf("Logged exits = %d, want 2", len(*logged))
+	}
+
+	summary = pm.FlattenPositions(context.Background(), false, "EOD_CLOSE")
+	if len(summary.Closed) != 1 || summary.Closed[0].Symbol != "MSFT" {
+		t.Errorf("Second flatten closed %+v, want only MSFT", summary.Closed)
+	}
+	if len(client.closed) != 3 {
+		t.Errorf("Broker closes = %v, want 3 in total", client.closed)
+	}
+}
+
+func TestFlattenSymbol_CancelsBracketLegs(t *testing.T) {
+	pm, client, logged := newAutoExitManager(t, config.AutoExitConfig{})
+	addLongPosition(pm, "o1", "AAPL", 100, 10, 95, 110)
+	pm.positions["o1"].BracketTargetOrderID = "aapl-target"
+	pm.positions["o1"].BracketStopOrderID = "aapl-stop"
+
+	summary := pm.FlattenSymbol(context.Background(), "AAPL", "NEWS_HALT")
+	if len(summary.Closed) != 1 || summary.Skipped != 0 || len(summary.Failed) != 0 {
+		t.Fatalf("Summary = %+v, want the bracket-entered AAPL closed", summary)
+	}
+	if strings.Join(client.cancelled, ",") != "aapl-target,aapl-stop" {
+		t.Errorf("Cancelled = %v, want both bracket legs", client.cancelled)
+	}
+	pos := pm.positions["o1"]
+	if pos.Status != "CLOSED" || pos.BracketTargetOrderID != "" || pos.BracketStopOrderID != "" {
+		t.Errorf("Position = %s with legs %q/%q, want CLOSED with legs cleared", pos.Status, pos.BracketTargetOrderID, pos.BracketStopOrderID)
+	}
+	if len(client.closed) != 1 || len(*logged) != 1 {
+		t.Errorf("Broker closes = %v, logged exits = %d; want one each", client.closed, len(*logged))
+	}
+}
+
+func TestFlattenPositions_ClosesEachSymbolOnce(t *testing.T) {
+	pm, client, logged := newAutoExitManager(t, config.AutoExitConfig{})
+	addLongPosition(pm, "o1", "AAPL", 100, 10, 95, 110)
+	addLongPosition(pm, "o2", "AAPL", 104, 5, 99, 114)
+	addLongPosition(pm, "o3", "MSFT", 300, 4, 290, 320)
+
+	summary := pm.FlattenPositions(context.Background(), false, "EOD_CLOSE")
+	if len(summary.Closed) != 3 || len(summary.Failed) != 0 {
+		t.Fatalf("Summary = %+v, want all three lots closed", summary)
+	}
+	if len(client.closed) != 2 {
+		t.Errorf("Broker closes = %v, want one per symbol", client.closed)
+	}
+	for _, id := range []string{"o1", "o2", "o3"} {
+		if pm.positions[id].Status != "CLOSED" {
+			t.Errorf("%s = %s, want CLOSED", id, pm.positions[id].Status)
+		}
+	}
+	if len(*logged) != 2 {
+		t.Fatalf("Logged exits = %+v, want one per symbol", *logged)
+	}
+	for _, exit := range *logged {
+		if exit.symbol == "AAPL" && exit.qty != 15 {
+			t.Errorf("AAPL exit logged x%d, want both lots' 15", exit.qty)
+		}
+	}
+}
+
+func TestFlattenPositions_IntradayLotsLeaveOthersHeld(t *testing.T) {
+	pm, client, _ := newAutoExitManager(t, config.AutoExitConfig{})
+	addLongPosition(pm, "o1", "AAPL", 100, 10, 95, 110)
+	addLongPosition(pm, "o2", "AAPL", 104, 5, 99, 114)
+	if err := pm.SetIntraday("o1", true); err != nil {
+		t.Fatalf("SetIntraday() error = %v", err)
+	}
+
+	summary := pm.FlattenPositions(context.Background(), true, "EOD_CLOSE")
+	if len(summary.Closed) != 1 || summary.Skipped != 1 {
+		t.Fatalf("Summary = %+v, want the intraday lot closed and the other skipped", summary)
+	}
+	// closing the broker position would take the untagged lot's shares with it
+	if len(client.closed) != 0 || len(client.placed) != 1 || client.placed[0].Qty.IntPart() != 10 {
+		t.Fatalf("Broker closes = %v, orders = %+v; want a single 10-share sell", client.closed, client.placed)
+	}
+	if pm.positions["o2"].Status != "OPEN" {
+		t.Errorf("Untagged AAPL lot = %s, want OPEN", pm.positions["o2"].Status)
+	}
+}
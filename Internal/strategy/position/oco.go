@@ -0,0 +1,210 @@
+package position
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrPositionNotFound    = errors.New("no open position")
+	ErrOCOQuantityMismatch = errors.New("tracked quantity does not match the broker position")
+	ErrOCOAlreadyAttached  = errors.New("position already has an OCO exit")
+	ErrInvalidOCOLevels    = errors.New("invalid OCO stop/target")
+)
+
+func (p *OpenPosition) hasOCOExit() bool {
+	return p.OCOTargetOrderID != "" || p.OCOStopOrderID != ""
+}
+
+// builds the closing OCO for a position: a limit leg at the target and a stop leg, GTC so it survives the session
+func BuildOCOExitRequest(symbol, direction string, quantity int64, stop, target float64) (alpaca.PlaceOrderRequest, error) {
+	if quantity <= 0 {
+		return alpaca.PlaceOrderRequest{}, fmt.Errorf("%w: quantity must be positive, got %d", ErrInvalidOCOLevels, quantity)
+	}
+	if stop <= 0 || target <= 0 {
+		return alpaca.PlaceOrderRequest{}, fmt.Errorf("%w: stop and target must be positive", ErrInvalidOCOLevels)
+	}
+
+	side := alpaca.Sell
+	if direction == "SHORT" {
+		side = alpaca.Buy
+		if stop <= target {
+			return alpaca.PlaceOrderRequest{}, fmt.Errorf("%w: short stop $%.2f must be above target $%.2f", ErrInvalidOCOLevels, stop, target)
+		}
+	} else if stop >= target {
+		return alpaca.PlaceOrderRequest{}, fmt.Errorf("%w: long stop $%.2f must be below target $%.2f", ErrInvalidOCOLevels, stop, target)
+	}
+
+	qty := decimal.NewFromInt(quantity)
+	targetPrice := decimal.NewFromFloat(target)
+	stopPrice := decimal.NewFromFloat(stop)
+
+	return alpaca.PlaceOrderRequest{
+		Symbol:      symbol,
+		Qty:         &qty,
+		Side:        side,
+		Type:        alpaca.Limit,
+		TimeInForce: alpaca.GTC,
+		LimitPrice:  &targetPrice,
+		OrderClass:  alpaca.OCO,
+		TakeProfit:  &alpaca.TakeProfit{LimitPrice: &targetPrice},
+		StopLoss:    &alpaca.StopLoss{StopPrice: &stopPrice},
+	}, nil
+}
+
+// submits an OCO exit for an open position and records its leg IDs so a fill can cancel the sibling
+func (pm *PositionManager) AttachOCOExit(symbol string, stop, target float64) (*OpenPosition, error) {
+	if pm.exitClient == nil {
+		return nil, fmt.Errorf("alpaca client not initialized")
+	}
+
+	pm.positionsMutex.RLock()
+	var pos *OpenPosition
+	for _, p := range pm.positions {
+		if p.Symbol == symbol && (p.Status == "OPEN" || p.Status == "PARTIAL_EXIT") {
+			pos = p
+			break
+		}
+	}
+	var direction string
+	var quantity int64
+	var attached bool
+	if pos != nil {
+		direction, quantity, attached = pos.Direction, pos.Quantity, pos.hasOCOExit()
+	}
+	pm.positionsMutex.RUnlock()
+
+	if pos == nil {
+		return nil, fmt.Errorf("%w for %s", ErrPositionNotFound, symbol)
+	}
+	if attached {
+		return nil, fmt.Errorf("%w: %s", ErrOCOAlreadyAttached, symbol)
+	}
+
+	brokerPos, err := pm.exitClient.GetPosition(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s position: %w", symbol, err)
+	}
+	if brokerPos == nil || !brokerPos.Qty.Abs().Equal(decimal.NewFromInt(quantity)) {
+		brokerQty := "none"
+		if brokerPos != nil {
+			brokerQty = brokerPos.Qty.String()
+		}
+		return nil, fmt.Errorf("%w: %s tracked x%d, broker %s", ErrOCOQuantityMismatch, symbol, quantity, brokerQty)
+	}
+
+	req, err := BuildOCOExitRequest(symbol, direction, quantity, stop, target)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := pm.exitClient.PlaceOrder(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit OCO exit for %s: %w", symbol, err)
+	}
+
+	pm.positionsMutex.Lock()
+	pos.StopLossPrice = stop
+	pos.TakeProfitPrice = target
+	pos.OCOTargetOrderID, pos.OCOStopOrderID = ocoLegIDs(order)
+	pm.positionsMutex.Unlock()
+
+	log.Printf("OCO exit attached: %s x%d | Target: $%.2f (%s) | Stop: $%.2f (%s)\n",
+		symbol, quantity, target, pos.OCOTargetOrderID, stop, pos.OCOStopOrderID)
+
+	return pos, nil
+}
+
+// the parent of an OCO is the limit leg, the stop leg comes back in Legs
+func ocoLegIDs(order *alpaca.Order) (targetID, stopID string) {
+	targetID = order.ID
+	for _, leg := range order.Legs {
+		if leg.Type == alpaca.Stop || leg.Type == alpaca.StopLimit || stopID == "" {
+			stopID = leg.ID
+		}
+	}
+	return targetID, stopID
+}
+
+// books a filled OCO leg: cancels the sibling and closes the position at the fill price
+func (pm *PositionManager) HandleOCOFill(filledOrderID string, fillPrice float64) error {
+	if filledOrderID == "" {
+		return fmt.Errorf("filled order ID is required")
+	}
+
+	pm.positionsMutex.Lock()
+	var pos *OpenPosition
+	var sibling, reason string
+	for _, p := range pm.positions {
+		switch filledOrderID {
+		case p.OCOTargetOrderID:
+			pos, sibling, reason = p, p.OCOStopOrderID, "OCO_TAKE_PROFIT"
+		case p.OCOStopOrderID:
+			pos, sibling, reason = p, p.OCOTargetOrderID, "OCO_STOP_LOSS"
+		}
+		if pos != nil {
+			break
+		}
+	}
+	if pos != nil {
+		pos.OCOTargetOrderID, pos.OCOStopOrderID = "", ""
+	}
+	pm.positionsMutex.Unlock()
+
+	if pos == nil {
+		return fmt.Errorf("no position has OCO leg %s", filledOrderID)
+	}
+
+	if sibling != "" && pm.exitClient != nil {
+		// Alpaca normally cancels the other leg itself; this covers the window before it does
+		if err := pm.exitClient.CancelOrder(sibling); err != nil {
+			log.Printf("Warning: could not cancel OCO sibling %s for %s: %v\n", sibling, pos.Symbol, err)
+		}
+	}
+
+	return pm.ClosePosition(pos.OrderID, fillPrice, reason)
+}
+
+// polls the legs of attached OCO exits and books any that filled
+func (pm *PositionManager) CheckOCOFills() {
+	if pm.exitClient == nil {
+		return
+	}
+
+	pm.positionsMutex.RLock()
+	var legs []string
+	for _, p := range pm.positions {
+		if p.Status == "CLOSED" {
+			continue
+		}
+		for _, id := range []string{p.OCOTargetOrderID, p.OCOStopOrderID} {
+			if id != "" {
+				legs = append(legs, id)
+			}
+		}
+	}
+	pm.positionsMutex.RUnlock()
+
+	for _, id := range legs {
+		order, err := pm.exitClient.GetOrder(id)
+		if err != nil || order == nil || order.Status != "filled" {
+			continue
+		}
+		price := 0.0
+		switch {
+		case order.FilledAvgPrice != nil:
+			price = order.FilledAvgPrice.InexactFloat64()
+		case order.LimitPrice != nil:
+			price = order.LimitPrice.InexactFloat64()
+		case order.StopPrice != nil:
+			price = order.StopPrice.InexactFloat64()
+		}
+		if err := pm.HandleOCOFill(id, price); err != nil {
+			log.Printf("Failed to book OCO fill %s: %v\n", id, err)
+		}
+	}
+}
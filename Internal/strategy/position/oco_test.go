@@ -0,0 +1,127 @@
+package position
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+	"github.com/shopspring/decimal"
+)
+
+func TestBuildOCOExitRequest(t *testing.T) {
+	req, err := BuildOCOExitRequest("AAPL", "LONG", 10, 95, 110)
+	if err != nil {
+		t.Fatalf("BuildOCOExitRequest() error = %v", err)
+	}
+	if req.OrderClass != alpaca.OCO || req.Side != alpaca.Sell || req.Type != alpaca.Limit || req.TimeInForce != alpaca.GTC {
+		t.Errorf("Unexpected OCO order shape: class=%s side=%s type=%s tif=%s", req.OrderClass, req.Side, req.Type, req.TimeInForce)
+	}
+	if !req.Qty.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("Qty = %s, want 10", req.Qty)
+	}
+	if !req.TakeProfit.LimitPrice.Equal(decimal.NewFromInt(110)) || !req.LimitPrice.Equal(decimal.NewFromInt(110)) {
+		t.Errorf("Target leg = %s, want 110", req.TakeProfit.LimitPrice)
+	}
+	if !req.StopLoss.StopPrice.Equal(decimal.NewFromInt(95)) {
+		t.Errorf("Stop leg = %s, want 95", req.StopLoss.StopPrice)
+	}
+
+	short, err := BuildOCOExitRequest("TSLA", "SHORT", 5, 210, 180)
+	if err != nil {
+		t.Fatalf("BuildOCOExitRequest(short) error = %v", err)
+	}
+	if short.Side != alpaca.Buy {
+		t.Errorf("Short OCO side = %s, want buy", short.Side)
+	}
+
+	invalid := []struct {
+		direction    string
+		qty          int64
+		stop, target float64
+	}{
+		{"LONG", 10, 110, 95},
+		{"SHORT", 10, 180, 210},
+		{"LONG", 0, 95, 110},
+		{"LONG", 10, 0, 110},
+	}
+	for _, c := range invalid {
+		if _, err := BuildOCOExitRequest("X", c.direction, c.qty, c.stop, c.target); !errors.Is(err, ErrInvalidOCOLevels) {
+			t.Errorf("BuildOCOExitRequest(%s x%d, stop %.0f, target %.0f) error = %v, want ErrInvalidOCOLevels",
+				c.direction, c.qty, c.stop, c.target, err)
+		}
+	}
+}
+
+func TestAttachOCOExit_ValidatesPositionAndQuantity(t *testing.T) {
+	pm, client, _ := newAutoExitManager(t, config.AutoExitConfig{})
+	addLongPosition(pm, "o1", "AAPL", 100, 10, 95, 110)
+
+	if _, err := pm.AttachOCOExit("MSFT", 95, 110); !errors.Is(err, ErrPositionNotFound) {
+		t.Errorf("Untracked symbol error = %v, want ErrPositionNotFound", err)
+	}
+
+	client.positions = map[string]*alpaca.Position{"AAPL": {Symbol: "AAPL", Qty: decimal.NewFromInt(8)}}
+	if _, err := pm.AttachOCOExit("AAPL", 95, 110); !errors.Is(err, ErrOCOQuantityMismatch) {
+		t.Errorf("Quantity mismatch error = %v, want ErrOCOQuantityMismatch", err)
+	}
+	if len(client.placed) != 0 {
+		t.Errorf("Expected no order on failed validation, got %d", len(client.placed))
+	}
+}
+
+func TestAttachOCOExit_CancelsSiblingOnFill(t *testing.T) {
+	pm, client, _ := newAutoExitManager(t, config.AutoExitConfig{Enabled: true})
+	addLongPosition(pm, "o1", "AAPL", 100, 10, 90, 120)
+	client.positions = map[string]*alpaca.Position{"AAPL": {Symbol: "AAPL", Qty: decimal.NewFromInt(10)}}
+	client.placeResp = &alpaca.Order{
+		ID:     "oco-target",
+		Symbol: "AAPL",
+		Legs:   []alpaca.Order{{ID: "oco-stop", Type: alpaca.Stop}},
+	}
+
+	pos, err := pm.AttachOCOExit("AAPL", 95, 110)
+	if err != nil {
+		t.Fatalf("AttachOCOExit() error = %v", err)
+	}
+	if pos.OCOTargetOrderID != "oco-target" || pos.OCOStopOrderID != "oco-stop" {
+		t.Errorf("Leg IDs = %s/%s, want oco-target/oco-stop", pos.OCOTargetOrderID, pos.OCOStopOrderID)
+	}
+	if pos.StopLossPrice != 95 || pos.TakeProfitPrice != 110 {
+		t.Errorf("Levels = %.2f/%.2f, want 95/110", pos.StopLossPrice, pos.TakeProfitPrice)
+	}
+	if _, err := pm.AttachOCOExit("AAPL", 95, 110); !errors.Is(err, ErrOCOAlreadyAttached) {
+		t.Errorf("Second attach error = %v, want ErrOCOAlreadyAttached", err)
+	}
+
+	// the broker manages the exit now, so a stop hit must not trigger a second close
+	if err := pm.UpdatePosition("o1", 94); err != nil {
+		t.Fatalf("UpdatePosition() error = %v", err)
+	}
+	pm.checkExitHits(context.Background())
+	if len(client.closed) != 0 {
+		t.Errorf("Auto-exit closed a position with an OCO attached: %v", client.closed)
+	}
+
+	filledPrice := decimal.NewFromFloat(94.5)
+	client.orders = map[string]*alpaca.Order{
+		"oco-target": {ID: "oco-target", Status: "new"},
+		"oco-stop":   {ID: "oco-stop", Status: "filled", FilledAvgPrice: &filledPrice},
+	}
+	pm.CheckOCOFills()
+	pm.CheckOCOFills()
+
+	if len(client.cancelled) != 1 || client.cancelled[0] != "oco-target" {
+		t.Errorf("Cancelled = %v, want [oco-target]", client.cancelled)
+	}
+	if pos.Status != "CLOSED" || pos.CurrentPrice != 94.5 {
+		t.Errorf("Position after fill = %s @ %.2f, want CLOSED @ 94.50", pos.Status, pos.CurrentPrice)
+	}
+	if pos.OCOTargetOrderID != "" || pos.OCOStopOrderID != "" {
+		t.Errorf("Expected leg IDs cleared after fill")
+	}
+	if got := pm.GetDailyLoss(); got != -55 {
+		t.Errorf("Daily loss = %.2f, want -55", got)
+	}
+}
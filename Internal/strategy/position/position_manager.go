@@ -4,14 +4,30 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"sync"
 	"time"
 
 	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	datafeed "github.com/fazecat/mogulmaker/Internal/database"
 	"github.com/fazecat/mogulmaker/Internal/strategy"
 	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+	"github.com/shopspring/decimal"
 )
 
+// order calls the monitor and OCO exits need (*alpaca.Client satisfies it)
+type ExitOrderClient interface {
+	PlaceOrder(req alpaca.PlaceOrderRequest) (*alpaca.Order, error)
+	ClosePosition(symbol string, req alpaca.ClosePositionRequest) (*alpaca.Order, error)
+	CancelOrder(orderID string) error
+	GetOrder(orderID string) (*alpaca.Order, error)
+	GetPosition(symbol string) (*alpaca.Position, error)
+}
+
+// records an executed exit, datafeed.LogTradeExecution by default
+type ExitTradeLogger func(ctx context.Context, symbol string, side string, quantity int64, price decimal.Decimal, alpacaOrderID string, status string) error
+
 // tracks an active trade
 type OpenPosition struct {
 	Symbol               string
@@ -27,6 +43,25 @@ type OpenPosition struct {
 	UnrealizedPnL        float64
 	UnrealizedPnLPercent float64
 	Status               string // "OPEN", "PARTIAL_EXIT", "CLOSED"
+	OCOTargetOrderID     string // limit leg of an attached OCO exit
+	OCOStopOrderID       string // stop leg of an attached OCO exit
+	BracketTargetOrderID string // take-profit leg of the bracket entry this position opened with
+	BracketStopOrderID   string // stop-loss leg of the bracket entry
+	Intraday             bool   // flattened by the end-of-day close when it runs intraday-only
+	TakeProfitTaken      bool   // a take-profit scale-out already sold; the remainder keeps only its stop
+
+	// set once the safe-bail rung sells and the remainder switches from the static take-profit to a trailing one
+	Trailing       bool
+	TrailPercent   float64
+	TrailAnchor    float64 // best price since trailing started (highest for longs, lowest for shorts)
+	TrailStopPrice float64
+
+	// trailing stop-loss: StopLossPrice ratchets to TrailingStopPercent behind the best price seen since entry
+	TrailingStopPercent float64
+	HighWaterMark       float64 // highest price since entry, for longs
+	LowWaterMark        float64 // lowest price since entry, for shorts
+
+	Lots []*OpenPosition // per-lot detail when this is a net position aggregated from several entries
 }
 
 // tracks all open positions and enforces limits
@@ -37,21 +72,108 @@ type PositionManager struct {
 	client         *alpaca.Client
 	dailyLoss      float64
 	dailyLossMutex sync.RWMutex
+
+	autoExit    config.AutoExitConfig
+	exitClient  ExitOrderClient
+	logExit     ExitTradeLogger
+	exiting     map[string]bool // order IDs with an exit in flight, so concurrent monitors never double-submit
+	exitingLock sync.Mutex
+
+	throttle *EntryThrottle // nil means no per-hour/per-day entry cap
+
+	aggregateLots bool // GetOpenPositions nets lots of the same symbol into one position
+
+	recalculateLevels bool // ApplyOrderConfig recomputes open positions' stop/target from the new percents
+
+	trackBracketLegs bool // AddPosition records a bracket entry's legs and ReconcileBracketLegs books their fills
 }
 
 // creates a new position manager
 func NewPositionManager(client *alpaca.Client, cfg *strategy.OrderConfig) *PositionManager {
-	return &PositionManager{
+	pm := &PositionManager{
 		positions: make(map[string]*OpenPosition),
 		config:    cfg,
 		client:    client,
 		dailyLoss: 0,
+		logExit:   datafeed.LogTradeExecution,
+		exiting:   make(map[string]bool),
+	}
+	if client != nil {
+		pm.exitClient = client
+	}
+	return pm
+}
+
+// turns on automatic exits from MonitorPositions (off by default)
+func (pm *PositionManager) SetAutoExit(cfg config.AutoExitConfig) {
+	pm.autoExit = cfg
+	if cfg.Enabled {
+		log.Printf("Auto-exit enabled (take-profit scale-out: %.0f%%)\n", pm.takeProfitScaleOut()*100)
+		if cfg.TrailAfterSafeBail {
+			log.Printf("Safe bail sells %.0f%% and trails the rest\n", pm.safeBailScaleOut()*100)
+		}
+	}
+}
+
+// caps how many positions may be opened per rolling hour/day; the throttle can be shared between managers
+func (pm *PositionManager) SetEntryThrottle(throttle *EntryThrottle) {
+	pm.throttle = throttle
+}
+
+// nets scaled-in lots of the same symbol into one position in GetOpenPositions (off by default)
+func (pm *PositionManager) SetAggregateLots(enabled bool) {
+	pm.aggregateLots = enabled
+}
+
+// reports whether a new position may be opened now, with the reason when it may not
+func (pm *PositionManager) CanOpenPosition() (bool, string) {
+	if cfg := pm.config.Snapshot(); cfg != nil && cfg.MaxOpenPositions > 0 {
+		if open := pm.CountOpenPositions(); open >= cfg.MaxOpenPositions {
+			return false, fmt.Sprintf("Max open positions reached (%d/%d)", open, cfg.MaxOpenPositions)
+		}
+	}
+	if pm.throttle != nil {
+		return pm.throttle.Allow()
+	}
+	return true, ""
+}
+
+// CanOpenPosition and RecordEntry in one step for callers that place the entry order themselves; call release
+// if the order fails so the entry stops counting against the throttle
+func (pm *PositionManager) ReserveEntry() (ok bool, reason string, release func()) {
+	if cfg := pm.config.Snapshot(); cfg != nil && cfg.MaxOpenPositions > 0 {
+		if open := pm.CountOpenPositions(); open >= cfg.MaxOpenPositions {
+			return false, fmt.Sprintf("Max open positions reached (%d/%d)", open, cfg.MaxOpenPositions), func() {}
+		}
+	}
+	if pm.throttle != nil {
+		return pm.throttle.Reserve()
+	}
+	return true, "", func() {}
+}
+
+// counts a newly opened position against the entry throttle
+func (pm *PositionManager) RecordEntry() {
+	if pm.throttle != nil {
+		pm.throttle.Record()
 	}
 }
 
+// tags a tracked position as intraday so an intraday-only end-of-day close flattens it
+func (pm *PositionManager) SetIntraday(orderID string, intraday bool) error {
+	pm.positionsMutex.Lock()
+	defer pm.positionsMutex.Unlock()
+	pos, ok := pm.positions[orderID]
+	if !ok {
+		return fmt.Errorf("%w: order %s", ErrPositionNotFound, orderID)
+	}
+	pos.Intraday = intraday
+	return nil
+}
+
 // adds a new open position
 func (pm *PositionManager) AddPosition(order *alpaca.Order, signal *types.TradeSignal, entryPrice float64,
-	stopLoss float64, takeProfit float64, safeBail float64) *OpenPosition {
+	stopLoss float64, takeProfit float64, safeBail float64, opts ...PositionOption) *OpenPosition {
 
 	pm.positionsMutex.Lock()
 	defer pm.positionsMutex.Unlock()
@@ -73,16 +195,34 @@ func (pm *PositionManager) AddPosition(order *alpaca.Order, signal *types.TradeS
 		CurrentPrice:    entryPrice,
 		Status:          "OPEN",
 	}
+	if pm.trackBracketLegs && order.OrderClass == alpaca.Bracket {
+		position.BracketTargetOrderID, position.BracketStopOrderID = bracketLegIDs(order)
+	}
+	for _, opt := range opts {
+		opt(position)
+	}
 
 	pm.positions[order.ID] = position
+	pm.RecordEntry()
 	log.Printf("✅ Position added: %s x%d @ $%.2f (ID: %s)\n",
 		position.Symbol, position.Quantity, position.EntryPrice, position.OrderID)
 
 	return position
 }
 
-// returns all open positions
+// returns all open positions, netted per symbol when lot aggregation is on
 func (pm *PositionManager) GetOpenPositions() []*OpenPosition {
+	lots := pm.GetOpenLots()
+	if !pm.aggregateLots {
+		return lots
+	}
+	pm.positionsMutex.RLock()
+	defer pm.positionsMutex.RUnlock()
+	return AggregateLots(lots)
+}
+
+// returns every open lot, one per entry order, regardless of lot aggregation
+func (pm *PositionManager) GetOpenLots() []*OpenPosition {
 	pm.positionsMutex.RLock()
 	defer pm.positionsMutex.RUnlock()
 
@@ -97,7 +237,16 @@ func (pm *PositionManager) GetOpenPositions() []*OpenPosition {
 
 // returns number of open trades
 func (pm *PositionManager) CountOpenPositions() int {
-	return len(pm.GetOpenPositions())
+	return len(pm.GetOpenLots())
+}
+
+// dollars at risk if every open lot hit its stop
+func (pm *PositionManager) OpenRiskAmount() float64 {
+	total := 0.0
+	for _, pos := range pm.GetOpenLots() {
+		total += float64(pos.Quantity) * math.Abs(pos.EntryPrice-pos.StopLossPrice)
+	}
+	return total
 }
 
 // updates current price and calculates P&L
@@ -120,6 +269,7 @@ func (pm *PositionManager) UpdatePosition(orderID string, currentPrice float64)
 		position.UnrealizedPnL = (position.EntryPrice - currentPrice) * float64(position.Quantity)
 		position.UnrealizedPnLPercent = ((position.EntryPrice - currentPrice) / position.EntryPrice) * 100
 	}
+	position.ratchetTrailingStop()
 
 	return nil
 }
@@ -131,8 +281,9 @@ func (pm *PositionManager) CheckStopLosses() []*OpenPosition {
 
 	hitStopLoss := make([]*OpenPosition, 0)
 
+	// what a scale-out or safe bail left behind still needs its stop
 	for _, pos := range pm.positions {
-		if pos.Status != "OPEN" {
+		if pos.Status != "OPEN" && pos.Status != "PARTIAL_EXIT" {
 			continue
 		}
 
@@ -160,7 +311,8 @@ func (pm *PositionManager) CheckTakeProfits() []*OpenPosition {
 	hitTakeProfit := make([]*OpenPosition, 0)
 
 	for _, pos := range pm.positions {
-		if pos.Status != "OPEN" {
+		// a trailing position has let go of its static target, and a scaled-out one has already used it
+		if (pos.Status != "OPEN" && pos.Status != "PARTIAL_EXIT") || pos.Trailing || pos.TakeProfitTaken {
 			continue
 		}
 
@@ -335,25 +487,202 @@ func (pm *PositionManager) MonitorPositions(ctx context.Context, checkInterval t
 			log.Println("Position monitor stopped")
 			return
 		case <-ticker.C:
-			// Check stop losses
-			stopLossHits := pm.CheckStopLosses()
-			for _, pos := range stopLossHits {
-				log.Printf("STOP LOSS HIT: %s @ $%.2f - Go to menu option 8 to close\n", pos.Symbol, pos.CurrentPrice)
+			pm.CheckOCOFills()
+			pm.ReconcileBracketLegs(ctx)
+			pm.checkExitHits(ctx)
+		}
+	}
+}
+
+// one monitor pass: closes stop/take-profit hits when auto-exit is on, otherwise just alerts
+func (pm *PositionManager) checkExitHits(ctx context.Context) {
+	// Check stop losses
+	stopLossHits := pm.CheckStopLosses()
+	for _, pos := range stopLossHits {
+		if pm.autoExit.Enabled {
+			if err := pm.autoClose(ctx, pos, 1, "STOP_LOSS"); err != nil {
+				log.Printf("Auto-exit failed for %s: %v\n", pos.Symbol, err)
+			}
+			continue
+		}
+		log.Printf("STOP LOSS HIT: %s @ $%.2f - Go to menu option 8 to close\n", pos.Symbol, pos.CurrentPrice)
+	}
+
+	// Check take profits
+	takeProfitHits := pm.CheckTakeProfits()
+	for _, pos := range takeProfitHits {
+		if pm.autoExit.Enabled {
+			if err := pm.autoClose(ctx, pos, pm.takeProfitScaleOut(), "TAKE_PROFIT"); err != nil {
+				log.Printf("Auto-exit failed for %s: %v\n", pos.Symbol, err)
 			}
+			continue
+		}
+		log.Printf("TAKE PROFIT HIT: %s @ $%.2f - Go to menu option 8 to close\n", pos.Symbol, pos.CurrentPrice)
+	}
 
-			// Check take profits
-			takeProfitHits := pm.CheckTakeProfits()
-			for _, pos := range takeProfitHits {
-				log.Printf("TAKE PROFIT HIT: %s @ $%.2f - Go to menu option 8 to close\n", pos.Symbol, pos.CurrentPrice)
+	// Check safe bails
+	safeBails := pm.CheckSafeBails()
+	for _, pos := range safeBails {
+		if pm.autoExit.Enabled && pm.autoExit.TrailAfterSafeBail {
+			if err := pm.takeSafeBail(ctx, pos); err != nil {
+				log.Printf("Safe bail failed for %s: %v\n", pos.Symbol, err)
 			}
+			continue
+		}
+		log.Printf("💰 SAFE BAIL READY: %s @ $%.2f - Go to menu option 8 to partial exit\n", pos.Symbol, pos.CurrentPrice)
+	}
 
-			// Check safe bails
-			safeBails := pm.CheckSafeBails()
-			for _, pos := range safeBails {
-				log.Printf("💰 SAFE BAIL READY: %s @ $%.2f - Go to menu option 8 to partial exit\n", pos.Symbol, pos.CurrentPrice)
+	// Check trailing take-profits on what a safe bail left behind
+	for _, pos := range pm.CheckTrailingExits() {
+		if pm.autoExit.Enabled {
+			if err := pm.autoClose(ctx, pos, 1, reasonTrailingTakeProfit); err != nil {
+				log.Printf("Auto-exit failed for %s: %v\n", pos.Symbol, err)
 			}
+			continue
+		}
+		log.Printf("TRAILING TAKE PROFIT HIT: %s @ $%.2f - Go to menu option 8 to close\n", pos.Symbol, pos.CurrentPrice)
+	}
+}
+
+func (pm *PositionManager) takeProfitScaleOut() float64 {
+	fraction := pm.autoExit.TakeProfitScaleOut
+	if fraction <= 0 || fraction >= 1 {
+		return 1
+	}
+	return fraction
+}
+
+// submits the exit for a hit position and books it; fraction < 1 scales out instead of closing
+func (pm *PositionManager) autoClose(ctx context.Context, pos *OpenPosition, fraction float64, reason string) error {
+	if pm.exitClient == nil {
+		return fmt.Errorf("alpaca client not initialized")
+	}
+	if !pm.claimExit(pos.OrderID) {
+		return nil
+	}
+	defer pm.releaseExit(pos.OrderID)
+
+	pm.positionsMutex.RLock()
+	symbol, direction, quantity, entryPrice, price := pos.Symbol, pos.Direction, pos.Quantity, pos.EntryPrice, pos.CurrentPrice
+	pm.positionsMutex.RUnlock()
+
+	exitQty := int64(float64(quantity) * fraction)
+	partial := fraction < 1 && exitQty >= 1 && exitQty < quantity
+
+	var order *alpaca.Order
+	var err error
+	if partial {
+		side := alpaca.Sell
+		if direction == "SHORT" {
+			side = alpaca.Buy
+		}
+		qty := decimal.NewFromInt(exitQty)
+		order, err = pm.exitClient.PlaceOrder(alpaca.PlaceOrderRequest{
+			Symbol:      symbol,
+			Qty:         &qty,
+			Side:        side,
+			Type:        alpaca.Market,
+			TimeInForce: alpaca.Day,
+		})
+	} else if pm.otherLotsHeld(pos) {
+		// closing the broker position would take the other lots' shares with it
+		exitQty = quantity
+		side := alpaca.Sell
+		if direction == "SHORT" {
+			side = alpaca.Buy
+		}
+		qty := decimal.NewFromInt(exitQty)
+		order, err = pm.exitClient.PlaceOrder(alpaca.PlaceOrderRequest{
+			Symbol:      symbol,
+			Qty:         &qty,
+			Side:        side,
+			Type:        alpaca.Market,
+			TimeInForce: alpaca.Day,
+		})
+	} else {
+		exitQty = quantity
+		order, err = pm.exitClient.ClosePosition(symbol, alpaca.ClosePositionRequest{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to submit %s exit: %w", reason, err)
+	}
+
+	if partial {
+		if err := pm.PartialExit(pos.OrderID, exitQty, price); err != nil {
+			return err
+		}
+		pm.recordRealizedPnL(direction, entryPrice, price, exitQty)
+		if reason == "TAKE_PROFIT" {
+			pm.positionsMutex.Lock()
+			pos.TakeProfitTaken = true
+			pm.positionsMutex.Unlock()
+		}
+	} else if err := pm.ClosePosition(pos.OrderID, price, reason); err != nil {
+		return err
+	}
+
+	exitSide := "SELL"
+	if direction == "SHORT" {
+		exitSide = "BUY"
+	}
+	if pm.logExit != nil {
+		if err := pm.logExit(ctx, symbol, exitSide, exitQty, decimal.NewFromFloat(price), order.ID, order.Status); err != nil {
+			log.Printf("Warning: Could not log %s exit for %s: %v\n", reason, symbol, err)
 		}
 	}
+
+	log.Printf("AUTO-EXIT %s: %s x%d @ $%.2f (Order ID: %s)\n", reason, symbol, exitQty, price, order.ID)
+	return nil
+}
+
+// whether another tracked lot of pos's symbol still holds shares
+func (pm *PositionManager) otherLotsHeld(pos *OpenPosition) bool {
+	pm.positionsMutex.RLock()
+	defer pm.positionsMutex.RUnlock()
+	for _, other := range pm.positions {
+		if other != pos && other.Symbol == pos.Symbol && (other.Status == "OPEN" || other.Status == "PARTIAL_EXIT") {
+			return true
+		}
+	}
+	return false
+}
+
+// adds a losing partial exit to the daily loss, matching what ClosePosition does for full closes
+func (pm *PositionManager) recordRealizedPnL(direction string, entryPrice, exitPrice float64, quantity int64) {
+	realizedPnL := (exitPrice - entryPrice) * float64(quantity)
+	if direction == "SHORT" {
+		realizedPnL = -realizedPnL
+	}
+	if realizedPnL >= 0 {
+		return
+	}
+	pm.dailyLossMutex.Lock()
+	pm.dailyLoss += realizedPnL
+	pm.dailyLossMutex.Unlock()
+}
+
+func (pm *PositionManager) claimExit(orderID string) bool {
+	pm.exitingLock.Lock()
+	defer pm.exitingLock.Unlock()
+	if pm.exiting[orderID] {
+		return false
+	}
+	pm.positionsMutex.RLock()
+	pos, ok := pm.positions[orderID]
+	// an attached OCO or bracket legs already exit at the broker
+	open := ok && (pos.Status == "OPEN" || pos.Status == "PARTIAL_EXIT") && !pos.hasOCOExit() && !pos.hasBracketLegs()
+	pm.positionsMutex.RUnlock()
+	if !open {
+		return false
+	}
+	pm.exiting[orderID] = true
+	return true
+}
+
+func (pm *PositionManager) releaseExit(orderID string) {
+	pm.exitingLock.Lock()
+	delete(pm.exiting, orderID)
+	pm.exitingLock.Unlock()
 }
 
 // checks and displays alerts when returning to main menu
@@ -386,6 +715,11 @@ func (pm *PositionManager) CheckMenuAlerts() {
 		hasAlerts = true
 	}
 
+	for _, pos := range pm.CheckTrailingExits() {
+		fmt.Printf("TRAILING TAKE PROFIT HIT: %s @ $%.2f\n", pos.Symbol, pos.CurrentPrice)
+		hasAlerts = true
+	}
+
 	if hasAlerts {
 		fmt.Println("\nSelect menu option 8 to close/sell positions")
 		fmt.Println(separator)
@@ -0,0 +1,199 @@
+package position
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+	"github.com/shopspring/decimal"
+)
+
+type mockExitClient struct {
+	placed    []alpaca.PlaceOrderRequest
+	closed    []string
+	cancelled []string
+	orders    map[string]*alpaca.Order
+	positions map[string]*alpaca.Position
+	placeResp *alpaca.Order
+}
+
+func (m *mockExitClient) PlaceOrder(req alpaca.PlaceOrderRequest) (*alpaca.Order, error) {
+	m.placed = append(m.placed, req)
+	if m.placeResp != nil {
+		return m.placeResp, nil
+	}
+	return &alpaca.Order{ID: "exit-partial", Symbol: req.Symbol, Status: "accepted"}, nil
+}
+
+func (m *mockExitClient) CancelOrder(orderID string) error {
+	m.cancelled = append(m.cancelled, orderID)
+	return nil
+}
+
+func (m *mockExitClient) GetOrder(orderID string) (*alpaca.Order, error) {
+	if order, ok := m.orders[orderID]; ok {
+		return order, nil
+	}
+	return nil, fmt.Errorf("order %s not found", orderID)
+}
+
+func (m *mockExitClient) GetPosition(symbol string) (*alpaca.Position, error) {
+	if pos, ok := m.positions[symbol]; ok {
+		return pos, nil
+	}
+	return nil, fmt.Errorf("position %s not found", symbol)
+}
+
+func (m *mockExitClient) ClosePosition(symbol string, req alpaca.ClosePositionRequest) (*alpaca.Order, error) {
+	m.closed = append(m.closed, symbol)
+	return &alpaca.Order{ID: "exit-close", Symbol: symbol, Status: "accepted"}, nil
+}
+
+type loggedExit struct {
+	symbol string
+	side   string
+	qty    int64
+}
+
+func newAutoExitManager(t *testing.T, autoExit config.AutoExitConfig) (*PositionManager, *mockExitClient, *[]loggedExit) {
+	t.Helper()
+	client := &mockExitClient{}
+	logged := &[]loggedExit{}
+
+	pm := NewPositionManager(nil, &strategy.OrderConfig{})
+	pm.exitClient = client
+	pm.logExit = func(ctx context.Context, symbol, side string, quantity int64, price decimal.Decimal, orderID, status string) error {
+		*logged = append(*logged, loggedExit{symbol: symbol, side: side, qty: quantity})
+		return nil
+	}
+	pm.SetAutoExit(autoExit)
+	return pm, client, logged
+}
+
+func addLongPosition(pm *PositionManager, orderID, symbol string, entry float64, qty int64, stop, target float64) {
+	pm.positions[orderID] = &OpenPosition{
+		Symbol:          symbol,
+		OrderID:         orderID,
+		Direction:       "LONG",
+		EntryPrice:      entry,
+		Quantity:        qty,
+		StopLossPrice:   stop,
+		TakeProfitPrice: target,
+		CurrentPrice:    entry,
+		Status:          "OPEN",
+	}
+}
+
+func TestCheckExitHits_StopLossClosesOnce(t *testing.T) {
+	pm, client, logged := newAutoExitManager(t, config.AutoExitConfig{Enabled: true})
+	addLongPosition(pm, "o1", "AAPL", 100, 10, 95, 110)
+	if err := pm.UpdatePosition("o1", 94); err != nil {
+		t.Fatalf("UpdatePosition() error = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		pm.checkExitHits(context.Background())
+	}
+
+	if len(client.closed) != 1 || client.closed[0] != "AAPL" {
+		t.Fatalf("Expected exactly one close for AAPL, got %v", client.closed)
+	}
+	if len(client.placed) != 0 {
+		t.Errorf("Expected no scale-out orders on a stop, got %d", len(client.placed))
+	}
+	if len(*logged) != 1 || (*logged)[0] != (loggedExit{symbol: "AAPL", side: "SELL", qty: 10}) {
+		t.Errorf("Logged exits = %+v, want one SELL x10", *logged)
+	}
+	if pm.positions["o1"].Status != "CLOSED" {
+		t.Errorf("Status = %s, want CLOSED", pm.positions["o1"].Status)
+	}
+	if got := pm.GetDailyLoss(); got != -60 {
+		t.Errorf("Daily loss = %.2f, want -60", got)
+	}
+}
+
+func TestCheckExitHits_TakeProfitScalesOutOnce(t *testing.T) {
+	pm, client, logged := newAutoExitManager(t, config.AutoExitConfig{Enabled: true, TakeProfitScaleOut: 0.5})
+	addLongPosition(pm, "o1", "MSFT", 100, 10, 95, 110)
+	if err := pm.UpdatePosition("o1", 111); err != nil {
+		t.Fatalf("UpdatePosition() error = %v", err)
+	}
+
+	pm.checkExitHits(context.Background())
+	pm.checkExitHits(context.Background())
+
+	if len(client.placed) != 1 {
+		t.Fatalf("Expected exactly one scale-out order, got %d", len(client.placed))
+	}
+	order := client.placed[0]
+	if order.Side != alpaca.Sell || !order.Qty.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("Scale-out order = %s x%s, want sell x5", order.Side, order.Qty)
+	}
+	if len(client.closed) != 0 {
+		t.Errorf("Expected no full close, got %v", client.closed)
+	}
+	if len(*logged) != 1 || (*logged)[0].qty != 5 {
+		t.Errorf("Logged exits = %+v, want one x5", *logged)
+	}
+	pos := pm.positions["o1"]
+	if pos.Quantity != 5 || pos.Status != "PARTIAL_EXIT" || !pos.TakeProfitTaken {
+		t.Errorf("Position after scale-out = x%d %s (taken %v), want x5 PARTIAL_EXIT with the target used", pos.Quantity, pos.Status, pos.TakeProfitTaken)
+	}
+
+	// the remaining shares keep their stop
+	if err := pm.UpdatePosition("o1", 94); err != nil {
+		t.Fatalf("UpdatePosition() error = %v", err)
+	}
+	pm.checkExitHits(context.Background())
+	if len(client.closed) != 1 || pos.Status != "CLOSED" {
+		t.Errorf("Stop on the remainder: closes = %v, status = %s; want the rest closed", client.closed, pos.Status)
+	}
+}
+
+func TestCheckExitHits_StopLeavesOtherLotsHeld(t *testing.T) {
+	pm, client, logged := newAutoExitManager(t, config.AutoExitConfig{Enabled: true})
+	addLongPosition(pm, "o1", "AAPL", 100, 10, 95, 110)
+	addLongPosition(pm, "o2", "AAPL", 90, 4, 80, 120)
+	if err := pm.UpdatePosition("o1", 94); err != nil {
+		t.Fatalf("UpdatePosition() error = %v", err)
+	}
+	if err := pm.UpdatePosition("o2", 94); err != nil {
+		t.Fatalf("UpdatePosition() error = %v", err)
+	}
+
+	pm.checkExitHits(context.Background())
+
+	if len(client.closed) != 0 {
+		t.Fatalf("Broker closes = %v, want none while the o2 lot is held", client.closed)
+	}
+	if len(client.placed) != 1 || client.placed[0].Side != alpaca.Sell || !client.placed[0].Qty.Equal(decimal.NewFromInt(10)) {
+		t.Fatalf("Orders = %+v, want a single sell x10 for the stopped lot", client.placed)
+	}
+	if pm.positions["o1"].Status != "CLOSED" || pm.positions["o2"].Status != "OPEN" {
+		t.Errorf("Statuses = %s/%s, want CLOSED/OPEN", pm.positions["o1"].Status, pm.positions["o2"].Status)
+	}
+	if len(*logged) != 1 || (*logged)[0].qty != 10 {
+		t.Errorf("Logged exits = %+v, want one x10", *logged)
+	}
+}
+
+func TestCheckExitHits_DisabledOnlyAlerts(t *testing.T) {
+	pm, client, logged := newAutoExitManager(t, config.AutoExitConfig{})
+	addLongPosition(pm, "o1", "TSLA", 100, 10, 95, 110)
+	if err := pm.UpdatePosition("o1", 90); err != nil {
+		t.Fatalf("UpdatePosition() error = %v", err)
+	}
+
+	pm.checkExitHits(context.Background())
+
+	if len(client.closed) != 0 || len(client.placed) != 0 || len(*logged) != 0 {
+		t.Errorf("Expected no orders with auto-exit off, got closes=%v orders=%d logs=%d",
+			client.closed, len(client.placed), len(*logged))
+	}
+	if pm.positions["o1"].Status != "OPEN" {
+		t.Errorf("Status = %s, want OPEN", pm.positions["o1"].Status)
+	}
+}
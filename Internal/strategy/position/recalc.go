@@ -0,0 +1,162 @@
+package position
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+)
+
+// protective levels of one position moved by an order config change
+type LevelChange struct {
+	Symbol      string  `json:"symbol"`
+	OrderID     string  `json:"order_id"`
+	OldStop     float64 `json:"old_stop"`
+	NewStop     float64 `json:"new_stop"`
+	OldTarget   float64 `json:"old_target"`
+	NewTarget   float64 `json:"new_target"`
+	OldSafeBail float64 `json:"old_safe_bail"`
+	NewSafeBail float64 `json:"new_safe_bail"`
+	Resubmitted bool    `json:"resubmitted"` // the attached OCO or bracket exit was replaced by an OCO at the new levels
+	Error       string  `json:"error,omitempty"`
+}
+
+// recompute open positions' stop/target/safe-bail when the order config changes (off by default)
+func (pm *PositionManager) SetRecalculateLevels(enabled bool) {
+	pm.recalculateLevels = enabled
+}
+
+// installs a new order config in place (see OrderConfig.Replace), so everything sharing the pointer sees it. With
+// level recalculation on, open positions whose asset-class stop/target/safe-bail percents changed get those levels
+// recomputed from entry, and an attached OCO or bracket exit is cancelled and re-submitted as an OCO at them
+func (pm *PositionManager) ApplyOrderConfig(next strategy.OrderConfig) []LevelChange {
+	pm.positionsMutex.Lock()
+	if pm.config == nil {
+		pm.config = &next
+		pm.positionsMutex.Unlock()
+		return nil
+	}
+	previous := pm.config.Replace(next)
+
+	var held []*OpenPosition
+	if pm.recalculateLevels {
+		for _, pos := range pm.positions {
+			if pos.Status == "OPEN" || pos.Status == "PARTIAL_EXIT" {
+				held = append(held, pos)
+			}
+		}
+	}
+	pm.positionsMutex.Unlock()
+
+	var changes []LevelChange
+	for _, pos := range held {
+		change, changed := pm.recalculateLevelsFor(pos, &previous, &next)
+		if !changed {
+			continue
+		}
+		changes = append(changes, change)
+		if change.Error != "" {
+			log.Printf("Warning: levels for %s recalculated but OCO exit not replaced: %s\n", change.Symbol, change.Error)
+		}
+		resubmitted := ""
+		if change.Resubmitted {
+			resubmitted = " (OCO re-submitted)"
+		}
+		log.Printf("Levels recalculated for %s: stop $%.2f -> $%.2f | target $%.2f -> $%.2f | safe bail $%.2f -> $%.2f%s\n",
+			change.Symbol, change.OldStop, change.NewStop, change.OldTarget, change.NewTarget,
+			change.OldSafeBail, change.NewSafeBail, resubmitted)
+	}
+	return changes
+}
+
+func (pm *PositionManager) recalculateLevelsFor(pos *OpenPosition, previous, next *strategy.OrderConfig) (LevelChange, bool) {
+	pm.positionsMutex.RLock()
+	symbol, direction, entry, quantity := pos.Symbol, pos.Direction, pos.EntryPrice, pos.Quantity
+	change := LevelChange{
+		Symbol:      symbol,
+		OrderID:     pos.OrderID,
+		OldStop:     pos.StopLossPrice,
+		OldTarget:   pos.TakeProfitPrice,
+		OldSafeBail: pos.SafeBailPrice,
+	}
+	trailing := pos.Trailing
+	legIDs := []string{pos.OCOTargetOrderID, pos.OCOStopOrderID, pos.BracketTargetOrderID, pos.BracketStopOrderID}
+	pm.positionsMutex.RUnlock()
+
+	oldCfg, newCfg := previous.ForAsset(symbol, ""), next.ForAsset(symbol, "")
+	if oldCfg.StopLossPercent == newCfg.StopLossPercent && oldCfg.TakeProfitPercent == newCfg.TakeProfitPercent &&
+		oldCfg.SafeBailPercent == newCfg.SafeBailPercent {
+		return LevelChange{}, false
+	}
+	if trailing {
+		// the trailing stop replaced the static levels; its percent comes from auto_exit, not the order config
+		log.Printf("Levels for %s left alone: it is on a trailing stop\n", symbol)
+		return LevelChange{}, false
+	}
+
+	change.NewStop, change.NewTarget = strategy.CalculatePriceTargets(entry, direction, newCfg)
+	change.NewSafeBail = entry * (1 + newCfg.SafeBailPercent/100)
+	if direction == "SHORT" {
+		change.NewSafeBail = entry * (1 - newCfg.SafeBailPercent/100)
+	}
+	if sameLevel(change.NewStop, change.OldStop) && sameLevel(change.NewTarget, change.OldTarget) &&
+		sameLevel(change.NewSafeBail, change.OldSafeBail) {
+		return LevelChange{}, false
+	}
+
+	pm.positionsMutex.Lock()
+	pos.StopLossPrice, pos.TakeProfitPrice, pos.SafeBailPrice = change.NewStop, change.NewTarget, change.NewSafeBail
+	pm.positionsMutex.Unlock()
+
+	if strings.Join(legIDs, "") == "" {
+		return change, true
+	}
+	if pm.exitClient == nil {
+		change.Error = "alpaca client not initialized"
+		return change, true
+	}
+
+	req, err := BuildOCOExitRequest(symbol, direction, quantity, change.NewStop, change.NewTarget)
+	if err != nil {
+		change.Error = err.Error()
+		return change, true
+	}
+	for _, id := range legIDs {
+		if id == "" {
+			continue
+		}
+		if err := pm.exitClient.CancelOrder(id); err != nil && !legCancelled(pm.exitClient, id) {
+			// the old legs may still be live, so don't stack a second OCO on top of them
+			change.Error = fmt.Sprintf("could not cancel exit leg %s: %v", id, err)
+			return change, true
+		}
+	}
+
+	order, err := pm.exitClient.PlaceOrder(req)
+	pm.positionsMutex.Lock()
+	defer pm.positionsMutex.Unlock()
+	// bracket legs are replaced by a standalone OCO, so the position stops tracking them either way
+	pos.BracketTargetOrderID, pos.BracketStopOrderID = "", ""
+	if err != nil {
+		// the old legs are gone; the auto-exit monitor still watches the new in-memory levels
+		pos.OCOTargetOrderID, pos.OCOStopOrderID = "", ""
+		change.Error = fmt.Sprintf("failed to re-submit OCO exit: %v", err)
+		return change, true
+	}
+	pos.OCOTargetOrderID, pos.OCOStopOrderID = ocoLegIDs(order)
+	change.Resubmitted = true
+	return change, true
+}
+
+// cancelling one leg of an OCO or bracket takes its sibling with it, so a failed cancel of an already
+// cancelled leg is no reason to stop
+func legCancelled(client ExitOrderClient, id string) bool {
+	order, err := client.GetOrder(id)
+	return err == nil && order != nil && order.Status == "canceled"
+}
+
+func sameLevel(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
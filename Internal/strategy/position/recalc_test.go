@@ -0,0 +1,101 @@
+package position
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+func exitPercents(stop, target float64) strategy.OrderConfig {
+	return strategy.OrderConfig{StopLossPercent: stop, TakeProfitPercent: target, SafeBailPercent: 3}
+}
+
+func TestApplyOrderConfig_RecalculatesOpenPositions(t *testing.T) {
+	pm, client, _ := newAutoExitManager(t, config.AutoExitConfig{})
+	*pm.config = exitPercents(2, 5)
+	pm.SetRecalculateLevels(true)
+	addLongPosition(pm, "o1", "AAPL", 100, 10, 98, 105)
+	addLongPosition(pm, "o2", "MSFT", 200, 5, 196, 210)
+	pm.positions["o2"].OCOTargetOrderID = "msft-target"
+	pm.positions["o2"].OCOStopOrderID = "msft-stop"
+	client.placeResp = &alpaca.Order{ID: "new-target", Symbol: "MSFT", Legs: []alpaca.Order{{ID: "new-stop", Type: alpaca.Stop}}}
+
+	changes := pm.ApplyOrderConfig(exitPercents(4, 10))
+
+	if pm.config.StopLossPercent != 4 || pm.config.TakeProfitPercent != 10 {
+		t.Errorf("config = %.1f/%.1f, want 4/10", pm.config.StopLossPercent, pm.config.TakeProfitPercent)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("changes = %+v, want one per open position", changes)
+	}
+	aapl := pm.positions["o1"]
+	if math.Abs(aapl.StopLossPrice-96) > 1e-9 || math.Abs(aapl.TakeProfitPrice-110) > 1e-9 {
+		t.Errorf("AAPL levels = %.2f/%.2f, want 96/110", aapl.StopLossPrice, aapl.TakeProfitPrice)
+	}
+	msft := pm.positions["o2"]
+	if math.Abs(msft.StopLossPrice-192) > 1e-9 || math.Abs(msft.TakeProfitPrice-220) > 1e-9 {
+		t.Errorf("MSFT levels = %.2f/%.2f, want 192/220", msft.StopLossPrice, msft.TakeProfitPrice)
+	}
+	if len(client.cancelled) != 2 || len(client.placed) != 1 || client.placed[0].Symbol != "MSFT" {
+		t.Errorf("cancelled %v, placed %d orders; want MSFT's OCO replaced", client.cancelled, len(client.placed))
+	}
+	if msft.OCOTargetOrderID != "new-target" || msft.OCOStopOrderID != "new-stop" {
+		t.Errorf("MSFT legs = %s/%s, want new-target/new-stop", msft.OCOTargetOrderID, msft.OCOStopOrderID)
+	}
+	for _, change := range changes {
+		if change.Symbol == "MSFT" && !change.Resubmitted {
+			t.Errorf("MSFT change = %+v, want Resubmitted", change)
+		}
+		if change.OldStop == change.NewStop || change.Error != "" {
+			t.Errorf("change = %+v, want a moved stop and no error", change)
+		}
+	}
+}
+
+func TestApplyOrderConfig_LeavesLevelsAloneByDefault(t *testing.T) {
+	pm, client, _ := newAutoExitManager(t, config.AutoExitConfig{})
+	*pm.config = exitPercents(2, 5)
+	addLongPosition(pm, "o1", "AAPL", 100, 10, 98, 105)
+
+	if changes := pm.ApplyOrderConfig(exitPercents(4, 10)); len(changes) != 0 {
+		t.Errorf("changes = %+v with recalculation off, want none", changes)
+	}
+	if pm.config.StopLossPercent != 4 {
+		t.Errorf("config stop = %.1f, want the new config installed", pm.config.StopLossPercent)
+	}
+	if pos := pm.positions["o1"]; pos.StopLossPrice != 98 || pos.TakeProfitPrice != 105 {
+		t.Errorf("levels = %.2f/%.2f, want the original 98/105", pos.StopLossPrice, pos.TakeProfitPrice)
+	}
+	if len(client.placed) != 0 || len(client.cancelled) != 0 {
+		t.Errorf("broker calls with recalculation off: placed %d, cancelled %v", len(client.placed), client.cancelled)
+	}
+}
+
+func TestApplyOrderConfig_ResubmitsBracketLegs(t *testing.T) {
+	pm, client, _ := newAutoExitManager(t, config.AutoExitConfig{})
+	*pm.config = exitPercents(2, 5)
+	pm.SetRecalculateLevels(true)
+	addLongPosition(pm, "o1", "AAPL", 100, 10, 98, 105)
+	pm.positions["o1"].BracketTargetOrderID = "aapl-target"
+	pm.positions["o1"].BracketStopOrderID = "aapl-stop"
+	client.placeResp = &alpaca.Order{ID: "new-target", Symbol: "AAPL", Legs: []alpaca.Order{{ID: "new-stop", Type: alpaca.Stop}}}
+
+	changes := pm.ApplyOrderConfig(exitPercents(4, 10))
+
+	if len(changes) != 1 || !changes[0].Resubmitted || changes[0].Error != "" {
+		t.Fatalf("changes = %+v, want the bracket re-submitted", changes)
+	}
+	if len(client.cancelled) != 2 || client.cancelled[0] != "aapl-target" || client.cancelled[1] != "aapl-stop" {
+		t.Errorf("cancelled %v, want both bracket legs", client.cancelled)
+	}
+	aapl := pm.positions["o1"]
+	if aapl.BracketTargetOrderID != "" || aapl.BracketStopOrderID != "" {
+		t.Errorf("bracket legs = %s/%s, want them dropped", aapl.BracketTargetOrderID, aapl.BracketStopOrderID)
+	}
+	if aapl.OCOTargetOrderID != "new-target" || aapl.OCOStopOrderID != "new-stop" {
+		t.Errorf("OCO legs = %s/%s, want new-target/new-stop", aapl.OCOTargetOrderID, aapl.OCOStopOrderID)
+	}
+}
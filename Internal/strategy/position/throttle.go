@@ -0,0 +1,115 @@
+package position
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// caps new entries per rolling hour and day so automated flows can't overtrade; 0 disables a cap
+type EntryThrottle struct {
+	MaxPerHour int
+	MaxPerDay  int
+
+	now     func() time.Time
+	entries []time.Time
+	mu      sync.Mutex
+}
+
+// now is the clock used for the windows, time.Now when nil
+func NewEntryThrottle(maxPerHour, maxPerDay int, now func() time.Time) *EntryThrottle {
+	if now == nil {
+		now = time.Now
+	}
+	return &EntryThrottle{MaxPerHour: maxPerHour, MaxPerDay: maxPerDay, now: now}
+}
+
+// reports whether another entry fits in both windows, with the reason when it doesn't
+func (t *EntryThrottle) Allow() (bool, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.allow(t.now())
+}
+
+// Allow and Record in one step, so concurrent callers can't both take the last slot. release hands the slot
+// back when the entry never happens (its order failed); calling it again does nothing
+func (t *EntryThrottle) Reserve() (ok bool, reason string, release func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if ok, reason := t.allow(now); !ok {
+		return false, reason, func() {}
+	}
+	t.entries = append(t.entries, now)
+
+	var once sync.Once
+	return true, "", func() {
+		once.Do(func() { t.remove(now) })
+	}
+}
+
+// Allow with t.mu held
+func (t *EntryThrottle) allow(now time.Time) (bool, string) {
+	t.prune(now)
+
+	if t.MaxPerHour > 0 {
+		if count, oldest := t.countSince(now.Add(-time.Hour)); count >= t.MaxPerHour {
+			return false, fmt.Sprintf("Hourly entry limit reached (%d/%d), next entry allowed at %s",
+				count, t.MaxPerHour, oldest.Add(time.Hour).Format("15:04:05"))
+		}
+	}
+	if t.MaxPerDay > 0 {
+		if count, oldest := t.countSince(now.Add(-24 * time.Hour)); count >= t.MaxPerDay {
+			return false, fmt.Sprintf("Daily entry limit reached (%d/%d), next entry allowed at %s",
+				count, t.MaxPerDay, oldest.Add(24*time.Hour).Format("2006-01-02 15:04:05"))
+		}
+	}
+	return true, ""
+}
+
+// counts an entry opened now
+func (t *EntryThrottle) Record() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	t.entries = append(t.entries, now)
+	t.prune(now)
+}
+
+// drops one entry recorded at, if it is still in the window
+func (t *EntryThrottle) remove(at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := len(t.entries) - 1; i >= 0; i-- {
+		if t.entries[i].Equal(at) {
+			t.entries = append(t.entries[:i], t.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// entries newer than since, and the oldest of them (whose expiry reopens the window)
+func (t *EntryThrottle) countSince(since time.Time) (int, time.Time) {
+	count := 0
+	var oldest time.Time
+	for _, entry := range t.entries {
+		if entry.After(since) {
+			if count == 0 {
+				oldest = entry
+			}
+			count++
+		}
+	}
+	return count, oldest
+}
+
+// drops entries older than the daily window
+func (t *EntryThrottle) prune(now time.Time) {
+	cutoff := now.Add(-24 * time.Hour)
+	i := 0
+	for i < len(t.entries) && !t.entries[i].After(cutoff) {
+		i++
+	}
+	t.entries = t.entries[i:]
+}
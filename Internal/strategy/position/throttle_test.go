@@ -0,0 +1,118 @@
+package position
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestEntryThrottle_HourlyCapReopensAfterWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)}
+	throttle := NewEntryThrottle(2, 0, clock.Now)
+
+	for i := 0; i < 2; i++ {
+		if ok, reason := throttle.Allow(); !ok {
+			t.Fatalf("Entry %d rejected: %s", i+1, reason)
+		}
+		throttle.Record()
+		clock.Advance(10 * time.Minute)
+	}
+
+	ok, reason := throttle.Allow()
+	if ok {
+		t.Fatalf("Expected third entry within the hour to be rejected")
+	}
+	if !strings.Contains(reason, "Hourly entry limit reached (2/2)") || !strings.Contains(reason, "11:00:00") {
+		t.Errorf("Reason = %q, want hourly limit with next entry at 11:00:00", reason)
+	}
+
+	// the first entry ages out at 11:00, one minute past that the window has room again
+	clock.Advance(41 * time.Minute)
+	if ok, reason := throttle.Allow(); !ok {
+		t.Errorf("Expected entry after the window rolled, got %q", reason)
+	}
+}
+
+func TestEntryThrottle_DailyCap(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC)}
+	throttle := NewEntryThrottle(0, 3, clock.Now)
+
+	for i := 0; i < 3; i++ {
+		throttle.Record()
+		clock.Advance(2 * time.Hour)
+	}
+
+	ok, reason := throttle.Allow()
+	if ok || !strings.Contains(reason, "Daily entry limit reached (3/3)") {
+		t.Fatalf("Allow() = %v, %q, want daily limit rejection", ok, reason)
+	}
+
+	clock.Advance(18 * time.Hour)
+	if ok, reason := throttle.Allow(); !ok {
+		t.Errorf("Expected entry once the oldest fell out of the day, got %q", reason)
+	}
+}
+
+func TestCanOpenPosition_UsesThrottle(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)}
+	pm := NewPositionManager(nil, &strategy.OrderConfig{})
+	pm.SetEntryThrottle(NewEntryThrottle(1, 0, clock.Now))
+
+	if ok, reason := pm.CanOpenPosition(); !ok {
+		t.Fatalf("First entry rejected: %s", reason)
+	}
+	pm.RecordEntry()
+
+	if ok, _ := pm.CanOpenPosition(); ok {
+		t.Errorf("Expected CanOpenPosition to reject once the hourly cap is hit")
+	}
+	clock.Advance(time.Hour + time.Second)
+	if ok, reason := pm.CanOpenPosition(); !ok {
+		t.Errorf("Expected CanOpenPosition to allow after the hour, got %q", reason)
+	}
+}
+
+func TestEntryThrottle_ReserveIsAtomicAndReleasable(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)}
+	throttle := NewEntryThrottle(3, 0, clock.Now)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var releases []func()
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _, release := throttle.Reserve(); ok {
+				mu.Lock()
+				releases = append(releases, release)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(releases) != 3 {
+		t.Fatalf("Concurrent reservations granted = %d, want the cap of 3", len(releases))
+	}
+
+	// a failed order hands its slot back, once
+	releases[0]()
+	releases[0]()
+	if ok, reason, _ := throttle.Reserve(); !ok {
+		t.Fatalf("Reserve() after a release rejected: %s", reason)
+	}
+	if ok, _, _ := throttle.Reserve(); ok {
+		t.Errorf("Expected a second release call not to free another slot")
+	}
+}
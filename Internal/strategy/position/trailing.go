@@ -0,0 +1,135 @@
+package position
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+const (
+	defaultTrailPercent      = 2.0
+	defaultSafeBailScaleOut  = 0.5
+	reasonSafeBail           = "SAFE_BAIL"
+	reasonTrailingTakeProfit = "TRAILING_TAKE_PROFIT"
+)
+
+// configures a position as AddPosition creates it
+type PositionOption func(pos *OpenPosition)
+
+// trails the stop-loss percent behind the best price since entry; the stop passed to AddPosition holds until
+// the trail overtakes it
+func WithTrailingStop(percent float64) PositionOption {
+	return func(pos *OpenPosition) {
+		if percent <= 0 {
+			return
+		}
+		pos.TrailingStopPercent = percent
+		pos.HighWaterMark = pos.EntryPrice
+		pos.LowWaterMark = pos.EntryPrice
+		pos.ratchetTrailingStop()
+	}
+}
+
+// moves the water mark to a new favorable extreme and tightens the stop behind it; the stop never loosens
+func (pos *OpenPosition) ratchetTrailingStop() {
+	if pos.TrailingStopPercent <= 0 {
+		return
+	}
+	if pos.Direction == "SHORT" {
+		if pos.LowWaterMark <= 0 || pos.CurrentPrice < pos.LowWaterMark {
+			pos.LowWaterMark = pos.CurrentPrice
+		}
+		if stop := trailStop(pos.Direction, pos.LowWaterMark, pos.TrailingStopPercent); pos.StopLossPrice <= 0 || stop < pos.StopLossPrice {
+			pos.StopLossPrice = stop
+		}
+		return
+	}
+	if pos.CurrentPrice > pos.HighWaterMark {
+		pos.HighWaterMark = pos.CurrentPrice
+	}
+	if stop := trailStop(pos.Direction, pos.HighWaterMark, pos.TrailingStopPercent); stop > pos.StopLossPrice {
+		pos.StopLossPrice = stop
+	}
+}
+
+// replaces a position's static take-profit with one trailing percent behind the best price seen from now on
+func (pm *PositionManager) StartTrailing(orderID string, percent float64) error {
+	if percent <= 0 {
+		percent = defaultTrailPercent
+	}
+
+	pm.positionsMutex.Lock()
+	defer pm.positionsMutex.Unlock()
+	pos, ok := pm.positions[orderID]
+	if !ok {
+		return fmt.Errorf("%w: order %s", ErrPositionNotFound, orderID)
+	}
+
+	pos.Trailing = true
+	pos.TrailPercent = percent
+	pos.TrailAnchor = pos.CurrentPrice
+	pos.TrailStopPrice = trailStop(pos.Direction, pos.CurrentPrice, percent)
+	log.Printf("Trailing take-profit on %s x%d: %.1f%% behind $%.2f (exit at $%.2f)\n",
+		pos.Symbol, pos.Quantity, percent, pos.TrailAnchor, pos.TrailStopPrice)
+	return nil
+}
+
+// ratchets each trailing position's stop toward its best price and returns those the price has pulled back through
+func (pm *PositionManager) CheckTrailingExits() []*OpenPosition {
+	pm.positionsMutex.Lock()
+	defer pm.positionsMutex.Unlock()
+
+	hit := make([]*OpenPosition, 0)
+	for _, pos := range pm.positions {
+		if !pos.Trailing || (pos.Status != "OPEN" && pos.Status != "PARTIAL_EXIT") {
+			continue
+		}
+
+		improved := pos.CurrentPrice > pos.TrailAnchor
+		if pos.Direction == "SHORT" {
+			improved = pos.CurrentPrice < pos.TrailAnchor
+		}
+		if improved {
+			pos.TrailAnchor = pos.CurrentPrice
+			pos.TrailStopPrice = trailStop(pos.Direction, pos.CurrentPrice, pos.TrailPercent)
+			continue
+		}
+
+		if (pos.Direction == "LONG" && pos.CurrentPrice <= pos.TrailStopPrice) ||
+			(pos.Direction == "SHORT" && pos.CurrentPrice >= pos.TrailStopPrice) {
+			hit = append(hit, pos)
+			log.Printf("📉 TRAILING TAKE PROFIT HIT: %s @ $%.2f (best $%.2f)\n", pos.Symbol, pos.CurrentPrice, pos.TrailAnchor)
+		}
+	}
+	return hit
+}
+
+// books the safe-bail fraction and, when it left shares behind, trails the remainder
+func (pm *PositionManager) takeSafeBail(ctx context.Context, pos *OpenPosition) error {
+	if err := pm.autoClose(ctx, pos, pm.safeBailScaleOut(), reasonSafeBail); err != nil {
+		return err
+	}
+
+	pm.positionsMutex.RLock()
+	remainder := pos.Status == "PARTIAL_EXIT"
+	pm.positionsMutex.RUnlock()
+	if !remainder {
+		return nil
+	}
+	return pm.StartTrailing(pos.OrderID, pm.autoExit.TrailPercent)
+}
+
+func (pm *PositionManager) safeBailScaleOut() float64 {
+	cfg := pm.config.Snapshot()
+	if cfg == nil || cfg.PartialExitPercentage <= 0 || cfg.PartialExitPercentage >= 1 {
+		return defaultSafeBailScaleOut
+	}
+	return cfg.PartialExitPercentage
+}
+
+func trailStop(direction string, anchor, percent float64) float64 {
+	if direction == "SHORT" {
+		return anchor * (1 + percent/100)
+	}
+	return anchor * (1 - percent/100)
+}
@@ -0,0 +1,180 @@
+package position
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+	"github.com/shopspring/decimal"
+)
+
+func TestCheckExitHits_SafeBailThenTrailingExit(t *testing.T) {
+	pm, client, logged := newAutoExitManager(t, config.AutoExitConfig{Enabled: true, TrailAfterSafeBail: true, TrailPercent: 2})
+	addLongPosition(pm, "o1", "NVDA", 100, 10, 95, 110)
+	pm.positions["o1"].SafeBailPrice = 103
+	pos := pm.positions["o1"]
+
+	step := func(price float64) {
+		t.Helper()
+		if err := pm.UpdatePosition("o1", price); err != nil {
+			t.Fatalf("UpdatePosition(%.2f) error = %v", price, err)
+		}
+		pm.checkExitHits(context.Background())
+	}
+
+	// safe bail sells half and starts trailing the rest from 104
+	step(104)
+	if len(client.placed) != 1 || client.placed[0].Side != alpaca.Sell || !client.placed[0].Qty.Equal(decimal.NewFromInt(5)) {
+		t.Fatalf("Safe-bail orders = %+v, want one sell x5", client.placed)
+	}
+	if pos.Status != "PARTIAL_EXIT" || pos.Quantity != 5 || !pos.Trailing {
+		t.Fatalf("After safe bail = %s x%d trailing=%v, want PARTIAL_EXIT x5 trailing", pos.Status, pos.Quantity, pos.Trailing)
+	}
+	if math.Abs(pos.TrailStopPrice-101.92) > 1e-9 {
+		t.Errorf("Initial trail stop = %.2f, want 101.92", pos.TrailStopPrice)
+	}
+
+	// running past the old static target doesn't exit, it ratchets the trail
+	step(112)
+	if len(client.closed) != 0 || len(client.placed) != 1 {
+		t.Fatalf("Exited at 112 past the static target: closes=%v orders=%d", client.closed, len(client.placed))
+	}
+	if math.Abs(pos.TrailStopPrice-109.76) > 1e-9 {
+		t.Errorf("Trail stop after 112 = %.2f, want 109.76", pos.TrailStopPrice)
+	}
+
+	// a pullback that stays above the trail holds, one through it closes the remainder
+	step(110.5)
+	if len(client.closed) != 0 {
+		t.Fatalf("Closed at 110.50 above the 109.76 trail")
+	}
+	step(109.5)
+	if len(client.closed) != 1 || pos.Status != "CLOSED" {
+		t.Fatalf("Trailing exit closes = %v status %s, want one close and CLOSED", client.closed, pos.Status)
+	}
+	if len(*logged) != 2 || (*logged)[0].qty != 5 || (*logged)[1].qty != 5 {
+		t.Errorf("Logged exits = %+v, want two x5 legs", *logged)
+	}
+
+	step(100)
+	if len(client.closed) != 1 {
+		t.Errorf("Closed twice: %v", client.closed)
+	}
+}
+
+func TestCheckExitHits_SafeBailAlertsWithoutTrailFlag(t *testing.T) {
+	pm, client, _ := newAutoExitManager(t, config.AutoExitConfig{Enabled: true})
+	addLongPosition(pm, "o1", "NVDA", 100, 10, 95, 110)
+	pm.positions["o1"].SafeBailPrice = 103
+
+	if err := pm.UpdatePosition("o1", 104); err != nil {
+		t.Fatalf("UpdatePosition() error = %v", err)
+	}
+	pm.checkExitHits(context.Background())
+
+	if len(client.placed) != 0 || pm.positions["o1"].Trailing {
+		t.Errorf("Safe bail acted without trail_after_safe_bail: orders=%d trailing=%v", len(client.placed), pm.positions["o1"].Trailing)
+	}
+}
+
+func TestCheckTrailingExits_Short(t *testing.T) {
+	pm, _, _ := newAutoExitManager(t, config.AutoExitConfig{})
+	addLongPosition(pm, "o1", "TSLA", 200, 4, 210, 180)
+	pos := pm.positions["o1"]
+	pos.Direction = "SHORT"
+	pos.CurrentPrice = 190
+	if err := pm.StartTrailing("o1", 5); err != nil {
+		t.Fatalf("StartTrailing() error = %v", err)
+	}
+
+	pos.CurrentPrice = 180 // new low: trail follows down to 189
+	if hits := pm.CheckTrailingExits(); len(hits) != 0 {
+		t.Fatalf("Trailing short hit on a new low")
+	}
+	if math.Abs(pos.TrailStopPrice-189) > 1e-9 {
+		t.Errorf("Trail stop = %.2f, want 189", pos.TrailStopPrice)
+	}
+	pos.CurrentPrice = 189.5
+	if hits := pm.CheckTrailingExits(); len(hits) != 1 {
+		t.Errorf("Expected the bounce through 189 to trigger the trailing exit")
+	}
+}
+
+func TestTrailingStop_RatchetsUpThenTriggers(t *testing.T) {
+	pm := NewPositionManager(nil, &strategy.OrderConfig{})
+	order := &alpaca.Order{ID: "o1", Symbol: "AAPL", FilledQty: decimal.NewFromInt(10)}
+	pos := pm.AddPosition(order, &types.TradeSignal{Direction: "LONG"}, 100, 90, 0, 0, WithTrailingStop(5))
+
+	// the fixed stop at 90 sits under the 95 trail from entry, so the trail takes over right away
+	if math.Abs(pos.StopLossPrice-95) > 1e-9 || pos.HighWaterMark != 100 {
+		t.Fatalf("Initial stop %.2f / mark %.2f, want 95 / 100", pos.StopLossPrice, pos.HighWaterMark)
+	}
+
+	prices := []float64{104, 110, 108, 120, 115, 114.5, 113.9}
+	wantStops := []float64{98.8, 104.5, 104.5, 114, 114, 114, 114}
+	triggeredAt := -1
+	for i, price := range prices {
+		if err := pm.UpdatePosition("o1", price); err != nil {
+			t.Fatalf("UpdatePosition(%.2f) error = %v", price, err)
+		}
+		if math.Abs(pos.StopLossPrice-wantStops[i]) > 1e-9 {
+			t.Errorf("After %.2f stop = %.2f, want %.2f", price, pos.StopLossPrice, wantStops[i])
+		}
+		if hits := pm.CheckStopLosses(); len(hits) > 0 && triggeredAt < 0 {
+			triggeredAt = i
+		}
+	}
+
+	if triggeredAt != len(prices)-1 {
+		t.Errorf("Trailing stop fired at step %d, want the drop through 114 at step %d", triggeredAt, len(prices)-1)
+	}
+	if pos.HighWaterMark != 120 {
+		t.Errorf("High-water mark = %.2f, want 120", pos.HighWaterMark)
+	}
+}
+
+func TestTrailingStop_ShortRatchetsDown(t *testing.T) {
+	pm := NewPositionManager(nil, &strategy.OrderConfig{})
+	order := &alpaca.Order{ID: "s1", Symbol: "TSLA", FilledQty: decimal.NewFromInt(5)}
+	pos := pm.AddPosition(order, &types.TradeSignal{Direction: "SHORT"}, 200, 204, 0, 0, WithTrailingStop(10))
+
+	// the fixed 204 stop is tighter than the 220 trail from entry, so it holds until the trail overtakes it
+	if pos.StopLossPrice != 204 {
+		t.Fatalf("Initial stop = %.2f, want the fixed 204", pos.StopLossPrice)
+	}
+	for _, price := range []float64{190, 180, 185} {
+		if err := pm.UpdatePosition("s1", price); err != nil {
+			t.Fatalf("UpdatePosition(%.2f) error = %v", price, err)
+		}
+	}
+	if pos.LowWaterMark != 180 || math.Abs(pos.StopLossPrice-198) > 1e-9 {
+		t.Fatalf("Mark %.2f / stop %.2f, want 180 / 198", pos.LowWaterMark, pos.StopLossPrice)
+	}
+	if hits := pm.CheckStopLosses(); len(hits) != 0 {
+		t.Fatalf("Stop fired at 185 under the 198 stop")
+	}
+
+	if err := pm.UpdatePosition("s1", 198.5); err != nil {
+		t.Fatal(err)
+	}
+	if hits := pm.CheckStopLosses(); len(hits) != 1 {
+		t.Errorf("Expected the trailing stop to fire at 198.50, got %d hits", len(hits))
+	}
+}
+
+func TestAddPosition_WithoutTrailingKeepsFixedStop(t *testing.T) {
+	pm := NewPositionManager(nil, &strategy.OrderConfig{})
+	order := &alpaca.Order{ID: "o1", Symbol: "AAPL", FilledQty: decimal.NewFromInt(10)}
+	pos := pm.AddPosition(order, &types.TradeSignal{Direction: "LONG"}, 100, 95, 110, 0)
+
+	if err := pm.UpdatePosition("o1", 130); err != nil {
+		t.Fatal(err)
+	}
+	if pos.StopLossPrice != 95 || pos.TrailingStopPercent != 0 {
+		t.Errorf("Stop = %.2f (trail %.1f%%), want the fixed 95", pos.StopLossPrice, pos.TrailingStopPercent)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/shopspring/decimal"
 
 	datafeed "github.com/fazecat/mogulmaker/Internal/database"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
 	"github.com/fazecat/mogulmaker/Internal/utils/scanner"
 )
 
@@ -41,7 +42,7 @@ func ExecuteTrade(ctx context.Context, client *alpaca.Client, symbol string, qua
 	}
 	*req.Qty = decimal.NewFromInt(quantity)
 
-	order, err := client.PlaceOrder(req)
+	order, err := SubmitOrder(client, req, SubmitRetryFromConfig(config.OrderSubmitConfig{}))
 	if err != nil {
 		return fmt.Errorf("failed to create %s order for %s: %v", signal.Direction, symbol, err)
 	}
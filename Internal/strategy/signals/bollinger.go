@@ -0,0 +1,59 @@
+package signals
+
+import (
+	"math"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// Bollinger band term of the combined signal; set from the bollinger config block
+type BollingerSettings struct {
+	Enabled    bool
+	Period     int
+	StdDevMult float64
+	TrendBars  int // bars the middle band's slope is measured over
+}
+
+var Bollinger = BollingerSettings{Period: 20, StdDevMult: 2, TrendBars: 5}
+
+// a close within this fraction of a band counts as riding it
+const bollingerBandTouch = 0.01
+
+// turns the Bollinger term on or off; non-positive values keep the current period and width
+func SetBollinger(enabled bool, period int, stdDevMult float64) {
+	Bollinger.Enabled = enabled
+	if period > 0 {
+		Bollinger.Period = period
+	}
+	if stdDevMult > 0 {
+		Bollinger.StdDevMult = stdDevMult
+	}
+}
+
+// +2 when the latest close rides the lower band while the middle band is rising (a pullback inside an uptrend),
+// -2 for the mirror image at the upper band in a downtrend, 0 otherwise or without enough oldest-first bars
+func calculateBollingerScore(bars []types.Bar) float64 {
+	if len(bars) < Bollinger.Period+Bollinger.TrendBars {
+		return 0.0
+	}
+	closes := make([]float64, len(bars))
+	for i, bar := range bars {
+		closes[i] = bar.Close
+	}
+	upper, middle, lower, err := indicators.CalculateBollingerBands(closes, Bollinger.Period, Bollinger.StdDevMult)
+	if err != nil {
+		return 0.0
+	}
+
+	last := len(closes) - 1
+	slope := middle[last] - middle[last-Bollinger.TrendBars]
+	price := closes[last]
+	switch {
+	case slope > 0 && math.Abs(price-lower[last]) <= lower[last]*bollingerBandTouch:
+		return 2.0
+	case slope < 0 && math.Abs(price-upper[last]) <= upper[last]*bollingerBandTouch:
+		return -2.0
+	}
+	return 0.0
+}
@@ -0,0 +1,130 @@
+package signals
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
+)
+
+// what to do with a position already held
+const (
+	AdviceHold = "HOLD"
+	AdviceAdd  = "ADD"
+	AdviceTrim = "TRIM"
+	AdviceExit = "EXIT"
+)
+
+// the held position and the levels the advice is judged against
+type PositionContext struct {
+	Direction    string // "LONG" or "SHORT"
+	EntryPrice   float64
+	CurrentPrice float64
+	RSI          float64
+	Support      float64
+	Resistance   float64
+}
+
+type PositionAdvice struct {
+	Action        string   `json:"action"`
+	Direction     string   `json:"direction"`
+	RelativeScore float64  `json:"relative_score"` // ensemble score from the position's side, positive supports it
+	PnLPercent    float64  `json:"pnl_percent"`
+	Reasons       []string `json:"reasons"`
+}
+
+// reads a combined signal relative to an open position: the same engine that picks entries,
+// but a bearish read on a long (or bullish read on a short) argues for trimming or exiting
+func AdvisePosition(pos PositionContext, signal CombinedSignal) PositionAdvice {
+	side := 1.0
+	direction := "LONG"
+	if strings.EqualFold(pos.Direction, "SHORT") {
+		side, direction = -1.0, "SHORT"
+	}
+	against, favoring := "bearish", "bullish"
+	if side < 0 {
+		against, favoring = "bullish", "bearish"
+	}
+
+	advice := PositionAdvice{Direction: direction, RelativeScore: signal.Score * side}
+	if pos.EntryPrice > 0 {
+		advice.PnLPercent = (pos.CurrentPrice - pos.EntryPrice) / pos.EntryPrice * 100 * side
+	}
+
+	// a component's score from the position's side; negative works against it
+	relative := func(name string) float64 {
+		for _, c := range signal.Components {
+			if c.Name == name {
+				return c.Score * side
+			}
+		}
+		return 0
+	}
+
+	var exitReasons, trimReasons []string
+
+	if advice.RelativeScore <= SellThreshold {
+		exitReasons = append(exitReasons, fmt.Sprintf("Signal engine is strongly %s (%.2f from the %s side)", against, advice.RelativeScore, strings.ToLower(direction)))
+	}
+
+	pattern, divergence := relative("Pattern"), relative("Divergence")
+	reversal := pattern <= -2 || divergence <= -1
+	if reversal && advice.RelativeScore < 0 {
+		switch {
+		case pattern < 0 && divergence < 0:
+			exitReasons = append(exitReasons, fmt.Sprintf("%s reversal pattern confirmed by RSI divergence", titleCase(against)))
+		case pattern < 0:
+			exitReasons = append(exitReasons, fmt.Sprintf("Strong %s reversal pattern against the position", against))
+		default:
+			exitReasons = append(exitReasons, fmt.Sprintf("%s RSI divergence against the position", titleCase(against)))
+		}
+	}
+
+	if side > 0 && pos.Support > 0 && pos.CurrentPrice < pos.Support {
+		exitReasons = append(exitReasons, fmt.Sprintf("Price $%.2f broke below support $%.2f", pos.CurrentPrice, pos.Support))
+	}
+	if side < 0 && pos.Resistance > 0 && pos.CurrentPrice > pos.Resistance {
+		exitReasons = append(exitReasons, fmt.Sprintf("Price $%.2f broke above resistance $%.2f", pos.CurrentPrice, pos.Resistance))
+	}
+
+	if advice.RelativeScore <= DistributeThreshold && advice.RelativeScore > SellThreshold {
+		trimReasons = append(trimReasons, fmt.Sprintf("Signal leans %s (%.2f from the %s side)", against, advice.RelativeScore, strings.ToLower(direction)))
+	}
+	if side > 0 && pos.RSI >= 70 {
+		trimReasons = append(trimReasons, fmt.Sprintf("RSI %.1f is overbought, lock in part of the gain", pos.RSI))
+	}
+	if side < 0 && pos.RSI > 0 && pos.RSI <= 30 {
+		trimReasons = append(trimReasons, fmt.Sprintf("RSI %.1f is oversold, lock in part of the gain", pos.RSI))
+	}
+	nearOpposing := false
+	if side > 0 && pos.Resistance > 0 && indicators.IsAtResistance(pos.CurrentPrice, pos.Resistance) {
+		nearOpposing = true
+		trimReasons = append(trimReasons, fmt.Sprintf("Price is pressing into resistance $%.2f", pos.Resistance))
+	}
+	if side < 0 && pos.Support > 0 && indicators.IsAtSupport(pos.CurrentPrice, pos.Support) {
+		nearOpposing = true
+		trimReasons = append(trimReasons, fmt.Sprintf("Price is pressing into support $%.2f", pos.Support))
+	}
+
+	switch {
+	case len(exitReasons) > 0:
+		advice.Action, advice.Reasons = AdviceExit, exitReasons
+	case len(trimReasons) > 0:
+		advice.Action, advice.Reasons = AdviceTrim, trimReasons
+	case advice.RelativeScore >= BuyThreshold && !nearOpposing && advice.PnLPercent >= 0:
+		// only add to winners; a losing position with a strong signal is held, not averaged down
+		advice.Action = AdviceAdd
+		advice.Reasons = []string{fmt.Sprintf("Signal is strongly %s (%.2f) and the position is in profit", favoring, advice.RelativeScore)}
+	default:
+		advice.Action = AdviceHold
+		advice.Reasons = []string{fmt.Sprintf("No %s signal strong enough to act on (%.2f)", against, advice.RelativeScore)}
+	}
+	return advice
+}
+
+func titleCase(word string) string {
+	if word == "" {
+		return word
+	}
+	return strings.ToUpper(word[:1]) + word[1:]
+}
@@ -0,0 +1,71 @@
+package signals
+
+import (
+	"strings"
+	"testing"
+)
+
+// bearish engulfing-style reversal after a run-up: overbought RSI, strong bearish candle, bearish divergence
+func bearishReversalSignal() CombinedSignal {
+	components := []SignalComponent{
+		{Name: "RSI", Score: -3, Weight: DefaultSignalWeights["RSI"]},
+		{Name: "Pattern", Score: -2, Weight: DefaultSignalWeights["Pattern"]},
+		{Name: "Divergence", Score: -2, Weight: DefaultSignalWeights["Divergence"]},
+	}
+	score := 0.0
+	for _, c := range components {
+		score += c.Score * c.Weight
+	}
+	recommendation, reasoning := MapScoreToRecommendation(score)
+	return CombinedSignal{Recommendation: recommendation, Score: score, Reasoning: reasoning, Components: components}
+}
+
+func TestAdvisePosition_BearishReversalExitsLong(t *testing.T) {
+	signal := bearishReversalSignal()
+	pos := PositionContext{Direction: "LONG", EntryPrice: 100, CurrentPrice: 112, RSI: 74, Support: 95, Resistance: 130}
+
+	advice := AdvisePosition(pos, signal)
+	if advice.Action != AdviceExit {
+		t.Fatalf("Action = %s (%v), want EXIT", advice.Action, advice.Reasons)
+	}
+	if advice.RelativeScore >= 0 {
+		t.Errorf("RelativeScore = %.2f, want negative for a bearish read on a long", advice.RelativeScore)
+	}
+	if len(advice.Reasons) == 0 || !strings.Contains(advice.Reasons[len(advice.Reasons)-1], "reversal") {
+		t.Errorf("Reasons = %v, want the reversal named", advice.Reasons)
+	}
+	if advice.PnLPercent < 11.9 || advice.PnLPercent > 12.1 {
+		t.Errorf("PnLPercent = %.2f, want 12", advice.PnLPercent)
+	}
+}
+
+func TestAdvisePosition_SameSignalSupportsShort(t *testing.T) {
+	pos := PositionContext{Direction: "SHORT", EntryPrice: 115, CurrentPrice: 112, RSI: 55, Support: 95, Resistance: 130}
+
+	advice := AdvisePosition(pos, bearishReversalSignal())
+	if advice.Action != AdviceAdd && advice.Action != AdviceHold {
+		t.Errorf("Action = %s (%v), want the bearish read to back a short", advice.Action, advice.Reasons)
+	}
+	if advice.RelativeScore <= 0 {
+		t.Errorf("RelativeScore = %.2f, want positive for a bearish read on a short", advice.RelativeScore)
+	}
+}
+
+func TestAdvisePosition_HoldTrimAndBrokenSupport(t *testing.T) {
+	neutral := CombinedSignal{Score: 0.2, Recommendation: RecommendationWait}
+
+	hold := AdvisePosition(PositionContext{Direction: "LONG", EntryPrice: 100, CurrentPrice: 104, RSI: 55, Support: 95, Resistance: 130}, neutral)
+	if hold.Action != AdviceHold {
+		t.Errorf("neutral signal = %s (%v), want HOLD", hold.Action, hold.Reasons)
+	}
+
+	trim := AdvisePosition(PositionContext{Direction: "LONG", EntryPrice: 100, CurrentPrice: 129.5, RSI: 60, Support: 95, Resistance: 130}, neutral)
+	if trim.Action != AdviceTrim {
+		t.Errorf("long at resistance = %s (%v), want TRIM", trim.Action, trim.Reasons)
+	}
+
+	broken := AdvisePosition(PositionContext{Direction: "LONG", EntryPrice: 100, CurrentPrice: 94, RSI: 40, Support: 95, Resistance: 130}, neutral)
+	if broken.Action != AdviceExit {
+		t.Errorf("long below support = %s (%v), want EXIT", broken.Action, broken.Reasons)
+	}
+}
@@ -0,0 +1,58 @@
+package signals
+
+import (
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// fast/slow RSI crossover term of the combined signal; set from the rsi_crossover config block
+type RSICrossoverSettings struct {
+	Enabled    bool
+	FastPeriod int
+	SlowPeriod int
+	Lookback   int // bars a cross keeps counting after it happens
+}
+
+var RSICrossover = RSICrossoverSettings{FastPeriod: 5, SlowPeriod: 14, Lookback: 3}
+
+// turns the crossover term on or off; non-positive periods keep the current ones
+func SetRSICrossover(enabled bool, fastPeriod, slowPeriod, lookback int) {
+	RSICrossover.Enabled = enabled
+	if fastPeriod > 0 {
+		RSICrossover.FastPeriod = fastPeriod
+	}
+	if slowPeriod > 0 {
+		RSICrossover.SlowPeriod = slowPeriod
+	}
+	if lookback > 0 {
+		RSICrossover.Lookback = lookback
+	}
+}
+
+// latest fast/slow RSI cross with the current settings; false when the bars don't cover the slow period
+func DetectRSICrossover(bars []types.Bar) (indicators.RSICrossover, bool) {
+	closes := make([]float64, len(bars))
+	for i, bar := range bars {
+		closes[i] = bar.Close
+	}
+	fast, slow, err := indicators.CalculateRSIPair(closes, RSICrossover.FastPeriod, RSICrossover.SlowPeriod)
+	if err != nil {
+		return indicators.RSICrossover{}, false
+	}
+	return indicators.DetectRSICrossover(fast, slow, RSICrossover.FastPeriod, RSICrossover.SlowPeriod, RSICrossover.Lookback), true
+}
+
+// +2 for a recent bullish cross, -2 for a bearish one, 0 otherwise or without enough bars
+func calculateRSICrossoverScore(bars []types.Bar) float64 {
+	crossover, ok := DetectRSICrossover(bars)
+	if !ok {
+		return 0.0
+	}
+	switch crossover.Direction {
+	case "BULLISH":
+		return 2.0
+	case "BEARISH":
+		return -2.0
+	}
+	return 0.0
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/fazecat/mogulmaker/Internal/strategy/detection"
 	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
 	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
 )
 
 // Default weights for signal components
@@ -16,8 +17,14 @@ var DefaultSignalWeights = map[string]float64{
 	"Pattern":            0.18,
 	"Support/Resistance": 0.10,
 	"Divergence":         0.15,
+	"RSI Crossover":      0.15, // only applied when RSICrossover.Enabled
+	"Bollinger":          0.12, // only applied when Bollinger.Enabled
 }
 
+// drops components that contributed nothing (no whale events, no pattern, price away from S/R)
+// from CombinedSignal.Components; set from features.omit_zero_signal_components
+var OmitZeroComponents = false
+
 // Recommendation thresholds - single source of truth
 const (
 	BuyThreshold        = 1.5
@@ -59,6 +66,9 @@ type MultiTimeframeSignal struct {
 	CompositeScore   float64
 	Confidence       float64
 	RecommendedTrade string
+
+	PrimaryTimeframe   string // timeframe that leads the recommended trade
+	SecondaryTimeframe string // the one that has to agree with it
 }
 
 // converts RSI value into score
@@ -116,7 +126,8 @@ func calculateWhaleScore(symbol string, bars []types.Bar) float64 {
 	return 0.0
 }
 
-func calculatePatternScore(analysis string) float64 {
+// -2..2 from a single-candle analysis label (Strong Bullish, Bearish Engulfing, ...)
+func PatternScore(analysis string) float64 {
 	switch analysis {
 	case "Strong Bullish", "Bullish Hammer":
 		return 2.0
@@ -132,7 +143,8 @@ func calculatePatternScore(analysis string) float64 {
 	return 0.0
 }
 
-func calculateSRScore(bars []types.Bar) float64 {
+// 1 at support, -1 at resistance, 0 in between
+func SRScore(bars []types.Bar) float64 {
 	support := indicators.FindSupport(bars)
 	resistance := indicators.FindResistance(bars)
 	currentPrice := bars[len(bars)-1].Close
@@ -214,7 +226,49 @@ func CalculateSignal(
 	analysis string,
 	rsiValues []float64,
 ) CombinedSignal {
+	return CalculateSignalWithWeights(rsiValue, atrValue, bars, symbol, analysis, rsiValues, nil)
+}
+
+// DefaultSignalWeights overlaid with the given weights; keys that are missing keep their default
+func resolveSignalWeights(weights map[string]float64) map[string]float64 {
+	resolved := make(map[string]float64, len(DefaultSignalWeights))
+	for name, weight := range DefaultSignalWeights {
+		resolved[name] = weight
+	}
+	for name, weight := range weights {
+		resolved[name] = weight
+	}
+	return resolved
+}
 
+// a profile's signal_weights keyed like DefaultSignalWeights. Volume and news sentiment have no component in the
+// combined signal, and unset (zero) weights are left out so the defaults apply
+func ProfileSignalWeights(w config.SignalWeights) map[string]float64 {
+	weights := map[string]float64{}
+	if w.RSIWeight > 0 {
+		weights["RSI"] = w.RSIWeight
+	}
+	if w.ATRWeight > 0 {
+		weights["ATR"] = w.ATRWeight
+	}
+	if w.WhaleActivityWeight > 0 {
+		weights["Whale"] = w.WhaleActivityWeight
+	}
+	return weights
+}
+
+// CalculateSignal with the component weights taken from weights, falling back to DefaultSignalWeights for any
+// component it doesn't name
+func CalculateSignalWithWeights(
+	rsiValue *float64,
+	atrValue *float64,
+	bars []types.Bar,
+	symbol string,
+	analysis string,
+	rsiValues []float64,
+	weights map[string]float64,
+) CombinedSignal {
+	weights = resolveSignalWeights(weights)
 	components := []SignalComponent{}
 
 	rsiScore := 0.0
@@ -223,7 +277,7 @@ func CalculateSignal(
 		components = append(components, SignalComponent{
 			Name:   "RSI",
 			Score:  rsiScore,
-			Weight: DefaultSignalWeights["RSI"],
+			Weight: weights["RSI"],
 		})
 	}
 
@@ -233,7 +287,7 @@ func CalculateSignal(
 		components = append(components, SignalComponent{
 			Name:   "ATR",
 			Score:  atrScore,
-			Weight: DefaultSignalWeights["ATR"],
+			Weight: weights["ATR"],
 		})
 	}
 
@@ -241,21 +295,21 @@ func CalculateSignal(
 	components = append(components, SignalComponent{
 		Name:   "Whale",
 		Score:  whaleScore,
-		Weight: DefaultSignalWeights["Whale"],
+		Weight: weights["Whale"],
 	})
 
-	patternScore := calculatePatternScore(analysis)
+	patternScore := PatternScore(analysis)
 	components = append(components, SignalComponent{
 		Name:   "Pattern",
 		Score:  patternScore,
-		Weight: DefaultSignalWeights["Pattern"],
+		Weight: weights["Pattern"],
 	})
 
-	srScore := calculateSRScore(bars)
+	srScore := SRScore(bars)
 	components = append(components, SignalComponent{
 		Name:   "Support/Resistance",
 		Score:  srScore,
-		Weight: DefaultSignalWeights["Support/Resistance"],
+		Weight: weights["Support/Resistance"],
 	})
 
 	// Calculate divergence score if enough RSI data is available
@@ -266,16 +320,44 @@ func CalculateSignal(
 		components = append(components, SignalComponent{
 			Name:   "Divergence",
 			Score:  divergenceScore,
-			Weight: DefaultSignalWeights["Divergence"],
+			Weight: weights["Divergence"],
 		})
 	}
 
-	ensembleScore := (rsiScore * DefaultSignalWeights["RSI"]) +
-		(atrScore * DefaultSignalWeights["ATR"]) +
-		(whaleScore * DefaultSignalWeights["Whale"]) +
-		(patternScore * DefaultSignalWeights["Pattern"]) +
-		(srScore * DefaultSignalWeights["Support/Resistance"]) +
-		(divergenceScore * DefaultSignalWeights["Divergence"])
+	// fast/slow RSI cross turns earlier than the single 14-period RSI
+	crossoverScore := 0.0
+	if RSICrossover.Enabled {
+		crossoverScore = calculateRSICrossoverScore(bars)
+		components = append(components, SignalComponent{
+			Name:   "RSI Crossover",
+			Score:  crossoverScore,
+			Weight: weights["RSI Crossover"],
+		})
+	}
+
+	// lower-band pullbacks in an uptrend
+	bollingerScore := 0.0
+	if Bollinger.Enabled {
+		bollingerScore = calculateBollingerScore(bars)
+		components = append(components, SignalComponent{
+			Name:   "Bollinger",
+			Score:  bollingerScore,
+			Weight: weights["Bollinger"],
+		})
+	}
+
+	ensembleScore := (rsiScore * weights["RSI"]) +
+		(atrScore * weights["ATR"]) +
+		(whaleScore * weights["Whale"]) +
+		(patternScore * weights["Pattern"]) +
+		(srScore * weights["Support/Resistance"]) +
+		(divergenceScore * weights["Divergence"]) +
+		(crossoverScore * weights["RSI Crossover"]) +
+		(bollingerScore * weights["Bollinger"])
+
+	if OmitZeroComponents {
+		components = activeComponents(components)
+	}
 
 	recommendation, reasoning := MapScoreToRecommendation(ensembleScore)
 
@@ -313,6 +395,29 @@ func CalculateSignal(
 	}
 }
 
+// components that moved the score, in their original order
+func activeComponents(components []SignalComponent) []SignalComponent {
+	active := make([]SignalComponent, 0, len(components))
+	for _, c := range components {
+		if c.Score != 0 {
+			active = append(active, c)
+		}
+	}
+	return active
+}
+
+// how many non-zero components point the same way as the ensemble score, out of all non-zero ones;
+// zero-contribution components never count, whether or not they were omitted
+func (s CombinedSignal) ComponentAlignment() (aligned, active int) {
+	for _, c := range activeComponents(s.Components) {
+		active++
+		if (c.Score > 0 && s.Score > 0) || (c.Score < 0 && s.Score < 0) {
+			aligned++
+		}
+	}
+	return aligned, active
+}
+
 func ConvertToTradeSignal(combined CombinedSignal) *types.TradeSignal {
 	// Map recommendation to direction
 	direction := RecommendationWait
@@ -330,22 +435,37 @@ func ConvertToTradeSignal(combined CombinedSignal) *types.TradeSignal {
 }
 
 func CombineMultiTimeframeSignals(daily, fourHour, oneHour CombinedSignal) MultiTimeframeSignal {
-	result := MultiTimeframeSignal{
-		DailySignal:      daily,
-		FourHourSignal:   fourHour,
-		OneHourSignal:    oneHour,
-		Alignment:        false,
-		AlignmentPercent: 0.0,
-	}
+	return CombineMultiTimeframeSignalsWithWeights(daily, fourHour, oneHour, MultiTimeframeWeights)
+}
+
+func isBullish(s CombinedSignal) bool {
+	return s.Recommendation == RecommendationBuy || s.Recommendation == RecommendationAccumulate
+}
 
-	dailyBullish := daily.Recommendation == RecommendationBuy || daily.Recommendation == RecommendationAccumulate
-	dailyBearish := daily.Recommendation == RecommendationSell || daily.Recommendation == RecommendationDistribute
+func isBearish(s CombinedSignal) bool {
+	return s.Recommendation == RecommendationSell || s.Recommendation == RecommendationDistribute
+}
+
+// the composite blends the scores by weight; the timeframes align when the primary and secondary point the
+// same way and the third doesn't point against them, and the recommended trade follows the primary
+func CombineMultiTimeframeSignalsWithWeights(daily, fourHour, oneHour CombinedSignal, weights TimeframeWeights) MultiTimeframeSignal {
+	if weights.Validate() != nil {
+		weights = MultiTimeframeWeights
+	}
 
-	fourHourBullish := fourHour.Recommendation == RecommendationBuy || fourHour.Recommendation == RecommendationAccumulate
-	fourHourBearish := fourHour.Recommendation == RecommendationSell || fourHour.Recommendation == RecommendationDistribute
+	result := MultiTimeframeSignal{
+		DailySignal:        daily,
+		FourHourSignal:     fourHour,
+		OneHourSignal:      oneHour,
+		Alignment:          false,
+		AlignmentPercent:   0.0,
+		PrimaryTimeframe:   weights.Primary,
+		SecondaryTimeframe: weights.secondary(),
+	}
 
-	oneHourBullish := oneHour.Recommendation == RecommendationBuy || oneHour.Recommendation == RecommendationAccumulate
-	oneHourBearish := oneHour.Recommendation == RecommendationSell || oneHour.Recommendation == RecommendationDistribute
+	dailyBullish, dailyBearish := isBullish(daily), isBearish(daily)
+	fourHourBullish, fourHourBearish := isBullish(fourHour), isBearish(fourHour)
+	oneHourBullish, oneHourBearish := isBullish(oneHour), isBearish(oneHour)
 
 	alignedCount := 0
 	totalTimeframes := 3
@@ -362,19 +482,25 @@ func CombineMultiTimeframeSignals(daily, fourHour, oneHour CombinedSignal) Multi
 
 	result.AlignmentPercent = (float64(alignedCount) / float64(totalTimeframes)) * 100.0
 
-	result.Alignment = alignedCount >= 2
+	primary := result.signalFor(result.PrimaryTimeframe)
+	secondary := result.signalFor(result.SecondaryTimeframe)
+	third := result.signalFor(thirdTimeframe(result.PrimaryTimeframe, result.SecondaryTimeframe))
+
+	bullishAligned := isBullish(primary) && isBullish(secondary) && !isBearish(third)
+	bearishAligned := isBearish(primary) && isBearish(secondary) && !isBullish(third)
+	result.Alignment = bullishAligned || bearishAligned
 
-	result.CompositeScore = (daily.Score * 0.5) + (fourHour.Score * 0.35) + (oneHour.Score * 0.15)
+	dailyWeight, fourHourWeight, oneHourWeight := weights.normalized()
+	result.CompositeScore = (daily.Score * dailyWeight) + (fourHour.Score * fourHourWeight) + (oneHour.Score * oneHourWeight)
 
 	result.Confidence = (daily.Confidence + fourHour.Confidence + oneHour.Confidence) / 3.0
 
-	if result.Alignment {
-		if dailyBullish && fourHourBullish {
-			result.RecommendedTrade = "BUY"
-		} else if dailyBearish && fourHourBearish {
-			result.RecommendedTrade = "SELL"
-		}
-	} else {
+	switch {
+	case bullishAligned:
+		result.RecommendedTrade = "BUY"
+	case bearishAligned:
+		result.RecommendedTrade = "SELL"
+	default:
 		result.RecommendedTrade = "WAIT - Timeframes not aligned"
 	}
 
@@ -384,12 +510,16 @@ func CombineMultiTimeframeSignals(daily, fourHour, oneHour CombinedSignal) Multi
 // This is to help reduce false signals by ~60% through multi-timeframe confirmation giving strong indictation of trend direction
 func (m *MultiTimeframeSignal) IsMultiTimeframeConfirmed(requireStrongAlignment bool) bool {
 	if requireStrongAlignment {
-		// Daily + 4H must agree, 1H should not contradict
+		// primary + secondary must agree, the third should not contradict
 		if !m.Alignment {
 			return false
 		}
-		// check if daily trend is primary
-		return m.DailySignal.Confidence > 50.0 && m.FourHourSignal.Confidence > 50.0
+		// check the leading timeframes carry conviction (daily and 4H when unset)
+		primary, secondary := m.PrimaryTimeframe, m.SecondaryTimeframe
+		if primary == "" {
+			primary, secondary = TimeframeDaily, TimeframeFourHour
+		}
+		return m.signalFor(primary).Confidence > 50.0 && m.signalFor(secondary).Confidence > 50.0
 	}
 	// Moderate alignment: at least 2 timeframes agree
 	return m.AlignmentPercent >= 66.0
@@ -1,7 +1,11 @@
 package signals
 
 import (
+	"math"
 	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
 )
 
 func TestCombineMultiTimeframeSignals_AllAligned(t *testing.T) {
@@ -214,3 +218,242 @@ func TestMultiTimeframeAlignment_FalseSignalReduction(t *testing.T) {
 		t.Errorf("Should not recommend BUY when false signal, got %s", result.RecommendedTrade)
 	}
 }
+
+// a handful of quiet bars: too few for a whale baseline, so the whale component scores zero
+func quietBars(n int) []types.Bar {
+	bars := make([]types.Bar, n)
+	for i := range bars {
+		bars[i] = types.Bar{Open: 100, High: 100.5, Low: 99.5, Close: 100, Volume: 1000}
+	}
+	return bars
+}
+
+func findComponent(components []SignalComponent, name string) (SignalComponent, bool) {
+	for _, c := range components {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return SignalComponent{}, false
+}
+
+func TestCalculateSignal_ZeroWhaleComponentOmittedWhenEnabled(t *testing.T) {
+	defer func(prev bool) { OmitZeroComponents = prev }(OmitZeroComponents)
+	rsi := 25.0
+
+	OmitZeroComponents = false
+	full := CalculateSignal(&rsi, nil, quietBars(5), "TEST", "", nil)
+	whale, ok := findComponent(full.Components, "Whale")
+	if !ok || whale.Score != 0 {
+		t.Fatalf("Whale component = %+v (present %v), want a zero-score entry by default", whale, ok)
+	}
+
+	OmitZeroComponents = true
+	trimmed := CalculateSignal(&rsi, nil, quietBars(5), "TEST", "", nil)
+	if _, ok := findComponent(trimmed.Components, "Whale"); ok {
+		t.Errorf("Whale component kept with zero contribution: %+v", trimmed.Components)
+	}
+	for _, c := range trimmed.Components {
+		if c.Score == 0 {
+			t.Errorf("Component %s kept with zero score", c.Name)
+		}
+	}
+	if _, ok := findComponent(trimmed.Components, "RSI"); !ok {
+		t.Errorf("Active RSI component dropped: %+v", trimmed.Components)
+	}
+	if trimmed.Score != full.Score || trimmed.Recommendation != full.Recommendation {
+		t.Errorf("Omitting components changed the score: %.3f/%s vs %.3f/%s",
+			trimmed.Score, trimmed.Recommendation, full.Score, full.Recommendation)
+	}
+
+	fullAligned, fullActive := full.ComponentAlignment()
+	trimmedAligned, trimmedActive := trimmed.ComponentAlignment()
+	if fullAligned != trimmedAligned || fullActive != trimmedActive {
+		t.Errorf("Alignment %d/%d with zero components, %d/%d without; want equal",
+			fullAligned, fullActive, trimmedAligned, trimmedActive)
+	}
+	if fullActive != len(trimmed.Components) {
+		t.Errorf("Active count = %d, want %d non-zero components", fullActive, len(trimmed.Components))
+	}
+}
+
+func TestComponentAlignment_IgnoresZeroAndCountsAgreement(t *testing.T) {
+	signal := CombinedSignal{
+		Score: 0.6,
+		Components: []SignalComponent{
+			{Name: "RSI", Score: 0.8},
+			{Name: "ATR", Score: -0.2},
+			{Name: "Whale", Score: 0},
+			{Name: "Pattern", Score: 0.5},
+		},
+	}
+	aligned, active := signal.ComponentAlignment()
+	if aligned != 2 || active != 3 {
+		t.Errorf("ComponentAlignment() = %d/%d, want 2/3", aligned, active)
+	}
+}
+
+func TestCalculateSignal_RSICrossoverTerm(t *testing.T) {
+	saved := RSICrossover
+	defer func() { RSICrossover = saved }()
+
+	bars := make([]types.Bar, 0, 33)
+	price := 130.0
+	for i := 0; i < 30; i++ {
+		price--
+		bars = append(bars, types.Bar{Open: price + 1, High: price + 1.5, Low: price - 0.5, Close: price, Volume: 1000})
+	}
+	for i := 0; i < 3; i++ {
+		price += 3
+		bars = append(bars, types.Bar{Open: price - 3, High: price + 0.5, Low: price - 3.5, Close: price, Volume: 1000})
+	}
+
+	crossoverComponent := func(signal CombinedSignal) *SignalComponent {
+		for i := range signal.Components {
+			if signal.Components[i].Name == "RSI Crossover" {
+				return &signal.Components[i]
+			}
+		}
+		return nil
+	}
+
+	SetRSICrossover(false, 5, 14, 3)
+	off := CalculateSignal(nil, nil, bars, "TEST", "", nil)
+	if crossoverComponent(off) != nil {
+		t.Errorf("components with the crossover off = %+v, want no RSI Crossover term", off.Components)
+	}
+
+	SetRSICrossover(true, 5, 14, 3)
+	on := CalculateSignal(nil, nil, bars, "TEST", "", nil)
+	component := crossoverComponent(on)
+	if component == nil || component.Score != 2.0 {
+		t.Fatalf("RSI Crossover component = %+v, want a +2 bullish term", component)
+	}
+	if on.Score <= off.Score {
+		t.Errorf("score with the bullish cross = %.2f, want above %.2f", on.Score, off.Score)
+	}
+
+	// too few bars for the slow RSI: the term is zero rather than an error
+	if short := CalculateSignal(nil, nil, bars[:10], "TEST", "", nil); crossoverComponent(short).Score != 0 {
+		t.Errorf("crossover score on 10 bars = %.2f, want 0", crossoverComponent(short).Score)
+	}
+}
+
+func TestCombineMultiTimeframeSignalsWithWeights_WeightingChangesResult(t *testing.T) {
+	// the daily chart is flat while the intraday timeframes are both turning up
+	daily := CombinedSignal{Recommendation: RecommendationWait, Score: 0.2, Confidence: 55}
+	fourHour := CombinedSignal{Recommendation: RecommendationBuy, Score: 1.5, Confidence: 80}
+	oneHour := CombinedSignal{Recommendation: RecommendationBuy, Score: 1.8, Confidence: 85}
+
+	swing := TimeframeWeights{Daily: 0.5, FourHour: 0.35, OneHour: 0.15, Primary: TimeframeDaily}
+	scalp := TimeframeWeights{Daily: 0.15, FourHour: 0.35, OneHour: 0.5, Primary: TimeframeOneHour}
+
+	swingResult := CombineMultiTimeframeSignalsWithWeights(daily, fourHour, oneHour, swing)
+	scalpResult := CombineMultiTimeframeSignalsWithWeights(daily, fourHour, oneHour, scalp)
+
+	if math.Abs(swingResult.CompositeScore-0.895) > 1e-9 || math.Abs(scalpResult.CompositeScore-1.455) > 1e-9 {
+		t.Errorf("Composite swing %.3f / scalp %.3f, want 0.895 / 1.455", swingResult.CompositeScore, scalpResult.CompositeScore)
+	}
+	if swingResult.Alignment || swingResult.RecommendedTrade != "WAIT - Timeframes not aligned" {
+		t.Errorf("Daily-led result = %v / %s, want no alignment while the daily waits", swingResult.Alignment, swingResult.RecommendedTrade)
+	}
+	if !scalpResult.Alignment || scalpResult.RecommendedTrade != "BUY" || scalpResult.SecondaryTimeframe != TimeframeFourHour {
+		t.Errorf("1H-led result = %v / %s (secondary %s), want an aligned BUY backed by 4H", scalpResult.Alignment, scalpResult.RecommendedTrade, scalpResult.SecondaryTimeframe)
+	}
+	if !scalpResult.IsMultiTimeframeConfirmed(true) || swingResult.IsMultiTimeframeConfirmed(true) {
+		t.Errorf("Strict confirmation should follow the configured primary")
+	}
+
+	// weights that don't sum to 1 are scaled, so doubling them all changes nothing
+	doubled := CombineMultiTimeframeSignalsWithWeights(daily, fourHour, oneHour, TimeframeWeights{Daily: 1, FourHour: 0.7, OneHour: 0.3, Primary: TimeframeDaily})
+	if math.Abs(doubled.CompositeScore-swingResult.CompositeScore) > 1e-9 {
+		t.Errorf("Doubled weights composite %.3f, want %.3f", doubled.CompositeScore, swingResult.CompositeScore)
+	}
+}
+
+func TestSetMultiTimeframeWeights_Validation(t *testing.T) {
+	saved := MultiTimeframeWeights
+	t.Cleanup(func() { MultiTimeframeWeights = saved })
+
+	if err := SetMultiTimeframeWeights(0.5, -0.1, 0.6, TimeframeDaily); err == nil {
+		t.Error("Negative weight accepted")
+	}
+	if err := SetMultiTimeframeWeights(0.2, 0.3, 0.5, "15Min"); err == nil {
+		t.Error("Unknown primary accepted")
+	}
+	if MultiTimeframeWeights != saved {
+		t.Fatalf("Rejected weights were applied: %+v", MultiTimeframeWeights)
+	}
+
+	if err := SetMultiTimeframeWeights(0, 0, 0, TimeframeFourHour); err != nil {
+		t.Fatalf("SetMultiTimeframeWeights() error = %v", err)
+	}
+	if want := (TimeframeWeights{Daily: 0.5, FourHour: 0.35, OneHour: 0.15, Primary: TimeframeFourHour}); MultiTimeframeWeights != want {
+		t.Errorf("Weights = %+v, want the current weights led by 4H", MultiTimeframeWeights)
+	}
+	if got := MultiTimeframeWeights.secondary(); got != TimeframeDaily {
+		t.Errorf("Secondary for a 4H primary = %s, want %s", got, TimeframeDaily)
+	}
+}
+
+func TestCalculateSignalWithWeights_WeightChangeFlipsBorderline(t *testing.T) {
+	rsi := 30.0 // RSI score 3, worth 0.6 at the default 0.20 weight: just over the ACCUMULATE line
+
+	base := CalculateSignal(&rsi, nil, quietBars(5), "TEST", "", nil)
+	if base.Recommendation != RecommendationAccumulate {
+		t.Fatalf("Default weights: %s (score %.2f), want ACCUMULATE", base.Recommendation, base.Score)
+	}
+	if same := CalculateSignalWithWeights(&rsi, nil, quietBars(5), "TEST", "", nil, nil); same.Score != base.Score {
+		t.Errorf("nil weights scored %.3f, want the defaults' %.3f", same.Score, base.Score)
+	}
+
+	light := CalculateSignalWithWeights(&rsi, nil, quietBars(5), "TEST", "", nil, map[string]float64{"RSI": 0.1})
+	if light.Recommendation != RecommendationWait {
+		t.Errorf("RSI weight 0.1: %s (score %.2f), want WAIT", light.Recommendation, light.Score)
+	}
+	if c, _ := findComponent(light.Components, "RSI"); c.Weight != 0.1 {
+		t.Errorf("RSI component weight = %.2f, want 0.1", c.Weight)
+	}
+	if c, _ := findComponent(light.Components, "Pattern"); c.Weight != DefaultSignalWeights["Pattern"] {
+		t.Errorf("Pattern weight = %.2f, want the default for a missing key", c.Weight)
+	}
+}
+
+func TestProfileSignalWeights(t *testing.T) {
+	weights := ProfileSignalWeights(config.SignalWeights{RSIWeight: 0.3, ATRWeight: 0.15, VolumeWeight: 0.15, WhaleActivityWeight: 0.2})
+	if len(weights) != 3 || weights["RSI"] != 0.3 || weights["ATR"] != 0.15 || weights["Whale"] != 0.2 {
+		t.Errorf("weights = %v, want RSI 0.3, ATR 0.15, Whale 0.2", weights)
+	}
+	if unset := ProfileSignalWeights(config.SignalWeights{}); len(unset) != 0 {
+		t.Errorf("Unset profile weights should fall back to defaults, got %v", unset)
+	}
+}
+
+func TestCalculateSignal_BollingerLowerBandInUptrend(t *testing.T) {
+	defer func(prev BollingerSettings) { Bollinger = prev }(Bollinger)
+
+	// a steady climb, then a close back at the lower band while the middle band still rises
+	bars := make([]types.Bar, 0, 30)
+	for i := 0; i < 29; i++ {
+		bars = append(bars, types.Bar{Close: 100 + float64(i)*0.5, Volume: 1000})
+	}
+	pullback := append(append([]types.Bar{}, bars...), types.Bar{Close: 103, Volume: 1000})
+	extended := append(append([]types.Bar{}, bars...), types.Bar{Close: 114.5, Volume: 1000})
+
+	SetBollinger(false, 20, 2)
+	if _, ok := findComponent(CalculateSignal(nil, nil, pullback, "TEST", "", nil).Components, "Bollinger"); ok {
+		t.Errorf("Bollinger component present while disabled")
+	}
+
+	SetBollinger(true, 20, 2)
+	on := CalculateSignal(nil, nil, pullback, "TEST", "", nil)
+	if c, ok := findComponent(on.Components, "Bollinger"); !ok || c.Score != 2 {
+		t.Errorf("Bollinger component = %+v (present %v), want +2 at the lower band in an uptrend", c, ok)
+	}
+	if c, _ := findComponent(CalculateSignal(nil, nil, extended, "TEST", "", nil).Components, "Bollinger"); c.Score != 0 {
+		t.Errorf("Bollinger score away from the bands = %.2f, want 0", c.Score)
+	}
+	if c, _ := findComponent(CalculateSignal(nil, nil, pullback[:10], "TEST", "", nil).Components, "Bollinger"); c.Score != 0 {
+		t.Errorf("Bollinger score on 10 bars = %.2f, want 0", c.Score)
+	}
+}
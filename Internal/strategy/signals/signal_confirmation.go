@@ -0,0 +1,114 @@
+package signals
+
+import (
+	"sync"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// consecutive bars a BUY/SELL side must hold before it's actionable; 1 confirms every signal.
+// Set from features.signal_confirmation_bars
+var ConfirmationBars = 1
+
+// a combined signal plus whether its direction has held long enough to act on
+type ConfirmedSignal struct {
+	CombinedSignal
+	Confirmed       bool
+	ConsecutiveBars int // bars in a row, including this one, on the same side
+	RequiredBars    int
+}
+
+// remembers the recent recommendations per symbol so a single-bar flip isn't treated as actionable
+type SignalConfirmer struct {
+	required int
+	history  map[string][]confirmationEntry // symbol -> recent bars, newest last
+	mu       sync.Mutex
+}
+
+type confirmationEntry struct {
+	barTime string // timestamp of the bar the signal was computed on
+	side    int    // 1 bullish, -1 bearish, 0 wait
+}
+
+// requiredBars <= 0 uses ConfirmationBars
+func NewSignalConfirmer(requiredBars int) *SignalConfirmer {
+	if requiredBars <= 0 {
+		requiredBars = ConfirmationBars
+	}
+	if requiredBars < 1 {
+		requiredBars = 1
+	}
+	return &SignalConfirmer{required: requiredBars, history: make(map[string][]confirmationEntry)}
+}
+
+// CalculateSignal on the latest bar, confirmed against what this confirmer saw on earlier bars
+func (c *SignalConfirmer) CalculateSignal(
+	rsiValue *float64,
+	atrValue *float64,
+	bars []types.Bar,
+	symbol string,
+	analysis string,
+	rsiValues []float64,
+) ConfirmedSignal {
+	signal := CalculateSignal(rsiValue, atrValue, bars, symbol, analysis, rsiValues)
+	barTime := ""
+	if len(bars) > 0 {
+		barTime = bars[len(bars)-1].Timestamp
+	}
+	return c.Observe(symbol, barTime, signal)
+}
+
+// records the signal for the bar at barTime and reports whether its side has held for the required bars;
+// observing the same bar again replaces its entry instead of counting twice. WAIT is never confirmed
+func (c *SignalConfirmer) Observe(symbol, barTime string, signal CombinedSignal) ConfirmedSignal {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := confirmationEntry{barTime: barTime, side: recommendationSide(signal.Recommendation)}
+	history := c.history[symbol]
+	if n := len(history); n > 0 && barTime != "" && history[n-1].barTime == barTime {
+		history[n-1] = entry
+	} else {
+		history = append(history, entry)
+	}
+	if len(history) > c.required {
+		history = history[len(history)-c.required:]
+	}
+	c.history[symbol] = history
+
+	consecutive := 0
+	if entry.side != 0 {
+		for i := len(history) - 1; i >= 0 && history[i].side == entry.side; i-- {
+			consecutive++
+		}
+	}
+
+	return ConfirmedSignal{
+		CombinedSignal:  signal,
+		Confirmed:       consecutive >= c.required,
+		ConsecutiveBars: consecutive,
+		RequiredBars:    c.required,
+	}
+}
+
+func (c *SignalConfirmer) RequiredBars() int {
+	return c.required
+}
+
+// forgets the history for a symbol, e.g. after a data gap
+func (c *SignalConfirmer) Reset(symbol string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.history, symbol)
+}
+
+// BUY and ACCUMULATE count as the same side, as do SELL and DISTRIBUTE
+func recommendationSide(recommendation string) int {
+	switch recommendation {
+	case RecommendationBuy, RecommendationAccumulate:
+		return 1
+	case RecommendationSell, RecommendationDistribute:
+		return -1
+	}
+	return 0
+}
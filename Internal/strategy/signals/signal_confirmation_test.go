@@ -0,0 +1,60 @@
+package signals
+
+import (
+	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+func TestSignalConfirmer_OneBarFlipIsNotConfirmed(t *testing.T) {
+	confirmer := NewSignalConfirmer(2)
+
+	first := confirmer.Observe("AAPL", "2024-03-04T21:00:00Z", CombinedSignal{Recommendation: RecommendationWait})
+	if first.Confirmed {
+		t.Errorf("WAIT confirmed")
+	}
+	flip := confirmer.Observe("AAPL", "2024-03-05T21:00:00Z", CombinedSignal{Recommendation: RecommendationBuy})
+	if flip.Confirmed || flip.ConsecutiveBars != 1 {
+		t.Errorf("one-bar BUY flip = %+v, want unconfirmed after 1 bar", flip)
+	}
+	reversed := confirmer.Observe("AAPL", "2024-03-06T21:00:00Z", CombinedSignal{Recommendation: RecommendationSell})
+	if reversed.Confirmed {
+		t.Errorf("SELL right after a BUY confirmed")
+	}
+}
+
+func TestSignalConfirmer_TwoBarPersistenceIsConfirmed(t *testing.T) {
+	confirmer := NewSignalConfirmer(2)
+
+	confirmer.Observe("AAPL", "2024-03-05T21:00:00Z", CombinedSignal{Recommendation: RecommendationBuy})
+	// re-checking the same bar doesn't count as a second bar
+	again := confirmer.Observe("AAPL", "2024-03-05T21:00:00Z", CombinedSignal{Recommendation: RecommendationBuy})
+	if again.Confirmed {
+		t.Errorf("same bar observed twice confirmed the signal")
+	}
+
+	held := confirmer.Observe("AAPL", "2024-03-06T21:00:00Z", CombinedSignal{Recommendation: RecommendationAccumulate})
+	if !held.Confirmed || held.ConsecutiveBars != 2 || held.RequiredBars != 2 {
+		t.Errorf("two bullish bars = %+v, want confirmed after 2 of 2", held)
+	}
+
+	// other symbols keep their own history
+	if other := confirmer.Observe("MSFT", "2024-03-06T21:00:00Z", CombinedSignal{Recommendation: RecommendationBuy}); other.Confirmed {
+		t.Errorf("MSFT confirmed by AAPL's history")
+	}
+}
+
+func TestSignalConfirmer_WrapsCalculateSignal(t *testing.T) {
+	confirmer := NewSignalConfirmer(1)
+	rsi := 20.0
+	bars := []types.Bar{{Timestamp: "2024-03-05T21:00:00Z", Open: 100, High: 101, Low: 99, Close: 100, Volume: 1000}}
+
+	got := confirmer.CalculateSignal(&rsi, nil, bars, "AAPL", "", nil)
+	want := CalculateSignal(&rsi, nil, bars, "AAPL", "", nil)
+	if got.Recommendation != want.Recommendation || got.Score != want.Score {
+		t.Errorf("wrapped signal = %s/%.3f, want %s/%.3f", got.Recommendation, got.Score, want.Recommendation, want.Score)
+	}
+	if got.Confirmed != (recommendationSide(want.Recommendation) != 0) {
+		t.Errorf("Confirmed = %v for %s with a one-bar requirement", got.Confirmed, want.Recommendation)
+	}
+}
@@ -3,14 +3,21 @@ package signals
 import (
 	"fmt"
 
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
 	"github.com/fazecat/mogulmaker/Internal/types"
 )
 
+// ADX under this reads as a ranging market, where signals tend to whipsaw
+const DefaultMinADX = 20.0
+
 // Reduce false signals by filtering low-confidence signals
 type SignalQualityFilter struct {
 	MinConfidenceThreshold float64 //default: 70%
 	MaxConfidenceThreshold float64
-	RequireIndicatorMatch  bool // Require multiple indicators to align
+	RequireIndicatorMatch  bool    // Require multiple indicators to align
+	MinAlignedIndicators   int     // active components that must agree with the signal when RequireIndicatorMatch (default 2)
+	MinADX                 float64 // trend strength FilterSignalWithTrend requires, 0 disables the check
+	ADXPeriod              int     // bars behind that ADX, indicators.DefaultADXPeriod when 0
 	VerboseLogging         bool
 }
 
@@ -21,6 +28,7 @@ type FilteredSignal struct {
 	QualityScore       float64
 	IndicatorAlignment int
 	RecommendedAction  string
+	ADX                float64 // latest ADX when FilterSignalWithTrend measured it
 }
 
 func NewSignalQualityFilter() *SignalQualityFilter {
@@ -28,6 +36,7 @@ func NewSignalQualityFilter() *SignalQualityFilter {
 		MinConfidenceThreshold: 70.0,
 		MaxConfidenceThreshold: 100.0,
 		RequireIndicatorMatch:  true,
+		MinAlignedIndicators:   2,
 		VerboseLogging:         false,
 	}
 }
@@ -84,6 +93,56 @@ func (f *SignalQualityFilter) FilterSignal(signal *types.TradeSignal) *FilteredS
 	return result
 }
 
+// FilterSignal plus a trend-strength check on oldest-first bars: a signal that passes is rejected as a weak
+// trend when the latest ADX is below MinADX. Too few bars for an ADX leaves the FilterSignal result as is
+func (f *SignalQualityFilter) FilterSignalWithTrend(signal *types.TradeSignal, bars []types.Bar) *FilteredSignal {
+	result := f.FilterSignal(signal)
+	if !result.Passed || f.MinADX <= 0 {
+		return result
+	}
+
+	period := f.ADXPeriod
+	if period <= 0 {
+		period = indicators.DefaultADXPeriod
+	}
+	adx, err := indicators.CalculateADX(bars, period)
+	if err != nil {
+		if f.VerboseLogging {
+			fmt.Printf("Skipping trend-strength check: %v\n", err)
+		}
+		return result
+	}
+
+	result.ADX = adx[len(adx)-1]
+	if result.ADX < f.MinADX {
+		result.Passed = false
+		result.FailureReason = fmt.Sprintf("Weak trend: ADX %.1f below minimum %.1f", result.ADX, f.MinADX)
+		result.RecommendedAction = "REJECT - Weak Trend"
+	}
+	return result
+}
+
+// filters a combined signal, counting only its active components toward indicator alignment
+func (f *SignalQualityFilter) FilterCombinedSignal(combined CombinedSignal) *FilteredSignal {
+	result := f.FilterSignal(ConvertToTradeSignal(combined))
+	aligned, active := combined.ComponentAlignment()
+	result.IndicatorAlignment = aligned
+	if !result.Passed || !f.RequireIndicatorMatch {
+		return result
+	}
+
+	minAligned := f.MinAlignedIndicators
+	if minAligned <= 0 {
+		minAligned = 2
+	}
+	if aligned < minAligned {
+		result.Passed = false
+		result.FailureReason = fmt.Sprintf("Only %d of %d active indicators agree (need %d)", aligned, active, minAligned)
+		result.RecommendedAction = "REJECT - Indicators Not Aligned"
+	}
+	return result
+}
+
 func (f *SignalQualityFilter) FilterSignalBatch(signals []*types.TradeSignal) []*FilteredSignal {
 	filtered := make([]*FilteredSignal, len(signals))
 	for i, signal := range signals {
@@ -1,6 +1,7 @@
 package signals
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/fazecat/mogulmaker/Internal/types"
@@ -116,3 +117,69 @@ func TestSignalQualityFilter_GetHighestConfidenceSignal(t *testing.T) {
 		t.Errorf("GetHighestConfidenceSignal() returned direction %s, want SHORT", best.Original.Direction)
 	}
 }
+
+func TestSignalQualityFilter_FilterCombinedSignal_ZeroComponentsDontAlign(t *testing.T) {
+	filter := NewSignalQualityFilter()
+	combined := CombinedSignal{
+		Score:          0.7,
+		Confidence:     85,
+		Recommendation: RecommendationBuy,
+		Reasoning:      "RSI oversold",
+		Components: []SignalComponent{
+			{Name: "RSI", Score: 0.9},
+			{Name: "Whale", Score: 0},
+			{Name: "Pattern", Score: 0},
+			{Name: "Support/Resistance", Score: 0},
+		},
+	}
+
+	result := filter.FilterCombinedSignal(combined)
+	if result.Passed {
+		t.Errorf("Signal with one active indicator passed alignment filtering")
+	}
+	if result.IndicatorAlignment != 1 {
+		t.Errorf("IndicatorAlignment = %d, want 1", result.IndicatorAlignment)
+	}
+
+	combined.Components[2].Score = 0.4
+	result = filter.FilterCombinedSignal(combined)
+	if !result.Passed || result.IndicatorAlignment != 2 {
+		t.Errorf("Result = %+v, want a pass with 2 aligned indicators", result)
+	}
+}
+
+func TestSignalQualityFilter_FilterSignalWithTrend(t *testing.T) {
+	trending := make([]types.Bar, 40)
+	ranging := make([]types.Bar, 40)
+	for i := range trending {
+		c := 100 + float64(i)
+		trending[i] = types.Bar{Open: c - 0.5, High: c + 0.5, Low: c - 0.5, Close: c}
+		// up one bar, down the next
+		r := 100.0 + float64(i%2)
+		ranging[i] = types.Bar{Open: r, High: r + 0.5, Low: r - 0.5, Close: r}
+	}
+	signal := func() *types.TradeSignal {
+		return &types.TradeSignal{Direction: "LONG", Confidence: 85, Reasoning: "RSI oversold"}
+	}
+
+	filter := NewSignalQualityFilter()
+	filter.MinADX = DefaultMinADX
+
+	if result := filter.FilterSignalWithTrend(signal(), trending); !result.Passed || result.ADX < DefaultMinADX {
+		t.Errorf("trending bars: %+v, want a pass with ADX above %.0f", result, DefaultMinADX)
+	}
+
+	result := filter.FilterSignalWithTrend(signal(), ranging)
+	if result.Passed || !strings.Contains(result.FailureReason, "Weak trend") || result.RecommendedAction != "REJECT - Weak Trend" {
+		t.Errorf("ranging bars: %+v, want a weak-trend rejection", result)
+	}
+
+	// too few bars for an ADX, or the check turned off, keep the plain FilterSignal result
+	if result := filter.FilterSignalWithTrend(signal(), ranging[:10]); !result.Passed {
+		t.Errorf("short history: %+v, want the signal to pass unchecked", result)
+	}
+	filter.MinADX = 0
+	if result := filter.FilterSignalWithTrend(signal(), ranging); !result.Passed || result.ADX != 0 {
+		t.Errorf("MinADX 0: %+v, want a pass without measuring ADX", result)
+	}
+}
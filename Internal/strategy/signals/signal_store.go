@@ -0,0 +1,115 @@
+package signals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+// where a persisted signal was computed
+const (
+	SignalSourceScan     = "scan"
+	SignalSourceAnalysis = "analysis"
+)
+
+// subset of queries used to persist and read back signals (*database.Queries satisfies it)
+type SignalStore interface {
+	InsertSignal(ctx context.Context, arg database.InsertSignalParams) (int32, error)
+	GetSignalHistory(ctx context.Context, arg database.GetSignalHistoryParams) ([]database.SignalHistory, error)
+}
+
+type StoredComponent struct {
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	Weight float64 `json:"weight"`
+}
+
+// a persisted signal with its decoded component breakdown
+type SignalRecord struct {
+	ID             int32             `json:"id"`
+	Symbol         string            `json:"symbol"`
+	Source         string            `json:"source"`
+	Timeframe      string            `json:"timeframe"`
+	Recommendation string            `json:"recommendation"`
+	Confidence     float64           `json:"confidence"`
+	EnsembleScore  float64           `json:"ensemble_score"`
+	Price          float64           `json:"price"`
+	Components     []StoredComponent `json:"components"`
+	ComputedAt     time.Time         `json:"computed_at"`
+}
+
+// stores a computed signal along with the price it was generated at
+func RecordSignal(ctx context.Context, store SignalStore, symbol, source, timeframe string, price float64, signal CombinedSignal) (int32, error) {
+	if store == nil {
+		return 0, fmt.Errorf("signal store is nil")
+	}
+
+	components := make([]StoredComponent, len(signal.Components))
+	for i, c := range signal.Components {
+		components[i] = StoredComponent{Name: c.Name, Score: c.Score, Weight: c.Weight}
+	}
+	encoded, err := json.Marshal(components)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode signal components: %w", err)
+	}
+
+	id, err := store.InsertSignal(ctx, database.InsertSignalParams{
+		Symbol:         symbol,
+		Source:         source,
+		Timeframe:      timeframe,
+		Recommendation: signal.Recommendation,
+		Confidence:     signal.Confidence,
+		EnsembleScore:  signal.Score,
+		Price:          price,
+		Components:     string(encoded),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to persist signal for %s: %w", symbol, err)
+	}
+	return id, nil
+}
+
+// loads the most recent persisted signals for a symbol, newest first
+func LoadSignalHistory(ctx context.Context, store SignalStore, symbol string, limit int32) ([]SignalRecord, error) {
+	rows, err := store.GetSignalHistory(ctx, database.GetSignalHistoryParams{Symbol: symbol, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return SignalRecordsFromRows(rows)
+}
+
+func SignalRecordsFromRows(rows []database.SignalHistory) ([]SignalRecord, error) {
+	records := make([]SignalRecord, 0, len(rows))
+	for _, row := range rows {
+		record, err := SignalRecordFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func SignalRecordFromRow(row database.SignalHistory) (SignalRecord, error) {
+	var components []StoredComponent
+	if row.Components != "" {
+		if err := json.Unmarshal([]byte(row.Components), &components); err != nil {
+			return SignalRecord{}, fmt.Errorf("signal %d has invalid components: %w", row.ID, err)
+		}
+	}
+	return SignalRecord{
+		ID:             row.ID,
+		Symbol:         row.Symbol,
+		Source:         row.Source,
+		Timeframe:      row.Timeframe,
+		Recommendation: row.Recommendation,
+		Confidence:     row.Confidence,
+		EnsembleScore:  row.EnsembleScore,
+		Price:          row.Price,
+		Components:     components,
+		ComputedAt:     row.ComputedAt,
+	}, nil
+}
@@ -0,0 +1,110 @@
+package signals
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+type fakeSignalStore struct {
+	rows []database.SignalHistory
+}
+
+func (f *fakeSignalStore) InsertSignal(ctx context.Context, arg database.InsertSignalParams) (int32, error) {
+	id := int32(len(f.rows) + 1)
+	f.rows = append(f.rows, database.SignalHistory{
+		ID:             id,
+		Symbol:         arg.Symbol,
+		Source:         arg.Source,
+		Timeframe:      arg.Timeframe,
+		Recommendation: arg.Recommendation,
+		Confidence:     arg.Confidence,
+		EnsembleScore:  arg.EnsembleScore,
+		Price:          arg.Price,
+		Components:     arg.Components,
+		ComputedAt:     time.Date(2024, 1, 1, 0, 0, int(id), 0, time.UTC),
+	})
+	return id, nil
+}
+
+func (f *fakeSignalStore) GetSignalHistory(ctx context.Context, arg database.GetSignalHistoryParams) ([]database.SignalHistory, error) {
+	var rows []database.SignalHistory
+	for i := len(f.rows) - 1; i >= 0 && int32(len(rows)) < arg.Limit; i-- {
+		if f.rows[i].Symbol == arg.Symbol {
+			rows = append(rows, f.rows[i])
+		}
+	}
+	return rows, nil
+}
+
+func TestRecordSignal_RoundTripWithComponents(t *testing.T) {
+	store := &fakeSignalStore{}
+	signal := CombinedSignal{
+		Recommendation: RecommendationBuy,
+		Score:          1.8,
+		Confidence:     72.5,
+		Components: []SignalComponent{
+			{Name: "RSI", Score: 2.0, Weight: 0.20},
+			{Name: "Whale", Score: 1.5, Weight: 0.25},
+		},
+	}
+
+	id, err := RecordSignal(context.Background(), store, "AAPL", SignalSourceScan, "1Day", 187.25, signal)
+	if err != nil {
+		t.Fatalf("RecordSignal() error = %v", err)
+	}
+	if id != 1 {
+		t.Errorf("id = %d, want 1", id)
+	}
+
+	history, err := LoadSignalHistory(context.Background(), store, "AAPL", 10)
+	if err != nil {
+		t.Fatalf("LoadSignalHistory() error = %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("Expected 1 stored signal, got %d", len(history))
+	}
+
+	record := history[0]
+	if record.Recommendation != RecommendationBuy || record.Confidence != 72.5 || record.EnsembleScore != 1.8 || record.Price != 187.25 {
+		t.Errorf("Unexpected record fields: %+v", record)
+	}
+	if record.Source != SignalSourceScan || record.Timeframe != "1Day" {
+		t.Errorf("Source/Timeframe = %s/%s, want scan/1Day", record.Source, record.Timeframe)
+	}
+	if len(record.Components) != 2 {
+		t.Fatalf("Expected 2 components, got %d", len(record.Components))
+	}
+	if record.Components[1] != (StoredComponent{Name: "Whale", Score: 1.5, Weight: 0.25}) {
+		t.Errorf("Second component = %+v", record.Components[1])
+	}
+}
+
+func TestLoadSignalHistory_FiltersBySymbolNewestFirst(t *testing.T) {
+	store := &fakeSignalStore{}
+	ctx := context.Background()
+	for _, symbol := range []string{"AAPL", "MSFT", "AAPL"} {
+		if _, err := RecordSignal(ctx, store, symbol, SignalSourceAnalysis, "1Day", 100, CombinedSignal{Recommendation: RecommendationWait}); err != nil {
+			t.Fatalf("RecordSignal() error = %v", err)
+		}
+	}
+
+	history, err := LoadSignalHistory(ctx, store, "AAPL", 10)
+	if err != nil {
+		t.Fatalf("LoadSignalHistory() error = %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 AAPL signals, got %d", len(history))
+	}
+	if !history[0].ComputedAt.After(history[1].ComputedAt) {
+		t.Errorf("Expected newest signal first")
+	}
+}
+
+func TestSignalRecordFromRow_InvalidComponents(t *testing.T) {
+	if _, err := SignalRecordFromRow(database.SignalHistory{ID: 3, Components: "{bad"}); err == nil {
+		t.Errorf("Expected error for malformed components JSON")
+	}
+}
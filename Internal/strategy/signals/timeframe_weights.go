@@ -0,0 +1,112 @@
+package signals
+
+import "fmt"
+
+const (
+	TimeframeDaily    = "1Day"
+	TimeframeFourHour = "4Hour"
+	TimeframeOneHour  = "1Hour"
+)
+
+// how CombineMultiTimeframeSignals blends the three timeframes. The primary sets the trade direction and,
+// with the heaviest of the other two, has to agree for the timeframes to count as aligned
+type TimeframeWeights struct {
+	Daily    float64
+	FourHour float64
+	OneHour  float64
+	Primary  string // TimeframeDaily, TimeframeFourHour or TimeframeOneHour
+}
+
+// daily-led swing weighting; set from the multi_timeframe config block
+var MultiTimeframeWeights = TimeframeWeights{Daily: 0.5, FourHour: 0.35, OneHour: 0.15, Primary: TimeframeDaily}
+
+// rejects negative weights, an all-zero set and an unknown primary
+func (w TimeframeWeights) Validate() error {
+	if w.Daily < 0 || w.FourHour < 0 || w.OneHour < 0 {
+		return fmt.Errorf("timeframe weights must be non-negative, got daily %.2f / 4h %.2f / 1h %.2f", w.Daily, w.FourHour, w.OneHour)
+	}
+	if w.Daily+w.FourHour+w.OneHour == 0 {
+		return fmt.Errorf("at least one timeframe weight must be positive")
+	}
+	switch w.Primary {
+	case TimeframeDaily, TimeframeFourHour, TimeframeOneHour:
+	default:
+		return fmt.Errorf("primary timeframe must be %s, %s or %s, got %q", TimeframeDaily, TimeframeFourHour, TimeframeOneHour, w.Primary)
+	}
+	return nil
+}
+
+// replaces MultiTimeframeWeights; an invalid set is reported and the current weights kept. All-zero weights
+// (an absent config block) keep the current weights and an empty primary means daily
+func SetMultiTimeframeWeights(daily, fourHour, oneHour float64, primary string) error {
+	if daily == 0 && fourHour == 0 && oneHour == 0 {
+		daily, fourHour, oneHour = MultiTimeframeWeights.Daily, MultiTimeframeWeights.FourHour, MultiTimeframeWeights.OneHour
+	}
+	if primary == "" {
+		primary = TimeframeDaily
+	}
+	weights := TimeframeWeights{Daily: daily, FourHour: fourHour, OneHour: oneHour, Primary: primary}
+	if err := weights.Validate(); err != nil {
+		return err
+	}
+	MultiTimeframeWeights = weights
+	return nil
+}
+
+// weights scaled to sum to 1, so the composite stays on the single-timeframe score scale
+func (w TimeframeWeights) normalized() (daily, fourHour, oneHour float64) {
+	total := w.Daily + w.FourHour + w.OneHour
+	if total <= 0 {
+		return 0, 0, 0
+	}
+	return w.Daily / total, w.FourHour / total, w.OneHour / total
+}
+
+func (w TimeframeWeights) weight(timeframe string) float64 {
+	switch timeframe {
+	case TimeframeDaily:
+		return w.Daily
+	case TimeframeFourHour:
+		return w.FourHour
+	case TimeframeOneHour:
+		return w.OneHour
+	}
+	return 0
+}
+
+// the heavier of the two non-primary timeframes; on a tie the one nearer the primary, then the longer one
+func (w TimeframeWeights) secondary() string {
+	var candidates []string
+	switch w.Primary {
+	case TimeframeFourHour:
+		candidates = []string{TimeframeDaily, TimeframeOneHour}
+	case TimeframeOneHour:
+		candidates = []string{TimeframeFourHour, TimeframeDaily}
+	default:
+		candidates = []string{TimeframeFourHour, TimeframeOneHour}
+	}
+	if w.weight(candidates[1]) > w.weight(candidates[0]) {
+		return candidates[1]
+	}
+	return candidates[0]
+}
+
+// the remaining timeframe once primary and secondary are taken
+func thirdTimeframe(primary, secondary string) string {
+	for _, tf := range []string{TimeframeDaily, TimeframeFourHour, TimeframeOneHour} {
+		if tf != primary && tf != secondary {
+			return tf
+		}
+	}
+	return ""
+}
+
+func (m *MultiTimeframeSignal) signalFor(timeframe string) CombinedSignal {
+	switch timeframe {
+	case TimeframeFourHour:
+		return m.FourHourSignal
+	case TimeframeOneHour:
+		return m.OneHourSignal
+	}
+	return m.DailySignal
+}
@@ -0,0 +1,137 @@
+package signals
+
+import (
+	"github.com/fazecat/mogulmaker/Internal/strategy/detection"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// minimum 0-100 composite for each letter; anything under D is an F. Set from the trade_grade config block
+type GradeCutoffs struct {
+	A float64
+	B float64
+	C float64
+	D float64
+}
+
+var TradeGradeCutoffs = GradeCutoffs{A: 80, B: 65, C: 50, D: 35}
+
+// replaces the cutoffs; non-positive values keep the current ones and a set that isn't descending is ignored
+func SetTradeGradeCutoffs(a, b, c, d float64) {
+	next := TradeGradeCutoffs
+	if a > 0 {
+		next.A = a
+	}
+	if b > 0 {
+		next.B = b
+	}
+	if c > 0 {
+		next.C = c
+	}
+	if d > 0 {
+		next.D = d
+	}
+	if next.A > next.B && next.B > next.C && next.C > next.D {
+		TradeGradeCutoffs = next
+	}
+}
+
+// how much each input counts towards the composite; inputs that weren't measured drop out and the rest are rescaled
+const (
+	gradeWeightConfidence = 0.35
+	gradeWeightAlignment  = 0.25
+	gradeWeightSR         = 0.25
+	gradeWeightPattern    = 0.15
+)
+
+// the 0-100 inputs behind a grade; Alignment and SRValidation are nil when they weren't computed
+type GradeInputs struct {
+	SignalConfidence  float64
+	Alignment         *float64 // MultiTimeframeSignal.AlignmentPercent
+	SRValidation      *float64 // SignalValidationWithSR.ValidationScore
+	PatternConfidence float64  // best detected chart pattern, 0 when there is none
+}
+
+type TradeGrade struct {
+	Grade string  `json:"grade"`
+	Score float64 `json:"score"` // weighted composite the letter was cut from
+}
+
+// letter for a composite score; a score exactly on a cutoff earns that grade
+func (c GradeCutoffs) Grade(score float64) string {
+	switch {
+	case score >= c.A:
+		return "A"
+	case score >= c.B:
+		return "B"
+	case score >= c.C:
+		return "C"
+	case score >= c.D:
+		return "D"
+	}
+	return "F"
+}
+
+// A-F grade for a setup using the configured cutoffs
+func GradeSetup(in GradeInputs) TradeGrade {
+	total := in.SignalConfidence*gradeWeightConfidence + in.PatternConfidence*gradeWeightPattern
+	weight := gradeWeightConfidence + gradeWeightPattern
+	if in.Alignment != nil {
+		total += *in.Alignment * gradeWeightAlignment
+		weight += gradeWeightAlignment
+	}
+	if in.SRValidation != nil {
+		total += *in.SRValidation * gradeWeightSR
+		weight += gradeWeightSR
+	}
+
+	score := clampPercent(total / weight)
+	return TradeGrade{Grade: TradeGradeCutoffs.Grade(score), Score: score}
+}
+
+// grades a combined signal against the bars it came from; S/R is only validated for a LONG or SHORT call.
+// alignment is nil when no multi-timeframe read was made
+func GradeSignal(signal CombinedSignal, bars []types.Bar, alignment *float64) TradeGrade {
+	in := GradeInputs{
+		SignalConfidence:  signal.Confidence,
+		Alignment:         alignment,
+		PatternConfidence: BestPatternConfidence(bars),
+	}
+	if trade := ConvertToTradeSignal(signal); len(bars) > 0 && trade.Direction != RecommendationWait {
+		validation := NewSupportResistanceValidator().ValidateSignalWithSR(trade, bars, bars[len(bars)-1].Close)
+		in.SRValidation = &validation.ValidationScore
+	}
+	return GradeSetup(in)
+}
+
+// confidence of the strongest chart pattern detected in bars, 0 when none is
+func BestPatternConfidence(bars []types.Bar) float64 {
+	best := 0.0
+	for _, p := range detection.NewPatternDetector().DetectAllPatterns(bars) {
+		if p.Detected && p.Confidence > best {
+			best = p.Confidence
+		}
+	}
+	return clampPercent(best)
+}
+
+func clampPercent(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 100 {
+		return 100
+	}
+	return v
+}
+
+var gradeRank = map[string]int{"A": 5, "B": 4, "C": 3, "D": 2, "F": 1}
+
+// whether grade is one of A, B, C, D or F
+func ValidGrade(grade string) bool {
+	return gradeRank[grade] > 0
+}
+
+// whether grade is minGrade or better; an ungraded setup never qualifies
+func GradeAtLeast(grade, minGrade string) bool {
+	return gradeRank[grade] > 0 && gradeRank[grade] >= gradeRank[minGrade]
+}
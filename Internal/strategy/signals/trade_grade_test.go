@@ -0,0 +1,124 @@
+package signals
+
+import (
+	"math"
+	"testing"
+)
+
+func percent(v float64) *float64 { return &v }
+
+func TestGradeSetup_RepresentativeSetups(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        GradeInputs
+		wantGrade string
+		wantScore float64
+	}{
+		{
+			name:      "aligned breakout at support with a pattern",
+			in:        GradeInputs{SignalConfidence: 90, Alignment: percent(100), SRValidation: percent(90), PatternConfidence: 80},
+			wantGrade: "A",
+			wantScore: 91,
+		},
+		{
+			name:      "strong signal with two of three timeframes agreeing",
+			in:        GradeInputs{SignalConfidence: 85, Alignment: percent(200.0 / 3), SRValidation: percent(75), PatternConfidence: 50},
+			wantGrade: "B",
+			wantScore: 72.6667,
+		},
+		{
+			name:      "moderate signal far from support and no pattern",
+			in:        GradeInputs{SignalConfidence: 75, Alignment: percent(200.0 / 3), SRValidation: percent(60)},
+			wantGrade: "C",
+			wantScore: 57.9167,
+		},
+		{
+			name:      "neutral daily signal only, rescaled over what was measured",
+			in:        GradeInputs{SignalConfidence: 50},
+			wantGrade: "D",
+			wantScore: 35,
+		},
+		{
+			name:      "weak signal in a poor location",
+			in:        GradeInputs{SignalConfidence: 40, SRValidation: percent(20)},
+			wantGrade: "F",
+			wantScore: 25.3333,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GradeSetup(tt.in)
+			if got.Grade != tt.wantGrade {
+				t.Errorf("Grade = %s, want %s (score %.4f)", got.Grade, tt.wantGrade, got.Score)
+			}
+			if math.Abs(got.Score-tt.wantScore) > 0.001 {
+				t.Errorf("Score = %.4f, want %.4f", got.Score, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestGradeCutoffs_Boundaries(t *testing.T) {
+	cutoffs := GradeCutoffs{A: 80, B: 65, C: 50, D: 35}
+	tests := []struct {
+		score float64
+		want  string
+	}{
+		{100, "A"},
+		{80, "A"},
+		{79.99, "B"},
+		{65, "B"},
+		{64.99, "C"},
+		{50, "C"},
+		{49.99, "D"},
+		{35, "D"},
+		{34.99, "F"},
+		{0, "F"},
+	}
+	for _, tt := range tests {
+		if got := cutoffs.Grade(tt.score); got != tt.want {
+			t.Errorf("Grade(%.2f) = %s, want %s", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestSetTradeGradeCutoffs(t *testing.T) {
+	saved := TradeGradeCutoffs
+	t.Cleanup(func() { TradeGradeCutoffs = saved })
+
+	SetTradeGradeCutoffs(90, 0, 0, 20)
+	if want := (GradeCutoffs{A: 90, B: 65, C: 50, D: 20}); TradeGradeCutoffs != want {
+		t.Fatalf("Cutoffs = %+v, want %+v", TradeGradeCutoffs, want)
+	}
+	if got := GradeSetup(GradeInputs{SignalConfidence: 85, Alignment: percent(85), SRValidation: percent(85), PatternConfidence: 85}); got.Grade != "B" {
+		t.Errorf("85 across the board = %s under an A cutoff of 90, want B", got.Grade)
+	}
+
+	// B above A would leave no score graded B
+	SetTradeGradeCutoffs(70, 75, 0, 0)
+	if want := (GradeCutoffs{A: 90, B: 65, C: 50, D: 20}); TradeGradeCutoffs != want {
+		t.Errorf("Out-of-order cutoffs applied: %+v", TradeGradeCutoffs)
+	}
+}
+
+func TestGradeAtLeast(t *testing.T) {
+	tests := []struct {
+		grade, minGrade string
+		want            bool
+	}{
+		{"A", "B", true},
+		{"B", "B", true},
+		{"C", "B", false},
+		{"F", "F", true},
+		{"", "F", false},
+	}
+	for _, tt := range tests {
+		if got := GradeAtLeast(tt.grade, tt.minGrade); got != tt.want {
+			t.Errorf("GradeAtLeast(%q, %q) = %v, want %v", tt.grade, tt.minGrade, got, tt.want)
+		}
+	}
+	if ValidGrade("E") || !ValidGrade("D") {
+		t.Errorf("ValidGrade should accept A-D and F only")
+	}
+}
@@ -0,0 +1,95 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+// fallbacks when stop_noise leaves a field unset
+const (
+	defaultStopNoiseATRPeriod   = 14
+	defaultStopNoiseMinMultiple = 1.0
+)
+
+// a stop measured against the symbol's ATR; a stop inside MinDistance of entry is likely to be hit by noise
+type StopNoiseCheck struct {
+	Stop          float64 `json:"stop"` // stop to use: the original, or SuggestedStop when widened
+	StopDistance  float64 `json:"stop_distance"`
+	ATR           float64 `json:"atr"`
+	MinDistance   float64 `json:"min_distance"` // ATR times stop_noise.min_atr_multiple
+	SuggestedStop float64 `json:"suggested_stop"`
+	TooTight      bool    `json:"too_tight"`
+	Widened       bool    `json:"widened"`
+}
+
+// the warning to show for a too-tight stop, "" when it clears the ATR minimum
+func (c StopNoiseCheck) Warning() string {
+	if !c.TooTight {
+		return ""
+	}
+	if c.Widened {
+		return fmt.Sprintf("Stop widened from $%.2f away to $%.2f away (%.1fx ATR $%.2f) to stay out of noise; new stop $%.2f",
+			c.StopDistance, c.MinDistance, c.MinDistance/c.ATR, c.ATR, c.Stop)
+	}
+	return fmt.Sprintf("Stop $%.2f from entry is inside the ATR minimum $%.2f (ATR $%.2f) and likely to be hit by noise; consider $%.2f",
+		c.StopDistance, c.MinDistance, c.ATR, c.SuggestedStop)
+}
+
+// compares the entry-to-stop distance with the ATR minimum and, with stop_noise.auto_widen on, moves a too-tight
+// stop out to it. A zero ATR or a disabled check passes the stop through untouched
+func CheckStopNoise(entry, stop, atr float64, direction string, cfg config.StopNoiseConfig) StopNoiseCheck {
+	check := StopNoiseCheck{Stop: stop, StopDistance: math.Abs(entry - stop), ATR: atr, SuggestedStop: stop}
+	if !cfg.Enabled || atr <= 0 || entry <= 0 {
+		return check
+	}
+
+	multiple := cfg.MinATRMultiple
+	if multiple <= 0 {
+		multiple = defaultStopNoiseMinMultiple
+	}
+	check.MinDistance = atr * multiple
+	if check.StopDistance >= check.MinDistance {
+		return check
+	}
+
+	check.TooTight = true
+	if direction == "SHORT" {
+		check.SuggestedStop = entry + check.MinDistance
+	} else {
+		check.SuggestedStop = entry - check.MinDistance
+	}
+	if cfg.AutoWiden && check.SuggestedStop > 0 {
+		check.Stop, check.Widened = check.SuggestedStop, true
+	}
+	return check
+}
+
+func stopNoiseATRPeriod(cfg config.StopNoiseConfig) int {
+	if cfg.ATRPeriod <= 0 {
+		return defaultStopNoiseATRPeriod
+	}
+	return cfg.ATRPeriod
+}
+
+// daily bars to fetch for StopNoiseATR
+func StopNoiseBarsNeeded(cfg config.StopNoiseConfig) int {
+	return stopNoiseATRPeriod(cfg) + 1
+}
+
+// ATR over the stop_noise period as of the last bar (bars oldest-first)
+func StopNoiseATR(bars []types.Bar, cfg config.StopNoiseConfig) (float64, error) {
+	period := stopNoiseATRPeriod(cfg)
+	atrBars := make([]indicators.ATRBar, len(bars))
+	for i, bar := range bars {
+		atrBars[i] = indicators.ATRBar{High: bar.High, Low: bar.Low, Close: bar.Close}
+	}
+	atrValues, err := indicators.CalculateATR(atrBars, period)
+	if err != nil {
+		return 0, fmt.Errorf("ATR(%d) needs %d bars, got %d: %w", period, period+1, len(bars), err)
+	}
+	return atrValues[len(atrValues)-1], nil
+}
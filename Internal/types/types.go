@@ -28,9 +28,25 @@ type Candidate struct {
 	BodyLowerRatio float64
 	VWAPPrice      float64
 	WhaleCount     int
+	Direction      string  // LONG or SHORT setup the score refers to, "" when unknown
+	Grade          string  // A-F setup grade, "" when not graded
+	GradeScore     float64 // 0-100 composite the grade was cut from
+	RawScore       float64 // score as a long setup before the 0-10 clamp, negative for bearish setups
+	ShortCandidate bool    // surfaced as a short for its strongly negative RawScore
+	Breakdown      *ScoreBreakdown
+	Components     map[string]float64 // screener points per score component, nil when not screened
 	Bars           []Bar
 }
 
+// sub-scores behind a watchlist score: technical is the 0-10 interest score without news, news the 0-10
+// sentiment, pattern the latest candle (-2..2) and sr the support/resistance position (-1..1)
+type ScoreBreakdown struct {
+	Technical         float64 `json:"technical"`
+	News              float64 `json:"news"`
+	Pattern           float64 `json:"pattern"`
+	SupportResistance float64 `json:"sr"`
+}
+
 type ScoringInput struct {
 	CurrentPrice       float64
 	VWAPPrice          float64
@@ -10,6 +10,7 @@ import (
 	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
 	signalsPkg "github.com/fazecat/mogulmaker/Internal/strategy/signals"
 	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
 	"github.com/fazecat/mogulmaker/Internal/utils/config"
 )
 
@@ -31,7 +32,7 @@ func CalculateCandidateMetrics(ctx context.Context, symbol string, bars []types.
 		return nil, err
 	}
 
-	atrMap, err := datafeed.FetchATRForDisplay(symbol, 1)
+	atrMap, err := datafeed.FetchATRForDisplay(symbol, "", 1)
 	if err != nil {
 		return nil, err
 	}
@@ -54,18 +55,37 @@ func CalculateCandidateMetrics(ctx context.Context, symbol string, bars []types.
 
 	latestPattern := GetLatestCandlePattern(bars, 5)
 
+	breakdown := CalculateScoreBreakdown(interestScoreInput, weights, bars)
+
 	candidate := &types.Candidate{
-		Symbol:   symbol,
-		Score:    interestScore,
-		RSI:      rsiValues[len(rsiValues)-1],
-		ATR:      atrValue,
-		Analysis: latestPattern,
-		Bars:     bars,
+		Symbol:    symbol,
+		Score:     interestScore,
+		RSI:       rsiValues[len(rsiValues)-1],
+		ATR:       atrValue,
+		Analysis:  latestPattern,
+		Breakdown: &breakdown,
+		Bars:      bars,
 	}
 
 	return candidate, nil
 }
 
+// splits a watchlist score into its technical, news, candle pattern and support/resistance parts
+func CalculateScoreBreakdown(input types.ScoringInput, weights config.SignalWeights, bars []types.Bar) types.ScoreBreakdown {
+	technicalWeights := weights
+	technicalWeights.NewsSentimentWeight = 0
+
+	breakdown := types.ScoreBreakdown{
+		Technical: detection.CalculateInterestScore(input, technicalWeights),
+		News:      input.NewsSentimentScore,
+	}
+	if len(bars) > 0 {
+		breakdown.Pattern = signalsPkg.PatternScore(GetLatestCandlePattern(bars, 1))
+		breakdown.SupportResistance = signalsPkg.SRScore(bars)
+	}
+	return breakdown
+}
+
 func analyzeRecentCandles(bars []types.Bar, numCandles int) (int, int, string) {
 	if len(bars) == 0 {
 		return 0, 0, "N/A"
@@ -129,7 +149,7 @@ func GetPatternConfidence(ctx context.Context, symbol string, bars []types.Bar)
 
 	latestBar := bars[len(bars)-1]
 
-	atrMap, err := datafeed.FetchATRForDisplay(symbol, 1)
+	atrMap, err := datafeed.FetchATRForDisplay(symbol, "", 1)
 	if err != nil {
 		return 0, err
 	}
@@ -152,11 +172,14 @@ func GetPatternConfidence(ctx context.Context, symbol string, bars []types.Bar)
 	return confidence, nil
 }
 
-// AnalyzeSymbolDetailed performs comprehensive analysis on a symbol and returns formatted analysis data
+// AnalyzeSymbolDetailed performs comprehensive analysis on a symbol and returns formatted analysis data.
+// bars are latest first, as the fetchers return them
 func AnalyzeSymbolDetailed(symbol string, bars []types.Bar) (map[string]interface{}, error) {
 	if len(bars) < 14 {
-		return nil, fmt.Errorf("not enough data to analyze - need at least 14 bars, got %d", len(bars))
+		return nil, fmt.Errorf("%w to analyze - need at least 14 bars, got %d", utils.ErrInsufficientData, len(bars))
 	}
+	// the indicators and pattern detectors all read oldest first
+	bars = oldestFirstBars(bars)
 
 	// Calculate RSI
 	closes := extractClosingPrices(bars)
@@ -180,20 +203,16 @@ func AnalyzeSymbolDetailed(symbol string, bars []types.Bar) (map[string]interfac
 	}
 
 	// Get current values
-	currentPrice := bars[0].Close
+	currentPrice := bars[len(bars)-1].Close
 	currentRSI := rsiValues[len(rsiValues)-1]
 	currentATR := atrValues[len(atrValues)-1]
 
-	// Calculate SMA 20
-	sma20 := 0.0
-	barsForSMA := 20
-	if len(bars) < barsForSMA {
-		barsForSMA = len(bars)
-	}
-	for i := 0; i < barsForSMA; i++ {
-		sma20 += bars[i].Close
+	// Calculate SMA 20, over every bar when there are fewer
+	smaValues, err := indicators.CalculateSMA(closes, min(20, len(closes)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate SMA: %w", err)
 	}
-	sma20 /= float64(barsForSMA)
+	sma20 := smaValues[len(smaValues)-1]
 
 	// Determine trend
 	trend := "neutral"
@@ -249,10 +268,21 @@ func AnalyzeSymbolDetailed(symbol string, bars []types.Bar) (map[string]interfac
 		rsiSignal = "oversold"
 	}
 
+	// fast/slow RSI pair; nil when there are too few bars for the slow period
+	var rsiFast, rsiSlow, rsiCrossover interface{}
+	if crossover, ok := signalsPkg.DetectRSICrossover(bars); ok {
+		rsiFast, rsiSlow, rsiCrossover = crossover.Fast, crossover.Slow, crossover
+	}
+
 	// Calculate trading recommendation
 	tradingRec := signalsPkg.CalculateTradingRecommendation(currentPrice, currentRSI, support, resistance, trend, bestP)
+	addTradePlan(tradingRec, currentPrice, currentATR)
+
+	// A-F setup grade from the daily signal; no timeframe alignment goes in since only daily bars are read
+	dailySignal := signalsPkg.CalculateSignal(&currentRSI, &currentATR, bars, symbol, GetLatestCandlePattern(bars, 1), rsiValues)
+	tradeGrade := signalsPkg.GradeSignal(dailySignal, bars, nil)
 
-	// Format historical bars
+	// Format historical bars, oldest on the left of the chart and newest on the right
 	historicalBars := make([]map[string]interface{}, len(bars))
 	for i, bar := range bars {
 		rsiVal := 0.0
@@ -281,17 +311,17 @@ func AnalyzeSymbolDetailed(symbol string, bars []types.Bar) (map[string]interfac
 		}
 	}
 
-	// Reverse bars so oldest dates appear on left, newest on right
-	for i, j := 0, len(historicalBars)-1; i < j; i, j = i+1, j-1 {
-		historicalBars[i], historicalBars[j] = historicalBars[j], historicalBars[i]
-	}
-
 	// Build response
 	response := map[string]interface{}{
 		"symbol":                 symbol,
 		"current_price":          currentPrice,
 		"rsi":                    currentRSI,
 		"rsi_signal":             rsiSignal,
+		"rsi_fast":               rsiFast,
+		"rsi_slow":               rsiSlow,
+		"rsi_fast_period":        signalsPkg.RSICrossover.FastPeriod,
+		"rsi_slow_period":        signalsPkg.RSICrossover.SlowPeriod,
+		"rsi_crossover":          rsiCrossover,
 		"atr":                    currentATR,
 		"sma_20":                 sma20,
 		"trend":                  trend,
@@ -306,8 +336,18 @@ func AnalyzeSymbolDetailed(symbol string, bars []types.Bar) (map[string]interfac
 			"note": "Multi-timeframe analysis requires additional data fetching",
 		},
 		"trading_recommendation": tradingRec,
+		"trade_grade":            tradeGrade,
 		"historical_bars":        historicalBars,
 	}
 
 	return response, nil
 }
+
+// a copy of latest-first bars in the oldest-first order the indicators read
+func oldestFirstBars(bars []types.Bar) []types.Bar {
+	reversed := make([]types.Bar, len(bars))
+	for i, bar := range bars {
+		reversed[len(bars)-1-i] = bar
+	}
+	return reversed
+}
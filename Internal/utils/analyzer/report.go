@@ -0,0 +1,456 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	datafeed "github.com/fazecat/mogulmaker/Internal/database"
+	"github.com/fazecat/mogulmaker/Internal/strategy/detection"
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
+	signalsPkg "github.com/fazecat/mogulmaker/Internal/strategy/signals"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+const (
+	// fewest daily bars a report is built from (a 14-period RSI plus the bar before it)
+	reportMinBars = 15
+	// intraday bars fetched per timeframe for the multi-timeframe check
+	reportIntradayBars = 100
+	// swing points within this share of a level count as touches of it
+	levelTouchTolerance = 0.015
+	// a level this close to price (percent) is worth a recommendation of its own
+	nearLevelPercent = 2.0
+)
+
+// intraday bars for the multi-timeframe check, oldest first; replaced in tests
+var reportBars = func(symbol, timeframe string, limit int) ([]types.Bar, error) {
+	bars, err := datafeed.GetAlpacaBarsWithType(symbol, timeframe, limit, "", utils.DetectAssetType(symbol, ""))
+	if err != nil {
+		return nil, err
+	}
+	return oldestFirstBars(bars), nil
+}
+
+type ReportComponent struct {
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	Weight float64 `json:"weight"`
+}
+
+type ReportSignal struct {
+	Recommendation string            `json:"recommendation"`
+	Score          float64           `json:"score"`
+	Confidence     float64           `json:"confidence"`
+	Reasoning      string            `json:"reasoning"`
+	Components     []ReportComponent `json:"components"`
+}
+
+type ReportMultiTimeframe struct {
+	Daily            string  `json:"daily"`
+	FourHour         string  `json:"four_hour"`
+	OneHour          string  `json:"one_hour"`
+	Aligned          bool    `json:"aligned"`
+	Confirmed        bool    `json:"confirmed"` // aligned with conviction on the leading timeframes
+	AlignmentPercent float64 `json:"alignment_percent"`
+	CompositeScore   float64 `json:"composite_score"`
+	RecommendedTrade string  `json:"recommended_trade"`
+}
+
+type ReportPattern struct {
+	Pattern    string  `json:"pattern"`
+	Direction  string  `json:"direction"`
+	Confidence float64 `json:"confidence"`
+	TargetUp   float64 `json:"target_up,omitempty"`
+	TargetDown float64 `json:"target_down,omitempty"`
+	StopLoss   float64 `json:"stop_loss,omitempty"`
+	Reasoning  string  `json:"reasoning"`
+}
+
+type ReportDivergence struct {
+	Type       string  `json:"type"`      // BULLISH or BEARISH
+	Hidden     bool    `json:"hidden"`    // trend continuation rather than reversal
+	Direction  string  `json:"direction"` // LONG or SHORT
+	Confidence float64 `json:"confidence"`
+	Reasoning  string  `json:"reasoning"`
+}
+
+// how much a support or resistance level can be leaned on: touches are swing points near it
+type LevelQuality struct {
+	Price           float64 `json:"price"`
+	DistancePercent float64 `json:"distance_percent"` // from the current price
+	Touches         int     `json:"touches"`
+	Strength        string  `json:"strength"` // strong (3+ touches), moderate (2) or weak
+}
+
+type WhaleActivity struct {
+	Events        int     `json:"events"`
+	HighBuys      int     `json:"high_conviction_buys"`
+	HighSells     int     `json:"high_conviction_sells"`
+	Bias          string  `json:"bias"` // BUY, SELL or NEUTRAL from the high-conviction events
+	LatestAt      string  `json:"latest_at,omitempty"`
+	LatestZScore  float64 `json:"latest_zscore,omitempty"`
+	LatestVolume  int64   `json:"latest_volume,omitempty"`
+	BarsInspected int     `json:"bars_inspected"`
+}
+
+// everything the analyzer has to say about a symbol, with a plain-English Summary for dashboards
+type AnalysisReport struct {
+	Symbol       string    `json:"symbol"`
+	GeneratedAt  time.Time `json:"generated_at"`
+	CurrentPrice float64   `json:"current_price"`
+	BarsAnalyzed int       `json:"bars_analyzed"`
+
+	Signal             ReportSignal          `json:"signal"`
+	TradeGrade         signalsPkg.TradeGrade `json:"trade_grade"`
+	MultiTimeframe     *ReportMultiTimeframe `json:"multi_timeframe"` // nil when the intraday bars couldn't be used
+	MultiTimeframeNote string                `json:"multi_timeframe_note,omitempty"`
+	Patterns           []ReportPattern       `json:"patterns"`
+	Divergence         *ReportDivergence     `json:"divergence"` // nil when there is none
+	Support            LevelQuality          `json:"support"`
+	Resistance         LevelQuality          `json:"resistance"`
+	Whales             WhaleActivity         `json:"whale_activity"`
+
+	Recommendations []string `json:"recommendations"`
+	Summary         string   `json:"summary"`
+}
+
+// builds the report from oldest-first daily bars (reverse a fetch, which comes back latest first); 4H and 1H bars are fetched for the multi-timeframe check,
+// which is left out with a note when they can't be. cfg supplies the timeframe weights and whale baseline and
+// may be nil
+func GenerateAnalysisReport(ctx context.Context, symbol string, bars []datafeed.Bar, cfg *config.Config) (*AnalysisReport, error) {
+	if len(bars) < reportMinBars {
+		return nil, fmt.Errorf("%w for an analysis report - need at least %d bars, got %d", utils.ErrInsufficientData, reportMinBars, len(bars))
+	}
+
+	daily, rsiValues, err := timeframeSignal(symbol, bars)
+	if err != nil {
+		return nil, err
+	}
+
+	currentPrice := bars[len(bars)-1].Close
+	report := &AnalysisReport{
+		Symbol:       symbol,
+		GeneratedAt:  time.Now(),
+		CurrentPrice: currentPrice,
+		BarsAnalyzed: len(bars),
+		Signal:       reportSignal(daily),
+		Patterns:     detectedPatterns(bars),
+		Divergence:   detectDivergence(bars, rsiValues),
+		Support:      levelQuality(indicators.GetSupportLevels(bars), indicators.FindSupport(bars), currentPrice, true),
+		Resistance:   levelQuality(indicators.GetResistanceLevels(bars), indicators.FindResistance(bars), currentPrice, false),
+		Whales:       whaleActivity(symbol, bars, cfg),
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var alignment *float64
+	mtf, err := multiTimeframe(symbol, daily, cfg)
+	if err != nil {
+		report.MultiTimeframeNote = fmt.Sprintf("multi-timeframe check unavailable: %v", err)
+	} else {
+		report.MultiTimeframe = mtf
+		alignment = &mtf.AlignmentPercent
+	}
+	report.TradeGrade = signalsPkg.GradeSignal(daily, bars, alignment)
+
+	report.Recommendations = reportRecommendations(report)
+	report.Summary = reportSummary(report)
+	return report, nil
+}
+
+// the combined signal for one timeframe's bars, and the RSI series behind it
+func timeframeSignal(symbol string, bars []types.Bar) (signalsPkg.CombinedSignal, []float64, error) {
+	rsiValues, err := indicators.CalculateRSI(extractClosingPrices(bars), 14)
+	if err != nil {
+		return signalsPkg.CombinedSignal{}, nil, fmt.Errorf("failed to calculate RSI: %w", err)
+	}
+	atrBars := make([]indicators.ATRBar, len(bars))
+	for i, bar := range bars {
+		atrBars[i] = indicators.ATRBar{High: bar.High, Low: bar.Low, Close: bar.Close}
+	}
+	atrValues, err := indicators.CalculateATR(atrBars, 14)
+	if err != nil {
+		return signalsPkg.CombinedSignal{}, nil, fmt.Errorf("failed to calculate ATR: %w", err)
+	}
+
+	rsi, atr := rsiValues[len(rsiValues)-1], atrValues[len(atrValues)-1]
+	return signalsPkg.CalculateSignal(&rsi, &atr, bars, symbol, GetLatestCandlePattern(bars, 1), rsiValues), rsiValues, nil
+}
+
+func reportSignal(signal signalsPkg.CombinedSignal) ReportSignal {
+	components := make([]ReportComponent, len(signal.Components))
+	for i, c := range signal.Components {
+		components[i] = ReportComponent{Name: c.Name, Score: c.Score, Weight: c.Weight}
+	}
+	return ReportSignal{
+		Recommendation: signal.Recommendation,
+		Score:          signal.Score,
+		Confidence:     signal.Confidence,
+		Reasoning:      signal.Reasoning,
+		Components:     components,
+	}
+}
+
+// the daily signal checked against fresh 4H and 1H signals, blended with the multi_timeframe weights
+func multiTimeframe(symbol string, daily signalsPkg.CombinedSignal, cfg *config.Config) (*ReportMultiTimeframe, error) {
+	intraday := make(map[string]signalsPkg.CombinedSignal, 2)
+	for _, timeframe := range []string{signalsPkg.TimeframeFourHour, signalsPkg.TimeframeOneHour} {
+		bars, err := reportBars(symbol, timeframe, reportIntradayBars)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s bars: %w", timeframe, err)
+		}
+		if len(bars) < reportMinBars {
+			return nil, fmt.Errorf("%w - only %d %s bars", utils.ErrInsufficientData, len(bars), timeframe)
+		}
+		signal, _, err := timeframeSignal(symbol, bars)
+		if err != nil {
+			return nil, fmt.Errorf("%s signal: %w", timeframe, err)
+		}
+		intraday[timeframe] = signal
+	}
+
+	weights := signalsPkg.MultiTimeframeWeights
+	if cfg != nil {
+		weights = signalsPkg.TimeframeWeights{
+			Daily:    cfg.MultiTimeframe.DailyWeight,
+			FourHour: cfg.MultiTimeframe.FourHourWeight,
+			OneHour:  cfg.MultiTimeframe.OneHourWeight,
+			Primary:  cfg.MultiTimeframe.Primary,
+		}
+	}
+	combined := signalsPkg.CombineMultiTimeframeSignalsWithWeights(daily, intraday[signalsPkg.TimeframeFourHour], intraday[signalsPkg.TimeframeOneHour], weights)
+	return &ReportMultiTimeframe{
+		Daily:            combined.DailySignal.Recommendation,
+		FourHour:         combined.FourHourSignal.Recommendation,
+		OneHour:          combined.OneHourSignal.Recommendation,
+		Aligned:          combined.Alignment,
+		Confirmed:        combined.IsMultiTimeframeConfirmed(true),
+		AlignmentPercent: combined.AlignmentPercent,
+		CompositeScore:   combined.CompositeScore,
+		RecommendedTrade: combined.RecommendedTrade,
+	}, nil
+}
+
+// detected chart patterns, most confident first
+func detectedPatterns(bars []types.Bar) []ReportPattern {
+	patterns := []ReportPattern{}
+	for _, p := range detection.NewPatternDetector().DetectAllPatterns(bars) {
+		if !p.Detected {
+			continue
+		}
+		patterns = append(patterns, ReportPattern{
+			Pattern:    string(p.Pattern),
+			Direction:  p.Direction,
+			Confidence: p.Confidence,
+			TargetUp:   p.PriceTargetUp,
+			TargetDown: p.PriceTargetDown,
+			StopLoss:   p.StopLossLevel,
+			Reasoning:  p.Reasoning,
+		})
+	}
+	sort.SliceStable(patterns, func(i, j int) bool { return patterns[i].Confidence > patterns[j].Confidence })
+	return patterns
+}
+
+// regular RSI divergence, or hidden divergence when there is no regular one
+func detectDivergence(bars []types.Bar, rsiValues []float64) *ReportDivergence {
+	if len(bars) < 20 {
+		return nil
+	}
+	detector := detection.NewDivergenceDetector()
+	for _, hidden := range []bool{false, true} {
+		var div detection.DivergenceSignal
+		if hidden {
+			div = detector.DetectHiddenDivergence(bars, rsiValues)
+		} else {
+			div = detector.DetectRSIDivergence(bars, rsiValues)
+		}
+		if !div.Detected || (div.Type != detection.DivergenceBullish && div.Type != detection.DivergenceBearish) {
+			continue
+		}
+		return &ReportDivergence{
+			Type:       string(div.Type),
+			Hidden:     hidden,
+			Direction:  div.Direction,
+			Confidence: div.Confidence,
+			Reasoning:  div.Reasoning,
+		}
+	}
+	return nil
+}
+
+// the swing level nearest price on the wanted side (below for support) and how often price has turned there;
+// starts from the range extreme (lowest low or highest high), which is what's left without a swing level there
+func levelQuality(levels []indicators.PriceLevel, extreme, price float64, below bool) LevelQuality {
+	level := extreme
+	for _, l := range levels {
+		if (below && l.Price < price && l.Price > level) || (!below && l.Price > price && l.Price < level) {
+			level = l.Price
+		}
+	}
+
+	quality := LevelQuality{Price: level, Strength: "weak"}
+	if level <= 0 || price <= 0 {
+		return quality
+	}
+	quality.DistancePercent = math.Abs(price-level) / price * 100
+	for _, l := range levels {
+		if math.Abs(l.Price-level)/level <= levelTouchTolerance {
+			quality.Touches++
+		}
+	}
+	switch {
+	case quality.Touches >= 3:
+		quality.Strength = "strong"
+	case quality.Touches == 2:
+		quality.Strength = "moderate"
+	}
+	return quality
+}
+
+// whale bars over the daily history, measured against the whales config baseline when there is one
+func whaleActivity(symbol string, bars []types.Bar, cfg *config.Config) WhaleActivity {
+	baseline := detection.DefaultWhaleBaseline
+	if cfg != nil && cfg.Whales.BaselineWindow > 0 {
+		baseline = detection.WhaleBaseline{
+			Window:            cfg.Whales.BaselineWindow,
+			IncludeCurrentBar: cfg.Whales.IncludeCurrentBar,
+			Robust:            cfg.Whales.RobustZScore,
+		}
+	}
+
+	whales := detection.DetectWhalesWithBaseline(symbol, bars, baseline)
+	activity := WhaleActivity{Events: len(whales), Bias: "NEUTRAL", BarsInspected: len(bars)}
+	for _, whale := range whales {
+		if whale.Conviction != "HIGH" {
+			continue
+		}
+		switch whale.Direction {
+		case "BUY":
+			activity.HighBuys++
+		case "SELL":
+			activity.HighSells++
+		}
+	}
+	if activity.HighBuys > activity.HighSells {
+		activity.Bias = "BUY"
+	} else if activity.HighSells > activity.HighBuys {
+		activity.Bias = "SELL"
+	}
+	if len(whales) > 0 {
+		latest := whales[len(whales)-1]
+		activity.LatestAt, activity.LatestZScore, activity.LatestVolume = latest.Timestamp, latest.ZScore, latest.Volume
+	}
+	return activity
+}
+
+func reportRecommendations(r *AnalysisReport) []string {
+	var recs []string
+
+	switch r.Signal.Recommendation {
+	case signalsPkg.RecommendationBuy, signalsPkg.RecommendationAccumulate:
+		recs = append(recs, fmt.Sprintf("Combined signal favors longs (%s, %.0f%% confidence)", r.Signal.Recommendation, r.Signal.Confidence))
+	case signalsPkg.RecommendationSell, signalsPkg.RecommendationDistribute:
+		recs = append(recs, fmt.Sprintf("Combined signal favors reducing or shorting (%s, %.0f%% confidence)", r.Signal.Recommendation, r.Signal.Confidence))
+	}
+
+	if mtf := r.MultiTimeframe; mtf != nil && r.Signal.Recommendation != signalsPkg.RecommendationWait {
+		if mtf.Confirmed {
+			recs = append(recs, "Daily, 4H and 1H agree - the setup is confirmed across timeframes")
+		} else if !mtf.Aligned {
+			recs = append(recs, "Wait for the 4H and 1H to line up with the daily signal before sizing in")
+		}
+	}
+
+	if d := r.Divergence; d != nil {
+		kind := "reversal"
+		if d.Hidden {
+			kind = "continuation"
+		}
+		if d.Type == string(detection.DivergenceBearish) {
+			recs = append(recs, fmt.Sprintf("Bearish RSI divergence warns of a %s lower - tighten stops on longs", kind))
+		} else {
+			recs = append(recs, fmt.Sprintf("Bullish RSI divergence points to a %s higher - watch for a long entry", kind))
+		}
+	}
+
+	if len(r.Patterns) > 0 {
+		p := r.Patterns[0]
+		rec := fmt.Sprintf("%s pattern (%.0f%% confidence) points %s", humanize(p.Pattern), p.Confidence, p.Direction)
+		if p.StopLoss > 0 {
+			rec += fmt.Sprintf(" - invalidated below $%.2f", p.StopLoss)
+		}
+		recs = append(recs, rec)
+	}
+
+	if s := r.Support; s.Price > 0 && s.DistancePercent <= nearLevelPercent {
+		recs = append(recs, fmt.Sprintf("Price is %.1f%% above %s support at $%.2f - a stop just under it keeps risk tight", s.DistancePercent, s.Strength, s.Price))
+	}
+	if res := r.Resistance; res.Price > 0 && res.DistancePercent <= nearLevelPercent {
+		recs = append(recs, fmt.Sprintf("Price is %.1f%% below %s resistance at $%.2f - expect selling there unless it breaks on volume", res.DistancePercent, res.Strength, res.Price))
+	}
+
+	switch r.Whales.Bias {
+	case "BUY":
+		recs = append(recs, fmt.Sprintf("%d high-conviction whale buy bars suggest institutional accumulation", r.Whales.HighBuys))
+	case "SELL":
+		recs = append(recs, fmt.Sprintf("%d high-conviction whale sell bars suggest institutional distribution", r.Whales.HighSells))
+	}
+
+	if len(recs) == 0 {
+		recs = append(recs, "No actionable setup - keep it on the watchlist and recheck after the next close")
+	}
+	return recs
+}
+
+func reportSummary(r *AnalysisReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s at $%.2f: %s (score %.2f, %.0f%% confidence, grade %s).",
+		r.Symbol, r.CurrentPrice, r.Signal.Recommendation, r.Signal.Score, r.Signal.Confidence, r.TradeGrade.Grade)
+
+	if mtf := r.MultiTimeframe; mtf != nil {
+		if mtf.Aligned {
+			fmt.Fprintf(&b, " Timeframes are aligned (daily %s, 4H %s, 1H %s).", mtf.Daily, mtf.FourHour, mtf.OneHour)
+		} else {
+			fmt.Fprintf(&b, " Timeframes disagree (daily %s, 4H %s, 1H %s).", mtf.Daily, mtf.FourHour, mtf.OneHour)
+		}
+	}
+
+	if len(r.Patterns) > 0 {
+		fmt.Fprintf(&b, " %s detected (%.0f%%).", humanize(r.Patterns[0].Pattern), r.Patterns[0].Confidence)
+	}
+	if d := r.Divergence; d != nil {
+		hidden := ""
+		if d.Hidden {
+			hidden = "hidden "
+		}
+		fmt.Fprintf(&b, " %s%s RSI divergence.", hidden, strings.ToLower(d.Type))
+	} else {
+		b.WriteString(" No RSI divergence.")
+	}
+
+	fmt.Fprintf(&b, " Support $%.2f (%s) is %.1f%% below; resistance $%.2f (%s) is %.1f%% above.",
+		r.Support.Price, r.Support.Strength, r.Support.DistancePercent,
+		r.Resistance.Price, r.Resistance.Strength, r.Resistance.DistancePercent)
+
+	if r.Whales.Bias != "NEUTRAL" {
+		fmt.Fprintf(&b, " Whale activity leans %s.", strings.ToLower(r.Whales.Bias))
+	}
+	return b.String()
+}
+
+// DOUBLE_BOTTOM -> Double bottom
+func humanize(pattern string) string {
+	words := strings.ToLower(strings.ReplaceAll(pattern, "_", " "))
+	if words == "" {
+		return words
+	}
+	return strings.ToUpper(words[:1]) + words[1:]
+}
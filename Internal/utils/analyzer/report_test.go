@@ -0,0 +1,124 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+// an uptrend that pulls back every fifth bar, oldest first
+func reportTestBars(n int) []types.Bar {
+	bars := make([]types.Bar, n)
+	day := time.Date(2024, 1, 2, 21, 0, 0, 0, time.UTC)
+	price := 100.0
+	for i := range bars {
+		step := 1.0
+		if i%5 == 4 {
+			step = -2.5
+		}
+		open := price
+		price += step
+		bars[i] = types.Bar{
+			Timestamp: day.AddDate(0, 0, i).Format(time.RFC3339),
+			Open:      open,
+			High:      max(open, price) + 0.5,
+			Low:       min(open, price) - 0.5,
+			Close:     price,
+			Volume:    1_000_000,
+		}
+	}
+	return bars
+}
+
+func stubReportBars(t *testing.T, fetch func(symbol, timeframe string, limit int) ([]types.Bar, error)) {
+	t.Helper()
+	original := reportBars
+	reportBars = fetch
+	t.Cleanup(func() { reportBars = original })
+}
+
+func TestGenerateAnalysisReport_AggregatesSections(t *testing.T) {
+	var fetched []string
+	stubReportBars(t, func(symbol, timeframe string, limit int) ([]types.Bar, error) {
+		fetched = append(fetched, timeframe)
+		return reportTestBars(60), nil
+	})
+
+	bars := reportTestBars(120)
+	report, err := GenerateAnalysisReport(context.Background(), "AAPL", bars, nil)
+	if err != nil {
+		t.Fatalf("GenerateAnalysisReport: %v", err)
+	}
+
+	if len(fetched) != 2 || fetched[0] != "4Hour" || fetched[1] != "1Hour" {
+		t.Errorf("fetched timeframes %v, want [4Hour 1Hour]", fetched)
+	}
+	if report.MultiTimeframe == nil || report.MultiTimeframeNote != "" {
+		t.Fatalf("Expected a multi-timeframe section, got %+v (note %q)", report.MultiTimeframe, report.MultiTimeframeNote)
+	}
+	if report.CurrentPrice != bars[len(bars)-1].Close || report.BarsAnalyzed != len(bars) {
+		t.Errorf("price/bars = %.2f/%d, want the latest close and %d bars", report.CurrentPrice, report.BarsAnalyzed, len(bars))
+	}
+	if report.Signal.Recommendation == "" || len(report.Signal.Components) == 0 {
+		t.Errorf("Expected the combined signal with its components, got %+v", report.Signal)
+	}
+	if report.Support.Price <= 0 || report.Support.Price >= report.CurrentPrice {
+		t.Errorf("support %.2f should sit below price %.2f", report.Support.Price, report.CurrentPrice)
+	}
+	if report.TradeGrade.Grade == "" {
+		t.Error("Expected a trade grade")
+	}
+	if len(report.Recommendations) == 0 {
+		t.Error("Expected at least one recommendation")
+	}
+	if !strings.HasPrefix(report.Summary, "AAPL at $") || !strings.Contains(report.Summary, "Support $") {
+		t.Errorf("summary = %q", report.Summary)
+	}
+}
+
+func TestGenerateAnalysisReport_IntradayFailureLeavesNote(t *testing.T) {
+	stubReportBars(t, func(symbol, timeframe string, limit int) ([]types.Bar, error) {
+		return nil, errors.New("feed down")
+	})
+
+	report, err := GenerateAnalysisReport(context.Background(), "AAPL", reportTestBars(60), nil)
+	if err != nil {
+		t.Fatalf("GenerateAnalysisReport: %v", err)
+	}
+	if report.MultiTimeframe != nil || !strings.Contains(report.MultiTimeframeNote, "feed down") {
+		t.Errorf("multi-timeframe = %+v, note %q; want nil with the fetch error", report.MultiTimeframe, report.MultiTimeframeNote)
+	}
+	if report.Summary == "" || len(report.Recommendations) == 0 {
+		t.Error("Expected the rest of the report to be filled in")
+	}
+}
+
+func TestGenerateAnalysisReport_RequiresHistory(t *testing.T) {
+	_, err := GenerateAnalysisReport(context.Background(), "AAPL", reportTestBars(10), nil)
+	if !errors.Is(err, utils.ErrInsufficientData) {
+		t.Errorf("err = %v, want ErrInsufficientData", err)
+	}
+}
+
+func TestLevelQuality_CountsTouches(t *testing.T) {
+	levels := []indicators.PriceLevel{{Price: 95}, {Price: 95.5}, {Price: 94.8}, {Price: 90}, {Price: 101}}
+
+	support := levelQuality(levels, 88, 100, true)
+	if support.Price != 95.5 || support.Touches != 3 || support.Strength != "strong" {
+		t.Errorf("support = %+v, want 95.5 with 3 touches (strong)", support)
+	}
+	if support.DistancePercent < 4.49 || support.DistancePercent > 4.51 {
+		t.Errorf("distance = %.2f%%, want 4.5%%", support.DistancePercent)
+	}
+
+	resistance := levelQuality(levels, 110, 102, false)
+	if resistance.Price != 110 || resistance.Strength != "weak" {
+		t.Errorf("resistance without swing highs above price = %+v, want the range high (weak)", resistance)
+	}
+}
@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"math"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+// stop/target settings behind the plan in trading_recommendation; set from the recommendation_targets config block
+var recommendationTargets = config.RecommendationTargetsConfig{StopLossPercent: 2, TakeProfitPercent: 5}
+
+// applies the recommendation_targets block; non-positive percentages keep the current ones
+func SetRecommendationTargets(cfg config.RecommendationTargetsConfig) {
+	if cfg.StopLossPercent <= 0 {
+		cfg.StopLossPercent = recommendationTargets.StopLossPercent
+	}
+	if cfg.TakeProfitPercent <= 0 {
+		cfg.TakeProfitPercent = recommendationTargets.TakeProfitPercent
+	}
+	recommendationTargets = cfg
+}
+
+// concrete levels for acting on a BUY or SELL recommendation
+type TradePlan struct {
+	Direction   string  `json:"direction"`
+	Entry       float64 `json:"entry_price"`
+	StopLoss    float64 `json:"stop_loss"`
+	TakeProfit  float64 `json:"take_profit"`
+	RiskReward  float64 `json:"risk_reward"`
+	StopBasis   string  `json:"stop_basis"`   // percent or atr
+	TargetBasis string  `json:"target_basis"` // percent or atr
+}
+
+// entry at the current price with the stop and target from CalculatePriceTargets, or ATR multiples of the
+// entry when those are configured and an ATR is known; nil for anything but BUY or SELL
+func BuildTradePlan(action string, price, atr float64, targets config.RecommendationTargetsConfig) *TradePlan {
+	direction := ""
+	switch action {
+	case "BUY":
+		direction = "LONG"
+	case "SELL":
+		direction = "SHORT"
+	}
+	if direction == "" || price <= 0 {
+		return nil
+	}
+
+	stopLoss, takeProfit := strategy.CalculatePriceTargets(price, direction, &strategy.OrderConfig{
+		StopLossPercent:   targets.StopLossPercent,
+		TakeProfitPercent: targets.TakeProfitPercent,
+	})
+	plan := &TradePlan{Direction: direction, Entry: price, StopLoss: stopLoss, TakeProfit: takeProfit, StopBasis: "percent", TargetBasis: "percent"}
+
+	// a short's levels mirror a long's around the entry
+	side := 1.0
+	if direction == "SHORT" {
+		side = -1.0
+	}
+	if atr > 0 && targets.ATRStopMultiplier > 0 {
+		plan.StopLoss = price - side*atr*targets.ATRStopMultiplier
+		plan.StopBasis = "atr"
+	}
+	if atr > 0 && targets.ATRTargetMultiplier > 0 {
+		plan.TakeProfit = price + side*atr*targets.ATRTargetMultiplier
+		plan.TargetBasis = "atr"
+	}
+
+	if risk := math.Abs(price - plan.StopLoss); risk > 0 {
+		plan.RiskReward = math.Abs(plan.TakeProfit-price) / risk
+	}
+	return plan
+}
+
+// adds the plan's levels to a CalculateTradingRecommendation result; a HOLD gets none
+func addTradePlan(recommendation map[string]interface{}, price, atr float64) {
+	action, _ := recommendation["action"].(string)
+	plan := BuildTradePlan(action, price, atr, recommendationTargets)
+	if plan == nil {
+		return
+	}
+	recommendation["entry_price"] = plan.Entry
+	recommendation["stop_loss"] = plan.StopLoss
+	recommendation["take_profit"] = plan.TakeProfit
+	recommendation["risk_reward"] = plan.RiskReward
+	recommendation["stop_basis"] = plan.StopBasis
+	recommendation["target_basis"] = plan.TargetBasis
+}
@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+func TestBuildTradePlan_LevelsOnCorrectSide(t *testing.T) {
+	percent := config.RecommendationTargetsConfig{StopLossPercent: 2, TakeProfitPercent: 5}
+	atr := config.RecommendationTargetsConfig{StopLossPercent: 2, TakeProfitPercent: 5, ATRStopMultiplier: 1.5, ATRTargetMultiplier: 3}
+
+	tests := []struct {
+		name                 string
+		action               string
+		targets              config.RecommendationTargetsConfig
+		wantStop, wantTarget float64
+		wantRR               float64
+		wantStopBasis        string
+	}{
+		{"buy with percent levels", "BUY", percent, 98, 105, 2.5, "percent"},
+		{"sell with percent levels", "SELL", percent, 102, 95, 2.5, "percent"},
+		{"buy with ATR levels", "BUY", atr, 97, 106, 2, "atr"},
+		{"sell with ATR levels", "SELL", atr, 103, 94, 2, "atr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := BuildTradePlan(tt.action, 100, 2, tt.targets)
+			if plan == nil {
+				t.Fatal("BuildTradePlan() = nil")
+			}
+			if math.Abs(plan.StopLoss-tt.wantStop) > 1e-9 || math.Abs(plan.TakeProfit-tt.wantTarget) > 1e-9 {
+				t.Errorf("stop/target = %.2f/%.2f, want %.2f/%.2f", plan.StopLoss, plan.TakeProfit, tt.wantStop, tt.wantTarget)
+			}
+			if tt.action == "BUY" && !(plan.StopLoss < plan.Entry && plan.Entry < plan.TakeProfit) {
+				t.Errorf("BUY plan stop %.2f / entry %.2f / target %.2f, want stop below and target above", plan.StopLoss, plan.Entry, plan.TakeProfit)
+			}
+			if tt.action == "SELL" && !(plan.TakeProfit < plan.Entry && plan.Entry < plan.StopLoss) {
+				t.Errorf("SELL plan stop %.2f / entry %.2f / target %.2f, want stop above and target below", plan.StopLoss, plan.Entry, plan.TakeProfit)
+			}
+			if math.Abs(plan.RiskReward-tt.wantRR) > 1e-9 || plan.StopBasis != tt.wantStopBasis {
+				t.Errorf("R:R %.2f (%s), want %.2f (%s)", plan.RiskReward, plan.StopBasis, tt.wantRR, tt.wantStopBasis)
+			}
+		})
+	}
+}
+
+func TestBuildTradePlan_NoPlan(t *testing.T) {
+	targets := config.RecommendationTargetsConfig{StopLossPercent: 2, TakeProfitPercent: 5, ATRStopMultiplier: 1.5}
+	if plan := BuildTradePlan("HOLD", 100, 2, targets); plan != nil {
+		t.Errorf("HOLD got a plan: %+v", plan)
+	}
+
+	// without an ATR the configured multiplier can't apply, so the percent stop stays
+	plan := BuildTradePlan("BUY", 100, 0, targets)
+	if plan == nil || plan.StopBasis != "percent" || plan.StopLoss != 98 {
+		t.Errorf("plan without ATR = %+v, want the 2%% stop", plan)
+	}
+}
+
+func TestAddTradePlan_HoldUnchanged(t *testing.T) {
+	hold := map[string]interface{}{"action": "HOLD", "confidence": 50.0, "reasoning": ""}
+	addTradePlan(hold, 100, 2)
+	if len(hold) != 3 {
+		t.Errorf("HOLD recommendation gained fields: %v", hold)
+	}
+
+	buy := map[string]interface{}{"action": "BUY", "confidence": 65.0, "reasoning": "RSI is oversold"}
+	addTradePlan(buy, 100, 2)
+	if buy["entry_price"] != 100.0 || buy["stop_loss"] == nil || buy["take_profit"] == nil || buy["risk_reward"] == nil {
+		t.Errorf("BUY recommendation = %v, want entry, stop, target and R:R", buy)
+	}
+}
@@ -0,0 +1,214 @@
+package chart
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+const (
+	DefaultWidth  = 900
+	DefaultHeight = 600
+
+	padding       = 10
+	priceFraction = 0.7 // share of the plot height given to candles, the rest is the RSI panel
+	panelGap      = 12
+)
+
+var (
+	background    = color.RGBA{R: 18, G: 20, B: 28, A: 255}
+	gridColor     = color.RGBA{R: 48, G: 52, B: 64, A: 255}
+	upColor       = color.RGBA{R: 38, G: 166, B: 91, A: 255}
+	downColor     = color.RGBA{R: 214, G: 69, B: 65, A: 255}
+	rsiColor      = color.RGBA{R: 142, G: 118, B: 232, A: 255}
+	supportColor  = color.RGBA{R: 52, G: 152, B: 219, A: 255}
+	resistColor   = color.RGBA{R: 243, G: 156, B: 18, A: 255}
+	rsiBandsColor = color.RGBA{R: 90, G: 94, B: 110, A: 255}
+)
+
+var ErrNoBars = errors.New("no bars to chart")
+
+// what a chart draws: candles oldest first, an RSI value per bar (0 where undefined) and the S/R levels
+type Data struct {
+	Bars       []types.Bar
+	RSI        []float64
+	Support    float64 // 0 skips the line
+	Resistance float64 // 0 skips the line
+}
+
+// renders candles over an RSI panel with S/R levels as a PNG; zero width/height use the defaults
+func RenderPNG(data Data, width, height int) ([]byte, error) {
+	if len(data.Bars) == 0 {
+		return nil, ErrNoBars
+	}
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	if height <= 0 {
+		height = DefaultHeight
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: background}, image.Point{}, draw.Src)
+
+	plotHeight := height - 2*padding - panelGap
+	pricePanel := image.Rect(padding, padding, width-padding, padding+int(float64(plotHeight)*priceFraction))
+	rsiPanel := image.Rect(padding, pricePanel.Max.Y+panelGap, width-padding, height-padding)
+
+	drawCandles(img, pricePanel, data)
+	drawRSI(img, rsiPanel, data.RSI)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// the PNG as a data URL so a report can embed it inline
+func DataURL(pngBytes []byte) string {
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngBytes)
+}
+
+func drawCandles(img *image.RGBA, panel image.Rectangle, data Data) {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, bar := range data.Bars {
+		low = math.Min(low, bar.Low)
+		high = math.Max(high, bar.High)
+	}
+	for _, level := range []float64{data.Support, data.Resistance} {
+		if level > 0 {
+			low = math.Min(low, level)
+			high = math.Max(high, level)
+		}
+	}
+	if high <= low {
+		high = low + 1
+	}
+	y := func(price float64) int {
+		return panel.Max.Y - int((price-low)/(high-low)*float64(panel.Dy()-1))
+	}
+
+	strokeRect(img, panel, gridColor)
+
+	slot := float64(panel.Dx()) / float64(len(data.Bars))
+	body := int(slot * 0.6)
+	if body < 1 {
+		body = 1
+	}
+	for i, bar := range data.Bars {
+		center := panel.Min.X + int(slot*(float64(i)+0.5))
+		c := upColor
+		if bar.Close < bar.Open {
+			c = downColor
+		}
+		vLine(img, center, y(bar.High), y(bar.Low), c)
+
+		top, bottom := y(math.Max(bar.Open, bar.Close)), y(math.Min(bar.Open, bar.Close))
+		if bottom == top {
+			bottom++
+		}
+		draw.Draw(img, image.Rect(center-body/2, top, center-body/2+body, bottom), &image.Uniform{C: c}, image.Point{}, draw.Src)
+	}
+
+	if data.Support > 0 {
+		dashedHLine(img, panel.Min.X, panel.Max.X, y(data.Support), supportColor)
+	}
+	if data.Resistance > 0 {
+		dashedHLine(img, panel.Min.X, panel.Max.X, y(data.Resistance), resistColor)
+	}
+}
+
+func drawRSI(img *image.RGBA, panel image.Rectangle, rsi []float64) {
+	strokeRect(img, panel, gridColor)
+	y := func(value float64) int {
+		return panel.Max.Y - int(value/100*float64(panel.Dy()-1))
+	}
+	dashedHLine(img, panel.Min.X, panel.Max.X, y(70), rsiBandsColor)
+	dashedHLine(img, panel.Min.X, panel.Max.X, y(30), rsiBandsColor)
+
+	if len(rsi) == 0 {
+		return
+	}
+	slot := float64(panel.Dx()) / float64(len(rsi))
+	prevX, prevY, started := 0, 0, false
+	for i, value := range rsi {
+		if value <= 0 {
+			// the first period has no RSI yet
+			continue
+		}
+		x, vy := panel.Min.X+int(slot*(float64(i)+0.5)), y(value)
+		if started {
+			line(img, prevX, prevY, x, vy, rsiColor)
+		}
+		prevX, prevY, started = x, vy, true
+	}
+}
+
+func strokeRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	for x := r.Min.X; x < r.Max.X; x++ {
+		img.Set(x, r.Min.Y, c)
+		img.Set(x, r.Max.Y-1, c)
+	}
+	vLine(img, r.Min.X, r.Min.Y, r.Max.Y-1, c)
+	vLine(img, r.Max.X-1, r.Min.Y, r.Max.Y-1, c)
+}
+
+func vLine(img *image.RGBA, x, y0, y1 int, c color.Color) {
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	for y := y0; y <= y1; y++ {
+		img.Set(x, y, c)
+	}
+}
+
+func dashedHLine(img *image.RGBA, x0, x1, y int, c color.Color) {
+	for x := x0; x < x1; x++ {
+		if (x-x0)%8 < 5 {
+			img.Set(x, y, c)
+		}
+	}
+}
+
+// Bresenham line between two points
+func line(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
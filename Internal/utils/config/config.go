@@ -37,10 +37,341 @@ type Config struct {
 	Profiles map[string]ProfileConfig `yaml:"profiles"`
 
 	Features struct {
-		CryptoSupport      bool   `yaml:"crypto_support"`
-		EnableShortSignals bool   `yaml:"enable_short_signals"`
-		AssetType          string `yaml:"asset_type"`
+		CryptoSupport                   bool     `yaml:"crypto_support"`
+		EnableShortSignals              bool     `yaml:"enable_short_signals"`
+		ShortSignalWeight               float64  `yaml:"short_signal_weight" default:"1"` // scales short-setup points against long ones in the screener
+		ShortCandidateScore             float64  `yaml:"short_candidate_score"`           // raw score at or below this (negative) is scouted as a short instead of floored to 0, 0 disables
+		AssetType                       string   `yaml:"asset_type"`
+		PersistSignals                  bool     `yaml:"persist_signals"`                      // store every computed signal in the signal_history table
+		PersistScanRuns                 bool     `yaml:"persist_scan_runs"`                    // record each profile scan in the scan_runs table
+		LogSkippedSymbols               bool     `yaml:"log_skipped_symbols"`                  // log each symbol a scan skips and list them in the skip summary
+		ChartRendering                  bool     `yaml:"chart_rendering"`                      // serve server-rendered PNG charts at /api/chart
+		OmitZeroSignalComponents        bool     `yaml:"omit_zero_signal_components"`          // leave zero-contribution components out of signal breakdowns
+		CryptoSymbols                   []string `yaml:"crypto_symbols"`                       // bases detected as crypto when typed without a slash (BTCUSD)
+		AggregateLots                   bool     `yaml:"aggregate_lots"`                       // show scaled-in lots of a symbol as one net position for display and risk
+		SignalConfirmationBars          int      `yaml:"signal_confirmation_bars" default:"1"` // consecutive bars a signal's side must hold before it's confirmed
+		RecalculateLevelsOnConfigChange bool     `yaml:"recalculate_levels_on_config_change"`  // recompute open positions' stop/target (and OCO legs) when the stop/take-profit percents change
+		TrackBracketLegs                bool     `yaml:"track_bracket_legs"`                   // close positions when a bracket entry's stop or take-profit leg fills at the broker
+		BracketOrders                   bool     `yaml:"bracket_orders"`                       // submit manual stock entries as brackets so the broker holds the stop and take-profit
+		SessionVWAP                     bool     `yaml:"session_vwap"`                         // restart intraday VWAP at each trading day in global.market_hours.timezone
+		TrackSlippage                   bool     `yaml:"track_slippage"`                       // store each executed trade's expected price and fill slippage in bps
+		QuoteMissingMarketValues        bool     `yaml:"quote_missing_market_values"`          // value positions Alpaca returns without a market value from the latest close
 	} `yaml:"features"`
+
+	MarketRegime MarketRegimeConfig `yaml:"market_regime"`
+
+	AuctionOrders AuctionOrdersConfig `yaml:"auction_orders"`
+
+	Retention RetentionConfig `yaml:"retention"`
+
+	AutoExit AutoExitConfig `yaml:"auto_exit"`
+
+	TradeThrottle TradeThrottleConfig `yaml:"trade_throttle"`
+
+	EODClose EODCloseConfig `yaml:"eod_close"`
+
+	NewsHalt NewsHaltConfig `yaml:"news_halt"`
+
+	AssetClassRisk AssetClassRiskConfig `yaml:"asset_class_risk"`
+
+	PositionSizing PositionSizingConfig `yaml:"position_sizing"`
+
+	Patterns PatternsConfig `yaml:"patterns"`
+
+	Whales WhalesConfig `yaml:"whales"`
+
+	DataFreshness DataFreshnessConfig `yaml:"data_freshness"`
+
+	OrderSubmit OrderSubmitConfig `yaml:"order_submit"`
+
+	BarRetry BarRetryConfig `yaml:"bar_retry"`
+
+	OrderFill OrderFillConfig `yaml:"order_fill"`
+
+	RSICrossover RSICrossoverConfig `yaml:"rsi_crossover"`
+
+	Bollinger BollingerConfig `yaml:"bollinger"`
+
+	BuyingPower BuyingPowerConfig `yaml:"buying_power"`
+
+	TradeGrade TradeGradeConfig `yaml:"trade_grade"`
+
+	AssetCache AssetCacheConfig `yaml:"asset_cache"`
+
+	RecommendationTargets RecommendationTargetsConfig `yaml:"recommendation_targets"`
+
+	Earnings EarningsConfig `yaml:"earnings"`
+
+	MultiTimeframe MultiTimeframeConfig `yaml:"multi_timeframe"`
+
+	BarValidation BarValidationConfig `yaml:"bar_validation"`
+
+	PortfolioHeat PortfolioHeatConfig `yaml:"portfolio_heat"`
+
+	VolumeDryUp VolumeDryUpConfig `yaml:"volume_dry_up"`
+
+	MarketBreadth MarketBreadthConfig `yaml:"market_breadth"`
+
+	StopNoise StopNoiseConfig `yaml:"stop_noise"`
+
+	AlertCooldown AlertCooldownConfig `yaml:"alert_cooldown"`
+
+	ScoreExport ScoreExportConfig `yaml:"score_export"`
+
+	CryptoScoring CryptoScoringConfig `yaml:"crypto_scoring"`
+
+	DelistingDetection DelistingDetectionConfig `yaml:"delisting_detection"`
+}
+
+// flags symbols whose bar fetches keep failing (delisted or renamed) so scans stop retrying them; flagged
+// symbols stay skipped until cleared through /api/problem-symbols
+type DelistingDetectionConfig struct {
+	Enabled          bool `yaml:"enabled" default:"true"`
+	FailureThreshold int  `yaml:"failure_threshold" default:"3"` // consecutive failed scans before a symbol is flagged
+}
+
+// screener thresholds for crypto pairs, which trade around the clock with wider swings; profile price filters
+// are skipped for them
+type CryptoScoringConfig struct {
+	MinOversoldRSI float64 `yaml:"min_oversold_rsi" default:"30"`
+	MaxRSI         float64 `yaml:"max_rsi" default:"80"`
+	MinVolumeRatio float64 `yaml:"min_volume_ratio" default:"1.5"` // latest volume vs its 20-bar average before volume scores
+}
+
+// writes every scored symbol's per-component screener points to a CSV per scan, for offline weight tuning
+type ScoreExportConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Directory string `yaml:"directory" default:"exported_data/score_breakdowns"`
+}
+
+// identical risk events and alerts (same type and symbol) inside window_seconds update the first one instead
+// of piling up; disabling records and sends every repeat
+type AlertCooldownConfig struct {
+	Enabled       bool `yaml:"enabled"`
+	WindowSeconds int  `yaml:"window_seconds" default:"180"`
+}
+
+// flags percent stops sitting inside min_atr_multiple ATRs of entry, where normal noise tends to hit them;
+// auto_widen moves such a stop out to that distance instead of only warning
+type StopNoiseConfig struct {
+	Enabled        bool    `yaml:"enabled"`
+	ATRPeriod      int     `yaml:"atr_period" default:"14"`
+	MinATRMultiple float64 `yaml:"min_atr_multiple" default:"1"`
+	AutoWiden      bool    `yaml:"auto_widen"`
+}
+
+// universe and windows for the /api/market/breadth panel; with no symbols listed the first max_symbols
+// tradable assets are used
+type MarketBreadthConfig struct {
+	Enabled         bool     `yaml:"enabled"`
+	Symbols         []string `yaml:"symbols"`
+	MaxSymbols      int      `yaml:"max_symbols" default:"200"`
+	SMAPeriod       int      `yaml:"sma_period" default:"20"`
+	HighLowLookback int      `yaml:"high_low_lookback" default:"252"` // bars a close has to clear to count as a new high or low
+	Concurrency     int      `yaml:"concurrency" default:"8"`
+}
+
+// small "coiling" bonus in the screener when recent volume contracts well below its baseline
+type VolumeDryUpConfig struct {
+	Enabled  bool    `yaml:"enabled"`
+	Lookback int     `yaml:"lookback" default:"5"`    // recent bars averaged against the 20 before them
+	MaxRatio float64 `yaml:"max_ratio" default:"0.6"` // recent/baseline volume at or below this counts as dried up
+	Points   float64 `yaml:"points" default:"0.3"`
+}
+
+// green/yellow/red bands for total risk-at-stop as a percent of equity; enabled stores a snapshot every interval
+type PortfolioHeatConfig struct {
+	Enabled                 bool    `yaml:"enabled"`
+	YellowPercent           float64 `yaml:"yellow_percent" default:"5"`
+	RedPercent              float64 `yaml:"red_percent" default:"8"`
+	SnapshotIntervalMinutes int     `yaml:"snapshot_interval_minutes" default:"15"`
+}
+
+// drops fetched bars with non-positive prices, high below low or out-of-order timestamps before any indicator math
+type BarValidationConfig struct {
+	Enabled       bool    `yaml:"enabled"`
+	MaxDroppedPct float64 `yaml:"max_dropped_pct" default:"20"` // fail the fetch when more than this share of bars is malformed
+}
+
+// blend of the daily/4H/1H signals; swing setups lead with 1Day, scalps with 1Hour. Weights must be non-negative
+type MultiTimeframeConfig struct {
+	DailyWeight    float64 `yaml:"daily_weight" default:"0.5"`
+	FourHourWeight float64 `yaml:"four_hour_weight" default:"0.35"`
+	OneHourWeight  float64 `yaml:"one_hour_weight" default:"0.15"`
+	Primary        string  `yaml:"primary" default:"1Day"` // 1Day, 4Hour or 1Hour
+}
+
+// keeps new entries out of the days before a scheduled earnings report and flags held symbols heading into one
+type EarningsConfig struct {
+	Enabled   bool `yaml:"enabled"`
+	AvoidDays int  `yaml:"avoid_days" default:"3"` // no new entries this many calendar days before earnings
+	WarnDays  int  `yaml:"warn_days" default:"5"`  // open positions reporting this soon are flagged
+}
+
+// entry/stop/target plan attached to the analyze endpoint's trading recommendation. Stops and targets are
+// percentages of the entry unless the matching ATR multiplier is set
+type RecommendationTargetsConfig struct {
+	StopLossPercent     float64 `yaml:"stop_loss_percent" default:"2"`
+	TakeProfitPercent   float64 `yaml:"take_profit_percent" default:"5"`
+	ATRStopMultiplier   float64 `yaml:"atr_stop_multiplier"`   // > 0 places the stop this many ATRs from entry
+	ATRTargetMultiplier float64 `yaml:"atr_target_multiplier"` // > 0 places the target this many ATRs from entry
+}
+
+// how long the tradable-asset listing is reused across scans before Alpaca is asked again
+type AssetCacheConfig struct {
+	Enabled  bool `yaml:"enabled"`
+	TTLHours int  `yaml:"ttl_hours" default:"24"`
+}
+
+// minimum 0-100 composite (signal confidence, timeframe alignment, S/R validation, pattern confidence)
+// for each letter grade; below d is an F
+type TradeGradeConfig struct {
+	A float64 `yaml:"a" default:"80"`
+	B float64 `yaml:"b" default:"65"`
+	C float64 `yaml:"c" default:"50"`
+	D float64 `yaml:"d" default:"35"`
+}
+
+// checks a new entry's cost against the account's buying power before submission, so the broker never rejects it for funds
+type BuyingPowerConfig struct {
+	Enabled        bool    `yaml:"enabled"`
+	ResizeToFit    bool    `yaml:"resize_to_fit"`   // shrink an oversized order to what buying power covers instead of rejecting it
+	ReservePercent float64 `yaml:"reserve_percent"` // share of buying power left unused, a cushion for the fill moving past the quoted price
+}
+
+// fast/slow RSI crossover term in the combined signal, for earlier momentum turns than RSI(14) alone
+type RSICrossoverConfig struct {
+	Enabled      bool `yaml:"enabled"`
+	FastPeriod   int  `yaml:"fast_period" default:"5"`
+	SlowPeriod   int  `yaml:"slow_period" default:"14"`
+	LookbackBars int  `yaml:"lookback_bars" default:"3"` // bars a cross keeps counting after it happens
+}
+
+// Bollinger band term in the combined signal, scoring pullbacks to the lower band while the trend is up
+type BollingerConfig struct {
+	Enabled    bool    `yaml:"enabled"`
+	Period     int     `yaml:"period" default:"20"`
+	StdDevMult float64 `yaml:"std_dev_mult" default:"2"`
+}
+
+// retries of an order submission that failed on a network error; each order carries a client order ID,
+// so a retry never duplicates an order the broker already accepted
+type OrderSubmitConfig struct {
+	MaxAttempts        int `yaml:"max_attempts" default:"3"`
+	RetryBackoffMillis int `yaml:"retry_backoff_ms" default:"500"` // wait before the first retry, doubled after each one
+}
+
+// backoff for bar fetches that hit Alpaca rate limits (429) or provider errors (5xx) during scans
+type BarRetryConfig struct {
+	MaxRetries      int `yaml:"max_retries" default:"5"`
+	BaseDelayMillis int `yaml:"base_delay_ms" default:"500"` // wait before the first retry, doubled after each one unless Retry-After asks for longer
+}
+
+// polls a submitted entry until it fills so the position is seeded with the real quantity and price
+type OrderFillConfig struct {
+	Enabled            bool `yaml:"enabled"`
+	TimeoutSeconds     int  `yaml:"timeout_seconds" default:"30"`
+	PollIntervalMillis int  `yaml:"poll_interval_ms" default:"500"`
+}
+
+// refuses to trade off bars that stopped updating (halted or stale symbols)
+type DataFreshnessConfig struct {
+	Enabled          bool `yaml:"enabled"`
+	MaxBarAgeMinutes int  `yaml:"max_bar_age_minutes" default:"30"` // allowed age beyond one bar period while the market is open
+	WarnOnly         bool `yaml:"warn_only"`                        // log stale data instead of rejecting the order
+}
+
+// chart pattern detection tuning
+type PatternsConfig struct {
+	BreakoutVolumeMultiplier float64 `yaml:"breakout_volume_multiplier" default:"1.3"` // breakout volume vs the average volume before it
+	BreakoutVolumePeriod     int     `yaml:"breakout_volume_period" default:"20"`      // bars averaged for that comparison
+}
+
+// volume baseline behind whale detection
+type WhalesConfig struct {
+	BaselineWindow    int  `yaml:"baseline_window" default:"20"` // bars in the rolling volume baseline
+	IncludeCurrentBar bool `yaml:"include_current_bar"`          // count the bar being tested in its own baseline
+	RobustZScore      bool `yaml:"robust_zscore"`                // median/MAD z-score so one huge spike doesn't skew the baseline
+}
+
+// how the per-trade risk budget is sized
+type PositionSizingConfig struct {
+	ScaleByOpenPositions bool    `yaml:"scale_by_open_positions"` // shrink each new trade's risk budget by 1/sqrt(open positions + 1)
+	BetaAdjust           bool    `yaml:"beta_adjust"`             // divide the risk budget by the symbol's beta, so high-beta names get smaller
+	BetaBenchmark        string  `yaml:"beta_benchmark" default:"SPY"`
+	BetaLookbackDays     int     `yaml:"beta_lookback_days" default:"60"` // daily returns the beta is computed over
+	BetaCacheHours       int     `yaml:"beta_cache_hours" default:"24"`
+	MinBeta              float64 `yaml:"min_beta" default:"0.5"` // beta floor, caps how far a low-beta name's budget can grow
+}
+
+// watches fresh news on held symbols and halts a position on a strong negative catalyst
+type NewsHaltConfig struct {
+	Enabled              bool    `yaml:"enabled"`
+	MinImpact            float64 `yaml:"min_impact" default:"0.2"`             // catalyst impact (0-1) an article needs to count
+	MinNegativeSentiment float64 `yaml:"min_negative_sentiment" default:"0.5"` // headline sentiment must be at or below minus this
+	LookbackHours        int     `yaml:"lookback_hours" default:"24"`          // ignore articles older than this
+	CheckIntervalMinutes int     `yaml:"check_interval_minutes" default:"5"`
+	AutoExit             bool    `yaml:"auto_exit"` // close the position as well as alerting
+}
+
+// per-asset-class overrides of the order risk limits; zero fields fall back to the global order config
+type AssetClassRiskConfig struct {
+	Equity AssetRiskConfig `yaml:"equity"`
+	Crypto AssetRiskConfig `yaml:"crypto"`
+}
+
+type AssetRiskConfig struct {
+	MaxPortfolioPercent float64 `yaml:"max_portfolio_percent"`
+	MaxOpenPositions    int     `yaml:"max_open_positions"`
+	StopLossPercent     float64 `yaml:"stop_loss_percent"`
+	TakeProfitPercent   float64 `yaml:"take_profit_percent"`
+	SafeBailPercent     float64 `yaml:"safe_bail_percent"`
+}
+
+// flattens positions shortly before the regular close for intraday-only strategies
+type EODCloseConfig struct {
+	Enabled            bool `yaml:"enabled"`
+	MinutesBeforeClose int  `yaml:"minutes_before_close" default:"15"`
+	IntradayOnly       bool `yaml:"intraday_only"` // only close positions tagged intraday
+}
+
+// caps on new positions per rolling window to guard against runaway automated entries, 0 disables a cap
+type TradeThrottleConfig struct {
+	MaxEntriesPerHour int `yaml:"max_entries_per_hour"`
+	MaxEntriesPerDay  int `yaml:"max_entries_per_day"`
+}
+
+// lets the position monitor submit exits itself instead of only alerting
+type AutoExitConfig struct {
+	Enabled            bool    `yaml:"enabled"`                   // default off: hits are only logged for a manual close
+	TakeProfitScaleOut float64 `yaml:"take_profit_scale_out"`     // fraction sold on a take-profit hit, 0 or >= 1 closes the whole position
+	TrailAfterSafeBail bool    `yaml:"trail_after_safe_bail"`     // sell the safe-bail fraction, then let the rest ride a trailing take-profit
+	TrailPercent       float64 `yaml:"trail_percent" default:"2"` // how far the trailing take-profit follows the best price
+}
+
+// periodic pruning of old rows from tables that grow with every scan
+type RetentionConfig struct {
+	Enabled       bool           `yaml:"enabled"`
+	RunOnStartup  bool           `yaml:"run_on_startup"`
+	IntervalHours int            `yaml:"interval_hours" default:"24"` // defaults to 24
+	Tables        map[string]int `yaml:"tables"`                      // table name -> days to keep, 0 keeps rows forever
+}
+
+// submission windows (HH:MM Eastern) for on-open (opg) and on-close (cls) orders
+type AuctionOrdersConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	CloseCutoff    string `yaml:"close_cutoff" default:"15:50"`     // last time cls orders are accepted, defaults to 15:50
+	OpenCutoff     string `yaml:"open_cutoff" default:"09:28"`      // last time opg orders are accepted, defaults to 09:28
+	OpenAcceptFrom string `yaml:"open_accept_from" default:"19:00"` // earliest time opg orders for the next session are accepted, defaults to 19:00
+}
+
+// benchmark trend gate applied to scout scores
+type MarketRegimeConfig struct {
+	Enabled       bool    `yaml:"enabled"`
+	Benchmark     string  `yaml:"benchmark" default:"SPY"` // defaults to SPY
+	SMAPeriod     int     `yaml:"sma_period" default:"50"` // defaults to 50
+	LongDampening float64 `yaml:"long_dampening"`          // multiplier for long scores in a bearish regime
+	ShortBoost    float64 `yaml:"short_boost"`             // multiplier for short scores in a bearish regime
 }
 
 type ProfileConfig struct {
@@ -48,6 +379,17 @@ type ProfileConfig struct {
 	ScanIntervalDays int             `yaml:"scan_interval_days"`
 	Indicators       IndicatorConfig `yaml:"indicators"`
 	SignalWeights    SignalWeights   `yaml:"signal_weights"`
+	WatchlistOutput  WatchlistOutput `yaml:"watchlist_output"`
+	QualityGate      string          `yaml:"quality_gate" default:"lenient"` // "lenient" (default) penalizes filtered signals, "strict" drops the candidate
+	MinPrice         float64         `yaml:"min_price"`                      // skip symbols trading below this share price, 0 disables
+	MaxPrice         float64         `yaml:"max_price"`                      // skip symbols trading above this share price, 0 disables
+	MinADX           float64         `yaml:"min_adx"`                        // trend strength a signal needs to pass the quality filter, 0 disables
+}
+
+// controls whether profile scans write qualifying candidates into the watchlist
+type WatchlistOutput struct {
+	Enabled             bool `yaml:"enabled"`
+	PruneBelowThreshold bool `yaml:"prune_below_threshold"`
 }
 
 type IndicatorConfig struct {
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// one setting in the config tree, nested for struct and map sections
+type SchemaField struct {
+	Key     string        `json:"key"`
+	Path    string        `json:"path"` // dotted yaml path, e.g. profiles.balanced.threshold
+	Type    string        `json:"type"` // object, map, list, bool, int, float or string
+	Default interface{}   `json:"default,omitempty"`
+	Value   interface{}   `json:"value,omitempty"`
+	Fields  []SchemaField `json:"fields,omitempty"`
+}
+
+// walks the config by yaml tags so new fields show up without touching the schema;
+// a `default:"..."` tag documents the fallback the code applies when the value is unset
+func BuildSchema(cfg *Config) []SchemaField {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	return structSchema(reflect.ValueOf(*cfg), "")
+}
+
+// finds a field by its dotted path, nil when absent
+func FindSchemaField(fields []SchemaField, path string) *SchemaField {
+	for i := range fields {
+		if fields[i].Path == path {
+			return &fields[i]
+		}
+		if strings.HasPrefix(path, fields[i].Path+".") {
+			return FindSchemaField(fields[i].Fields, path)
+		}
+	}
+	return nil
+}
+
+func structSchema(v reflect.Value, prefix string) []SchemaField {
+	t := v.Type()
+	fields := make([]SchemaField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = strings.ToLower(sf.Name)
+		}
+		fields = append(fields, valueSchema(v.Field(i), key, joinPath(prefix, key), sf.Tag.Get("default")))
+	}
+	return fields
+}
+
+func valueSchema(v reflect.Value, key, path, defaultTag string) SchemaField {
+	field := SchemaField{Key: key, Path: path, Type: schemaType(v.Kind())}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		field.Fields = structSchema(v, path)
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, fmt.Sprint(k.Interface()))
+		}
+		sort.Strings(keys)
+
+		if v.Type().Elem().Kind() == reflect.Struct {
+			for _, k := range keys {
+				entry := v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key()))
+				field.Fields = append(field.Fields, valueSchema(entry, k, joinPath(path, k), ""))
+			}
+		} else {
+			values := make(map[string]interface{}, len(keys))
+			for _, k := range keys {
+				values[k] = v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key())).Interface()
+			}
+			field.Value = values
+		}
+	default:
+		field.Value = v.Interface()
+		field.Default = schemaDefault(v.Type(), defaultTag)
+	}
+	return field
+}
+
+// parses the default tag into the field's type, falling back to the zero value
+func schemaDefault(t reflect.Type, tag string) interface{} {
+	if tag == "" {
+		return reflect.Zero(t).Interface()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(tag); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		if n, err := strconv.Atoi(tag); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(tag, 64); err == nil {
+			return f
+		}
+	case reflect.String:
+		return tag
+	}
+	return reflect.Zero(t).Interface()
+}
+
+func schemaType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Struct:
+		return "object"
+	case reflect.Map:
+		return "map"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	default:
+		return "string"
+	}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
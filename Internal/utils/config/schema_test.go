@@ -0,0 +1,59 @@
+package config
+
+import "testing"
+
+func TestBuildSchema_IncludesKnownFieldsWithCurrentValues(t *testing.T) {
+	cfg := &Config{
+		Profiles: map[string]ProfileConfig{
+			"balanced": {
+				Threshold:     6.5,
+				SignalWeights: SignalWeights{RSIWeight: 0.3},
+			},
+		},
+		Retention: RetentionConfig{Tables: map[string]int{"signal_history": 90}},
+	}
+	cfg.Features.EnableShortSignals = true
+	cfg.Global.MarketHours.Timezone = "America/New_York"
+	cfg.MarketRegime.SMAPeriod = 20
+
+	schema := BuildSchema(cfg)
+
+	cases := []struct {
+		path     string
+		typ      string
+		value    interface{}
+		defaults interface{}
+	}{
+		{"features.enable_short_signals", "bool", true, false},
+		{"global.market_hours.timezone", "string", "America/New_York", ""},
+		{"profiles.balanced.threshold", "float", 6.5, 0.0},
+		{"profiles.balanced.signal_weights.rsi_weight", "float", 0.3, 0.0},
+		{"market_regime.sma_period", "int", 20, 50},
+		{"market_regime.benchmark", "string", "", "SPY"},
+		{"auto_exit.enabled", "bool", false, false},
+	}
+	for _, c := range cases {
+		field := FindSchemaField(schema, c.path)
+		if field == nil {
+			t.Errorf("schema missing %s", c.path)
+			continue
+		}
+		if field.Type != c.typ {
+			t.Errorf("%s type = %s, want %s", c.path, field.Type, c.typ)
+		}
+		if field.Value != c.value {
+			t.Errorf("%s value = %v, want %v", c.path, field.Value, c.value)
+		}
+		if field.Default != c.defaults {
+			t.Errorf("%s default = %v, want %v", c.path, field.Default, c.defaults)
+		}
+	}
+
+	tables := FindSchemaField(schema, "retention.tables")
+	if tables == nil || tables.Type != "map" {
+		t.Fatalf("retention.tables = %+v, want a map field", tables)
+	}
+	if values, ok := tables.Value.(map[string]interface{}); !ok || values["signal_history"] != 90 {
+		t.Errorf("retention.tables value = %v, want signal_history: 90", tables.Value)
+	}
+}
@@ -0,0 +1,10 @@
+package utils
+
+import "errors"
+
+// typed errors shared across packages so callers (and the API) can tell failures apart without matching strings
+var (
+	ErrInsufficientData = errors.New("insufficient data")
+	ErrRateLimited      = errors.New("rate limited by data provider")
+	ErrSymbolNotFound   = errors.New("symbol not found")
+)
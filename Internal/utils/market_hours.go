@@ -53,6 +53,23 @@ func CheckMarketStatus(t time.Time, cfg *config.Config) (status string, isOpen b
 	return "CLOSED", false
 }
 
+// minutes left in the regular session at t; false outside regular hours or when the config can't be parsed
+func MinutesUntilRegularClose(t time.Time, cfg *config.Config) (int, bool) {
+	if status, _ := CheckMarketStatus(t, cfg); status != "REGULAR" {
+		return 0, false
+	}
+	timeInEST, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return 0, false
+	}
+	regularClose, err := parseTimeToMinutes(cfg.Global.MarketHours.RegularClose)
+	if err != nil {
+		return 0, false
+	}
+	hour, min, _ := t.In(timeInEST).Clock()
+	return regularClose - (hour*60 + min), true
+}
+
 func parseTimeToMinutes(timeStr string) (int, error) {
 	if timeStr == "" {
 		return -1, errors.New("invalid time string")
@@ -1,6 +1,9 @@
 package utils
 
-import "math"
+import (
+	"math"
+	"sort"
+)
 
 func Average(values []float64) float64 {
 	if len(values) == 0 {
@@ -76,3 +79,16 @@ func StandardDeviation(values []float64) float64 {
 	variance = variance / float64(len(values))
 	return math.Sqrt(variance)
 }
+
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,41 @@
+package utils
+
+import "github.com/shopspring/decimal"
+
+// decimal places money values are rounded to when converted to float64 at the edge
+var MoneyDecimalPlaces int32 = 2
+
+// parses a stored price or quantity string, treating unparseable values as zero
+func ParseMoney(value string) decimal.Decimal {
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero
+	}
+	return d
+}
+
+// realized P&L of a long round trip: (exit - entry) * qty
+func RealizedPnL(entryPrice, exitPrice, quantity decimal.Decimal) decimal.Decimal {
+	return exitPrice.Sub(entryPrice).Mul(quantity)
+}
+
+// percent change from entry to exit, zero when entry is zero
+func ReturnPercent(entryPrice, exitPrice decimal.Decimal) decimal.Decimal {
+	if entryPrice.IsZero() {
+		return decimal.Zero
+	}
+	return exitPrice.Sub(entryPrice).Div(entryPrice).Mul(decimal.NewFromInt(100))
+}
+
+func SumMoney(values []decimal.Decimal) decimal.Decimal {
+	total := decimal.Zero
+	for _, v := range values {
+		total = total.Add(v)
+	}
+	return total
+}
+
+// converts a money amount to float64 for JSON/metrics, rounded to MoneyDecimalPlaces
+func MoneyToFloat(value decimal.Decimal) float64 {
+	return value.Round(MoneyDecimalPlaces).InexactFloat64()
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRealizedPnL_AvoidsFloatAccumulationError(t *testing.T) {
+	buy, sell, qty := 1.10, 1.20, 3.0
+	floatTotal := 0.0
+	decimalPnL := make([]decimal.Decimal, 0, 10)
+	for i := 0; i < 10; i++ {
+		floatTotal += (sell - buy) * qty
+		decimalPnL = append(decimalPnL, RealizedPnL(ParseMoney("1.10"), ParseMoney("1.20"), ParseMoney("3")))
+	}
+
+	// the float path drifts away from the exact $3.00
+	if floatTotal == 3.0 {
+		t.Fatalf("expected float accumulation to drift, got exactly %.20f", floatTotal)
+	}
+	if total := SumMoney(decimalPnL); !total.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("decimal total = %s, want 3", total)
+	}
+}
+
+func TestMoneyToFloat_RoundsHalfCentCorrectly(t *testing.T) {
+	// (10.045 - 10.00) * 3 is exactly 0.135, which float arithmetic lands just below
+	buy, sell, qty := 10.00, 10.045, 3.0
+	floatPnL := (sell - buy) * qty
+	floatRounded := math.Round(floatPnL*100) / 100
+	decimalRounded := MoneyToFloat(RealizedPnL(ParseMoney("10.00"), ParseMoney("10.045"), ParseMoney("3")))
+
+	if floatRounded != 0.13 {
+		t.Fatalf("expected float path to round down to 0.13, got %.2f", floatRounded)
+	}
+	if decimalRounded != 0.14 {
+		t.Errorf("decimal path = %.2f, want 0.14", decimalRounded)
+	}
+}
+
+func TestParseMoney_InvalidIsZero(t *testing.T) {
+	if !ParseMoney("not-a-number").IsZero() {
+		t.Errorf("expected unparseable value to be zero")
+	}
+	if !ReturnPercent(decimal.Zero, ParseMoney("5")).IsZero() {
+		t.Errorf("expected zero return for zero entry price")
+	}
+}
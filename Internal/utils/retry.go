@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"time"
 )
@@ -21,18 +22,23 @@ func DefaultRetryConfig() *RetryConfig {
 
 func RetryWithBackoff(operation func() error, config *RetryConfig) error {
 	delay := config.Delay
+	var err error
 	for i := 0; i < config.MaxRetries; i++ {
-		err := operation()
+		err = operation()
 		if err == nil {
 			return nil
 		}
+		// an unknown symbol won't appear on a retry
+		if errors.Is(err, ErrSymbolNotFound) {
+			return err
+		}
 		if i < config.MaxRetries-1 {
 			fmt.Printf("⚠️  Attempt %d failed: %v. Retrying in %s...\n", i+1, err, delay)
 			time.Sleep(delay)
 			delay = time.Duration(float64(delay) * config.Backoff)
 		}
 	}
-	return fmt.Errorf("operation failed after %d attempts", config.MaxRetries)
+	return fmt.Errorf("operation failed after %d attempts: %w", config.MaxRetries, err)
 }
 
 func TestRetryLogic() {
@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/strategy/alerts"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// subset of queries used to evaluate alert rules (*database.Queries satisfies it)
+type AlertRuleStore interface {
+	GetEnabledAlertRules(ctx context.Context) ([]database.AlertRule, error)
+	GetWatchlist(ctx context.Context) ([]database.GetWatchlistRow, error)
+	MarkAlertRuleTriggered(ctx context.Context, id int32) error
+}
+
+// returns bars for a symbol in any order; they are sorted oldest first before evaluation
+type BarFetcher func(symbol string) ([]types.Bar, error)
+
+// evaluates every enabled rule against the latest indicator values and reports matches through notify
+func EvaluateAlertRules(ctx context.Context, store AlertRuleStore, fetchBars BarFetcher, notify func(alerts.Trigger)) ([]alerts.Trigger, error) {
+	if store == nil || fetchBars == nil {
+		return nil, fmt.Errorf("alert rule store and bar fetcher are required")
+	}
+
+	records, err := store.GetEnabledAlertRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert rules: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	watchlist, err := store.GetWatchlist(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load watchlist: %w", err)
+	}
+	scores := make(map[string]float64, len(watchlist))
+	watchlistSymbols := make([]string, 0, len(watchlist))
+	for _, item := range watchlist {
+		scores[item.Symbol] = float64(item.Score)
+		watchlistSymbols = append(watchlistSymbols, item.Symbol)
+	}
+
+	// indicator values are computed once per symbol and shared across rules
+	valuesCache := make(map[string]map[string]float64)
+	valuesFor := func(symbol string) (map[string]float64, error) {
+		if values, ok := valuesCache[symbol]; ok {
+			return values, nil
+		}
+		bars, err := fetchBars(symbol)
+		if err != nil {
+			return nil, err
+		}
+		sorted := make([]types.Bar, len(bars))
+		copy(sorted, bars)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return barTime(sorted[i]).Before(barTime(sorted[j]))
+		})
+		values, err := alerts.ComputeIndicatorValues(sorted)
+		if err != nil {
+			return nil, err
+		}
+		if score, ok := scores[symbol]; ok {
+			values[alerts.FieldScore] = score
+		}
+		valuesCache[symbol] = values
+		return values, nil
+	}
+
+	var triggers []alerts.Trigger
+	for _, record := range records {
+		rule, err := alerts.RuleFromRecord(record)
+		if err != nil {
+			log.Printf("Skipping alert rule: %v", err)
+			continue
+		}
+
+		symbols := watchlistSymbols
+		if rule.Symbol != "" {
+			symbols = []string{rule.Symbol}
+		}
+
+		fired := false
+		for _, symbol := range symbols {
+			values, err := valuesFor(symbol)
+			if err != nil {
+				log.Printf("Alert rule %s: no indicator data for %s: %v", rule.Name, symbol, err)
+				continue
+			}
+			if !rule.Evaluate(values) {
+				continue
+			}
+
+			trigger := alerts.Trigger{Rule: rule, Symbol: symbol, Values: values}
+			triggers = append(triggers, trigger)
+			fired = true
+			if notify != nil {
+				notify(trigger)
+			}
+		}
+
+		if fired {
+			if err := store.MarkAlertRuleTriggered(ctx, rule.ID); err != nil {
+				log.Printf("Failed to record trigger time for alert rule %d: %v", rule.ID, err)
+			}
+		}
+	}
+
+	return triggers, nil
+}
+
+func barTime(bar types.Bar) time.Time {
+	t, _ := time.Parse(time.RFC3339, bar.Timestamp)
+	return t
+}
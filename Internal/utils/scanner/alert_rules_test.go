@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/strategy/alerts"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+type fakeAlertRuleStore struct {
+	rules     []database.AlertRule
+	watchlist []database.GetWatchlistRow
+	triggered []int32
+}
+
+func (f *fakeAlertRuleStore) GetEnabledAlertRules(ctx context.Context) ([]database.AlertRule, error) {
+	return f.rules, nil
+}
+
+func (f *fakeAlertRuleStore) GetWatchlist(ctx context.Context) ([]database.GetWatchlistRow, error) {
+	return f.watchlist, nil
+}
+
+func (f *fakeAlertRuleStore) MarkAlertRuleTriggered(ctx context.Context, id int32) error {
+	f.triggered = append(f.triggered, id)
+	return nil
+}
+
+// latest-first bars like the Alpaca feed returns; closes fall steadily and the newest bar spikes volume
+func buildLatestFirstBars(spike bool) []types.Bar {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bars := make([]types.Bar, 30)
+	for i := range bars {
+		price := 100.0 - float64(i)
+		volume := int64(1000)
+		if spike && i == len(bars)-1 {
+			volume = 2500
+		}
+		bars[len(bars)-1-i] = types.Bar{
+			Timestamp: base.AddDate(0, 0, i).Format(time.RFC3339),
+			High:      price + 1,
+			Low:       price - 1,
+			Close:     price,
+			Volume:    volume,
+		}
+	}
+	return bars
+}
+
+func TestEvaluateAlertRules_FiresMatchingRules(t *testing.T) {
+	store := &fakeAlertRuleStore{
+		rules: []database.AlertRule{
+			{ID: 1, Name: "oversold volume", Conditions: `[{"field":"rsi","operator":"<","value":30},{"field":"volume_ratio","operator":">","value":2}]`, Enabled: true},
+			{ID: 2, Name: "strong score", Symbol: sql.NullString{String: "QUIET", Valid: true}, Conditions: `[{"field":"score","operator":">=","value":5}]`, Enabled: true},
+		},
+		watchlist: []database.GetWatchlistRow{
+			{Symbol: "SPIKE", Score: 3},
+			{Symbol: "QUIET", Score: 6},
+		},
+	}
+	fetch := func(symbol string) ([]types.Bar, error) {
+		return buildLatestFirstBars(symbol == "SPIKE"), nil
+	}
+
+	var notified []alerts.Trigger
+	triggers, err := EvaluateAlertRules(context.Background(), store, fetch, func(trigger alerts.Trigger) {
+		notified = append(notified, trigger)
+	})
+	if err != nil {
+		t.Fatalf("EvaluateAlertRules() error = %v", err)
+	}
+
+	if len(triggers) != 2 || len(notified) != 2 {
+		t.Fatalf("Expected 2 triggers, got %d (notified %d)", len(triggers), len(notified))
+	}
+	if triggers[0].Rule.ID != 1 || triggers[0].Symbol != "SPIKE" {
+		t.Errorf("First trigger = rule %d on %s, want rule 1 on SPIKE", triggers[0].Rule.ID, triggers[0].Symbol)
+	}
+	if triggers[1].Rule.ID != 2 || triggers[1].Symbol != "QUIET" {
+		t.Errorf("Second trigger = rule %d on %s, want rule 2 on QUIET", triggers[1].Rule.ID, triggers[1].Symbol)
+	}
+	if triggers[0].Values[alerts.FieldPrice] != 71 {
+		t.Errorf("Price should come from the newest bar, got %.2f", triggers[0].Values[alerts.FieldPrice])
+	}
+	if len(store.triggered) != 2 {
+		t.Errorf("Expected both rules marked triggered, got %v", store.triggered)
+	}
+}
+
+func TestEvaluateAlertRules_SkipsInvalidConditions(t *testing.T) {
+	store := &fakeAlertRuleStore{
+		rules:     []database.AlertRule{{ID: 7, Name: "broken", Conditions: `not json`, Enabled: true}},
+		watchlist: []database.GetWatchlistRow{{Symbol: "AAPL", Score: 5}},
+	}
+	fetch := func(symbol string) ([]types.Bar, error) {
+		return buildLatestFirstBars(false), nil
+	}
+
+	triggers, err := EvaluateAlertRules(context.Background(), store, fetch, nil)
+	if err != nil {
+		t.Fatalf("EvaluateAlertRules() error = %v", err)
+	}
+	if len(triggers) != 0 || len(store.triggered) != 0 {
+		t.Errorf("Invalid rule should be skipped, got triggers %v", triggers)
+	}
+}
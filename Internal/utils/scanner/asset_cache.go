@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"sync"
+	"time"
+)
+
+const defaultAssetCacheTTL = 24 * time.Hour
+
+// tradable symbol list shared by every scan; the universe changes rarely, so one Alpaca listing
+// serves until the TTL runs out or a refresh is forced
+type AssetCache struct {
+	mu        sync.Mutex
+	fetch     func() ([]string, error)
+	now       func() time.Time
+	enabled   bool
+	ttl       time.Duration
+	symbols   []string
+	fetchedAt time.Time
+}
+
+// what the cache holds right now, for the refresh endpoint
+type AssetCacheStatus struct {
+	Enabled   bool      `json:"enabled"`
+	Count     int       `json:"count"`
+	FetchedAt time.Time `json:"fetched_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewAssetCache(fetch func() ([]string, error), ttl time.Duration) *AssetCache {
+	if ttl <= 0 {
+		ttl = defaultAssetCacheTTL
+	}
+	return &AssetCache{fetch: fetch, now: time.Now, enabled: true, ttl: ttl}
+}
+
+// cached symbols while they're fresh, otherwise a new listing; a failed fetch leaves the old list in place
+func (c *AssetCache) Symbols() ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.enabled && c.symbols != nil && c.now().Sub(c.fetchedAt) < c.ttl {
+		return append([]string(nil), c.symbols...), nil
+	}
+	return c.load()
+}
+
+// refetches regardless of age
+func (c *AssetCache) Refresh() ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.load()
+}
+
+// caller holds c.mu; holding it through the fetch keeps concurrent scans from each listing the assets
+func (c *AssetCache) load() ([]string, error) {
+	symbols, err := c.fetch()
+	if err != nil {
+		return nil, err
+	}
+	c.symbols = symbols
+	c.fetchedAt = c.now()
+	return append([]string(nil), symbols...), nil
+}
+
+// turns caching on or off; a non-positive ttl keeps the current one
+func (c *AssetCache) Configure(enabled bool, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.enabled = enabled
+	if ttl > 0 {
+		c.ttl = ttl
+	}
+}
+
+func (c *AssetCache) Status() AssetCacheStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := AssetCacheStatus{Enabled: c.enabled, Count: len(c.symbols)}
+	if c.symbols != nil {
+		status.FetchedAt = c.fetchedAt
+		status.ExpiresAt = c.fetchedAt.Add(c.ttl)
+	}
+	return status
+}
+
+var (
+	tradableAssets = NewAssetCache(fetchTradableAssets, defaultAssetCacheTTL)
+	tradableCrypto = NewAssetCache(fetchTradableCrypto, defaultAssetCacheTTL)
+)
+
+// applies the asset_cache config block to the shared caches
+func ConfigureAssetCache(enabled bool, ttl time.Duration) {
+	tradableAssets.Configure(enabled, ttl)
+	tradableCrypto.Configure(enabled, ttl)
+}
+
+// forces a new asset listing for every later scan
+func RefreshTradableAssets() (AssetCacheStatus, error) {
+	if _, err := tradableAssets.Refresh(); err != nil {
+		return tradableAssets.Status(), err
+	}
+	return tradableAssets.Status(), nil
+}
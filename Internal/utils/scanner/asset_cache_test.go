@@ -0,0 +1,134 @@
+package scanner
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// swaps the shared cache for one over a counting fetch and a controllable clock
+func fakeAssetCache(t *testing.T, ttl time.Duration) (fetches *int32, clock *time.Time) {
+	t.Helper()
+	fetches = new(int32)
+	clock = new(time.Time)
+	*clock = time.Date(2024, 3, 6, 14, 30, 0, 0, time.UTC)
+
+	cache := NewAssetCache(func() ([]string, error) {
+		atomic.AddInt32(fetches, 1)
+		return []string{"AAPL", "MSFT"}, nil
+	}, ttl)
+	cache.now = func() time.Time { return *clock }
+
+	saved := tradableAssets
+	tradableAssets = cache
+	t.Cleanup(func() { tradableAssets = saved })
+	return fetches, clock
+}
+
+func TestGetTradableAssets_ReusedWithinTTL(t *testing.T) {
+	fetches, clock := fakeAssetCache(t, 24*time.Hour)
+
+	first, err := GetTradableAssets()
+	if err != nil {
+		t.Fatalf("GetTradableAssets() error = %v", err)
+	}
+	first[0] = "CHANGED" // callers get their own copy
+
+	*clock = clock.Add(23 * time.Hour)
+	second, err := GetTradableAssets()
+	if err != nil {
+		t.Fatalf("GetTradableAssets() error = %v", err)
+	}
+	if got := atomic.LoadInt32(fetches); got != 1 {
+		t.Fatalf("Assets fetched %d times within the TTL, want 1", got)
+	}
+	if second[0] != "AAPL" {
+		t.Errorf("Cached list was modified through a returned slice: %v", second)
+	}
+
+	*clock = clock.Add(time.Hour)
+	if _, err := GetTradableAssets(); err != nil {
+		t.Fatalf("GetTradableAssets() error = %v", err)
+	}
+	if got := atomic.LoadInt32(fetches); got != 2 {
+		t.Errorf("Assets fetched %d times after the TTL ran out, want 2", got)
+	}
+}
+
+func TestRefreshTradableAssets_ForcesRefetch(t *testing.T) {
+	fetches, clock := fakeAssetCache(t, 24*time.Hour)
+
+	if _, err := GetTradableAssets(); err != nil {
+		t.Fatalf("GetTradableAssets() error = %v", err)
+	}
+	*clock = clock.Add(time.Minute)
+	status, err := RefreshTradableAssets()
+	if err != nil {
+		t.Fatalf("RefreshTradableAssets() error = %v", err)
+	}
+	if got := atomic.LoadInt32(fetches); got != 2 {
+		t.Fatalf("Assets fetched %d times, want 2 after a forced refresh", got)
+	}
+	if status.Count != 2 || !status.FetchedAt.Equal(*clock) || !status.ExpiresAt.Equal(clock.Add(24*time.Hour)) {
+		t.Errorf("Status = %+v, want 2 symbols fetched at %s", status, *clock)
+	}
+
+	// the refresh restarts the TTL
+	*clock = clock.Add(23*time.Hour + 30*time.Minute)
+	if _, err := GetTradableAssets(); err != nil {
+		t.Fatalf("GetTradableAssets() error = %v", err)
+	}
+	if got := atomic.LoadInt32(fetches); got != 2 {
+		t.Errorf("Assets fetched %d times, want the refreshed list reused", got)
+	}
+}
+
+func TestAssetCache_ConcurrentScansShareOneFetch(t *testing.T) {
+	fetches, _ := fakeAssetCache(t, time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := GetTradableAssets(); err != nil {
+				t.Errorf("GetTradableAssets() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(fetches); got != 1 {
+		t.Errorf("Concurrent scans fetched assets %d times, want 1", got)
+	}
+}
+
+func TestAssetCache_DisabledAndFailedFetch(t *testing.T) {
+	fetches := 0
+	fail := false
+	cache := NewAssetCache(func() ([]string, error) {
+		fetches++
+		if fail {
+			return nil, errors.New("alpaca unavailable")
+		}
+		return []string{"AAPL"}, nil
+	}, time.Hour)
+
+	cache.Configure(false, 0)
+	cache.Symbols()
+	cache.Symbols()
+	if fetches != 2 {
+		t.Fatalf("Fetched %d times with caching disabled, want 2", fetches)
+	}
+
+	cache.Configure(true, 0)
+	fail = true
+	if _, err := cache.Refresh(); err == nil {
+		t.Fatal("Refresh() error = nil, want the fetch error")
+	}
+	if status := cache.Status(); status.Count != 1 {
+		t.Errorf("A failed refresh dropped the cached list: %+v", status)
+	}
+}
@@ -0,0 +1,134 @@
+package scanner
+
+import (
+	"fmt"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/detection"
+)
+
+const (
+	DirectionLong  = "LONG"
+	DirectionShort = "SHORT"
+)
+
+// points from the direction-dependent components (RSI, patterns, S/R), scored once as a long setup and once as a short
+type directionalPoints struct {
+	Long  float64
+	Short float64
+}
+
+func (p directionalPoints) add(other directionalPoints) directionalPoints {
+	return directionalPoints{Long: p.Long + other.Long, Short: p.Short + other.Short}
+}
+
+// RSI (0-2.0): oversold earns a long up to 2 points and overbought earns a short the same, the opposite extreme costs a point
+func scoreRSIDirectional(rsi float64, criteria ScreenerCriteria) (directionalPoints, string) {
+	switch {
+	case rsi < criteria.MinOversoldRSI:
+		// More oversold = higher score (bullish opportunity)
+		return directionalPoints{Long: capPoints((criteria.MinOversoldRSI-rsi)/criteria.MinOversoldRSI*2.0, 2.0), Short: -1.0},
+			fmt.Sprintf("RSI Oversold: %.2f", rsi)
+	case rsi > criteria.MaxRSI:
+		// More overbought = higher short score, measured over the room left above MaxRSI
+		short := 2.0
+		if criteria.MaxRSI < 100 {
+			short = capPoints((rsi-criteria.MaxRSI)/(100-criteria.MaxRSI)*2.0, 2.0)
+		}
+		return directionalPoints{Long: -1.0, Short: short}, fmt.Sprintf("RSI Overbought: %.2f", rsi)
+	}
+	// Neutral RSI gets small bonus
+	return directionalPoints{Long: 0.5, Short: 0.5}, ""
+}
+
+// patterns: 0.5 per full-confidence pattern in the setup's direction, 0.3 against it, capped at 1.0 per side
+func scorePatternsDirectional(patterns []detection.PatternSignal) (directionalPoints, []string) {
+	points := directionalPoints{}
+	var signals []string
+	for _, pattern := range patterns {
+		if !pattern.Detected {
+			continue
+		}
+		confidence := pattern.Confidence / 100.0
+		switch pattern.Direction {
+		case DirectionLong:
+			points.Long += confidence * 0.5
+			points.Short += confidence * 0.3
+			signals = append(signals, fmt.Sprintf("UP%s [%.0f%% confidence]", pattern.Pattern, pattern.Confidence))
+		case DirectionShort:
+			points.Long += confidence * 0.3
+			points.Short += confidence * 0.5
+			signals = append(signals, fmt.Sprintf("DOWN%s [%.0f%% confidence]", pattern.Pattern, pattern.Confidence))
+		case "NONE":
+			signals = append(signals, fmt.Sprintf("NEUTRAL %s [%.0f%% confidence]", pattern.Pattern, pattern.Confidence))
+		}
+	}
+	points.Long = capPoints(points.Long, 1.0)
+	points.Short = capPoints(points.Short, 1.0)
+	return points, signals
+}
+
+// S/R (0-1.5): near support is a long entry and a poor short, near resistance the reverse
+func scoreSRDirectional(price, support, resistance float64) (directionalPoints, []string) {
+	points := directionalPoints{}
+	var signals []string
+	if price < support*1.01 {
+		points.Long += 1.5
+		points.Short -= 1.0
+		signals = append(signals, fmt.Sprintf("Near Support: $%.2f", support))
+	}
+	if price > resistance*0.99 {
+		points.Long -= 1.0
+		points.Short += 1.5
+		signals = append(signals, fmt.Sprintf("Near Resistance: $%.2f", resistance))
+	}
+	return points, signals
+}
+
+// picks the setup a candidate is ranked as; the short side is scaled by ShortWeight and only competes when shorts are enabled
+func (c ScreenerCriteria) chooseDirection(points directionalPoints) (float64, string) {
+	if !c.EnableShorts {
+		return points.Long, DirectionLong
+	}
+	weight := c.ShortWeight
+	if weight <= 0 {
+		weight = 1.0
+	}
+	if short := points.Short * weight; short > points.Long {
+		return short, DirectionShort
+	}
+	return points.Long, DirectionLong
+}
+
+// what one component's directional points added to the chosen side's score
+func (c ScreenerCriteria) directionalShare(points directionalPoints, direction string) float64 {
+	if direction != DirectionShort {
+		return points.Long
+	}
+	weight := c.ShortWeight
+	if weight <= 0 {
+		weight = 1.0
+	}
+	return points.Short * weight
+}
+
+// the score a setup earns as a long before clamping: the long directional points stand in for the chosen side's and
+// a passing sell-side final signal counts against the long rather than for it
+func rawLongScore(score, chosen, long, quality float64, bearishSignal bool) float64 {
+	raw := score - chosen + long
+	if bearishSignal && quality > 0 {
+		raw -= 2 * quality
+	}
+	return raw
+}
+
+// whether a raw score is bearish enough to scout as a short rather than floor to zero
+func (c ScreenerCriteria) isShortCandidate(rawScore float64) bool {
+	return c.EnableShorts && c.ShortCandidate < 0 && rawScore <= c.ShortCandidate
+}
+
+func capPoints(points, max float64) float64 {
+	if points > max {
+		return max
+	}
+	return points
+}
@@ -0,0 +1,233 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+const (
+	defaultBreadthSMAPeriod       = 20
+	defaultBreadthHighLowLookback = 252 // a year of dailies, the usual new-high/new-low window
+	defaultBreadthMaxSymbols      = 200
+	defaultBreadthConcurrency     = 8
+	breadthRSIPeriod              = 14
+)
+
+// breadth score at or above this leans BULLISH, at or below 100 minus it BEARISH
+const breadthBullishScore = 60
+
+// daily bars for one symbol, latest-first or oldest-first
+type BreadthBarFetcher func(symbol string, limit int) ([]types.Bar, error)
+
+// where one symbol sits for the breadth tally
+type SymbolBreadth struct {
+	Symbol     string
+	AboveSMA   bool
+	RSIAbove50 bool
+	NewHigh    bool // latest close at or above every high of the lookback before it
+	NewLow     bool // latest close at or below every low of the lookback before it
+}
+
+// breadth across a symbol universe; percentages are of the symbols that could be evaluated
+type MarketBreadth struct {
+	Universe          int      `json:"universe"`
+	Evaluated         int      `json:"evaluated"`
+	PercentAboveSMA   float64  `json:"percent_above_sma"`
+	PercentRSIAbove50 float64  `json:"percent_rsi_above_50"`
+	NewHighs          int      `json:"new_highs"`
+	NewLows           int      `json:"new_lows"`
+	NetNewHighs       int      `json:"net_new_highs"`
+	Score             float64  `json:"breadth_score"` // 0-100, 50 is an evenly split market
+	Bias              string   `json:"bias"`          // BULLISH, BEARISH or NEUTRAL
+	SMAPeriod         int      `json:"sma_period"`
+	HighLowLookback   int      `json:"high_low_lookback"`
+	Skipped           []string `json:"skipped,omitempty"` // symbols with no usable bars
+	ComputedAt        string   `json:"computed_at"`
+}
+
+// sma period, new-high window and worker count from the market_breadth block, defaults where unset
+type BreadthOptions struct {
+	SMAPeriod       int
+	HighLowLookback int
+	Concurrency     int
+}
+
+func BreadthOptionsFromConfig(cfg config.MarketBreadthConfig) BreadthOptions {
+	return BreadthOptions{
+		SMAPeriod:       cfg.SMAPeriod,
+		HighLowLookback: cfg.HighLowLookback,
+		Concurrency:     cfg.Concurrency,
+	}
+}
+
+func (o BreadthOptions) withDefaults() BreadthOptions {
+	if o.SMAPeriod <= 0 {
+		o.SMAPeriod = defaultBreadthSMAPeriod
+	}
+	if o.HighLowLookback <= 0 {
+		o.HighLowLookback = defaultBreadthHighLowLookback
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultBreadthConcurrency
+	}
+	return o
+}
+
+// bars the fetch needs so every reading has its full window
+func (o BreadthOptions) barLimit() int {
+	o = o.withDefaults()
+	return max(o.SMAPeriod, o.HighLowLookback, breadthRSIPeriod) + 1
+}
+
+// classifies one symbol; the new-high/new-low window shrinks to the history available, but the SMA and RSI
+// need their full periods
+func ReadSymbolBreadth(symbol string, bars []types.Bar, opts BreadthOptions) (SymbolBreadth, error) {
+	opts = opts.withDefaults()
+	need := max(opts.SMAPeriod, breadthRSIPeriod+1)
+	if len(bars) < need {
+		return SymbolBreadth{}, fmt.Errorf("%w for %s breadth (need %d bars, got %d)", ErrInsufficientData, symbol, need, len(bars))
+	}
+
+	sorted := make([]types.Bar, len(bars))
+	copy(sorted, bars)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return barTime(sorted[i]).Before(barTime(sorted[j]))
+	})
+
+	closes := make([]float64, len(sorted))
+	for i, bar := range sorted {
+		closes[i] = bar.Close
+	}
+	end := len(closes)
+	latest := closes[end-1]
+	reading := SymbolBreadth{Symbol: symbol}
+
+	reading.AboveSMA = latest > utils.Average(closes[end-opts.SMAPeriod:end])
+	if rsi, err := indicators.CalculateRSI(closes, breadthRSIPeriod); err == nil {
+		reading.RSIAbove50 = rsi[end-1] > 50
+	}
+
+	window := sorted[max(0, end-1-opts.HighLowLookback) : end-1]
+	reading.NewHigh, reading.NewLow = true, true
+	for _, bar := range window {
+		if bar.High > latest {
+			reading.NewHigh = false
+		}
+		if bar.Low < latest {
+			reading.NewLow = false
+		}
+	}
+	return reading, nil
+}
+
+// tallies readings into percentages, net new highs and a 0-100 score averaging the three
+func SummarizeBreadth(readings []SymbolBreadth) MarketBreadth {
+	breadth := MarketBreadth{Evaluated: len(readings), Bias: RegimeNeutral}
+	if len(readings) == 0 {
+		return breadth
+	}
+
+	aboveSMA, rsiAbove := 0, 0
+	for _, r := range readings {
+		if r.AboveSMA {
+			aboveSMA++
+		}
+		if r.RSIAbove50 {
+			rsiAbove++
+		}
+		if r.NewHigh {
+			breadth.NewHighs++
+		}
+		if r.NewLow {
+			breadth.NewLows++
+		}
+	}
+
+	n := float64(len(readings))
+	breadth.PercentAboveSMA = float64(aboveSMA) / n * 100
+	breadth.PercentRSIAbove50 = float64(rsiAbove) / n * 100
+	breadth.NetNewHighs = breadth.NewHighs - breadth.NewLows
+	// net new highs run from -n (all at lows) to +n (all at highs), mapped onto 0-100
+	highLowScore := (float64(breadth.NetNewHighs)/n + 1) * 50
+	breadth.Score = (breadth.PercentAboveSMA + breadth.PercentRSIAbove50 + highLowScore) / 3
+
+	switch {
+	case breadth.Score >= breadthBullishScore:
+		breadth.Bias = RegimeBullish
+	case breadth.Score <= 100-breadthBullishScore:
+		breadth.Bias = RegimeBearish
+	}
+	return breadth
+}
+
+// fetches and reads every symbol with a bounded worker pool; symbols whose bars can't be fetched or are too
+// short are listed as skipped rather than failing the whole panel
+func ComputeMarketBreadth(ctx context.Context, symbols []string, fetch BreadthBarFetcher, opts BreadthOptions) (*MarketBreadth, error) {
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("no symbols to compute breadth over")
+	}
+	opts = opts.withDefaults()
+	limit := opts.barLimit()
+
+	readings := make([]*SymbolBreadth, len(symbols))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(opts.Concurrency, len(symbols)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				bars, err := fetch(symbols[i], limit)
+				if err != nil {
+					continue
+				}
+				if reading, err := ReadSymbolBreadth(symbols[i], bars, opts); err == nil {
+					readings[i] = &reading
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range symbols {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var evaluated []SymbolBreadth
+	var skipped []string
+	for i, reading := range readings {
+		if reading == nil {
+			skipped = append(skipped, symbols[i])
+			continue
+		}
+		evaluated = append(evaluated, *reading)
+	}
+	if len(evaluated) == 0 {
+		return nil, fmt.Errorf("%w: none of %d symbols had enough bars for breadth", ErrInsufficientData, len(symbols))
+	}
+
+	breadth := SummarizeBreadth(evaluated)
+	breadth.Universe = len(symbols)
+	breadth.SMAPeriod = opts.SMAPeriod
+	breadth.HighLowLookback = opts.HighLowLookback
+	breadth.Skipped = skipped
+	breadth.ComputedAt = time.Now().UTC().Format(time.RFC3339)
+	return &breadth, nil
+}
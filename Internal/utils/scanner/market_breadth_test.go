@@ -0,0 +1,68 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// 30 daily bars moving step per day, returned latest-first like the Alpaca fetch
+func trendingBars(start, step float64) []types.Bar {
+	bars := make([]types.Bar, 30)
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := range bars {
+		c, wick := start+step*float64(i), math.Abs(step)*0.4
+		bars[len(bars)-1-i] = types.Bar{Timestamp: day.AddDate(0, 0, i).Format(time.RFC3339), Open: c, High: c + wick, Low: c - wick, Close: c, Volume: 1000}
+	}
+	return bars
+}
+
+func TestComputeMarketBreadth_MockedUniverse(t *testing.T) {
+	universe := map[string][]types.Bar{
+		"UP1":  trendingBars(100, 1),
+		"UP2":  trendingBars(50, 0.5),
+		"UP3":  trendingBars(20, 0.2),
+		"DOWN": trendingBars(100, -1),
+		"NEW":  trendingBars(10, 1)[:5],
+	}
+	fetch := func(symbol string, limit int) ([]types.Bar, error) {
+		if bars, ok := universe[symbol]; ok {
+			return bars, nil
+		}
+		return nil, errors.New("symbol not found")
+	}
+
+	breadth, err := ComputeMarketBreadth(context.Background(), []string{"UP1", "UP2", "UP3", "DOWN", "NEW", "GONE"}, fetch, BreadthOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("ComputeMarketBreadth() error = %v", err)
+	}
+	if breadth.Universe != 6 || breadth.Evaluated != 4 || len(breadth.Skipped) != 2 {
+		t.Errorf("universe %d, evaluated %d, skipped %v; want 6, 4 and NEW/GONE skipped", breadth.Universe, breadth.Evaluated, breadth.Skipped)
+	}
+	if breadth.PercentAboveSMA != 75 || breadth.PercentRSIAbove50 != 75 {
+		t.Errorf("above SMA %.1f%%, RSI>50 %.1f%%, want 75%% each", breadth.PercentAboveSMA, breadth.PercentRSIAbove50)
+	}
+	if breadth.NewHighs != 3 || breadth.NewLows != 1 || breadth.NetNewHighs != 2 {
+		t.Errorf("highs %d, lows %d, net %d; want 3, 1, +2", breadth.NewHighs, breadth.NewLows, breadth.NetNewHighs)
+	}
+	if breadth.Score != 75 || breadth.Bias != RegimeBullish {
+		t.Errorf("score %.1f, bias %s; want 75 BULLISH", breadth.Score, breadth.Bias)
+	}
+
+	if _, err := ComputeMarketBreadth(context.Background(), []string{"GONE"}, fetch, BreadthOptions{}); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("nothing evaluable: err = %v, want ErrInsufficientData", err)
+	}
+}
+
+func TestSummarizeBreadth_BearishTape(t *testing.T) {
+	readings := []SymbolBreadth{{NewLow: true}, {NewLow: true}, {RSIAbove50: true}, {}}
+	breadth := SummarizeBreadth(readings)
+	// 0% above SMA, 25% RSI>50, net -2 of 4 -> 25
+	if breadth.Score < 16.6 || breadth.Score > 16.7 || breadth.Bias != RegimeBearish {
+		t.Errorf("score %.2f, bias %s; want about 16.67 BEARISH", breadth.Score, breadth.Bias)
+	}
+}
@@ -0,0 +1,183 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	db "github.com/fazecat/mogulmaker/Internal/database"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+const (
+	RegimeBullish = "BULLISH"
+	RegimeBearish = "BEARISH"
+	RegimeNeutral = "NEUTRAL"
+)
+
+const (
+	defaultRegimeBenchmark = "SPY"
+	defaultRegimeSMAPeriod = 50
+	regimeSlopeLookback    = 5 // bars between the two SMA readings used for slope
+	regimeCacheTTL         = 15 * time.Minute
+)
+
+// benchmark trend snapshot used to bias scout scores
+type MarketRegime struct {
+	Benchmark     string  `json:"benchmark"`
+	Regime        string  `json:"regime"`
+	Price         float64 `json:"price"`
+	SMA           float64 `json:"sma"`
+	SMASlopePct   float64 `json:"sma_slope_percent"`
+	DistancePct   float64 `json:"distance_from_sma_percent"`
+	LongMultiple  float64 `json:"long_multiplier"`
+	ShortMultiple float64 `json:"short_multiplier"`
+}
+
+type cachedRegime struct {
+	regime    *MarketRegime
+	fetchedAt time.Time
+}
+
+var (
+	regimeCache      = make(map[string]cachedRegime)
+	regimeCacheMutex sync.Mutex
+)
+
+// classifies the benchmark as bearish when price is below a falling SMA, bullish when above a rising one
+func DetectMarketRegime(benchmark string, bars []types.Bar, smaPeriod int) (*MarketRegime, error) {
+	if smaPeriod <= 0 {
+		smaPeriod = defaultRegimeSMAPeriod
+	}
+	if len(bars) < smaPeriod+regimeSlopeLookback {
+		return nil, fmt.Errorf("need %d bars for %s regime, got %d", smaPeriod+regimeSlopeLookback, benchmark, len(bars))
+	}
+
+	sorted := make([]types.Bar, len(bars))
+	copy(sorted, bars)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return barTime(sorted[i]).Before(barTime(sorted[j]))
+	})
+
+	closes := make([]float64, len(sorted))
+	for i, bar := range sorted {
+		closes[i] = bar.Close
+	}
+
+	end := len(closes)
+	sma := utils.Average(closes[end-smaPeriod : end])
+	priorSMA := utils.Average(closes[end-smaPeriod-regimeSlopeLookback : end-regimeSlopeLookback])
+	price := closes[end-1]
+
+	regime := &MarketRegime{
+		Benchmark:     benchmark,
+		Regime:        RegimeNeutral,
+		Price:         price,
+		SMA:           sma,
+		LongMultiple:  1,
+		ShortMultiple: 1,
+	}
+	if priorSMA > 0 {
+		regime.SMASlopePct = (sma - priorSMA) / priorSMA * 100
+	}
+	if sma > 0 {
+		regime.DistancePct = (price - sma) / sma * 100
+	}
+
+	switch {
+	case price < sma && regime.SMASlopePct < 0:
+		regime.Regime = RegimeBearish
+	case price > sma && regime.SMASlopePct > 0:
+		regime.Regime = RegimeBullish
+	}
+
+	return regime, nil
+}
+
+// fetches benchmark bars and detects the regime, cached so paginated scouts reuse one lookup
+func LoadMarketRegime(cfg *config.Config) (*MarketRegime, error) {
+	if cfg == nil || !cfg.MarketRegime.Enabled {
+		return nil, nil
+	}
+
+	benchmark := cfg.MarketRegime.Benchmark
+	if benchmark == "" {
+		benchmark = defaultRegimeBenchmark
+	}
+	period := cfg.MarketRegime.SMAPeriod
+	if period <= 0 {
+		period = defaultRegimeSMAPeriod
+	}
+
+	regimeCacheMutex.Lock()
+	defer regimeCacheMutex.Unlock()
+
+	if cached, ok := regimeCache[benchmark]; ok && time.Since(cached.fetchedAt) < regimeCacheTTL {
+		return withRegimeMultipliers(*cached.regime, cfg.MarketRegime), nil
+	}
+
+	bars, err := db.GetAlpacaBarsWithRetry(benchmark, "1Day", period+regimeSlopeLookback+5, "", 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s bars: %w", benchmark, err)
+	}
+	regime, err := DetectMarketRegime(benchmark, bars, period)
+	if err != nil {
+		return nil, err
+	}
+	regimeCache[benchmark] = cachedRegime{regime: regime, fetchedAt: time.Now()}
+
+	return withRegimeMultipliers(*regime, cfg.MarketRegime), nil
+}
+
+func withRegimeMultipliers(regime MarketRegime, cfg config.MarketRegimeConfig) *MarketRegime {
+	regime.LongMultiple, regime.ShortMultiple = 1, 1
+	if regime.Regime == RegimeBearish {
+		if cfg.LongDampening > 0 {
+			regime.LongMultiple = cfg.LongDampening
+		}
+		if cfg.ShortBoost > 0 {
+			regime.ShortMultiple = cfg.ShortBoost
+		}
+	}
+	return &regime
+}
+
+// scales a candidate score by the regime multiplier for its direction, keeping the 0-10 range
+func ApplyMarketRegime(score float64, direction string, regime *MarketRegime) float64 {
+	if regime == nil {
+		return score
+	}
+
+	switch direction {
+	case "LONG":
+		score *= regime.LongMultiple
+	case "SHORT":
+		score *= regime.ShortMultiple
+	}
+
+	if score > 10.0 {
+		score = 10.0
+	}
+	if score < 0.0 {
+		score = 0.0
+	}
+	return score
+}
+
+// dominant trade direction of a screened stock: the side it was scored as when shorts are enabled,
+// otherwise the signal chosen for S/R validation
+func (s StockScore) Direction() string {
+	if s.ScoredDirection != "" {
+		return s.ScoredDirection
+	}
+	if s.LongSignal != nil && (s.ShortSignal == nil || s.LongSignal.Confidence >= s.ShortSignal.Confidence) {
+		return "LONG"
+	}
+	if s.ShortSignal != nil {
+		return "SHORT"
+	}
+	return ""
+}
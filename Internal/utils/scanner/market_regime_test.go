@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+// latest-first daily bars with a constant per-bar change
+func buildBenchmarkBars(start, step float64, count int) []types.Bar {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bars := make([]types.Bar, count)
+	for i := 0; i < count; i++ {
+		price := start + step*float64(i)
+		bars[count-1-i] = types.Bar{
+			Timestamp: base.AddDate(0, 0, i).Format(time.RFC3339),
+			Close:     price,
+		}
+	}
+	return bars
+}
+
+func TestDetectMarketRegime(t *testing.T) {
+	tests := []struct {
+		name string
+		step float64
+		want string
+	}{
+		{"downtrend", -1, RegimeBearish},
+		{"uptrend", 1, RegimeBullish},
+		{"flat", 0, RegimeNeutral},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regime, err := DetectMarketRegime("SPY", buildBenchmarkBars(500, tt.step, 60), 50)
+			if err != nil {
+				t.Fatalf("DetectMarketRegime() error = %v", err)
+			}
+			if regime.Regime != tt.want {
+				t.Errorf("Regime = %s, want %s", regime.Regime, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectMarketRegime_InsufficientBars(t *testing.T) {
+	if _, err := DetectMarketRegime("SPY", buildBenchmarkBars(500, -1, 20), 50); err == nil {
+		t.Errorf("Expected error with fewer bars than the SMA period")
+	}
+}
+
+func TestApplyMarketRegime_BearishDampensLongs(t *testing.T) {
+	detected, err := DetectMarketRegime("SPY", buildBenchmarkBars(500, -1, 60), 50)
+	if err != nil {
+		t.Fatalf("DetectMarketRegime() error = %v", err)
+	}
+	regime := withRegimeMultipliers(*detected, config.MarketRegimeConfig{
+		Enabled:       true,
+		LongDampening: 0.5,
+		ShortBoost:    1.5,
+	})
+
+	long := StockScore{Score: 8, LongSignal: &TradeSignal{Direction: "LONG", Confidence: 70}}
+	short := StockScore{Score: 6, ShortSignal: &TradeSignal{Direction: "SHORT", Confidence: 70}}
+
+	if got := ApplyMarketRegime(long.Score, long.Direction(), regime); got != 4 {
+		t.Errorf("Long score in bearish regime = %.2f, want 4", got)
+	}
+	if got := ApplyMarketRegime(short.Score, short.Direction(), regime); got != 9 {
+		t.Errorf("Short score in bearish regime = %.2f, want 9", got)
+	}
+	if got := ApplyMarketRegime(9, "SHORT", regime); got != 10 {
+		t.Errorf("Boosted short score should cap at 10, got %.2f", got)
+	}
+}
+
+func TestApplyMarketRegime_BullishOrDisabledLeavesScores(t *testing.T) {
+	detected, err := DetectMarketRegime("SPY", buildBenchmarkBars(500, 1, 60), 50)
+	if err != nil {
+		t.Fatalf("DetectMarketRegime() error = %v", err)
+	}
+	regime := withRegimeMultipliers(*detected, config.MarketRegimeConfig{Enabled: true, LongDampening: 0.5, ShortBoost: 1.5})
+
+	if got := ApplyMarketRegime(8, "LONG", regime); got != 8 {
+		t.Errorf("Long score in bullish regime = %.2f, want unchanged 8", got)
+	}
+	if got := ApplyMarketRegime(8, "LONG", nil); got != 8 {
+		t.Errorf("Long score with regime disabled = %.2f, want unchanged 8", got)
+	}
+	if regime, err := LoadMarketRegime(&config.Config{}); regime != nil || err != nil {
+		t.Errorf("LoadMarketRegime() with toggle off = %v, %v; want nil, nil", regime, err)
+	}
+}
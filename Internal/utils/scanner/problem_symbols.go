@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"context"
+	"log"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+// consecutive failed fetches before a symbol is flagged when delisting_detection leaves the threshold unset
+const DefaultDelistFailureThreshold = 3
+
+// subset of queries behind delisting detection (*database.Queries satisfies it)
+type ProblemSymbolStore interface {
+	RecordSymbolFetchFailure(ctx context.Context, arg database.RecordSymbolFetchFailureParams) (database.ProblemSymbol, error)
+	ResetSymbolFetchFailures(ctx context.Context, symbol string) error
+	IsSymbolFlagged(ctx context.Context, symbol string) (bool, error)
+}
+
+// counts consecutive fetch failures per symbol and flags the ones that reach the threshold
+type ProblemSymbolTracker struct {
+	store     ProblemSymbolStore
+	threshold int
+}
+
+func NewProblemSymbolTracker(store ProblemSymbolStore, threshold int) *ProblemSymbolTracker {
+	if threshold <= 0 {
+		threshold = DefaultDelistFailureThreshold
+	}
+	return &ProblemSymbolTracker{store: store, threshold: threshold}
+}
+
+// the tracker the delisting_detection block asks for, nil when it's disabled or there's no store
+func ProblemSymbolTrackerFromConfig(cfg *config.Config, store ProblemSymbolStore) *ProblemSymbolTracker {
+	if cfg == nil || !cfg.DelistingDetection.Enabled || store == nil {
+		return nil
+	}
+	return NewProblemSymbolTracker(store, cfg.DelistingDetection.FailureThreshold)
+}
+
+// whether the symbol has been flagged; a lookup error leaves it scannable
+func (t *ProblemSymbolTracker) Flagged(ctx context.Context, symbol string) bool {
+	if t == nil {
+		return false
+	}
+	flagged, err := t.store.IsSymbolFlagged(ctx, symbol)
+	return err == nil && flagged
+}
+
+// records how a symbol's scan went: a failed fetch (no bars or a fetch error) extends its failure streak,
+// anything else ends it
+func (t *ProblemSymbolTracker) Record(ctx context.Context, symbol string, err error) {
+	if t == nil {
+		return
+	}
+	if !isFetchFailure(err) {
+		if err := t.store.ResetSymbolFetchFailures(ctx, symbol); err != nil {
+			log.Printf("Warning: failed to reset fetch failures for %s: %v", symbol, err)
+		}
+		return
+	}
+
+	problem, storeErr := t.store.RecordSymbolFetchFailure(ctx, database.RecordSymbolFetchFailureParams{
+		Symbol:    symbol,
+		LastError: err.Error(),
+		Threshold: int32(t.threshold),
+	})
+	if storeErr != nil {
+		log.Printf("Warning: failed to record fetch failure for %s: %v", symbol, storeErr)
+		return
+	}
+	if problem.Flagged && int(problem.ConsecutiveFailures) == t.threshold {
+		log.Printf("Flagged %s as a problem symbol after %d consecutive fetch failures: %s", symbol, problem.ConsecutiveFailures, problem.LastError)
+	}
+}
+
+func isFetchFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	reason := ScreenSkipReason(err)
+	return reason == SkipReasonFetchError || reason == SkipReasonNoData
+}
+
+// the tracker's checks as symbolScanner hooks; both nil without a tracker
+func (t *ProblemSymbolTracker) scannerHooks(ctx context.Context) (func(symbol string) bool, func(symbol string, err error)) {
+	if t == nil {
+		return nil, nil
+	}
+	return func(symbol string) bool { return t.Flagged(ctx, symbol) },
+		func(symbol string, err error) { t.Record(ctx, symbol, err) }
+}
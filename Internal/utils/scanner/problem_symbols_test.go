@@ -0,0 +1,106 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+type memoryProblemSymbols struct {
+	rows map[string]database.ProblemSymbol
+}
+
+func (m *memoryProblemSymbols) RecordSymbolFetchFailure(ctx context.Context, arg database.RecordSymbolFetchFailureParams) (database.ProblemSymbol, error) {
+	row := m.rows[arg.Symbol]
+	row.Symbol = arg.Symbol
+	row.ConsecutiveFailures++
+	row.LastError = arg.LastError
+	row.Flagged = row.Flagged || row.ConsecutiveFailures >= arg.Threshold
+	m.rows[arg.Symbol] = row
+	return row, nil
+}
+
+func (m *memoryProblemSymbols) ResetSymbolFetchFailures(ctx context.Context, symbol string) error {
+	if !m.rows[symbol].Flagged {
+		delete(m.rows, symbol)
+	}
+	return nil
+}
+
+func (m *memoryProblemSymbols) IsSymbolFlagged(ctx context.Context, symbol string) (bool, error) {
+	return m.rows[symbol].Flagged, nil
+}
+
+func TestProblemSymbolTracker_FlagsAfterRepeatedFailuresThenSkips(t *testing.T) {
+	store := &memoryProblemSymbols{rows: map[string]database.ProblemSymbol{}}
+	tracker := NewProblemSymbolTracker(store, 3)
+
+	screened := map[string]int{}
+	s := symbolScanner{
+		screen: func(symbol string) (*StockScore, error) {
+			screened[symbol]++
+			if symbol == "GONE" {
+				return nil, errors.New("API returned status 422: asset not found")
+			}
+			return &StockScore{Symbol: symbol, Score: 8, Signals: []string{"signal"}}, nil
+		},
+		fetchBars: func(symbol string) ([]types.Bar, error) {
+			return []types.Bar{{Close: 10}}, nil
+		},
+	}
+	s.isFlagged, s.recordFetch = tracker.scannerHooks(context.Background())
+
+	symbols := []string{"AAPL", "GONE"}
+	for scan := 1; scan <= 3; scan++ {
+		summary := NewSkipSummary(true)
+		s.scan(symbols, 5, nil, summary)
+		if summary.Reasons[SkipReasonFetchError] != 1 {
+			t.Fatalf("Scan %d: fetch errors = %d, want 1", scan, summary.Reasons[SkipReasonFetchError])
+		}
+	}
+	if !store.rows["GONE"].Flagged || store.rows["GONE"].ConsecutiveFailures != 3 {
+		t.Fatalf("GONE after 3 failed scans = %+v, want flagged", store.rows["GONE"])
+	}
+	if _, ok := store.rows["AAPL"]; ok {
+		t.Errorf("AAPL fetched fine and should have no failure record")
+	}
+
+	summary := NewSkipSummary(true)
+	candidates, _ := s.scan(symbols, 5, nil, summary)
+	if screened["GONE"] != 3 {
+		t.Errorf("GONE screened %d times, want it skipped on the fourth scan", screened["GONE"])
+	}
+	if summary.Reasons[SkipReasonProblemSymbol] != 1 || len(candidates) != 1 {
+		t.Errorf("Fourth scan: reasons %v, %d candidates, want GONE skipped as a problem symbol", summary.Reasons, len(candidates))
+	}
+}
+
+func TestProblemSymbolTracker_SuccessResetsStreak(t *testing.T) {
+	store := &memoryProblemSymbols{rows: map[string]database.ProblemSymbol{}}
+	tracker := NewProblemSymbolTracker(store, 2)
+	ctx := context.Background()
+
+	tracker.Record(ctx, "FLAKY", fmt.Errorf("%w for FLAKY", ErrNoScreenData))
+	tracker.Record(ctx, "FLAKY", nil)
+	tracker.Record(ctx, "FLAKY", errors.New("timeout"))
+	if tracker.Flagged(ctx, "FLAKY") {
+		t.Errorf("A success between failures should reset the streak")
+	}
+
+	// thin history means the fetch worked, so it doesn't count toward delisting
+	tracker.Record(ctx, "NEW", fmt.Errorf("%w for NEW", ErrInsufficientData))
+	tracker.Record(ctx, "NEW", fmt.Errorf("%w for NEW", ErrInsufficientData))
+	if tracker.Flagged(ctx, "NEW") {
+		t.Errorf("Insufficient data should not flag a symbol")
+	}
+
+	var disabled *ProblemSymbolTracker
+	disabled.Record(ctx, "ANY", errors.New("down"))
+	if disabled.Flagged(ctx, "ANY") {
+		t.Errorf("A nil tracker should never flag")
+	}
+}
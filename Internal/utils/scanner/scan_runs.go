@@ -0,0 +1,46 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// subset of queries used to persist and chart scan runs (*database.Queries satisfies it)
+type ScanRunStore interface {
+	InsertScanRun(ctx context.Context, arg database.InsertScanRunParams) (int32, error)
+	GetScanRuns(ctx context.Context, arg database.GetScanRunsParams) ([]database.ScanRun, error)
+}
+
+// summarizes one scan: how many symbols were looked at, how many cleared the bar and their average score
+func NewScanRun(profileName string, scanned int, candidates []types.Candidate, ranAt time.Time) database.InsertScanRunParams {
+	run := database.InsertScanRunParams{
+		ProfileName:     profileName,
+		SymbolsScanned:  int32(scanned),
+		CandidatesFound: int32(len(candidates)),
+		RanAt:           ranAt,
+	}
+	if len(candidates) > 0 {
+		total := 0.0
+		for _, c := range candidates {
+			total += c.Score
+		}
+		run.AvgScore = total / float64(len(candidates))
+	}
+	return run
+}
+
+// stores a scan run so opportunity counts can be charted as a breadth indicator
+func RecordScanRun(ctx context.Context, store ScanRunStore, run database.InsertScanRunParams) (int32, error) {
+	if store == nil {
+		return 0, fmt.Errorf("scan run store is nil")
+	}
+	id, err := store.InsertScanRun(ctx, run)
+	if err != nil {
+		return 0, fmt.Errorf("failed to persist scan run for %s: %w", run.ProfileName, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// keeps scan runs in memory and applies the same range/profile filter as GetScanRuns
+type fakeScanRunStore struct {
+	runs []database.ScanRun
+}
+
+func (f *fakeScanRunStore) InsertScanRun(ctx context.Context, arg database.InsertScanRunParams) (int32, error) {
+	id := int32(len(f.runs) + 1)
+	f.runs = append(f.runs, database.ScanRun{
+		ID:              id,
+		ProfileName:     arg.ProfileName,
+		SymbolsScanned:  arg.SymbolsScanned,
+		CandidatesFound: arg.CandidatesFound,
+		AvgScore:        arg.AvgScore,
+		RanAt:           arg.RanAt,
+	})
+	return id, nil
+}
+
+func (f *fakeScanRunStore) GetScanRuns(ctx context.Context, arg database.GetScanRunsParams) ([]database.ScanRun, error) {
+	var runs []database.ScanRun
+	for _, run := range f.runs {
+		if run.RanAt.Before(arg.FromTime) || !run.RanAt.Before(arg.ToTime) {
+			continue
+		}
+		if arg.Profile != "" && run.ProfileName != arg.Profile {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+func TestRecordScanRun_StoresBreadthAndQueriesByRange(t *testing.T) {
+	store := &fakeScanRunStore{}
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 14, 0, 0, 0, time.UTC) }
+
+	runs := []database.InsertScanRunParams{
+		NewScanRun("balanced", 100, []types.Candidate{{Score: 6}, {Score: 8}}, day(1)),
+		NewScanRun("balanced", 100, []types.Candidate{{Score: 7}}, day(3)),
+		NewScanRun("aggressive", 80, nil, day(3)),
+		NewScanRun("balanced", 100, nil, day(10)),
+	}
+	for _, run := range runs {
+		if _, err := RecordScanRun(context.Background(), store, run); err != nil {
+			t.Fatalf("RecordScanRun() error = %v", err)
+		}
+	}
+
+	if first := store.runs[0]; first.CandidatesFound != 2 || first.AvgScore != 7 || first.SymbolsScanned != 100 {
+		t.Errorf("first run = %+v, want 2 candidates averaging 7 out of 100", first)
+	}
+	if empty := store.runs[2]; empty.CandidatesFound != 0 || empty.AvgScore != 0 {
+		t.Errorf("empty run = %+v, want zero candidates and score", empty)
+	}
+
+	got, err := store.GetScanRuns(context.Background(), database.GetScanRunsParams{FromTime: day(1), ToTime: day(5)})
+	if err != nil {
+		t.Fatalf("GetScanRuns() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("runs in June 1-5 = %d, want 3", len(got))
+	}
+
+	got, _ = store.GetScanRuns(context.Background(), database.GetScanRunsParams{FromTime: day(2), ToTime: day(11), Profile: "balanced"})
+	if len(got) != 2 || got[0].RanAt != day(3) || got[1].RanAt != day(10) {
+		t.Errorf("balanced runs June 2-11 = %+v, want the 3rd and 10th", got)
+	}
+
+	if _, err := RecordScanRun(context.Background(), nil, runs[0]); err == nil {
+		t.Errorf("RecordScanRun(nil store) returned no error")
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log"
+	"sort"
 	"time"
 
 	db "github.com/fazecat/mogulmaker/Internal/database"
 	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	signalsPkg "github.com/fazecat/mogulmaker/Internal/strategy/signals"
 	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
 	"github.com/fazecat/mogulmaker/Internal/utils/config"
 )
 
@@ -25,27 +29,44 @@ func ShouldScan(ctx context.Context, profileName string, cfg *config.Config, q *
 }
 
 func PerformScan(ctx context.Context, profileName string, cfg *config.Config, q *database.Queries) (int, error) {
+	scannedCount, _, err := PerformScanWithSummary(ctx, profileName, cfg, q)
+	return scannedCount, err
+}
+
+// same as PerformScan, also reporting why watchlist symbols were not updated
+func PerformScanWithSummary(ctx context.Context, profileName string, cfg *config.Config, q *database.Queries) (int, *SkipSummary, error) {
+	summary := NewSkipSummary(cfg != nil && cfg.Features.LogSkippedSymbols)
+
 	watchlist, err := q.GetWatchlist(ctx)
 	if err != nil {
-		return 0, err
+		return 0, summary, err
 	}
 
 	scannedCount := 0
-	criteria := DefaultScreenerCriteria()
+	criteria := ScreenerCriteriaForProfile(cfg, profileName)
+	scored := []types.Candidate{}
+	problems := ProblemSymbolTrackerFromConfig(cfg, q)
 
 	for _, item := range watchlist {
 		symbol := item.Symbol
+		summary.Scanned++
 
-		// Use the advanced screener logic
-		stockScores, err := ScreenStocksWithType([]string{symbol}, "1Day", 100, criteria, nil, "stock")
-		if err != nil || len(stockScores) == 0 {
+		if problems.Flagged(ctx, symbol) {
+			summary.Skip(symbol, SkipReasonProblemSymbol, nil)
 			continue
 		}
 
-		result := stockScores[0]
+		// Use the advanced screener logic
+		result, err := ScreenSymbol(symbol, "1Day", 100, criteria, nil, "stock")
+		problems.Record(ctx, symbol, err)
+		if err != nil {
+			summary.Skip(symbol, ScreenSkipReason(err), err)
+			continue
+		}
 
 		// Skip if no meaningful data
 		if result.Score == 0 && len(result.Signals) == 0 {
+			summary.Skip(symbol, SkipReasonNoSignals, nil)
 			continue
 		}
 
@@ -55,10 +76,30 @@ func PerformScan(ctx context.Context, profileName string, cfg *config.Config, q
 			Symbol: symbol,
 		})
 		if err != nil {
+			summary.Skip(symbol, SkipReasonUpdateFailed, err)
 			continue
 		}
 
+		scored = append(scored, types.Candidate{Symbol: symbol, Score: result.Score, RawScore: result.RawScore, Direction: result.Direction(), Components: result.Components})
 		scannedCount++
+		summary.Produced++
+	}
+
+	exportScoreBreakdown(cfg, profileName, scored, time.Now())
+
+	if cfg != nil {
+		if profile := cfg.GetProfile(profileName); profile != nil && profile.WatchlistOutput.PruneBelowThreshold {
+			syncResult, err := SyncCandidatesToWatchlist(ctx, q, scored, WatchlistSyncOptions{
+				ProfileName: profileName,
+				Threshold:   profile.Threshold,
+				Prune:       true,
+			})
+			if err != nil {
+				log.Printf("Watchlist prune failed for profile %s: %v", profileName, err)
+			} else if len(syncResult.Pruned) > 0 {
+				log.Printf("Pruned %d watchlist symbols below threshold %.2f: %v", len(syncResult.Pruned), profile.Threshold, syncResult.Pruned)
+			}
+		}
 	}
 
 	err = q.UpsertScanLog(ctx, database.UpsertScanLogParams{
@@ -68,10 +109,10 @@ func PerformScan(ctx context.Context, profileName string, cfg *config.Config, q
 		SymbolsScanned:    sql.NullInt32{Int32: int32(scannedCount), Valid: true},
 	})
 	if err != nil {
-		return 0, err
+		return 0, summary, err
 	}
 
-	return scannedCount, nil
+	return scannedCount, summary, nil
 }
 
 func CalculateScanInterval(profileName string, cfg *config.Config) time.Duration {
@@ -88,9 +129,26 @@ func GetNextScanDue(lastScan time.Time, profileName string, cfg *config.Config)
 }
 
 func PerformProfileScan(ctx context.Context, profileName string, minScore float64, offset int, batchSize int, cfg *config.Config) ([]types.Candidate, int, error) {
-	symbols, err := GetTradableAssets()
+	candidates, totalSymbols, _, err := PerformProfileScanWithSummary(ctx, profileName, minScore, offset, batchSize, cfg)
+	return candidates, totalSymbols, err
+}
+
+// same as PerformProfileScan, also reporting why the rest of the batch produced no candidate
+func PerformProfileScanWithSummary(ctx context.Context, profileName string, minScore float64, offset int, batchSize int, cfg *config.Config) ([]types.Candidate, int, *SkipSummary, error) {
+	return PerformProfileScanForAsset(ctx, profileName, minScore, offset, batchSize, cfg, utils.AssetTypeStock)
+}
+
+// PerformProfileScanWithSummary over the tradable symbols of assetType; crypto walks Alpaca's crypto pairs and
+// scores them with the crypto criteria
+func PerformProfileScanForAsset(ctx context.Context, profileName string, minScore float64, offset int, batchSize int, cfg *config.Config, assetType string) ([]types.Candidate, int, *SkipSummary, error) {
+	summary := NewSkipSummary(cfg != nil && cfg.Features.LogSkippedSymbols)
+	if assetType != utils.AssetTypeCrypto {
+		assetType = utils.AssetTypeStock
+	}
+
+	symbols, err := tradableSymbolsFor(assetType)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to fetch tradeable assets: %v", err)
+		return nil, 0, summary, fmt.Errorf("failed to fetch tradeable assets: %w", err)
 	}
 
 	totalSymbols := len(symbols)
@@ -101,26 +159,144 @@ func PerformProfileScan(ctx context.Context, profileName string, minScore float6
 	}
 
 	if offset >= totalSymbols {
-		return []types.Candidate{}, totalSymbols, nil
+		return []types.Candidate{}, totalSymbols, summary, nil
 	}
 
-	candidates := []types.Candidate{}
-	criteria := DefaultScreenerCriteria()
-	scannedCount := 0
+	criteria := ScreenerCriteriaForProfile(cfg, profileName)
 
-	for i := offset; i < end && scannedCount < batchSize; i++ {
-		symbol := symbols[i]
-		scannedCount++
+	regime, err := LoadMarketRegime(cfg)
+	if err != nil {
+		log.Printf("Market regime unavailable, scoring without it: %v", err)
+	} else if regime != nil {
+		log.Printf("Market regime (%s): %s", regime.Benchmark, regime.Regime)
+	}
+
+	candidates, scored := liveSymbolScanner(ctx, profileName, criteria, assetType, liveProblemSymbols(cfg)).scan(symbols[offset:end], minScore, regime, summary)
+	if summary.Skipped > 0 {
+		log.Printf("Scan (%s): %d of %d symbols produced candidates, skipped %v", profileName, summary.Produced, summary.Scanned, summary.Reasons)
+	}
+
+	exportScoreBreakdown(cfg, profileName, scored, time.Now())
+
+	if cfg != nil && cfg.Features.PersistScanRuns && db.Queries != nil {
+		if _, err := RecordScanRun(ctx, db.Queries, NewScanRun(profileName, summary.Scanned, candidates, time.Now())); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+
+	if cfg != nil && db.Queries != nil {
+		if profile := cfg.GetProfile(profileName); profile != nil && (profile.WatchlistOutput.Enabled || profile.WatchlistOutput.PruneBelowThreshold) {
+			syncResult, err := SyncCandidatesToWatchlist(ctx, db.Queries, scored, WatchlistSyncOptions{
+				ProfileName: profileName,
+				AssetType:   assetType,
+				Threshold:   profile.Threshold,
+				Insert:      profile.WatchlistOutput.Enabled,
+				Prune:       profile.WatchlistOutput.PruneBelowThreshold,
+			})
+			if err != nil {
+				log.Printf("Watchlist sync failed for profile %s: %v", profileName, err)
+			} else {
+				log.Printf("Watchlist sync (%s): %d added, %d updated, %d duplicates, %d pruned",
+					profileName, len(syncResult.Added), len(syncResult.Updated), len(syncResult.Duplicates), len(syncResult.Pruned))
+			}
+		}
+	}
+
+	return candidates, totalSymbols, summary, nil
+}
+
+// scores exactly the given symbols with a profile's criteria and returns those at or above minScore, highest first.
+// Unlike a profile scan it leaves the watchlist and scan-run history alone
+func ScoreSymbols(ctx context.Context, profileName string, symbols []string, minScore float64, cfg *config.Config) ([]types.Candidate, *SkipSummary, error) {
+	summary := NewSkipSummary(true)
+	if len(symbols) == 0 {
+		return nil, summary, fmt.Errorf("no symbols to score")
+	}
+
+	regime, err := LoadMarketRegime(cfg)
+	if err != nil {
+		log.Printf("Market regime unavailable, scoring without it: %v", err)
+	}
+
+	live := liveSymbolScanner(ctx, profileName, ScreenerCriteriaForProfile(cfg, profileName), utils.AssetTypeStock, liveProblemSymbols(cfg))
+	return live.scoreRanked(symbols, minScore, regime, summary), summary, nil
+}
 
+// sorts candidates by score, highest first, keeping the input order for ties
+func RankCandidates(candidates []types.Candidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+}
+
+// delisting detection against the live database, nil when it's disabled or the database is down
+func liveProblemSymbols(cfg *config.Config) *ProblemSymbolTracker {
+	if db.Queries == nil {
+		return nil
+	}
+	return ProblemSymbolTrackerFromConfig(cfg, db.Queries)
+}
+
+// the screener and Alpaca bars behind a live scan, with the profile's skip list when the database is up and
+// flagged problem symbols skipped when a tracker is given
+func liveSymbolScanner(ctx context.Context, profileName string, criteria ScreenerCriteria, assetType string, problems *ProblemSymbolTracker) symbolScanner {
+	live := symbolScanner{
 		// Use the advanced screener logic instead of simple scoring
-		stockScores, err := ScreenStocksWithType([]string{symbol}, "1Day", 100, criteria, nil, "stock")
-		if err != nil || len(stockScores) == 0 {
+		screen: func(symbol string) (*StockScore, error) {
+			return ScreenSymbol(symbol, "1Day", 100, criteria, nil, assetType)
+		},
+		fetchBars: func(symbol string) ([]types.Bar, error) {
+			return db.GetAlpacaBarsWithTypeRetry(symbol, "1Day", 100, "", assetType, 0)
+		},
+	}
+	if db.Queries != nil {
+		live.isSkipped = func(symbol string) bool {
+			skipped, err := db.Queries.IsSymbolSkipped(ctx, database.IsSymbolSkippedParams{Symbol: symbol, ProfileName: profileName})
+			return err == nil && skipped
+		}
+	}
+	live.isFlagged, live.recordFetch = problems.scannerHooks(ctx)
+	return live
+}
+
+// per-symbol dependencies of a profile scan, swapped for fakes in tests
+type symbolScanner struct {
+	screen    func(symbol string) (*StockScore, error)
+	fetchBars func(symbol string) ([]types.Bar, error)
+	isSkipped func(symbol string) bool // nil when the skip list is unavailable
+
+	isFlagged   func(symbol string) bool       // nil when delisting detection is off
+	recordFetch func(symbol string, err error) // nil when delisting detection is off
+}
+
+// scores each symbol, returning candidates at or above minScore plus every scored symbol for watchlist sync
+func (s symbolScanner) scan(symbols []string, minScore float64, regime *MarketRegime, summary *SkipSummary) ([]types.Candidate, []types.Candidate) {
+	candidates := []types.Candidate{}
+	scored := []types.Candidate{}
+
+	for _, symbol := range symbols {
+		summary.Scanned++
+
+		if s.isSkipped != nil && s.isSkipped(symbol) {
+			summary.Skip(symbol, SkipReasonSkipList, nil)
+			continue
+		}
+		if s.isFlagged != nil && s.isFlagged(symbol) {
+			summary.Skip(symbol, SkipReasonProblemSymbol, nil)
 			continue
 		}
 
-		result := stockScores[0]
+		result, err := s.screen(symbol)
+		if s.recordFetch != nil {
+			s.recordFetch(symbol, err)
+		}
+		if err != nil {
+			summary.Skip(symbol, ScreenSkipReason(err), err)
+			continue
+		}
 
 		if result.Score == 0 && len(result.Signals) == 0 {
+			summary.Skip(symbol, SkipReasonNoSignals, nil)
 			continue
 		}
 
@@ -129,16 +305,29 @@ func PerformProfileScan(ctx context.Context, profileName string, minScore float6
 			analysis = result.Signals[0] // Use first signal as primary analysis
 		}
 
-		bars, err := db.GetAlpacaBars(symbol, "1Day", 100, "")
+		bars, err := s.fetchBars(symbol)
 		if err != nil {
+			if s.recordFetch != nil {
+				s.recordFetch(symbol, err)
+			}
+			summary.Skip(symbol, SkipReasonFetchError, err)
 			continue
 		}
 
+		direction := result.Direction()
+		grade := signalsPkg.GradeSignal(result.FinalSignal, bars, nil)
 		candidate := types.Candidate{
-			Symbol:   symbol,
-			Score:    result.Score,
-			Analysis: analysis,
-			Bars:     bars,
+			Symbol:     symbol,
+			Score:      ApplyMarketRegime(result.Score, direction, regime),
+			Analysis:   analysis,
+			Direction:  direction,
+			Grade:      grade.Grade,
+			GradeScore: grade.Score,
+			Bars:       bars,
+
+			RawScore:       result.RawScore,
+			ShortCandidate: result.ShortCandidate,
+			Components:     result.Components,
 		}
 
 		if result.RSI != nil {
@@ -148,41 +337,92 @@ func PerformProfileScan(ctx context.Context, profileName string, minScore float6
 			candidate.ATR = *result.ATR
 		}
 
-		if candidate.Score >= minScore {
+		scored = append(scored, candidate)
+		if candidate.Score >= minScore || candidate.ShortCandidate {
 			candidates = append(candidates, candidate)
+			summary.Produced++
+		} else {
+			summary.Skip(symbol, SkipReasonBelowThreshold, nil)
 		}
 	}
 
-	return candidates, totalSymbols, nil
+	return candidates, scored
+}
+
+// scan without the watchlist side, ranked highest score first
+func (s symbolScanner) scoreRanked(symbols []string, minScore float64, regime *MarketRegime, summary *SkipSummary) []types.Candidate {
+	candidates, _ := s.scan(symbols, minScore, regime, summary)
+	RankCandidates(candidates)
+	return candidates
 }
 
-// FormatScoutResults formats scan candidates into the API response structure
+// sorts short candidates most bearish raw score first, keeping the input order for ties
+func RankShortCandidates(candidates []types.Candidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].RawScore < candidates[j].RawScore
+	})
+}
+
+// FormatScoutResults formats scan candidates into the API response structure. Short candidates surfaced for a
+// strongly negative raw score are listed separately under short_candidates, most bearish first
 func FormatScoutResults(candidates []types.Candidate, totalScanned, limit int, minScore float64) map[string]interface{} {
-	var opportunities []map[string]interface{}
+	var longs, shorts []types.Candidate
+	for _, candidate := range candidates {
+		if candidate.ShortCandidate {
+			shorts = append(shorts, candidate)
+		} else {
+			longs = append(longs, candidate)
+		}
+	}
+	RankShortCandidates(shorts)
+
+	opportunities := formatScoutEntries(longs, limit)
+	return map[string]interface{}{
+		"scanned_count":    len(opportunities),
+		"total_symbols":    totalScanned,
+		"min_score":        minScore,
+		"limit":            limit,
+		"opportunities":    opportunities,
+		"short_candidates": formatScoutEntries(shorts, limit),
+		"scan_timestamp":   time.Now().Unix(),
+		"message":          "Real-time stock screening results",
+	}
+}
+
+func formatScoutEntries(candidates []types.Candidate, limit int) []map[string]interface{} {
+	var entries []map[string]interface{}
 	for i, candidate := range candidates {
 		if i >= limit {
 			break
 		}
 
 		opp := map[string]interface{}{
-			"symbol":    candidate.Symbol,
-			"score":     candidate.Score, // Score is already 0-10
-			"analysis":  candidate.Analysis,
-			"rsi":       candidate.RSI,
-			"atr":       candidate.ATR,
-			"timestamp": time.Now().Unix(),
-			"rank":      i + 1,
+			"symbol":      candidate.Symbol,
+			"score":       candidate.Score, // Score is already 0-10
+			"analysis":    candidate.Analysis,
+			"rsi":         candidate.RSI,
+			"atr":         candidate.ATR,
+			"direction":   candidate.Direction,
+			"grade":       candidate.Grade,
+			"grade_score": candidate.GradeScore,
+			"timestamp":   time.Now().Unix(),
+			"rank":        i + 1,
 		}
-		opportunities = append(opportunities, opp)
+		if candidate.ShortCandidate {
+			opp["raw_score"] = candidate.RawScore
+		}
+		entries = append(entries, opp)
 	}
+	return entries
+}
 
-	return map[string]interface{}{
-		"scanned_count":  len(opportunities),
-		"total_symbols":  totalScanned,
-		"min_score":      minScore,
-		"limit":          limit,
-		"opportunities":  opportunities,
-		"scan_timestamp": time.Now().Unix(),
-		"message":        "Real-time stock screening results",
+// keeps candidates graded minGrade or better, in their current order
+func FilterByGrade(candidates []types.Candidate, minGrade string) []types.Candidate {
+	kept := make([]types.Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		if signalsPkg.GradeAtLeast(c.Grade, minGrade) {
+			kept = append(kept, c)
+		}
 	}
+	return kept
 }
@@ -0,0 +1,80 @@
+package scanner
+
+// screener score components, in the order they are scored
+const (
+	ComponentRSI               = "rsi"
+	ComponentATR               = "atr"
+	ComponentVolume            = "volume"
+	ComponentNews              = "news"
+	ComponentWhale             = "whale"
+	ComponentPattern           = "pattern"
+	ComponentSupportResistance = "support_resistance"
+	ComponentSignalQuality     = "signal_quality"
+)
+
+// points each component added to a symbol's score, keyed by the Component names; the volume dry-up bonus counts
+// as volume and the S/R validation bonus or penalty as support_resistance
+type ScoreComponents map[string]float64
+
+var screenerComponents = []string{
+	ComponentRSI, ComponentATR, ComponentVolume, ComponentNews,
+	ComponentWhale, ComponentPattern, ComponentSupportResistance, ComponentSignalQuality,
+}
+
+// max points each component adds to the 0-10 score
+var screenerComponentPoints = map[string]float64{
+	ComponentRSI:               2.0,
+	ComponentATR:               1.0,
+	ComponentVolume:            1.5,
+	ComponentNews:              0.5,
+	ComponentWhale:             0.5,
+	ComponentPattern:           1.0,
+	ComponentSupportResistance: 1.5,
+	ComponentSignalQuality:     2.0,
+}
+
+// bars a component needs before its output means anything; RSI/ATR come precomputed and news isn't bar based
+var DefaultComponentMinBars = map[string]int{
+	ComponentVolume:            20, // latest bar against the 20-bar average
+	ComponentWhale:             21, // 20-bar volume baseline before the first bar checked
+	ComponentPattern:           10, // longest single pattern formation
+	ComponentSignalQuality:     20, // RSI divergence lookback inside the combined signal
+	ComponentSupportResistance: 50, // swing levels from a few weeks of dailies aren't real support
+}
+
+// components the history is too short for, in scoring order
+func (c ScreenerCriteria) UnavailableComponents(barCount int) []string {
+	minBars := c.ComponentMinBars
+	if minBars == nil {
+		minBars = DefaultComponentMinBars
+	}
+
+	var unavailable []string
+	for _, component := range screenerComponents {
+		if barCount < minBars[component] {
+			unavailable = append(unavailable, component)
+		}
+	}
+	return unavailable
+}
+
+// rescales a score earned from the achievable components back onto the full 0-10 range,
+// so a short history isn't penalized for terms it couldn't score
+func renormalizeScore(score float64, unavailable []string) float64 {
+	total, achievable := 0.0, 0.0
+	skipped := make(map[string]bool, len(unavailable))
+	for _, component := range unavailable {
+		skipped[component] = true
+	}
+	for _, component := range screenerComponents {
+		points := screenerComponentPoints[component]
+		total += points
+		if !skipped[component] {
+			achievable += points
+		}
+	}
+	if achievable == 0 {
+		return 0
+	}
+	return score * total / achievable
+}
@@ -0,0 +1,68 @@
+package scanner
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestUnavailableComponents(t *testing.T) {
+	criteria := DefaultScreenerCriteria()
+
+	if got := criteria.UnavailableComponents(200); len(got) != 0 {
+		t.Errorf("200 bars: unavailable = %v, want none", got)
+	}
+	if got, want := criteria.UnavailableComponents(30), []string{ComponentSupportResistance}; !reflect.DeepEqual(got, want) {
+		t.Errorf("30 bars: unavailable = %v, want %v", got, want)
+	}
+	want := []string{ComponentVolume, ComponentWhale, ComponentPattern, ComponentSupportResistance, ComponentSignalQuality}
+	if got := criteria.UnavailableComponents(5); !reflect.DeepEqual(got, want) {
+		t.Errorf("5 bars: unavailable = %v, want %v", got, want)
+	}
+
+	criteria.ComponentMinBars = map[string]int{ComponentPattern: 40}
+	if got, want := criteria.UnavailableComponents(30), []string{ComponentPattern}; !reflect.DeepEqual(got, want) {
+		t.Errorf("custom min bars: unavailable = %v, want %v", got, want)
+	}
+}
+
+// a symbol earning the same share of every component it can be scored on should rank the same
+// whether it has 30 or 200 bars of history
+func TestRenormalizeScore_ShortHistoryNotPenalized(t *testing.T) {
+	criteria := DefaultScreenerCriteria()
+
+	for _, share := range []float64{0.25, 0.6, 1.0} {
+		scoreFor := func(barCount int) (raw, final float64) {
+			unavailable := criteria.UnavailableComponents(barCount)
+			skipped := make(map[string]bool)
+			for _, component := range unavailable {
+				skipped[component] = true
+			}
+			for _, component := range screenerComponents {
+				if !skipped[component] {
+					raw += share * screenerComponentPoints[component]
+				}
+			}
+			if len(unavailable) == 0 {
+				return raw, raw
+			}
+			return raw, renormalizeScore(raw, unavailable)
+		}
+
+		raw30, score30 := scoreFor(30)
+		_, score200 := scoreFor(200)
+
+		if raw30 >= score200 {
+			t.Fatalf("share %.2f: expected the un-normalized 30-bar score (%.2f) to trail 200 bars (%.2f)", share, raw30, score200)
+		}
+		if math.Abs(score30-score200) > 1e-9 {
+			t.Errorf("share %.2f: 30 bars scored %.4f, 200 bars %.4f, want equal", share, score30, score200)
+		}
+	}
+}
+
+func TestRenormalizeScore_NothingAchievable(t *testing.T) {
+	if got := renormalizeScore(3, screenerComponents); got != 0 {
+		t.Errorf("renormalizeScore with every component skipped = %.2f, want 0", got)
+	}
+}
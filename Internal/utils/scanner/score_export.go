@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+const defaultScoreExportDir = "exported_data/score_breakdowns"
+
+// CSV header of a score breakdown export: scan metadata, the score, then one column per component in scoring order
+func ScoreExportColumns() []string {
+	columns := []string{"scan_time", "profile", "symbol", "direction", "score", "raw_score"}
+	return append(columns, screenerComponents...)
+}
+
+func formatPoints(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+// one symbol's row in ScoreExportColumns order; components it didn't score are 0
+func ScoreExportRow(profileName string, scanTime time.Time, candidate types.Candidate) []string {
+	row := []string{
+		scanTime.UTC().Format(time.RFC3339),
+		profileName,
+		candidate.Symbol,
+		candidate.Direction,
+		formatPoints(candidate.Score),
+		formatPoints(candidate.RawScore),
+	}
+	for _, component := range screenerComponents {
+		row = append(row, formatPoints(candidate.Components[component]))
+	}
+	return row
+}
+
+// writes the header and one row per scored symbol
+func WriteScoreBreakdownCSV(w io.Writer, profileName string, scanTime time.Time, candidates []types.Candidate) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(ScoreExportColumns()); err != nil {
+		return err
+	}
+	for _, candidate := range candidates {
+		if err := writer.Write(ScoreExportRow(profileName, scanTime, candidate)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writes the scan's breakdown to dir as scores_<profile>_<time>.csv and returns the path
+func ExportScoreBreakdown(dir, profileName string, scanTime time.Time, candidates []types.Candidate) (string, error) {
+	if dir == "" {
+		dir = defaultScoreExportDir
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("scores_%s_%s.csv", strings.ReplaceAll(profileName, string(filepath.Separator), "_"), scanTime.UTC().Format("20060102_150405"))
+	path := filepath.Join(dir, name)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := WriteScoreBreakdownCSV(file, profileName, scanTime, candidates); err != nil {
+		file.Close()
+		return "", err
+	}
+	return path, file.Close()
+}
+
+// exports the scan's scored symbols when score_export is enabled; a failed write is only logged
+func exportScoreBreakdown(cfg *config.Config, profileName string, scored []types.Candidate, scanTime time.Time) {
+	if cfg == nil || !cfg.ScoreExport.Enabled || len(scored) == 0 {
+		return
+	}
+	path, err := ExportScoreBreakdown(cfg.ScoreExport.Directory, profileName, scanTime, scored)
+	if err != nil {
+		log.Printf("Warning: score breakdown export failed for profile %s: %v", profileName, err)
+		return
+	}
+	log.Printf("Score breakdown for %d symbols written to %s", len(scored), path)
+}
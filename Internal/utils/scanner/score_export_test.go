@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+func TestWriteScoreBreakdownCSV_OneRowPerScoredSymbol(t *testing.T) {
+	components := map[string]ScoreComponents{
+		"AAA": {ComponentRSI: 1.5, ComponentVolume: 0.75, ComponentPattern: 0.4, ComponentSupportResistance: 1.2, ComponentSignalQuality: 1.8, ComponentWhale: 0.25, ComponentNews: 0.5},
+		"BBB": {ComponentRSI: 0.5, ComponentATR: 1},
+		"CCC": {ComponentSignalQuality: -0.5},
+	}
+	s := symbolScanner{
+		screen: func(symbol string) (*StockScore, error) {
+			if symbol == "NONE" {
+				return &StockScore{Symbol: symbol}, nil
+			}
+			total := 0.0
+			for _, points := range components[symbol] {
+				total += points
+			}
+			return &StockScore{Symbol: symbol, Score: max(total, 0), Signals: []string{"signal"}, Components: components[symbol]}, nil
+		},
+		fetchBars: func(symbol string) ([]types.Bar, error) { return []types.Bar{{Close: 10}}, nil },
+	}
+	_, scored := s.scan([]string{"AAA", "NONE", "BBB", "CCC"}, 5, nil, NewSkipSummary(false))
+
+	var buf bytes.Buffer
+	scanTime := time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC)
+	if err := WriteScoreBreakdownCSV(&buf, "swing", scanTime, scored); err != nil {
+		t.Fatalf("WriteScoreBreakdownCSV() error = %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+
+	if len(records)-1 != len(scored) || len(scored) != 3 {
+		t.Fatalf("rows = %d for %d scored symbols, want one per symbol", len(records)-1, len(scored))
+	}
+	header := records[0]
+	metaColumns := len(header) - len(screenerComponents)
+	for i, component := range screenerComponents {
+		if header[metaColumns+i] != component {
+			t.Errorf("column %d = %q, want component %q", metaColumns+i, header[metaColumns+i], component)
+		}
+	}
+
+	aaa := map[string]string{}
+	for i, column := range header {
+		aaa[column] = records[1][i]
+	}
+	if aaa["symbol"] != "AAA" || aaa["profile"] != "swing" || aaa["scan_time"] != "2024-05-01T14:30:00Z" {
+		t.Errorf("AAA row metadata = %v", aaa)
+	}
+	if aaa[ComponentRSI] != "1.5000" || aaa[ComponentSignalQuality] != "1.8000" || aaa[ComponentATR] != "0.0000" {
+		t.Errorf("AAA component columns = %v", aaa)
+	}
+}
+
+func TestExportScoreBreakdown_WritesFilePerScan(t *testing.T) {
+	dir := t.TempDir()
+	scanTime := time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC)
+	path, err := ExportScoreBreakdown(dir, "swing", scanTime, []types.Candidate{{Symbol: "AAA", Score: 6}})
+	if err != nil {
+		t.Fatalf("ExportScoreBreakdown() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading export: %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil || len(records) != 2 {
+		t.Errorf("export = %v (err %v), want a header and one row", records, err)
+	}
+}
@@ -2,9 +2,12 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
@@ -16,6 +19,7 @@ import (
 	signalsPkg "github.com/fazecat/mogulmaker/Internal/strategy/signals"
 	"github.com/fazecat/mogulmaker/Internal/types"
 	"github.com/fazecat/mogulmaker/Internal/utils"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
 )
 
 type TradeSignal struct {
@@ -25,12 +29,54 @@ type TradeSignal struct {
 }
 
 type ScreenerCriteria struct {
+	MinOversoldRSI    float64
+	MaxRSI            float64
+	MinATR            float64
+	MinVolumeRatio    float64
+	EnableShorts      bool           // score short setups (overbought, near resistance) alongside longs and rank by the better one
+	ShortWeight       float64        // scales short-setup points against long ones, 0 means 1
+	ShortCandidate    float64        // raw score at or below this (negative) surfaces a short candidate when shorts are enabled, 0 disables
+	StrictQualityGate bool           // exclude candidates whose signal fails the quality filter instead of penalizing
+	PersistSignals    bool           // store each computed signal in the signals table for audit
+	MinPrice          float64        // exclude symbols whose latest close is below this, 0 disables
+	MaxPrice          float64        // exclude symbols whose latest close is above this, 0 disables
+	MinADX            float64        // trend strength the quality filter requires of a signal, 0 disables
+	ComponentMinBars  map[string]int // bars each component needs before it's scored, DefaultComponentMinBars when nil
+	DryUpLookback     int            // recent bars checked for a volume dry-up, 0 disables the coiling bonus
+	DryUpMaxRatio     float64        // recent/baseline volume counted as dried up, 0 means indicators.DryUpThreshold
+	DryUpPoints       float64        // bonus for a dry-up
+	Crypto            CryptoCriteria // thresholds swapped in when the symbol is a crypto pair
+
+	SignalWeights map[string]float64 // combined-signal weights from the profile, signalsPkg.DefaultSignalWeights for missing keys
+
+	MaxConcurrency int // symbols ScreenStocksWithType scores at once, DefaultScreenConcurrency when 0
+}
+
+// crypto trades around the clock with wider swings than equities, so it gets its own RSI bands and volume bar;
+// zero fields keep the equity value
+type CryptoCriteria struct {
 	MinOversoldRSI float64
 	MaxRSI         float64
-	MinATR         float64
 	MinVolumeRatio float64
 }
 
+const (
+	QualityGateLenient = "lenient"
+	QualityGateStrict  = "strict"
+)
+
+// returned by scoring when a candidate is dropped by the strict quality gate
+var ErrFailedQualityGate = errors.New("signal failed quality filter")
+
+// returned when a symbol has too few bars (or none at all) to score
+var (
+	ErrInsufficientData = utils.ErrInsufficientData
+	ErrNoScreenData     = errors.New("no data available")
+)
+
+// returned when a symbol's price falls outside the profile's min/max price filter
+var ErrOutsidePriceRange = errors.New("price outside allowed range")
+
 type StockScore struct {
 	Symbol         string
 	Score          float64
@@ -44,6 +90,13 @@ type StockScore struct {
 	LongSignal     *TradeSignal
 	ShortSignal    *TradeSignal
 	SRValidation   *signalsPkg.SignalValidationWithSR // S/R analysis
+
+	ScoredDirection string // setup the score was computed for when short signals are enabled, "" otherwise
+
+	RawScore       float64 // score as a long setup before the 0-10 clamp, negative for bearish setups
+	ShortCandidate bool    // RawScore fell to the criteria's ShortCandidate cutoff, so it's surfaced as a short
+
+	Components ScoreComponents // points each component added, before any short-history rescaling
 }
 
 func DefaultScreenerCriteria() ScreenerCriteria {
@@ -52,49 +105,187 @@ func DefaultScreenerCriteria() ScreenerCriteria {
 		MaxRSI:         75,
 		MinATR:         0.1,
 		MinVolumeRatio: 1.0,
+		Crypto: CryptoCriteria{
+			MinOversoldRSI: 30,
+			MaxRSI:         80,
+			MinVolumeRatio: 1.5,
+		},
 	}
 }
 
+// the criteria a symbol of assetType is scored with. Crypto pairs take the Crypto thresholds and skip the
+// profile's share-price filter, which is meaningless for coins; equities come back unchanged
+func (c ScreenerCriteria) ForAsset(symbol, assetType string) ScreenerCriteria {
+	if utils.DetectAssetType(symbol, assetType) != utils.AssetTypeCrypto {
+		return c
+	}
+	if c.Crypto.MinOversoldRSI > 0 {
+		c.MinOversoldRSI = c.Crypto.MinOversoldRSI
+	}
+	if c.Crypto.MaxRSI > 0 {
+		c.MaxRSI = c.Crypto.MaxRSI
+	}
+	if c.Crypto.MinVolumeRatio > 0 {
+		c.MinVolumeRatio = c.Crypto.MinVolumeRatio
+	}
+	c.MinPrice, c.MaxPrice = 0, 0
+	return c
+}
+
+// default criteria with the profile's quality gate mode applied
+func ScreenerCriteriaForProfile(cfg *config.Config, profileName string) ScreenerCriteria {
+	criteria := DefaultScreenerCriteria()
+	if cfg == nil {
+		return criteria
+	}
+	criteria.PersistSignals = cfg.Features.PersistSignals
+	criteria.EnableShorts = cfg.Features.EnableShortSignals
+	criteria.ShortWeight = cfg.Features.ShortSignalWeight
+	criteria.ShortCandidate = cfg.Features.ShortCandidateScore
+	if cfg.VolumeDryUp.Enabled {
+		criteria.DryUpLookback = cfg.VolumeDryUp.Lookback
+		criteria.DryUpMaxRatio = cfg.VolumeDryUp.MaxRatio
+		criteria.DryUpPoints = cfg.VolumeDryUp.Points
+	}
+	if cfg.CryptoScoring.MinOversoldRSI > 0 {
+		criteria.Crypto.MinOversoldRSI = cfg.CryptoScoring.MinOversoldRSI
+	}
+	if cfg.CryptoScoring.MaxRSI > 0 {
+		criteria.Crypto.MaxRSI = cfg.CryptoScoring.MaxRSI
+	}
+	if cfg.CryptoScoring.MinVolumeRatio > 0 {
+		criteria.Crypto.MinVolumeRatio = cfg.CryptoScoring.MinVolumeRatio
+	}
+	if profile := cfg.GetProfile(profileName); profile != nil {
+		criteria.StrictQualityGate = strings.EqualFold(profile.QualityGate, QualityGateStrict)
+		criteria.MinPrice = profile.MinPrice
+		criteria.MaxPrice = profile.MaxPrice
+		criteria.MinADX = profile.MinADX
+		criteria.SignalWeights = signalsPkg.ProfileSignalWeights(profile.SignalWeights)
+	}
+	return criteria
+}
+
+// rejects a price outside the criteria's min/max so untradeable names are dropped before scoring
+func (c ScreenerCriteria) CheckPrice(symbol string, price float64) error {
+	if c.MinPrice > 0 && price < c.MinPrice {
+		return fmt.Errorf("%w: %s at $%.2f is below the $%.2f minimum", ErrOutsidePriceRange, symbol, price, c.MinPrice)
+	}
+	if c.MaxPrice > 0 && price > c.MaxPrice {
+		return fmt.Errorf("%w: %s at $%.2f is above the $%.2f maximum", ErrOutsidePriceRange, symbol, price, c.MaxPrice)
+	}
+	return nil
+}
+
+// symbols ScreenStocksWithType scores at once when the criteria leave MaxConcurrency unset
+const DefaultScreenConcurrency = 8
+
+// ScreenSymbol behind ScreenStocksWithType, swapped for a fake data source in tests
+var screenSymbol = ScreenSymbol
+
+// scores the symbols on up to criteria.MaxConcurrency workers and returns them best score first (ties keep the
+// input order); a symbol that can't be scored is logged and left out
 func ScreenStocksWithType(symbols []string, timeframe string, numBars int, criteria ScreenerCriteria, newsStorage *NewsStorage, assetType string) ([]StockScore, error) {
-	var results []StockScore
+	workers := criteria.MaxConcurrency
+	if workers <= 0 {
+		workers = DefaultScreenConcurrency
+	}
+
+	// each worker writes only its own symbol's slot, so no lock is needed
+	scored := make([]*StockScore, len(symbols))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, symbol := range symbols {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, symbol string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			scored[i] = screenOne(symbol, timeframe, numBars, criteria, newsStorage, assetType)
+		}(i, symbol)
+	}
+	wg.Wait()
 
-	for _, symbol := range symbols {
-		score, signals, rsi, atr, longSignal, shortSignal, srValidation, err := scoreStockWithType(symbol, timeframe, numBars, criteria, newsStorage, assetType)
-		if err != nil {
-			log.Printf("Error screening %s: %v", symbol, err)
-			continue
-		}
-		if score == 0 && len(signals) == 0 && rsi == nil && atr == nil {
-			log.Printf("Skipping %s: no data available", symbol)
-			continue
+	var results []StockScore
+	for _, result := range scored {
+		if result != nil {
+			results = append(results, *result)
 		}
-		results = append(results, StockScore{
-			Symbol:       symbol,
-			Score:        score,
-			Signals:      signals,
-			RSI:          rsi,
-			ATR:          atr,
-			LongSignal:   longSignal,
-			ShortSignal:  shortSignal,
-			SRValidation: srValidation,
-		})
-	}
-	sort.Slice(results, func(i, j int) bool {
+	}
+	sort.SliceStable(results, func(i, j int) bool {
 		return results[i].Score > results[j].Score
 	})
 
 	return results, nil
 }
 
-func scoreStockWithType(symbol, timeframe string, numBars int, criteria ScreenerCriteria, newsStorage *NewsStorage, assetType string) (score float64, signals []string, rsi, atr *float64, longSignal, shortSignal *TradeSignal, srValidation *signalsPkg.SignalValidationWithSR, err error) {
+// screens one symbol for ScreenStocksWithType, logging why it was dropped and returning nil if it was
+func screenOne(symbol, timeframe string, numBars int, criteria ScreenerCriteria, newsStorage *NewsStorage, assetType string) *StockScore {
+	result, err := screenSymbol(symbol, timeframe, numBars, criteria, newsStorage, assetType)
+	if errors.Is(err, ErrFailedQualityGate) || errors.Is(err, ErrOutsidePriceRange) {
+		log.Printf("Excluding %s: %v", symbol, err)
+		return nil
+	}
+	if errors.Is(err, ErrNoScreenData) {
+		log.Printf("Skipping %s: no data available", symbol)
+		return nil
+	}
+	if err != nil {
+		log.Printf("Error screening %s: %v", symbol, err)
+		return nil
+	}
+	return result
+}
+
+// scores one symbol; a dropped symbol comes back as ErrFailedQualityGate, ErrInsufficientData,
+// ErrNoScreenData, ErrOutsidePriceRange or the fetch error so callers can tell why
+func ScreenSymbol(symbol, timeframe string, numBars int, criteria ScreenerCriteria, newsStorage *NewsStorage, assetType string) (*StockScore, error) {
+	score, rawScore, signals, rsi, atr, longSignal, shortSignal, srValidation, direction, finalSignal, components, err := scoreStockWithType(symbol, timeframe, numBars, criteria, newsStorage, assetType)
+	if err != nil {
+		return nil, err
+	}
+	if score == 0 && len(signals) == 0 && rsi == nil && atr == nil {
+		return nil, fmt.Errorf("%w for %s", ErrNoScreenData, symbol)
+	}
+	result := &StockScore{
+		Symbol:         symbol,
+		Score:          score,
+		Signals:        signals,
+		RSI:            rsi,
+		ATR:            atr,
+		LongSignal:     longSignal,
+		ShortSignal:    shortSignal,
+		SRValidation:   srValidation,
+		FinalSignal:    finalSignal,
+		Recommendation: finalSignal.Recommendation,
+		RawScore:       rawScore,
+		Components:     components,
+	}
+	if criteria.EnableShorts {
+		result.ScoredDirection = direction
+	}
+	if criteria.isShortCandidate(rawScore) {
+		result.ShortCandidate = true
+		result.ScoredDirection = DirectionShort
+	}
+	return result, nil
+}
+
+func scoreStockWithType(symbol, timeframe string, numBars int, criteria ScreenerCriteria, newsStorage *NewsStorage, assetType string) (score, rawScore float64, signals []string, rsi, atr *float64, longSignal, shortSignal *TradeSignal, srValidation *signalsPkg.SignalValidationWithSR, direction string, finalSignal signalsPkg.CombinedSignal, components ScoreComponents, err error) {
+	criteria = criteria.ForAsset(symbol, assetType)
 
-	bars, err := datafeed.GetAlpacaBarsWithType(symbol, timeframe, numBars, "", assetType)
+	bars, err := datafeed.GetAlpacaBarsWithTypeRetry(symbol, timeframe, numBars, "", assetType, 0)
 	if err != nil {
-		return 0, nil, nil, nil, nil, nil, nil, err
+		return 0, 0, nil, nil, nil, nil, nil, nil, "", finalSignal, nil, err
 	}
 
 	if len(bars) < 2 {
-		return 0, nil, nil, nil, nil, nil, nil, fmt.Errorf("insufficient data for %s (need 2 bars, got %d)", symbol, len(bars))
+		return 0, 0, nil, nil, nil, nil, nil, nil, "", finalSignal, nil, fmt.Errorf("%w for %s (need 2 bars, got %d)", ErrInsufficientData, symbol, len(bars))
+	}
+
+	// bars are latest-first, so this filters on the most recent close before any indicator work
+	if err := criteria.CheckPrice(symbol, bars[0].Close); err != nil {
+		return 0, 0, nil, nil, nil, nil, nil, nil, "", finalSignal, nil, err
 	}
 
 	startTime := time.Now().AddDate(0, 0, -180)
@@ -107,14 +298,14 @@ func scoreStockWithType(symbol, timeframe string, numBars int, criteria Screener
 		}
 	}
 
-	rsiMap, rsiErr := datafeed.FetchRSIByTimestampRange(symbol, startTime, endTime)
+	rsiMap, rsiErr := datafeed.FetchRSIByTimestampRange(symbol, timeframe, startTime, endTime)
 	if rsiErr != nil {
 		log.Printf("RSI fetch failed for %s: %v (continuing with other signals)", symbol, rsiErr)
 	} else if len(rsiMap) > 0 {
 		rsi = findLatestValue(rsiMap)
 	}
 
-	atrMap, atrErr := datafeed.FetchATRByTimestampRange(symbol, startTime, endTime)
+	atrMap, atrErr := datafeed.FetchATRByTimestampRange(symbol, timeframe, startTime, endTime)
 	if atrErr != nil {
 		log.Printf("ATR fetch failed for %s: %v (continuing with other signals)", symbol, atrErr)
 	} else if len(atrMap) > 0 {
@@ -128,27 +319,30 @@ func scoreStockWithType(symbol, timeframe string, numBars int, criteria Screener
 	}
 	avgVol20 := utils.CalculateAvgVolume(volumes, 20)
 
+	// components the history can't support are skipped rather than scored as zero, then the rest rescaled
+	unavailable := criteria.UnavailableComponents(len(bars))
+	skip := make(map[string]bool, len(unavailable))
+	for _, component := range unavailable {
+		skip[component] = true
+	}
+
 	// WEIGHTED SCORING SYSTEM (0-10 scale)
 	score = 0.0
 	signals = []string{}
+	components = ScoreComponents{}
+
+	// RSI, pattern and S/R points depend on the trade direction, so they're collected
+	// for both a long and a short setup and the chosen side is added once at the end
+	directional := directionalPoints{}
+	var rsiPoints, srPoints directionalPoints
 
 	// RSI Score (0-2.0 points = 20% weight)
 	if rsi != nil {
-		if *rsi < criteria.MinOversoldRSI {
-			// More oversold = higher score (bullish opportunity)
-			rsiScore := (criteria.MinOversoldRSI - *rsi) / criteria.MinOversoldRSI * 2.0
-			if rsiScore > 2.0 {
-				rsiScore = 2.0
-			}
-			score += rsiScore
-			signals = append(signals, fmt.Sprintf("RSI Oversold: %.2f", *rsi))
-		} else if *rsi > criteria.MaxRSI {
-			// Overbought is negative
-			score -= 1.0
-			signals = append(signals, fmt.Sprintf("RSI Overbought: %.2f", *rsi))
-		} else {
-			// Neutral RSI gets small bonus
-			score += 0.5
+		var rsiSignal string
+		rsiPoints, rsiSignal = scoreRSIDirectional(*rsi, criteria)
+		directional = directional.add(rsiPoints)
+		if rsiSignal != "" {
+			signals = append(signals, rsiSignal)
 		}
 	}
 
@@ -160,33 +354,41 @@ func scoreStockWithType(symbol, timeframe string, numBars int, criteria Screener
 			atrScore = 1.0
 		}
 		score += atrScore
+		components[ComponentATR] = atrScore
 		signals = append(signals, fmt.Sprintf("High Volatility ATR: %.2f", *atr))
 	}
 
 	// Volume Score (0-1.5 points = 15% weight)
-	if avgVol20 > 0 {
+	if avgVol20 > 0 && !skip[ComponentVolume] {
 		volRatio := float64(latestBar.Volume) / avgVol20
-		if volRatio > criteria.MinVolumeRatio {
-			// Scale volume score: 1x = 0.5 pts, 2x = 1.0 pts, 3x+ = 1.5 pts
-			volScore := (volRatio - 1.0) * 0.75
-			if volScore > 1.5 {
-				volScore = 1.5
-			}
+		if volScore, ok := criteria.scoreVolumeRatio(volRatio); ok {
 			score += volScore
+			components[ComponentVolume] += volScore
 			signals = append(signals, fmt.Sprintf("High Volume: %.1fx avg", volRatio))
 		}
 	}
 
+	// Volume dry-up bonus: quiet volume into a base often precedes a breakout
+	if dryUpPoints, dryUpSignal := criteria.scoreVolumeDryUp(volumes); dryUpPoints > 0 {
+		score += dryUpPoints
+		components[ComponentVolume] += dryUpPoints
+		signals = append(signals, dryUpSignal)
+	}
+
 	// News Score (0-0.5 points = 5% weight)
 	if newsStorage != nil {
 		news, err := newsStorage.GetLatestNews(context.Background(), symbol, 1)
 		if err == nil && len(news) > 0 && news[0].Sentiment == Positive {
 			score += 0.5
+			components[ComponentNews] = 0.5
 		}
 	}
 
 	// Whale Activity Score (0-0.5 points = 5% weight)
-	whales := detection.DetectWhales(symbol, bars)
+	var whales []detection.WhaleEvent
+	if !skip[ComponentWhale] {
+		whales = detection.DetectWhales(symbol, bars)
+	}
 	if len(whales) > 0 {
 		whaleScore := 0.0
 		for _, whale := range whales {
@@ -199,43 +401,34 @@ func scoreStockWithType(symbol, timeframe string, numBars int, criteria Screener
 			whaleScore = 0.5
 		}
 		score += whaleScore
+		components[ComponentWhale] = whaleScore
 	}
 
 	// Pattern Detection Score (0-1.0 points = 10% weight)
-	patternDetector := detection.NewPatternDetector()
-	patterns := patternDetector.DetectAllPatterns(bars)
-	patternScore := 0.0
-	for _, pattern := range patterns {
-		if pattern.Detected {
-			switch pattern.Direction {
-			case "LONG":
-				patternScore += (pattern.Confidence / 100.0) * 0.5 // Max 0.5 per pattern
-				signals = append(signals, fmt.Sprintf("UP%s [%.0f%% confidence]", pattern.Pattern, pattern.Confidence))
-			case "SHORT":
-				patternScore += (pattern.Confidence / 100.0) * 0.3 // Max 0.3 per pattern
-				signals = append(signals, fmt.Sprintf("DOWN%s [%.0f%% confidence]", pattern.Pattern, pattern.Confidence))
-			case "NONE":
-				signals = append(signals, fmt.Sprintf("NEUTRAL %s [%.0f%% confidence]", pattern.Pattern, pattern.Confidence))
-			}
-		}
-	}
-	if patternScore > 1.0 {
-		patternScore = 1.0
+	var patterns []detection.PatternSignal
+	if !skip[ComponentPattern] {
+		patterns = detection.NewPatternDetector().DetectAllPatterns(bars)
 	}
-	score += patternScore
+	patternPoints, patternSignals := scorePatternsDirectional(patterns)
+	directional = directional.add(patternPoints)
+	signals = append(signals, patternSignals...)
 
 	// Support/Resistance Score (0-1.5 points = 15% weight)
-	support := indicators.FindSupport(bars)
-	resistance := indicators.FindResistance(bars)
 	currentPrice := latestBar.Close
-
-	if currentPrice < support*1.01 {
-		score += 1.5 // Strong buy signal near support
-		signals = append(signals, fmt.Sprintf("Near Support: $%.2f", support))
+	if !skip[ComponentSupportResistance] {
+		var srSignals []string
+		srPoints, srSignals = scoreSRDirectional(currentPrice, indicators.FindSupport(bars), indicators.FindResistance(bars))
+		directional = directional.add(srPoints)
+		signals = append(signals, srSignals...)
 	}
-	if currentPrice > resistance*0.99 {
-		score -= 1.0 // Penalty for being at resistance
-		signals = append(signals, fmt.Sprintf("Near Resistance: $%.2f", resistance))
+
+	directionalScore, direction := criteria.chooseDirection(directional)
+	score += directionalScore
+	components[ComponentRSI] = criteria.directionalShare(rsiPoints, direction)
+	components[ComponentPattern] = criteria.directionalShare(patternPoints, direction)
+	components[ComponentSupportResistance] = criteria.directionalShare(srPoints, direction)
+	if criteria.EnableShorts {
+		signals = append(signals, fmt.Sprintf("Scored as %s setup (long %.2f / short %.2f)", direction, directional.Long, directional.Short))
 	}
 
 	// Calculate RSI values array for divergence detection
@@ -249,27 +442,30 @@ func scoreStockWithType(symbol, timeframe string, numBars int, criteria Screener
 	}
 
 	// Signal Quality Score (0-2.0 points = 20% weight)
-	combinedSignal := signalsPkg.CalculateSignal(rsi, atr, bars, symbol, "", rsiValues)
-	filter := signalsPkg.NewSignalQualityFilter()
-	filter.MinConfidenceThreshold = 65.0
-	filter.VerboseLogging = false
+	qualityPoints, bearishSignal := 0.0, false
+	combinedSignal := signalsPkg.CalculateSignalWithWeights(rsi, atr, bars, symbol, "", rsiValues, criteria.SignalWeights)
+	if criteria.PersistSignals && datafeed.Queries != nil {
+		if _, err := signalsPkg.RecordSignal(context.Background(), datafeed.Queries, symbol, signalsPkg.SignalSourceScan, timeframe, currentPrice, combinedSignal); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	if !skip[ComponentSignalQuality] {
+		filter := signalsPkg.NewSignalQualityFilter()
+		filter.MinConfidenceThreshold = 65.0
+		filter.MinADX = criteria.MinADX
+		filter.VerboseLogging = false
 
-	tradeSignal := signalsPkg.ConvertToTradeSignal(combinedSignal)
-	filteredResult := filter.FilterSignal(tradeSignal)
+		tradeSignal := signalsPkg.ConvertToTradeSignal(combinedSignal)
+		filteredResult := filter.FilterSignalWithTrend(tradeSignal, reversedBars(bars))
 
-	if filteredResult.Passed {
-		signals = append(signals, fmt.Sprintf("\n[FINAL] %s [Quality: %.1f%% ✓]",
-			signalsPkg.FormatSignal(combinedSignal), filteredResult.QualityScore))
-		// Scale quality score: 65% = 1.0 pts, 100% = 2.0 pts
-		qualityScore := ((filteredResult.QualityScore-65.0)/35.0)*1.0 + 1.0
-		if qualityScore > 2.0 {
-			qualityScore = 2.0
+		qualityScore, qualitySignal, excluded := applyQualityGate(combinedSignal, filteredResult, criteria.StrictQualityGate)
+		if excluded {
+			return 0, 0, nil, nil, nil, nil, nil, nil, "", finalSignal, nil, fmt.Errorf("%w: %s", ErrFailedQualityGate, filteredResult.FailureReason)
 		}
 		score += qualityScore
-	} else {
-		signals = append(signals, fmt.Sprintf("\n[WARNING] SIGNAL FILTERED: %s (Reason: %s)",
-			signalsPkg.FormatSignal(combinedSignal), filteredResult.FailureReason))
-		score -= 0.5 // Small penalty for filtered signal
+		components[ComponentSignalQuality] = qualityScore
+		qualityPoints, bearishSignal = qualityScore, tradeSignal.Direction == DirectionShort
+		signals = append(signals, qualitySignal)
 	}
 
 	longSignal = AnalyzeForLongs(latestBar, rsi, atr, criteria)
@@ -283,7 +479,7 @@ func scoreStockWithType(symbol, timeframe string, numBars int, criteria Screener
 		signalToValidate = shortSignal
 	}
 
-	if signalToValidate != nil {
+	if signalToValidate != nil && !skip[ComponentSupportResistance] {
 		// Convert TradeSignal to types.TradeSignal for validation
 		typesSignal := &types.TradeSignal{
 			Direction:  signalToValidate.Direction,
@@ -302,13 +498,23 @@ func scoreStockWithType(symbol, timeframe string, numBars int, criteria Screener
 		if srValidation.IsValidLocation {
 			srBonus := (srValidation.ValidationScore / 100.0) * 0.5
 			score += srBonus
+			components[ComponentSupportResistance] += srBonus
 			signals = append(signals, fmt.Sprintf("[VALID] S/R: %.0f%% - %s", srValidation.ValidationScore, srValidation.DetailedAnalysis))
 		} else {
 			score -= 0.5 // Penalty for poor S/R positioning
+			components[ComponentSupportResistance] -= 0.5
 			signals = append(signals, fmt.Sprintf("[WARNING] S/R: %.0f%% - %s", srValidation.ValidationScore, srValidation.DetailedAnalysis))
 		}
 	}
 
+	rawScore = rawLongScore(score, directionalScore, directional.Long, qualityPoints, bearishSignal)
+
+	if len(unavailable) > 0 {
+		score = renormalizeScore(score, unavailable)
+		rawScore = renormalizeScore(rawScore, unavailable)
+		signals = append(signals, fmt.Sprintf("Short history (%d bars), not scored: %s", len(bars), strings.Join(unavailable, ", ")))
+	}
+
 	// Final capping to ensure 0-10 range
 	if score > 10.0 {
 		score = 10.0
@@ -317,17 +523,104 @@ func scoreStockWithType(symbol, timeframe string, numBars int, criteria Screener
 		score = 0.0
 	}
 
-	return score, signals, rsi, atr, longSignal, shortSignal, srValidation, nil
+	return score, rawScore, signals, rsi, atr, longSignal, shortSignal, srValidation, direction, combinedSignal, components, nil
 }
 
+// volume points (up to 1.5) for the latest bar against its 20-bar average: 1x = 0, 2x = 0.75, 3x+ = 1.5; ok is
+// false until the ratio clears MinVolumeRatio
+func (c ScreenerCriteria) scoreVolumeRatio(volRatio float64) (float64, bool) {
+	if volRatio <= c.MinVolumeRatio {
+		return 0, false
+	}
+	return capPoints((volRatio-1.0)*0.75, 1.5), true
+}
+
+// the coiling bonus for latest-first volumes whose recent window has dried up against its baseline
+func (c ScreenerCriteria) scoreVolumeDryUp(volumes []int64) (float64, string) {
+	if c.DryUpLookback <= 0 || c.DryUpPoints <= 0 {
+		return 0, ""
+	}
+	chronological := make([]int64, len(volumes))
+	for i, v := range volumes {
+		chronological[len(volumes)-1-i] = v
+	}
+
+	maxRatio := c.DryUpMaxRatio
+	if maxRatio <= 0 {
+		maxRatio = indicators.DryUpThreshold
+	}
+	ratio := indicators.VolumeDryUpRatio(chronological, c.DryUpLookback)
+	if ratio <= 0 || ratio > maxRatio {
+		return 0, ""
+	}
+	return c.DryUpPoints, fmt.Sprintf("Volume Dry-Up: last %d bars at %.0f%% of avg (coiling)", c.DryUpLookback, ratio*100)
+}
+
+// a copy of latest-first bars in the oldest-first order the trend indicators read
+func reversedBars(bars []datafeed.Bar) []datafeed.Bar {
+	reversed := make([]datafeed.Bar, len(bars))
+	for i, bar := range bars {
+		reversed[len(bars)-1-i] = bar
+	}
+	return reversed
+}
+
+// returns the score adjustment for the final signal quality check, or excluded=true in strict mode
+func applyQualityGate(combinedSignal signalsPkg.CombinedSignal, filteredResult *signalsPkg.FilteredSignal, strict bool) (scoreDelta float64, signal string, excluded bool) {
+	if filteredResult.Passed {
+		// Scale quality score: 65% = 1.0 pts, 100% = 2.0 pts
+		qualityScore := ((filteredResult.QualityScore-65.0)/35.0)*1.0 + 1.0
+		if qualityScore > 2.0 {
+			qualityScore = 2.0
+		}
+		return qualityScore, fmt.Sprintf("\n[FINAL] %s [Quality: %.1f%% ✓]",
+			signalsPkg.FormatSignal(combinedSignal), filteredResult.QualityScore), false
+	}
+
+	if strict {
+		return 0, "", true
+	}
+
+	// Small penalty for filtered signal
+	return -0.5, fmt.Sprintf("\n[WARNING] SIGNAL FILTERED: %s (Reason: %s)",
+		signalsPkg.FormatSignal(combinedSignal), filteredResult.FailureReason), false
+}
+
+// active, tradable US equities; served from the shared asset cache so scans don't list the universe every time
 func GetTradableAssets() ([]string, error) {
+	return tradableAssets.Symbols()
+}
+
+// tradable crypto pairs (BTC/USD form), cached like the equity list
+func GetTradableCrypto() ([]string, error) {
+	return tradableCrypto.Symbols()
+}
+
+// the tradable universe a scan of assetType walks
+func tradableSymbolsFor(assetType string) ([]string, error) {
+	if assetType == utils.AssetTypeCrypto {
+		return GetTradableCrypto()
+	}
+	return GetTradableAssets()
+}
+
+func fetchTradableAssets() ([]string, error) {
+	return fetchTradableAssetsOfClass(alpaca.USEquity)
+}
+
+func fetchTradableCrypto() ([]string, error) {
+	return fetchTradableAssetsOfClass(alpaca.Crypto)
+}
+
+func fetchTradableAssetsOfClass(class alpaca.AssetClass) ([]string, error) {
 	client := datafeed.GetAlpacaClient()
 	if client == nil {
 		return nil, fmt.Errorf("alpaca client not initialized - call InitAlpacaClient() first")
 	}
 
 	assets, err := client.GetAssets(alpaca.GetAssetsRequest{
-		Status: "active",
+		Status:     "active",
+		AssetClass: string(class),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch assets from Alpaca: %v", err)
@@ -335,12 +628,12 @@ func GetTradableAssets() ([]string, error) {
 
 	symbols := make([]string, 0, len(assets))
 	for _, asset := range assets {
-		if asset.Class == "us_equity" && asset.Tradable {
+		if asset.Class == class && asset.Tradable {
 			symbols = append(symbols, asset.Symbol)
 		}
 	}
 
-	log.Printf("Fetched %d tradeable assets from Alpaca", len(symbols))
+	log.Printf("Fetched %d tradeable %s assets from Alpaca", len(symbols), class)
 	return symbols, nil
 }
 
@@ -0,0 +1,380 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	datafeed "github.com/fazecat/mogulmaker/Internal/database"
+	newsPkg "github.com/fazecat/mogulmaker/Internal/news_scraping"
+	"github.com/fazecat/mogulmaker/Internal/strategy/detection"
+	signalsPkg "github.com/fazecat/mogulmaker/Internal/strategy/signals"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+func TestApplyQualityGate(t *testing.T) {
+	filter := signalsPkg.NewSignalQualityFilter()
+	filter.MinConfidenceThreshold = 65.0
+
+	passing := signalsPkg.CombinedSignal{Recommendation: "BUY", Confidence: 90.0, Reasoning: "Strong buy signals"}
+	failing := signalsPkg.CombinedSignal{Recommendation: "WAIT", Confidence: 50.0, Reasoning: "Neutral signals"}
+
+	tests := []struct {
+		name         string
+		combined     signalsPkg.CombinedSignal
+		strict       bool
+		wantExcluded bool
+		wantPositive bool
+	}{
+		{"passing signal included in strict mode", passing, true, false, true},
+		{"passing signal included in lenient mode", passing, false, false, true},
+		{"failing signal excluded in strict mode", failing, true, true, false},
+		{"failing signal penalized in lenient mode", failing, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := filter.FilterSignal(&types.TradeSignal{
+				Direction:  signalsPkg.ConvertToTradeSignal(tt.combined).Direction,
+				Confidence: tt.combined.Confidence,
+				Reasoning:  tt.combined.Reasoning,
+			})
+
+			delta, signal, excluded := applyQualityGate(tt.combined, filtered, tt.strict)
+
+			if excluded != tt.wantExcluded {
+				t.Errorf("excluded = %v, want %v", excluded, tt.wantExcluded)
+			}
+			if excluded {
+				return
+			}
+			if signal == "" {
+				t.Errorf("Expected a quality signal description")
+			}
+			if tt.wantPositive && delta <= 0 {
+				t.Errorf("Expected positive score delta for passing signal, got %.2f", delta)
+			}
+			if !tt.wantPositive && delta != -0.5 {
+				t.Errorf("Expected -0.5 penalty in lenient mode, got %.2f", delta)
+			}
+		})
+	}
+}
+
+func TestScreenerCriteriaForProfile(t *testing.T) {
+	cfg := &config.Config{Profiles: map[string]config.ProfileConfig{
+		"strict_profile":  {QualityGate: QualityGateStrict},
+		"lenient_profile": {QualityGate: QualityGateLenient},
+	}}
+
+	if !ScreenerCriteriaForProfile(cfg, "strict_profile").StrictQualityGate {
+		t.Errorf("strict profile should enable StrictQualityGate")
+	}
+	if ScreenerCriteriaForProfile(cfg, "lenient_profile").StrictQualityGate {
+		t.Errorf("lenient profile should not enable StrictQualityGate")
+	}
+	if ScreenerCriteriaForProfile(nil, "strict_profile").StrictQualityGate {
+		t.Errorf("nil config should fall back to lenient defaults")
+	}
+}
+
+func TestScreenerCriteria_CheckPrice(t *testing.T) {
+	criteria := DefaultScreenerCriteria()
+	criteria.MinPrice = 5
+
+	if err := criteria.CheckPrice("PENNY", 0.50); !errors.Is(err, ErrOutsidePriceRange) {
+		t.Errorf("$0.50 at a $5 minimum: error = %v, want ErrOutsidePriceRange", err)
+	}
+	if err := criteria.CheckPrice("MID", 50); err != nil {
+		t.Errorf("$50 at a $5 minimum: unexpected error %v", err)
+	}
+
+	criteria.MaxPrice = 40
+	if err := criteria.CheckPrice("MID", 50); !errors.Is(err, ErrOutsidePriceRange) {
+		t.Errorf("$50 at a $40 maximum: error = %v, want ErrOutsidePriceRange", err)
+	}
+	if reason := ScreenSkipReason(criteria.CheckPrice("MID", 50)); reason != SkipReasonPriceFilter {
+		t.Errorf("Skip reason = %s, want %s", reason, SkipReasonPriceFilter)
+	}
+
+	if err := DefaultScreenerCriteria().CheckPrice("PENNY", 0.01); err != nil {
+		t.Errorf("Default criteria should not filter on price, got %v", err)
+	}
+}
+
+func TestScreenerCriteriaForProfile_PriceFilter(t *testing.T) {
+	cfg := &config.Config{Profiles: map[string]config.ProfileConfig{
+		"balanced": {MinPrice: 5, MaxPrice: 500},
+	}}
+
+	criteria := ScreenerCriteriaForProfile(cfg, "balanced")
+	if criteria.MinPrice != 5 || criteria.MaxPrice != 500 {
+		t.Errorf("Price filter = %.2f-%.2f, want 5-500", criteria.MinPrice, criteria.MaxPrice)
+	}
+	if other := ScreenerCriteriaForProfile(cfg, "missing"); other.MinPrice != 0 || other.MaxPrice != 0 {
+		t.Errorf("Unknown profile should not filter on price")
+	}
+}
+
+func TestScreenerCriteria_ForAssetCrypto(t *testing.T) {
+	criteria := DefaultScreenerCriteria()
+	criteria.MinPrice, criteria.MaxPrice = 5, 500
+
+	equity := criteria.ForAsset("AAPL", "stock")
+	if equity.MinOversoldRSI != 35 || equity.MaxRSI != 75 || equity.MinVolumeRatio != 1.0 || equity.MaxPrice != 500 {
+		t.Errorf("Equity criteria changed: %+v", equity)
+	}
+
+	crypto := criteria.ForAsset("BTC/USD", "")
+	if crypto.MinOversoldRSI != 30 || crypto.MaxRSI != 80 || crypto.MinVolumeRatio != 1.5 {
+		t.Errorf("Crypto thresholds = %.0f/%.0f/%.1f, want 30/80/1.5", crypto.MinOversoldRSI, crypto.MaxRSI, crypto.MinVolumeRatio)
+	}
+	if err := crypto.CheckPrice("BTC/USD", 60000); err != nil {
+		t.Errorf("Crypto should skip the equity price filter, got %v", err)
+	}
+
+	rsi, atr := 32.0, 1.0
+	if AnalyzeForLongs(datafeed.Bar{}, &rsi, &atr, equity) == nil {
+		t.Errorf("RSI 32 should be an equity long")
+	}
+	if AnalyzeForLongs(datafeed.Bar{}, &rsi, &atr, crypto) != nil {
+		t.Errorf("RSI 32 is not oversold enough for crypto")
+	}
+
+	if _, ok := equity.scoreVolumeRatio(1.2); !ok {
+		t.Errorf("1.2x volume should score for an equity")
+	}
+	if _, ok := crypto.scoreVolumeRatio(1.2); ok {
+		t.Errorf("1.2x volume should not score for crypto")
+	}
+}
+
+func TestScreenerCriteriaForProfile_CryptoScoring(t *testing.T) {
+	cfg := &config.Config{CryptoScoring: config.CryptoScoringConfig{MinOversoldRSI: 25, MinVolumeRatio: 2}}
+
+	crypto := ScreenerCriteriaForProfile(cfg, "balanced").ForAsset("ETH/USD", "crypto")
+	if crypto.MinOversoldRSI != 25 || crypto.MaxRSI != 80 || crypto.MinVolumeRatio != 2 {
+		t.Errorf("Crypto thresholds = %.0f/%.0f/%.1f, want 25/80/2", crypto.MinOversoldRSI, crypto.MaxRSI, crypto.MinVolumeRatio)
+	}
+}
+
+func TestDirectionalScoring_OverboughtAtResistanceRanksAsShort(t *testing.T) {
+	criteria := DefaultScreenerCriteria()
+
+	rsiPoints, rsiSignal := scoreRSIDirectional(90, criteria)
+	srPoints, srSignals := scoreSRDirectional(101, 80, 100)
+	points := rsiPoints.add(srPoints)
+	if rsiSignal == "" || len(srSignals) != 1 {
+		t.Errorf("signals = %q %v, want the overbought and resistance notes", rsiSignal, srSignals)
+	}
+
+	// longs-only keeps the old bias: overbought and at resistance is a poor setup
+	longOnly, direction := criteria.chooseDirection(points)
+	if direction != DirectionLong || longOnly != -2.0 {
+		t.Errorf("shorts disabled = %.2f %s, want -2.00 LONG", longOnly, direction)
+	}
+
+	criteria.EnableShorts = true
+	shortScore, direction := criteria.chooseDirection(points)
+	// RSI 90 is 60% of the way from 75 to 100 (1.2 pts) plus 1.5 for sitting at resistance
+	if direction != DirectionShort || math.Abs(shortScore-2.7) > 1e-9 {
+		t.Errorf("shorts enabled = %.2f %s, want 2.70 SHORT", shortScore, direction)
+	}
+
+	criteria.ShortWeight = 0.5
+	if halved, _ := criteria.chooseDirection(points); math.Abs(halved-1.35) > 1e-9 {
+		t.Errorf("short weight 0.5 = %.2f, want 1.35", halved)
+	}
+
+	// an oversold name at support still ranks long with shorts on
+	oversold, _ := scoreRSIDirectional(20, criteria)
+	atSupport, _ := scoreSRDirectional(80.5, 80, 100)
+	if _, direction := criteria.chooseDirection(oversold.add(atSupport)); direction != DirectionLong {
+		t.Errorf("oversold at support ranked %s, want LONG", direction)
+	}
+}
+
+func TestScorePatternsDirectional_FavorsMatchingSide(t *testing.T) {
+	points, signals := scorePatternsDirectional([]detection.PatternSignal{
+		{Pattern: "Double Top", Detected: true, Direction: DirectionShort, Confidence: 80},
+		{Pattern: "Ignored", Detected: false, Direction: DirectionLong, Confidence: 90},
+	})
+	if math.Abs(points.Short-0.4) > 1e-9 || math.Abs(points.Long-0.24) > 1e-9 {
+		t.Errorf("points = %+v, want short 0.40 / long 0.24", points)
+	}
+	if len(signals) != 1 {
+		t.Errorf("signals = %v, want only the detected pattern", signals)
+	}
+}
+
+func TestStockScore_DirectionPrefersScoredSide(t *testing.T) {
+	s := StockScore{
+		LongSignal:      &TradeSignal{Direction: DirectionLong, Confidence: 80},
+		ShortSignal:     &TradeSignal{Direction: DirectionShort, Confidence: 60},
+		ScoredDirection: DirectionShort,
+	}
+	if s.Direction() != DirectionShort {
+		t.Errorf("Direction() = %s, want the scored SHORT side", s.Direction())
+	}
+	s.ScoredDirection = ""
+	if s.Direction() != DirectionLong {
+		t.Errorf("Direction() = %s, want LONG from signal confidence", s.Direction())
+	}
+}
+
+func TestShortCandidates_BearishSetupTopsShortList(t *testing.T) {
+	criteria := DefaultScreenerCriteria()
+	criteria.EnableShorts = true
+	criteria.ShortCandidate = -2
+
+	// overbought at resistance, with a bearish-divergence SELL passing the quality filter for 2 points
+	rsiPoints, _ := scoreRSIDirectional(90, criteria)
+	srPoints, _ := scoreSRDirectional(101, 80, 100)
+	bearish := rsiPoints.add(srPoints)
+	chosen, direction := criteria.chooseDirection(bearish)
+	base := 1.5 // volatility and volume
+	raw := rawLongScore(base+chosen+2, chosen, bearish.Long, 2, true)
+	if direction != DirectionShort || math.Abs(raw-(-2.5)) > 1e-9 {
+		t.Fatalf("bearish setup = %s raw %.2f, want SHORT raw -2.50", direction, raw)
+	}
+	if !criteria.isShortCandidate(raw) {
+		t.Errorf("raw %.2f at cutoff -2 should be a short candidate", raw)
+	}
+	if criteria.isShortCandidate(-1) {
+		t.Errorf("raw -1 above cutoff -2 should not be a short candidate")
+	}
+	longOnly := criteria
+	longOnly.EnableShorts = false
+	if longOnly.isShortCandidate(raw) {
+		t.Errorf("short candidates need short signals enabled")
+	}
+
+	results := map[string]*StockScore{
+		"BEAR": {Symbol: "BEAR", Score: 0, RawScore: raw, ShortCandidate: true, ScoredDirection: DirectionShort, Signals: []string{"RSI Overbought: 90.00"}},
+		"SOFT": {Symbol: "SOFT", Score: 0, RawScore: -2.1, ShortCandidate: true, ScoredDirection: DirectionShort, Signals: []string{"Near Resistance: $100.00"}},
+		"BULL": {Symbol: "BULL", Score: 7, RawScore: 7, ScoredDirection: DirectionLong, Signals: []string{"RSI Oversold: 25.00"}},
+		"MEH":  {Symbol: "MEH", Score: 0, RawScore: -1, ScoredDirection: DirectionLong, Signals: []string{"signal"}},
+	}
+	s := symbolScanner{
+		screen: func(symbol string) (*StockScore, error) { return results[symbol], nil },
+		fetchBars: func(symbol string) ([]types.Bar, error) {
+			return []types.Bar{{Close: 10}}, nil
+		},
+	}
+	ranked := s.scoreRanked([]string{"MEH", "SOFT", "BULL", "BEAR"}, 5, nil, NewSkipSummary(true))
+	if len(ranked) != 3 {
+		t.Fatalf("candidates = %+v, want BULL plus the two short candidates despite the min score", ranked)
+	}
+
+	response := FormatScoutResults(ranked, 4, 10, 5)
+	shorts := response["short_candidates"].([]map[string]interface{})
+	if len(shorts) != 2 || shorts[0]["symbol"] != "BEAR" || shorts[0]["direction"] != DirectionShort || shorts[0]["raw_score"] != raw {
+		t.Errorf("short_candidates = %v, want BEAR first as a SHORT with its raw score", shorts)
+	}
+	if longs := response["opportunities"].([]map[string]interface{}); len(longs) != 1 || longs[0]["symbol"] != "BULL" {
+		t.Errorf("opportunities = %v, want only BULL", longs)
+	}
+}
+
+func TestScoreVolumeDryUp_CoilingBonus(t *testing.T) {
+	// latest-first, as the screener holds them: five quiet bars on top of a steady million a day
+	volumes := []int64{300_000, 350_000, 400_000, 450_000, 500_000}
+	for i := 0; i < 20; i++ {
+		volumes = append(volumes, 1_000_000)
+	}
+
+	criteria := ScreenerCriteriaForProfile(&config.Config{VolumeDryUp: config.VolumeDryUpConfig{Enabled: true, Lookback: 5, Points: 0.3}}, "")
+	if points, signal := criteria.scoreVolumeDryUp(volumes); points != 0.3 || signal == "" {
+		t.Errorf("dry-up: points %.2f, signal %q, want the 0.3 bonus", points, signal)
+	}
+	if points, _ := criteria.scoreVolumeDryUp(volumes[5:]); points != 0 {
+		t.Errorf("steady volume: points %.2f, want none", points)
+	}
+	if points, _ := DefaultScreenerCriteria().scoreVolumeDryUp(volumes); points != 0 {
+		t.Errorf("disabled: points %.2f, want none", points)
+	}
+}
+
+// swaps the screener's data source for fake, which answers after delay, restoring it when the test ends
+func fakeScreenSymbol(tb testing.TB, delay time.Duration, fake func(symbol string) (*StockScore, error)) {
+	tb.Helper()
+	original := screenSymbol
+	screenSymbol = func(symbol, timeframe string, numBars int, criteria ScreenerCriteria, newsStorage *newsPkg.NewsStorage, assetType string) (*StockScore, error) {
+		time.Sleep(delay)
+		return fake(symbol)
+	}
+	tb.Cleanup(func() { screenSymbol = original })
+}
+
+func TestScreenStocksWithType_ConcurrentKeepsRankingAndSkipsErrors(t *testing.T) {
+	var inFlight, peak int32
+	fakeScreenSymbol(t, 0, func(symbol string) (*StockScore, error) {
+		running := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&peak)
+			if running <= seen || atomic.CompareAndSwapInt32(&peak, seen, running) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		switch symbol {
+		case "BAD":
+			return nil, errors.New("fetch failed")
+		case "GATED":
+			return nil, ErrFailedQualityGate
+		case "EMPTY":
+			return nil, ErrNoScreenData
+		}
+		return &StockScore{Symbol: symbol, Score: float64(len(symbol))}, nil
+	})
+
+	symbols := []string{"A", "BAD", "CCC", "BB", "GATED", "DD", "EMPTY", "EEEE"}
+	results, err := ScreenStocksWithType(symbols, "1Day", 100, ScreenerCriteria{MaxConcurrency: 3}, nil, "stock")
+	if err != nil {
+		t.Fatalf("ScreenStocksWithType() error = %v", err)
+	}
+
+	var got []string
+	for _, result := range results {
+		got = append(got, result.Symbol)
+	}
+	// best score first, equal scores in input order
+	want := []string{"EEEE", "CCC", "BB", "DD", "A"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+	if peak > 3 || peak < 2 {
+		t.Errorf("peak concurrency = %d, want between 2 and the limit of 3", peak)
+	}
+}
+
+func benchmarkScreenStocks(b *testing.B, concurrency int) {
+	fakeScreenSymbol(b, time.Millisecond, func(symbol string) (*StockScore, error) {
+		return &StockScore{Symbol: symbol, Score: 5}, nil
+	})
+	symbols := make([]string, 32)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYM%d", i)
+	}
+	criteria := ScreenerCriteria{MaxConcurrency: concurrency}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ScreenStocksWithType(symbols, "1Day", 100, criteria, nil, "stock"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// 32 symbols at 1ms each: about 32ms serially against about 4ms on the default pool
+func BenchmarkScreenStocksWithType_Serial(b *testing.B) { benchmarkScreenStocks(b, 1) }
+
+func BenchmarkScreenStocksWithType_Concurrent(b *testing.B) {
+	benchmarkScreenStocks(b, DefaultScreenConcurrency)
+}
@@ -0,0 +1,69 @@
+package scanner
+
+import (
+	"errors"
+	"log"
+)
+
+// why a scanned symbol produced no candidate
+const (
+	SkipReasonInsufficientData = "insufficient_data"
+	SkipReasonNoData           = "no_data"
+	SkipReasonFetchError       = "fetch_error"
+	SkipReasonSkipList         = "skip_list"
+	SkipReasonQualityGate      = "quality_gate"
+	SkipReasonNoSignals        = "no_signals"
+	SkipReasonBelowThreshold   = "below_threshold"
+	SkipReasonUpdateFailed     = "update_failed"
+	SkipReasonPriceFilter      = "price_filter"
+	SkipReasonProblemSymbol    = "problem_symbol"
+)
+
+// tallies skipped symbols by reason so callers can explain why only X of Y produced results
+type SkipSummary struct {
+	Scanned  int                 `json:"scanned"`
+	Produced int                 `json:"produced"`
+	Skipped  int                 `json:"skipped"`
+	Reasons  map[string]int      `json:"reasons"`
+	Symbols  map[string][]string `json:"symbols,omitempty"` // per-reason symbols, only kept when LogSymbols is set
+
+	LogSymbols bool `json:"-"` // log each skip and keep the symbol lists (features.log_skipped_symbols)
+}
+
+func NewSkipSummary(logSymbols bool) *SkipSummary {
+	summary := &SkipSummary{Reasons: make(map[string]int), LogSymbols: logSymbols}
+	if logSymbols {
+		summary.Symbols = make(map[string][]string)
+	}
+	return summary
+}
+
+func (s *SkipSummary) Skip(symbol, reason string, err error) {
+	s.Skipped++
+	s.Reasons[reason]++
+	if !s.LogSymbols {
+		return
+	}
+	s.Symbols[reason] = append(s.Symbols[reason], symbol)
+	if err != nil {
+		log.Printf("Scan skipped %s (%s): %v", symbol, reason, err)
+	} else {
+		log.Printf("Scan skipped %s (%s)", symbol, reason)
+	}
+}
+
+// maps a ScreenSymbol error onto a skip reason
+func ScreenSkipReason(err error) string {
+	switch {
+	case errors.Is(err, ErrInsufficientData):
+		return SkipReasonInsufficientData
+	case errors.Is(err, ErrNoScreenData):
+		return SkipReasonNoData
+	case errors.Is(err, ErrFailedQualityGate):
+		return SkipReasonQualityGate
+	case errors.Is(err, ErrOutsidePriceRange):
+		return SkipReasonPriceFilter
+	default:
+		return SkipReasonFetchError
+	}
+}
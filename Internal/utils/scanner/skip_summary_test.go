@@ -0,0 +1,128 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+func TestSymbolScannerScan_TalliesSkipReasons(t *testing.T) {
+	scores := map[string]float64{"GOOD1": 8, "GOOD2": 7, "LOW1": 3, "LOW2": 2, "LOW3": 1}
+	insufficient := map[string]bool{"THIN1": true, "THIN2": true}
+
+	s := symbolScanner{
+		screen: func(symbol string) (*StockScore, error) {
+			switch {
+			case insufficient[symbol]:
+				return nil, fmt.Errorf("%w for %s (need 2 bars, got 1)", ErrInsufficientData, symbol)
+			case symbol == "GATED":
+				return nil, fmt.Errorf("%w: low confidence", ErrFailedQualityGate)
+			case symbol == "DOWN":
+				return nil, errors.New("API returned status 500")
+			case symbol == "FLAT":
+				return &StockScore{Symbol: symbol}, nil
+			}
+			return &StockScore{Symbol: symbol, Score: scores[symbol], Signals: []string{"signal"}}, nil
+		},
+		fetchBars: func(symbol string) ([]types.Bar, error) {
+			return []types.Bar{{Close: 10}}, nil
+		},
+		isSkipped: func(symbol string) bool { return symbol == "IGNORED" },
+	}
+
+	symbols := []string{"GOOD1", "LOW1", "THIN1", "GATED", "GOOD2", "IGNORED", "LOW2", "DOWN", "THIN2", "FLAT", "LOW3"}
+	summary := NewSkipSummary(true)
+
+	candidates, scored := s.scan(symbols, 5, nil, summary)
+
+	if len(candidates) != 2 {
+		t.Fatalf("Expected 2 candidates, got %d", len(candidates))
+	}
+	if len(scored) != 5 {
+		t.Errorf("Expected 5 scored symbols for watchlist sync, got %d", len(scored))
+	}
+	if summary.Scanned != len(symbols) || summary.Produced != 2 || summary.Skipped != len(symbols)-2 {
+		t.Errorf("Summary totals = scanned %d produced %d skipped %d", summary.Scanned, summary.Produced, summary.Skipped)
+	}
+
+	want := map[string]int{
+		SkipReasonInsufficientData: 2,
+		SkipReasonBelowThreshold:   3,
+		SkipReasonQualityGate:      1,
+		SkipReasonFetchError:       1,
+		SkipReasonSkipList:         1,
+		SkipReasonNoSignals:        1,
+	}
+	for reason, count := range want {
+		if summary.Reasons[reason] != count {
+			t.Errorf("Reasons[%s] = %d, want %d", reason, summary.Reasons[reason], count)
+		}
+	}
+	if len(summary.Reasons) != len(want) {
+		t.Errorf("Unexpected reasons: %v", summary.Reasons)
+	}
+	if got := summary.Symbols[SkipReasonInsufficientData]; len(got) != 2 || got[0] != "THIN1" || got[1] != "THIN2" {
+		t.Errorf("Insufficient-data symbols = %v, want [THIN1 THIN2]", got)
+	}
+}
+
+func TestSymbolScannerScan_BarsFetchFailureCountsAsFetchError(t *testing.T) {
+	s := symbolScanner{
+		screen: func(symbol string) (*StockScore, error) {
+			return &StockScore{Symbol: symbol, Score: 9, Signals: []string{"signal"}}, nil
+		},
+		fetchBars: func(symbol string) ([]types.Bar, error) {
+			return nil, errors.New("timeout")
+		},
+	}
+
+	summary := NewSkipSummary(false)
+	candidates, _ := s.scan([]string{"AAPL", "MSFT"}, 5, nil, summary)
+
+	if len(candidates) != 0 {
+		t.Errorf("Expected no candidates, got %d", len(candidates))
+	}
+	if summary.Reasons[SkipReasonFetchError] != 2 {
+		t.Errorf("Fetch errors = %d, want 2", summary.Reasons[SkipReasonFetchError])
+	}
+	if summary.Symbols != nil {
+		t.Errorf("Expected symbol lists to be omitted when logging is off, got %v", summary.Symbols)
+	}
+}
+
+func TestSymbolScannerScoreRanked_RanksRequestedSymbols(t *testing.T) {
+	scores := map[string]float64{"AAPL": 6.5, "MSFT": 8.2, "NVDA": 7.1}
+	var screened []string
+	s := symbolScanner{
+		screen: func(symbol string) (*StockScore, error) {
+			screened = append(screened, symbol)
+			return &StockScore{Symbol: symbol, Score: scores[symbol], Signals: []string{"signal"}}, nil
+		},
+		fetchBars: func(symbol string) ([]types.Bar, error) {
+			return []types.Bar{{Close: 10}}, nil
+		},
+	}
+
+	summary := NewSkipSummary(true)
+	ranked := s.scoreRanked([]string{"AAPL", "MSFT", "NVDA"}, 0, nil, summary)
+
+	if len(screened) != 3 {
+		t.Errorf("Screened %v, want exactly the three requested symbols", screened)
+	}
+	want := []string{"MSFT", "NVDA", "AAPL"}
+	if len(ranked) != len(want) {
+		t.Fatalf("Ranked %d candidates, want %d", len(ranked), len(want))
+	}
+	for i, symbol := range want {
+		if ranked[i].Symbol != symbol || ranked[i].Score != scores[symbol] {
+			t.Errorf("Rank %d = %s (%.1f), want %s (%.1f)", i+1, ranked[i].Symbol, ranked[i].Score, symbol, scores[symbol])
+		}
+	}
+
+	above := s.scoreRanked([]string{"AAPL", "MSFT", "NVDA"}, 7, nil, NewSkipSummary(true))
+	if len(above) != 2 || above[0].Symbol != "MSFT" || above[1].Symbol != "NVDA" {
+		t.Errorf("Candidates at min score 7 = %+v, want MSFT then NVDA", above)
+	}
+}
@@ -0,0 +1,106 @@
+package scanner
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// subset of queries used to sync scan output into the watchlist (*database.Queries satisfies it)
+type WatchlistStore interface {
+	AddScanCandidateToWatchlist(ctx context.Context, arg database.AddScanCandidateToWatchlistParams) (int32, error)
+	GetWatchlistByProfile(ctx context.Context, profile sql.NullString) ([]database.GetWatchlistByProfileRow, error)
+	UpdateWatchlistScore(ctx context.Context, arg database.UpdateWatchlistScoreParams) error
+	RemoveFromWatchlist(ctx context.Context, symbol string) error
+}
+
+type WatchlistSyncOptions struct {
+	ProfileName string
+	AssetType   string
+	Threshold   float64
+	Insert      bool // add qualifying candidates that are not tracked yet
+	Prune       bool // remove entries tagged with this profile that scored below threshold
+}
+
+type WatchlistSyncResult struct {
+	Added      []string
+	Updated    []string
+	Duplicates []string
+	Pruned     []string
+}
+
+// writes scored candidates into the watchlist, skipping duplicates and optionally pruning weak entries
+func SyncCandidatesToWatchlist(ctx context.Context, store WatchlistStore, scored []types.Candidate, opts WatchlistSyncOptions) (*WatchlistSyncResult, error) {
+	result := &WatchlistSyncResult{}
+	if store == nil {
+		return result, fmt.Errorf("watchlist store is nil")
+	}
+
+	profileTag := sql.NullString{String: opts.ProfileName, Valid: opts.ProfileName != ""}
+	assetType := opts.AssetType
+	if assetType == "" {
+		assetType = "stock"
+	}
+
+	existing, err := store.GetWatchlistByProfile(ctx, profileTag)
+	if err != nil {
+		return result, fmt.Errorf("failed to load watchlist for profile %s: %w", opts.ProfileName, err)
+	}
+	tagged := make(map[string]bool, len(existing))
+	for _, item := range existing {
+		tagged[item.Symbol] = true
+	}
+
+	for _, candidate := range scored {
+		if candidate.Score < opts.Threshold {
+			if opts.Prune && tagged[candidate.Symbol] {
+				if err := store.RemoveFromWatchlist(ctx, candidate.Symbol); err != nil {
+					return result, fmt.Errorf("failed to prune %s: %w", candidate.Symbol, err)
+				}
+				result.Pruned = append(result.Pruned, candidate.Symbol)
+			}
+			continue
+		}
+
+		// already tracked by this profile so just refresh the score
+		if tagged[candidate.Symbol] {
+			err := store.UpdateWatchlistScore(ctx, database.UpdateWatchlistScoreParams{
+				Score:  float32(candidate.Score),
+				Symbol: candidate.Symbol,
+			})
+			if err != nil {
+				return result, fmt.Errorf("failed to update %s: %w", candidate.Symbol, err)
+			}
+			result.Updated = append(result.Updated, candidate.Symbol)
+			continue
+		}
+
+		if !opts.Insert {
+			continue
+		}
+
+		reason := fmt.Sprintf("Scan profile %s: %s", opts.ProfileName, candidate.Analysis)
+		_, err := store.AddScanCandidateToWatchlist(ctx, database.AddScanCandidateToWatchlistParams{
+			Symbol:    candidate.Symbol,
+			AssetType: assetType,
+			Score:     float32(candidate.Score),
+			Reason:    sql.NullString{String: reason, Valid: true},
+			Profile:   profileTag,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// ON CONFLICT DO NOTHING returns no row when the symbol is already on the watchlist
+			result.Duplicates = append(result.Duplicates, candidate.Symbol)
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to add %s to watchlist: %w", candidate.Symbol, err)
+		}
+		result.Added = append(result.Added, candidate.Symbol)
+	}
+
+	return result, nil
+}
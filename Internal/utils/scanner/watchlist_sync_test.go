@@ -0,0 +1,142 @@
+package scanner
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+type fakeWatchlistEntry struct {
+	score   float32
+	profile string
+}
+
+type fakeWatchlistStore struct {
+	entries map[string]*fakeWatchlistEntry
+}
+
+func newFakeWatchlistStore() *fakeWatchlistStore {
+	return &fakeWatchlistStore{entries: make(map[string]*fakeWatchlistEntry)}
+}
+
+func (f *fakeWatchlistStore) AddScanCandidateToWatchlist(ctx context.Context, arg database.AddScanCandidateToWatchlistParams) (int32, error) {
+	if _, exists := f.entries[arg.Symbol]; exists {
+		return 0, sql.ErrNoRows
+	}
+	f.entries[arg.Symbol] = &fakeWatchlistEntry{score: arg.Score, profile: arg.Profile.String}
+	return int32(len(f.entries)), nil
+}
+
+func (f *fakeWatchlistStore) GetWatchlistByProfile(ctx context.Context, profile sql.NullString) ([]database.GetWatchlistByProfileRow, error) {
+	var rows []database.GetWatchlistByProfileRow
+	for symbol, entry := range f.entries {
+		if entry.profile == profile.String {
+			rows = append(rows, database.GetWatchlistByProfileRow{Symbol: symbol, Score: entry.score})
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeWatchlistStore) UpdateWatchlistScore(ctx context.Context, arg database.UpdateWatchlistScoreParams) error {
+	if entry, exists := f.entries[arg.Symbol]; exists {
+		entry.score = arg.Score
+	}
+	return nil
+}
+
+func (f *fakeWatchlistStore) RemoveFromWatchlist(ctx context.Context, symbol string) error {
+	delete(f.entries, symbol)
+	return nil
+}
+
+func TestSyncCandidatesToWatchlist_InsertNew(t *testing.T) {
+	store := newFakeWatchlistStore()
+	scored := []types.Candidate{
+		{Symbol: "AAPL", Score: 7.5},
+		{Symbol: "MSFT", Score: 2.0},
+	}
+
+	result, err := SyncCandidatesToWatchlist(context.Background(), store, scored, WatchlistSyncOptions{
+		ProfileName: "balanced",
+		Threshold:   4.0,
+		Insert:      true,
+	})
+	if err != nil {
+		t.Fatalf("SyncCandidatesToWatchlist() error = %v", err)
+	}
+
+	if len(result.Added) != 1 || result.Added[0] != "AAPL" {
+		t.Errorf("Added = %v, want [AAPL]", result.Added)
+	}
+	if entry, ok := store.entries["AAPL"]; !ok || entry.profile != "balanced" {
+		t.Errorf("AAPL should be stored with profile tag 'balanced'")
+	}
+	if _, ok := store.entries["MSFT"]; ok {
+		t.Errorf("MSFT below threshold should not be added")
+	}
+}
+
+func TestSyncCandidatesToWatchlist_SkipDuplicate(t *testing.T) {
+	store := newFakeWatchlistStore()
+	// added manually, so not tagged with the scan profile
+	store.entries["TSLA"] = &fakeWatchlistEntry{score: 5.0, profile: ""}
+
+	result, err := SyncCandidatesToWatchlist(context.Background(), store, []types.Candidate{
+		{Symbol: "TSLA", Score: 8.0},
+	}, WatchlistSyncOptions{
+		ProfileName: "aggressive",
+		Threshold:   1.0,
+		Insert:      true,
+	})
+	if err != nil {
+		t.Fatalf("SyncCandidatesToWatchlist() error = %v", err)
+	}
+
+	if len(result.Added) != 0 {
+		t.Errorf("Expected no inserts for duplicate symbol, got %v", result.Added)
+	}
+	if len(result.Duplicates) != 1 || result.Duplicates[0] != "TSLA" {
+		t.Errorf("Duplicates = %v, want [TSLA]", result.Duplicates)
+	}
+	if store.entries["TSLA"].profile != "" {
+		t.Errorf("Existing entry should keep its original tag")
+	}
+}
+
+func TestSyncCandidatesToWatchlist_PruneBelowThreshold(t *testing.T) {
+	store := newFakeWatchlistStore()
+	store.entries["AMD"] = &fakeWatchlistEntry{score: 6.0, profile: "balanced"}
+	store.entries["NVDA"] = &fakeWatchlistEntry{score: 6.0, profile: "balanced"}
+	store.entries["INTC"] = &fakeWatchlistEntry{score: 6.0, profile: ""}
+
+	scored := []types.Candidate{
+		{Symbol: "AMD", Score: 2.5},
+		{Symbol: "NVDA", Score: 7.0},
+		{Symbol: "INTC", Score: 1.0},
+	}
+
+	result, err := SyncCandidatesToWatchlist(context.Background(), store, scored, WatchlistSyncOptions{
+		ProfileName: "balanced",
+		Threshold:   4.0,
+		Prune:       true,
+	})
+	if err != nil {
+		t.Fatalf("SyncCandidatesToWatchlist() error = %v", err)
+	}
+
+	if len(result.Pruned) != 1 || result.Pruned[0] != "AMD" {
+		t.Errorf("Pruned = %v, want [AMD]", result.Pruned)
+	}
+	if _, ok := store.entries["AMD"]; ok {
+		t.Errorf("AMD should have been pruned")
+	}
+	if _, ok := store.entries["INTC"]; !ok {
+		t.Errorf("INTC is not tagged with the profile and should not be pruned")
+	}
+	if store.entries["NVDA"].score != 7.0 {
+		t.Errorf("NVDA score should be refreshed to 7.0, got %.1f", store.entries["NVDA"].score)
+	}
+}
@@ -0,0 +1,95 @@
+package utils
+
+import "strings"
+
+const (
+	AssetTypeStock  = "stock"
+	AssetTypeCrypto = "crypto"
+)
+
+// quote currencies recognised when splitting a compact crypto symbol, longest first so USDT wins over USD
+var CryptoQuoteCurrencies = []string{"USDT", "USDC", "USD", "BTC"}
+
+// bases treated as crypto when written without a separator (BTCUSD, ethusdt); features.crypto_symbols replaces it
+var CryptoBases = []string{"BTC", "ETH", "SOL", "DOGE", "LTC", "AVAX", "LINK", "UNI", "AAVE", "BCH", "DOT", "XRP", "SHIB"}
+
+// separators users type between base and quote in a crypto pair
+const cryptoPairSeparators = "/-_ "
+
+// treats explicit crypto asset types and anything written as a pair as crypto
+func IsCryptoSymbol(symbol, assetType string) bool {
+	if strings.EqualFold(assetType, AssetTypeCrypto) {
+		return true
+	}
+	return strings.ContainsAny(strings.TrimSpace(symbol), "/")
+}
+
+// canonical storage/lookup key: upper case, and crypto pairs collapsed to BASEQUOTE (BTC/USD, btc-usd -> BTCUSD)
+func NormalizeSymbol(symbol, assetType string) string {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if !IsCryptoSymbol(symbol, assetType) {
+		return symbol
+	}
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(cryptoPairSeparators, r) {
+			return -1
+		}
+		return r
+	}, symbol)
+}
+
+// human-friendly form of a canonical symbol, BASE/QUOTE for crypto and unchanged for stocks
+func DisplaySymbol(symbol, assetType string) string {
+	canonical := NormalizeSymbol(symbol, assetType)
+	if !IsCryptoSymbol(symbol, assetType) {
+		return canonical
+	}
+	for _, quote := range CryptoQuoteCurrencies {
+		if len(canonical) > len(quote) && strings.HasSuffix(canonical, quote) {
+			return canonical[:len(canonical)-len(quote)] + "/" + quote
+		}
+	}
+	return canonical
+}
+
+// infers the asset type of a user-entered symbol when the caller has none
+func DetectAssetType(symbol, assetType string) string {
+	if assetType != "" {
+		return strings.ToLower(assetType)
+	}
+	if IsCryptoSymbol(symbol, "") || isKnownCryptoPair(symbol) {
+		return AssetTypeCrypto
+	}
+	return AssetTypeStock
+}
+
+// swaps in the configured crypto bases; an empty list keeps the defaults
+func SetCryptoBases(bases []string) {
+	if len(bases) == 0 {
+		return
+	}
+	normalized := make([]string, 0, len(bases))
+	for _, base := range bases {
+		if base = strings.ToUpper(strings.TrimSpace(base)); base != "" {
+			normalized = append(normalized, base)
+		}
+	}
+	CryptoBases = normalized
+}
+
+// a compact BASEQUOTE symbol whose base is a known crypto and whose quote is a recognised quote currency
+func isKnownCryptoPair(symbol string) bool {
+	compact := NormalizeSymbol(symbol, AssetTypeCrypto)
+	for _, quote := range CryptoQuoteCurrencies {
+		base, ok := strings.CutSuffix(compact, quote)
+		if !ok || base == "" {
+			continue
+		}
+		for _, known := range CryptoBases {
+			if base == known {
+				return true
+			}
+		}
+	}
+	return false
+}
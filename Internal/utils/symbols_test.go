@@ -0,0 +1,98 @@
+package utils
+
+import "testing"
+
+func TestNormalizeSymbol_CryptoVariantsShareOneKey(t *testing.T) {
+	variants := []struct {
+		symbol    string
+		assetType string
+	}{
+		{"BTC/USD", ""},
+		{"btc/usd", "crypto"},
+		{"BTC-USD", "crypto"},
+		{" btc_usd ", "crypto"},
+		{"BTCUSD", "crypto"},
+		{"btcusd", "CRYPTO"},
+	}
+
+	for _, v := range variants {
+		if got := NormalizeSymbol(v.symbol, v.assetType); got != "BTCUSD" {
+			t.Errorf("NormalizeSymbol(%q, %q) = %q, want BTCUSD", v.symbol, v.assetType, got)
+		}
+	}
+}
+
+func TestNormalizeSymbol_StocksOnlyUppercased(t *testing.T) {
+	if got := NormalizeSymbol(" aapl ", "stock"); got != "AAPL" {
+		t.Errorf("NormalizeSymbol(aapl) = %q, want AAPL", got)
+	}
+	if got := NormalizeSymbol("brk-b", "stock"); got != "BRK-B" {
+		t.Errorf("NormalizeSymbol(brk-b) = %q, want BRK-B", got)
+	}
+}
+
+func TestDisplaySymbol_RoundTripsCryptoPairs(t *testing.T) {
+	cases := map[string]string{
+		"BTCUSD":   "BTC/USD",
+		"eth-usdt": "ETH/USDT",
+		"SOLUSDC":  "SOL/USDC",
+		"ETHBTC":   "ETH/BTC",
+		"USDTUSD":  "USDT/USD",
+	}
+	for input, want := range cases {
+		got := DisplaySymbol(input, "crypto")
+		if got != want {
+			t.Errorf("DisplaySymbol(%q) = %q, want %q", input, got, want)
+		}
+		if back := NormalizeSymbol(got, "crypto"); back != NormalizeSymbol(input, "crypto") {
+			t.Errorf("round trip of %q gave %q", input, back)
+		}
+	}
+
+	if got := DisplaySymbol("aapl", "stock"); got != "AAPL" {
+		t.Errorf("DisplaySymbol(aapl) = %q, want AAPL", got)
+	}
+}
+
+func TestDetectAssetType(t *testing.T) {
+	if got := DetectAssetType("BTC/USD", ""); got != AssetTypeCrypto {
+		t.Errorf("DetectAssetType(BTC/USD) = %q, want crypto", got)
+	}
+	if got := DetectAssetType("AAPL", ""); got != AssetTypeStock {
+		t.Errorf("DetectAssetType(AAPL) = %q, want stock", got)
+	}
+	if got := DetectAssetType("BTCUSD", "Crypto"); got != AssetTypeCrypto {
+		t.Errorf("DetectAssetType(BTCUSD, Crypto) = %q, want crypto", got)
+	}
+}
+
+func TestDetectAssetType_KnownCryptoBasesWithoutSlash(t *testing.T) {
+	for _, symbol := range []string{"BTCUSD", "ethusdt", "SOLUSDC"} {
+		if got := DetectAssetType(symbol, ""); got != AssetTypeCrypto {
+			t.Errorf("DetectAssetType(%q) = %q, want crypto", symbol, got)
+		}
+	}
+	// a bare base or an unknown base stays a stock ticker
+	for _, symbol := range []string{"BTC", "MSFT", "ABCUSD"} {
+		if got := DetectAssetType(symbol, ""); got != AssetTypeStock {
+			t.Errorf("DetectAssetType(%q) = %q, want stock", symbol, got)
+		}
+	}
+}
+
+func TestSetCryptoBases_ReplacesWhitelist(t *testing.T) {
+	defer func(prev []string) { CryptoBases = prev }(CryptoBases)
+
+	SetCryptoBases([]string{" pepe ", "btc"})
+	if got := DetectAssetType("PEPEUSD", ""); got != AssetTypeCrypto {
+		t.Errorf("DetectAssetType(PEPEUSD) = %q, want crypto after configuring it", got)
+	}
+	if got := DetectAssetType("ETHUSD", ""); got != AssetTypeStock {
+		t.Errorf("DetectAssetType(ETHUSD) = %q, want stock once ETH is dropped", got)
+	}
+
+	SetCryptoBases(nil)
+	if len(CryptoBases) != 2 {
+		t.Errorf("empty list changed the whitelist to %v", CryptoBases)
+	}
+}
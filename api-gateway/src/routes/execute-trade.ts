@@ -30,7 +30,7 @@ router.post('/execute-trade', async (req: Request, res: Response) => {
       symbol: symbol.toUpperCase(),
       side,
       quantity,
-    });
+    }, req.headers.authorization);
 
     res.json(data);
   } catch (error: any) {
@@ -44,7 +44,7 @@ router.delete('/:symbol', async (req: Request, res: Response, next) => {
     }
 
     logger.info('Closing position', { symbol });
-    const data = await apiClient.delete(`/api/positions/${symbol}`);
+    const data = await apiClient.delete(`/api/positions/${symbol}`, undefined, req.headers.authorization);
     logger.info('Position closed successfully', { symbol });
     res.json(data);
   } catch (error) {
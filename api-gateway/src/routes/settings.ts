@@ -65,7 +65,7 @@ router.post('/', async (req: Request, res: Response, next: NextFunction) => {
     }
 
     logger.info('Updating settings', { payload });
-    const data = await apiClient.post('/api/settings', payload);
+    const data = await apiClient.post('/api/settings', payload, req.headers.authorization);
     logger.info('Settings updated successfully');
     res.json(data);
   } catch (error) {
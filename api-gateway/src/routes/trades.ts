@@ -5,10 +5,17 @@ import logger from '../utils/logger';
 
 const router = Router();
 
-// GET /api/trades - Get all trades
+// GET /api/trades - Get trades, passing through the symbol/status filters and paging params
 router.get('/', async (req: Request, res: Response, next) => {
   try {
-    const data = await apiClient.get('/api/trades');
+    const queryParams = new URLSearchParams();
+    for (const key of ['symbol', 'status', 'limit', 'page_size', 'page', 'offset', 'cursor']) {
+      if (typeof req.query[key] === 'string') {
+        queryParams.append(key, req.query[key] as string);
+      }
+    }
+    const query = queryParams.toString();
+    const data = await apiClient.get(query ? `/api/trades?${query}` : '/api/trades');
     res.json(data);
   } catch (error) {
     next(error);
@@ -29,7 +36,7 @@ router.get('/statistics', async (req: Request, res: Response, next) => {
 router.post('/', async (req: Request, res: Response, next) => {
   try {
     logger.info('Executing trade', { symbol: req.body.symbol, quantity: req.body.quantity });
-    const data = await apiClient.post('/api/trades', req.body);
+    const data = await apiClient.post('/api/trades', req.body, req.headers.authorization);
     logger.info('Trade executed successfully', { orderId: (data as any).id });
     res.json(data);
   } catch (error) {
@@ -41,7 +48,7 @@ router.post('/', async (req: Request, res: Response, next) => {
 router.post('/sell-all', async (req: Request, res: Response, next) => {
   try {
     logger.info('Selling all trades');
-    const data = await apiClient.post('/api/trades/sell-all', req.body);
+    const data = await apiClient.post('/api/trades/sell-all', req.body, req.headers.authorization);
     logger.info('All trades sold successfully', { count: (data as any).count });
     res.json(data);
   } catch (error) {
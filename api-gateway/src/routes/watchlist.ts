@@ -29,7 +29,7 @@ router.get('/', async (req: Request, res: Response) => {
 router.put('/refresh-scores', async (req: Request, res: Response) => {
   try {
     logger.info('Refreshing all watchlist scores');
-    const response = await apiClient.put(`/api/watchlist/refresh-scores`);
+    const response = await apiClient.put(`/api/watchlist/refresh-scores`, undefined, req.headers.authorization);
     apiClient.invalidateCache('/api/watchlist');
     logger.info('Watchlist scores refreshed', { response });
     res.json(response);
@@ -65,7 +65,7 @@ router.post('/', async (req: Request, res: Response) => {
       symbol, 
       score: score || 50,
       reason: reason || '' 
-    });
+    }, req.headers.authorization);
     logger.info('Symbol added to watchlist', { symbol });
     res.json(data);
   } catch (error: any) {
@@ -93,7 +93,7 @@ router.delete('/', async (req: Request, res: Response) => {
     }
     logger.info('Removing from watchlist', { symbol });
     // Call Go backend DELETE endpoint
-    const response = await apiClient.delete(`/api/watchlist?symbol=${encodeURIComponent(symbol)}`);
+    const response = await apiClient.delete(`/api/watchlist?symbol=${encodeURIComponent(symbol)}`, undefined, req.headers.authorization);
     logger.info('Symbol removed from watchlist', { symbol, response });
     res.json(response);
   } catch (error: any) {
@@ -125,7 +125,8 @@ router.get('/analyze', async (req: Request, res: Response) => {
       return;
     }
     logger.info('Analyzing stock', { symbol });
-    const data = await apiClient.get(`/api/watchlist/analyze?symbol=${symbol}`);
+    const skipMTF = req.query.skip_mtf === 'true' ? '&skip_mtf=true' : '';
+    const data = await apiClient.get(`/api/watchlist/analyze?symbol=${symbol}${skipMTF}`);
     logger.info('Stock analyzed successfully', { symbol });
     res.json(data);
   } catch (error: any) {
@@ -105,9 +105,12 @@ class APIClient {
     }
   }
 
-  async post<T>(url: string, data?: any): Promise<T> {
+  /**
+   * POST, forwarding the caller's Authorization header when the Go route requires a token
+   */
+  async post<T>(url: string, data?: any, authorization?: string): Promise<T> {
     try {
-      const response = await this.client.post<T>(url, data);
+      const response = await this.client.post<T>(url, data, this.authConfig(authorization));
       this.invalidateCache(url);
       return response.data;
     } catch (error) {
@@ -116,9 +119,9 @@ class APIClient {
     }
   }
 
-  async put<T>(url: string, data?: any): Promise<T> {
+  async put<T>(url: string, data?: any, authorization?: string): Promise<T> {
     try {
-      const response = await this.client.put<T>(url, data);
+      const response = await this.client.put<T>(url, data, this.authConfig(authorization));
       this.invalidateCache(url);
       return response.data;
     } catch (error) {
@@ -127,9 +130,9 @@ class APIClient {
     }
   }
 
-  async delete<T>(url: string, data?: any): Promise<T> {
+  async delete<T>(url: string, data?: any, authorization?: string): Promise<T> {
     try {
-      const response = await this.client.delete<T>(url, { data });
+      const response = await this.client.delete<T>(url, { data, ...this.authConfig(authorization) });
       // Invalidate both the specific URL and the base path
       this.invalidateCache(url);
       const baseUrl = url.split('?')[0]; // Remove query params
@@ -143,6 +146,10 @@ class APIClient {
     }
   }
 
+  private authConfig(authorization?: string) {
+    return authorization ? { headers: { Authorization: authorization } } : {};
+  }
+
   invalidateCache(url?: string) {
     if (url) {
       this.cache.delete(url);
@@ -0,0 +1,191 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/strategy/alerts"
+)
+
+type alertRuleRequest struct {
+	Name       string             `json:"name"`
+	Symbol     string             `json:"symbol"`
+	Conditions []alerts.Condition `json:"conditions"`
+	Enabled    *bool              `json:"enabled"`
+}
+
+type alertRuleResponse struct {
+	alerts.Rule
+	CreatedAt       string `json:"created_at,omitempty"`
+	LastTriggeredAt string `json:"last_triggered_at,omitempty"`
+}
+
+// decodes and validates a rule body, returning the rule and its encoded conditions
+func decodeAlertRuleRequest(r *http.Request) (alerts.Rule, string, error) {
+	var req alertRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return alerts.Rule{}, "", errors.New("Invalid JSON body")
+	}
+
+	rule := alerts.Rule{
+		Name:       strings.TrimSpace(req.Name),
+		Symbol:     strings.ToUpper(strings.TrimSpace(req.Symbol)),
+		Conditions: req.Conditions,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+	if err := rule.Validate(); err != nil {
+		return alerts.Rule{}, "", err
+	}
+
+	conditions, err := alerts.EncodeConditions(rule.Conditions)
+	if err != nil {
+		return alerts.Rule{}, "", err
+	}
+	return rule, conditions, nil
+}
+
+func toAlertRuleResponse(record database.AlertRule) (alertRuleResponse, error) {
+	rule, err := alerts.RuleFromRecord(record)
+	if err != nil {
+		return alertRuleResponse{}, err
+	}
+	resp := alertRuleResponse{Rule: rule}
+	if record.CreatedAt.Valid {
+		resp.CreatedAt = record.CreatedAt.Time.Format(time.RFC3339)
+	}
+	if record.LastTriggeredAt.Valid {
+		resp.LastTriggeredAt = record.LastTriggeredAt.Time.Format(time.RFC3339)
+	}
+	return resp, nil
+}
+
+func alertRuleID(r *http.Request) (int32, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 32)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid alert rule id")
+	}
+	return int32(id), nil
+}
+
+func (api *API) HandleGetAlertRules(w http.ResponseWriter, r *http.Request) {
+	records, err := api.Queries.GetAlertRules(r.Context())
+	if err != nil {
+		log.Printf("Error fetching alert rules: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch alert rules")
+		return
+	}
+
+	rules := make([]alertRuleResponse, 0, len(records))
+	for _, record := range records {
+		resp, err := toAlertRuleResponse(record)
+		if err != nil {
+			log.Printf("Skipping alert rule with unreadable conditions: %v", err)
+			continue
+		}
+		rules = append(rules, resp)
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"rules":            rules,
+		"count":            len(rules),
+		"supported_fields": alerts.SupportedFields,
+	})
+}
+
+func (api *API) HandleCreateAlertRule(w http.ResponseWriter, r *http.Request) {
+	rule, conditions, err := decodeAlertRuleRequest(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	record, err := api.Queries.CreateAlertRule(r.Context(), database.CreateAlertRuleParams{
+		Name:       rule.Name,
+		Symbol:     sql.NullString{String: rule.Symbol, Valid: rule.Symbol != ""},
+		Conditions: conditions,
+		Enabled:    rule.Enabled,
+	})
+	if err != nil {
+		log.Printf("Error creating alert rule: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to create alert rule")
+		return
+	}
+
+	resp, err := toAlertRuleResponse(record)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to read created alert rule")
+		return
+	}
+	WriteJSON(w, http.StatusCreated, resp)
+}
+
+func (api *API) HandleUpdateAlertRule(w http.ResponseWriter, r *http.Request) {
+	id, err := alertRuleID(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule, conditions, err := decodeAlertRuleRequest(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	record, err := api.Queries.UpdateAlertRule(r.Context(), database.UpdateAlertRuleParams{
+		ID:         id,
+		Name:       rule.Name,
+		Symbol:     sql.NullString{String: rule.Symbol, Valid: rule.Symbol != ""},
+		Conditions: conditions,
+		Enabled:    rule.Enabled,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		WriteError(w, http.StatusNotFound, fmt.Sprintf("Alert rule %d not found", id))
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating alert rule %d: %v", id, err)
+		WriteError(w, http.StatusInternalServerError, "Failed to update alert rule")
+		return
+	}
+
+	resp, err := toAlertRuleResponse(record)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to read updated alert rule")
+		return
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (api *API) HandleDeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	id, err := alertRuleID(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := api.Queries.GetAlertRule(r.Context(), id); errors.Is(err, sql.ErrNoRows) {
+		WriteError(w, http.StatusNotFound, fmt.Sprintf("Alert rule %d not found", id))
+		return
+	}
+
+	if err := api.Queries.DeleteAlertRule(r.Context(), id); err != nil {
+		log.Printf("Error deleting alert rule %d: %v", id, err)
+		WriteError(w, http.StatusInternalServerError, "Failed to delete alert rule")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"id":      id,
+		"message": "Alert rule deleted",
+	})
+}
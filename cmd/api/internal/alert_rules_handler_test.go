@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleCreateAlertRule_RejectsInvalidRules(t *testing.T) {
+	api := &API{}
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"malformed json", `{"name":`},
+		{"unsupported field", `{"name":"macd cross","conditions":[{"field":"macd","operator":">","value":0}]}`},
+		{"unsupported operator", `{"name":"rsi","conditions":[{"field":"rsi","operator":"!=","value":30}]}`},
+		{"no conditions", `{"name":"empty"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/alert-rules", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			api.HandleCreateAlertRule(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestHandleUpdateAlertRule_InvalidID(t *testing.T) {
+	api := &API{}
+	req := httptest.NewRequest(http.MethodPut, "/api/alert-rules/abc", strings.NewReader(`{}`))
+	req.SetPathValue("id", "abc")
+	rec := httptest.NewRecorder()
+
+	api.HandleUpdateAlertRule(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
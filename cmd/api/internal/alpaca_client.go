@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+// DefaultAlpacaTimeout bounds a single Alpaca call when API.AlpacaTimeout is unset
+const DefaultAlpacaTimeout = 10 * time.Second
+
+// ErrAlpacaTimeout is returned when an Alpaca call outlives its request deadline
+var ErrAlpacaTimeout = errors.New("alpaca request timed out")
+
+// Alpaca trading methods used by the API handlers (*alpaca.Client satisfies it)
+type TradingClient interface {
+	GetAccount() (*alpaca.Account, error)
+	GetPositions() ([]alpaca.Position, error)
+	GetPosition(symbol string) (*alpaca.Position, error)
+	GetOrders(req alpaca.GetOrdersRequest) ([]alpaca.Order, error)
+	PlaceOrder(req alpaca.PlaceOrderRequest) (*alpaca.Order, error)
+	GetOrderByClientOrderID(clientOrderID string) (*alpaca.Order, error)
+	ClosePosition(symbol string, req alpaca.ClosePositionRequest) (*alpaca.Order, error)
+	GetAsset(symbol string) (*alpaca.Asset, error)
+}
+
+// the v3 SDK takes no context, so each call races the request context plus a deadline
+func callWithTimeout[T any](ctx context.Context, timeout time.Duration, fn func() (T, error)) (T, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero T
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return zero, ErrAlpacaTimeout
+		}
+		return zero, ctx.Err()
+	}
+}
+
+// order submission retry from order_submit; an Alpaca call that timed out may still have reached the broker,
+// so those are retried too (after checking for the order)
+func (api *API) submitRetry() strategy.SubmitRetry {
+	var cfg config.OrderSubmitConfig
+	if api.Config != nil {
+		cfg = api.Config.OrderSubmit
+	}
+	retry := strategy.SubmitRetryFromConfig(cfg)
+	retry.IsTransient = func(err error) bool {
+		return errors.Is(err, ErrAlpacaTimeout) || strategy.IsTransientError(err)
+	}
+	return retry
+}
+
+// wraps a TradingClient so every call honours the request context and timeout
+type contextTradingClient struct {
+	ctx     context.Context
+	timeout time.Duration
+	client  TradingClient
+}
+
+func (api *API) alpacaClient(r *http.Request) TradingClient {
+	timeout := api.AlpacaTimeout
+	if timeout <= 0 {
+		timeout = DefaultAlpacaTimeout
+	}
+	return contextTradingClient{ctx: r.Context(), timeout: timeout, client: api.AlpacaClient}
+}
+
+func (c contextTradingClient) GetAccount() (*alpaca.Account, error) {
+	return callWithTimeout(c.ctx, c.timeout, c.client.GetAccount)
+}
+
+func (c contextTradingClient) GetPositions() ([]alpaca.Position, error) {
+	return callWithTimeout(c.ctx, c.timeout, c.client.GetPositions)
+}
+
+func (c contextTradingClient) GetPosition(symbol string) (*alpaca.Position, error) {
+	return callWithTimeout(c.ctx, c.timeout, func() (*alpaca.Position, error) {
+		return c.client.GetPosition(symbol)
+	})
+}
+
+func (c contextTradingClient) GetOrders(req alpaca.GetOrdersRequest) ([]alpaca.Order, error) {
+	return callWithTimeout(c.ctx, c.timeout, func() ([]alpaca.Order, error) {
+		return c.client.GetOrders(req)
+	})
+}
+
+func (c contextTradingClient) PlaceOrder(req alpaca.PlaceOrderRequest) (*alpaca.Order, error) {
+	return callWithTimeout(c.ctx, c.timeout, func() (*alpaca.Order, error) {
+		return c.client.PlaceOrder(req)
+	})
+}
+
+func (c contextTradingClient) GetOrderByClientOrderID(clientOrderID string) (*alpaca.Order, error) {
+	return callWithTimeout(c.ctx, c.timeout, func() (*alpaca.Order, error) {
+		return c.client.GetOrderByClientOrderID(clientOrderID)
+	})
+}
+
+func (c contextTradingClient) ClosePosition(symbol string, req alpaca.ClosePositionRequest) (*alpaca.Order, error) {
+	return callWithTimeout(c.ctx, c.timeout, func() (*alpaca.Order, error) {
+		return c.client.ClosePosition(symbol, req)
+	})
+}
+
+func (c contextTradingClient) GetAsset(symbol string) (*alpaca.Asset, error) {
+	return callWithTimeout(c.ctx, c.timeout, func() (*alpaca.Asset, error) {
+		return c.client.GetAsset(symbol)
+	})
+}
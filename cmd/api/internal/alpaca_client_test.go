@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+)
+
+// blocks every call for delay to simulate an unresponsive Alpaca API
+type slowTradingClient struct {
+	delay time.Duration
+}
+
+func (s slowTradingClient) GetAccount() (*alpaca.Account, error) {
+	time.Sleep(s.delay)
+	return &alpaca.Account{}, nil
+}
+
+func (s slowTradingClient) GetPositions() ([]alpaca.Position, error) {
+	time.Sleep(s.delay)
+	return []alpaca.Position{}, nil
+}
+
+func (s slowTradingClient) GetPosition(symbol string) (*alpaca.Position, error) {
+	time.Sleep(s.delay)
+	return &alpaca.Position{Symbol: symbol}, nil
+}
+
+func (s slowTradingClient) GetOrders(req alpaca.GetOrdersRequest) ([]alpaca.Order, error) {
+	time.Sleep(s.delay)
+	return []alpaca.Order{}, nil
+}
+
+func (s slowTradingClient) PlaceOrder(req alpaca.PlaceOrderRequest) (*alpaca.Order, error) {
+	time.Sleep(s.delay)
+	return &alpaca.Order{}, nil
+}
+
+func (s slowTradingClient) GetOrderByClientOrderID(clientOrderID string) (*alpaca.Order, error) {
+	time.Sleep(s.delay)
+	return nil, nil
+}
+
+func (s slowTradingClient) ClosePosition(symbol string, req alpaca.ClosePositionRequest) (*alpaca.Order, error) {
+	time.Sleep(s.delay)
+	return &alpaca.Order{}, nil
+}
+
+func (s slowTradingClient) GetAsset(symbol string) (*alpaca.Asset, error) {
+	time.Sleep(s.delay)
+	return &alpaca.Asset{Symbol: symbol}, nil
+}
+
+func TestHandleGetTrades_AlpacaTimeoutReturns504(t *testing.T) {
+	api := &API{
+		AlpacaClient:  slowTradingClient{delay: 2 * time.Second},
+		AlpacaTimeout: 20 * time.Millisecond,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/trades", nil)
+	rec := httptest.NewRecorder()
+
+	start := time.Now()
+	api.HandleGetTrades(rec, req)
+	elapsed := time.Since(start)
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("HandleGetTrades() status = %d, want %d", rec.Code, http.StatusGatewayTimeout)
+	}
+	if elapsed > time.Second {
+		t.Errorf("HandleGetTrades() took %v, expected to fail fast on deadline", elapsed)
+	}
+}
+
+func TestHandleGetPositions_FastClientSucceeds(t *testing.T) {
+	api := &API{
+		AlpacaClient:  slowTradingClient{delay: 0},
+		AlpacaTimeout: time.Second,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/positions", nil)
+	rec := httptest.NewRecorder()
+	api.HandleGetPositions(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("HandleGetPositions() status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestHandleGetPositionBySymbol_CancelledRequestReturns503(t *testing.T) {
+	api := &API{
+		AlpacaClient:  slowTradingClient{delay: 2 * time.Second},
+		AlpacaTimeout: 5 * time.Second,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/positions/AAPL", nil)
+	req.SetPathValue("symbol", "AAPL")
+	ctx, cancel := context.WithCancel(req.Context())
+	cancel()
+	rec := httptest.NewRecorder()
+	api.HandleGetPositionBySymbol(rec, req.WithContext(ctx))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("HandleGetPositionBySymbol() status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
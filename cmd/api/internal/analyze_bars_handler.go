@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
+	"github.com/fazecat/mogulmaker/Internal/strategy/signals"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/analyzer"
+)
+
+const (
+	analyzeBarsMinLength = 15   // RSI and ATR(14) need one bar beyond the period
+	analyzeBarsMaxLength = 5000 // keeps a single request from pinning the server
+	analyzeBarsMaxBody   = 4 << 20
+)
+
+// POST /api/analyze/bars?symbol=... runs the full analysis on a client-supplied bar series
+// (oldest first, {"t","o","h","l","c","v"} per bar) instead of fetching from Alpaca
+func (api *API) HandleAnalyzeBars(w http.ResponseWriter, r *http.Request) {
+	var bars []types.Bar
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, analyzeBarsMaxBody)).Decode(&bars); err != nil {
+		WriteError(w, http.StatusBadRequest, "Body must be a JSON array of bars")
+		return
+	}
+	if err := validateBarSeries(bars); err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	symbol := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("symbol")))
+	if symbol == "" {
+		symbol = "CUSTOM"
+	}
+
+	// the analyzer takes bars latest first, the way the fetchers return them
+	response, err := analyzer.AnalyzeSymbolDetailed(symbol, reversedBars(bars))
+	if err != nil {
+		writeServiceError(w, err, http.StatusUnprocessableEntity, "Failed to analyze bars")
+		return
+	}
+	response["signal"] = barSeriesSignal(symbol, bars, response)
+
+	WriteJSON(w, http.StatusOK, response)
+}
+
+// rejects series the indicators would silently misread: too short, out of order, or with impossible prices
+func validateBarSeries(bars []types.Bar) error {
+	if len(bars) < analyzeBarsMinLength {
+		return fmt.Errorf("need at least %d bars, got %d", analyzeBarsMinLength, len(bars))
+	}
+	if len(bars) > analyzeBarsMaxLength {
+		return fmt.Errorf("at most %d bars per request, got %d", analyzeBarsMaxLength, len(bars))
+	}
+
+	var previous time.Time
+	for i, bar := range bars {
+		at, err := time.Parse(time.RFC3339, bar.Timestamp)
+		if err != nil {
+			return fmt.Errorf("bar %d: timestamp %q is not RFC3339", i, bar.Timestamp)
+		}
+		if i > 0 && !at.After(previous) {
+			return fmt.Errorf("bar %d: timestamps must be strictly increasing (oldest first)", i)
+		}
+		previous = at
+
+		if bar.Open <= 0 || bar.High <= 0 || bar.Low <= 0 || bar.Close <= 0 {
+			return fmt.Errorf("bar %d: prices must be > 0", i)
+		}
+		if bar.High < bar.Low || bar.High < bar.Open || bar.High < bar.Close || bar.Low > bar.Open || bar.Low > bar.Close {
+			return fmt.Errorf("bar %d: high/low don't bracket open/close", i)
+		}
+		if bar.Volume < 0 {
+			return fmt.Errorf("bar %d: volume must be >= 0", i)
+		}
+	}
+	return nil
+}
+
+// the combined signal the analysis page shows, from the RSI/ATR already computed for the response;
+// the signal is replayed over the trailing bars so "confirmed" reflects features.signal_confirmation_bars
+func barSeriesSignal(symbol string, bars []types.Bar, analysis map[string]interface{}) map[string]interface{} {
+	rsi, _ := analysis["rsi"].(float64)
+	atr, _ := analysis["atr"].(float64)
+
+	closes := make([]float64, len(bars))
+	atrBars := make([]indicators.ATRBar, len(bars))
+	for i, bar := range bars {
+		closes[i] = bar.Close
+		atrBars[i] = indicators.ATRBar{High: bar.High, Low: bar.Low, Close: bar.Close}
+	}
+	rsiValues, err := indicators.CalculateRSI(closes, 14)
+	if err != nil {
+		rsiValues = []float64{}
+	}
+	atrValues, _ := indicators.CalculateATR(atrBars, 14)
+
+	confirmer := signals.NewSignalConfirmer(0)
+	for back := min(confirmer.RequiredBars(), len(rsiValues), len(atrValues)) - 1; back > 0; back-- {
+		// both indicators are causal, so the value back bars ago is what that shorter series would have produced
+		end := len(bars) - back
+		pastRSI, pastATR := rsiValues[end-1], atrValues[end-1]
+		confirmer.CalculateSignal(&pastRSI, &pastATR, bars[:end], symbol, analyzer.GetLatestCandlePattern(bars[:end], 1), rsiValues[:end])
+	}
+	signal := confirmer.CalculateSignal(&rsi, &atr, bars, symbol, analyzer.GetLatestCandlePattern(bars, 1), rsiValues)
+
+	components := make([]signals.StoredComponent, 0, len(signal.Components))
+	for _, c := range signal.Components {
+		components = append(components, signals.StoredComponent{Name: c.Name, Score: c.Score, Weight: c.Weight})
+	}
+
+	return map[string]interface{}{
+		"recommendation":   signal.Recommendation,
+		"score":            signal.Score,
+		"confidence":       signal.Confidence,
+		"reasoning":        signal.Reasoning,
+		"components":       components,
+		"confirmed":        signal.Confirmed,
+		"consecutive_bars": signal.ConsecutiveBars,
+		"required_bars":    signal.RequiredBars,
+	}
+}
+
+// a copy of bars in the opposite order; the fetchers return latest first, client-supplied bars run oldest first
+func reversedBars(bars []types.Bar) []types.Bar {
+	reversed := make([]types.Bar, len(bars))
+	for i, bar := range bars {
+		reversed[len(bars)-1-i] = bar
+	}
+	return reversed
+}
@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/signals"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// n daily bars whose close moves by step each day from start
+func trendingBars(n int, start, step float64) []types.Bar {
+	bars := make([]types.Bar, n)
+	day := time.Date(2024, 1, 2, 21, 0, 0, 0, time.UTC)
+	for i := range bars {
+		closePrice := start + float64(i)*step
+		open := closePrice - step
+		bars[i] = types.Bar{
+			Timestamp: day.AddDate(0, 0, i).Format(time.RFC3339),
+			Open:      open,
+			High:      max(open, closePrice) + 0.5,
+			Low:       min(open, closePrice) - 0.5,
+			Close:     closePrice,
+			Volume:    1_000_000,
+		}
+	}
+	return bars
+}
+
+func postBars(t *testing.T, bars []types.Bar) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(bars)
+	if err != nil {
+		t.Fatalf("marshal bars: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/analyze/bars?symbol=test", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	(&API{}).HandleAnalyzeBars(w, req)
+	return w
+}
+
+func TestHandleAnalyzeBars_RisingSeries(t *testing.T) {
+	w := postBars(t, trendingBars(30, 100, 1))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Symbol       string  `json:"symbol"`
+		CurrentPrice float64 `json:"current_price"`
+		RSI          float64 `json:"rsi"`
+		RSISignal    string  `json:"rsi_signal"`
+		Trend        string  `json:"trend"`
+		Signal       struct {
+			Recommendation string  `json:"recommendation"`
+			Score          float64 `json:"score"`
+			Components     []struct {
+				Name  string  `json:"name"`
+				Score float64 `json:"score"`
+			} `json:"components"`
+		} `json:"signal"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if resp.Symbol != "TEST" || resp.CurrentPrice != 129 {
+		t.Errorf("symbol/price = %s/%.2f, want TEST/129 from the last bar", resp.Symbol, resp.CurrentPrice)
+	}
+	// no down closes in the window: RSI pins at 100
+	if resp.RSI != 100 || resp.RSISignal != "overbought" {
+		t.Errorf("RSI = %.2f (%s), want 100 (overbought)", resp.RSI, resp.RSISignal)
+	}
+	if resp.Trend != "bullish" {
+		t.Errorf("trend = %s, want bullish", resp.Trend)
+	}
+	if len(resp.Signal.Components) == 0 || resp.Signal.Components[0].Name != "RSI" || resp.Signal.Components[0].Score != -3 {
+		t.Errorf("signal components = %+v, want RSI scored -3 for an overbought reading", resp.Signal.Components)
+	}
+	if resp.Signal.Recommendation != "DISTRIBUTE" || resp.Signal.Score >= 0 {
+		t.Errorf("signal = %s (%.3f), want DISTRIBUTE with a negative score", resp.Signal.Recommendation, resp.Signal.Score)
+	}
+}
+
+func TestHandleAnalyzeBars_FallingSeriesIsOversold(t *testing.T) {
+	w := postBars(t, trendingBars(30, 130, -1))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp["rsi"] != 0.0 || resp["rsi_signal"] != "oversold" {
+		t.Errorf("RSI = %v (%v), want 0 (oversold)", resp["rsi"], resp["rsi_signal"])
+	}
+}
+
+func TestHandleAnalyzeBars_RejectsBadSeries(t *testing.T) {
+	unordered := trendingBars(30, 100, 1)
+	unordered[10], unordered[11] = unordered[11], unordered[10]
+
+	badHigh := trendingBars(30, 100, 1)
+	badHigh[5].High = badHigh[5].Low - 1
+
+	tests := []struct {
+		name string
+		bars []types.Bar
+		want string
+	}{
+		{"too short", trendingBars(10, 100, 1), "at least 15 bars"},
+		{"out of order", unordered, "strictly increasing"},
+		{"inverted range", badHigh, "high/low"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postBars(t, tt.bars)
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("got %d %s, want 400 mentioning %q", w.Code, w.Body.String(), tt.want)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/analyze/bars", strings.NewReader(`{"bars": 1}`))
+	w := httptest.NewRecorder()
+	(&API{}).HandleAnalyzeBars(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("non-array body status = %d, want 400", w.Code)
+	}
+}
+
+func TestHandleAnalyzeBars_ReportsConfirmation(t *testing.T) {
+	defer func(prev int) { signals.ConfirmationBars = prev }(signals.ConfirmationBars)
+	signals.ConfirmationBars = 3
+
+	w := postBars(t, trendingBars(60, 100, 0.5))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Signal struct {
+			Recommendation  string `json:"recommendation"`
+			Confirmed       bool   `json:"confirmed"`
+			ConsecutiveBars int    `json:"consecutive_bars"`
+			RequiredBars    int    `json:"required_bars"`
+		} `json:"signal"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Signal.RequiredBars != 3 {
+		t.Errorf("required_bars = %d, want 3", resp.Signal.RequiredBars)
+	}
+	if resp.Signal.ConsecutiveBars > 3 || resp.Signal.Confirmed != (resp.Signal.ConsecutiveBars == 3) {
+		t.Errorf("signal = %+v, want confirmed exactly when all 3 replayed bars agree", resp.Signal)
+	}
+}
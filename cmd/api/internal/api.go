@@ -1,16 +1,20 @@
 package internal
 
 import (
+	"container/list"
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
 	"net/http"
+	"net/url"
 	"os"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,35 +24,61 @@ import (
 	"github.com/fazecat/mogulmaker/Internal/handlers/monitoring"
 	"github.com/fazecat/mogulmaker/Internal/handlers/risk"
 	settingshandler "github.com/fazecat/mogulmaker/Internal/handlers/settings"
+	"github.com/fazecat/mogulmaker/Internal/strategy"
 	"github.com/fazecat/mogulmaker/Internal/strategy/detection"
 	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
 	"github.com/fazecat/mogulmaker/Internal/strategy/metrics"
 	"github.com/fazecat/mogulmaker/Internal/strategy/position"
+	"github.com/fazecat/mogulmaker/Internal/strategy/signals"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
 	"github.com/fazecat/mogulmaker/Internal/utils/analyzer"
 	"github.com/fazecat/mogulmaker/Internal/utils/config"
 	"github.com/fazecat/mogulmaker/Internal/utils/formatting"
 	"github.com/fazecat/mogulmaker/Internal/utils/scanner"
 	"github.com/fazecat/mogulmaker/Internal/utils/scoring"
+	"github.com/fazecat/mogulmaker/interactive"
 	"github.com/shopspring/decimal"
 )
 
+// starting capital for backtests when neither the request nor the account supplies one
+const defaultBacktestCapital = 100000.0
+
 type API struct {
 	PositionManager *position.PositionManager
 	RiskManager     *risk.Manager
 	Queries         *database.Queries
 	TradeMonitor    *monitoring.Monitor
-	AlpacaClient    *alpaca.Client
+	AlpacaClient    TradingClient
+	AlpacaTimeout   time.Duration // per-call deadline for Alpaca requests
 	JWTManager      *JWTManager
 	DB              *sql.DB
-	backtestCache   map[string]map[string]interface{} // backtestID -> results
-	backtestMutex   sync.RWMutex
+	OrderConfig     *strategy.OrderConfig
+	Config          *config.Config // loaded config.yaml, nil when it couldn't be read
+	ChartsEnabled   bool           // serves /api/chart, from features.chart_rendering
+
+	BacktestStore     BacktestStore                // where finished backtests are saved; serves results evicted from the cache
+	BacktestCacheSize int                          // max cached backtests before LRU eviction
+	BacktestCacheTTL  time.Duration                // max age of a cached backtest
+	backtestCache     map[string]*list.Element     // backtestID -> LRU element holding *backtestCacheEntry
+	backtestLRU       *list.List                   // most recently used at the front
+	backtestJobs      map[string]*backtestJob      // async runs that are queued, running or failed
+	backtestRunner    backtestRunner               // overrides runSymbolBacktest in tests
+	bars              barFetcher                   // overrides the Alpaca bar fetch in tests
+	scanRuns          scanner.ScanRunStore         // overrides Queries for /api/scan-runs in tests
+	watchlist         watchlistStore               // overrides Queries for the watchlist handlers in tests
+	scorer            symbolScorer                 // overrides scanner.ScoreSymbols for POST /api/scout in tests
+	earnings          strategy.EarningsCalendar    // overrides the Finnhub earnings calendar in tests
+	heatSnapshots     monitoring.HeatSnapshotStore // overrides Queries for /api/risk/heat in tests
+	slippage          slippageStore                // overrides Queries for /api/trades/slippage in tests
+	problemSymbols    problemSymbolStore           // overrides Queries for /api/problem-symbols in tests
+	backtestMutex     sync.RWMutex
 }
 
 func (api *API) HandleGetPositions(w http.ResponseWriter, r *http.Request) {
-	alpacaPositions, err := api.AlpacaClient.GetPositions()
+	alpacaPositions, err := api.alpacaClient(r).GetPositions()
 	if err != nil {
-		log.Printf("Error fetching positions from Alpaca: %v", err)
-		WriteError(w, http.StatusInternalServerError, "Failed to fetch positions")
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch positions")
 		return
 	}
 
@@ -57,7 +87,7 @@ func (api *API) HandleGetPositions(w http.ResponseWriter, r *http.Request) {
 		alpacaPositions = []alpaca.Position{}
 	}
 
-	pendingOrders, err := api.AlpacaClient.GetOrders(alpaca.GetOrdersRequest{
+	pendingOrders, err := api.alpacaClient(r).GetOrders(alpaca.GetOrdersRequest{
 		Status: "open",
 		Limit:  100,
 		Nested: true,
@@ -87,40 +117,40 @@ func (api *API) HandleGetPositions(w http.ResponseWriter, r *http.Request) {
 }
 
 func (api *API) HandleGetRiskStatus(w http.ResponseWriter, r *http.Request) {
-	if api.RiskManager == nil {
-		log.Printf("Error: RiskManager is nil")
-		WriteError(w, http.StatusInternalServerError, "Risk manager not initialized")
+	if !api.requireRiskManager(w) {
 		return
 	}
 
 	// Get Alpaca account info
-	account, err := api.AlpacaClient.GetAccount()
+	account, err := api.alpacaClient(r).GetAccount()
 	if err != nil {
-		log.Printf("Error fetching account: %v", err)
-		WriteError(w, http.StatusInternalServerError, "Failed to fetch account data")
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch account data")
 		return
 	}
 
 	log.Printf("Account fetched successfully: Portfolio Value: %v, Cash: %v", account.PortfolioValue, account.Cash)
 
 	// Get open positions from Alpaca
-	alpacaPositions, err := api.AlpacaClient.GetPositions()
+	alpacaPositions, err := api.alpacaClient(r).GetPositions()
 	if err != nil {
 		log.Printf("Error fetching positions: %v", err)
 		alpacaPositions = []alpaca.Position{}
 	}
 
 	// Calculate portfolio risk metrics
-	accountBalance := decimal.NewFromFloat(account.Cash.InexactFloat64() + account.PortfolioValue.InexactFloat64()).InexactFloat64()
+	accountBalanceDec := account.Cash.Add(account.PortfolioValue)
+	accountBalance := utils.MoneyToFloat(accountBalanceDec)
 	portfolioValue := account.PortfolioValue.InexactFloat64()
 	buyingPower := account.BuyingPower.InexactFloat64()
 	dayTradingBuyingPower := account.DaytradingBuyingPower.InexactFloat64()
 
 	// Calculate total unrealized P&L
-	totalUnrealizedPnL := 0.0
+	totalUnrealizedPnLDec := decimal.Zero
 	largestPositionValue := 0.0
 	for _, pos := range alpacaPositions {
-		totalUnrealizedPnL += pos.UnrealizedPL.InexactFloat64()
+		if pos.UnrealizedPL != nil {
+			totalUnrealizedPnLDec = totalUnrealizedPnLDec.Add(*pos.UnrealizedPL)
+		}
 
 		// Calculate position market value
 		qty, _ := pos.Qty.Float64()
@@ -130,6 +160,7 @@ func (api *API) HandleGetRiskStatus(w http.ResponseWriter, r *http.Request) {
 			largestPositionValue = positionValue
 		}
 	}
+	totalUnrealizedPnL := utils.MoneyToFloat(totalUnrealizedPnLDec)
 
 	// Calculate largest position as percentage of portfolio
 	largestPositionPercent := 0.0
@@ -147,9 +178,9 @@ func (api *API) HandleGetRiskStatus(w http.ResponseWriter, r *http.Request) {
 		accountBal = accountBalance
 	}
 
-	portfolioRisk := (totalUnrealizedPnL / accountBalance) * 100
-	if portfolioRisk < 0 {
-		portfolioRisk = -portfolioRisk
+	portfolioRisk := 0.0
+	if !accountBalanceDec.IsZero() {
+		portfolioRisk = totalUnrealizedPnLDec.Div(accountBalanceDec).Mul(decimal.NewFromInt(100)).Abs().InexactFloat64()
 	}
 
 	// Determine status based on risk levels
@@ -207,6 +238,9 @@ func (api *API) HandleGetRiskStatus(w http.ResponseWriter, r *http.Request) {
 		"positions":                positions,
 		"timestamp":                time.Now().Unix(),
 	}
+	if api.PositionManager != nil {
+		riskStatus["portfolio_heat"] = monitoring.CurrentHeat(api.PositionManager, account.Equity.InexactFloat64())
+	}
 
 	WriteJSON(w, http.StatusOK, riskStatus)
 }
@@ -221,38 +255,94 @@ func (api *API) HandleGetStats(w http.ResponseWriter, r *http.Request) {
 
 	totalTrades := len(dbTrades)
 
-	trades := convertToTradeResults(dbTrades)
+	trades, realized := pairTradeRows(dbTrades)
 	completedTrades := len(trades)
 
 	sharpe := 0.0
 	sortino := 0.0
+	calmar := 0.0
+	var drawdownDuration time.Duration
 	winRate := 0.0
 	totalPnL := 0.0
 
 	if len(trades) > 0 {
 		sharpe = metrics.CalculateSharpeRatio(trades, 0.02)
 		sortino = metrics.CalculateSortinoRatio(trades, 0.02)
+		calmar = metrics.CalculateCalmarRatio(trades)
+		drawdownDuration = metrics.CalculateMaxDrawdownDuration(trades)
 		winRate = metrics.CalculateWinRate(trades)
-
-		for _, trade := range trades {
-			totalPnL += trade.PnL
-		}
+		// summed exact and rounded once; the per-trade PnL is already rounded to cents
+		totalPnL = utils.MoneyToFloat(realized)
 	}
 
 	response := map[string]interface{}{
-		"total_trades":     totalTrades,
-		"completed_trades": completedTrades,
-		"total_pnl":        totalPnL,
-		"sharpe_ratio":     sharpe,
-		"sortino_ratio":    sortino,
-		"win_rate":         winRate,
+		"total_trades":                totalTrades,
+		"completed_trades":            completedTrades,
+		"total_pnl":                   totalPnL,
+		"sharpe_ratio":                sharpe,
+		"sortino_ratio":               sortino,
+		"calmar_ratio":                calmar,
+		"max_drawdown_duration_hours": drawdownDuration.Hours(),
+		"max_drawdown_duration":       drawdownDuration.Round(time.Minute).String(),
+		"win_rate":                    winRate,
+	}
+
+	benchmark := strings.ToUpper(r.URL.Query().Get("benchmark"))
+	if benchmark == "" {
+		benchmark = metrics.DefaultBenchmark
+	}
+	if relative, err := benchmarkRelativeStats(trades, benchmark, datafeed.GetAlpacaBars); err != nil {
+		log.Printf("Skipping alpha/beta against %s: %v", benchmark, err)
+	} else {
+		response["benchmark"] = relative
 	}
 
 	WriteJSON(w, http.StatusOK, response)
 }
 
+// alpha/beta of the trade returns against the benchmark's return over each holding period
+func benchmarkRelativeStats(trades []metrics.TradeResult, benchmark string, fetchBars func(symbol, timeframe string, limit int, start string) ([]datafeed.Bar, error)) (map[string]interface{}, error) {
+	if len(trades) < 2 {
+		return nil, fmt.Errorf("need at least 2 completed trades, got %d", len(trades))
+	}
+
+	earliest := time.Now()
+	for _, trade := range trades {
+		if !trade.EntryTime.IsZero() && trade.EntryTime.Before(earliest) {
+			earliest = trade.EntryTime
+		}
+	}
+	start := earliest.AddDate(0, 0, -7)
+	limit := int(time.Since(start).Hours()/24) + 10
+
+	bars, err := fetchBars(benchmark, "1Day", limit, start.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	tradeReturns, benchmarkReturns := metrics.AlignBenchmarkReturns(trades, bars)
+	if len(tradeReturns) < 2 {
+		return nil, fmt.Errorf("only %d trades overlap the %s bars", len(tradeReturns), benchmark)
+	}
+	alpha, beta := metrics.CalculateAlphaBeta(tradeReturns, benchmarkReturns)
+
+	return map[string]interface{}{
+		"symbol":         benchmark,
+		"alpha":          alpha,
+		"beta":           beta,
+		"aligned_trades": len(tradeReturns),
+	}, nil
+}
+
 func convertToTradeResults(dbTrades []database.GetAllTradesRow) []metrics.TradeResult {
+	results, _ := pairTradeRows(dbTrades)
+	return results
+}
+
+// convertToTradeResults along with the exact realized P&L of all the trades, unrounded
+func pairTradeRows(dbTrades []database.GetAllTradesRow) ([]metrics.TradeResult, decimal.Decimal) {
 	var results []metrics.TradeResult
+	realized := decimal.Zero
 
 	tradesBySymbol := make(map[string][]database.GetAllTradesRow)
 	for _, trade := range dbTrades {
@@ -268,21 +358,24 @@ func convertToTradeResults(dbTrades []database.GetAllTradesRow) []metrics.TradeR
 				continue
 			}
 
+			// imported and exit-logged rows store BUY/SELL, orders placed here buy/sell
 			side := trade.Side
 
-			if side == "buy" {
+			if strings.EqualFold(side, "buy") {
 				buyTrades = append(buyTrades, trade)
-			} else if side == "sell" && len(buyTrades) > 0 {
+			} else if strings.EqualFold(side, "sell") && len(buyTrades) > 0 {
 
 				buyTrade := buyTrades[0]
 				buyTrades = buyTrades[1:]
 
-				buyPrice, _ := strconv.ParseFloat(buyTrade.Price, 64)
-				sellPrice, _ := strconv.ParseFloat(trade.Price, 64)
-				qty, _ := strconv.ParseFloat(trade.Quantity, 64)
+				// keep prices as decimals so P&L is exact before converting at the edge
+				buyPrice := utils.ParseMoney(buyTrade.Price)
+				sellPrice := utils.ParseMoney(trade.Price)
+				qty := utils.ParseMoney(trade.Quantity)
 
-				pnl := (sellPrice - buyPrice) * qty
-				returnPercent := ((sellPrice - buyPrice) / buyPrice) * 100
+				pnl := utils.RealizedPnL(buyPrice, sellPrice, qty)
+				realized = realized.Add(pnl)
+				returnPercent := utils.ReturnPercent(buyPrice, sellPrice)
 
 				var duration time.Duration
 				if buyTrade.CreatedAt.Valid && trade.CreatedAt.Valid {
@@ -291,11 +384,11 @@ func convertToTradeResults(dbTrades []database.GetAllTradesRow) []metrics.TradeR
 
 				result := metrics.TradeResult{
 					Symbol:        symbol,
-					EntryPrice:    buyPrice,
-					ExitPrice:     sellPrice,
-					Quantity:      qty,
-					PnL:           pnl,
-					ReturnPercent: returnPercent,
+					EntryPrice:    buyPrice.InexactFloat64(),
+					ExitPrice:     sellPrice.InexactFloat64(),
+					Quantity:      qty.InexactFloat64(),
+					PnL:           utils.MoneyToFloat(pnl),
+					ReturnPercent: returnPercent.InexactFloat64(),
 					Duration:      duration,
 					EntryTime:     buyTrade.CreatedAt.Time,
 					ExitTime:      trade.CreatedAt.Time,
@@ -306,78 +399,63 @@ func convertToTradeResults(dbTrades []database.GetAllTradesRow) []metrics.TradeR
 		}
 	}
 
-	return results
+	return results, realized
 }
 
+// GET /api/trades lists filled orders newest first, paired into round trips, with optional symbol and status
+// (all, open, closed) filters. Paging takes page_size (or limit) with one of offset, page or the next_cursor of
+// the previous response. Pairing runs over every order fetched for the request, so a leg whose counterpart is
+// older than that window shows as open on its page and can turn up closed once a later page loads the
+// counterpart; each closed leg carries pair_id and the two legs of a pair can land on different pages
 func (api *API) HandleGetTrades(w http.ResponseWriter, r *http.Request) {
-	_ = r.URL.Query().Get("symbol") // Symbol filter available if needed
-	limitStr := r.URL.Query().Get("limit")
-	statusFilter := r.URL.Query().Get("status") // all, open, closed
+	query := r.URL.Query()
+	symbolFilter := strings.ToUpper(strings.TrimSpace(query.Get("symbol")))
+	statusFilter := query.Get("status") // all, open, closed
 
-	limit := 100
-	if limitStr != "" {
-		parsedLimit, err := strconv.Atoi(limitStr)
-		if err == nil && parsedLimit > 0 {
-			limit = parsedLimit
-		}
-	}
-
-	// Get all orders from Alpaca (includes full trading history)
-	orders, err := api.AlpacaClient.GetOrders(alpaca.GetOrdersRequest{
-		Status: "all",          // Get all orders: open, closed, etc.
-		Limit:  int(limit * 2), // Get more to account for filtering
-		Nested: true,
-	})
+	page, err := parseTradesPage(query)
 	if err != nil {
-		log.Printf("Error fetching Alpaca orders: %v", err)
-		WriteError(w, http.StatusInternalServerError, "Failed to fetch orders")
+		WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// Group filled orders by symbol to pair buy/sell
-	filledBySymbol := make(map[string][]alpaca.Order)
-	allOrders := []alpaca.Order{}
-
-	for _, order := range orders {
-		if order.Status == "filled" || order.Status == "closed" {
-			filledBySymbol[order.Symbol] = append(filledBySymbol[order.Symbol], order)
+	// twice the orders as records wanted gives most legs on the page their counterpart; past that, keep
+	// fetching until the filtered list runs beyond the page or the history runs out
+	history := newOrderHistory(api.alpacaClient(r), symbolFilter)
+	need := 2 * (page.end() + 1)
+	var trades []map[string]interface{}
+	for {
+		if err := history.load(need); err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch orders")
+			return
 		}
-		allOrders = append(allOrders, order)
-	}
-
-	// Create trade records with P&L calculations
-	// Pair trades and calculate P&L using the monitoring package
-	tradeRecords := monitoring.PairTradesAndCalculatePnL(allOrders)
-	trades := monitoring.FormatTradeRecordsAsJSON(tradeRecords)
-
-	// Filter by status if provided
-	if statusFilter != "" && statusFilter != "all" {
-		var filtered []map[string]interface{}
-		for _, trade := range trades {
-			if tradeStatus, ok := trade["status"].(string); ok {
-				if tradeStatus == statusFilter {
-					filtered = append(filtered, trade)
-				}
-			}
+		trades = filterTradesByStatus(monitoring.FormatTradeRecordsAsJSON(monitoring.PairTradesAndCalculatePnL(history.orders)), statusFilter)
+		if len(trades) > page.end() || history.exhausted {
+			break
 		}
-		trades = filtered
+		need = len(history.orders) + tradeOrdersBatchSize
 	}
 
-	// Sort by submitted_at descending
-	sort.Slice(trades, func(i, j int) bool {
+	// newest first, with the order ID breaking ties so pages stay stable between requests
+	sort.SliceStable(trades, func(i, j int) bool {
 		iTime, _ := trades[i]["submitted_at"].(string)
 		jTime, _ := trades[j]["submitted_at"].(string)
-		return iTime > jTime
+		if iTime != jTime {
+			return iTime > jTime
+		}
+		iID, _ := trades[i]["id"].(string)
+		jID, _ := trades[j]["id"].(string)
+		return iID < jID
 	})
 
-	// Limit results
-	if len(trades) > limit {
-		trades = trades[:limit]
-	}
+	pageTrades, hasMore, nextCursor := page.slice(trades)
 
 	response := map[string]interface{}{
-		"count":       len(trades),
-		"trades":      trades,
+		"count":       len(pageTrades),
+		"trades":      pageTrades,
+		"offset":      page.Offset,
+		"page_size":   page.Size,
+		"has_more":    hasMore,
+		"next_cursor": nextCursor,
 		"timestamp":   time.Now().Unix(),
 		"risk_status": map[string]interface{}{"enabled": true},
 	}
@@ -385,16 +463,28 @@ func (api *API) HandleGetTrades(w http.ResponseWriter, r *http.Request) {
 	WriteJSON(w, http.StatusOK, response)
 }
 
+func filterTradesByStatus(trades []map[string]interface{}, status string) []map[string]interface{} {
+	if status == "" || status == "all" {
+		return trades
+	}
+	filtered := []map[string]interface{}{}
+	for _, trade := range trades {
+		if tradeStatus, ok := trade["status"].(string); ok && tradeStatus == status {
+			filtered = append(filtered, trade)
+		}
+	}
+	return filtered
+}
+
 func (api *API) HandleTradeStatistics(w http.ResponseWriter, r *http.Request) {
 	// Get all orders from Alpaca
-	orders, err := api.AlpacaClient.GetOrders(alpaca.GetOrdersRequest{
+	orders, err := api.alpacaClient(r).GetOrders(alpaca.GetOrdersRequest{
 		Status: "all",
 		Limit:  1000, // Get more orders for better statistics
 		Nested: true,
 	})
 	if err != nil {
-		log.Printf("Error fetching Alpaca orders: %v", err)
-		WriteError(w, http.StatusInternalServerError, "Failed to fetch orders")
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch orders")
 		return
 	}
 
@@ -409,10 +499,11 @@ func (api *API) HandleTradeStatistics(w http.ResponseWriter, r *http.Request) {
 
 	// Calculate P&L by pairing buy/sell trades
 	var pnlResults []float64
+	var pairedTrades []metrics.TradeResult
 	var completedTrades []map[string]interface{}
-	totalPnL := 0.0
-	largestWin := 0.0
-	largestLoss := 0.0
+	totalPnL := decimal.Zero
+	largestWin := decimal.Zero
+	largestLoss := decimal.Zero
 
 	for symbol, trades := range tradesBySymbol {
 		// Separate buys and sells
@@ -437,31 +528,39 @@ func (api *API) HandleTradeStatistics(w http.ResponseWriter, r *http.Request) {
 			buyOrder := buyTrades[i]
 			sellOrder := sellTrades[i]
 
-			buyQty, _ := buyOrder.FilledQty.Float64()
-			buyPrice, _ := buyOrder.FilledAvgPrice.Float64()
-			sellQty, _ := sellOrder.FilledQty.Float64()
-			sellPrice, _ := sellOrder.FilledAvgPrice.Float64()
-
-			// Use minimum quantity to pair
-			qty := buyQty
-			if sellQty < qty {
-				qty = sellQty
+			buyPrice := decimal.Zero
+			if buyOrder.FilledAvgPrice != nil {
+				buyPrice = *buyOrder.FilledAvgPrice
+			}
+			sellPrice := decimal.Zero
+			if sellOrder.FilledAvgPrice != nil {
+				sellPrice = *sellOrder.FilledAvgPrice
 			}
 
-			pnl := (sellPrice - buyPrice) * qty
-			pnlResults = append(pnlResults, pnl)
-			totalPnL += pnl
+			// Use minimum quantity to pair
+			qty := decimal.Min(buyOrder.FilledQty, sellOrder.FilledQty)
+
+			pnl := utils.RealizedPnL(buyPrice, sellPrice, qty)
+			pnlResults = append(pnlResults, pnl.InexactFloat64())
+			pairedTrades = append(pairedTrades, metrics.TradeResult{
+				Symbol:        symbol,
+				PnL:           pnl.InexactFloat64(),
+				ReturnPercent: utils.ReturnPercent(buyPrice, sellPrice).InexactFloat64(),
+				EntryTime:     orderFillTime(buyOrder),
+				ExitTime:      orderFillTime(sellOrder),
+			})
+			totalPnL = totalPnL.Add(pnl)
 
-			if pnl > largestWin {
+			if pnl.GreaterThan(largestWin) {
 				largestWin = pnl
 			}
-			if pnl < largestLoss {
+			if pnl.LessThan(largestLoss) {
 				largestLoss = pnl
 			}
 
 			completedTrades = append(completedTrades, map[string]interface{}{
 				"symbol": symbol,
-				"pnl":    pnl,
+				"pnl":    utils.MoneyToFloat(pnl),
 			})
 		}
 	}
@@ -477,47 +576,123 @@ func (api *API) HandleTradeStatistics(w http.ResponseWriter, r *http.Request) {
 	losingTrades := len(pnlResults) - winningTrades
 
 	winRate := 0.0
-	avgPnL := 0.0
+	avgPnL := decimal.Zero
 	if len(pnlResults) > 0 {
 		winRate = (float64(winningTrades) / float64(len(pnlResults))) * 100
-		avgPnL = totalPnL / float64(len(pnlResults))
+		avgPnL = totalPnL.Div(decimal.NewFromInt(int64(len(pnlResults))))
 	}
 
 	// Calculate Sharpe ratio from PnL returns using metrics package
 	sharpeRatio := metrics.CalculateSharpeFromReturns(pnlResults)
 	sortinoRatio := metrics.CalculateSortinoFromReturns(pnlResults)
+	drawdownDuration := metrics.CalculateMaxDrawdownDuration(pairedTrades)
 
 	// Get open positions for additional context
-	openPositions, err := api.AlpacaClient.GetPositions()
+	openPositions, err := api.alpacaClient(r).GetPositions()
 	openCount := 0
-	openPnL := 0.0
+	openPnL := decimal.Zero
 	if err == nil {
 		openCount = len(openPositions)
 		for _, pos := range openPositions {
-			openPnL += pos.UnrealizedPL.InexactFloat64()
+			if pos.UnrealizedPL != nil {
+				openPnL = openPnL.Add(*pos.UnrealizedPL)
+			}
 		}
 	}
 
 	response := map[string]interface{}{
-		"total_trades":       totalFilled,
-		"winning_trades":     winningTrades,
-		"losing_trades":      losingTrades,
-		"win_rate":           winRate,
-		"total_pnl":          totalPnL,
-		"avg_pnl":            avgPnL,
-		"largest_win":        largestWin,
-		"largest_loss":       largestLoss,
-		"avg_trade_duration": "N/A",
-		"sharpe_ratio":       sharpeRatio,
-		"sortino_ratio":      sortinoRatio,
-		"open_positions":     openCount,
-		"open_pnl":           openPnL,
-		"timestamp":          time.Now().Unix(),
+		"total_trades":                totalFilled,
+		"winning_trades":              winningTrades,
+		"losing_trades":               losingTrades,
+		"win_rate":                    winRate,
+		"total_pnl":                   utils.MoneyToFloat(totalPnL),
+		"avg_pnl":                     utils.MoneyToFloat(avgPnL),
+		"largest_win":                 utils.MoneyToFloat(largestWin),
+		"largest_loss":                utils.MoneyToFloat(largestLoss),
+		"avg_trade_duration":          "N/A",
+		"sharpe_ratio":                sharpeRatio,
+		"sortino_ratio":               sortinoRatio,
+		"calmar_ratio":                metrics.CalculateCalmarRatio(pairedTrades),
+		"max_drawdown_duration_hours": drawdownDuration.Hours(),
+		"max_drawdown_duration":       drawdownDuration.Round(time.Minute).String(),
+		"open_positions":              openCount,
+		"open_pnl":                    utils.MoneyToFloat(openPnL),
+		"timestamp":                   time.Now().Unix(),
 	}
 
 	WriteJSON(w, http.StatusOK, response)
 }
 
+// when an order filled, falling back to when it was submitted
+func orderFillTime(order alpaca.Order) time.Time {
+	if order.FilledAt != nil {
+		return *order.FilledAt
+	}
+	return order.SubmittedAt
+}
+
+// per-symbol win rate, P&L and hold time from FIFO-paired logged trades, best symbols first
+func (api *API) HandleTradeStatisticsBySymbol(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			WriteError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+	minTrades := 1
+	if minStr := r.URL.Query().Get("min_trades"); minStr != "" {
+		parsed, err := strconv.Atoi(minStr)
+		if err != nil || parsed < 1 {
+			WriteError(w, http.StatusBadRequest, "min_trades must be a positive integer")
+			return
+		}
+		minTrades = parsed
+	}
+
+	dbTrades, err := api.Queries.GetAllTrades(r.Context())
+	if err != nil {
+		log.Printf("Error fetching trades: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch trades")
+		return
+	}
+
+	symbols := symbolStatsResponse(convertToTradeResults(dbTrades), minTrades, limit)
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"symbols":    symbols,
+		"count":      len(symbols),
+		"min_trades": minTrades,
+		"timestamp":  time.Now().Unix(),
+	})
+}
+
+func symbolStatsResponse(trades []metrics.TradeResult, minTrades, limit int) []map[string]interface{} {
+	ranked := metrics.RankSymbolStats(metrics.CalculateSymbolStats(trades), minTrades)
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	symbols := make([]map[string]interface{}, 0, len(ranked))
+	for _, stat := range ranked {
+		symbols = append(symbols, map[string]interface{}{
+			"symbol":         stat.Symbol,
+			"trade_count":    stat.TotalTrades,
+			"wins":           stat.Wins,
+			"losses":         stat.Losses,
+			"win_rate":       stat.WinRate,
+			"net_pnl":        stat.NetPnL,
+			"largest_win":    stat.LargestWin,
+			"largest_loss":   stat.LargestLoss,
+			"avg_hold_hours": stat.AvgHold.Hours(),
+			"avg_hold":       stat.AvgHold.Round(time.Minute).String(),
+		})
+	}
+	return symbols
+}
+
 func (api *API) HandleSellAllTrades(w http.ResponseWriter, r *http.Request) {
 	positions := api.PositionManager.GetOpenPositions()
 
@@ -534,7 +709,7 @@ func (api *API) HandleSellAllTrades(w http.ResponseWriter, r *http.Request) {
 	var failedSymbols []map[string]interface{}
 
 	for _, pos := range positions {
-		_, err := api.AlpacaClient.ClosePosition(pos.Symbol, alpaca.ClosePositionRequest{})
+		_, err := api.alpacaClient(r).ClosePosition(pos.Symbol, alpaca.ClosePositionRequest{})
 		if err != nil {
 			failedSymbols = append(failedSymbols, map[string]interface{}{
 				"symbol": pos.Symbol,
@@ -564,20 +739,40 @@ func (api *API) HandleGetPositionBySymbol(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	position, err := api.AlpacaClient.GetPosition(symbol)
+	position, err := api.alpacaClient(r).GetPosition(symbol)
 	if err != nil {
-		WriteError(w, http.StatusNotFound, "Position not found")
+		writeServiceError(w, err, http.StatusNotFound, "Position not found")
 		return
 	}
 
 	WriteJSON(w, http.StatusOK, position)
 }
 
+// a sell against a long, or a buy against a short, reduces or closes it; anything else opens or adds to one
+func opensPosition(side string, held alpaca.Position) bool {
+	short := held.Side == "short" || held.Qty.IsNegative()
+	if side == "buy" {
+		return !short
+	}
+	return short
+}
+
+// whether a config check that only applies to new entries is on, so the held positions are needed to tell
+func (api *API) entryChecksEnabled() bool {
+	cfg := api.Config
+	return cfg != nil && (cfg.DataFreshness.Enabled || cfg.Earnings.Enabled || cfg.BuyingPower.Enabled)
+}
+
 func (api *API) HandleExecuteTrade(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Symbol   string  `json:"symbol"`
-		Side     string  `json:"side"`
-		Quantity float64 `json:"quantity"`
+		Symbol      string  `json:"symbol"`
+		Side        string  `json:"side"`
+		Quantity    float64 `json:"quantity"`
+		Type        string  `json:"type"`          // market (default) or limit
+		LimitPrice  float64 `json:"limit_price"`   // required for limit orders
+		TimeInForce string  `json:"time_in_force"` // day (default), gtc, opg, cls, ioc, fok
+		// idempotency key: resending a request with the same ID never places a second order; generated when empty
+		ClientOrderID string `json:"client_order_id"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -589,6 +784,7 @@ func (api *API) HandleExecuteTrade(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, "Symbol is required")
 		return
 	}
+	req.Symbol = utils.NormalizeSymbol(req.Symbol, "")
 	if req.Side != "buy" && req.Side != "sell" {
 		WriteError(w, http.StatusBadRequest, "Side must be 'buy' or 'sell'")
 		return
@@ -598,6 +794,138 @@ func (api *API) HandleExecuteTrade(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	orderType := alpaca.Market
+	switch req.Type {
+	case "", "market":
+	case "limit":
+		if req.LimitPrice <= 0 {
+			WriteError(w, http.StatusBadRequest, "limit_price must be greater than 0 for limit orders")
+			return
+		}
+		orderType = alpaca.Limit
+	default:
+		WriteError(w, http.StatusBadRequest, "Type must be 'market' or 'limit'")
+		return
+	}
+
+	timeInForce, err := strategy.ParseTimeInForce(req.TimeInForce)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if strategy.IsAuctionTimeInForce(timeInForce) {
+		// Alpaca only accepts whole-share quantities in the opening/closing auction
+		if req.Quantity != math.Trunc(req.Quantity) {
+			WriteError(w, http.StatusBadRequest, "On-open/on-close orders require a whole-share quantity")
+			return
+		}
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			log.Printf("Error loading config: %v", err)
+			WriteError(w, http.StatusInternalServerError, "Failed to load config")
+			return
+		}
+		if err := strategy.CheckAuctionOrderWindow(timeInForce, time.Now(), cfg); err != nil {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	// whether the order opens or adds to a position is decided from what the broker holds, not the side:
+	// a sell while flat opens a short and a buy against a short covers it
+	opensEntry := true
+	var heldSymbols []string
+	if api.PositionManager != nil || api.RiskManager != nil || api.entryChecksEnabled() {
+		held, err := api.alpacaClient(r).GetPositions()
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch positions")
+			return
+		}
+		heldSymbols = make([]string, len(held))
+		for i, pos := range held {
+			heldSymbols[i] = utils.NormalizeSymbol(pos.Symbol, string(pos.AssetClass))
+			if heldSymbols[i] == req.Symbol {
+				opensEntry = opensPosition(req.Side, pos)
+			}
+		}
+	}
+
+	// entries take a throttle slot up front, handed back unless the order goes through
+	placed := false
+	if opensEntry && api.PositionManager != nil {
+		ok, reason, release := api.PositionManager.ReserveEntry()
+		if !ok {
+			WriteError(w, http.StatusTooManyRequests, reason)
+			return
+		}
+		defer func() {
+			if !placed {
+				release()
+			}
+		}()
+	}
+
+	// entries are also held to the risk manager's same-sector limit
+	sector := ""
+	if opensEntry && api.RiskManager != nil {
+		sector = api.RiskManager.SymbolSector(req.Symbol)
+		if ok, reason := api.RiskManager.CanAddPosition(req.Symbol, sector, heldSymbols); !ok {
+			WriteError(w, http.StatusConflict, reason)
+			return
+		}
+	}
+
+	// exits always go through; only new entries are held back on stale bars
+	if opensEntry && api.Config != nil && api.Config.DataFreshness.Enabled {
+		if err := api.checkDataFreshness(req.Symbol); err != nil {
+			if !errors.Is(err, strategy.ErrStaleMarketData) {
+				writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch market data")
+				return
+			}
+			if !api.Config.DataFreshness.WarnOnly {
+				WriteError(w, http.StatusConflict, err.Error())
+				return
+			}
+			log.Printf("Warning: trading %s on stale data: %v", req.Symbol, err)
+		}
+	}
+
+	// new entries stay out of the days before an earnings report; a calendar outage lets them through with a note
+	earningsNote := ""
+	if opensEntry && api.Config != nil && api.Config.Earnings.Enabled {
+		note, err := strategy.CheckEarningsWindow(req.Symbol, time.Now(), api.earningsCalendar(), api.Config.Earnings)
+		if err != nil {
+			WriteError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if note != "" {
+			log.Printf("Warning: %s", note)
+			earningsNote = note
+		}
+	}
+
+	// new entries, shorts included, are checked against buying power up front instead of bouncing off the broker
+	resizedFrom := 0.0
+	if opensEntry && api.Config != nil && api.Config.BuyingPower.Enabled {
+		limitPrice := 0.0
+		if orderType == alpaca.Limit {
+			limitPrice = req.LimitPrice
+		}
+		quantity, err := api.fitBuyingPower(r, req.Symbol, req.Quantity, limitPrice, api.Config.BuyingPower)
+		if err != nil {
+			if errors.Is(err, strategy.ErrInsufficientBuyingPower) {
+				WriteError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+			writeServiceError(w, err, http.StatusInternalServerError, "Failed to check buying power")
+			return
+		}
+		if quantity != req.Quantity {
+			log.Printf("Resized %s %s from %v to %v to fit buying power", req.Symbol, req.Side, req.Quantity, quantity)
+			resizedFrom, req.Quantity = req.Quantity, quantity
+		}
+	}
+
 	side := alpaca.Buy
 	if req.Side == "sell" {
 		side = alpaca.Sell
@@ -605,27 +933,44 @@ func (api *API) HandleExecuteTrade(w http.ResponseWriter, r *http.Request) {
 
 	qty := decimal.NewFromFloat(req.Quantity)
 	order := alpaca.PlaceOrderRequest{
-		Symbol:      req.Symbol,
-		Qty:         &qty,
-		Side:        side,
-		Type:        alpaca.Market,
-		TimeInForce: alpaca.Day,
+		Symbol:        req.Symbol,
+		Qty:           &qty,
+		Side:          side,
+		Type:          orderType,
+		TimeInForce:   timeInForce,
+		ClientOrderID: req.ClientOrderID,
+	}
+	if orderType == alpaca.Limit {
+		limitPrice := decimal.NewFromFloat(req.LimitPrice)
+		order.LimitPrice = &limitPrice
 	}
 
-	placedOrder, err := api.AlpacaClient.PlaceOrder(order)
+	placedOrder, err := strategy.SubmitOrder(api.alpacaClient(r), order, api.submitRetry())
 	if err != nil {
-		log.Printf("Error placing order: %v", err)
-		WriteError(w, http.StatusInternalServerError, "Failed to execute trade")
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to execute trade")
 		return
 	}
+	placed = true
 
 	response := map[string]interface{}{
-		"success":  true,
-		"order_id": placedOrder.ID,
-		"symbol":   placedOrder.Symbol,
-		"side":     placedOrder.Side,
-		"quantity": placedOrder.Qty.String(),
-		"status":   placedOrder.Status,
+		"success":         true,
+		"order_id":        placedOrder.ID,
+		"client_order_id": placedOrder.ClientOrderID,
+		"symbol":          placedOrder.Symbol,
+		"side":            placedOrder.Side,
+		"quantity":        placedOrder.Qty.String(),
+		"status":          placedOrder.Status,
+		"type":            orderType,
+		"time_in_force":   timeInForce,
+	}
+	if resizedFrom > 0 {
+		response["resized_from"] = resizedFrom
+	}
+	if earningsNote != "" {
+		response["earnings_note"] = earningsNote
+	}
+	if sector != "" {
+		response["sector"] = sector
 	}
 
 	WriteJSON(w, http.StatusCreated, response)
@@ -638,9 +983,9 @@ func (api *API) HandleClosePosition(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	position, err := api.AlpacaClient.GetPosition(symbol)
+	position, err := api.alpacaClient(r).GetPosition(symbol)
 	if err != nil {
-		WriteError(w, http.StatusNotFound, "Position not found")
+		writeServiceError(w, err, http.StatusNotFound, "Position not found")
 		return
 	}
 
@@ -660,10 +1005,9 @@ func (api *API) HandleClosePosition(w http.ResponseWriter, r *http.Request) {
 		TimeInForce: alpaca.Day,
 	}
 
-	placedOrder, err := api.AlpacaClient.PlaceOrder(order)
+	placedOrder, err := strategy.SubmitOrder(api.alpacaClient(r), order, api.submitRetry())
 	if err != nil {
-		log.Printf("Error closing position: %v", err)
-		WriteError(w, http.StatusInternalServerError, "Failed to close position")
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to close position")
 		return
 	}
 
@@ -679,7 +1023,64 @@ func (api *API) HandleClosePosition(w http.ResponseWriter, r *http.Request) {
 	WriteJSON(w, http.StatusOK, response)
 }
 
+// HandleAttachOCOExit places a one-cancels-other stop/target exit for an open position
+func (api *API) HandleAttachOCOExit(w http.ResponseWriter, r *http.Request) {
+	symbol := utils.NormalizeSymbol(r.PathValue("symbol"), "")
+	if symbol == "" {
+		WriteError(w, http.StatusBadRequest, "Symbol is required")
+		return
+	}
+
+	var req struct {
+		StopPrice   float64 `json:"stop_price"`
+		TargetPrice float64 `json:"target_price"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	if api.PositionManager == nil {
+		WriteError(w, http.StatusServiceUnavailable, "Position manager not initialized")
+		return
+	}
+	if err := api.PositionManager.SyncFromAlpaca(r.Context()); err != nil {
+		log.Printf("Warning: could not sync positions before OCO attach: %v", err)
+	}
+
+	pos, err := api.PositionManager.AttachOCOExit(symbol, req.StopPrice, req.TargetPrice)
+	switch {
+	case errors.Is(err, position.ErrPositionNotFound):
+		WriteError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, position.ErrInvalidOCOLevels), errors.Is(err, position.ErrOCOQuantityMismatch):
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, position.ErrOCOAlreadyAttached):
+		WriteError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to attach OCO exit")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, map[string]interface{}{
+		"success":         true,
+		"symbol":          pos.Symbol,
+		"quantity":        pos.Quantity,
+		"stop_price":      pos.StopLossPrice,
+		"target_price":    pos.TakeProfitPrice,
+		"target_order_id": pos.OCOTargetOrderID,
+		"stop_order_id":   pos.OCOStopOrderID,
+	})
+}
+
 func (api *API) HandleGenerateToken(w http.ResponseWriter, r *http.Request) {
+	if !api.JWTManager.CanIssue(r) {
+		WriteError(w, http.StatusUnauthorized, "A valid X-API-Key header is required to issue a token")
+		return
+	}
+
 	var req struct {
 		UserID string `json:"user_id"`
 		Email  string `json:"email"`
@@ -715,10 +1116,9 @@ func (api *API) HandleGenerateToken(w http.ResponseWriter, r *http.Request) {
 func (api *API) HandlePortfolioSummary(w http.ResponseWriter, r *http.Request) {
 	symbol := r.URL.Query().Get("symbol")
 
-	alpacaPositions, err := api.AlpacaClient.GetPositions()
+	alpacaPositions, err := api.alpacaClient(r).GetPositions()
 	if err != nil {
-		log.Printf("Error fetching positions from Alpaca: %v", err)
-		WriteError(w, http.StatusInternalServerError, "Failed to fetch portfolio summary")
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch portfolio summary")
 		return
 	}
 
@@ -733,36 +1133,33 @@ func (api *API) HandlePortfolioSummary(w http.ResponseWriter, r *http.Request) {
 		filteredPositions = alpacaPositions
 	}
 
-	// Calculate portfolio metrics
-	var totalValue decimal.Decimal
-	var totalCost decimal.Decimal
-	var totalGain decimal.Decimal
-
-	for _, pos := range filteredPositions {
-		if pos.MarketValue != nil {
-			totalValue = totalValue.Add(*pos.MarketValue)
-		}
-		totalCost = totalCost.Add(pos.CostBasis)
-
-		if pos.UnrealizedPL != nil {
-			totalGain = totalGain.Add(*pos.UnrealizedPL)
-		}
+	// positions missing a market value are estimated or flagged rather than summed as zero
+	var quote quoteFetcher
+	if api.Config != nil && api.Config.Features.QuoteMissingMarketValues {
+		quote = api.latestClose
 	}
+	totals := summarizePositions(filteredPositions, quote)
 
 	response := map[string]interface{}{
-		"total_positions": len(filteredPositions),
-		"total_value":     totalValue.String(),
-		"total_cost":      totalCost.String(),
-		"total_gain":      totalGain.String(),
-		"positions":       filteredPositions,
+		"total_positions":      len(filteredPositions),
+		"total_value":          totals.Value.String(),
+		"total_cost":           totals.Cost.String(),
+		"total_gain":           totals.Gain.String(),
+		"positions":            filteredPositions,
+		"complete":             len(totals.Incomplete) == 0,
+		"incomplete_positions": len(totals.Incomplete),
+		"incomplete_symbols":   totals.Incomplete,
+		"estimated_symbols":    totals.Estimated,
+	}
+	if len(totals.Incomplete) > 0 {
+		response["note"] = "positions without a market value or quote are left out of the totals"
 	}
 
 	WriteJSON(w, http.StatusOK, response)
 }
 
 func (api *API) HandleRiskAdjustments(w http.ResponseWriter, r *http.Request) {
-	if api.RiskManager == nil {
-		WriteError(w, http.StatusInternalServerError, "Risk manager not initialized")
+	if !api.requireRiskManager(w) {
 		return
 	}
 
@@ -797,8 +1194,7 @@ func (api *API) HandlePerformanceMetrics(w http.ResponseWriter, r *http.Request)
 }
 
 func (api *API) HandleRiskAlerts(w http.ResponseWriter, r *http.Request) {
-	if api.RiskManager == nil {
-		WriteError(w, http.StatusInternalServerError, "Risk manager not initialized")
+	if !api.requireRiskManager(w) {
 		return
 	}
 
@@ -852,28 +1248,53 @@ func (api *API) HandleRiskAlerts(w http.ResponseWriter, r *http.Request) {
 
 //5.4
 
-func (api *API) HandleBacktest(w http.ResponseWriter, r *http.Request) {
-	symbol := r.URL.Query().Get("symbol")
+// validated inputs for a single-symbol backtest
+type backtestParams struct {
+	Symbol    string
+	StartDate string
+	EndDate   string
+	Capital   float64
+	Strategy  string // registered backtest strategy name
+	Bootstrap int    // resamples for metric confidence intervals, 0 skips them
+
+	Commission  float64 // flat fee per fill
+	SlippageBps float64 // basis points each fill moves against the trade
+}
+
+// runs a backtest and reports progress (0-100) as bars are processed
+type backtestRunner func(ctx context.Context, params backtestParams, progress func(percent int)) (map[string]interface{}, error)
+
+// backtest capital when the request doesn't set one: the live account balance, or a flat $100k when there is no
+// risk manager or it never loaded a balance
+func (api *API) defaultBacktestCapital() float64 {
+	if api.RiskManager != nil {
+		if balance := api.RiskManager.GetAccountBalance(); balance > 0 {
+			return balance
+		}
+	}
+	return defaultBacktestCapital
+}
+
+func (api *API) parseBacktestParams(query url.Values) (backtestParams, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(query.Get("symbol")))
 	if symbol == "" {
-		WriteError(w, http.StatusBadRequest, "Symbol is required for backtest")
-		return
+		return backtestParams{}, fmt.Errorf("Symbol is required for backtest")
 	}
 
-	openPositions := api.PositionManager.GetOpenPositions()
-	for _, pos := range openPositions {
-		if pos.Symbol == symbol {
-			WriteError(w, http.StatusBadRequest, "Cannot run backtest on an open position")
-			return
+	if api.PositionManager != nil {
+		for _, pos := range api.PositionManager.GetOpenPositions() {
+			if pos.Symbol == symbol {
+				return backtestParams{}, fmt.Errorf("Cannot run backtest on an open position")
+			}
 		}
 	}
 
-	startDate := r.URL.Query().Get("start_date")
-	endDate := r.URL.Query().Get("end_date")
-	capitalStr := r.URL.Query().Get("capital")
+	startDate := query.Get("start_date")
+	endDate := query.Get("end_date")
+	capitalStr := query.Get("capital")
 
 	if startDate == "" || endDate == "" {
-		WriteError(w, http.StatusBadRequest, "start_date and end_date are required (YYYY-MM-DD)")
-		return
+		return backtestParams{}, fmt.Errorf("start_date and end_date are required (YYYY-MM-DD)")
 	}
 
 	// Parse dates using formatting package
@@ -881,73 +1302,133 @@ func (api *API) HandleBacktest(w http.ResponseWriter, r *http.Request) {
 	endDateParsed := formatting.ParseDate(endDate)
 
 	if startDateParsed.IsZero() || endDateParsed.IsZero() {
-		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid date format. Use YYYY-MM-DD (received: %s to %s)", startDate, endDate))
-		return
+		return backtestParams{}, fmt.Errorf("Invalid date format. Use YYYY-MM-DD (received: %s to %s)", startDate, endDate)
 	}
 
-	// Normalize dates to YYYY-MM-DD format for API consistency
-	startDate = startDateParsed.Format("2006-01-02")
-	endDate = endDateParsed.Format("2006-01-02")
-
 	// Parse capital amount
-	capital := 100000.0
+	capital := api.defaultBacktestCapital()
 	if capitalStr != "" {
 		if parsedCap, err := strconv.ParseFloat(capitalStr, 64); err == nil && parsedCap > 0 {
 			capital = parsedCap
 		}
-	} else if api.RiskManager != nil {
-		capital = api.RiskManager.GetAccountBalance()
 	}
 
-	// Fetch historical bars for the symbol using the date range
-	historicalBars, err := datafeed.GetAlpacaBars(symbol, "1Day", 10000, startDate)
-	if err != nil || len(historicalBars) == 0 {
-		log.Printf("Error fetching historical bars: %v", err)
-		WriteError(w, http.StatusInternalServerError, "Failed to fetch historical data for backtest")
-		return
+	strat, err := metrics.StrategyByName(query.Get("strategy"))
+	if err != nil {
+		return backtestParams{}, err
 	}
 
-	// Parse dates for filtering (startDateParsed and endDateParsed already parsed at top)
-	startDateOnly, _ := time.Parse("2006-01-02", startDate)
-	endDateOnly, _ := time.Parse("2006-01-02", endDate)
-	// Extend end date to include the entire day
-	endDateOnly = endDateOnly.AddDate(0, 0, 1)
-
-	// Filter bars to only include data within the specified date range
-	var filteredBars []datafeed.Bar
-	for _, bar := range historicalBars {
-		barDate, err := time.Parse(time.RFC3339, bar.Timestamp)
-		if err != nil {
-			log.Printf("Error parsing bar timestamp %s: %v", bar.Timestamp, err)
-			continue
+	bootstrap := 0
+	if raw := query.Get("bootstrap"); raw != "" {
+		bootstrap, err = strconv.Atoi(raw)
+		if err != nil || bootstrap < 0 || bootstrap > metrics.MaxBootstrapSamples {
+			return backtestParams{}, fmt.Errorf("Invalid 'bootstrap': use a sample count from 0 to %d", metrics.MaxBootstrapSamples)
 		}
+	}
 
-		// Compare dates only (ignore time component)
-		barDateOnly := barDate.Truncate(24 * time.Hour)
-
-		// Include bars that fall within [startDate, endDate] inclusive
-		if !barDateOnly.Before(startDateOnly) && barDateOnly.Before(endDateOnly) {
-			filteredBars = append(filteredBars, bar)
-		}
+	commission, err := nonNegativeQueryFloat(query, "commission")
+	if err != nil {
+		return backtestParams{}, err
+	}
+	slippageBps, err := nonNegativeQueryFloat(query, "slippage_bps")
+	if err != nil {
+		return backtestParams{}, err
 	}
 
-	historicalBars = filteredBars
+	// Normalize dates to YYYY-MM-DD format for API consistency
+	return backtestParams{
+		Symbol:    symbol,
+		StartDate: startDateParsed.Format("2006-01-02"),
+		EndDate:   endDateParsed.Format("2006-01-02"),
+		Capital:   capital,
+		Strategy:  strat.Name(),
+		Bootstrap: bootstrap,
+
+		Commission:  commission,
+		SlippageBps: slippageBps,
+	}, nil
+}
 
-	// Sort bars chronologically (oldest first) for backtest
-	sort.Slice(historicalBars, func(i, j int) bool {
-		timeI, _ := time.Parse(time.RFC3339, historicalBars[i].Timestamp)
-		timeJ, _ := time.Parse(time.RFC3339, historicalBars[j].Timestamp)
-		return timeI.Before(timeJ)
-	})
+// an optional query number that can't be negative, 0 when absent
+func nonNegativeQueryFloat(query url.Values, key string) (float64, error) {
+	raw := query.Get(key)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || value < 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("Invalid '%s': use a non-negative number", key)
+	}
+	return value, nil
+}
+
+func (api *API) HandleBacktest(w http.ResponseWriter, r *http.Request) {
+	params, err := api.parseBacktestParams(r.URL.Query())
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	// Run backtest with TradeResult from metrics.RunBacktest
-	trades, err := metrics.RunBacktest(symbol, historicalBars, capital)
+	response, err := api.runBacktest(r.Context(), params, nil)
 	if err != nil {
 		log.Printf("Error running backtest: %v", err)
 		WriteError(w, http.StatusInternalServerError, "Failed to execute backtest")
 		return
 	}
 
+	backtestID := params.Symbol + "_" + time.Now().Format("20060102150405")
+	response["backtest_id"] = backtestID
+
+	// Save the results, keeping them cached in front of the store
+	api.storeBacktest(r.Context(), backtestID, response)
+
+	WriteJSON(w, http.StatusOK, response)
+}
+
+func (api *API) runBacktest(ctx context.Context, params backtestParams, progress func(percent int)) (map[string]interface{}, error) {
+	if api.backtestRunner != nil {
+		return api.backtestRunner(ctx, params, progress)
+	}
+	return api.runSymbolBacktest(ctx, params, progress)
+}
+
+// fetches bars (first 10% of progress) and runs the single-symbol backtest over them (remaining 90%)
+func (api *API) runSymbolBacktest(ctx context.Context, params backtestParams, progress func(percent int)) (map[string]interface{}, error) {
+	report := func(percent int) {
+		if progress != nil {
+			progress(percent)
+		}
+	}
+
+	symbol, startDate, endDate, capital := params.Symbol, params.StartDate, params.EndDate, params.Capital
+
+	historicalBars, err := fetchBacktestBars(symbol, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch historical data for backtest: %w", err)
+	}
+	report(10)
+
+	strat, err := metrics.StrategyByName(params.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run the chosen strategy over the bars, sized with the live order config and net of trading costs
+	trades, err := metrics.RunBacktestWithConfig(symbol, historicalBars, metrics.BacktestConfig{
+		InitialCapital:     capital,
+		CommissionPerTrade: params.Commission,
+		SlippageBps:        params.SlippageBps,
+		AllowShorts:        api.shortSignalsEnabled(),
+		Order:              api.OrderConfig.Snapshot(),
+		Strategy:           strat,
+		Progress: func(processed, total int) {
+			report(10 + processed*90/total)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	// Calculate metrics from trades
 	winRate := metrics.CalculateWinRate(trades)
 
@@ -974,8 +1455,6 @@ func (api *API) HandleBacktest(w http.ResponseWriter, r *http.Request) {
 	totalReturnPct := (totalPnL / capital) * 100
 	losingTrades := len(trades) - winningTrades
 
-	backtestID := symbol + "_" + time.Now().Format("20060102150405")
-
 	// Build historical bars data for charting
 	formattedBars := make([]map[string]interface{}, 0)
 	for _, bar := range historicalBars {
@@ -1001,6 +1480,7 @@ func (api *API) HandleBacktest(w http.ResponseWriter, r *http.Request) {
 	for i, trade := range trades {
 		formattedTrades = append(formattedTrades, map[string]interface{}{
 			"trade_num":   i + 1,
+			"direction":   trade.Direction,
 			"entry_price": trade.EntryPrice,
 			"exit_price":  trade.ExitPrice,
 			"entry_time":  trade.EntryTime.Format("2006-01-02"),
@@ -1008,12 +1488,13 @@ func (api *API) HandleBacktest(w http.ResponseWriter, r *http.Request) {
 			"pnl":         trade.PnL,
 			"return_pct":  trade.ReturnPercent,
 			"quantity":    trade.Quantity,
+			"fees":        trade.Fees,
 		})
 	}
 
 	response := map[string]interface{}{
-		"backtest_id":      backtestID,
 		"symbol":           symbol,
+		"strategy":         strat.Name(),
 		"status":           "completed",
 		"start_date":       startDate,
 		"end_date":         endDate,
@@ -1026,18 +1507,202 @@ func (api *API) HandleBacktest(w http.ResponseWriter, r *http.Request) {
 		"losing_trades":    losingTrades,
 		"largest_win":      largestWin,
 		"largest_loss":     largestLoss,
+		"commission":       params.Commission,
+		"slippage_bps":     params.SlippageBps,
+		"allow_shorts":     api.shortSignalsEnabled(),
+		"total_fees":       metrics.TotalFees(trades),
 		"created_at":       time.Now().Unix(),
 		"historical_bars":  formattedBars,
 		"trades":           formattedTrades,
 	}
 
-	// Cache the backtest results
-	api.backtestMutex.Lock()
-	if api.backtestCache == nil {
-		api.backtestCache = make(map[string]map[string]interface{})
+	if params.Bootstrap > 0 {
+		bootstrap, err := metrics.BootstrapMetrics(trades, capital, params.Bootstrap, nil)
+		if err != nil {
+			return nil, err
+		}
+		response["bootstrap"] = bootstrap
+	}
+
+	return response, nil
+}
+
+// whether features.enable_short_signals lets backtests take SHORT entries
+func (api *API) shortSignalsEnabled() bool {
+	return api.Config != nil && api.Config.Features.EnableShortSignals
+}
+
+// fetches daily bars for [startDate, endDate] sorted oldest first
+func fetchBacktestBars(symbol, startDate, endDate string) ([]datafeed.Bar, error) {
+	historicalBars, err := datafeed.GetAlpacaBars(symbol, "1Day", 10000, startDate)
+	if err != nil {
+		return nil, err
 	}
-	api.backtestCache[backtestID] = response
-	api.backtestMutex.Unlock()
+	if len(historicalBars) == 0 {
+		return nil, fmt.Errorf("no historical data for %s", symbol)
+	}
+
+	startDateOnly, _ := time.Parse("2006-01-02", startDate)
+	endDateOnly, _ := time.Parse("2006-01-02", endDate)
+	// Extend end date to include the entire day
+	endDateOnly = endDateOnly.AddDate(0, 0, 1)
+
+	// Filter bars to only include data within the specified date range
+	var filteredBars []datafeed.Bar
+	for _, bar := range historicalBars {
+		barDate, err := time.Parse(time.RFC3339, bar.Timestamp)
+		if err != nil {
+			log.Printf("Error parsing bar timestamp %s: %v", bar.Timestamp, err)
+			continue
+		}
+
+		// Compare dates only (ignore time component)
+		barDateOnly := barDate.Truncate(24 * time.Hour)
+
+		// Include bars that fall within [startDate, endDate] inclusive
+		if !barDateOnly.Before(startDateOnly) && barDateOnly.Before(endDateOnly) {
+			filteredBars = append(filteredBars, bar)
+		}
+	}
+
+	// Sort bars chronologically (oldest first) for backtest
+	sort.Slice(filteredBars, func(i, j int) bool {
+		timeI, _ := time.Parse(time.RFC3339, filteredBars[i].Timestamp)
+		timeJ, _ := time.Parse(time.RFC3339, filteredBars[j].Timestamp)
+		return timeI.Before(timeJ)
+	})
+
+	return filteredBars, nil
+}
+
+func (api *API) HandlePortfolioBacktest(w http.ResponseWriter, r *http.Request) {
+	symbolsParam := r.URL.Query().Get("symbols")
+	if symbolsParam == "" {
+		WriteError(w, http.StatusBadRequest, "symbols is required (comma separated)")
+		return
+	}
+
+	var symbols []string
+	for _, s := range strings.Split(symbolsParam, ",") {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if s != "" {
+			symbols = append(symbols, s)
+		}
+	}
+	if len(symbols) == 0 {
+		WriteError(w, http.StatusBadRequest, "symbols is required (comma separated)")
+		return
+	}
+
+	startDate := r.URL.Query().Get("start_date")
+	endDate := r.URL.Query().Get("end_date")
+	if startDate == "" || endDate == "" {
+		WriteError(w, http.StatusBadRequest, "start_date and end_date are required (YYYY-MM-DD)")
+		return
+	}
+
+	startDateParsed := formatting.ParseDate(startDate)
+	endDateParsed := formatting.ParseDate(endDate)
+	if startDateParsed.IsZero() || endDateParsed.IsZero() {
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid date format. Use YYYY-MM-DD (received: %s to %s)", startDate, endDate))
+		return
+	}
+	startDate = startDateParsed.Format("2006-01-02")
+	endDate = endDateParsed.Format("2006-01-02")
+
+	capital := api.defaultBacktestCapital()
+	if capitalStr := r.URL.Query().Get("capital"); capitalStr != "" {
+		if parsedCap, err := strconv.ParseFloat(capitalStr, 64); err == nil && parsedCap > 0 {
+			capital = parsedCap
+		}
+	}
+
+	// Query params override the server order config for what-if runs
+	orderConfig := strategy.OrderConfig{MaxOpenPositions: 5, MaxPortfolioPercent: 20.0}
+	if current := api.OrderConfig.Snapshot(); current != nil {
+		orderConfig = *current
+	}
+	if v, err := strconv.Atoi(r.URL.Query().Get("max_positions")); err == nil && v > 0 {
+		orderConfig.MaxOpenPositions = v
+	}
+	if v, err := strconv.ParseFloat(r.URL.Query().Get("max_portfolio_percent"), 64); err == nil && v > 0 && v <= 100 {
+		orderConfig.MaxPortfolioPercent = v
+	}
+
+	barsBySymbol := make(map[string][]datafeed.Bar)
+	var missing []string
+	for _, symbol := range symbols {
+		bars, err := fetchBacktestBars(symbol, startDate, endDate)
+		if err != nil {
+			log.Printf("Error fetching historical bars for %s: %v", symbol, err)
+			missing = append(missing, symbol)
+			continue
+		}
+		barsBySymbol[symbol] = bars
+	}
+	if len(barsBySymbol) == 0 {
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch historical data for backtest")
+		return
+	}
+
+	result, err := metrics.RunPortfolioBacktest(symbols, barsBySymbol, capital, &orderConfig)
+	if err != nil {
+		log.Printf("Error running portfolio backtest: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to execute portfolio backtest")
+		return
+	}
+
+	formattedTrades := make([]map[string]interface{}, 0)
+	for i, trade := range result.Trades {
+		formattedTrades = append(formattedTrades, map[string]interface{}{
+			"trade_num":   i + 1,
+			"symbol":      trade.Symbol,
+			"entry_price": trade.EntryPrice,
+			"exit_price":  trade.ExitPrice,
+			"entry_time":  trade.EntryTime.Format("2006-01-02"),
+			"exit_time":   trade.ExitTime.Format("2006-01-02"),
+			"pnl":         trade.PnL,
+			"return_pct":  trade.ReturnPercent,
+			"quantity":    trade.Quantity,
+		})
+	}
+
+	equityCurve := make([]map[string]interface{}, 0, len(result.EquityCurve))
+	for _, point := range result.EquityCurve {
+		equityCurve = append(equityCurve, map[string]interface{}{
+			"date":           point.Date,
+			"equity":         point.Equity,
+			"cash":           point.Cash,
+			"open_positions": point.OpenPositions,
+		})
+	}
+
+	backtestID := "portfolio_" + time.Now().Format("20060102150405")
+
+	response := map[string]interface{}{
+		"backtest_id":              backtestID,
+		"symbols":                  symbols,
+		"missing_symbols":          missing,
+		"status":                   "completed",
+		"start_date":               startDate,
+		"end_date":                 endDate,
+		"initial_capital":          capital,
+		"final_balance":            result.FinalEquity,
+		"total_return_pct":         result.TotalReturnPercent,
+		"max_drawdown_pct":         result.MaxDrawdownPercent,
+		"sharpe_ratio":             result.SharpeRatio,
+		"win_rate":                 result.WinRate,
+		"total_trades":             len(result.Trades),
+		"max_concurrent_positions": result.MaxConcurrentPositions,
+		"skipped_entries":          result.SkippedEntries,
+		"max_open_positions":       orderConfig.MaxOpenPositions,
+		"max_portfolio_percent":    orderConfig.MaxPortfolioPercent,
+		"created_at":               time.Now().Unix(),
+		"equity_curve":             equityCurve,
+		"trades":                   formattedTrades,
+	}
+
+	api.storeBacktest(r.Context(), backtestID, response)
 
 	WriteJSON(w, http.StatusOK, response)
 }
@@ -1049,11 +1714,26 @@ func (api *API) HandleBacktestResults(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Retrieve backtest results from cache using backtestID
-	api.backtestMutex.RLock()
-	results, exists := api.backtestCache[backtestID]
-	api.backtestMutex.RUnlock()
+	if job, ok := api.backtestJobStatus(backtestID); ok {
+		if job.Status == BacktestStatusFailed {
+			WriteError(w, http.StatusInternalServerError, "Backtest failed: "+job.Error)
+			return
+		}
+		WriteJSON(w, http.StatusAccepted, map[string]interface{}{
+			"backtest_id": backtestID,
+			"status":      job.Status,
+			"progress":    job.Progress,
+		})
+		return
+	}
 
+	// Retrieve backtest results from cache using backtestID
+	results, exists, err := api.lookupBacktest(r.Context(), backtestID)
+	if err != nil {
+		log.Printf("Error loading backtest %s: %v", backtestID, err)
+		WriteError(w, http.StatusInternalServerError, "Failed to load backtest results")
+		return
+	}
 	if !exists {
 		WriteError(w, http.StatusNotFound, "Backtest results not found")
 		return
@@ -1069,17 +1749,32 @@ func (api *API) HandleBacktestStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check backtest status from cache
-	api.backtestMutex.RLock()
-	results, exists := api.backtestCache[backtestID]
-	api.backtestMutex.RUnlock()
+	if job, ok := api.backtestJobStatus(backtestID); ok {
+		response := map[string]interface{}{
+			"backtest_id": backtestID,
+			"status":      job.Status,
+			"progress":    job.Progress,
+		}
+		if job.Error != "" {
+			response["error"] = job.Error
+		}
+		WriteJSON(w, http.StatusOK, response)
+		return
+	}
 
+	// Finished backtests are served from the cache
+	results, exists, err := api.lookupBacktest(r.Context(), backtestID)
+	if err != nil {
+		log.Printf("Error loading backtest %s: %v", backtestID, err)
+		WriteError(w, http.StatusInternalServerError, "Failed to load backtest status")
+		return
+	}
 	if !exists {
 		WriteError(w, http.StatusNotFound, "Backtest not found")
 		return
 	}
 
-	status := "completed"
+	status := BacktestStatusCompleted
 	if resultsStatus, ok := results["status"].(string); ok {
 		status = resultsStatus
 	}
@@ -1093,52 +1788,140 @@ func (api *API) HandleBacktestStatus(w http.ResponseWriter, r *http.Request) {
 	WriteJSON(w, http.StatusOK, response)
 }
 
+// GET /api/analysis/symbol?symbol=...&timeframe=1Day condenses the /api/watchlist/analyze analysis to RSI
+// signals, nearby support/resistance levels and the trend
 func (api *API) HandleSymbolAnalysis(w http.ResponseWriter, r *http.Request) {
 	symbol := r.URL.Query().Get("symbol")
 	if symbol == "" {
 		WriteError(w, http.StatusBadRequest, "Symbol is required")
 		return
 	}
+	symbol, assetType := resolveSymbol(symbol, r.URL.Query().Get("asset_type"))
+
+	timeframe := r.URL.Query().Get("timeframe")
+	if timeframe == "" {
+		timeframe = "1Day"
+	}
+
+	bars, err := api.fetchBars(symbol, timeframe, 250, assetType)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch market data")
+		return
+	}
+	// same 14-bar minimum AnalyzeSymbolDetailed enforces, reported as a bad request
+	if len(bars) < 14 {
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Not enough %s data to analyze %s - need at least 14 bars, got %d", timeframe, symbol, len(bars)))
+		return
+	}
+
+	analysis, err := analyzer.AnalyzeSymbolDetailed(symbol, bars)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, "Failed to analyze symbol")
+		return
+	}
+
+	// the analyzer's latest close; the fetch is latest first. The swing levels below don't depend on bar order
+	currentPrice, _ := analysis["current_price"].(float64)
+	support, _ := analysis["support_level"].(float64)
+	resistance, _ := analysis["resistance_level"].(float64)
 
-	// Return basic analysis structure, needs to be implemented
 	response := map[string]interface{}{
-		"symbol":            symbol,
-		"status":            "not_analyzed",
-		"rsi_signals":       nil,
-		"support_levels":    nil,
-		"resistance_levels": nil,
-		"trend":             "neutral",
-		"message":           "Symbol analysis not implemented yet",
+		"symbol":        symbol,
+		"timeframe":     timeframe,
+		"status":        "analyzed",
+		"current_price": currentPrice,
+		"rsi_signals": map[string]interface{}{
+			"rsi":         analysis["rsi"],
+			"signal":      analysis["rsi_signal"],
+			"fast":        analysis["rsi_fast"],
+			"slow":        analysis["rsi_slow"],
+			"fast_period": analysis["rsi_fast_period"],
+			"slow_period": analysis["rsi_slow_period"],
+			"crossover":   analysis["rsi_crossover"],
+		},
+		"support_levels":    nearestLevels(indicators.GetSupportLevels(bars), currentPrice, support, true),
+		"resistance_levels": nearestLevels(indicators.GetResistanceLevels(bars), currentPrice, resistance, false),
+		"trend":             analysis["trend"],
+		"sma_20":            analysis["sma_20"],
+		"atr":               analysis["atr"],
+		"chart_pattern":     analysis["chart_pattern"],
+		"bars_analyzed":     len(bars),
+		"timestamp":         time.Now().Unix(),
 	}
 
 	WriteJSON(w, http.StatusOK, response)
 }
 
+// most swing levels listed per side by HandleSymbolAnalysis
+const maxAnalysisLevels = 3
+
+// swing levels on the wanted side of price (below for support), nearest first and deduplicated; falls back to
+// the range extreme when price has no swing level on that side
+func nearestLevels(levels []indicators.PriceLevel, price, extreme float64, below bool) []float64 {
+	var prices []float64
+	for _, level := range levels {
+		if (below && level.Price < price) || (!below && level.Price > price) {
+			prices = append(prices, level.Price)
+		}
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return math.Abs(prices[i]-price) < math.Abs(prices[j]-price)
+	})
+
+	nearest := []float64{}
+	for _, p := range prices {
+		if len(nearest) > 0 && nearest[len(nearest)-1] == p {
+			continue
+		}
+		nearest = append(nearest, p)
+		if len(nearest) == maxAnalysisLevels {
+			break
+		}
+	}
+	if len(nearest) == 0 && extreme > 0 {
+		nearest = append(nearest, extreme)
+	}
+	return nearest
+}
+
+// GET /api/analysis/report?symbol=... combines the signal, multi-timeframe confirmation, patterns, divergence,
+// S/R quality and whale activity into one report with recommendations and a plain-English summary
 func (api *API) HandleAnalysisReport(w http.ResponseWriter, r *http.Request) {
 	symbol := r.URL.Query().Get("symbol")
 	if symbol == "" {
 		WriteError(w, http.StatusBadRequest, "Symbol is required for analysis report")
 		return
 	}
+	symbol, assetType := resolveSymbol(symbol, r.URL.Query().Get("asset_type"))
 
-	response := map[string]interface{}{
-		"symbol":          symbol,
-		"generated_at":    time.Now().Unix(),
-		"analysis":        nil,
-		"recommendations": nil,
-		"message":         "Analysis report generation not implemented yet",
+	bars, err := api.fetchBars(symbol, "1Day", 250, assetType)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch market data")
+		return
 	}
 
-	WriteJSON(w, http.StatusOK, response)
+	// the report reads oldest first and the fetch comes back latest first
+	report, err := analyzer.GenerateAnalysisReport(r.Context(), symbol, reversedBars(bars), api.Config)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, "Failed to generate analysis report")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, report)
 }
 
+// GET /api/watchlist?sort=technical orders by a stored sub-score (score, technical, news, pattern, sr) instead of the total
 func (api *API) HandleGetWatchlist(w http.ResponseWriter, r *http.Request) {
-	watchlist, err := api.Queries.GetWatchlist(r.Context())
+	watchlist, err := api.watchlistQueries().GetWatchlist(r.Context())
 	if err != nil {
 		log.Printf("Error fetching watchlist: %v", err)
 		WriteError(w, http.StatusInternalServerError, "Failed to fetch watchlist")
 		return
 	}
+	if err := sortWatchlist(watchlist, r.URL.Query().Get("sort")); err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	log.Printf("GetWatchlist returned %d items", len(watchlist))
 
@@ -1151,12 +1934,14 @@ func (api *API) HandleGetWatchlist(w http.ResponseWriter, r *http.Request) {
 	for i, item := range watchlist {
 		log.Printf("Watchlist item %d: Symbol=%s, Score=%v", i, item.Symbol, item.Score)
 		symbols[i] = map[string]interface{}{
-			"symbol":  item.Symbol,
-			"score":   item.Score,
-			"type":    item.AssetType,
-			"reason":  item.Reason,
-			"added":   item.AddedDate,
-			"updated": item.LastUpdated,
+			"symbol":         item.Symbol,
+			"display_symbol": utils.DisplaySymbol(item.Symbol, item.AssetType),
+			"score":          item.Score,
+			"type":           item.AssetType,
+			"reason":         item.Reason,
+			"added":          item.AddedDate,
+			"updated":        item.LastUpdated,
+			"breakdown":      rowBreakdown(item),
 		}
 	}
 
@@ -1172,9 +1957,10 @@ func (api *API) HandleGetWatchlist(w http.ResponseWriter, r *http.Request) {
 
 func (api *API) HandleAddToWatchlist(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Symbol string  `json:"symbol"`
-		Score  float64 `json:"score"`
-		Reason string  `json:"reason"`
+		Symbol    string  `json:"symbol"`
+		AssetType string  `json:"asset_type"` // stock (default) or crypto, inferred from BTC/USD style pairs
+		Score     float64 `json:"score"`
+		Reason    string  `json:"reason"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -1186,9 +1972,11 @@ func (api *API) HandleAddToWatchlist(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, "Symbol is required")
 		return
 	}
+	var assetType string
+	req.Symbol, assetType = resolveSymbol(req.Symbol, req.AssetType)
 
 	// Validate that the stock exists by fetching asset info from Alpaca
-	asset, err := api.AlpacaClient.GetAsset(req.Symbol)
+	asset, err := api.alpacaClient(r).GetAsset(req.Symbol)
 	if err != nil {
 		log.Printf("Warning: Stock validation failed for %s: %v", req.Symbol, err)
 		// Continue anyway - validation is optional, log the error for debugging
@@ -1201,9 +1989,10 @@ func (api *API) HandleAddToWatchlist(w http.ResponseWriter, r *http.Request) {
 
 	// Calculate actual score using metrics
 	calculatedScore := req.Score // Default to provided score
+	var breakdown *types.ScoreBreakdown
 
 	// Fetch bars and calculate real metrics
-	bars, err := datafeed.GetAlpacaBars(req.Symbol, "1Day", 100, "")
+	bars, err := api.fetchBars(req.Symbol, "1Day", 100, assetType)
 	if err == nil && len(bars) > 0 {
 		// Load config for weights
 		cfg, cfgErr := config.LoadConfig()
@@ -1215,6 +2004,7 @@ func (api *API) HandleAddToWatchlist(w http.ResponseWriter, r *http.Request) {
 			candidate, metricsErr := analyzer.CalculateCandidateMetrics(r.Context(), req.Symbol, bars, cfg, weights)
 			if metricsErr == nil && candidate != nil {
 				calculatedScore = candidate.Score
+				breakdown = candidate.Breakdown
 				log.Printf("Calculated score for %s: %.2f", req.Symbol, calculatedScore)
 			} else {
 				log.Printf("Warning: Could not calculate metrics for %s: %v", req.Symbol, metricsErr)
@@ -1228,7 +2018,7 @@ func (api *API) HandleAddToWatchlist(w http.ResponseWriter, r *http.Request) {
 
 	params := database.AddToWatchlistParams{
 		Symbol:    req.Symbol,
-		AssetType: "stock",
+		AssetType: assetType,
 		Score:     float32(calculatedScore),
 		Reason: sql.NullString{
 			String: req.Reason,
@@ -1236,7 +2026,7 @@ func (api *API) HandleAddToWatchlist(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	watchlistID, err := api.Queries.AddToWatchlist(r.Context(), params)
+	watchlistID, err := api.watchlistQueries().AddToWatchlist(r.Context(), params)
 	if err != nil {
 		log.Printf("Error adding to watchlist: %v", err)
 
@@ -1257,12 +2047,21 @@ func (api *API) HandleAddToWatchlist(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if breakdown != nil {
+		if err := api.watchlistQueries().UpdateWatchlistScoreBreakdown(r.Context(), breakdownParams(req.Symbol, *breakdown)); err != nil {
+			log.Printf("Warning: could not store score breakdown for %s: %v", req.Symbol, err)
+		}
+	}
+
 	response := map[string]interface{}{
-		"success":      true,
-		"watchlist_id": watchlistID,
-		"symbol":       req.Symbol,
-		"score":        calculatedScore,
-		"message":      "Symbol added to watchlist",
+		"success":        true,
+		"watchlist_id":   watchlistID,
+		"symbol":         req.Symbol,
+		"display_symbol": utils.DisplaySymbol(req.Symbol, assetType),
+		"asset_type":     assetType,
+		"score":          calculatedScore,
+		"breakdown":      breakdown,
+		"message":        "Symbol added to watchlist",
 	}
 
 	WriteJSON(w, http.StatusCreated, response)
@@ -1288,6 +2087,7 @@ func (api *API) HandleRemoveFromWatchlist(w http.ResponseWriter, r *http.Request
 		WriteError(w, http.StatusBadRequest, "Symbol is required")
 		return
 	}
+	symbol = utils.NormalizeSymbol(symbol, "")
 
 	log.Printf("DEBUG: Attempting to remove symbol '%s' from watchlist", symbol)
 	err := api.Queries.RemoveFromWatchlist(r.Context(), symbol)
@@ -1307,14 +2107,23 @@ func (api *API) HandleRemoveFromWatchlist(w http.ResponseWriter, r *http.Request
 	WriteJSON(w, http.StatusOK, response)
 }
 
+// PUT /api/watchlist/refresh-scores?symbols=AAPL,MSFT&max_age=6h&force=true recomputes scores;
+// symbols limits the refresh to those rows and max_age skips rows updated more recently, unless force is set
 func (api *API) HandleRefreshWatchlistScores(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseWatchlistRefreshFilter(r.URL.Query())
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Get all watchlist items
-	watchlist, err := api.Queries.GetWatchlist(r.Context())
+	all, err := api.watchlistQueries().GetWatchlist(r.Context())
 	if err != nil {
 		log.Printf("Error fetching watchlist: %v", err)
 		WriteError(w, http.StatusInternalServerError, "Failed to fetch watchlist")
 		return
 	}
+	watchlist, skipped := filter.selectRows(all, time.Now())
 
 	// Load config for weights
 	cfg, err := config.LoadConfig()
@@ -1335,7 +2144,7 @@ func (api *API) HandleRefreshWatchlistScores(w http.ResponseWriter, r *http.Requ
 		symbol := item.Symbol
 
 		// Fetch bars
-		bars, err := datafeed.GetAlpacaBars(symbol, "1Day", 100, "")
+		bars, err := api.fetchBarsWithRetry(symbol, "1Day", 100, utils.DetectAssetType(symbol, item.AssetType))
 		if err != nil || len(bars) == 0 {
 			log.Printf("Failed to fetch bars for %s: %v", symbol, err)
 			failed++
@@ -1399,6 +2208,7 @@ func (api *API) HandleRefreshWatchlistScores(w http.ResponseWriter, r *http.Requ
 
 		scoringInput, _ := scoring.BuildScoringInput(bars, vwapValue, rsiValue, whaleCount, atrValue, atrCategory)
 		score := detection.CalculateInterestScore(scoringInput, weights)
+		breakdown := analyzer.CalculateScoreBreakdown(scoringInput, weights, bars)
 
 		// Update the score in database
 		updateParams := database.UpdateWatchlistScoreParams{
@@ -1406,7 +2216,7 @@ func (api *API) HandleRefreshWatchlistScores(w http.ResponseWriter, r *http.Requ
 			Score:  float32(score),
 		}
 
-		err = api.Queries.UpdateWatchlistScore(r.Context(), updateParams)
+		err = api.watchlistQueries().UpdateWatchlistScore(r.Context(), updateParams)
 		if err != nil {
 			log.Printf("Failed to update score for %s: %v", symbol, err)
 			failed++
@@ -1418,12 +2228,17 @@ func (api *API) HandleRefreshWatchlistScores(w http.ResponseWriter, r *http.Requ
 			continue
 		}
 
+		if err := api.watchlistQueries().UpdateWatchlistScoreBreakdown(r.Context(), breakdownParams(symbol, breakdown)); err != nil {
+			log.Printf("Warning: could not store score breakdown for %s: %v", symbol, err)
+		}
+
 		updated++
 		results = append(results, map[string]interface{}{
 			"symbol":    symbol,
 			"status":    "updated",
 			"old_score": item.Score,
 			"new_score": score,
+			"breakdown": breakdown,
 		})
 
 		log.Printf("Updated score for %s: %.2f -> %.2f", symbol, item.Score, score)
@@ -1434,8 +2249,9 @@ func (api *API) HandleRefreshWatchlistScores(w http.ResponseWriter, r *http.Requ
 		"total":   len(watchlist),
 		"updated": updated,
 		"failed":  failed,
+		"skipped": skipped,
 		"results": results,
-		"message": fmt.Sprintf("Refreshed scores: %d updated, %d failed", updated, failed),
+		"message": fmt.Sprintf("Refreshed scores: %d updated, %d failed, %d skipped as fresh", updated, failed, len(skipped)),
 	}
 
 	WriteJSON(w, http.StatusOK, response)
@@ -1447,25 +2263,81 @@ func (api *API) HandleAnalyzeSymbol(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, "Symbol parameter is required")
 		return
 	}
+	symbol, assetType := resolveSymbol(symbol, r.URL.Query().Get("asset_type"))
 
-	bars, err := datafeed.GetAlpacaBars(symbol, "1Day", 250, "")
+	bars, err := api.fetchBars(symbol, "1Day", 250, assetType)
 	if err != nil {
-		log.Printf("Error fetching bars for %s: %v", symbol, err)
-		WriteError(w, http.StatusInternalServerError, "Failed to fetch market data")
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch market data")
 		return
 	}
 
 	// Delegate detailed analysis to analyzer package
 	response, err := analyzer.AnalyzeSymbolDetailed(symbol, bars)
 	if err != nil {
-		log.Printf("Error analyzing symbol %s: %v", symbol, err)
-		WriteError(w, http.StatusBadRequest, err.Error())
+		writeServiceError(w, err, http.StatusBadRequest, "Failed to analyze symbol")
 		return
 	}
 
+	// skip_mtf=true keeps the single-timeframe path and its one bars request
+	if r.URL.Query().Get("skip_mtf") == "true" {
+		response["multi_timeframe"] = map[string]interface{}{"skipped": true}
+	} else {
+		response["multi_timeframe"] = api.multiTimeframeAnalysis(symbol, assetType, bars)
+	}
+
 	WriteJSON(w, http.StatusOK, response)
 }
 
+// the daily, 4H and 1H signals from interactive.FetchMultiTimeframeSignalsWith, reusing the daily bars the
+// handler already has. A timeframe that fails leaves a partial result with a warning; only when all three fail
+// is the result unavailable
+func (api *API) multiTimeframeAnalysis(symbol, assetType string, dailyBars []types.Bar) map[string]interface{} {
+	fetch := func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+		if timeframe == signals.TimeframeDaily && len(dailyBars) >= limit {
+			return dailyBars[:limit], nil
+		}
+		return api.fetchBars(symbol, timeframe, limit, assetType)
+	}
+	multi, failures := interactive.FetchMultiTimeframeSignalsWith(symbol, assetType, fetch)
+
+	failed := []string{}
+	messages := []string{}
+	for _, timeframe := range []string{signals.TimeframeDaily, signals.TimeframeFourHour, signals.TimeframeOneHour} {
+		if err, ok := failures[timeframe]; ok {
+			log.Printf("Warning: multi-timeframe %s for %s: %v", timeframe, symbol, err)
+			failed = append(failed, timeframe)
+			messages = append(messages, err.Error())
+		}
+	}
+	if multi == nil {
+		return map[string]interface{}{
+			"available":         false,
+			"failed_timeframes": failed,
+			"warning":           "Multi-timeframe analysis unavailable: " + strings.Join(messages, "; "),
+		}
+	}
+
+	result := map[string]interface{}{
+		"available":          true,
+		"daily":              multi.DailySignal.Recommendation,
+		"four_hour":          multi.FourHourSignal.Recommendation,
+		"one_hour":           multi.OneHourSignal.Recommendation,
+		"aligned":            multi.Alignment,
+		"alignment_percent":  multi.AlignmentPercent,
+		"composite_score":    multi.CompositeScore,
+		"confidence":         multi.Confidence,
+		"recommended_trade":  multi.RecommendedTrade,
+		"primary_timeframe":  multi.PrimaryTimeframe,
+		"confirmed":          multi.IsMultiTimeframeConfirmed(true),
+		"confirmed_moderate": multi.IsMultiTimeframeConfirmed(false),
+		"failed_timeframes":  failed,
+	}
+	if len(failed) > 0 {
+		result["warning"] = "Partial multi-timeframe analysis: " + strings.Join(messages, "; ")
+	}
+	return result
+}
+
 func (api *API) HandleScoutStocks(w http.ResponseWriter, r *http.Request) {
 	limitStr := r.URL.Query().Get("limit")
 	limit := 100
@@ -1491,26 +2363,48 @@ func (api *API) HandleScoutStocks(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	minGrade := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("min_grade")))
+	if minGrade != "" && !signals.ValidGrade(minGrade) {
+		WriteError(w, http.StatusBadRequest, "min_grade must be one of A, B, C, D or F")
+		return
+	}
+
 	log.Printf("Scanning stocks with min score %.1f (limit=%d, offset=%d)", minScore, limit, offset)
 	ctx := context.Background()
 
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Warning: Could not load config for scout, using defaults: %v", err)
+		cfg = nil
+	}
+
 	// Delegate to scanner package
-	candidates, totalScanned, err := scanner.PerformProfileScan(ctx, "api_scout", minScore, offset, limit, nil)
+	candidates, totalScanned, skips, err := scanner.PerformProfileScanWithSummary(ctx, "api_scout", minScore, offset, limit, cfg)
 	if err != nil {
-		log.Printf("SCANNER ERROR: %v", err)
-		WriteError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err, http.StatusInternalServerError, "Scan failed")
 		return
 	}
 
 	log.Printf("SCAN COMPLETE: Got %d results from %d total symbols, limit was %d", len(candidates), totalScanned, limit)
 
 	// Sort candidates by score (highest first)
-	sort.SliceStable(candidates, func(i, j int) bool {
-		return candidates[i].Score > candidates[j].Score
-	})
+	scanner.RankCandidates(candidates)
+	if minGrade != "" {
+		candidates = scanner.FilterByGrade(candidates, minGrade)
+	}
 
 	// Format results using scanner package
 	response := scanner.FormatScoutResults(candidates, totalScanned, limit, minScore)
+	response["skip_summary"] = skips
+	if minGrade != "" {
+		response["min_grade"] = minGrade
+	}
+
+	// the regime lookup is cached, so this reuses the one applied during the scan
+	if regime, err := scanner.LoadMarketRegime(cfg); err == nil && regime != nil {
+		response["market_regime"] = regime
+	}
+
 	WriteJSON(w, http.StatusOK, response)
 }
 
@@ -1547,6 +2441,20 @@ func (api *API) HandleGetSettings(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// HandleGetSettingsSchema describes every config.yaml setting with its type, default and current value
+func (api *API) HandleGetSettingsSchema(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Error loading config: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to load config")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"schema": config.BuildSchema(cfg),
+	})
+}
+
 // HandleUpdateSettings updates settings for the current user
 func (api *API) HandleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -1574,6 +2482,11 @@ func (api *API) HandleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 		log.Printf("[Settings] Trading settings saved successfully")
 	}
 
+	var levelChanges []position.LevelChange
+	if payload.Trading != nil && (payload.Trading.StopLossPercent > 0 || payload.Trading.TakeProfitPercent > 0) {
+		levelChanges = api.applyExitPercents(payload.Trading.StopLossPercent, payload.Trading.TakeProfitPercent)
+	}
+
 	// Update API settings
 	if payload.API != nil {
 		if payload.API.AlpacaKey != "" {
@@ -1605,10 +2518,41 @@ func (api *API) HandleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	response := map[string]string{
+	response := map[string]interface{}{
 		"message": "Settings updated successfully",
 	}
+	if levelChanges != nil {
+		response["level_changes"] = levelChanges
+	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+// swaps in new stop/take-profit percents (0 keeps the current one); the position manager recalculates open
+// positions' levels when features.recalculate_levels_on_config_change is on
+func (api *API) applyExitPercents(stopLossPercent, takeProfitPercent float64) []position.LevelChange {
+	current := api.OrderConfig.Snapshot()
+	if current == nil {
+		return nil
+	}
+	next := *current
+	if stopLossPercent > 0 {
+		next.StopLossPercent = stopLossPercent
+	}
+	if takeProfitPercent > 0 {
+		next.TakeProfitPercent = takeProfitPercent
+	}
+	log.Printf("[Settings] Order config: stop loss %.2f%% -> %.2f%%, take profit %.2f%% -> %.2f%%",
+		current.StopLossPercent, next.StopLossPercent, current.TakeProfitPercent, next.TakeProfitPercent)
+
+	if api.PositionManager == nil {
+		api.OrderConfig.Replace(next)
+		return nil
+	}
+	changes := api.PositionManager.ApplyOrderConfig(next)
+	if changes == nil {
+		changes = []position.LevelChange{}
+	}
+	return changes
+}
@@ -0,0 +1,511 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/handlers/risk"
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/strategy/position"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+	"github.com/shopspring/decimal"
+)
+
+func TestConvertToTradeResults_DecimalPnL(t *testing.T) {
+	trades := []database.GetAllTradesRow{
+		{Symbol: "AAPL", Side: "buy", Price: "10.00", Quantity: "3"},
+		{Symbol: "AAPL", Side: "sell", Price: "10.045", Quantity: "3"},
+	}
+
+	results := convertToTradeResults(trades)
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 completed trade, got %d", len(results))
+	}
+
+	// exact P&L is 0.135; float math produces 0.13499999... and rounds to 0.13
+	if results[0].PnL != 0.14 {
+		t.Errorf("PnL = %v, want 0.14", results[0].PnL)
+	}
+	if results[0].ReturnPercent != 0.45 {
+		t.Errorf("ReturnPercent = %v, want 0.45", results[0].ReturnPercent)
+	}
+}
+
+func TestPairTradeRows_RoundsOnlyTheTotal(t *testing.T) {
+	var trades []database.GetAllTradesRow
+	for range 10 {
+		trades = append(trades,
+			database.GetAllTradesRow{Symbol: "AAPL", Side: "buy", Price: "10.00", Quantity: "3"},
+			database.GetAllTradesRow{Symbol: "AAPL", Side: "sell", Price: "10.045", Quantity: "3"},
+		)
+	}
+
+	results, realized := pairTradeRows(trades)
+	if len(results) != 10 {
+		t.Fatalf("Expected 10 completed trades, got %d", len(results))
+	}
+	// ten 0.135 trades; summing the rounded 0.14s would give 1.40
+	if !realized.Equal(decimal.RequireFromString("1.35")) {
+		t.Errorf("realized = %s, want 1.35", realized)
+	}
+}
+
+func TestConvertToTradeResults_PairsImportedSides(t *testing.T) {
+	// imported history and logged exits store the side upper-case
+	trades := []database.GetAllTradesRow{
+		{Symbol: "AAPL", Side: "BUY", Price: "100", Quantity: "2"},
+		{Symbol: "AAPL", Side: "SELL", Price: "110", Quantity: "2"},
+		{Symbol: "MSFT", Side: "buy", Price: "50", Quantity: "1"},
+		{Symbol: "MSFT", Side: "SELL", Price: "55", Quantity: "1"},
+	}
+
+	results := convertToTradeResults(trades)
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 completed trades, got %d", len(results))
+	}
+}
+
+func TestSymbolStatsResponse_MultipleSymbols(t *testing.T) {
+	at := func(day, hour int) sql.NullTime {
+		return sql.NullTime{Time: time.Date(2024, 1, day, hour, 0, 0, 0, time.UTC), Valid: true}
+	}
+	trades := []database.GetAllTradesRow{
+		// AAPL: two winners (+20, +10), held 2h and 4h
+		{Symbol: "AAPL", Side: "buy", Price: "100", Quantity: "2", CreatedAt: at(1, 10)},
+		{Symbol: "AAPL", Side: "sell", Price: "110", Quantity: "2", CreatedAt: at(1, 12)},
+		{Symbol: "AAPL", Side: "buy", Price: "100", Quantity: "1", CreatedAt: at(2, 10)},
+		{Symbol: "AAPL", Side: "sell", Price: "110", Quantity: "1", CreatedAt: at(2, 14)},
+		// TSLA: one winner (+5), one loser (-30)
+		{Symbol: "TSLA", Side: "buy", Price: "200", Quantity: "1", CreatedAt: at(1, 10)},
+		{Symbol: "TSLA", Side: "sell", Price: "205", Quantity: "1", CreatedAt: at(1, 11)},
+		{Symbol: "TSLA", Side: "buy", Price: "200", Quantity: "1", CreatedAt: at(3, 10)},
+		{Symbol: "TSLA", Side: "sell", Price: "170", Quantity: "1", CreatedAt: at(3, 11)},
+		// MSFT: a single small winner
+		{Symbol: "MSFT", Side: "buy", Price: "50", Quantity: "1", CreatedAt: at(1, 10)},
+		{Symbol: "MSFT", Side: "sell", Price: "51", Quantity: "1", CreatedAt: at(1, 11)},
+	}
+	results := convertToTradeResults(trades)
+
+	symbols := symbolStatsResponse(results, 1, 0)
+	if len(symbols) != 3 {
+		t.Fatalf("Expected 3 symbols, got %d", len(symbols))
+	}
+	order := []string{symbols[0]["symbol"].(string), symbols[1]["symbol"].(string), symbols[2]["symbol"].(string)}
+	if order[0] != "AAPL" || order[1] != "MSFT" || order[2] != "TSLA" {
+		t.Errorf("Order = %v, want [AAPL MSFT TSLA] by net P&L", order)
+	}
+
+	aapl := symbols[0]
+	if aapl["net_pnl"] != 30.0 || aapl["win_rate"] != 100.0 || aapl["trade_count"] != 2 {
+		t.Errorf("Unexpected AAPL stats: %v", aapl)
+	}
+	if aapl["avg_hold_hours"] != 3.0 || aapl["largest_win"] != 20.0 {
+		t.Errorf("Unexpected AAPL hold/largest win: %v", aapl)
+	}
+
+	tsla := symbols[2]
+	if tsla["net_pnl"] != -25.0 || tsla["win_rate"] != 50.0 || tsla["largest_loss"] != -30.0 || tsla["largest_win"] != 5.0 {
+		t.Errorf("Unexpected TSLA stats: %v", tsla)
+	}
+
+	// min_trades drops MSFT, limit keeps only the best remaining symbol
+	filtered := symbolStatsResponse(results, 2, 1)
+	if len(filtered) != 1 || filtered[0]["symbol"] != "AAPL" {
+		t.Errorf("Expected only AAPL after min_trades=2 limit=1, got %v", filtered)
+	}
+}
+
+func TestHandleExecuteTrade_RejectsInvalidOrderOptions(t *testing.T) {
+	api := &API{}
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"unknown time in force", `{"symbol":"AAPL","side":"buy","quantity":1,"time_in_force":"moc"}`},
+		{"limit without price", `{"symbol":"AAPL","side":"buy","quantity":1,"type":"limit","time_in_force":"cls"}`},
+		{"fractional on close", `{"symbol":"AAPL","side":"buy","quantity":1.5,"time_in_force":"cls"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/execute-trade", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			api.HandleExecuteTrade(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+// counts orders so a test can assert nothing reached the broker
+type orderCountingClient struct {
+	slowTradingClient
+	placed *int
+	held   []alpaca.Position
+}
+
+func (c orderCountingClient) GetPositions() ([]alpaca.Position, error) {
+	return c.held, nil
+}
+
+func (c orderCountingClient) PlaceOrder(req alpaca.PlaceOrderRequest) (*alpaca.Order, error) {
+	*c.placed++
+	return &alpaca.Order{ID: "order-1", Symbol: req.Symbol, Qty: req.Qty}, nil
+}
+
+func TestHandleExecuteTrade_DataFreshness(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.DataFreshness.Enabled = true
+	cfg.DataFreshness.MaxBarAgeMinutes = 30
+
+	tests := []struct {
+		name       string
+		barAge     time.Duration
+		wantStatus int
+		wantOrders int
+	}{
+		{"fresh bar", time.Hour, http.StatusCreated, 1},
+		{"day-old bar", 2 * 24 * time.Hour, http.StatusConflict, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placed := 0
+			barTime := time.Now().Add(-tt.barAge).UTC().Format(time.RFC3339)
+			olderTime := time.Now().Add(-tt.barAge - 4*24*time.Hour).UTC().Format(time.RFC3339)
+			api := &API{
+				AlpacaClient: orderCountingClient{placed: &placed},
+				Config:       cfg,
+				bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+					// latest first, the way the fetchers return them
+					return []types.Bar{{Timestamp: barTime, Close: 60000}, {Timestamp: olderTime, Close: 59000}}, nil
+				},
+			}
+
+			// crypto is checked around the clock, so the test doesn't depend on market hours
+			body := `{"symbol":"BTC/USD","side":"buy","quantity":0.1}`
+			req := httptest.NewRequest(http.MethodPost, "/api/execute-trade", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+			api.HandleExecuteTrade(rec, req)
+
+			if rec.Code != tt.wantStatus || placed != tt.wantOrders {
+				t.Errorf("status = %d with %d orders placed, want %d with %d: %s", rec.Code, placed, tt.wantStatus, tt.wantOrders, rec.Body.String())
+			}
+		})
+	}
+}
+
+// a fixed buying power that records the quantity of each order placed
+type buyingPowerClient struct {
+	slowTradingClient
+	buyingPower float64
+	placedQty   *[]string
+	held        []alpaca.Position
+}
+
+func (c buyingPowerClient) GetPositions() ([]alpaca.Position, error) {
+	return c.held, nil
+}
+
+func (c buyingPowerClient) GetAccount() (*alpaca.Account, error) {
+	return &alpaca.Account{BuyingPower: decimal.NewFromFloat(c.buyingPower)}, nil
+}
+
+func (c buyingPowerClient) PlaceOrder(req alpaca.PlaceOrderRequest) (*alpaca.Order, error) {
+	*c.placedQty = append(*c.placedQty, req.Qty.String())
+	return &alpaca.Order{ID: "order-1", Symbol: req.Symbol, Qty: req.Qty}, nil
+}
+
+func TestHandleExecuteTrade_BuyingPower(t *testing.T) {
+	tests := []struct {
+		name       string
+		resize     bool
+		body       string
+		wantStatus int
+		wantQty    []string
+	}{
+		{"fits", false, `{"symbol":"AAPL","side":"buy","quantity":40}`, http.StatusCreated, []string{"40"}},
+		{"exceeds and rejected", false, `{"symbol":"AAPL","side":"buy","quantity":80}`, http.StatusUnprocessableEntity, nil},
+		{"exceeds and resized", true, `{"symbol":"AAPL","side":"buy","quantity":80}`, http.StatusCreated, []string{"49"}},
+		{"limit price used", false, `{"symbol":"AAPL","side":"buy","quantity":80,"type":"limit","limit_price":50}`, http.StatusCreated, []string{"80"}},
+		{"short sale checked", false, `{"symbol":"MSFT","side":"sell","quantity":80}`, http.StatusUnprocessableEntity, nil},
+		{"exits not checked", false, `{"symbol":"AAPL","side":"sell","quantity":80}`, http.StatusCreated, []string{"80"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.BuyingPower = config.BuyingPowerConfig{Enabled: true, ResizeToFit: tt.resize, ReservePercent: 1}
+			var placed []string
+			api := &API{
+				AlpacaClient: buyingPowerClient{buyingPower: 5000, placedQty: &placed, held: []alpaca.Position{
+					{Symbol: "AAPL", Side: "long", Qty: decimal.NewFromInt(100)},
+				}},
+				Config: cfg,
+				bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+					return []types.Bar{{Close: 100}}, nil
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/execute-trade", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			api.HandleExecuteTrade(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if strings.Join(placed, ",") != strings.Join(tt.wantQty, ",") {
+				t.Errorf("placed quantities = %v, want %v", placed, tt.wantQty)
+			}
+			if tt.wantStatus == http.StatusUnprocessableEntity && !strings.Contains(rec.Body.String(), "buying power") {
+				t.Errorf("rejection should explain the buying power shortfall: %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+// reports every symbol's earnings daysAway days from now, or fails every lookup when err is set
+type fixedEarningsCalendar struct {
+	daysAway int
+	err      error
+}
+
+func (c fixedEarningsCalendar) NextEarnings(symbol string, from, to time.Time) (time.Time, bool, error) {
+	if c.err != nil {
+		return time.Time{}, false, c.err
+	}
+	return time.Now().AddDate(0, 0, c.daysAway), true, nil
+}
+
+func TestHandleExecuteTrade_EarningsWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		calendar   fixedEarningsCalendar
+		symbol     string
+		side       string
+		wantStatus int
+		wantOrders int
+		wantNote   bool
+	}{
+		{"earnings tomorrow blocks a buy", true, fixedEarningsCalendar{daysAway: 1}, "MSFT", "buy", http.StatusConflict, 0, false},
+		{"earnings tomorrow with the toggle off", false, fixedEarningsCalendar{daysAway: 1}, "MSFT", "buy", http.StatusCreated, 1, false},
+		{"earnings tomorrow never blocks an exit", true, fixedEarningsCalendar{daysAway: 1}, "AAPL", "sell", http.StatusCreated, 1, false},
+		{"earnings tomorrow blocks a short sale", true, fixedEarningsCalendar{daysAway: 1}, "MSFT", "sell", http.StatusConflict, 0, false},
+		{"earnings after the window", true, fixedEarningsCalendar{daysAway: 10}, "MSFT", "buy", http.StatusCreated, 1, false},
+		{"calendar unavailable", true, fixedEarningsCalendar{err: errors.New("FINNHUB_API_KEY not set in environment")}, "MSFT", "buy", http.StatusCreated, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Earnings = config.EarningsConfig{Enabled: tt.enabled, AvoidDays: 3}
+			placed := 0
+			api := &API{
+				AlpacaClient: orderCountingClient{placed: &placed, held: []alpaca.Position{
+					{Symbol: "AAPL", Side: "long", Qty: decimal.NewFromInt(5)},
+				}},
+				Config:   cfg,
+				earnings: tt.calendar,
+			}
+
+			body := `{"symbol":"` + tt.symbol + `","side":"` + tt.side + `","quantity":5}`
+			rec := httptest.NewRecorder()
+			api.HandleExecuteTrade(rec, httptest.NewRequest(http.MethodPost, "/api/execute-trade", strings.NewReader(body)))
+
+			if rec.Code != tt.wantStatus || placed != tt.wantOrders {
+				t.Fatalf("status %d with %d orders, want %d with %d: %s", rec.Code, placed, tt.wantStatus, tt.wantOrders, rec.Body.String())
+			}
+			if got := strings.Contains(rec.Body.String(), "earnings_note"); got != tt.wantNote {
+				t.Errorf("earnings_note present = %v, want %v: %s", got, tt.wantNote, rec.Body.String())
+			}
+		})
+	}
+}
+
+// holds the symbols in *held and opens a position for every buy it places
+type sectorHeldClient struct {
+	slowTradingClient
+	held   *[]string
+	placed *int
+}
+
+func (c sectorHeldClient) GetPositions() ([]alpaca.Position, error) {
+	positions := make([]alpaca.Position, len(*c.held))
+	for i, symbol := range *c.held {
+		positions[i] = alpaca.Position{Symbol: symbol}
+	}
+	return positions, nil
+}
+
+func (c sectorHeldClient) PlaceOrder(req alpaca.PlaceOrderRequest) (*alpaca.Order, error) {
+	*c.placed++
+	if req.Side == alpaca.Buy {
+		*c.held = append(*c.held, req.Symbol)
+	}
+	return &alpaca.Order{ID: "order-1", Symbol: req.Symbol, Qty: req.Qty}, nil
+}
+
+func TestHandleExecuteTrade_SectorLimit(t *testing.T) {
+	var held []string
+	placed := 0
+	rm := risk.NewManager(nil, 100000)
+	rm.SetSectorResolver(risk.NewSectorResolver(nil))
+	api := &API{
+		AlpacaClient: sectorHeldClient{held: &held, placed: &placed},
+		RiskManager:  rm,
+	}
+	buy := func(symbol string) *httptest.ResponseRecorder {
+		body := `{"symbol":"` + symbol + `","side":"buy","quantity":1}`
+		rec := httptest.NewRecorder()
+		api.HandleExecuteTrade(rec, httptest.NewRequest(http.MethodPost, "/api/execute-trade", strings.NewReader(body)))
+		return rec
+	}
+
+	for _, symbol := range []string{"AAPL", "MSFT", "NVDA"} {
+		if rec := buy(symbol); rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"sector":"TECHNOLOGY"`) {
+			t.Fatalf("buy %s = %d: %s", symbol, rec.Code, rec.Body.String())
+		}
+	}
+	rec := buy("AMD")
+	if rec.Code != http.StatusConflict || placed != 3 {
+		t.Fatalf("4th tech buy = %d with %d orders, want 409 with 3: %s", rec.Code, placed, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "Sector limit") {
+		t.Errorf("rejection should name the sector limit: %s", rec.Body.String())
+	}
+
+	// another sector and an unresolved symbol still go through
+	if rec := buy("XOM"); rec.Code != http.StatusCreated {
+		t.Errorf("buy XOM = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := buy("ZZZZ"); rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"sector":"UNKNOWN"`) {
+		t.Errorf("buy ZZZZ = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// MSFT closes outside this handler (a stop, an OCO leg, the EOD flatten); its slot is free for AMD
+	held = []string{"AAPL", "NVDA", "XOM", "ZZZZ"}
+	if rec := buy("AMD"); rec.Code != http.StatusCreated {
+		t.Errorf("buy AMD after MSFT closed = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := buy("MSFT"); rec.Code != http.StatusConflict {
+		t.Errorf("reopening MSFT with three tech positions held = %d, want 409", rec.Code)
+	}
+}
+
+// holds fixed broker positions and rejects orders while *reject is set
+type throttledOrderClient struct {
+	slowTradingClient
+	held   []alpaca.Position
+	reject *bool
+	placed *int
+}
+
+func (c throttledOrderClient) GetPositions() ([]alpaca.Position, error) {
+	return c.held, nil
+}
+
+func (c throttledOrderClient) PlaceOrder(req alpaca.PlaceOrderRequest) (*alpaca.Order, error) {
+	if *c.reject {
+		return nil, errors.New("insufficient qty available for order")
+	}
+	*c.placed++
+	return &alpaca.Order{ID: "order-1", Symbol: req.Symbol, Qty: req.Qty}, nil
+}
+
+func TestHandleExecuteTrade_ThrottlesEntriesByHeldPosition(t *testing.T) {
+	reject := false
+	placed := 0
+	pm := position.NewPositionManager(nil, &strategy.OrderConfig{})
+	pm.SetEntryThrottle(position.NewEntryThrottle(1, 0, nil))
+	api := &API{
+		AlpacaClient: throttledOrderClient{
+			held: []alpaca.Position{
+				{Symbol: "TSLA", Side: "short", Qty: decimal.NewFromInt(-5)},
+				{Symbol: "AAPL", Side: "long", Qty: decimal.NewFromInt(10)},
+			},
+			reject: &reject,
+			placed: &placed,
+		},
+		PositionManager: pm,
+	}
+	trade := func(symbol, side string) int {
+		body := `{"symbol":"` + symbol + `","side":"` + side + `","quantity":1}`
+		rec := httptest.NewRecorder()
+		api.HandleExecuteTrade(rec, httptest.NewRequest(http.MethodPost, "/api/execute-trade", strings.NewReader(body)))
+		return rec.Code
+	}
+
+	// a rejected entry hands its slot back
+	reject = true
+	if code := trade("NVDA", "sell"); code != http.StatusInternalServerError {
+		t.Fatalf("rejected short entry = %d, want 500", code)
+	}
+	reject = false
+
+	// a sell while flat opens a short and takes the one slot
+	if code := trade("NVDA", "sell"); code != http.StatusCreated {
+		t.Fatalf("short entry = %d, want 201", code)
+	}
+	if code := trade("MSFT", "buy"); code != http.StatusTooManyRequests {
+		t.Errorf("second entry within the hour = %d, want 429", code)
+	}
+	if code := trade("AMD", "sell"); code != http.StatusTooManyRequests {
+		t.Errorf("second short entry within the hour = %d, want 429", code)
+	}
+
+	// covering the TSLA short and selling the AAPL long are exits and never throttled
+	if code := trade("TSLA", "buy"); code != http.StatusCreated {
+		t.Errorf("buy to cover = %d, want 201", code)
+	}
+	if code := trade("AAPL", "sell"); code != http.StatusCreated {
+		t.Errorf("long exit = %d, want 201", code)
+	}
+	if placed != 3 {
+		t.Errorf("orders placed = %d, want 3", placed)
+	}
+}
+
+func TestHandleAnalyzeSymbol_ReadsLatestFirstFetch(t *testing.T) {
+	// the fetchers return bars latest first: 60 rising closes from 100 to 129.5
+	api := &API{
+		bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+			return reversedBars(trendingBars(60, 100, 0.5)), nil
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	api.HandleAnalyzeSymbol(rec, httptest.NewRequest(http.MethodGet, "/api/analyze?symbol=AAPL&skip_mtf=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		CurrentPrice   float64 `json:"current_price"`
+		Trend          string  `json:"trend"`
+		RSISignal      string  `json:"rsi_signal"`
+		HistoricalBars []struct {
+			Close     float64 `json:"close"`
+			Timestamp int64   `json:"timestamp"`
+		} `json:"historical_bars"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if body.CurrentPrice != 129.5 || body.Trend != "bullish" || body.RSISignal != "overbought" {
+		t.Errorf("current/trend/rsi = %.2f/%s/%s, want 129.50/bullish/overbought", body.CurrentPrice, body.Trend, body.RSISignal)
+	}
+	chart := body.HistoricalBars
+	if len(chart) != 60 || chart[0].Close != 100 || chart[59].Close != 129.5 || chart[0].Timestamp >= chart[59].Timestamp {
+		t.Errorf("chart series should run oldest to newest, got %d bars from %.2f to %.2f", len(chart), chart[0].Close, chart[len(chart)-1].Close)
+	}
+}
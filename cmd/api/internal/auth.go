@@ -1,7 +1,10 @@
 package internal
 
 import (
+	"crypto/subtle"
 	"fmt"
+	"log"
+	"net/http"
 	"os"
 	"time"
 
@@ -10,6 +13,7 @@ import (
 
 type JWTManager struct {
 	secretKey string
+	issueKey  string // API_TOKEN_KEY; when set, /api/token only mints for callers sending it as X-API-Key
 }
 
 type Claims struct {
@@ -23,9 +27,22 @@ func NewJWTManager() *JWTManager {
 	if secretKey == "" {
 		secretKey = "your-secret-key-change-this-in-production"
 	}
+	issueKey := os.Getenv("API_TOKEN_KEY")
+	if issueKey == "" {
+		log.Println("Warning: API_TOKEN_KEY not set, /api/token mints a token for any caller so the protected routes are only as private as the network")
+	}
 	return &JWTManager{
 		secretKey: secretKey,
+		issueKey:  issueKey,
+	}
+}
+
+// whether the request may be issued a token: it must carry API_TOKEN_KEY as X-API-Key when one is configured
+func (jm *JWTManager) CanIssue(r *http.Request) bool {
+	if jm.issueKey == "" {
+		return true
 	}
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("X-API-Key")), []byte(jm.issueKey)) == 1
 }
 
 func (jm *JWTManager) GenerateToken(userID, email string, expirationHours int) (string, error) {
@@ -59,7 +76,7 @@ func (jm *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse token: %v", err)
+		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
 	if !token.Valid {
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"container/list"
+	"context"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+// DefaultBacktestCacheSize and DefaultBacktestCacheTTL bound the in-memory backtest cache when the API fields are unset
+const (
+	DefaultBacktestCacheSize = 50
+	DefaultBacktestCacheTTL  = time.Hour
+)
+
+// durable backtest storage behind the in-memory cache: finished runs are saved to it and looked up there once
+// evicted; GetBacktest returns nil results for an unknown id
+type BacktestStore interface {
+	GetBacktest(ctx context.Context, backtestID string) (map[string]interface{}, error)
+	SaveBacktest(ctx context.Context, backtestID string, results map[string]interface{}) error
+	ListBacktests(ctx context.Context, limit int) ([]database.ListBacktestsRow, error)
+}
+
+type backtestCacheEntry struct {
+	id       string
+	results  map[string]interface{}
+	storedAt time.Time
+}
+
+// stores results at the front of the LRU, evicting expired and least recently used entries
+func (api *API) cacheBacktest(backtestID string, results map[string]interface{}) {
+	api.backtestMutex.Lock()
+	defer api.backtestMutex.Unlock()
+
+	if api.backtestCache == nil {
+		api.backtestCache = make(map[string]*list.Element)
+		api.backtestLRU = list.New()
+	}
+
+	now := time.Now()
+	if elem, ok := api.backtestCache[backtestID]; ok {
+		entry := elem.Value.(*backtestCacheEntry)
+		entry.results = results
+		entry.storedAt = now
+		api.backtestLRU.MoveToFront(elem)
+	} else {
+		entry := &backtestCacheEntry{id: backtestID, results: results, storedAt: now}
+		api.backtestCache[backtestID] = api.backtestLRU.PushFront(entry)
+	}
+
+	api.evictBacktests(now)
+}
+
+// returns cached results, falling back to BacktestStore for entries that were evicted
+func (api *API) lookupBacktest(ctx context.Context, backtestID string) (map[string]interface{}, bool, error) {
+	api.backtestMutex.Lock()
+	if elem, ok := api.backtestCache[backtestID]; ok {
+		entry := elem.Value.(*backtestCacheEntry)
+		if time.Since(entry.storedAt) <= api.backtestCacheTTL() {
+			api.backtestLRU.MoveToFront(elem)
+			api.backtestMutex.Unlock()
+			return entry.results, true, nil
+		}
+		api.removeBacktest(elem)
+	}
+	api.backtestMutex.Unlock()
+
+	if api.BacktestStore == nil {
+		return nil, false, nil
+	}
+	results, err := api.BacktestStore.GetBacktest(ctx, backtestID)
+	if err != nil || results == nil {
+		return nil, false, err
+	}
+	api.cacheBacktest(backtestID, results)
+	return results, true, nil
+}
+
+// caller must hold backtestMutex
+func (api *API) evictBacktests(now time.Time) {
+	ttl := api.backtestCacheTTL()
+	for elem := api.backtestLRU.Back(); elem != nil; {
+		prev := elem.Prev()
+		if now.Sub(elem.Value.(*backtestCacheEntry).storedAt) > ttl {
+			api.removeBacktest(elem)
+		}
+		elem = prev
+	}
+
+	maxSize := api.BacktestCacheSize
+	if maxSize <= 0 {
+		maxSize = DefaultBacktestCacheSize
+	}
+	for api.backtestLRU.Len() > maxSize {
+		api.removeBacktest(api.backtestLRU.Back())
+	}
+}
+
+// caller must hold backtestMutex
+func (api *API) removeBacktest(elem *list.Element) {
+	entry := api.backtestLRU.Remove(elem).(*backtestCacheEntry)
+	delete(api.backtestCache, entry.id)
+}
+
+func (api *API) backtestCacheTTL() time.Duration {
+	if api.BacktestCacheTTL > 0 {
+		return api.BacktestCacheTTL
+	}
+	return DefaultBacktestCacheTTL
+}
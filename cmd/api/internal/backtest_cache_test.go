@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+type fakeBacktestStore struct {
+	results map[string]map[string]interface{}
+	lookups int
+	saved   []string
+	listed  []database.ListBacktestsRow
+	limit   int
+}
+
+func (f *fakeBacktestStore) GetBacktest(ctx context.Context, backtestID string) (map[string]interface{}, error) {
+	f.lookups++
+	return f.results[backtestID], nil
+}
+
+func (f *fakeBacktestStore) SaveBacktest(ctx context.Context, backtestID string, results map[string]interface{}) error {
+	if f.results == nil {
+		f.results = map[string]map[string]interface{}{}
+	}
+	f.results[backtestID] = results
+	f.saved = append(f.saved, backtestID)
+	return nil
+}
+
+func (f *fakeBacktestStore) ListBacktests(ctx context.Context, limit int) ([]database.ListBacktestsRow, error) {
+	f.limit = limit
+	return f.listed, nil
+}
+
+func TestBacktestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	api := &API{BacktestCacheSize: 2}
+
+	api.cacheBacktest("a", map[string]interface{}{"backtest_id": "a"})
+	api.cacheBacktest("b", map[string]interface{}{"backtest_id": "b"})
+	// touching "a" makes "b" the least recently used entry
+	if _, ok, _ := api.lookupBacktest(context.Background(), "a"); !ok {
+		t.Fatal("Expected a to be cached")
+	}
+	api.cacheBacktest("c", map[string]interface{}{"backtest_id": "c"})
+
+	if len(api.backtestCache) != 2 || api.backtestLRU.Len() != 2 {
+		t.Fatalf("Expected cache capped at 2 entries, got map=%d list=%d", len(api.backtestCache), api.backtestLRU.Len())
+	}
+	if _, ok, _ := api.lookupBacktest(context.Background(), "b"); ok {
+		t.Errorf("Expected b to be evicted")
+	}
+	for _, id := range []string{"a", "c"} {
+		if _, ok, _ := api.lookupBacktest(context.Background(), id); !ok {
+			t.Errorf("Expected %s to remain cached", id)
+		}
+	}
+}
+
+func TestBacktestCache_ExpiresByAge(t *testing.T) {
+	api := &API{BacktestCacheTTL: time.Millisecond}
+	api.cacheBacktest("old", map[string]interface{}{"backtest_id": "old"})
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok, _ := api.lookupBacktest(context.Background(), "old"); ok {
+		t.Errorf("Expected expired entry to be dropped")
+	}
+	if len(api.backtestCache) != 0 {
+		t.Errorf("Expected expired entry removed from cache, got %d entries", len(api.backtestCache))
+	}
+}
+
+func TestBacktestCache_EvictedEntriesServedFromStore(t *testing.T) {
+	store := &fakeBacktestStore{results: map[string]map[string]interface{}{}}
+	api := &API{BacktestCacheSize: 3, BacktestStore: store}
+
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("bt_%d", i)
+		results := map[string]interface{}{"backtest_id": id, "status": "completed"}
+		store.results[id] = results
+		api.cacheBacktest(id, results)
+	}
+	if _, inMemory := api.backtestCache["bt_0"]; inMemory {
+		t.Fatal("Expected bt_0 to be evicted from memory")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/backtest/results?id=bt_0", nil)
+	rec := httptest.NewRecorder()
+	api.HandleBacktestResults(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if store.lookups != 1 {
+		t.Errorf("Expected one store lookup, got %d", store.lookups)
+	}
+	// the store hit is promoted back into memory
+	if _, inMemory := api.backtestCache["bt_0"]; !inMemory {
+		t.Errorf("Expected bt_0 to be re-cached after the store lookup")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/backtest/status?id=missing", nil)
+	rec = httptest.NewRecorder()
+	api.HandleBacktestStatus(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d for unknown backtest", rec.Code, http.StatusNotFound)
+	}
+}
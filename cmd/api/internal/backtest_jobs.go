@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	BacktestStatusQueued    = "queued"
+	BacktestStatusRunning   = "running"
+	BacktestStatusCompleted = "completed"
+	BacktestStatusFailed    = "failed"
+)
+
+var backtestJobSeq atomic.Int64
+
+// progress of an async backtest that has not finished successfully yet
+type backtestJob struct {
+	ID         string
+	Status     string
+	Progress   int
+	Error      string
+	FinishedAt time.Time
+}
+
+// starts a backtest in the background and returns its id immediately; poll /api/backtest/status
+func (api *API) HandleStartBacktest(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if r.ContentLength != 0 {
+		var body struct {
+			Symbol    string  `json:"symbol"`
+			StartDate string  `json:"start_date"`
+			EndDate   string  `json:"end_date"`
+			Capital   float64 `json:"capital"`
+			Strategy  string  `json:"strategy"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			WriteError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+		query = url.Values{}
+		query.Set("symbol", body.Symbol)
+		query.Set("start_date", body.StartDate)
+		query.Set("end_date", body.EndDate)
+		if body.Capital > 0 {
+			query.Set("capital", strconv.FormatFloat(body.Capital, 'f', -1, 64))
+		}
+		query.Set("strategy", body.Strategy)
+	}
+
+	params, err := api.parseBacktestParams(query)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	backtestID := fmt.Sprintf("%s_%s_%d", params.Symbol, time.Now().Format("20060102150405"), backtestJobSeq.Add(1))
+	api.startBacktestJob(backtestID, params)
+
+	WriteJSON(w, http.StatusAccepted, map[string]interface{}{
+		"backtest_id": backtestID,
+		"status":      BacktestStatusQueued,
+		"progress":    0,
+	})
+}
+
+func (api *API) startBacktestJob(backtestID string, params backtestParams) {
+	api.backtestMutex.Lock()
+	if api.backtestJobs == nil {
+		api.backtestJobs = make(map[string]*backtestJob)
+	}
+	// failed jobs are kept for status polling until they are as old as a cached result would be
+	for id, job := range api.backtestJobs {
+		if !job.FinishedAt.IsZero() && time.Since(job.FinishedAt) > api.backtestCacheTTL() {
+			delete(api.backtestJobs, id)
+		}
+	}
+	api.backtestJobs[backtestID] = &backtestJob{ID: backtestID, Status: BacktestStatusQueued}
+	api.backtestMutex.Unlock()
+
+	go api.runBacktestJob(backtestID, params)
+}
+
+func (api *API) runBacktestJob(backtestID string, params backtestParams) {
+	api.updateBacktestJob(backtestID, func(job *backtestJob) {
+		job.Status = BacktestStatusRunning
+	})
+
+	// the request that started the job has already returned, so run detached from it
+	response, err := api.runBacktest(context.Background(), params, func(percent int) {
+		api.updateBacktestJob(backtestID, func(job *backtestJob) {
+			// leave 100 for completion so pollers never see a finished run without results
+			if percent > 99 {
+				percent = 99
+			}
+			if percent > job.Progress {
+				job.Progress = percent
+			}
+		})
+	})
+	if err != nil {
+		log.Printf("Backtest %s failed: %v", backtestID, err)
+		api.updateBacktestJob(backtestID, func(job *backtestJob) {
+			job.Status = BacktestStatusFailed
+			job.Error = err.Error()
+			job.FinishedAt = time.Now()
+		})
+		return
+	}
+
+	response["backtest_id"] = backtestID
+	response["status"] = BacktestStatusCompleted
+	api.storeBacktest(context.Background(), backtestID, response)
+
+	api.backtestMutex.Lock()
+	delete(api.backtestJobs, backtestID)
+	api.backtestMutex.Unlock()
+}
+
+func (api *API) updateBacktestJob(backtestID string, update func(job *backtestJob)) {
+	api.backtestMutex.Lock()
+	defer api.backtestMutex.Unlock()
+	if job, ok := api.backtestJobs[backtestID]; ok {
+		update(job)
+	}
+}
+
+// snapshot of an unfinished or failed async job
+func (api *API) backtestJobStatus(backtestID string) (backtestJob, bool) {
+	api.backtestMutex.RLock()
+	defer api.backtestMutex.RUnlock()
+	job, ok := api.backtestJobs[backtestID]
+	if !ok {
+		return backtestJob{}, false
+	}
+	return *job, true
+}
@@ -0,0 +1,214 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func getBacktestStatus(t *testing.T, api *API, backtestID string) map[string]interface{} {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/backtest/status?id="+backtestID, nil)
+	rec := httptest.NewRecorder()
+	api.HandleBacktestStatus(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status endpoint returned %d: %s", rec.Code, rec.Body.String())
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid status JSON: %v", err)
+	}
+	return body
+}
+
+// polls the status endpoint until it reports the wanted status and progress
+func waitForBacktestStatus(t *testing.T, api *API, backtestID, status string, progress float64) map[string]interface{} {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		body := getBacktestStatus(t, api, backtestID)
+		if body["status"] == status && body["progress"] == progress {
+			return body
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s/%v, last status %v", status, progress, body)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func startTestBacktest(t *testing.T, api *API) string {
+	t.Helper()
+	body := `{"symbol":"aapl","start_date":"2024-01-01","end_date":"2024-06-30","capital":5000}`
+	req := httptest.NewRequest(http.MethodPost, "/api/backtest", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	api.HandleStartBacktest(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+	var response map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if response["status"] != BacktestStatusQueued {
+		t.Errorf("initial status = %v, want queued", response["status"])
+	}
+	backtestID, _ := response["backtest_id"].(string)
+	if !strings.HasPrefix(backtestID, "AAPL_") {
+		t.Fatalf("unexpected backtest_id %q", backtestID)
+	}
+	return backtestID
+}
+
+func TestHandleStartBacktest_ReportsProgressUntilCompleted(t *testing.T) {
+	step := make(chan int)
+	finish := make(chan struct{})
+	var gotParams backtestParams
+
+	api := &API{}
+	api.backtestRunner = func(ctx context.Context, params backtestParams, progress func(percent int)) (map[string]interface{}, error) {
+		gotParams = params
+		for percent := range step {
+			progress(percent)
+		}
+		<-finish
+		return map[string]interface{}{"symbol": params.Symbol, "total_trades": 3}, nil
+	}
+
+	backtestID := startTestBacktest(t, api)
+
+	waitForBacktestStatus(t, api, backtestID, BacktestStatusRunning, 0)
+	step <- 40
+	waitForBacktestStatus(t, api, backtestID, BacktestStatusRunning, 40)
+	step <- 100 // held at 99 until results are stored
+	waitForBacktestStatus(t, api, backtestID, BacktestStatusRunning, 99)
+
+	// results are not ready while the job is still running
+	req := httptest.NewRequest(http.MethodGet, "/api/backtest/results?id="+backtestID, nil)
+	rec := httptest.NewRecorder()
+	api.HandleBacktestResults(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("results status = %d while running, want %d", rec.Code, http.StatusAccepted)
+	}
+
+	close(step)
+	close(finish)
+	waitForBacktestStatus(t, api, backtestID, BacktestStatusCompleted, 100)
+
+	if gotParams.Symbol != "AAPL" || gotParams.Capital != 5000 || gotParams.StartDate != "2024-01-01" {
+		t.Errorf("Unexpected params passed to runner: %+v", gotParams)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/backtest/results?id="+backtestID, nil)
+	rec = httptest.NewRecorder()
+	api.HandleBacktestResults(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"total_trades":3`) {
+		t.Errorf("results = %d %s, want completed results", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleStartBacktest_Failed(t *testing.T) {
+	api := &API{}
+	api.backtestRunner = func(ctx context.Context, params backtestParams, progress func(percent int)) (map[string]interface{}, error) {
+		progress(10)
+		return nil, errors.New("no historical data for AAPL")
+	}
+
+	backtestID := startTestBacktest(t, api)
+
+	body := waitForBacktestStatus(t, api, backtestID, BacktestStatusFailed, 10)
+	if body["error"] != "no historical data for AAPL" {
+		t.Errorf("error = %v, want runner error", body["error"])
+	}
+}
+
+func TestHandleStartBacktest_ValidatesParams(t *testing.T) {
+	api := &API{}
+	req := httptest.NewRequest(http.MethodPost, "/api/backtest", strings.NewReader(`{"symbol":"AAPL"}`))
+	rec := httptest.NewRecorder()
+	api.HandleStartBacktest(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if len(api.backtestJobs) != 0 {
+		t.Errorf("Expected no job for invalid params")
+	}
+}
+
+func TestParseBacktestParams_Strategy(t *testing.T) {
+	api := &API{}
+	query := url.Values{"symbol": {"AAPL"}, "start_date": {"2024-01-01"}, "end_date": {"2024-06-30"}}
+
+	params, err := api.parseBacktestParams(query)
+	if err != nil || params.Strategy != "rsi" {
+		t.Errorf("default strategy = %q, err %v, want rsi", params.Strategy, err)
+	}
+
+	query.Set("strategy", "breakout")
+	params, err = api.parseBacktestParams(query)
+	if err != nil || params.Strategy != "breakout" {
+		t.Errorf("strategy = %q, err %v, want breakout", params.Strategy, err)
+	}
+
+	query.Set("strategy", "coinflip")
+	if _, err := api.parseBacktestParams(query); err == nil {
+		t.Error("Expected an error for an unknown strategy")
+	}
+}
+
+func TestParseBacktestParams_Bootstrap(t *testing.T) {
+	api := &API{}
+	query := url.Values{"symbol": {"AAPL"}, "start_date": {"2024-01-01"}, "end_date": {"2024-06-30"}}
+
+	params, err := api.parseBacktestParams(query)
+	if err != nil || params.Bootstrap != 0 {
+		t.Errorf("default bootstrap = %d, err %v, want 0", params.Bootstrap, err)
+	}
+
+	query.Set("bootstrap", "1000")
+	params, err = api.parseBacktestParams(query)
+	if err != nil || params.Bootstrap != 1000 {
+		t.Errorf("bootstrap = %d, err %v, want 1000", params.Bootstrap, err)
+	}
+
+	for _, bad := range []string{"-1", "lots", "1000000"} {
+		query.Set("bootstrap", bad)
+		if _, err := api.parseBacktestParams(query); err == nil {
+			t.Errorf("Expected an error for bootstrap=%s", bad)
+		}
+	}
+}
+
+func TestParseBacktestParams_TradingCosts(t *testing.T) {
+	api := &API{}
+	query := url.Values{"symbol": {"AAPL"}, "start_date": {"2024-01-01"}, "end_date": {"2024-06-30"}}
+
+	params, err := api.parseBacktestParams(query)
+	if err != nil || params.Commission != 0 || params.SlippageBps != 0 {
+		t.Errorf("default costs = %v/%v, err %v, want 0/0", params.Commission, params.SlippageBps, err)
+	}
+
+	query.Set("commission", "1.5")
+	query.Set("slippage_bps", "5")
+	params, err = api.parseBacktestParams(query)
+	if err != nil || params.Commission != 1.5 || params.SlippageBps != 5 {
+		t.Errorf("costs = %v/%v, err %v, want 1.5/5", params.Commission, params.SlippageBps, err)
+	}
+
+	for _, key := range []string{"commission", "slippage_bps"} {
+		for _, bad := range []string{"-1", "free"} {
+			q := url.Values{"symbol": {"AAPL"}, "start_date": {"2024-01-01"}, "end_date": {"2024-06-30"}, key: {bad}}
+			if _, err := api.parseBacktestParams(q); err == nil {
+				t.Errorf("Expected an error for %s=%s", key, bad)
+			}
+		}
+	}
+}
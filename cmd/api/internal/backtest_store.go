@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+// bounds for GET /api/backtest/list
+const (
+	defaultBacktestListLimit = 20
+	maxBacktestListLimit     = 200
+)
+
+// subset of queries behind the backtests table (*database.Queries satisfies it)
+type backtestQueries interface {
+	SaveBacktest(ctx context.Context, arg database.SaveBacktestParams) error
+	GetBacktestByID(ctx context.Context, id string) (database.Backtest, error)
+	ListBacktests(ctx context.Context, limit int32) ([]database.ListBacktestsRow, error)
+}
+
+// BacktestStore kept in the backtests table, with the full results payload in its metrics column
+type DBBacktestStore struct {
+	queries backtestQueries
+}
+
+func NewDBBacktestStore(queries backtestQueries) *DBBacktestStore {
+	return &DBBacktestStore{queries: queries}
+}
+
+// the stored results, nil when no backtest has that id
+func (s *DBBacktestStore) GetBacktest(ctx context.Context, backtestID string) (map[string]interface{}, error) {
+	row, err := s.queries.GetBacktestByID(ctx, backtestID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var results map[string]interface{}
+	if err := json.Unmarshal(row.Metrics, &results); err != nil {
+		return nil, fmt.Errorf("decoding backtest %s: %w", backtestID, err)
+	}
+	return results, nil
+}
+
+func (s *DBBacktestStore) SaveBacktest(ctx context.Context, backtestID string, results map[string]interface{}) error {
+	params, err := backtestRecord(backtestID, results)
+	if err != nil {
+		return err
+	}
+	return s.queries.SaveBacktest(ctx, params)
+}
+
+func (s *DBBacktestStore) ListBacktests(ctx context.Context, limit int) ([]database.ListBacktestsRow, error) {
+	return s.queries.ListBacktests(ctx, int32(limit))
+}
+
+// the backtests row for a results payload from runSymbolBacktest or the portfolio backtest, whose symbols are
+// stored comma-separated
+func backtestRecord(backtestID string, results map[string]interface{}) (database.SaveBacktestParams, error) {
+	symbol, _ := results["symbol"].(string)
+	if symbols, ok := results["symbols"].([]string); ok && symbol == "" {
+		symbol = strings.Join(symbols, ",")
+	}
+
+	startDate, err := backtestDate(results, "start_date")
+	if err != nil {
+		return database.SaveBacktestParams{}, err
+	}
+	endDate, err := backtestDate(results, "end_date")
+	if err != nil {
+		return database.SaveBacktestParams{}, err
+	}
+
+	metrics, err := json.Marshal(results)
+	if err != nil {
+		return database.SaveBacktestParams{}, fmt.Errorf("encoding backtest %s: %w", backtestID, err)
+	}
+
+	initialCapital, _ := results["initial_capital"].(float64)
+	finalBalance, _ := results["final_balance"].(float64)
+	return database.SaveBacktestParams{
+		ID:             backtestID,
+		Symbol:         symbol,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		InitialCapital: initialCapital,
+		FinalBalance:   finalBalance,
+		Metrics:        metrics,
+	}, nil
+}
+
+func backtestDate(results map[string]interface{}, key string) (time.Time, error) {
+	raw, _ := results[key].(string)
+	date, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("backtest %s %q is not a YYYY-MM-DD date", key, raw)
+	}
+	return date, nil
+}
+
+// caches results and saves them to BacktestStore; a failed save is only logged since the cache still serves them
+func (api *API) storeBacktest(ctx context.Context, backtestID string, results map[string]interface{}) {
+	api.cacheBacktest(backtestID, results)
+	if api.BacktestStore == nil {
+		return
+	}
+	if err := api.BacktestStore.SaveBacktest(ctx, backtestID, results); err != nil {
+		log.Printf("Warning: failed to persist backtest %s: %v", backtestID, err)
+	}
+}
+
+// GET /api/backtest/list returns the most recent saved backtests (limit, default 20) without their trades
+func (api *API) HandleListBacktests(w http.ResponseWriter, r *http.Request) {
+	limit := defaultBacktestListLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxBacktestListLimit {
+			WriteError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxBacktestListLimit))
+			return
+		}
+		limit = parsed
+	}
+	if api.BacktestStore == nil {
+		WriteError(w, http.StatusServiceUnavailable, "Database not initialized")
+		return
+	}
+
+	rows, err := api.BacktestStore.ListBacktests(r.Context(), limit)
+	if err != nil {
+		log.Printf("Error listing backtests: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to list backtests")
+		return
+	}
+
+	backtests := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		totalReturnPct := 0.0
+		if row.InitialCapital > 0 {
+			totalReturnPct = (row.FinalBalance - row.InitialCapital) / row.InitialCapital * 100
+		}
+		backtests = append(backtests, map[string]interface{}{
+			"backtest_id":      row.ID,
+			"symbol":           row.Symbol,
+			"start_date":       row.StartDate.Format("2006-01-02"),
+			"end_date":         row.EndDate.Format("2006-01-02"),
+			"initial_capital":  row.InitialCapital,
+			"final_balance":    row.FinalBalance,
+			"total_return_pct": totalReturnPct,
+			"created_at":       row.CreatedAt.Unix(),
+		})
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"backtests": backtests,
+		"count":     len(backtests),
+	})
+}
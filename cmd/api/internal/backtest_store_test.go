@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+type fakeBacktestQueries struct {
+	rows map[string]database.SaveBacktestParams
+}
+
+func (f *fakeBacktestQueries) SaveBacktest(ctx context.Context, arg database.SaveBacktestParams) error {
+	f.rows[arg.ID] = arg
+	return nil
+}
+
+func (f *fakeBacktestQueries) GetBacktestByID(ctx context.Context, id string) (database.Backtest, error) {
+	row, ok := f.rows[id]
+	if !ok {
+		return database.Backtest{}, sql.ErrNoRows
+	}
+	return database.Backtest{ID: row.ID, Symbol: row.Symbol, Metrics: row.Metrics}, nil
+}
+
+func (f *fakeBacktestQueries) ListBacktests(ctx context.Context, limit int32) ([]database.ListBacktestsRow, error) {
+	return nil, nil
+}
+
+func TestDBBacktestStore_SavesAndLoadsResults(t *testing.T) {
+	queries := &fakeBacktestQueries{rows: map[string]database.SaveBacktestParams{}}
+	store := NewDBBacktestStore(queries)
+
+	results := map[string]interface{}{
+		"symbol":          "AAPL",
+		"start_date":      "2024-01-01",
+		"end_date":        "2024-06-30",
+		"initial_capital": 10000.0,
+		"final_balance":   11250.0,
+		"total_trades":    4,
+	}
+	if err := store.SaveBacktest(context.Background(), "AAPL_1", results); err != nil {
+		t.Fatalf("SaveBacktest: %v", err)
+	}
+
+	row := queries.rows["AAPL_1"]
+	if row.Symbol != "AAPL" || row.InitialCapital != 10000 || row.FinalBalance != 11250 {
+		t.Errorf("saved row = %+v, want AAPL 10000 -> 11250", row)
+	}
+	if !row.StartDate.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || !row.EndDate.Equal(time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("saved dates = %v..%v, want 2024-01-01..2024-06-30", row.StartDate, row.EndDate)
+	}
+
+	loaded, err := store.GetBacktest(context.Background(), "AAPL_1")
+	if err != nil {
+		t.Fatalf("GetBacktest: %v", err)
+	}
+	if loaded["symbol"] != "AAPL" || loaded["total_trades"] != 4.0 {
+		t.Errorf("loaded results = %v", loaded)
+	}
+
+	missing, err := store.GetBacktest(context.Background(), "nope")
+	if err != nil || missing != nil {
+		t.Errorf("GetBacktest(unknown) = %v, %v; want nil, nil", missing, err)
+	}
+}
+
+func TestDBBacktestStore_PortfolioSymbolsJoined(t *testing.T) {
+	params, err := backtestRecord("portfolio_1", map[string]interface{}{
+		"symbols":    []string{"AAPL", "MSFT"},
+		"start_date": "2024-01-01",
+		"end_date":   "2024-02-01",
+	})
+	if err != nil {
+		t.Fatalf("backtestRecord: %v", err)
+	}
+	if params.Symbol != "AAPL,MSFT" {
+		t.Errorf("symbol = %q, want AAPL,MSFT", params.Symbol)
+	}
+
+	if _, err := backtestRecord("bad", map[string]interface{}{"symbol": "AAPL", "start_date": "soon"}); err == nil {
+		t.Error("Expected an error for a backtest without valid dates")
+	}
+}
+
+func TestHandleBacktest_PersistsResults(t *testing.T) {
+	store := &fakeBacktestStore{}
+	api := &API{BacktestStore: store}
+	api.backtestRunner = func(ctx context.Context, params backtestParams, progress func(percent int)) (map[string]interface{}, error) {
+		return map[string]interface{}{"symbol": params.Symbol, "status": "completed"}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/backtest?symbol=AAPL&start_date=2024-01-01&end_date=2024-06-30", nil)
+	rec := httptest.NewRecorder()
+	api.HandleBacktest(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	if len(store.saved) != 1 {
+		t.Fatalf("Expected the backtest to be saved once, got %v", store.saved)
+	}
+	backtestID := store.saved[0]
+	if _, cached := api.backtestCache[backtestID]; !cached {
+		t.Errorf("Expected %s to stay cached in front of the store", backtestID)
+	}
+}
+
+func TestHandleListBacktests(t *testing.T) {
+	created := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeBacktestStore{listed: []database.ListBacktestsRow{{
+		ID:             "AAPL_1",
+		Symbol:         "AAPL",
+		StartDate:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:        time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC),
+		InitialCapital: 10000,
+		FinalBalance:   11000,
+		CreatedAt:      created,
+	}}}
+	api := &API{BacktestStore: store}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/backtest/list?limit=5", nil)
+	rec := httptest.NewRecorder()
+	api.HandleListBacktests(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if store.limit != 5 {
+		t.Errorf("limit = %d, want 5", store.limit)
+	}
+
+	var body struct {
+		Backtests []map[string]interface{} `json:"backtests"`
+		Count     int                      `json:"count"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if body.Count != 1 || body.Backtests[0]["backtest_id"] != "AAPL_1" || body.Backtests[0]["total_return_pct"] != 10.0 {
+		t.Errorf("body = %+v", body)
+	}
+	if body.Backtests[0]["start_date"] != "2024-01-01" {
+		t.Errorf("start_date = %v, want 2024-01-01", body.Backtests[0]["start_date"])
+	}
+
+	for _, limit := range []string{"0", "abc", "1000"} {
+		rec = httptest.NewRecorder()
+		api.HandleListBacktests(rec, httptest.NewRequest(http.MethodGet, "/api/backtest/list?limit="+limit, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("limit=%s: status = %d, want %d", limit, rec.Code, http.StatusBadRequest)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	(&API{}).HandleListBacktests(rec, httptest.NewRequest(http.MethodGet, "/api/backtest/list", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status without a store = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/analyzer"
+	"github.com/fazecat/mogulmaker/Internal/utils/chart"
+)
+
+const (
+	defaultChartBars = 120
+	maxChartBars     = 1000
+	maxChartSize     = 2000
+)
+
+// GET /api/chart?symbol=...&timeframe=1Day&bars=120&width=&height=&format=png|data_url renders candles, RSI
+// and S/R levels server-side; off unless features.chart_rendering is set
+func (api *API) HandleChart(w http.ResponseWriter, r *http.Request) {
+	if !api.ChartsEnabled {
+		WriteError(w, http.StatusNotFound, "Chart rendering is disabled (features.chart_rendering)")
+		return
+	}
+
+	query := r.URL.Query()
+	symbol := query.Get("symbol")
+	if symbol == "" {
+		WriteError(w, http.StatusBadRequest, "Symbol is required")
+		return
+	}
+	symbol, assetType := resolveSymbol(symbol, query.Get("asset_type"))
+
+	timeframe := query.Get("timeframe")
+	if timeframe == "" {
+		timeframe = "1Day"
+	}
+	numBars := defaultChartBars
+	if v, err := strconv.Atoi(query.Get("bars")); err == nil && v > 0 && v <= maxChartBars {
+		numBars = v
+	}
+	width, _ := strconv.Atoi(query.Get("width"))
+	height, _ := strconv.Atoi(query.Get("height"))
+	if width > maxChartSize || height > maxChartSize {
+		WriteError(w, http.StatusBadRequest, "width and height must be at most 2000")
+		return
+	}
+
+	bars, err := api.fetchBars(symbol, timeframe, numBars, assetType)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch market data")
+		return
+	}
+
+	analysis, err := analyzer.AnalyzeSymbolDetailed(symbol, bars)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, "Failed to analyze symbol")
+		return
+	}
+
+	img, err := chart.RenderPNG(chartDataFromAnalysis(bars, analysis), width, height)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to render chart")
+		return
+	}
+
+	if query.Get("format") == "data_url" {
+		WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"symbol":   symbol,
+			"data_url": chart.DataURL(img),
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Disposition", "inline; filename=\""+symbol+".png\"")
+	w.WriteHeader(http.StatusOK)
+	w.Write(img)
+}
+
+// pulls the S/R levels out of an AnalyzeSymbolDetailed result so the chart matches the analysis
+func chartDataFromAnalysis(bars []types.Bar, analysis map[string]interface{}) chart.Data {
+	data := chart.Data{Bars: bars}
+	data.Support, _ = analysis["support_level"].(float64)
+	data.Resistance, _ = analysis["resistance_level"].(float64)
+
+	closes := make([]float64, len(bars))
+	for i, bar := range bars {
+		closes[i] = bar.Close
+	}
+	if rsi, err := indicators.CalculateRSI(closes, 14); err == nil {
+		data.RSI = rsi
+	}
+	return data
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+func chartTestAPI(enabled bool) *API {
+	return &API{
+		ChartsEnabled: enabled,
+		bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+			return trendingBars(60, 100, 0.5), nil
+		},
+	}
+}
+
+func TestHandleChart_ReturnsPNG(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/chart?symbol=AAPL&width=640&height=400", nil)
+	w := httptest.NewRecorder()
+	chartTestAPI(true).HandleChart(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Content-Type = %q, want image/png", ct)
+	}
+	if w.Body.Len() == 0 {
+		t.Fatalf("empty chart body")
+	}
+	img, err := png.Decode(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("body is not a valid PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 640 || b.Dy() != 400 {
+		t.Errorf("size = %dx%d, want 640x400", b.Dx(), b.Dy())
+	}
+}
+
+func TestHandleChart_DataURL(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/chart?symbol=AAPL&format=data_url", nil)
+	w := httptest.NewRecorder()
+	chartTestAPI(true).HandleChart(w, req)
+
+	var resp struct {
+		DataURL string `json:"data_url"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	encoded, ok := strings.CutPrefix(resp.DataURL, "data:image/png;base64,")
+	if !ok {
+		t.Fatalf("data_url = %.40q..., want a PNG data URL", resp.DataURL)
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("data_url payload is not base64: %v", err)
+	}
+	if _, err := png.Decode(bytes.NewReader(raw)); err != nil {
+		t.Errorf("data_url payload is not a valid PNG: %v", err)
+	}
+}
+
+func TestHandleChart_DisabledByFlag(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/chart?symbol=AAPL", nil)
+	w := httptest.NewRecorder()
+	chartTestAPI(false).HandleChart(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 while features.chart_rendering is off", w.Code)
+	}
+}
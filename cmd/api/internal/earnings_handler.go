@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"net/http"
+	"time"
+
+	newsscraping "github.com/fazecat/mogulmaker/Internal/news_scraping"
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+func (api *API) earningsCalendar() strategy.EarningsCalendar {
+	if api.earnings != nil {
+		return api.earnings
+	}
+	return newsscraping.NewFinnhubClient()
+}
+
+// GET /api/positions/earnings flags held symbols that report earnings within earnings.warn_days
+func (api *API) HandlePositionsEarnings(w http.ResponseWriter, r *http.Request) {
+	positions, err := api.alpacaClient(r).GetPositions()
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch positions")
+		return
+	}
+
+	cfg := config.EarningsConfig{}
+	if api.Config != nil {
+		cfg = api.Config.Earnings
+	}
+
+	symbols := make([]string, 0, len(positions))
+	for _, p := range positions {
+		if utils.DetectAssetType(p.Symbol, "") != utils.AssetTypeCrypto {
+			symbols = append(symbols, p.Symbol)
+		}
+	}
+	flags, unavailable := strategy.FlagPositionsNearEarnings(symbols, time.Now(), api.earningsCalendar(), cfg)
+	if flags == nil {
+		flags = []strategy.EarningsFlag{}
+	}
+
+	response := map[string]interface{}{
+		"flagged": flags,
+		"checked": len(symbols),
+	}
+	if len(unavailable) > 0 {
+		response["unavailable"] = unavailable
+		response["note"] = "earnings dates unavailable for some symbols; they were not checked"
+	}
+	WriteJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+// client-safe status and message for each typed error; anything unlisted falls back to the caller's message
+var serviceErrors = []struct {
+	err     error
+	status  int
+	message string
+}{
+	{ErrAlpacaTimeout, http.StatusGatewayTimeout, "Alpaca request timed out"},
+	{context.Canceled, http.StatusServiceUnavailable, "Request cancelled"},
+	{utils.ErrRateLimited, http.StatusTooManyRequests, "Market data rate limit reached, try again shortly"},
+	{utils.ErrSymbolNotFound, http.StatusNotFound, "Symbol not found"},
+	{utils.ErrInsufficientData, http.StatusUnprocessableEntity, "Not enough market data for this symbol"},
+}
+
+// what every risk endpoint answers when the server started without Alpaca account data
+const riskManagerUnavailable = "risk manager unavailable — configure Alpaca"
+
+// writes a 503 and returns false when there is no risk manager, so risk endpoints never report made-up numbers
+func (api *API) requireRiskManager(w http.ResponseWriter) bool {
+	if api.RiskManager == nil {
+		WriteError(w, http.StatusServiceUnavailable, riskManagerUnavailable)
+		return false
+	}
+	return true
+}
+
+// maps err to a status and sanitized message, reporting false when it isn't a known typed error
+func classifyError(err error) (int, string, bool) {
+	for _, e := range serviceErrors {
+		if errors.Is(err, e.err) {
+			return e.status, e.message, true
+		}
+	}
+	return 0, "", false
+}
+
+// logs the full error server-side and writes only a safe message to the client
+func writeServiceError(w http.ResponseWriter, err error, fallbackStatus int, message string) {
+	log.Printf("%s: %v", message, err)
+	if status, safe, ok := classifyError(err); ok {
+		WriteError(w, status, safe)
+		return
+	}
+	WriteError(w, fallbackStatus, message)
+}
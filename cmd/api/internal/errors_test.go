@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+func TestWriteServiceError_MapsTypedErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantMessage string
+	}{
+		{"rate limited", utils.ErrRateLimited, http.StatusTooManyRequests, "Market data rate limit reached, try again shortly"},
+		{"symbol not found", utils.ErrSymbolNotFound, http.StatusNotFound, "Symbol not found"},
+		{"insufficient data", utils.ErrInsufficientData, http.StatusUnprocessableEntity, "Not enough market data for this symbol"},
+		{"alpaca timeout", ErrAlpacaTimeout, http.StatusGatewayTimeout, "Alpaca request timed out"},
+		{"cancelled", context.Canceled, http.StatusServiceUnavailable, "Request cancelled"},
+		{"unknown error uses fallback", errors.New("pq: connection refused to 10.0.0.5:5432"), http.StatusInternalServerError, "Failed to fetch market data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// wrap with internal detail that must never reach the client
+			err := fmt.Errorf("GET https://data.alpaca.markets/v2/stocks/XYZ/bars?key=secret: %w", tt.err)
+
+			rec := httptest.NewRecorder()
+			writeServiceError(rec, err, http.StatusInternalServerError, "Failed to fetch market data")
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var body map[string]string
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+			if body["error"] != tt.wantMessage {
+				t.Errorf("message = %q, want %q", body["error"], tt.wantMessage)
+			}
+			if strings.Contains(body["error"], "secret") || strings.Contains(body["error"], "pq:") {
+				t.Errorf("message leaked internal detail: %q", body["error"])
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/handlers/risk"
+)
+
+const heatHistoryDefaultDays = 30
+
+// GET /api/risk/heat?from=...&to=... lists persisted portfolio heat snapshots oldest first, with equity alongside
+// so heat can be charted against the equity curve (portfolio_heat.enabled)
+func (api *API) HandlePortfolioHeatHistory(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseTimeRange(w, r.URL.Query(), heatHistoryDefaultDays)
+	if !ok {
+		return
+	}
+
+	store := api.heatSnapshots
+	if store == nil {
+		if api.Queries == nil {
+			WriteError(w, http.StatusServiceUnavailable, "Database not initialized")
+			return
+		}
+		store = api.Queries
+	}
+
+	snapshots, err := store.GetHeatSnapshots(r.Context(), database.GetHeatSnapshotsParams{FromTime: from, ToTime: to})
+	if err != nil {
+		log.Printf("Error fetching heat snapshots: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch heat snapshots")
+		return
+	}
+	if snapshots == nil {
+		snapshots = []database.PortfolioHeatSnapshot{}
+	}
+
+	peak := 0.0
+	for _, s := range snapshots {
+		peak = max(peak, s.HeatPercent)
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"from":      from.Format(time.RFC3339),
+		"to":        to.Format(time.RFC3339),
+		"snapshots": snapshots,
+		"count":     len(snapshots),
+		"peak_heat": peak,
+		"bands": map[string]float64{
+			"yellow": risk.PortfolioHeatBands.Yellow,
+			"red":    risk.PortfolioHeatBands.Red,
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/scanner"
+)
+
+// GET /api/market/breadth?symbols=AAPL,MSFT reports how much of the scan universe is above its 20-day SMA,
+// has RSI over 50 and is making new highs rather than lows; without symbols it uses market_breadth.symbols,
+// falling back to the first max_symbols tradable assets
+func (api *API) HandleMarketBreadth(w http.ResponseWriter, r *http.Request) {
+	if api.Config == nil || !api.Config.MarketBreadth.Enabled {
+		WriteError(w, http.StatusNotFound, "Market breadth is disabled (market_breadth.enabled)")
+		return
+	}
+	cfg := api.Config.MarketBreadth
+
+	var symbols []string
+	if param := r.URL.Query().Get("symbols"); param != "" {
+		for _, s := range strings.Split(param, ",") {
+			if s = strings.ToUpper(strings.TrimSpace(s)); s != "" {
+				symbols = append(symbols, s)
+			}
+		}
+	} else if len(cfg.Symbols) > 0 {
+		symbols = cfg.Symbols
+	} else {
+		assets, err := scanner.GetTradableAssets()
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError, "Failed to list tradable assets")
+			return
+		}
+		symbols = assets
+	}
+	if cfg.MaxSymbols > 0 && len(symbols) > cfg.MaxSymbols {
+		symbols = symbols[:cfg.MaxSymbols]
+	}
+	if len(symbols) == 0 {
+		WriteError(w, http.StatusBadRequest, "No symbols to compute breadth over")
+		return
+	}
+
+	fetch := func(symbol string, limit int) ([]types.Bar, error) {
+		symbol, assetType := resolveSymbol(symbol, "")
+		return api.fetchBars(symbol, "1Day", limit, assetType)
+	}
+	breadth, err := scanner.ComputeMarketBreadth(r.Context(), symbols, fetch, scanner.BreadthOptionsFromConfig(cfg))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to compute market breadth")
+		return
+	}
+	WriteJSON(w, http.StatusOK, breadth)
+}
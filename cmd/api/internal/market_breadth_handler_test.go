@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+	"github.com/fazecat/mogulmaker/Internal/utils/scanner"
+)
+
+func TestHandleMarketBreadth(t *testing.T) {
+	rising := make([]types.Bar, 30)
+	for i := range rising {
+		c := 100 + float64(i)
+		rising[i] = types.Bar{Timestamp: time.Date(2024, 3, 1+i, 0, 0, 0, 0, time.UTC).Format(time.RFC3339), High: c + 0.2, Low: c - 0.2, Close: c}
+	}
+	cfg := &config.Config{MarketBreadth: config.MarketBreadthConfig{Enabled: true, Symbols: []string{"AAPL", "MSFT"}}}
+	api := &API{Config: cfg, bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+		if symbol == "AAPL" {
+			return rising, nil
+		}
+		return nil, errors.New("no data")
+	}}
+
+	w := httptest.NewRecorder()
+	api.HandleMarketBreadth(w, httptest.NewRequest(http.MethodGet, "/api/market/breadth", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var breadth scanner.MarketBreadth
+	if err := json.Unmarshal(w.Body.Bytes(), &breadth); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if breadth.Universe != 2 || breadth.Evaluated != 1 || breadth.PercentAboveSMA != 100 || breadth.NewHighs != 1 {
+		t.Errorf("breadth = %+v, want AAPL alone evaluated, above its SMA at a new high", breadth)
+	}
+
+	cfg.MarketBreadth.Enabled = false
+	w = httptest.NewRecorder()
+	api.HandleMarketBreadth(w, httptest.NewRequest(http.MethodGet, "/api/market/breadth", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("disabled status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	datafeed "github.com/fazecat/mogulmaker/Internal/database"
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+type barFetcher func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error)
+
+// canonical symbol and asset type for a user-entered symbol; an explicit asset_type wins,
+// otherwise BTC/USD style pairs and known crypto bases (BTCUSD) are crypto and everything else is stock
+func resolveSymbol(symbol, assetType string) (string, string) {
+	assetType = utils.DetectAssetType(symbol, assetType)
+	return utils.NormalizeSymbol(symbol, assetType), assetType
+}
+
+// bars from the stock or crypto data API depending on assetType
+func (api *API) fetchBars(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+	if api.bars != nil {
+		return api.bars(symbol, timeframe, limit, assetType)
+	}
+	return datafeed.GetAlpacaBarsWithType(symbol, timeframe, limit, "", assetType)
+}
+
+// fetchBars for handlers that loop over many symbols, backing off on rate limits instead of failing the symbol
+func (api *API) fetchBarsWithRetry(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+	if api.bars != nil {
+		return api.bars(symbol, timeframe, limit, assetType)
+	}
+	return datafeed.GetAlpacaBarsWithTypeRetry(symbol, timeframe, limit, "", assetType, 0)
+}
+
+// wraps strategy.ErrStaleMarketData when the symbol's latest daily bar is too old to trade from
+func (api *API) checkDataFreshness(symbol string) error {
+	symbol, assetType := resolveSymbol(symbol, "")
+	// a few days back so the newest bar is in range after a weekend
+	bars, err := api.fetchBars(symbol, "1Day", 5, assetType)
+	if err != nil {
+		return err
+	}
+	if len(bars) == 0 {
+		return fmt.Errorf("%w: no bars returned for %s", strategy.ErrStaleMarketData, symbol)
+	}
+	return strategy.CheckBarFreshness(strategy.LatestBar(bars), "1Day", assetType, time.Now(), api.Config)
+}
+
+// the quantity of a buy that the account's buying power covers, priced at the limit or else the latest close
+func (api *API) fitBuyingPower(r *http.Request, symbol string, quantity, limitPrice float64, cfg config.BuyingPowerConfig) (float64, error) {
+	symbol, assetType := resolveSymbol(symbol, "")
+	account, err := api.alpacaClient(r).GetAccount()
+	if err != nil {
+		return 0, err
+	}
+	price := limitPrice
+	if price <= 0 {
+		bars, err := api.fetchBars(symbol, "1Day", 1, assetType)
+		if err != nil {
+			return 0, err
+		}
+		if len(bars) == 0 {
+			return 0, fmt.Errorf("no bars returned for %s", symbol)
+		}
+		price = bars[len(bars)-1].Close
+	}
+	return strategy.CheckBuyingPower(quantity, price, strategy.AccountBuyingPower(account, symbol, assetType), cfg)
+}
@@ -0,0 +1,254 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+type fetchCall struct {
+	symbol    string
+	assetType string
+}
+
+func recordingBarsAPI(calls *[]fetchCall) *API {
+	return &API{
+		bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+			*calls = append(*calls, fetchCall{symbol: symbol, assetType: assetType})
+			return trendingBars(60, 100, 0.5), nil
+		},
+	}
+}
+
+func TestHandleAnalyzeSymbol_RoutesByAssetType(t *testing.T) {
+	cases := []struct {
+		query         string
+		wantSymbol    string
+		wantAssetType string
+	}{
+		{"symbol=aapl", "AAPL", "stock"},
+		{"symbol=BTC/USD", "BTCUSD", "crypto"},
+		{"symbol=ethusd", "ETHUSD", "crypto"},
+		{"symbol=SOLUSD&asset_type=stock", "SOLUSD", "stock"},
+	}
+
+	for _, tc := range cases {
+		var calls []fetchCall
+		req := httptest.NewRequest(http.MethodGet, "/api/analyze?"+tc.query, nil)
+		w := httptest.NewRecorder()
+		recordingBarsAPI(&calls).HandleAnalyzeSymbol(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, body %s", tc.query, w.Code, w.Body.String())
+			continue
+		}
+		if len(calls) == 0 {
+			t.Fatalf("%s: nothing fetched", tc.query)
+		}
+		// the multi-timeframe bars go to the same feed as the daily ones
+		for _, call := range calls {
+			if call.symbol != tc.wantSymbol || call.assetType != tc.wantAssetType {
+				t.Errorf("%s: fetched %s as %s, want %s as %s",
+					tc.query, call.symbol, call.assetType, tc.wantSymbol, tc.wantAssetType)
+			}
+		}
+	}
+}
+
+func analyzeSymbolMTF(t *testing.T, api *API, query string) map[string]interface{} {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/watchlist/analyze?"+query, nil)
+	w := httptest.NewRecorder()
+	api.HandleAnalyzeSymbol(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s: status = %d, body %s", query, w.Code, w.Body.String())
+	}
+	var resp struct {
+		MultiTimeframe map[string]interface{} `json:"multi_timeframe"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	return resp.MultiTimeframe
+}
+
+func TestHandleAnalyzeSymbol_MultiTimeframe(t *testing.T) {
+	var timeframes []string
+	failing := map[string]bool{}
+	api := &API{
+		bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+			timeframes = append(timeframes, timeframe)
+			if failing[timeframe] && len(timeframes) > 1 {
+				return nil, errors.New("feed unavailable")
+			}
+			return trendingBars(limit, 100, 0.5), nil
+		},
+	}
+
+	mtf := analyzeSymbolMTF(t, api, "symbol=aapl")
+	// the 250 daily bars already fetched cover the daily timeframe
+	if !reflect.DeepEqual(timeframes, []string{"1Day", "4Hour", "1Hour"}) {
+		t.Errorf("fetched %v, want the daily bars plus 4Hour and 1Hour", timeframes)
+	}
+	if mtf["available"] != true || mtf["warning"] != nil {
+		t.Fatalf("multi_timeframe = %v, want a full result", mtf)
+	}
+	for _, key := range []string{"daily", "four_hour", "one_hour", "recommended_trade"} {
+		if value, _ := mtf[key].(string); value == "" {
+			t.Errorf("multi_timeframe[%s] is empty: %v", key, mtf)
+		}
+	}
+	for _, key := range []string{"alignment_percent", "composite_score", "confirmed"} {
+		if _, ok := mtf[key]; !ok {
+			t.Errorf("multi_timeframe is missing %s: %v", key, mtf)
+		}
+	}
+
+	timeframes = nil
+	failing["4Hour"] = true
+	mtf = analyzeSymbolMTF(t, api, "symbol=aapl")
+	if mtf["available"] != true || mtf["four_hour"] != "" {
+		t.Errorf("with 4Hour failing, multi_timeframe = %v, want a partial result without a 4H signal", mtf)
+	}
+	if warning, _ := mtf["warning"].(string); !strings.Contains(warning, "4H") {
+		t.Errorf("warning = %q, want it to name the 4H failure", warning)
+	}
+	if failed, _ := mtf["failed_timeframes"].([]interface{}); len(failed) != 1 || failed[0] != "4Hour" {
+		t.Errorf("failed_timeframes = %v, want [4Hour]", mtf["failed_timeframes"])
+	}
+}
+
+func TestHandleAnalyzeSymbol_MultiTimeframeUnavailable(t *testing.T) {
+	calls := 0
+	api := &API{
+		bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+			calls++
+			if calls > 1 {
+				return nil, errors.New("feed unavailable")
+			}
+			// too few to reuse for the daily timeframe, so every multi-timeframe fetch fails
+			return trendingBars(60, 100, 0.5), nil
+		},
+	}
+
+	mtf := analyzeSymbolMTF(t, api, "symbol=aapl")
+	if mtf["available"] != false || mtf["warning"] == nil {
+		t.Errorf("multi_timeframe = %v, want it unavailable with a warning", mtf)
+	}
+	if failed, _ := mtf["failed_timeframes"].([]interface{}); len(failed) != 3 {
+		t.Errorf("failed_timeframes = %v, want all three", mtf["failed_timeframes"])
+	}
+}
+
+func TestHandleAnalyzeSymbol_SkipMTF(t *testing.T) {
+	var calls []fetchCall
+	mtf := analyzeSymbolMTF(t, recordingBarsAPI(&calls), "symbol=aapl&skip_mtf=true")
+	if len(calls) != 1 {
+		t.Errorf("skip_mtf fetched %d times, want once", len(calls))
+	}
+	if mtf["skipped"] != true {
+		t.Errorf("multi_timeframe = %v, want it marked skipped", mtf)
+	}
+}
+
+func TestResolveSymbol_WatchlistStoresDetectedType(t *testing.T) {
+	if symbol, assetType := resolveSymbol("btc-usd", ""); symbol != "BTCUSD" || assetType != "crypto" {
+		t.Errorf("resolveSymbol(btc-usd) = %s/%s, want BTCUSD/crypto", symbol, assetType)
+	}
+	if symbol, assetType := resolveSymbol("msft", ""); symbol != "MSFT" || assetType != "stock" {
+		t.Errorf("resolveSymbol(msft) = %s/%s, want MSFT/stock", symbol, assetType)
+	}
+}
+
+func TestHandleSymbolAnalysis_AnalyzesFetchedBars(t *testing.T) {
+	var timeframes []string
+	api := &API{
+		bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+			timeframes = append(timeframes, timeframe)
+			return reversedBars(trendingBars(60, 100, 0.5)), nil
+		},
+	}
+
+	for query, wantTimeframe := range map[string]string{"symbol=aapl": "1Day", "symbol=aapl&timeframe=1Hour": "1Hour"} {
+		timeframes = nil
+		req := httptest.NewRequest(http.MethodGet, "/api/analysis/symbol?"+query, nil)
+		w := httptest.NewRecorder()
+		api.HandleSymbolAnalysis(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, body %s", query, w.Code, w.Body.String())
+		}
+		if len(timeframes) != 1 || timeframes[0] != wantTimeframe {
+			t.Errorf("%s: fetched timeframes %v, want [%s]", query, timeframes, wantTimeframe)
+		}
+
+		var body map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if body["current_price"] != 129.5 {
+			t.Errorf("%s: current_price = %v, want the latest close 129.5", query, body["current_price"])
+		}
+		if body["status"] != "analyzed" || body["trend"] != "bullish" || body["symbol"] != "AAPL" {
+			t.Errorf("%s: status/trend/symbol = %v/%v/%v, want analyzed/bullish/AAPL", query, body["status"], body["trend"], body["symbol"])
+		}
+		rsiSignals, ok := body["rsi_signals"].(map[string]interface{})
+		if !ok || rsiSignals["rsi"] == nil {
+			t.Errorf("%s: rsi_signals = %v, want the current RSI", query, body["rsi_signals"])
+		}
+		if levels, ok := body["support_levels"].([]interface{}); !ok || len(levels) == 0 {
+			t.Errorf("%s: support_levels = %v, want at least one level", query, body["support_levels"])
+		}
+	}
+}
+
+func TestHandleSymbolAnalysis_RejectsShortHistory(t *testing.T) {
+	api := &API{
+		bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+			return trendingBars(10, 100, 0.5), nil
+		},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/analysis/symbol?symbol=AAPL", nil)
+	w := httptest.NewRecorder()
+	api.HandleSymbolAnalysis(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d for 10 bars", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestNearestLevels(t *testing.T) {
+	levels := []indicators.PriceLevel{{Price: 90}, {Price: 97}, {Price: 95}, {Price: 97}, {Price: 80}, {Price: 105}}
+
+	if got, want := nearestLevels(levels, 100, 75, true), []float64{97, 95, 90}; !reflect.DeepEqual(got, want) {
+		t.Errorf("support levels = %v, want %v", got, want)
+	}
+	if got, want := nearestLevels(levels, 100, 110, false), []float64{105}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resistance levels = %v, want %v", got, want)
+	}
+	if got, want := nearestLevels(levels, 120, 130, false), []float64{130}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resistance without swing highs = %v, want the range high %v", got, want)
+	}
+}
+
+func TestHandleAnalysisReport_RejectsShortHistory(t *testing.T) {
+	api := &API{
+		bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+			return trendingBars(10, 100, 0.5), nil
+		},
+	}
+	w := httptest.NewRecorder()
+	api.HandleAnalysisReport(w, httptest.NewRequest(http.MethodGet, "/api/analysis/report?symbol=AAPL", nil))
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want %d for 10 bars", w.Code, http.StatusUnprocessableEntity)
+	}
+}
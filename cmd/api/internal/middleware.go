@@ -1,8 +1,12 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
 func CorsMiddleware(next http.Handler) http.Handler {
@@ -18,7 +22,13 @@ func CorsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func JWTAuthMiddleware(jwtMgr *JWTManager) func(http.Handler) http.Handler {
+type contextKey string
+
+const userIDContextKey contextKey = "user_id"
+
+// rejects requests without a valid "Authorization: Bearer <token>" issued by jwtMgr with 401, and hands the
+// token's user ID to the handler through the request context (see UserIDFromContext)
+func AuthMiddleware(jwtMgr *JWTManager) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -27,22 +37,60 @@ func JWTAuthMiddleware(jwtMgr *JWTManager) func(http.Handler) http.Handler {
 				return
 			}
 
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
+			scheme, token, ok := strings.Cut(authHeader, " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
 				WriteError(w, http.StatusUnauthorized, "Invalid authorization header format")
 				return
 			}
 
-			token := parts[1]
-			claims, err := jwtMgr.ValidateToken(token)
-			if err != nil {
-				WriteError(w, http.StatusUnauthorized, "Invalid or expired token")
+			claims, err := jwtMgr.ValidateToken(strings.TrimSpace(token))
+			if errors.Is(err, jwt.ErrTokenExpired) {
+				WriteError(w, http.StatusUnauthorized, "Token expired")
+				return
+			}
+			if err != nil || claims.UserID == "" {
+				WriteError(w, http.StatusUnauthorized, "Invalid token")
 				return
 			}
 
-			// Store claims in request context for use in handlers
-			r.Header.Set("X-User-ID", claims.UserID)
-			next.ServeHTTP(w, r)
+			ctx := context.WithValue(r.Context(), userIDContextKey, claims.UserID)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// the user ID AuthMiddleware stored for the request, false on unauthenticated routes
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDContextKey).(string)
+	return userID, ok && userID != ""
+}
+
+// a route mounted behind AuthMiddleware
+type Route struct {
+	Method  string
+	Pattern string
+	Handler http.HandlerFunc
+}
+
+// every route that places orders, closes positions or writes to the database; cmd/api mounts them behind
+// AuthMiddleware so none of them accepts an unauthenticated request
+func (api *API) ProtectedRoutes() []Route {
+	return []Route{
+		{http.MethodPost, "/api/execute-trade", api.HandleExecuteTrade},
+		{http.MethodPost, "/api/trades", api.HandleExecuteTrade},
+		{http.MethodPost, "/api/trades/sell-all", api.HandleSellAllTrades},
+		{http.MethodPost, "/api/trades/import-from-alpaca", api.HandleImportTradesFromAlpaca},
+		{http.MethodDelete, "/api/positions/{symbol}", api.HandleClosePosition},
+		{http.MethodPost, "/api/positions/{symbol}/oco", api.HandleAttachOCOExit},
+		{http.MethodPost, "/api/watchlist", api.HandleAddToWatchlist},
+		{http.MethodDelete, "/api/watchlist", api.HandleRemoveFromWatchlist},
+		{http.MethodPut, "/api/watchlist/refresh-scores", api.HandleRefreshWatchlistScores},
+		{http.MethodPost, "/api/settings", api.HandleUpdateSettings},
+		{http.MethodPost, "/api/alert-rules", api.HandleCreateAlertRule},
+		{http.MethodPut, "/api/alert-rules/{id}", api.HandleUpdateAlertRule},
+		{http.MethodDelete, "/api/alert-rules/{id}", api.HandleDeleteAlertRule},
+		{http.MethodPost, "/api/backtest", api.HandleStartBacktest},
+		{http.MethodPost, "/api/assets/refresh", api.HandleRefreshAssets},
+		{http.MethodDelete, "/api/problem-symbols/{symbol}", api.HandleClearProblemSymbol},
+	}
+}
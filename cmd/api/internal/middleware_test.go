@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serveWithAuth(t *testing.T, jm *JWTManager, authHeader string) (*httptest.ResponseRecorder, string, bool) {
+	t.Helper()
+	var userID string
+	called := false
+	handler := AuthMiddleware(jm)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		userID, _ = UserIDFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/execute-trade", nil)
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec, userID, called
+}
+
+func TestAuthMiddlewarePassesUserIDForValidToken(t *testing.T) {
+	jm := &JWTManager{secretKey: "test-secret"}
+	token, err := jm.GenerateToken("user-42", "trader@example.com", 1)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	rec, userID, called := serveWithAuth(t, jm, "Bearer "+token)
+	if !called || rec.Code != http.StatusNoContent {
+		t.Fatalf("expected the handler to run, got status %d", rec.Code)
+	}
+	if userID != "user-42" {
+		t.Errorf("expected user-42 in the request context, got %q", userID)
+	}
+}
+
+func TestAuthMiddlewareRejectsBadTokens(t *testing.T) {
+	jm := &JWTManager{secretKey: "test-secret"}
+	expired, err := jm.GenerateToken("user-42", "trader@example.com", -1)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	otherKey, err := (&JWTManager{secretKey: "other-secret"}).GenerateToken("user-42", "trader@example.com", 1)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		header  string
+		message string
+	}{
+		{"missing header", "", "Missing authorization header"},
+		{"wrong scheme", "Basic dXNlcjpwYXNz", "Invalid authorization header format"},
+		{"no token", "Bearer ", "Invalid authorization header format"},
+		{"malformed token", "Bearer not.a.jwt", "Invalid token"},
+		{"wrong signing key", "Bearer " + otherKey, "Invalid token"},
+		{"expired token", "Bearer " + expired, "Token expired"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec, _, called := serveWithAuth(t, jm, tc.header)
+			if called {
+				t.Fatal("handler should not run without a valid token")
+			}
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("expected 401, got %d", rec.Code)
+			}
+			var body map[string]string
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if body["error"] != tc.message {
+				t.Errorf("expected error %q, got %q", tc.message, body["error"])
+			}
+		})
+	}
+}
+
+func TestUserIDFromContextWithoutMiddleware(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/watchlist", nil)
+	if userID, ok := UserIDFromContext(req.Context()); ok {
+		t.Errorf("expected no user ID on an unauthenticated request, got %q", userID)
+	}
+}
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	api := &API{}
+	jm := &JWTManager{secretKey: "test-secret"}
+	mux := http.NewServeMux()
+	registered := make(map[string]bool)
+	for _, route := range api.ProtectedRoutes() {
+		pattern := route.Method + " " + route.Pattern
+		registered[pattern] = true
+		mux.Handle(pattern, AuthMiddleware(jm)(route.Handler))
+	}
+
+	mutating := []struct{ method, path, pattern string }{
+		{http.MethodPost, "/api/execute-trade", "/api/execute-trade"},
+		{http.MethodPost, "/api/trades", "/api/trades"},
+		{http.MethodPost, "/api/trades/sell-all", "/api/trades/sell-all"},
+		{http.MethodPost, "/api/trades/import-from-alpaca", "/api/trades/import-from-alpaca"},
+		{http.MethodDelete, "/api/positions/AAPL", "/api/positions/{symbol}"},
+		{http.MethodPost, "/api/positions/AAPL/oco", "/api/positions/{symbol}/oco"},
+		{http.MethodPost, "/api/watchlist", "/api/watchlist"},
+		{http.MethodDelete, "/api/watchlist", "/api/watchlist"},
+		{http.MethodPut, "/api/watchlist/refresh-scores", "/api/watchlist/refresh-scores"},
+		{http.MethodPost, "/api/settings", "/api/settings"},
+		{http.MethodPost, "/api/alert-rules", "/api/alert-rules"},
+		{http.MethodPut, "/api/alert-rules/3", "/api/alert-rules/{id}"},
+		{http.MethodDelete, "/api/alert-rules/3", "/api/alert-rules/{id}"},
+		{http.MethodPost, "/api/backtest", "/api/backtest"},
+		{http.MethodPost, "/api/assets/refresh", "/api/assets/refresh"},
+		{http.MethodDelete, "/api/problem-symbols/AAPL", "/api/problem-symbols/{symbol}"},
+	}
+	for _, route := range mutating {
+		if !registered[route.method+" "+route.pattern] {
+			t.Errorf("%s %s is not a protected route", route.method, route.pattern)
+			continue
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(route.method, route.path, nil))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s without a token = %d, want 401", route.method, route.path, rec.Code)
+		}
+	}
+}
+
+func TestHandleGenerateToken_RequiresIssueKey(t *testing.T) {
+	api := &API{JWTManager: &JWTManager{secretKey: "test-secret", issueKey: "admin-key"}}
+	body := `{"user_id":"trader"}`
+
+	for _, tt := range []struct {
+		name       string
+		key        string
+		wantStatus int
+	}{
+		{"no key", "", http.StatusUnauthorized},
+		{"wrong key", "guess", http.StatusUnauthorized},
+		{"configured key", "admin-key", http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/token", strings.NewReader(body))
+		if tt.key != "" {
+			req.Header.Set("X-API-Key", tt.key)
+		}
+		rec := httptest.NewRecorder()
+		api.HandleGenerateToken(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, rec.Code, tt.wantStatus, rec.Body.String())
+		}
+	}
+}
@@ -8,10 +8,9 @@ import (
 )
 
 func (api *API) HandleGetNews(w http.ResponseWriter, r *http.Request) {
-	positions, err := api.AlpacaClient.GetPositions()
+	positions, err := api.alpacaClient(r).GetPositions()
 	if err != nil {
-		log.Printf("Error fetching positions: %v", err)
-		WriteError(w, http.StatusInternalServerError, "Failed to fetch positions")
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch positions")
 		return
 	}
 
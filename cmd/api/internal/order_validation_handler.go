@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+)
+
+// POST /api/execute-trade/validate runs the same checks as the CLI order preview (position limit,
+// daily loss, sizing, R:R) against the live account and positions, without placing anything
+func (api *API) HandleValidateOrder(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Symbol     string  `json:"symbol"`
+		AssetType  string  `json:"asset_type"`
+		Direction  string  `json:"direction"`   // LONG (default) or SHORT
+		Quantity   int64   `json:"quantity"`    // 0 sizes it from the config
+		EntryPrice float64 `json:"entry_price"` // 0 uses the latest close
+		StopLoss   float64 `json:"stop_loss"`   // 0 uses the configured stop percent
+		TakeProfit float64 `json:"take_profit"` // 0 uses the configured target percent
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	symbol, assetType := resolveSymbol(req.Symbol, req.AssetType)
+	if symbol == "" {
+		WriteError(w, http.StatusBadRequest, "Symbol is required")
+		return
+	}
+	direction := strings.ToUpper(req.Direction)
+	if direction == "" {
+		direction = "LONG"
+	}
+	if direction != "LONG" && direction != "SHORT" {
+		WriteError(w, http.StatusBadRequest, "Direction must be 'LONG' or 'SHORT'")
+		return
+	}
+	if req.Quantity < 0 {
+		WriteError(w, http.StatusBadRequest, "Quantity must not be negative")
+		return
+	}
+	// one consistent copy for the whole validation, in case settings swap the config meanwhile
+	orderConfig := api.OrderConfig.Snapshot()
+	if orderConfig == nil {
+		WriteError(w, http.StatusServiceUnavailable, "Order config not initialized")
+		return
+	}
+
+	client := api.alpacaClient(r)
+	account, err := client.GetAccount()
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch account")
+		return
+	}
+	accountValue, _ := account.Equity.Float64()
+
+	// broker positions are the source of truth; the position manager may not have synced yet
+	held, err := client.GetPositions()
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch positions")
+		return
+	}
+	openPositions := len(held)
+	dailyLoss, openRisk := 0.0, 0.0
+	if api.PositionManager != nil {
+		if tracked := api.PositionManager.CountOpenPositions(); tracked > openPositions {
+			openPositions = tracked
+		}
+		dailyLoss = api.PositionManager.GetDailyLoss()
+		openRisk = api.PositionManager.OpenRiskAmount()
+	}
+
+	entry := req.EntryPrice
+	if entry <= 0 {
+		bars, err := api.fetchBars(symbol, "1Day", 1, assetType)
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch market data")
+			return
+		}
+		entry = bars[len(bars)-1].Close
+	}
+
+	assetConfig := orderConfig.ForAsset(symbol, assetType)
+	stopLoss, takeProfit := strategy.CalculatePriceTargets(entry, direction, assetConfig)
+	if req.StopLoss > 0 {
+		stopLoss = req.StopLoss
+	}
+	if req.TakeProfit > 0 {
+		takeProfit = req.TakeProfit
+	}
+
+	// an explicit stop is only warned about; auto_widen applies to the configured percent stop
+	warnings := []string{}
+	var stopNoise *strategy.StopNoiseCheck
+	if api.Config != nil && api.Config.StopNoise.Enabled {
+		noiseCfg := api.Config.StopNoise
+		if req.StopLoss > 0 {
+			noiseCfg.AutoWiden = false
+		}
+		bars, err := api.fetchBars(symbol, "1Day", strategy.StopNoiseBarsNeeded(noiseCfg)+5, assetType)
+		if err == nil {
+			var atr float64
+			atr, err = strategy.StopNoiseATR(bars, noiseCfg)
+			if err == nil {
+				check := strategy.CheckStopNoise(entry, stopLoss, atr, direction, noiseCfg)
+				stopLoss, stopNoise = check.Stop, &check
+				if warning := check.Warning(); warning != "" {
+					warnings = append(warnings, warning)
+				}
+			}
+		}
+		if err != nil {
+			log.Printf("Stop noise check skipped for %s: %v", symbol, err)
+			warnings = append(warnings, "Stop distance not checked against ATR: market data unavailable")
+		}
+	}
+
+	quantity := req.Quantity
+	beta := 0.0
+	if quantity == 0 {
+		openRiskPercent := 0.0
+		if accountValue > 0 {
+			openRiskPercent = openRisk / accountValue * 100
+		}
+		riskBudget, adjustedBeta, err := assetConfig.BetaAdjustedRisk(symbol, assetConfig.MaxPortfolioPercent)
+		if err != nil {
+			log.Printf("Sizing %s without beta adjustment: %v", symbol, err)
+			warnings = append(warnings, "Position not beta-adjusted: beta unavailable")
+		}
+		beta = adjustedBeta
+		quantity = strategy.CalculateScaledPositionSize(accountValue, entry, stopLoss, riskBudget, openPositions, openRiskPercent, assetConfig)
+	}
+
+	orderReq := &strategy.OrderRequest{
+		Symbol:          symbol,
+		Quantity:        quantity,
+		Direction:       direction,
+		StopLossPrice:   stopLoss,
+		TakeProfitPrice: takeProfit,
+		EntryPrice:      entry,
+		AssetType:       assetType,
+	}
+	validation := strategy.ValidateOrder(orderReq, orderConfig, accountValue, openPositions, dailyLoss)
+
+	riskReward := 0.0
+	if validation.RiskAmount > 0 {
+		riskReward = validation.PotentialGain / validation.RiskAmount
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"valid":          validation.IsValid,
+		"issues":         validation.Issues,
+		"warnings":       warnings,
+		"stop_noise":     stopNoise,
+		"beta":           beta, // 0 unless the quantity was beta-adjusted
+		"symbol":         symbol,
+		"direction":      direction,
+		"quantity":       validation.Quantity,
+		"entry_price":    entry,
+		"stop_loss":      stopLoss,
+		"take_profit":    takeProfit,
+		"risk_amount":    validation.RiskAmount,
+		"portfolio_risk": validation.PortfolioRisk,
+		"potential_gain": validation.PotentialGain,
+		"risk_reward":    riskReward,
+		"account_value":  accountValue,
+		"open_positions": openPositions,
+		"daily_loss":     dailyLoss,
+	})
+}
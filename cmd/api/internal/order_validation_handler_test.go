@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+// a fixed account and position book that fails the test if an order is placed
+type accountSnapshotClient struct {
+	slowTradingClient
+	t         *testing.T
+	equity    float64
+	positions []alpaca.Position
+}
+
+func (c accountSnapshotClient) GetAccount() (*alpaca.Account, error) {
+	return &alpaca.Account{Equity: decimal.NewFromFloat(c.equity)}, nil
+}
+
+func (c accountSnapshotClient) GetPositions() ([]alpaca.Position, error) {
+	return c.positions, nil
+}
+
+func (c accountSnapshotClient) PlaceOrder(req alpaca.PlaceOrderRequest) (*alpaca.Order, error) {
+	c.t.Errorf("PlaceOrder(%s) called by a dry validation", req.Symbol)
+	return &alpaca.Order{}, nil
+}
+
+type orderValidationResponse struct {
+	Valid         bool     `json:"valid"`
+	Issues        []string `json:"issues"`
+	Quantity      int64    `json:"quantity"`
+	RiskAmount    float64  `json:"risk_amount"`
+	PortfolioRisk float64  `json:"portfolio_risk"`
+	PotentialGain float64  `json:"potential_gain"`
+	RiskReward    float64  `json:"risk_reward"`
+	OpenPositions int      `json:"open_positions"`
+	StopLoss      float64  `json:"stop_loss"`
+	Warnings      []string `json:"warnings"`
+}
+
+func postOrderValidation(t *testing.T, api *API, body string) orderValidationResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/execute-trade/validate", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	api.HandleValidateOrder(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var resp orderValidationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return resp
+}
+
+func validationOrderConfig() *strategy.OrderConfig {
+	return &strategy.OrderConfig{
+		MaxOpenPositions:    2,
+		MaxPortfolioPercent: 20,
+		StopLossPercent:     2,
+		TakeProfitPercent:   5,
+		MaxDailyLossPercent: -2,
+	}
+}
+
+func TestHandleValidateOrder_ValidOrder(t *testing.T) {
+	api := &API{
+		AlpacaClient: accountSnapshotClient{t: t, equity: 100000},
+		OrderConfig:  validationOrderConfig(),
+	}
+
+	resp := postOrderValidation(t, api, `{"symbol":"aapl","direction":"LONG","quantity":50,"entry_price":100,"stop_loss":98,"take_profit":105}`)
+
+	if !resp.Valid || len(resp.Issues) != 0 {
+		t.Fatalf("valid = %v, issues %v; want a clean order", resp.Valid, resp.Issues)
+	}
+	if resp.Quantity != 50 || resp.RiskAmount != 100 || resp.PotentialGain != 250 {
+		t.Errorf("quantity/risk/gain = %d/%.2f/%.2f, want 50/100/250", resp.Quantity, resp.RiskAmount, resp.PotentialGain)
+	}
+	if resp.PortfolioRisk != 0.1 {
+		t.Errorf("portfolio_risk = %.4f, want 0.1", resp.PortfolioRisk)
+	}
+	if resp.RiskReward != 2.5 {
+		t.Errorf("risk_reward = %.2f, want 2.5", resp.RiskReward)
+	}
+}
+
+func TestHandleValidateOrder_PositionLimitReached(t *testing.T) {
+	api := &API{
+		AlpacaClient: accountSnapshotClient{t: t, equity: 100000, positions: []alpaca.Position{
+			{Symbol: "MSFT"}, {Symbol: "NVDA"},
+		}},
+		OrderConfig: validationOrderConfig(),
+	}
+
+	resp := postOrderValidation(t, api, `{"symbol":"AAPL","quantity":50,"entry_price":100,"stop_loss":98,"take_profit":105}`)
+
+	if resp.Valid {
+		t.Fatalf("order validated with 2/2 positions open")
+	}
+	if resp.OpenPositions != 2 {
+		t.Errorf("open_positions = %d, want 2", resp.OpenPositions)
+	}
+	if len(resp.Issues) != 1 || !strings.Contains(resp.Issues[0], "Max open positions") {
+		t.Errorf("issues = %v, want only the position limit", resp.Issues)
+	}
+}
+
+func TestHandleValidateOrder_TightStopWarnsAgainstATR(t *testing.T) {
+	// $2 daily ranges around $100 give an ATR of 2, four times the 0.5% stop
+	bars := make([]types.Bar, 20)
+	for i := range bars {
+		bars[i] = types.Bar{High: 101, Low: 99, Close: 100}
+	}
+	orderConfig := validationOrderConfig()
+	orderConfig.StopLossPercent = 0.5
+	api := &API{
+		AlpacaClient: accountSnapshotClient{t: t, equity: 100000},
+		OrderConfig:  orderConfig,
+		Config:       &config.Config{StopNoise: config.StopNoiseConfig{Enabled: true, MinATRMultiple: 1}},
+		bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+			return bars, nil
+		},
+	}
+
+	resp := postOrderValidation(t, api, `{"symbol":"AAPL","quantity":10,"entry_price":100}`)
+	if resp.StopLoss != 99.5 || len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "noise") {
+		t.Errorf("stop %.2f, warnings %v; want the 0.5%% stop kept with a noise warning", resp.StopLoss, resp.Warnings)
+	}
+
+	api.Config.StopNoise.AutoWiden = true
+	resp = postOrderValidation(t, api, `{"symbol":"AAPL","quantity":10,"entry_price":100}`)
+	if resp.StopLoss != 98 || len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "widened") {
+		t.Errorf("stop %.2f, warnings %v; want the stop widened to one ATR at $98", resp.StopLoss, resp.Warnings)
+	}
+}
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"log"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+// latest price for a symbol, used to value a position Alpaca returned without a market value
+type quoteFetcher func(symbol string) (decimal.Decimal, error)
+
+// totals over the positions whose value is known; incomplete ones are listed instead of counted as zero
+type portfolioTotals struct {
+	Value      decimal.Decimal
+	Cost       decimal.Decimal
+	Gain       decimal.Decimal
+	Estimated  []string // valued from current price or a live quote instead of Alpaca's market value
+	Incomplete []string // no market value, current price or quote; left out of every total
+}
+
+// sums value, cost and unrealized P&L, falling back to qty x current price and then to quote for a missing
+// market value; a nil quote skips the live lookup
+func summarizePositions(positions []alpaca.Position, quote quoteFetcher) portfolioTotals {
+	totals := portfolioTotals{Estimated: []string{}, Incomplete: []string{}}
+
+	for _, pos := range positions {
+		var marketValue decimal.Decimal
+		switch {
+		case pos.MarketValue != nil:
+			marketValue = *pos.MarketValue
+		case pos.CurrentPrice != nil:
+			marketValue = pos.Qty.Mul(*pos.CurrentPrice)
+			totals.Estimated = append(totals.Estimated, pos.Symbol)
+		default:
+			price := decimal.Zero
+			if quote != nil {
+				fetched, err := quote(pos.Symbol)
+				if err != nil {
+					log.Printf("No quote to value %s: %v", pos.Symbol, err)
+				} else {
+					price = fetched
+				}
+			}
+			if !price.IsPositive() {
+				totals.Incomplete = append(totals.Incomplete, pos.Symbol)
+				continue
+			}
+			marketValue = pos.Qty.Mul(price)
+			totals.Estimated = append(totals.Estimated, pos.Symbol)
+		}
+
+		totals.Value = totals.Value.Add(marketValue)
+		totals.Cost = totals.Cost.Add(pos.CostBasis)
+		if pos.UnrealizedPL != nil && pos.MarketValue != nil {
+			totals.Gain = totals.Gain.Add(*pos.UnrealizedPL)
+		} else {
+			// Alpaca's P&L goes with its market value, so an estimated value gets a matching estimated P&L
+			totals.Gain = totals.Gain.Add(marketValue.Sub(pos.CostBasis))
+		}
+	}
+	return totals
+}
+
+// latest daily close through the bar fetch, for summarizePositions
+func (api *API) latestClose(symbol string) (decimal.Decimal, error) {
+	symbol, assetType := resolveSymbol(symbol, "")
+	bars, err := api.fetchBars(symbol, "1Day", 1, assetType)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if len(bars) == 0 {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromFloat(bars[len(bars)-1].Close), nil
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+)
+
+func summaryPositions() []alpaca.Position {
+	dec := func(v float64) *decimal.Decimal { d := decimal.NewFromFloat(v); return &d }
+	return []alpaca.Position{
+		{Symbol: "AAPL", Qty: decimal.NewFromInt(10), CostBasis: decimal.NewFromInt(1800), MarketValue: dec(1900), UnrealizedPL: dec(100)},
+		// Alpaca returned no market value, current price or P&L
+		{Symbol: "MSFT", Qty: decimal.NewFromInt(5), CostBasis: decimal.NewFromInt(2000)},
+	}
+}
+
+type portfolioSummaryResponse struct {
+	TotalValue  string   `json:"total_value"`
+	TotalCost   string   `json:"total_cost"`
+	TotalGain   string   `json:"total_gain"`
+	Complete    bool     `json:"complete"`
+	Incomplete  int      `json:"incomplete_positions"`
+	Symbols     []string `json:"incomplete_symbols"`
+	Estimated   []string `json:"estimated_symbols"`
+	Positions   int      `json:"total_positions"`
+	Explanation string   `json:"note"`
+}
+
+func getPortfolioSummary(t *testing.T, api *API) portfolioSummaryResponse {
+	t.Helper()
+	w := httptest.NewRecorder()
+	api.HandlePortfolioSummary(w, httptest.NewRequest(http.MethodGet, "/api/portfolio-summary", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var resp portfolioSummaryResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return resp
+}
+
+func TestHandlePortfolioSummary_MissingMarketValueIsFlagged(t *testing.T) {
+	api := &API{
+		AlpacaClient: accountSnapshotClient{t: t, positions: summaryPositions()},
+		bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+			return nil, errors.New("no data")
+		},
+		Config: &config.Config{},
+	}
+	api.Config.Features.QuoteMissingMarketValues = true
+
+	resp := getPortfolioSummary(t, api)
+	if resp.Complete || resp.Incomplete != 1 || len(resp.Symbols) != 1 || resp.Symbols[0] != "MSFT" || resp.Explanation == "" {
+		t.Errorf("response = %+v, want MSFT flagged as incomplete", resp)
+	}
+	// MSFT's $2000 cost would otherwise show up as a $2000 loss against a zero value
+	if resp.TotalValue != "1900" || resp.TotalCost != "1800" || resp.TotalGain != "100" || resp.Positions != 2 {
+		t.Errorf("totals = %s/%s/%s, want AAPL alone at 1900/1800/100", resp.TotalValue, resp.TotalCost, resp.TotalGain)
+	}
+}
+
+func TestHandlePortfolioSummary_QuoteFillsMissingMarketValue(t *testing.T) {
+	api := &API{
+		AlpacaClient: accountSnapshotClient{t: t, positions: summaryPositions()},
+		bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+			return []types.Bar{{Close: 420}}, nil
+		},
+		Config: &config.Config{},
+	}
+	api.Config.Features.QuoteMissingMarketValues = true
+
+	resp := getPortfolioSummary(t, api)
+	if !resp.Complete || len(resp.Estimated) != 1 || resp.Estimated[0] != "MSFT" {
+		t.Errorf("response = %+v, want MSFT valued from the quote", resp)
+	}
+	if resp.TotalValue != "4000" || resp.TotalCost != "3800" || resp.TotalGain != "200" {
+		t.Errorf("totals = %s/%s/%s, want 4000/3800/200", resp.TotalValue, resp.TotalCost, resp.TotalGain)
+	}
+
+	// with quoting off the position is flagged rather than looked up
+	api.Config.Features.QuoteMissingMarketValues = false
+	if resp := getPortfolioSummary(t, api); resp.Incomplete != 1 || resp.TotalValue != "1900" {
+		t.Errorf("quoting off: %+v, want MSFT flagged", resp)
+	}
+}
@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
+	"github.com/fazecat/mogulmaker/Internal/strategy/signals"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/analyzer"
+)
+
+const positionAdviceBars = 250
+
+// GET /api/positions/{symbol}/advice?timeframe=1Day analyzes fresh bars against the held position's
+// direction and entry and advises HOLD / ADD / TRIM / EXIT
+func (api *API) HandlePositionAdvice(w http.ResponseWriter, r *http.Request) {
+	symbol, assetType := resolveSymbol(r.PathValue("symbol"), r.URL.Query().Get("asset_type"))
+	if symbol == "" {
+		WriteError(w, http.StatusBadRequest, "Symbol is required")
+		return
+	}
+	timeframe := r.URL.Query().Get("timeframe")
+	if timeframe == "" {
+		timeframe = "1Day"
+	}
+
+	held, err := api.alpacaClient(r).GetPosition(symbol)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, "Position not found")
+		return
+	}
+
+	bars, err := api.fetchBars(symbol, timeframe, positionAdviceBars, assetType)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch market data")
+		return
+	}
+	analysis, err := analyzer.AnalyzeSymbolDetailed(symbol, bars)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, "Failed to analyze symbol")
+		return
+	}
+
+	direction := "LONG"
+	if strings.EqualFold(held.Side, "short") {
+		direction = "SHORT"
+	}
+	entry, _ := held.AvgEntryPrice.Float64()
+	quantity, _ := held.Qty.Abs().Float64()
+
+	// the fetch is latest first; the signal reads oldest first
+	signal := analysisSignal(symbol, reversedBars(bars), analysis)
+	pos := signals.PositionContext{
+		Direction:  direction,
+		EntryPrice: entry,
+	}
+	// the broker's mark, else the latest close
+	if held.CurrentPrice != nil {
+		pos.CurrentPrice, _ = held.CurrentPrice.Float64()
+	} else {
+		pos.CurrentPrice, _ = analysis["current_price"].(float64)
+	}
+	pos.RSI, _ = analysis["rsi"].(float64)
+	pos.Support, _ = analysis["support_level"].(float64)
+	pos.Resistance, _ = analysis["resistance_level"].(float64)
+
+	advice := signals.AdvisePosition(pos, signal)
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"symbol":         symbol,
+		"timeframe":      timeframe,
+		"direction":      direction,
+		"quantity":       quantity,
+		"entry_price":    entry,
+		"current_price":  pos.CurrentPrice,
+		"rsi":            pos.RSI,
+		"support":        pos.Support,
+		"resistance":     pos.Resistance,
+		"advice":         advice,
+		"recommendation": signal.Recommendation,
+		"signal_score":   signal.Score,
+		"divergence":     signal.DivergenceDetails,
+	})
+}
+
+// the combined signal for the latest of oldest-first bars, from the RSI/ATR the analyzer already computed
+func analysisSignal(symbol string, bars []types.Bar, analysis map[string]interface{}) signals.CombinedSignal {
+	rsi, _ := analysis["rsi"].(float64)
+	atr, _ := analysis["atr"].(float64)
+
+	closes := make([]float64, len(bars))
+	for i, bar := range bars {
+		closes[i] = bar.Close
+	}
+	rsiValues, err := indicators.CalculateRSI(closes, 14)
+	if err != nil {
+		rsiValues = []float64{}
+	}
+	return signals.CalculateSignal(&rsi, &atr, bars, symbol, analyzer.GetLatestCandlePattern(bars, 1), rsiValues)
+}
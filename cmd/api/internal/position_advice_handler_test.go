@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// answers GetPosition from a fixed set of holdings
+type heldPositionsClient struct {
+	slowTradingClient
+	held map[string]alpaca.Position
+}
+
+func (c heldPositionsClient) GetPosition(symbol string) (*alpaca.Position, error) {
+	pos, ok := c.held[symbol]
+	if !ok {
+		return nil, errors.New("position does not exist")
+	}
+	return &pos, nil
+}
+
+func TestHandlePositionAdvice_UsesHeldDirection(t *testing.T) {
+	api := &API{
+		AlpacaClient: heldPositionsClient{held: map[string]alpaca.Position{
+			"TSLA": {Symbol: "TSLA", Side: "short", Qty: decimal.NewFromInt(-5), AvgEntryPrice: decimal.NewFromInt(150)},
+		}},
+		bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+			return reversedBars(trendingBars(60, 100, 0.5)), nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/positions/tsla/advice", nil)
+	req.SetPathValue("symbol", "tsla")
+	w := httptest.NewRecorder()
+	api.HandlePositionAdvice(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Direction    string  `json:"direction"`
+		Quantity     float64 `json:"quantity"`
+		CurrentPrice float64 `json:"current_price"`
+		Advice       struct {
+			Action     string   `json:"action"`
+			Direction  string   `json:"direction"`
+			PnLPercent float64  `json:"pnl_percent"`
+			Reasons    []string `json:"reasons"`
+		} `json:"advice"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Direction != "SHORT" || resp.Advice.Direction != "SHORT" || resp.Quantity != 5 {
+		t.Errorf("response = %+v, want a 5 share SHORT", resp)
+	}
+	if resp.Advice.Action == "" || len(resp.Advice.Reasons) == 0 {
+		t.Errorf("advice = %+v, want an action with reasons", resp.Advice)
+	}
+	// short from 150, last close 129.5
+	if resp.CurrentPrice != 129.5 {
+		t.Errorf("current_price = %.2f, want the latest close 129.5", resp.CurrentPrice)
+	}
+	if resp.Advice.PnLPercent <= 0 {
+		t.Errorf("pnl_percent = %.2f, want a gain on the short", resp.Advice.PnLPercent)
+	}
+}
+
+func TestHandlePositionAdvice_PricesAtBrokerMark(t *testing.T) {
+	mark := decimal.NewFromFloat(131.25)
+	api := &API{
+		AlpacaClient: heldPositionsClient{held: map[string]alpaca.Position{
+			"AAPL": {Symbol: "AAPL", Side: "long", Qty: decimal.NewFromInt(5), AvgEntryPrice: decimal.NewFromInt(120), CurrentPrice: &mark},
+		}},
+		bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+			return reversedBars(trendingBars(60, 100, 0.5)), nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/positions/AAPL/advice", nil)
+	req.SetPathValue("symbol", "AAPL")
+	w := httptest.NewRecorder()
+	api.HandlePositionAdvice(w, req)
+
+	var resp struct {
+		CurrentPrice float64 `json:"current_price"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.CurrentPrice != 131.25 {
+		t.Errorf("current_price = %.2f, want the broker's 131.25 over the last close", resp.CurrentPrice)
+	}
+}
+
+func TestHandlePositionAdvice_NoPositionIs404(t *testing.T) {
+	api := &API{AlpacaClient: heldPositionsClient{}}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/positions/AAPL/advice", nil)
+	req.SetPathValue("symbol", "AAPL")
+	w := httptest.NewRecorder()
+	api.HandlePositionAdvice(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
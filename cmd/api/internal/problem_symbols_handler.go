@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+type problemSymbolStore interface {
+	GetFlaggedSymbols(ctx context.Context) ([]database.ProblemSymbol, error)
+	ClearProblemSymbol(ctx context.Context, symbol string) (int64, error)
+}
+
+func (api *API) problemSymbolStore() problemSymbolStore {
+	if api.problemSymbols != nil {
+		return api.problemSymbols
+	}
+	if api.Queries == nil {
+		return nil
+	}
+	return api.Queries
+}
+
+// GET /api/problem-symbols lists symbols flagged after repeated fetch failures (delisting_detection), which
+// every scan skips until they're cleared
+func (api *API) HandleGetProblemSymbols(w http.ResponseWriter, r *http.Request) {
+	store := api.problemSymbolStore()
+	if store == nil {
+		WriteError(w, http.StatusServiceUnavailable, "Database not initialized")
+		return
+	}
+
+	flagged, err := store.GetFlaggedSymbols(r.Context())
+	if err != nil {
+		log.Printf("Error fetching problem symbols: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch problem symbols")
+		return
+	}
+	if flagged == nil {
+		flagged = []database.ProblemSymbol{}
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"symbols": flagged,
+		"count":   len(flagged),
+	})
+}
+
+// DELETE /api/problem-symbols/{symbol} clears a flag and its failure streak so scans pick the symbol up again
+func (api *API) HandleClearProblemSymbol(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(strings.TrimSpace(r.PathValue("symbol")))
+	if symbol == "" {
+		WriteError(w, http.StatusBadRequest, "Symbol is required")
+		return
+	}
+	store := api.problemSymbolStore()
+	if store == nil {
+		WriteError(w, http.StatusServiceUnavailable, "Database not initialized")
+		return
+	}
+
+	cleared, err := store.ClearProblemSymbol(r.Context(), symbol)
+	if err != nil {
+		log.Printf("Error clearing problem symbol %s: %v", symbol, err)
+		WriteError(w, http.StatusInternalServerError, "Failed to clear problem symbol")
+		return
+	}
+	if cleared == 0 {
+		WriteError(w, http.StatusNotFound, fmt.Sprintf("%s is not a problem symbol", symbol))
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"symbol":  symbol,
+		"message": "Problem symbol cleared",
+	})
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+type fakeProblemSymbols struct {
+	flagged map[string]database.ProblemSymbol
+}
+
+func (f *fakeProblemSymbols) GetFlaggedSymbols(ctx context.Context) ([]database.ProblemSymbol, error) {
+	var rows []database.ProblemSymbol
+	for _, row := range f.flagged {
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (f *fakeProblemSymbols) ClearProblemSymbol(ctx context.Context, symbol string) (int64, error) {
+	if _, ok := f.flagged[symbol]; !ok {
+		return 0, nil
+	}
+	delete(f.flagged, symbol)
+	return 1, nil
+}
+
+func TestProblemSymbolsHandlers_ListAndClear(t *testing.T) {
+	store := &fakeProblemSymbols{flagged: map[string]database.ProblemSymbol{
+		"GONE": {Symbol: "GONE", ConsecutiveFailures: 3, LastError: "asset not found", Flagged: true,
+			FlaggedAt: sql.NullTime{Time: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), Valid: true}},
+	}}
+	api := &API{problemSymbols: store}
+
+	w := httptest.NewRecorder()
+	api.HandleGetProblemSymbols(w, httptest.NewRequest(http.MethodGet, "/api/problem-symbols", nil))
+	var list struct {
+		Count   int                      `json:"count"`
+		Symbols []database.ProblemSymbol `json:"symbols"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("status %d, decode %v: %s", w.Code, err, w.Body.String())
+	}
+	if list.Count != 1 || list.Symbols[0].Symbol != "GONE" {
+		t.Errorf("list = %+v, want GONE", list)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/problem-symbols/gone", nil)
+	req.SetPathValue("symbol", "gone")
+	w = httptest.NewRecorder()
+	api.HandleClearProblemSymbol(w, req)
+	if w.Code != http.StatusOK || len(store.flagged) != 0 {
+		t.Errorf("clear: status %d, %d still flagged", w.Code, len(store.flagged))
+	}
+
+	w = httptest.NewRecorder()
+	api.HandleClearProblemSymbol(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("clearing an unflagged symbol: status %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/position"
+)
+
+// GET /api/risk/report?format=json|text&download=true returns the risk report the CLI dashboard prints;
+// download adds an attachment header so the browser saves it for archiving
+func (api *API) HandleRiskReport(w http.ResponseWriter, r *http.Request) {
+	if !api.requireRiskManager(w) {
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "text" {
+		WriteError(w, http.StatusBadRequest, "format must be 'json' or 'text'")
+		return
+	}
+
+	var positions []*position.OpenPosition
+	if api.PositionManager != nil {
+		if err := api.PositionManager.SyncFromAlpaca(r.Context()); err != nil {
+			log.Printf("Warning: Could not sync positions for risk report: %v", err)
+		}
+		positions = api.PositionManager.GetOpenPositions()
+	}
+	report := api.RiskManager.GenerateRiskReport(positions)
+
+	var body []byte
+	contentType, extension := "application/json", "json"
+	if format == "text" {
+		var buf bytes.Buffer
+		report.WriteText(&buf)
+		body = buf.Bytes()
+		contentType, extension = "text/plain; charset=utf-8", "txt"
+	} else {
+		var err error
+		if body, err = report.ToJSON(); err != nil {
+			log.Printf("Error encoding risk report: %v", err)
+			WriteError(w, http.StatusInternalServerError, "Failed to encode risk report")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if r.URL.Query().Get("download") == "true" {
+		filename := fmt.Sprintf("risk-report-%s.%s", report.Timestamp.Format("20060102-150405"), extension)
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/handlers/risk"
+)
+
+func TestHandleRiskReport_Formats(t *testing.T) {
+	api := &API{RiskManager: risk.NewManager(nil, 50000)}
+
+	w := httptest.NewRecorder()
+	api.HandleRiskReport(w, httptest.NewRequest(http.MethodGet, "/api/risk/report?format=json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("json status = %d, body %s", w.Code, w.Body.String())
+	}
+	var report struct {
+		AccountBalance float64 `json:"account_balance"`
+		HealthStatus   string  `json:"health_status"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.HealthStatus != "HEALTHY" || report.AccountBalance != 50000 {
+		t.Errorf("report = %+v, want a HEALTHY $50000 account", report)
+	}
+
+	w = httptest.NewRecorder()
+	api.HandleRiskReport(w, httptest.NewRequest(http.MethodGet, "/api/risk/report?format=text&download=true", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "PORTFOLIO RISK REPORT") {
+		t.Errorf("text status = %d, body %q", w.Code, w.Body.String())
+	}
+	if disposition := w.Header().Get("Content-Disposition"); !strings.HasPrefix(disposition, "attachment") || !strings.Contains(disposition, ".txt") {
+		t.Errorf("Content-Disposition = %q, want a .txt attachment", disposition)
+	}
+
+	w = httptest.NewRecorder()
+	api.HandleRiskReport(w, httptest.NewRequest(http.MethodGet, "/api/risk/report?format=pdf", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("pdf status = %d, want 400", w.Code)
+	}
+}
+
+func TestRiskEndpoints_NilRiskManagerReturns503(t *testing.T) {
+	api := &API{AlpacaClient: slowTradingClient{}}
+	endpoints := map[string]http.HandlerFunc{
+		"/api/risk":             api.HandleGetRiskStatus,
+		"/api/risk/report":      api.HandleRiskReport,
+		"/api/risk-adjustments": api.HandleRiskAdjustments,
+		"/api/risk-alerts":      api.HandleRiskAlerts,
+	}
+
+	for path, handler := range endpoints {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s status = %d, want 503", path, w.Code)
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || !strings.Contains(w.Body.String(), riskManagerUnavailable) {
+			t.Errorf("%s body = %s, want the risk manager unavailable message", path, w.Body.String())
+		}
+	}
+
+	if capital := api.defaultBacktestCapital(); capital != defaultBacktestCapital {
+		t.Errorf("backtest capital without a risk manager = %.2f, want %.2f", capital, defaultBacktestCapital)
+	}
+	if capital := (&API{RiskManager: risk.NewManager(nil, 0)}).defaultBacktestCapital(); capital != defaultBacktestCapital {
+		t.Errorf("backtest capital with no balance loaded = %.2f, want %.2f", capital, defaultBacktestCapital)
+	}
+}
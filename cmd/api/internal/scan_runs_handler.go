@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+const scanRunsDefaultDays = 30
+
+// GET /api/scan-runs?from=...&to=...&profile=... lists persisted scan runs oldest first, so the number of
+// candidates each scan found can be charted as a market-breadth indicator (features.persist_scan_runs)
+func (api *API) HandleScanRuns(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, to, ok := parseTimeRange(w, query, scanRunsDefaultDays)
+	if !ok {
+		return
+	}
+
+	store := api.scanRuns
+	if store == nil {
+		if api.Queries == nil {
+			WriteError(w, http.StatusServiceUnavailable, "Database not initialized")
+			return
+		}
+		store = api.Queries
+	}
+
+	profile := strings.TrimSpace(query.Get("profile"))
+	runs, err := store.GetScanRuns(r.Context(), database.GetScanRunsParams{FromTime: from, ToTime: to, Profile: profile})
+	if err != nil {
+		log.Printf("Error fetching scan runs: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch scan runs")
+		return
+	}
+	if runs == nil {
+		runs = []database.ScanRun{}
+	}
+
+	avgCandidates := 0.0
+	for _, run := range runs {
+		avgCandidates += float64(run.CandidatesFound)
+	}
+	if len(runs) > 0 {
+		avgCandidates /= float64(len(runs))
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"from":                    from.Format(time.RFC3339),
+		"to":                      to.Format(time.RFC3339),
+		"profile":                 profile,
+		"runs":                    runs,
+		"count":                   len(runs),
+		"avg_candidates_per_scan": avgCandidates,
+	})
+}
+
+// the from/to range of a chart endpoint, defaulting to the last defaultDays; a bare 'to' date covers that whole day.
+// Writes a 400 and returns false when the range is invalid
+func parseTimeRange(w http.ResponseWriter, query url.Values, defaultDays int) (time.Time, time.Time, bool) {
+	to := time.Now()
+	if value := query.Get("to"); value != "" {
+		parsed, dateOnly, err := parseImportTime(value)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "Invalid 'to': use YYYY-MM-DD or RFC3339")
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+	}
+	from := to.AddDate(0, 0, -defaultDays)
+	if value := query.Get("from"); value != "" {
+		parsed, _, err := parseImportTime(value)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "Invalid 'from': use YYYY-MM-DD or RFC3339")
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+	if !from.Before(to) {
+		WriteError(w, http.StatusBadRequest, "'from' must be before 'to'")
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
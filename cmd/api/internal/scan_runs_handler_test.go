@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+type recordedScanRuns struct {
+	runs []database.ScanRun
+	last database.GetScanRunsParams
+}
+
+func (f *recordedScanRuns) InsertScanRun(ctx context.Context, arg database.InsertScanRunParams) (int32, error) {
+	f.runs = append(f.runs, database.ScanRun{ID: int32(len(f.runs) + 1), ProfileName: arg.ProfileName, CandidatesFound: arg.CandidatesFound, RanAt: arg.RanAt})
+	return int32(len(f.runs)), nil
+}
+
+func (f *recordedScanRuns) GetScanRuns(ctx context.Context, arg database.GetScanRunsParams) ([]database.ScanRun, error) {
+	f.last = arg
+	var runs []database.ScanRun
+	for _, run := range f.runs {
+		if !run.RanAt.Before(arg.FromTime) && run.RanAt.Before(arg.ToTime) {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+func TestHandleScanRuns_FiltersByDateRange(t *testing.T) {
+	store := &recordedScanRuns{}
+	for _, d := range []int{1, 2, 9} {
+		store.InsertScanRun(context.Background(), database.InsertScanRunParams{
+			ProfileName:     "balanced",
+			CandidatesFound: int32(d),
+			RanAt:           time.Date(2024, 6, d, 14, 0, 0, 0, time.UTC),
+		})
+	}
+	api := &API{scanRuns: store}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/scan-runs?from=2024-06-01&to=2024-06-02&profile=balanced", nil)
+	w := httptest.NewRecorder()
+	api.HandleScanRuns(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Count         int                `json:"count"`
+		Runs          []database.ScanRun `json:"runs"`
+		AvgCandidates float64            `json:"avg_candidates_per_scan"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	// a bare 'to' date covers that whole day
+	if resp.Count != 2 || resp.AvgCandidates != 1.5 {
+		t.Errorf("response = %+v, want the runs on June 1 and 2", resp)
+	}
+	if store.last.Profile != "balanced" || !store.last.ToTime.Equal(time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("query params = %+v, want profile balanced up to June 3", store.last)
+	}
+}
+
+func TestHandleScanRuns_RejectsBadRange(t *testing.T) {
+	api := &API{scanRuns: &recordedScanRuns{}}
+	for _, query := range []string{"from=yesterday", "from=2024-06-05&to=2024-06-01"} {
+		w := httptest.NewRecorder()
+		api.HandleScanRuns(w, httptest.NewRequest(http.MethodGet, "/api/scan-runs?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, w.Code)
+		}
+	}
+}
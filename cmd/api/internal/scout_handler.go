@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/signals"
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+	"github.com/fazecat/mogulmaker/Internal/utils/scanner"
+)
+
+// most symbols one POST /api/scout may score; bigger lists belong to the full scan
+const maxScoutSymbols = 50
+
+type symbolScorer func(ctx context.Context, profileName string, symbols []string, minScore float64, cfg *config.Config) ([]types.Candidate, *scanner.SkipSummary, error)
+
+func (api *API) scoreSymbols(ctx context.Context, profileName string, symbols []string, minScore float64, cfg *config.Config) ([]types.Candidate, *scanner.SkipSummary, error) {
+	if api.scorer != nil {
+		return api.scorer(ctx, profileName, symbols, minScore, cfg)
+	}
+	return scanner.ScoreSymbols(ctx, profileName, symbols, minScore, cfg)
+}
+
+// POST /api/scout scores just the listed symbols through the scan pipeline and returns them ranked
+func (api *API) HandleScoutSymbols(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Symbols  []string `json:"symbols"`
+		Profile  string   `json:"profile"`   // screener criteria to score with, api_scout by default
+		MinScore *float64 `json:"min_score"` // omitted returns every symbol that scored
+		MinGrade string   `json:"min_grade"` // A-F; omitted keeps every grade
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	symbols, err := normalizeScoutSymbols(req.Symbols)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	profile := strings.TrimSpace(req.Profile)
+	if profile == "" {
+		profile = "api_scout"
+	}
+	minScore := 0.0
+	if req.MinScore != nil {
+		minScore = *req.MinScore
+	}
+	minGrade := strings.ToUpper(strings.TrimSpace(req.MinGrade))
+	if minGrade != "" && !signals.ValidGrade(minGrade) {
+		WriteError(w, http.StatusBadRequest, "min_grade must be one of A, B, C, D or F")
+		return
+	}
+
+	cfg := api.Config
+	if cfg == nil {
+		if loaded, err := config.LoadConfig(); err == nil {
+			cfg = loaded
+		} else {
+			log.Printf("Warning: Could not load config for scout, using defaults: %v", err)
+		}
+	}
+
+	candidates, skips, err := api.scoreSymbols(r.Context(), profile, symbols, minScore, cfg)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Scoring failed")
+		return
+	}
+	if minGrade != "" {
+		candidates = scanner.FilterByGrade(candidates, minGrade)
+	}
+
+	response := scanner.FormatScoutResults(candidates, len(symbols), len(symbols), minScore)
+	response["profile"] = profile
+	response["symbols"] = symbols
+	response["skip_summary"] = skips
+	if minGrade != "" {
+		response["min_grade"] = minGrade
+	}
+	WriteJSON(w, http.StatusOK, response)
+}
+
+// POST /api/assets/refresh relists the tradable universe now instead of waiting out asset_cache.ttl_hours
+func (api *API) HandleRefreshAssets(w http.ResponseWriter, r *http.Request) {
+	status, err := scanner.RefreshTradableAssets()
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to refresh tradable assets")
+		return
+	}
+	WriteJSON(w, http.StatusOK, status)
+}
+
+// upper-cases, trims and de-duplicates the requested symbols, keeping their order
+func normalizeScoutSymbols(requested []string) ([]string, error) {
+	seen := make(map[string]bool, len(requested))
+	symbols := make([]string, 0, len(requested))
+	for _, raw := range requested {
+		symbol := strings.ToUpper(strings.TrimSpace(raw))
+		if symbol == "" {
+			return nil, fmt.Errorf("symbols must not be blank")
+		}
+		if seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		symbols = append(symbols, symbol)
+	}
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("symbols is required")
+	}
+	if len(symbols) > maxScoutSymbols {
+		return nil, fmt.Errorf("at most %d symbols can be scored at once, got %d", maxScoutSymbols, len(symbols))
+	}
+	return symbols, nil
+}
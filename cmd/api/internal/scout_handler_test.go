@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fazecat/mogulmaker/Internal/types"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+	"github.com/fazecat/mogulmaker/Internal/utils/scanner"
+)
+
+func TestHandleScoutSymbols_ScoresListRanked(t *testing.T) {
+	var gotProfile string
+	var gotSymbols []string
+	var gotMinScore float64
+	api := &API{
+		Config: &config.Config{},
+		scorer: func(ctx context.Context, profileName string, symbols []string, minScore float64, cfg *config.Config) ([]types.Candidate, *scanner.SkipSummary, error) {
+			gotProfile, gotSymbols, gotMinScore = profileName, symbols, minScore
+			candidates := []types.Candidate{
+				{Symbol: "AAPL", Score: 6.5, Direction: "LONG"},
+				{Symbol: "MSFT", Score: 8.2, Direction: "LONG"},
+				{Symbol: "NVDA", Score: 7.1, Direction: "SHORT"},
+			}
+			scanner.RankCandidates(candidates)
+			return candidates, scanner.NewSkipSummary(true), nil
+		},
+	}
+
+	body := `{"symbols": ["aapl", " MSFT ", "nvda", "AAPL"], "profile": "swing", "min_score": 5}`
+	req := httptest.NewRequest(http.MethodPost, "/api/scout", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	api.HandleScoutSymbols(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	if gotProfile != "swing" || gotMinScore != 5 || strings.Join(gotSymbols, ",") != "AAPL,MSFT,NVDA" {
+		t.Errorf("scored profile %q, min %.1f, symbols %v; want swing, 5, [AAPL MSFT NVDA]", gotProfile, gotMinScore, gotSymbols)
+	}
+
+	var resp struct {
+		Profile       string `json:"profile"`
+		TotalSymbols  int    `json:"total_symbols"`
+		Opportunities []struct {
+			Symbol string  `json:"symbol"`
+			Score  float64 `json:"score"`
+			Rank   int     `json:"rank"`
+		} `json:"opportunities"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.TotalSymbols != 3 || len(resp.Opportunities) != 3 {
+		t.Fatalf("response = %+v, want three scored symbols", resp)
+	}
+	for i, symbol := range []string{"MSFT", "NVDA", "AAPL"} {
+		if opp := resp.Opportunities[i]; opp.Symbol != symbol || opp.Rank != i+1 {
+			t.Errorf("opportunity %d = %s rank %d, want %s rank %d", i, opp.Symbol, opp.Rank, symbol, i+1)
+		}
+	}
+}
+
+func TestHandleScoutSymbols_ValidatesList(t *testing.T) {
+	called := false
+	api := &API{
+		Config: &config.Config{},
+		scorer: func(ctx context.Context, profileName string, symbols []string, minScore float64, cfg *config.Config) ([]types.Candidate, *scanner.SkipSummary, error) {
+			called = true
+			return nil, scanner.NewSkipSummary(true), nil
+		},
+	}
+
+	tooMany := make([]string, maxScoutSymbols+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("SYM%d", i)
+	}
+	oversized, _ := json.Marshal(map[string]interface{}{"symbols": tooMany})
+
+	for name, body := range map[string]string{
+		"missing":  `{}`,
+		"empty":    `{"symbols": []}`,
+		"blank":    `{"symbols": ["AAPL", "  "]}`,
+		"too many": string(oversized),
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/scout", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		api.HandleScoutSymbols(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, w.Code)
+		}
+	}
+	if called {
+		t.Error("scorer ran for an invalid symbol list")
+	}
+}
+
+func TestHandleScoutSymbols_FiltersByGrade(t *testing.T) {
+	api := &API{
+		Config: &config.Config{},
+		scorer: func(ctx context.Context, profileName string, symbols []string, minScore float64, cfg *config.Config) ([]types.Candidate, *scanner.SkipSummary, error) {
+			return []types.Candidate{
+				{Symbol: "MSFT", Score: 8.2, Grade: "A", GradeScore: 84},
+				{Symbol: "NVDA", Score: 7.1, Grade: "C", GradeScore: 52},
+				{Symbol: "AAPL", Score: 6.5, Grade: "B", GradeScore: 70},
+			}, scanner.NewSkipSummary(true), nil
+		},
+	}
+
+	body := `{"symbols": ["AAPL", "MSFT", "NVDA"], "min_grade": "b"}`
+	w := httptest.NewRecorder()
+	api.HandleScoutSymbols(w, httptest.NewRequest(http.MethodPost, "/api/scout", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		MinGrade      string `json:"min_grade"`
+		Opportunities []struct {
+			Symbol string `json:"symbol"`
+			Grade  string `json:"grade"`
+		} `json:"opportunities"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.MinGrade != "B" || len(resp.Opportunities) != 2 ||
+		resp.Opportunities[0].Symbol != "MSFT" || resp.Opportunities[1].Grade != "B" {
+		t.Errorf("response = %+v, want MSFT (A) and AAPL (B) only", resp)
+	}
+
+	w = httptest.NewRecorder()
+	api.HandleScoutSymbols(w, httptest.NewRequest(http.MethodPost, "/api/scout", strings.NewReader(`{"symbols": ["AAPL"], "min_grade": "E"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("min_grade E: status = %d, want 400", w.Code)
+	}
+}
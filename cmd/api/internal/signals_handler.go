@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fazecat/mogulmaker/Internal/strategy/metrics"
+	"github.com/fazecat/mogulmaker/Internal/strategy/signals"
+)
+
+const (
+	defaultSignalHistoryLimit     = 100
+	defaultCalibrationSignalLimit = 5000
+)
+
+// returns persisted signals for a symbol, or the most recent signals across all symbols
+func (api *API) HandleSignalHistory(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("symbol")))
+
+	limit := int32(defaultSignalHistoryLimit)
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			WriteError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = int32(parsed)
+	}
+
+	var (
+		records []signals.SignalRecord
+		err     error
+	)
+	if symbol != "" {
+		records, err = signals.LoadSignalHistory(r.Context(), api.Queries, symbol, limit)
+	} else {
+		rows, queryErr := api.Queries.GetRecentSignals(r.Context(), limit)
+		if queryErr != nil {
+			err = queryErr
+		} else {
+			records, err = signals.SignalRecordsFromRows(rows)
+		}
+	}
+	if err != nil {
+		log.Printf("Error fetching signal history: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch signal history")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"symbol":  symbol,
+		"signals": records,
+		"count":   len(records),
+	})
+}
+
+// buckets persisted signals by predicted confidence and reports the realized win rate of the trades they led to
+func (api *API) HandleSignalCalibration(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	bucketSize := metrics.DefaultCalibrationBucketSize
+	if sizeStr := query.Get("bucket_size"); sizeStr != "" {
+		parsed, err := strconv.ParseFloat(sizeStr, 64)
+		if err != nil || parsed <= 0 || parsed > 100 {
+			WriteError(w, http.StatusBadRequest, "bucket_size must be between 0 and 100")
+			return
+		}
+		bucketSize = parsed
+	}
+
+	window := metrics.DefaultCalibrationMatchWindow
+	if windowStr := query.Get("window_hours"); windowStr != "" {
+		parsed, err := strconv.Atoi(windowStr)
+		if err != nil || parsed <= 0 {
+			WriteError(w, http.StatusBadRequest, "window_hours must be a positive integer")
+			return
+		}
+		window = time.Duration(parsed) * time.Hour
+	}
+
+	rows, err := api.Queries.GetRecentSignals(r.Context(), defaultCalibrationSignalLimit)
+	if err != nil {
+		log.Printf("Error fetching signal history: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch signal history")
+		return
+	}
+	records, err := signals.SignalRecordsFromRows(rows)
+	if err != nil {
+		log.Printf("Error decoding signal history: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to decode signal history")
+		return
+	}
+
+	dbTrades, err := api.Queries.GetAllTrades(r.Context())
+	if err != nil {
+		log.Printf("Error fetching trades: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch trades")
+		return
+	}
+	trades := convertToTradeResults(dbTrades)
+
+	outcomes := metrics.MatchSignalOutcomes(records, trades, window)
+	report := metrics.BuildCalibrationReport(outcomes, bucketSize)
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"calibration":       report,
+		"signals_evaluated": len(records),
+		"closed_trades":     len(trades),
+		"window_hours":      window.Hours(),
+	})
+}
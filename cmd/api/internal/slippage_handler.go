@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	datafeed "github.com/fazecat/mogulmaker/Internal/database"
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+const slippageDefaultDays = 30
+
+// the slippage query the handler needs; *database.Queries satisfies it
+type slippageStore interface {
+	GetSlippageSummary(ctx context.Context, arg database.GetSlippageSummaryParams) ([]database.GetSlippageSummaryRow, error)
+}
+
+// GET /api/trades/slippage?from=...&to=... averages fill slippage against the expected price by symbol and order
+// type, costliest first; only trades logged with features.track_slippage on are counted
+func (api *API) HandleSlippageSummary(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseTimeRange(w, r.URL.Query(), slippageDefaultDays)
+	if !ok {
+		return
+	}
+
+	store := api.slippage
+	if store == nil {
+		if api.Queries == nil {
+			WriteError(w, http.StatusServiceUnavailable, "Database not initialized")
+			return
+		}
+		store = api.Queries
+	}
+
+	rows, err := store.GetSlippageSummary(r.Context(), database.GetSlippageSummaryParams{FromTime: from, ToTime: to})
+	if err != nil {
+		log.Printf("Error fetching slippage summary: %v", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch slippage summary")
+		return
+	}
+	if rows == nil {
+		rows = []database.GetSlippageSummaryRow{}
+	}
+	avg, fills := datafeed.AverageSlippage(rows)
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"from":             from.Format(time.RFC3339),
+		"to":               to.Format(time.RFC3339),
+		"by_symbol_type":   rows,
+		"fills":            fills,
+		"avg_slippage_bps": avg,
+	})
+}
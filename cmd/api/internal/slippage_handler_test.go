@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+)
+
+type fakeSlippageStore struct {
+	rows []database.GetSlippageSummaryRow
+	got  database.GetSlippageSummaryParams
+}
+
+func (f *fakeSlippageStore) GetSlippageSummary(ctx context.Context, arg database.GetSlippageSummaryParams) ([]database.GetSlippageSummaryRow, error) {
+	f.got = arg
+	return f.rows, nil
+}
+
+func TestHandleSlippageSummary(t *testing.T) {
+	store := &fakeSlippageStore{rows: []database.GetSlippageSummaryRow{
+		{Symbol: "TSLA", OrderType: "market", Fills: 2, AvgSlippageBps: 15, WorstSlippageBps: 22},
+		{Symbol: "AAPL", OrderType: "limit", Fills: 2, AvgSlippageBps: -5, WorstSlippageBps: -1},
+	}}
+	api := &API{slippage: store}
+
+	w := httptest.NewRecorder()
+	api.HandleSlippageSummary(w, httptest.NewRequest(http.MethodGet, "/api/trades/slippage?from=2024-05-01&to=2024-05-31", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Rows  []database.GetSlippageSummaryRow `json:"by_symbol_type"`
+		Fills int64                            `json:"fills"`
+		Avg   float64                          `json:"avg_slippage_bps"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Rows) != 2 || resp.Fills != 4 || resp.Avg != 5 {
+		t.Errorf("response = %+v, want two rows averaging 5 bps over 4 fills", resp)
+	}
+	if store.got.ToTime.Day() != 1 || store.got.ToTime.Month() != 6 {
+		t.Errorf("queried to %v, want the whole of May 31 covered", store.got.ToTime)
+	}
+}
@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	datafeed "github.com/fazecat/mogulmaker/Internal/database"
+)
+
+// rows written between flushes while streaming an export
+const exportFlushEvery = 100
+
+// GET /api/trades/export?format=csv|json&from=...&to=...&status=closed|open|all pairs Alpaca fills into
+// round trips FIFO and streams them with P&L, duration, exit reason and tags
+func (api *API) HandleExportTrades(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		WriteError(w, http.StatusBadRequest, "Invalid 'format': use csv or json")
+		return
+	}
+	status := query.Get("status")
+	if status == "" {
+		status = datafeed.TradeStatusAll
+	}
+	if status != datafeed.TradeStatusClosed && status != datafeed.TradeStatusOpen && status != datafeed.TradeStatusAll {
+		WriteError(w, http.StatusBadRequest, "Invalid 'status': use closed, open or all")
+		return
+	}
+	from, to, ok := parseTimeRange(w, query, importDefaultDays)
+	if !ok {
+		return
+	}
+
+	orders, err := fetchClosedOrders(api.alpacaClient(r), from, to)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch orders")
+		return
+	}
+	rows, err := datafeed.TradeExportRows(orders, status)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("trades_%s_%s.%s", from.Format("20060102"), to.Format("20060102"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		err = streamTradesCSV(w, rows)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		err = streamTradesJSON(w, rows)
+	}
+	if err != nil {
+		// headers are already out, so all that's left is to note the cut-off
+		log.Printf("Trade export %s to %s stopped early: %v", from.Format(time.RFC3339), to.Format(time.RFC3339), err)
+	}
+}
+
+func flushResponse(w http.ResponseWriter) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func streamTradesCSV(w http.ResponseWriter, rows []datafeed.TradeExportRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(datafeed.TradeExportColumns); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		if err := writer.Write(row.CSVRecord()); err != nil {
+			return err
+		}
+		if (i+1)%exportFlushEvery == 0 {
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+			flushResponse(w)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writes a JSON array one element at a time instead of marshalling the whole export
+func streamTradesJSON(w http.ResponseWriter, rows []datafeed.TradeExportRow) error {
+	if _, err := w.Write([]byte("[")); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		if i > 0 {
+			if _, err := w.Write([]byte(",")); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		if (i+1)%exportFlushEvery == 0 {
+			flushResponse(w)
+		}
+	}
+	_, err := w.Write([]byte("]\n"))
+	return err
+}
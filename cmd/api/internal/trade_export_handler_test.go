@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+
+	datafeed "github.com/fazecat/mogulmaker/Internal/database"
+)
+
+// a trading client whose order history is fixed
+type orderHistoryClient struct {
+	slowTradingClient
+	orders []alpaca.Order
+}
+
+func (c orderHistoryClient) GetOrders(req alpaca.GetOrdersRequest) ([]alpaca.Order, error) {
+	return c.orders, nil
+}
+
+func exportFill(id, symbol string, side alpaca.Side, qty, price float64, at time.Time) alpaca.Order {
+	avg := decimal.NewFromFloat(price)
+	return alpaca.Order{
+		ID: id, Symbol: symbol, Side: side, Status: "filled", Type: alpaca.Market,
+		FilledQty: decimal.NewFromFloat(qty), FilledAvgPrice: &avg, SubmittedAt: at, FilledAt: &at,
+	}
+}
+
+func exportAPI() *API {
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 15, 0, 0, 0, time.UTC) }
+	return &API{AlpacaClient: orderHistoryClient{orders: []alpaca.Order{
+		exportFill("aapl-buy", "AAPL", alpaca.Buy, 10, 180, day(1)),
+		exportFill("aapl-sell", "AAPL", alpaca.Sell, 10, 190, day(3)),
+		exportFill("tsla-buy", "TSLA", alpaca.Buy, 5, 170, day(4)),
+	}}}
+}
+
+func TestHandleExportTrades_CSVClosedOnly(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/trades/export?format=csv&status=closed&from=2024-05-01&to=2024-05-31", nil)
+	rec := httptest.NewRecorder()
+	exportAPI().HandleExportTrades(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q, want text/csv", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, "attachment") || !strings.Contains(cd, ".csv") {
+		t.Errorf("Content-Disposition = %q, want a .csv attachment", cd)
+	}
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("response is not valid CSV: %v", err)
+	}
+	if strings.Join(records[0], ",") != strings.Join(datafeed.TradeExportColumns, ",") {
+		t.Errorf("header = %v, want %v", records[0], datafeed.TradeExportColumns)
+	}
+	if len(records) != 2 || records[1][0] != "AAPL" || records[1][2] != "closed" || records[1][8] != "100.00" || records[1][11] != "MARKET" {
+		t.Errorf("rows = %v, want only the closed AAPL trade at +100.00", records[1:])
+	}
+}
+
+func TestHandleExportTrades_JSONAndValidation(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/trades/export?format=json&from=2024-05-01&to=2024-05-31", nil)
+	rec := httptest.NewRecorder()
+	exportAPI().HandleExportTrades(rec, req)
+
+	var rows []datafeed.TradeExportRow
+	if err := json.Unmarshal(rec.Body.Bytes(), &rows); err != nil {
+		t.Fatalf("response is not a JSON array: %v (%s)", err, rec.Body.String())
+	}
+	if len(rows) != 2 || rows[1].Symbol != "TSLA" || rows[1].Status != "open" {
+		t.Errorf("rows = %+v, want AAPL closed and TSLA open", rows)
+	}
+
+	for _, query := range []string{"format=xml", "status=pending"} {
+		rec := httptest.NewRecorder()
+		exportAPI().HandleExportTrades(rec, httptest.NewRequest(http.MethodGet, "/api/trades/export?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
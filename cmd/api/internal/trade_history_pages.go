@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+)
+
+// bounds for GET /api/trades paging
+const (
+	defaultTradesPageSize = 100
+	maxTradesPageSize     = 500
+	tradeOrdersBatchSize  = 500
+	tradeOrdersMaxBatches = 20
+)
+
+// the slice of the sorted, filtered trade list one GET /api/trades call returns
+type tradesPage struct {
+	Offset int
+	Size   int
+}
+
+// reads page_size (or the older limit) plus one of offset, cursor or 1-based page. limit keeps its old
+// behaviour of falling back to the default when it doesn't parse; the paging params are rejected instead
+func parseTradesPage(query url.Values) (tradesPage, error) {
+	page := tradesPage{Size: defaultTradesPageSize}
+	if raw := query.Get("limit"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			page.Size = min(parsed, maxTradesPageSize)
+		}
+	}
+	if raw := query.Get("page_size"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxTradesPageSize {
+			return tradesPage{}, fmt.Errorf("page_size must be between 1 and %d", maxTradesPageSize)
+		}
+		page.Size = parsed
+	}
+
+	positions := 0
+	for _, key := range []string{"offset", "cursor", "page"} {
+		if query.Get(key) != "" {
+			positions++
+		}
+	}
+	if positions > 1 {
+		return tradesPage{}, fmt.Errorf("use only one of offset, cursor or page")
+	}
+
+	for _, key := range []string{"offset", "cursor"} {
+		if raw := query.Get(key); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 0 {
+				return tradesPage{}, fmt.Errorf("%s must be a non-negative integer", key)
+			}
+			page.Offset = parsed
+		}
+	}
+	if raw := query.Get("page"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			return tradesPage{}, fmt.Errorf("page must be a positive integer")
+		}
+		page.Offset = (parsed - 1) * page.Size
+	}
+	return page, nil
+}
+
+// the records past this page, which is how many the order history has to cover before has_more is known
+func (p tradesPage) end() int {
+	return p.Offset + p.Size
+}
+
+// the page's trades out of the full list, whether any follow, and the cursor for the next page ("" without one)
+func (p tradesPage) slice(trades []map[string]interface{}) ([]map[string]interface{}, bool, string) {
+	if p.Offset >= len(trades) {
+		return []map[string]interface{}{}, false, ""
+	}
+	end := min(p.end(), len(trades))
+	hasMore := len(trades) > end
+	nextCursor := ""
+	if hasMore {
+		nextCursor = strconv.Itoa(end)
+	}
+	return trades[p.Offset:end], hasMore, nextCursor
+}
+
+// Alpaca orders fetched newest first, one batch at a time, so a page only pulls the history it needs
+type orderHistory struct {
+	client    TradingClient
+	symbol    string
+	orders    []alpaca.Order
+	seen      map[string]bool
+	until     time.Time
+	batches   int
+	exhausted bool
+}
+
+func newOrderHistory(client TradingClient, symbol string) *orderHistory {
+	return &orderHistory{client: client, symbol: symbol, seen: make(map[string]bool)}
+}
+
+// fetches batches until at least n orders are loaded or the history (or the batch cap) runs out
+func (h *orderHistory) load(n int) error {
+	for len(h.orders) < n && !h.exhausted {
+		req := alpaca.GetOrdersRequest{
+			Status:    "all",
+			Until:     h.until,
+			Direction: "desc",
+			Limit:     tradeOrdersBatchSize,
+			Nested:    true,
+		}
+		if h.symbol != "" {
+			req.Symbols = []string{h.symbol}
+		}
+		batch, err := h.client.GetOrders(req)
+		if err != nil {
+			return err
+		}
+		h.batches++
+
+		added := 0
+		for _, order := range batch {
+			// until is inclusive, so the order the last batch ended on comes back at the top of this one
+			if h.seen[order.ID] {
+				continue
+			}
+			h.seen[order.ID] = true
+			h.orders = append(h.orders, order)
+			added++
+		}
+		if len(batch) < tradeOrdersBatchSize || added == 0 || h.batches >= tradeOrdersMaxBatches {
+			h.exhausted = true
+		}
+		if len(batch) > 0 {
+			h.until = batch[len(batch)-1].SubmittedAt
+		}
+	}
+	return nil
+}
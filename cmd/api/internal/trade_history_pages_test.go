@@ -0,0 +1,231 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+)
+
+// serves a fixed history the way Alpaca pages it: newest first, until inclusive, at most Limit per call
+type pagedOrdersClient struct {
+	slowTradingClient
+	orders []alpaca.Order // newest first
+	calls  *[]alpaca.GetOrdersRequest
+}
+
+func (c pagedOrdersClient) GetOrders(req alpaca.GetOrdersRequest) ([]alpaca.Order, error) {
+	*c.calls = append(*c.calls, req)
+	var batch []alpaca.Order
+	for _, order := range c.orders {
+		if !req.Until.IsZero() && order.SubmittedAt.After(req.Until) {
+			continue
+		}
+		if len(req.Symbols) > 0 && order.Symbol != req.Symbols[0] {
+			continue
+		}
+		if len(batch) == req.Limit {
+			break
+		}
+		batch = append(batch, order)
+	}
+	return batch, nil
+}
+
+// roundTrips closed buy/sell pairs on distinct symbols plus openBuys unpaired buys, newest first, an hour apart
+func pagedOrderHistory(roundTrips, openBuys int) []alpaca.Order {
+	start := time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC)
+	var chronological []alpaca.Order
+	at := start
+	for i := 0; i < roundTrips; i++ {
+		symbol := fmt.Sprintf("S%04d", i)
+		chronological = append(chronological,
+			exportFill(fmt.Sprintf("%s-buy", symbol), symbol, alpaca.Buy, 1, 100, at),
+			exportFill(fmt.Sprintf("%s-sell", symbol), symbol, alpaca.Sell, 1, 101, at.Add(time.Hour)))
+		at = at.Add(2 * time.Hour)
+	}
+	for i := 0; i < openBuys; i++ {
+		symbol := fmt.Sprintf("OPEN%d", i)
+		chronological = append(chronological, exportFill(symbol+"-buy", symbol, alpaca.Buy, 1, 50, at))
+		at = at.Add(time.Hour)
+	}
+
+	newestFirst := make([]alpaca.Order, len(chronological))
+	for i, order := range chronological {
+		newestFirst[len(chronological)-1-i] = order
+	}
+	return newestFirst
+}
+
+type tradesPageResponse struct {
+	Count      int                      `json:"count"`
+	Trades     []map[string]interface{} `json:"trades"`
+	Offset     int                      `json:"offset"`
+	PageSize   int                      `json:"page_size"`
+	HasMore    bool                     `json:"has_more"`
+	NextCursor string                   `json:"next_cursor"`
+}
+
+func getTradesPage(t *testing.T, client TradingClient, query string) tradesPageResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/trades?"+query, nil)
+	rec := httptest.NewRecorder()
+	(&API{AlpacaClient: client}).HandleGetTrades(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/trades?%s status = %d: %s", query, rec.Code, rec.Body.String())
+	}
+	var resp tradesPageResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	return resp
+}
+
+func TestParseTradesPage(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    tradesPage
+		wantErr bool
+	}{
+		{"", tradesPage{Offset: 0, Size: defaultTradesPageSize}, false},
+		{"limit=20", tradesPage{Offset: 0, Size: 20}, false},
+		{"limit=abc", tradesPage{Offset: 0, Size: defaultTradesPageSize}, false},
+		{"page_size=25&page=3", tradesPage{Offset: 50, Size: 25}, false},
+		{"page_size=25&offset=7", tradesPage{Offset: 7, Size: 25}, false},
+		{"cursor=40&limit=10", tradesPage{Offset: 40, Size: 10}, false},
+		{"page_size=0", tradesPage{}, true},
+		{"page_size=501", tradesPage{}, true},
+		{"page=0", tradesPage{}, true},
+		{"offset=-1", tradesPage{}, true},
+		{"offset=10&page=2", tradesPage{}, true},
+	}
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		got, err := parseTradesPage(query)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTradesPage(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseTradesPage(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHandleGetTrades_CursorWalksEveryTradeOnce(t *testing.T) {
+	var calls []alpaca.GetOrdersRequest
+	client := pagedOrdersClient{orders: pagedOrderHistory(30, 0), calls: &calls}
+
+	seen := make(map[string]bool)
+	lastSubmitted := "9999"
+	query := "page_size=25"
+	pages := 0
+	for {
+		resp := getTradesPage(t, client, query)
+		pages++
+		for _, trade := range resp.Trades {
+			id := trade["id"].(string)
+			if seen[id] {
+				t.Fatalf("trade %s returned on two pages", id)
+			}
+			seen[id] = true
+			submitted := trade["submitted_at"].(string)
+			if submitted > lastSubmitted {
+				t.Fatalf("trade %s at %s is out of newest-first order", id, submitted)
+			}
+			lastSubmitted = submitted
+		}
+		if !resp.HasMore {
+			if resp.NextCursor != "" {
+				t.Errorf("last page next_cursor = %q, want empty", resp.NextCursor)
+			}
+			if resp.Count != 10 {
+				t.Errorf("last page count = %d, want 10", resp.Count)
+			}
+			break
+		}
+		if resp.Count != 25 {
+			t.Errorf("page %d count = %d, want a full page of 25", pages, resp.Count)
+		}
+		query = "page_size=25&cursor=" + resp.NextCursor
+	}
+	if pages != 3 || len(seen) != 60 {
+		t.Errorf("walked %d pages covering %d trades, want 3 pages covering all 60", pages, len(seen))
+	}
+
+	third := getTradesPage(t, client, "page_size=25&page=3")
+	if third.Offset != 50 || third.Count != 10 || third.HasMore {
+		t.Errorf("page=3 gave offset %d, count %d, has_more %v; want 50, 10, false", third.Offset, third.Count, third.HasMore)
+	}
+
+	past := getTradesPage(t, client, "page_size=25&offset=60")
+	if past.Count != 0 || past.HasMore || past.Trades == nil {
+		t.Errorf("offset past the end gave %+v, want an empty trades list", past)
+	}
+}
+
+func TestHandleGetTrades_StatusAndSymbolFiltersPage(t *testing.T) {
+	var calls []alpaca.GetOrdersRequest
+	client := pagedOrdersClient{orders: pagedOrderHistory(10, 4), calls: &calls}
+
+	open := getTradesPage(t, client, "status=open&page_size=3")
+	if open.Count != 3 || !open.HasMore || open.NextCursor != "3" {
+		t.Fatalf("first open page = count %d, has_more %v, cursor %q; want 3, true, \"3\"", open.Count, open.HasMore, open.NextCursor)
+	}
+	rest := getTradesPage(t, client, "status=open&page_size=3&cursor=3")
+	if rest.Count != 1 || rest.HasMore {
+		t.Errorf("second open page = count %d, has_more %v; want the 1 remaining open buy", rest.Count, rest.HasMore)
+	}
+	for _, trade := range append(open.Trades, rest.Trades...) {
+		if trade["status"] != "open" {
+			t.Errorf("status=open returned %v", trade)
+		}
+	}
+
+	calls = nil
+	closed := getTradesPage(t, client, "symbol=s0003&status=closed&page_size=1")
+	if len(calls) == 0 || len(calls[0].Symbols) != 1 || calls[0].Symbols[0] != "S0003" {
+		t.Fatalf("symbol filter not passed to Alpaca: %+v", calls)
+	}
+	if closed.Count != 1 || !closed.HasMore {
+		t.Fatalf("first closed S0003 page = count %d, has_more %v; want 1 leg with the other to follow", closed.Count, closed.HasMore)
+	}
+	other := getTradesPage(t, client, "symbol=S0003&status=closed&page_size=1&cursor="+closed.NextCursor)
+	if other.Count != 1 || other.HasMore {
+		t.Fatalf("second closed S0003 page = count %d, has_more %v; want the last leg", other.Count, other.HasMore)
+	}
+	// the buy and the sell land on different pages but share a pair id
+	if closed.Trades[0]["pair_id"] == "" || closed.Trades[0]["pair_id"] != other.Trades[0]["pair_id"] {
+		t.Errorf("split pair legs have pair ids %v and %v, want the same non-empty id", closed.Trades[0]["pair_id"], other.Trades[0]["pair_id"])
+	}
+}
+
+func TestHandleGetTrades_FetchesOlderBatchesForLaterPages(t *testing.T) {
+	var calls []alpaca.GetOrdersRequest
+	client := pagedOrdersClient{orders: pagedOrderHistory(600, 0), calls: &calls}
+
+	first := getTradesPage(t, client, "page_size=50")
+	if len(calls) != 1 {
+		t.Errorf("first page made %d order requests, want 1", len(calls))
+	}
+	if first.Count != 50 || !first.HasMore {
+		t.Errorf("first page = count %d, has_more %v", first.Count, first.HasMore)
+	}
+
+	calls = nil
+	deep := getTradesPage(t, client, "page_size=50&offset=1150")
+	if len(calls) < 3 {
+		t.Errorf("offset 1150 made %d order requests, want it to page back through the history", len(calls))
+	}
+	if deep.Count != 50 || deep.HasMore {
+		t.Errorf("final page = count %d, has_more %v; want the oldest 50 with none after", deep.Count, deep.HasMore)
+	}
+	if oldest := deep.Trades[len(deep.Trades)-1]["id"]; oldest != "S0000-buy" {
+		t.Errorf("oldest trade = %v, want S0000-buy", oldest)
+	}
+}
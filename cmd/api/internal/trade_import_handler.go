@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+
+	datafeed "github.com/fazecat/mogulmaker/Internal/database"
+)
+
+const (
+	importOrdersPageSize = 500
+	importMaxPages       = 50
+	importDefaultDays    = 90
+)
+
+// POST /api/trades/import-from-alpaca?from=...&to=... pulls filled orders, pairs them into round trips
+// and stores both legs as imported trades so stats cover history placed before this app
+func (api *API) HandleImportTradesFromAlpaca(w http.ResponseWriter, r *http.Request) {
+	to := time.Now()
+	if value := r.URL.Query().Get("to"); value != "" {
+		parsed, dateOnly, err := parseImportTime(value)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "Invalid 'to': use YYYY-MM-DD or RFC3339")
+			return
+		}
+		to = parsed
+		if dateOnly {
+			// a bare date covers the whole day
+			to = to.Add(24*time.Hour - time.Nanosecond)
+		}
+	}
+
+	from := to.AddDate(0, 0, -importDefaultDays)
+	if value := r.URL.Query().Get("from"); value != "" {
+		parsed, _, err := parseImportTime(value)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "Invalid 'from': use YYYY-MM-DD or RFC3339")
+			return
+		}
+		from = parsed
+	}
+	if !from.Before(to) {
+		WriteError(w, http.StatusBadRequest, "'from' must be before 'to'")
+		return
+	}
+
+	// orders run up to now so today's holdings can be walked back to what was held at 'from'; sells of those
+	// shares closed trips opened before the range and aren't imported
+	client := api.alpacaClient(r)
+	until := time.Now()
+	if to.After(until) {
+		until = to
+	}
+	orders, err := fetchClosedOrders(client, from, until)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch orders")
+		return
+	}
+	held, err := client.GetPositions()
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to fetch positions")
+		return
+	}
+	opening := datafeed.OpeningPositions(held, datafeed.FillsFromOrders(orders))
+
+	var inRange []alpaca.Order
+	for _, order := range orders {
+		if !order.SubmittedAt.After(to) {
+			inRange = append(inRange, order)
+		}
+	}
+
+	result, err := datafeed.ImportAlpacaOrders(r.Context(), api.Queries, inRange, opening)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "Failed to import trades")
+		return
+	}
+
+	log.Printf("Imported Alpaca trades %s to %s: %d rows, %d duplicates, %d round trips",
+		from.Format(time.RFC3339), to.Format(time.RFC3339), result.Imported, result.Duplicates, result.RoundTrips)
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"from":   from.Format(time.RFC3339),
+		"to":     to.Format(time.RFC3339),
+		"result": result,
+	})
+}
+
+// accepts a bare date or a full RFC3339 timestamp, reporting which one it got
+func parseImportTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// pages through closed orders submitted in [from, to], oldest first
+func fetchClosedOrders(client TradingClient, from, to time.Time) ([]alpaca.Order, error) {
+	var orders []alpaca.Order
+	after := from
+
+	for page := 0; page < importMaxPages; page++ {
+		batch, err := client.GetOrders(alpaca.GetOrdersRequest{
+			Status:    "closed",
+			After:     after,
+			Until:     to,
+			Direction: "asc",
+			Limit:     importOrdersPageSize,
+			Nested:    true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, batch...)
+		if len(batch) < importOrdersPageSize {
+			return orders, nil
+		}
+		// continue from the last order returned; FillsFromOrders drops any order that shows up on two pages
+		after = batch[len(batch)-1].SubmittedAt
+	}
+	return nil, fmt.Errorf("more than %d orders in range, narrow 'from'/'to'", importMaxPages*importOrdersPageSize)
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+// queries behind the watchlist handlers (*database.Queries satisfies it)
+type watchlistStore interface {
+	GetWatchlist(ctx context.Context) ([]database.GetWatchlistRow, error)
+	AddToWatchlist(ctx context.Context, arg database.AddToWatchlistParams) (int32, error)
+	UpdateWatchlistScore(ctx context.Context, arg database.UpdateWatchlistScoreParams) error
+	UpdateWatchlistScoreBreakdown(ctx context.Context, arg database.UpdateWatchlistScoreBreakdownParams) error
+}
+
+func (api *API) watchlistQueries() watchlistStore {
+	if api.watchlist != nil {
+		return api.watchlist
+	}
+	return api.Queries
+}
+
+// sort keys accepted by GET /api/watchlist?sort=
+var watchlistSortKeys = map[string]func(database.GetWatchlistRow) float64{
+	"score":     func(row database.GetWatchlistRow) float64 { return float64(row.Score) },
+	"technical": func(row database.GetWatchlistRow) float64 { return row.TechnicalScore.Float64 },
+	"news":      func(row database.GetWatchlistRow) float64 { return row.NewsScore.Float64 },
+	"pattern":   func(row database.GetWatchlistRow) float64 { return row.PatternScore.Float64 },
+	"sr":        func(row database.GetWatchlistRow) float64 { return row.SrScore.Float64 },
+}
+
+// orders rows by a sub-score, highest first; rows without a stored breakdown go last
+func sortWatchlist(rows []database.GetWatchlistRow, key string) error {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == "" || key == "score" {
+		return nil // the query already orders by score
+	}
+	value, ok := watchlistSortKeys[key]
+	if !ok {
+		return fmt.Errorf("Invalid 'sort': use score, technical, news, pattern or sr")
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		hasI, hasJ := rowBreakdown(rows[i]) != nil, rowBreakdown(rows[j]) != nil
+		if hasI != hasJ {
+			return hasI
+		}
+		return value(rows[i]) > value(rows[j])
+	})
+	return nil
+}
+
+// the stored breakdown of a row, nil when it was last scored before breakdowns were kept
+func rowBreakdown(row database.GetWatchlistRow) *types.ScoreBreakdown {
+	if !row.TechnicalScore.Valid && !row.NewsScore.Valid && !row.PatternScore.Valid && !row.SrScore.Valid {
+		return nil
+	}
+	return &types.ScoreBreakdown{
+		Technical:         row.TechnicalScore.Float64,
+		News:              row.NewsScore.Float64,
+		Pattern:           row.PatternScore.Float64,
+		SupportResistance: row.SrScore.Float64,
+	}
+}
+
+func breakdownParams(symbol string, breakdown types.ScoreBreakdown) database.UpdateWatchlistScoreBreakdownParams {
+	return database.UpdateWatchlistScoreBreakdownParams{
+		TechnicalScore: sql.NullFloat64{Float64: breakdown.Technical, Valid: true},
+		NewsScore:      sql.NullFloat64{Float64: breakdown.News, Valid: true},
+		PatternScore:   sql.NullFloat64{Float64: breakdown.Pattern, Valid: true},
+		SrScore:        sql.NullFloat64{Float64: breakdown.SupportResistance, Valid: true},
+		Symbol:         symbol,
+	}
+}
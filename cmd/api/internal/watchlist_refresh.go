@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+)
+
+// which watchlist rows a score refresh recomputes; the zero value recomputes everything
+type watchlistRefreshFilter struct {
+	Symbols map[string]bool // only these symbols, when set
+	MaxAge  time.Duration   // only rows last updated longer ago than this, when > 0
+	Force   bool            // ignore MaxAge
+}
+
+// reads ?symbols=AAPL,BTC/USD&max_age=6h&force=true; max_age also accepts a bare number of minutes
+func parseWatchlistRefreshFilter(query url.Values) (watchlistRefreshFilter, error) {
+	var filter watchlistRefreshFilter
+
+	if value := strings.TrimSpace(query.Get("symbols")); value != "" {
+		filter.Symbols = make(map[string]bool)
+		for _, symbol := range strings.Split(value, ",") {
+			if symbol = strings.TrimSpace(symbol); symbol != "" {
+				filter.Symbols[utils.NormalizeSymbol(symbol, "")] = true
+			}
+		}
+	}
+
+	if value := strings.TrimSpace(query.Get("max_age")); value != "" {
+		age, err := time.ParseDuration(value)
+		if err != nil {
+			minutes, convErr := strconv.Atoi(value)
+			if convErr != nil {
+				return filter, fmt.Errorf("Invalid 'max_age': use a duration like 6h or a number of minutes")
+			}
+			age = time.Duration(minutes) * time.Minute
+		}
+		if age < 0 {
+			return filter, fmt.Errorf("'max_age' must not be negative")
+		}
+		filter.MaxAge = age
+	}
+
+	if value := query.Get("force"); value != "" {
+		force, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid 'force': use true or false")
+		}
+		filter.Force = force
+	}
+	return filter, nil
+}
+
+// splits the watchlist into rows to recompute and the symbols left alone, with the reason for each skip
+func (f watchlistRefreshFilter) selectRows(watchlist []database.GetWatchlistRow, now time.Time) ([]database.GetWatchlistRow, map[string]string) {
+	refresh := make([]database.GetWatchlistRow, 0, len(watchlist))
+	skipped := make(map[string]string)
+
+	for _, item := range watchlist {
+		if f.Symbols != nil && !f.Symbols[utils.NormalizeSymbol(item.Symbol, item.AssetType)] {
+			continue
+		}
+		if !f.Force && f.MaxAge > 0 && item.LastUpdated.Valid && now.Sub(item.LastUpdated.Time) < f.MaxAge {
+			skipped[item.Symbol] = fmt.Sprintf("updated %s ago", now.Sub(item.LastUpdated.Time).Round(time.Minute))
+			continue
+		}
+		refresh = append(refresh, item)
+	}
+	return refresh, skipped
+}
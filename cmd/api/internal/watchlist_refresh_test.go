@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	database "github.com/fazecat/mogulmaker/Internal/database/sqlc"
+	"github.com/fazecat/mogulmaker/Internal/types"
+)
+
+func watchlistRow(symbol, assetType string, updated time.Time) database.GetWatchlistRow {
+	return database.GetWatchlistRow{
+		Symbol:      symbol,
+		AssetType:   assetType,
+		LastUpdated: sql.NullTime{Time: updated, Valid: !updated.IsZero()},
+	}
+}
+
+func refreshedSymbols(rows []database.GetWatchlistRow) []string {
+	symbols := make([]string, len(rows))
+	for i, row := range rows {
+		symbols[i] = row.Symbol
+	}
+	return symbols
+}
+
+func TestWatchlistRefresh_MaxAgeSkipsFreshAndForceRecomputes(t *testing.T) {
+	now := time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC)
+	watchlist := []database.GetWatchlistRow{
+		watchlistRow("AAPL", "stock", now.Add(-30*time.Minute)),
+		watchlistRow("MSFT", "stock", now.Add(-8*time.Hour)),
+		watchlistRow("NVDA", "stock", time.Time{}), // never scored
+	}
+
+	filter, err := parseWatchlistRefreshFilter(url.Values{"max_age": {"6h"}})
+	if err != nil {
+		t.Fatalf("parseWatchlistRefreshFilter() error = %v", err)
+	}
+	refresh, skipped := filter.selectRows(watchlist, now)
+	if got := refreshedSymbols(refresh); len(got) != 2 || got[0] != "MSFT" || got[1] != "NVDA" {
+		t.Errorf("refreshed = %v, want MSFT and NVDA", got)
+	}
+	if _, ok := skipped["AAPL"]; !ok || len(skipped) != 1 {
+		t.Errorf("skipped = %v, want only the fresh AAPL", skipped)
+	}
+
+	filter, err = parseWatchlistRefreshFilter(url.Values{"max_age": {"360"}, "force": {"true"}})
+	if err != nil {
+		t.Fatalf("parseWatchlistRefreshFilter() error = %v", err)
+	}
+	if filter.MaxAge != 6*time.Hour {
+		t.Errorf("MaxAge = %v, want 360 minutes", filter.MaxAge)
+	}
+	refresh, skipped = filter.selectRows(watchlist, now)
+	if len(refresh) != 3 || len(skipped) != 0 {
+		t.Errorf("forced refresh = %v, skipped %v, want every symbol recomputed", refreshedSymbols(refresh), skipped)
+	}
+}
+
+func TestWatchlistRefresh_SymbolsFilter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC)
+	watchlist := []database.GetWatchlistRow{
+		watchlistRow("AAPL", "stock", now.Add(-time.Hour)),
+		watchlistRow("BTCUSD", "crypto", now.Add(-time.Hour)),
+		watchlistRow("MSFT", "stock", now.Add(-time.Hour)),
+	}
+
+	filter, err := parseWatchlistRefreshFilter(url.Values{"symbols": {"btc/usd, aapl"}})
+	if err != nil {
+		t.Fatalf("parseWatchlistRefreshFilter() error = %v", err)
+	}
+	refresh, _ := filter.selectRows(watchlist, now)
+	if got := refreshedSymbols(refresh); len(got) != 2 || got[0] != "AAPL" || got[1] != "BTCUSD" {
+		t.Errorf("refreshed = %v, want AAPL and BTCUSD", got)
+	}
+
+	for _, bad := range []url.Values{{"max_age": {"soon"}}, {"max_age": {"-1h"}}, {"force": {"maybe"}}} {
+		if _, err := parseWatchlistRefreshFilter(bad); err == nil {
+			t.Errorf("parseWatchlistRefreshFilter(%v) accepted invalid input", bad)
+		}
+	}
+}
+
+// in-memory watchlist rows keyed by symbol
+type memoryWatchlist struct {
+	rows map[string]*database.GetWatchlistRow
+}
+
+func (m *memoryWatchlist) GetWatchlist(ctx context.Context) ([]database.GetWatchlistRow, error) {
+	rows := make([]database.GetWatchlistRow, 0, len(m.rows))
+	for _, row := range m.rows {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Score > rows[j].Score })
+	return rows, nil
+}
+
+func (m *memoryWatchlist) AddToWatchlist(ctx context.Context, arg database.AddToWatchlistParams) (int32, error) {
+	m.rows[arg.Symbol] = &database.GetWatchlistRow{ID: int32(len(m.rows) + 1), Symbol: arg.Symbol, AssetType: arg.AssetType, Score: arg.Score}
+	return int32(len(m.rows)), nil
+}
+
+func (m *memoryWatchlist) UpdateWatchlistScore(ctx context.Context, arg database.UpdateWatchlistScoreParams) error {
+	m.rows[arg.Symbol].Score = arg.Score
+	return nil
+}
+
+func (m *memoryWatchlist) UpdateWatchlistScoreBreakdown(ctx context.Context, arg database.UpdateWatchlistScoreBreakdownParams) error {
+	row := m.rows[arg.Symbol]
+	row.TechnicalScore, row.NewsScore, row.PatternScore, row.SrScore = arg.TechnicalScore, arg.NewsScore, arg.PatternScore, arg.SrScore
+	return nil
+}
+
+func TestWatchlistRefresh_PersistsScoreBreakdown(t *testing.T) {
+	store := &memoryWatchlist{rows: map[string]*database.GetWatchlistRow{
+		"AAPL": {ID: 1, Symbol: "AAPL", AssetType: "stock", Score: 1},
+		"MSFT": {ID: 2, Symbol: "MSFT", AssetType: "stock", Score: 2},
+	}}
+	api := &API{
+		watchlist: store,
+		bars: func(symbol, timeframe string, limit int, assetType string) ([]types.Bar, error) {
+			if symbol == "AAPL" {
+				return trendingBars(60, 100, 1), nil
+			}
+			return trendingBars(60, 200, -1), nil
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	api.HandleRefreshWatchlistScores(rec, httptest.NewRequest(http.MethodPut, "/api/watchlist/refresh-scores", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refresh status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	api.HandleGetWatchlist(rec, httptest.NewRequest(http.MethodGet, "/api/watchlist?sort=technical", nil))
+	var body struct {
+		Watchlist []struct {
+			Symbol    string                `json:"symbol"`
+			Breakdown *types.ScoreBreakdown `json:"breakdown"`
+		} `json:"watchlist"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode watchlist: %v", err)
+	}
+	if len(body.Watchlist) != 2 {
+		t.Fatalf("watchlist = %+v, want both symbols", body.Watchlist)
+	}
+	for _, item := range body.Watchlist {
+		stored := rowBreakdown(*store.rows[item.Symbol])
+		if item.Breakdown == nil || stored == nil || *item.Breakdown != *stored {
+			t.Errorf("%s breakdown = %+v, want the stored %+v", item.Symbol, item.Breakdown, stored)
+		}
+		if item.Breakdown != nil && (item.Breakdown.Technical <= 0 || item.Breakdown.News != 5) {
+			t.Errorf("%s breakdown = %+v, want a technical score and neutral news", item.Symbol, item.Breakdown)
+		}
+	}
+	if first, second := body.Watchlist[0].Breakdown, body.Watchlist[1].Breakdown; first != nil && second != nil && first.Technical < second.Technical {
+		t.Errorf("sort=technical returned %.2f before %.2f", first.Technical, second.Technical)
+	}
+
+	rec = httptest.NewRecorder()
+	api.HandleGetWatchlist(rec, httptest.NewRequest(http.MethodGet, "/api/watchlist?sort=volume", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown sort status = %d, want 400", rec.Code)
+	}
+}
+
+func TestSortWatchlist_UnscoredRowsLast(t *testing.T) {
+	rows := []database.GetWatchlistRow{
+		{Symbol: "OLD", Score: 9},
+		{Symbol: "LOW", Score: 3, PatternScore: sql.NullFloat64{Float64: -1, Valid: true}},
+		{Symbol: "HIGH", Score: 5, PatternScore: sql.NullFloat64{Float64: 2, Valid: true}},
+	}
+	if err := sortWatchlist(rows, "pattern"); err != nil {
+		t.Fatalf("sortWatchlist() error = %v", err)
+	}
+	if got := refreshedSymbols(rows); got[0] != "HIGH" || got[1] != "LOW" || got[2] != "OLD" {
+		t.Errorf("order = %v, want [HIGH LOW OLD]", got)
+	}
+}
@@ -1,18 +1,29 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
 	datafeed "github.com/fazecat/mogulmaker/Internal/database"
 	"github.com/fazecat/mogulmaker/Internal/handlers/monitoring"
 	"github.com/fazecat/mogulmaker/Internal/handlers/risk"
 	settingshandler "github.com/fazecat/mogulmaker/Internal/handlers/settings"
+	newsscraping "github.com/fazecat/mogulmaker/Internal/news_scraping"
 	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/strategy/detection"
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
 	"github.com/fazecat/mogulmaker/Internal/strategy/position"
+	"github.com/fazecat/mogulmaker/Internal/strategy/signals"
+	"github.com/fazecat/mogulmaker/Internal/utils"
+	"github.com/fazecat/mogulmaker/Internal/utils/analyzer"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
+	"github.com/fazecat/mogulmaker/Internal/utils/scanner"
 	"github.com/fazecat/mogulmaker/cmd/api/internal"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -61,6 +72,16 @@ func main() {
 	if account != nil {
 		accountEquity, _ := account.Equity.Float64()
 		riskMgr = risk.NewManager(alpclient, accountEquity)
+		// resolved sectors are cached in the database when there is one
+		sectors := risk.NewSectorResolver(nil)
+		if datafeed.Queries != nil {
+			sectors = risk.NewSectorResolver(datafeed.Queries)
+		}
+		riskMgr.SetSectorResolver(sectors)
+		if webhookURL := os.Getenv("ALERT_WEBHOOK_URL"); webhookURL != "" {
+			riskMgr.RegisterAlertCallback(risk.NewWebhookAlertCallback(webhookURL))
+			log.Println("Risk alerts will also be posted to ALERT_WEBHOOK_URL")
+		}
 		log.Println("Risk Manager initialized")
 	} else {
 		log.Println("Risk Manager could not be initialized - account data unavailable")
@@ -75,6 +96,7 @@ func main() {
 		MaxDailyLossPercent:   -2.0,
 		PartialExitPercentage: 0.5,
 	}
+	riskMgr.CapOrderSizing(orderConfig)
 	posManager := position.NewPositionManager(alpclient, orderConfig)
 
 	tradeMon := monitoring.NewMonitor(posManager, riskMgr, datafeed.Queries)
@@ -87,17 +109,100 @@ func main() {
 		log.Printf("Warning: Alpaca client initialization failed: %v\n", err)
 	}
 
+	chartsEnabled := false
+	var appConfig *config.Config
+	if cfg, err := config.LoadConfig(); err != nil {
+		log.Printf("Warning: retention job, entry throttle and end-of-day close disabled, could not load config: %v\n", err)
+	} else {
+		// Prune old persisted rows on a schedule so scans and news don't grow the database unbounded; a nil
+		// *Queries would be a non-nil RetentionStore, so check before handing it over
+		if datafeed.Queries != nil {
+			go datafeed.StartRetentionJob(context.Background(), datafeed.Queries, cfg.Retention)
+		}
+		orderConfig.SetAssetClassRisk(cfg.AssetClassRisk)
+		orderConfig.SetPositionSizing(cfg.PositionSizing)
+		posManager.SetEntryThrottle(position.NewEntryThrottle(cfg.TradeThrottle.MaxEntriesPerHour, cfg.TradeThrottle.MaxEntriesPerDay, nil))
+		posManager.SetAggregateLots(cfg.Features.AggregateLots)
+		posManager.SetRecalculateLevels(cfg.Features.RecalculateLevelsOnConfigChange)
+		posManager.SetTrackBracketLegs(cfg.Features.TrackBracketLegs)
+		go position.NewEODCloser(posManager, cfg, nil, riskMgr.SendFlattenAlert).Run(context.Background(), time.Minute)
+		newsHaltInterval := time.Duration(max(cfg.NewsHalt.CheckIntervalMinutes, 1)) * time.Minute
+		go monitoring.NewNewsHaltMonitor(posManager, newsscraping.NewFinnhubClient(), cfg.NewsHalt, nil, monitoring.NewsHaltAlert(riskMgr)).Run(context.Background(), newsHaltInterval)
+		if cfg.PortfolioHeat.Enabled && alpclient != nil && datafeed.Queries != nil {
+			heatInterval := time.Duration(max(cfg.PortfolioHeat.SnapshotIntervalMinutes, 1)) * time.Minute
+			go monitoring.NewHeatRecorder(posManager, monitoring.AccountEquity(alpclient), datafeed.Queries, nil).Run(context.Background(), heatInterval)
+		}
+		appConfig = cfg
+		chartsEnabled = cfg.Features.ChartRendering
+		signals.OmitZeroComponents = cfg.Features.OmitZeroSignalComponents
+		signals.ConfirmationBars = cfg.Features.SignalConfirmationBars
+		signals.SetRSICrossover(cfg.RSICrossover.Enabled, cfg.RSICrossover.FastPeriod, cfg.RSICrossover.SlowPeriod, cfg.RSICrossover.LookbackBars)
+		signals.SetBollinger(cfg.Bollinger.Enabled, cfg.Bollinger.Period, cfg.Bollinger.StdDevMult)
+		signals.SetTradeGradeCutoffs(cfg.TradeGrade.A, cfg.TradeGrade.B, cfg.TradeGrade.C, cfg.TradeGrade.D)
+		if err := signals.SetMultiTimeframeWeights(cfg.MultiTimeframe.DailyWeight, cfg.MultiTimeframe.FourHourWeight, cfg.MultiTimeframe.OneHourWeight, cfg.MultiTimeframe.Primary); err != nil {
+			log.Printf("Warning: ignoring multi_timeframe config: %v", err)
+		}
+		detection.SetBreakoutVolume(cfg.Patterns.BreakoutVolumeMultiplier, cfg.Patterns.BreakoutVolumePeriod)
+		detection.SetWhaleBaseline(cfg.Whales.BaselineWindow, cfg.Whales.IncludeCurrentBar, cfg.Whales.RobustZScore)
+		utils.SetCryptoBases(cfg.Features.CryptoSymbols)
+		scanner.ConfigureAssetCache(cfg.AssetCache.Enabled, time.Duration(cfg.AssetCache.TTLHours)*time.Hour)
+		analyzer.SetRecommendationTargets(cfg.RecommendationTargets)
+		datafeed.SetBarValidation(cfg.BarValidation.Enabled, cfg.BarValidation.MaxDroppedPct)
+		datafeed.SetBarRetry(cfg.BarRetry.MaxRetries, time.Duration(cfg.BarRetry.BaseDelayMillis)*time.Millisecond)
+		if err := indicators.SetSessionVWAP(cfg.Features.SessionVWAP, cfg.Global.MarketHours.Timezone); err != nil {
+			log.Printf("Warning: session VWAP using New York days: %v", err)
+		}
+		if err := risk.SetHeatBands(cfg.PortfolioHeat.YellowPercent, cfg.PortfolioHeat.RedPercent); err != nil {
+			log.Printf("Warning: ignoring portfolio_heat bands: %v", err)
+		}
+		riskMgr.SetAlertCooldown(risk.AlertCooldownFromConfig(cfg.AlertCooldown))
+	}
+
+	// OCO and bracket legs that fill at the broker are only booked by the position monitor, so the API runs one
+	// the way the CLI does after a trade
+	go posManager.MonitorPositions(context.Background(), 5*time.Second)
+
 	// Initialize JWT manager
 	jwtManager := internal.NewJWTManager()
 
+	// Per-call deadline for Alpaca requests made from HTTP handlers
+	alpacaTimeout := internal.DefaultAlpacaTimeout
+	if v, err := strconv.Atoi(os.Getenv("ALPACA_TIMEOUT_SECONDS")); err == nil && v > 0 {
+		alpacaTimeout = time.Duration(v) * time.Second
+	}
+
+	// Bounds for the in-memory backtest cache
+	backtestCacheSize := internal.DefaultBacktestCacheSize
+	if v, err := strconv.Atoi(os.Getenv("BACKTEST_CACHE_SIZE")); err == nil && v > 0 {
+		backtestCacheSize = v
+	}
+	backtestCacheTTL := internal.DefaultBacktestCacheTTL
+	if v, err := strconv.Atoi(os.Getenv("BACKTEST_CACHE_TTL_MINUTES")); err == nil && v > 0 {
+		backtestCacheTTL = time.Duration(v) * time.Minute
+	}
+
+	// Finished backtests are saved to the database when there is one
+	var backtestStore internal.BacktestStore
+	if datafeed.Queries != nil {
+		backtestStore = internal.NewDBBacktestStore(datafeed.Queries)
+	}
+
 	apiServer := &internal.API{
 		PositionManager: posManager,
 		RiskManager:     riskMgr,
 		Queries:         datafeed.Queries,
 		TradeMonitor:    tradeMon,
 		AlpacaClient:    alpclient,
+		AlpacaTimeout:   alpacaTimeout,
 		JWTManager:      jwtManager,
 		DB:              datafeed.DB,
+		OrderConfig:     orderConfig,
+		Config:          appConfig,
+		ChartsEnabled:   chartsEnabled,
+
+		BacktestStore:     backtestStore,
+		BacktestCacheSize: backtestCacheSize,
+		BacktestCacheTTL:  backtestCacheTTL,
 	}
 
 	r := chi.NewRouter()
@@ -117,11 +222,18 @@ func main() {
 
 	// Public routes
 	r.Get("/api/positions", apiServer.HandleGetPositions)
+	r.Get("/api/positions/earnings", apiServer.HandlePositionsEarnings)
 	r.Get("/api/positions/{symbol}", apiServer.HandleGetPositionBySymbol)
 	r.Get("/api/risk", apiServer.HandleGetRiskStatus)
+	r.Get("/api/risk/report", apiServer.HandleRiskReport)
+	r.Get("/api/risk/heat", apiServer.HandlePortfolioHeatHistory)
 	r.Get("/api/stats", apiServer.HandleGetStats)
 	r.Get("/api/trades", apiServer.HandleGetTrades)
 	r.Get("/api/trades/statistics", apiServer.HandleTradeStatistics)
+	r.Get("/api/trades/statistics/by-symbol", apiServer.HandleTradeStatisticsBySymbol)
+	r.Get("/api/trades/export", apiServer.HandleExportTrades)
+	r.Get("/api/trades/slippage", apiServer.HandleSlippageSummary)
+	// needs the API_TOKEN_KEY as X-API-Key when that's set; without it anyone who can reach the API gets a token
 	r.Post("/api/token", apiServer.HandleGenerateToken)
 
 	//Analytics & Monitoring
@@ -130,6 +242,9 @@ func main() {
 	r.Get("/api/performance-metrics", apiServer.HandlePerformanceMetrics)
 	r.Get("/api/risk-alerts", apiServer.HandleRiskAlerts)
 
+	// Alert Rules
+	r.Get("/api/alert-rules", apiServer.HandleGetAlertRules)
+
 	// News
 	r.Get("/api/news", apiServer.HandleGetNews)
 
@@ -137,26 +252,40 @@ func main() {
 	r.Get("/api/backtest", apiServer.HandleBacktest)
 	r.Get("/api/backtest/results", apiServer.HandleBacktestResults)
 	r.Get("/api/backtest/status", apiServer.HandleBacktestStatus)
+	r.Get("/api/backtest/list", apiServer.HandleListBacktests)
+	r.Get("/api/backtest/portfolio", apiServer.HandlePortfolioBacktest)
 	r.Get("/api/analysis/symbol", apiServer.HandleSymbolAnalysis)
 	r.Get("/api/analysis/report", apiServer.HandleAnalysisReport)
+	r.Get("/api/chart", apiServer.HandleChart)
+	r.Post("/api/analyze/bars", apiServer.HandleAnalyzeBars)
+	r.Get("/api/signals/history", apiServer.HandleSignalHistory)
+	r.Get("/api/scan-runs", apiServer.HandleScanRuns)
+	r.Get("/api/analytics/calibration", apiServer.HandleSignalCalibration)
 
 	// Watchlist & Scanner
 	r.Get("/api/watchlist", apiServer.HandleGetWatchlist)
-	r.Post("/api/watchlist", apiServer.HandleAddToWatchlist)
-	r.Delete("/api/watchlist", apiServer.HandleRemoveFromWatchlist)
-	r.Put("/api/watchlist/refresh-scores", apiServer.HandleRefreshWatchlistScores)
 	r.Get("/api/watchlist/analyze", apiServer.HandleAnalyzeSymbol)
 	r.Get("/api/scout", apiServer.HandleScoutStocks)
+	r.Post("/api/scout", apiServer.HandleScoutSymbols)
+	r.Get("/api/market/breadth", apiServer.HandleMarketBreadth)
+	r.Get("/api/problem-symbols", apiServer.HandleGetProblemSymbols)
 
 	// Settings
 	r.Get("/api/settings", apiServer.HandleGetSettings)
-	r.Post("/api/settings", apiServer.HandleUpdateSettings)
+	r.Get("/api/settings/schema", apiServer.HandleGetSettingsSchema)
 
 	// Trade Execution
-	r.Post("/api/execute-trade", apiServer.HandleExecuteTrade)
-	r.Post("/api/trades", apiServer.HandleExecuteTrade)
-	r.Post("/api/trades/sell-all", apiServer.HandleSellAllTrades)
-	r.Delete("/api/positions/{symbol}", apiServer.HandleClosePosition)
+	r.Post("/api/execute-trade/validate", apiServer.HandleValidateOrder)
+	r.Get("/api/positions/{symbol}/advice", apiServer.HandlePositionAdvice)
+
+	// Protected routes: anything that places orders, closes positions or writes to the database needs a bearer
+	// token from /api/token
+	r.Group(func(r chi.Router) {
+		r.Use(internal.AuthMiddleware(jwtManager))
+		for _, route := range apiServer.ProtectedRoutes() {
+			r.Method(route.Method, route.Pattern, route.Handler)
+		}
+	})
 
 	log.Println("Starting API server on :8080")
 	if err := http.ListenAndServe(":8080", r); err != nil {
@@ -15,6 +15,7 @@ import (
 	"github.com/fazecat/mogulmaker/Internal/types"
 	"github.com/fazecat/mogulmaker/Internal/utils"
 	"github.com/fazecat/mogulmaker/Internal/utils/analyzer"
+	"github.com/fazecat/mogulmaker/Internal/utils/config"
 	"github.com/fazecat/mogulmaker/Internal/utils/scanner"
 	"github.com/fazecat/mogulmaker/Internal/utils/scoring"
 )
@@ -61,81 +62,91 @@ func FetchMarketDataWithType(symbol string, timeframe string, limit int, startDa
 	return bars, nil
 }
 
-func FetchMultiTimeframeSignals(symbol string, assetType string) (*signals.MultiTimeframeSignal, error) {
-	dailyBars, err := datafeed.GetAlpacaBarsWithType(symbol, "1Day", 100, "", assetType)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch daily data: %w", err)
-	}
+// loads bars for one timeframe of the multi-timeframe check, latest first like GetAlpacaBarsWithType
+type BarFetcher func(symbol, timeframe string, limit int, assetType string) ([]datafeed.Bar, error)
 
-	fourHourBars, err := datafeed.GetAlpacaBarsWithType(symbol, "4Hour", 100, "", assetType)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch 4H data: %w", err)
-	}
+// bars fetched per timeframe for the multi-timeframe check
+const multiTimeframeBars = 100
 
-	oneHourBars, err := datafeed.GetAlpacaBarsWithType(symbol, "1Hour", 100, "", assetType)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch 1H data: %w", err)
-	}
+// the timeframes FetchMultiTimeframeSignalsWith combines, with the labels its errors use
+var multiTimeframes = []struct {
+	timeframe string
+	label     string
+}{
+	{signals.TimeframeDaily, "daily"},
+	{signals.TimeframeFourHour, "4H"},
+	{signals.TimeframeOneHour, "1H"},
+}
 
-	// Extract closes for RSI calculation
-	dailyCloses := make([]float64, len(dailyBars))
-	for i, bar := range dailyBars {
-		dailyCloses[i] = bar.Close
-	}
-	fourHourCloses := make([]float64, len(fourHourBars))
-	for i, bar := range fourHourBars {
-		fourHourCloses[i] = bar.Close
-	}
-	oneHourCloses := make([]float64, len(oneHourBars))
-	for i, bar := range oneHourBars {
-		oneHourCloses[i] = bar.Close
-	}
+func alpacaTimeframeBars(symbol, timeframe string, limit int, assetType string) ([]datafeed.Bar, error) {
+	return datafeed.GetAlpacaBarsWithType(symbol, timeframe, limit, "", assetType)
+}
 
-	// Calculate RSI for each timeframe
-	dailyRSIValues, err := indicators.CalculateRSI(dailyCloses, 14)
-	if err != nil || len(dailyRSIValues) == 0 {
-		return nil, fmt.Errorf("failed to calculate daily RSI: %w", err)
+func FetchMultiTimeframeSignals(symbol string, assetType string) (*signals.MultiTimeframeSignal, error) {
+	multiSignal, failures := FetchMultiTimeframeSignalsWith(symbol, assetType, alpacaTimeframeBars)
+	for _, tf := range multiTimeframes {
+		if err := failures[tf.timeframe]; err != nil {
+			return nil, err
+		}
 	}
-	dailyRSI := dailyRSIValues[len(dailyRSIValues)-1]
+	return multiSignal, nil
+}
 
-	fourHourRSIValues, err := indicators.CalculateRSI(fourHourCloses, 14)
-	if err != nil || len(fourHourRSIValues) == 0 {
-		return nil, fmt.Errorf("failed to calculate 4H RSI: %w", err)
+// the daily, 4H and 1H signals combined, plus the error for each timeframe that couldn't be fetched or
+// analyzed. A failed timeframe is left as an empty signal that neither confirms nor contradicts the others, so
+// it can't produce an alignment on its own; the signal is nil only when every timeframe failed
+func FetchMultiTimeframeSignalsWith(symbol string, assetType string, fetch BarFetcher) (*signals.MultiTimeframeSignal, map[string]error) {
+	timeframeSignals := make(map[string]signals.CombinedSignal, len(multiTimeframes))
+	failures := make(map[string]error)
+	for _, tf := range multiTimeframes {
+		bars, err := fetch(symbol, tf.timeframe, multiTimeframeBars, assetType)
+		if err != nil {
+			failures[tf.timeframe] = fmt.Errorf("failed to fetch %s data: %w", tf.label, err)
+			continue
+		}
+		signal, err := timeframeSignal(symbol, bars)
+		if err != nil {
+			failures[tf.timeframe] = fmt.Errorf("failed to analyze %s data: %w", tf.label, err)
+			continue
+		}
+		timeframeSignals[tf.timeframe] = signal
 	}
-	fourHourRSI := fourHourRSIValues[len(fourHourRSIValues)-1]
-
-	oneHourRSIValues, err := indicators.CalculateRSI(oneHourCloses, 14)
-	if err != nil || len(oneHourRSIValues) == 0 {
-		return nil, fmt.Errorf("failed to calculate 1H RSI: %w", err)
+	if len(timeframeSignals) == 0 {
+		return nil, failures
 	}
-	oneHourRSI := oneHourRSIValues[len(oneHourRSIValues)-1]
-
-	// Calculate ATR using scoring helper
-	dailyATR := scoring.CalculateATRFromBars(dailyBars)
-	fourHourATR := scoring.CalculateATRFromBars(fourHourBars)
-	oneHourATR := scoring.CalculateATRFromBars(oneHourBars)
 
-	// Analyze each timeframe
-	dailyCandle := analyzer.Candlestick{Open: dailyBars[len(dailyBars)-1].Open, Close: dailyBars[len(dailyBars)-1].Close, High: dailyBars[len(dailyBars)-1].High, Low: dailyBars[len(dailyBars)-1].Low}
-	_, dailyResults := analyzer.AnalyzeCandlestick(dailyCandle)
-	dailyAnalysis := dailyResults["Analysis"]
+	multiSignal := signals.CombineMultiTimeframeSignals(
+		timeframeSignals[signals.TimeframeDaily],
+		timeframeSignals[signals.TimeframeFourHour],
+		timeframeSignals[signals.TimeframeOneHour],
+	)
+	return &multiSignal, failures
+}
 
-	fourHourCandle := analyzer.Candlestick{Open: fourHourBars[len(fourHourBars)-1].Open, Close: fourHourBars[len(fourHourBars)-1].Close, High: fourHourBars[len(fourHourBars)-1].High, Low: fourHourBars[len(fourHourBars)-1].Low}
-	_, fourHourResults := analyzer.AnalyzeCandlestick(fourHourCandle)
-	fourHourAnalysis := fourHourResults["Analysis"]
+// RSI, ATR and the latest candle of one timeframe's bars combined into its signal
+func timeframeSignal(symbol string, bars []datafeed.Bar) (signals.CombinedSignal, error) {
+	if len(bars) == 0 {
+		return signals.CombinedSignal{}, fmt.Errorf("no bars returned")
+	}
+	closes := make([]float64, len(bars))
+	for i, bar := range bars {
+		closes[i] = bar.Close
+	}
 
-	oneHourCandle := analyzer.Candlestick{Open: oneHourBars[len(oneHourBars)-1].Open, Close: oneHourBars[len(oneHourBars)-1].Close, High: oneHourBars[len(oneHourBars)-1].High, Low: oneHourBars[len(oneHourBars)-1].Low}
-	_, oneHourResults := analyzer.AnalyzeCandlestick(oneHourCandle)
-	oneHourAnalysis := oneHourResults["Analysis"]
+	rsiValues, err := indicators.CalculateRSI(closes, 14)
+	if err != nil {
+		return signals.CombinedSignal{}, fmt.Errorf("failed to calculate RSI: %w", err)
+	}
+	if len(rsiValues) == 0 {
+		return signals.CombinedSignal{}, fmt.Errorf("failed to calculate RSI: no values")
+	}
+	rsi := rsiValues[len(rsiValues)-1]
+	atr := scoring.CalculateATRFromBars(bars)
 
-	// Generate signals for each timeframe
-	dailySignal := signals.CalculateSignal(&dailyRSI, &dailyATR, dailyBars, symbol, dailyAnalysis, dailyRSIValues)
-	fourHourSignal := signals.CalculateSignal(&fourHourRSI, &fourHourATR, fourHourBars, symbol, fourHourAnalysis, fourHourRSIValues)
-	oneHourSignal := signals.CalculateSignal(&oneHourRSI, &oneHourATR, oneHourBars, symbol, oneHourAnalysis, oneHourRSIValues)
+	last := bars[len(bars)-1]
+	_, results := analyzer.AnalyzeCandlestick(analyzer.Candlestick{Open: last.Open, Close: last.Close, High: last.High, Low: last.Low})
 
-	// Combine multi-timeframe signals
-	multiSignal := signals.CombineMultiTimeframeSignals(dailySignal, fourHourSignal, oneHourSignal)
-	return &multiSignal, nil
+	return signals.CalculateSignal(&rsi, &atr, bars, symbol, results["Analysis"], rsiValues), nil
 }
 
 func PickStockFromResults(results []scanner.StockScore) (string, error) {
@@ -182,9 +193,17 @@ func DisplayAdvancedData(bars []datafeed.Bar, symbol string, timeframe string) {
 		fmt.Printf("%-20s | %11.2f | %11.2f | %9.2f | %11.2f | %8d\n",
 			bar.Timestamp, bar.Open, bar.High, bar.Low, bar.Close, bar.Volume)
 	}
+
+	if len(bars) > obvTrendLookback {
+		obv := indicators.CalculateOBV(oldestFirstBars(bars))
+		fmt.Printf("\nOBV trend (%d bars): %s\n", obvTrendLookback, indicators.OBVTrend(obv, obvTrendLookback))
+	}
 }
 
-func DisplayAnalyticsData(bars []datafeed.Bar, symbol string, timeframe string, tz *time.Location, queries *sqlc.Queries, newsStorage *newsscraping.NewsStorage) {
+// bars of on-balance volume the advanced view reads its trend from
+const obvTrendLookback = 20
+
+func DisplayAnalyticsData(bars []datafeed.Bar, symbol string, timeframe string, tz *time.Location, queries *sqlc.Queries, newsStorage *newsscraping.NewsStorage, signalWeights map[string]float64) {
 	fmt.Printf("\n[ANALYTICS] Analytics Data for %s (%s) - Timezone: %s\n", symbol, timeframe, tz.String())
 
 	// Display news first if available
@@ -210,12 +229,12 @@ func DisplayAnalyticsData(bars []datafeed.Bar, symbol string, timeframe string,
 
 	// Try to fetch from database first
 	if !startTime.IsZero() && !endTime.IsZero() {
-		rsiMap, err = datafeed.FetchRSIByTimestampRange(symbol, startTime, endTime)
+		rsiMap, err = datafeed.FetchRSIByTimestampRange(symbol, timeframe, startTime, endTime)
 		if err != nil {
 			rsiMap = make(map[string]float64)
 		}
 
-		atrMap, err = datafeed.FetchATRByTimestampRange(symbol, startTime, endTime)
+		atrMap, err = datafeed.FetchATRByTimestampRange(symbol, timeframe, startTime, endTime)
 		if err != nil {
 			atrMap = make(map[string]float64)
 		}
@@ -238,9 +257,7 @@ func DisplayAnalyticsData(bars []datafeed.Bar, symbol string, timeframe string,
 			for i, rsi := range rsiValues {
 				barIdx := startIdx + i
 				if barIdx >= 0 && barIdx < len(bars) {
-					t, _ := time.Parse(time.RFC3339, bars[barIdx].Timestamp)
-					timestampStr := t.Format("2006-01-02 15:04:05")
-					rsiMap[timestampStr] = rsi
+					rsiMap[datafeed.BarIndicatorKey(bars[barIdx].Timestamp)] = rsi
 				}
 			}
 		}
@@ -251,14 +268,14 @@ func DisplayAnalyticsData(bars []datafeed.Bar, symbol string, timeframe string,
 		atrValue := scoring.CalculateATRFromBars(bars)
 		// Store same ATR for all recent bars
 		for _, bar := range bars {
-			t, _ := time.Parse(time.RFC3339, bar.Timestamp)
-			timestampStr := t.Format("2006-01-02 15:04:05")
-			atrMap[timestampStr] = atrValue
+			atrMap[datafeed.BarIndicatorKey(bar.Timestamp)] = atrValue
 		}
 	}
 
-	fmt.Println("Timestamp           | Close Price | Price Chg | Chg %  | Volume   | RSI    | ATR    | B/U Ratio | B/L Ratio | Analysis                  | Signals             ")
-	fmt.Println("--------------------|-------------|-----------|--------|----------|--------|--------|-----------|-----------|--------------------------|---------------------")
+	stochK, stochD, stochastic := analyticsStochastic(bars)
+
+	fmt.Println("Timestamp           | Close Price | Price Chg | Chg %  | Volume   | RSI    | Stoch K/D   | ATR    | B/U Ratio | B/L Ratio | Analysis                  | Signals             ")
+	fmt.Println("--------------------|-------------|-----------|--------|----------|--------|-------------|--------|-----------|-----------|--------------------------|---------------------")
 
 	var latestAnalysis string
 	var latestRSI *float64
@@ -273,20 +290,15 @@ func DisplayAnalyticsData(bars []datafeed.Bar, symbol string, timeframe string,
 			fmt.Printf("⚠️  Could not parse timestamp: %v\n", err)
 		}
 
-		var timestampStr string
-		var displayTimestamp string
-
+		displayTimestamp := bar.Timestamp
 		if err == nil {
-			localTime := t.In(tz)
-			displayTimestamp = localTime.Format("2006-01-02 15:04:05")
-			timestampStr = t.Format("2006-01-02 15:04:05")
-		} else {
-			timestampStr = bar.Timestamp
-			displayTimestamp = bar.Timestamp
+			displayTimestamp = t.In(tz).Format("2006-01-02 15:04:05")
 		}
 
-		rsiVal, hasRSI := rsiMap[timestampStr]
-		atrVal, hasATR := atrMap[timestampStr]
+		// indicators are keyed by the bar's UTC instant; the display timezone only affects the printed column
+		key := datafeed.BarIndicatorKey(bar.Timestamp)
+		rsiVal, hasRSI := rsiMap[key]
+		atrVal, hasATR := atrMap[key]
 
 		rsiStr := "  -   "
 		if hasRSI {
@@ -298,6 +310,12 @@ func DisplayAnalyticsData(bars []datafeed.Bar, symbol string, timeframe string,
 			atrStr = fmt.Sprintf("%6.2f", atrVal)
 		}
 
+		stochVal, hasStoch := stochastic[key]
+		stochStr := "     -     "
+		if hasStoch {
+			stochStr = fmt.Sprintf("%5.1f/%5.1f", stochVal.k, stochVal.d)
+		}
+
 		candle := analyzer.Candlestick{
 			Open:  bar.Open,
 			Close: bar.Close,
@@ -349,6 +367,20 @@ func DisplayAnalyticsData(bars []datafeed.Bar, symbol string, timeframe string,
 			}
 		}
 
+		if hasStoch {
+			stochSignal := ""
+			switch indicators.DetermineStochasticSignal(stochVal.k, stochVal.d) {
+			case "overbought":
+				stochSignal = "[OVERBOUGHT] Stoch High"
+			case "oversold":
+				stochSignal = "[OVERSOLD] Stoch Low"
+			}
+			if stochSignal != "" && signalStr != "" {
+				signalStr += " | "
+			}
+			signalStr += stochSignal
+		}
+
 		if hasATR {
 
 			atrThreshold := bar.Close * 0.01
@@ -385,11 +417,13 @@ func DisplayAnalyticsData(bars []datafeed.Bar, symbol string, timeframe string,
 			signalStr = "-"
 		}
 
-		fmt.Printf("%-20s | %11.2f | %9.2f | %6.2f | %8d | %6s | %6s | %9s | %9s | %-25s | %-20s\n",
-			displayTimestamp, bar.Close, priceChange, priceChangePercent, bar.Volume, rsiStr, atrStr, bodyToUpperStr, bodyToLowerStr, analysisStr, signalStr)
+		fmt.Printf("%-20s | %11.2f | %9.2f | %6.2f | %8d | %6s | %11s | %6s | %9s | %9s | %-25s | %-20s\n",
+			displayTimestamp, bar.Close, priceChange, priceChangePercent, bar.Volume, rsiStr, stochStr, atrStr, bodyToUpperStr, bodyToLowerStr, analysisStr, signalStr)
 	}
 
-	displayFinalSignal(bars, symbol, latestAnalysis, latestRSI, latestATR, "stock")
+	displayStochasticSummary(stochK, stochD)
+
+	displayFinalSignal(bars, symbol, timeframe, latestAnalysis, latestRSI, latestATR, "stock", queries, signalWeights)
 
 	if queries != nil {
 		fmt.Println()
@@ -400,6 +434,51 @@ func DisplayAnalyticsData(bars []datafeed.Bar, symbol string, timeframe string,
 	displayPatternSignals(bars, symbol)
 }
 
+// a copy of latest-first bars in the oldest-first order the oscillators read
+func oldestFirstBars(bars []datafeed.Bar) []datafeed.Bar {
+	reversed := make([]datafeed.Bar, len(bars))
+	for i, bar := range bars {
+		reversed[len(bars)-1-i] = bar
+	}
+	return reversed
+}
+
+type stochasticValue struct {
+	k, d float64
+}
+
+// the default %K/%D series over latest-first bars, plus each bar's reading past the warm-up keyed like the RSI
+// map. The series are nil without enough bars
+func analyticsStochastic(bars []datafeed.Bar) (k, d []float64, byTimestamp map[string]stochasticValue) {
+	byTimestamp = make(map[string]stochasticValue)
+	oldestFirst := oldestFirstBars(bars)
+	k, d, err := indicators.CalculateStochastic(oldestFirst, indicators.DefaultStochasticKPeriod, indicators.DefaultStochasticDPeriod)
+	if err != nil {
+		return nil, nil, byTimestamp
+	}
+	for i := indicators.DefaultStochasticKPeriod + indicators.DefaultStochasticDPeriod - 2; i < len(oldestFirst); i++ {
+		byTimestamp[datafeed.BarIndicatorKey(oldestFirst[i].Timestamp)] = stochasticValue{k: k[i], d: d[i]}
+	}
+	return k, d, byTimestamp
+}
+
+// the latest %K/%D reading and whether %K just crossed %D
+func displayStochasticSummary(k, d []float64) {
+	if len(k) == 0 {
+		return
+	}
+	lastK, lastD := k[len(k)-1], d[len(d)-1]
+	fmt.Printf("\nStochastic (%d/%d): %%K %.2f / %%D %.2f - %s", indicators.DefaultStochasticKPeriod, indicators.DefaultStochasticDPeriod,
+		lastK, lastD, indicators.DetermineStochasticSignal(lastK, lastD))
+	crossedUp, crossedDown := indicators.DetectStochasticCrossover(k, d, indicators.DefaultStochasticKPeriod, indicators.DefaultStochasticDPeriod)
+	if crossedUp {
+		fmt.Print(" | %K crossed above %D (bullish)")
+	} else if crossedDown {
+		fmt.Print(" | %K crossed below %D (bearish)")
+	}
+	fmt.Println()
+}
+
 func displayPatternSignals(bars []datafeed.Bar, symbol string) {
 	if len(bars) < 5 {
 		return
@@ -510,7 +589,8 @@ func displayPatternSignals(bars []datafeed.Bar, symbol string) {
 	fmt.Println("═══════════════════════════════════════════════════════════════════════════════════")
 }
 
-func displayFinalSignal(bars []datafeed.Bar, symbol string, analysis string, rsi, atr *float64, assetType string) {
+// signalWeights are the active profile's combined-signal weights, nil for the defaults
+func displayFinalSignal(bars []datafeed.Bar, symbol string, timeframe string, analysis string, rsi, atr *float64, assetType string, queries *sqlc.Queries, signalWeights map[string]float64) {
 	if len(bars) == 0 {
 		return
 	}
@@ -525,13 +605,15 @@ func displayFinalSignal(bars []datafeed.Bar, symbol string, analysis string, rsi
 		rsiValues = []float64{} // Use empty array if calculation fails
 	}
 
-	signal := signals.CalculateSignal(rsi, atr, bars, symbol, analysis, rsiValues)
+	signal := signals.CalculateSignalWithWeights(rsi, atr, bars, symbol, analysis, rsiValues, signalWeights)
+	persistAnalysisSignal(queries, bars, symbol, timeframe, signal)
 	filter := signals.NewSignalQualityFilter()
 	filter.MinConfidenceThreshold = 70.0
+	filter.MinADX = signals.DefaultMinADX
 	filter.VerboseLogging = true
 
 	tradeSignal := signals.ConvertToTradeSignal(signal)
-	filteredResult := filter.FilterSignal(tradeSignal)
+	filteredResult := filter.FilterSignalWithTrend(tradeSignal, oldestFirstBars(bars))
 
 	fmt.Println()
 	fmt.Println("═══════════════════════════════════════════════════════════════════════════════════")
@@ -541,6 +623,9 @@ func displayFinalSignal(bars []datafeed.Bar, symbol string, analysis string, rsi
 	if filteredResult.Passed {
 		fmt.Printf("[FINAL] RECOMMENDATION: %s \n", recommendationStr)
 		fmt.Printf("[PASS] Signal Quality: %.1f%% - %s\n", filteredResult.QualityScore, filteredResult.RecommendedAction)
+		if filteredResult.ADX > 0 {
+			fmt.Printf("[TREND] ADX %.1f (minimum %.1f)\n", filteredResult.ADX, filter.MinADX)
+		}
 	} else {
 		fmt.Printf("[WARNING] FILTERED SIGNAL: %s\n", recommendationStr)
 		fmt.Printf("-X- Quality Check Failed: %s\n", filteredResult.FailureReason)
@@ -610,6 +695,25 @@ func displayFinalSignal(bars []datafeed.Bar, symbol string, analysis string, rsi
 	fmt.Println("═══════════════════════════════════════════════════════════════════════════════════")
 }
 
+// stores the analysis signal when features.persist_signals is enabled
+func persistAnalysisSignal(queries *sqlc.Queries, bars []datafeed.Bar, symbol string, timeframe string, signal signals.CombinedSignal) {
+	if queries == nil || len(bars) == 0 {
+		return
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil || !cfg.Features.PersistSignals {
+		return
+	}
+
+	latest := bars[0]
+	if bars[len(bars)-1].Timestamp > latest.Timestamp {
+		latest = bars[len(bars)-1]
+	}
+	if _, err := signals.RecordSignal(context.Background(), queries, symbol, signals.SignalSourceAnalysis, timeframe, latest.Close, signal); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
+
 func displayWhaleEventsInline(symbol string, queries *sqlc.Queries) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -786,6 +890,7 @@ func ShowDisplayMenu() (string, error) {
 	fmt.Println("4. All Data")
 	fmt.Println("5. Export Data")
 	fmt.Println("6. vWAP Analysis")
+	fmt.Println("7. Bollinger Bands")
 
 	fmt.Print("Enter choice: ")
 	var choice int
@@ -808,6 +913,8 @@ func ShowDisplayMenu() (string, error) {
 		return "export", nil
 	case 6:
 		return "vwap", nil
+	case 7:
+		return "bollinger", nil
 	default:
 		fmt.Println("Invalid choice.")
 	}
@@ -857,7 +964,7 @@ func ShowTimezoneMenu() (*time.Location, error) {
 	}
 }
 
-func PrepareExportData(bars []datafeed.Bar, symbol string, timezone *time.Location) []export.ExportRecord {
+func PrepareExportData(bars []datafeed.Bar, symbol, timeframe string, timezone *time.Location) []export.ExportRecord {
 	var records []export.ExportRecord
 
 	var rsiMap map[string]float64
@@ -874,20 +981,21 @@ func PrepareExportData(bars []datafeed.Bar, symbol string, timezone *time.Locati
 	}
 
 	if !startTime.IsZero() && !endTime.IsZero() {
-		rsiMap, _ = datafeed.FetchRSIByTimestampRange(symbol, startTime, endTime)
-		atrMap, _ = datafeed.FetchATRByTimestampRange(symbol, startTime, endTime)
+		rsiMap, _ = datafeed.FetchRSIByTimestampRange(symbol, timeframe, startTime, endTime)
+		atrMap, _ = datafeed.FetchATRByTimestampRange(symbol, timeframe, startTime, endTime)
 	} else {
 		fetchLimit := len(bars) * 10
-		rsiMap, _ = datafeed.FetchRSIForDisplay(symbol, fetchLimit)
-		atrMap, _ = datafeed.FetchATRForDisplay(symbol, fetchLimit)
+		rsiMap, _ = datafeed.FetchRSIForDisplay(symbol, timeframe, fetchLimit)
+		atrMap, _ = datafeed.FetchATRForDisplay(symbol, timeframe, fetchLimit)
 	}
 
 	for _, bar := range bars {
 		t, _ := time.Parse(time.RFC3339, bar.Timestamp)
 		timestampStr := t.In(timezone).Format("2006-01-02 15:04:05")
 
-		rsiVal, hasRSI := rsiMap[t.Format("2006-01-02 15:04:05")]
-		atrVal, hasATR := atrMap[t.Format("2006-01-02 15:04:05")]
+		key := datafeed.BarIndicatorKey(bar.Timestamp)
+		rsiVal, hasRSI := rsiMap[key]
+		atrVal, hasATR := atrMap[key]
 
 		var rsiPtr *float64
 		if hasRSI {
@@ -1063,6 +1171,62 @@ func DisplayVWAPAnalysis(bars []datafeed.Bar, symbol string, timeframe string) {
 	fmt.Println("\n==========================================")
 }
 
+// bands, width and squeeze for oldest-first bars, read alongside volume dry-up since a squeeze on drying volume is
+// the classic coil before a breakout
+func DisplayBollingerAnalysis(bars []datafeed.Bar, symbol string, timeframe string) {
+	period := signals.Bollinger.Period
+	if len(bars) < period {
+		fmt.Printf(" Need at least %d bars for Bollinger Band analysis (got %d)\n", period, len(bars))
+		return
+	}
+
+	typesBars := make([]types.Bar, len(bars))
+	closes := make([]float64, len(bars))
+	volumes := make([]int64, len(bars))
+	for i := range bars {
+		typesBars[i] = types.Bar(bars[i])
+		closes[i] = bars[i].Close
+		volumes[i] = bars[i].Volume
+	}
+
+	upper, middle, lower, err := indicators.CalculateBollingerBands(closes, period, signals.Bollinger.StdDevMult)
+	if err != nil {
+		fmt.Printf(" Bollinger Bands unavailable: %v\n", err)
+		return
+	}
+	squeeze, width := indicators.IsBollingerSqueeze(typesBars, period)
+	last := len(closes) - 1
+
+	fmt.Printf("\n Bollinger Bands (%d, %.1f) for %s (%s)\n", period, signals.Bollinger.StdDevMult, symbol, timeframe)
+	fmt.Println("==========================================")
+	fmt.Printf("  Upper Band:  %.2f\n", upper[last])
+	fmt.Printf("  Middle Band: %.2f\n", middle[last])
+	fmt.Printf("  Lower Band:  %.2f\n", lower[last])
+	fmt.Printf("  Close:       %.2f\n", closes[last])
+	if upper[last] > lower[last] {
+		fmt.Printf("  %%B:          %.2f (0 = lower band, 1 = upper band)\n", (closes[last]-lower[last])/(upper[last]-lower[last]))
+	}
+	fmt.Printf("  Band Width:  %.4f\n", width)
+
+	fmt.Println("\n SQUEEZE:")
+	if squeeze {
+		fmt.Printf("  SQUEEZE - bands at or under %.0f%% of their recent average width\n", indicators.SqueezeWidthRatio*100)
+	} else {
+		fmt.Println("  No squeeze")
+	}
+
+	dryUpRatio := indicators.VolumeDryUpRatio(volumes, 5)
+	dryUp := indicators.DetectVolumeDryUp(volumes, 5)
+	if dryUpRatio > 0 {
+		fmt.Printf("  Volume (5 bars vs baseline): %.2fx\n", dryUpRatio)
+	}
+	if squeeze && dryUp {
+		fmt.Println("  → Squeeze on drying volume: coiling, watch for a breakout")
+	}
+
+	fmt.Println("\n==========================================")
+}
+
 func displayNewsForSymbol(symbol string, newsStorage *newsscraping.NewsStorage) {
 	ctx := context.Background()
 	articles, err := newsStorage.GetLatestNews(ctx, symbol, 5)
@@ -15,8 +15,14 @@ import (
 	"github.com/fazecat/mogulmaker/Internal/handlers/risk"
 	newsscraping "github.com/fazecat/mogulmaker/Internal/news_scraping"
 	"github.com/fazecat/mogulmaker/Internal/strategy"
+	"github.com/fazecat/mogulmaker/Internal/strategy/alerts"
+	"github.com/fazecat/mogulmaker/Internal/strategy/detection"
+	"github.com/fazecat/mogulmaker/Internal/strategy/indicators"
 	"github.com/fazecat/mogulmaker/Internal/strategy/position"
+	"github.com/fazecat/mogulmaker/Internal/strategy/signals"
+	"github.com/fazecat/mogulmaker/Internal/types"
 	"github.com/fazecat/mogulmaker/Internal/utils"
+	"github.com/fazecat/mogulmaker/Internal/utils/analyzer"
 	"github.com/fazecat/mogulmaker/Internal/utils/config"
 	"github.com/fazecat/mogulmaker/Internal/utils/scanner"
 	"github.com/joho/godotenv"
@@ -75,6 +81,19 @@ func main() {
 	if account != nil {
 		accountEquity, _ := account.Equity.Float64()
 		riskMgr = risk.NewManager(alpclient, accountEquity)
+		// resolved sectors are cached in the database when there is one
+		sectors := risk.NewSectorResolver(nil)
+		if datafeed.Queries != nil {
+			sectors = risk.NewSectorResolver(datafeed.Queries)
+		}
+		riskMgr.SetSectorResolver(sectors)
+		riskMgr.RegisterAlertCallback(func(alert *risk.Alert) {
+			log.Printf("[%s] %s: %s", alert.Level, alert.Title, alert.Message)
+		})
+		if webhookURL := os.Getenv("ALERT_WEBHOOK_URL"); webhookURL != "" {
+			riskMgr.RegisterAlertCallback(risk.NewWebhookAlertCallback(webhookURL))
+			log.Println("Risk alerts will also be posted to ALERT_WEBHOOK_URL")
+		}
 		log.Println("Risk Manager initialized")
 	} else {
 		log.Println("Risk Manager could not be initialized - account data unavailable")
@@ -89,7 +108,40 @@ func main() {
 		MaxDailyLossPercent:   -2.0,
 		PartialExitPercentage: 0.5,
 	}
+	riskMgr.CapOrderSizing(orderConfig)
+	if cfg != nil {
+		orderConfig.SetAssetClassRisk(cfg.AssetClassRisk)
+		orderConfig.SetPositionSizing(cfg.PositionSizing)
+		signals.OmitZeroComponents = cfg.Features.OmitZeroSignalComponents
+		signals.ConfirmationBars = cfg.Features.SignalConfirmationBars
+		signals.SetRSICrossover(cfg.RSICrossover.Enabled, cfg.RSICrossover.FastPeriod, cfg.RSICrossover.SlowPeriod, cfg.RSICrossover.LookbackBars)
+		signals.SetBollinger(cfg.Bollinger.Enabled, cfg.Bollinger.Period, cfg.Bollinger.StdDevMult)
+		signals.SetTradeGradeCutoffs(cfg.TradeGrade.A, cfg.TradeGrade.B, cfg.TradeGrade.C, cfg.TradeGrade.D)
+		if err := signals.SetMultiTimeframeWeights(cfg.MultiTimeframe.DailyWeight, cfg.MultiTimeframe.FourHourWeight, cfg.MultiTimeframe.OneHourWeight, cfg.MultiTimeframe.Primary); err != nil {
+			log.Printf("Warning: ignoring multi_timeframe config: %v", err)
+		}
+		detection.SetBreakoutVolume(cfg.Patterns.BreakoutVolumeMultiplier, cfg.Patterns.BreakoutVolumePeriod)
+		detection.SetWhaleBaseline(cfg.Whales.BaselineWindow, cfg.Whales.IncludeCurrentBar, cfg.Whales.RobustZScore)
+		utils.SetCryptoBases(cfg.Features.CryptoSymbols)
+		scanner.ConfigureAssetCache(cfg.AssetCache.Enabled, time.Duration(cfg.AssetCache.TTLHours)*time.Hour)
+		analyzer.SetRecommendationTargets(cfg.RecommendationTargets)
+		datafeed.SetBarValidation(cfg.BarValidation.Enabled, cfg.BarValidation.MaxDroppedPct)
+		datafeed.SetBarRetry(cfg.BarRetry.MaxRetries, time.Duration(cfg.BarRetry.BaseDelayMillis)*time.Millisecond)
+		if err := indicators.SetSessionVWAP(cfg.Features.SessionVWAP, cfg.Global.MarketHours.Timezone); err != nil {
+			log.Printf("Warning: session VWAP using New York days: %v", err)
+		}
+		if err := risk.SetHeatBands(cfg.PortfolioHeat.YellowPercent, cfg.PortfolioHeat.RedPercent); err != nil {
+			log.Printf("Warning: ignoring portfolio_heat bands: %v", err)
+		}
+		riskMgr.SetAlertCooldown(risk.AlertCooldownFromConfig(cfg.AlertCooldown))
+	}
 	posManager := position.NewPositionManager(alpclient, orderConfig)
+	if cfg != nil {
+		posManager.SetAutoExit(cfg.AutoExit)
+		posManager.SetAggregateLots(cfg.Features.AggregateLots)
+		posManager.SetRecalculateLevels(cfg.Features.RecalculateLevelsOnConfigChange)
+		posManager.SetTrackBracketLegs(cfg.Features.TrackBracketLegs)
+	}
 
 	tradeMon := monitoring.NewMonitor(posManager, riskMgr, datafeed.Queries)
 	log.Println("Trade Monitor initialized")
@@ -106,7 +158,20 @@ func main() {
 	log.Println("News scraping initialized")
 
 	ctx := context.Background()
-	go startBackgroundScanner(ctx, cfg)
+	go startBackgroundScanner(ctx, cfg, riskMgr)
+	if cfg != nil {
+		// a nil *Queries would be a non-nil RetentionStore, so check before handing it over
+		if datafeed.Queries != nil {
+			go datafeed.StartRetentionJob(ctx, datafeed.Queries, cfg.Retention)
+		}
+		go position.NewEODCloser(posManager, cfg, nil, riskMgr.SendFlattenAlert).Run(ctx, time.Minute)
+		newsHaltInterval := time.Duration(max(cfg.NewsHalt.CheckIntervalMinutes, 1)) * time.Minute
+		go monitoring.NewNewsHaltMonitor(posManager, finnhubClient, cfg.NewsHalt, nil, monitoring.NewsHaltAlert(riskMgr)).Run(ctx, newsHaltInterval)
+		if cfg.PortfolioHeat.Enabled && datafeed.Queries != nil {
+			heatInterval := time.Duration(max(cfg.PortfolioHeat.SnapshotIntervalMinutes, 1)) * time.Minute
+			go monitoring.NewHeatRecorder(posManager, monitoring.AccountEquity(alpclient), datafeed.Queries, nil).Run(ctx, heatInterval)
+		}
+	}
 
 	for {
 		if pm := handlers.GetGlobalPositionManager(); pm != nil {
@@ -142,7 +207,7 @@ func main() {
 		case 3:
 			handlers.HandleScout(ctx, cfg, datafeed.Queries, newsStorage, finnhubClient)
 		case 4:
-			handlers.HandleExecuteTrades(ctx, cfg, datafeed.Queries, alpclient)
+			handlers.HandleExecuteTrades(ctx, cfg, datafeed.Queries, alpclient, riskMgr)
 		case 5:
 			handlers.HandleTradeHistory(ctx, cfg, datafeed.Queries)
 		case 6:
@@ -164,7 +229,7 @@ func main() {
 	}
 }
 
-func startBackgroundScanner(ctx context.Context, cfg *config.Config) {
+func startBackgroundScanner(ctx context.Context, cfg *config.Config, riskMgr *risk.Manager) {
 	log.Println("Background scanner started...")
 	ticker := time.NewTicker(15 * time.Minute)
 	defer ticker.Stop()
@@ -184,6 +249,35 @@ func startBackgroundScanner(ctx context.Context, cfg *config.Config) {
 			}
 			scanner.PerformScan(ctx, "default", cfg, datafeed.Queries)
 
+			evaluateAlertRules(ctx, riskMgr)
 		}
 	}
 }
+
+// runs user-defined alert rules and forwards matches to the risk manager's alert callbacks
+func evaluateAlertRules(ctx context.Context, riskMgr *risk.Manager) {
+	fetchBars := func(symbol string) ([]types.Bar, error) {
+		return datafeed.GetAlpacaBars(symbol, "1Day", 100, "")
+	}
+	_, err := scanner.EvaluateAlertRules(ctx, datafeed.Queries, fetchBars, func(trigger alerts.Trigger) {
+		if riskMgr == nil {
+			log.Printf("Alert rule %s fired for %s: %s", trigger.Rule.Name, trigger.Symbol, trigger.Rule.Describe())
+			return
+		}
+		data := make(map[string]interface{}, len(trigger.Values)+1)
+		for field, value := range trigger.Values {
+			data[field] = value
+		}
+		data["rule_id"] = trigger.Rule.ID
+		riskMgr.SendAlert(&risk.Alert{
+			Level:   "INFO",
+			Title:   fmt.Sprintf("Alert rule: %s", trigger.Rule.Name),
+			Message: fmt.Sprintf("%s matched %s", trigger.Symbol, trigger.Rule.Describe()),
+			Symbol:  trigger.Symbol,
+			Data:    data,
+		})
+	})
+	if err != nil {
+		log.Printf("Alert rule evaluation error: %v", err)
+	}
+}